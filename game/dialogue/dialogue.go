@@ -0,0 +1,132 @@
+package dialogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Dialogue 对话，由多个对话节点组成的节点图，通常由策划配置表导出为 JSON 后通过 Unmarshal 加载
+type Dialogue struct {
+	ID    string  `json:"id"`    // 对话 ID
+	Start string  `json:"start"` // 起始节点 ID
+	Nodes []*Node `json:"nodes"` // 对话节点
+
+	nodes map[string]*Node
+}
+
+// Node 对话节点
+type Node struct {
+	ID         string    `json:"id"`         // 节点 ID
+	Speaker    string    `json:"speaker"`    // 说话者
+	Text       string    `json:"text"`       // 对话内容，通常为多语言 key
+	Conditions []Expr    `json:"conditions"` // 进入节点的条件
+	Effects    []Expr    `json:"effects"`    // 进入节点时执行的效果
+	Choices    []*Choice `json:"choices"`    // 选项，为空时将通过 Next 继续对话
+	Next       string    `json:"next"`       // 没有选项时的下一个节点，为空时对话结束
+}
+
+// Choice 对话选项
+type Choice struct {
+	Text       string `json:"text"`       // 选项内容，通常为多语言 key
+	Conditions []Expr `json:"conditions"` // 选项可用的条件，不满足时选项将不会展示
+	Effects    []Expr `json:"effects"`    // 选择该选项时执行的效果
+	Next       string `json:"next"`       // 选择该选项后的下一个节点，为空时对话结束
+}
+
+// Expr 条件或效果表达式，格式为 "name:arg1:arg2"，例如 "has_item:1001:2"、"quest_progress:30001:1"
+type Expr struct {
+	Name string   // 条件或效果的名称
+	Args []string // 参数
+}
+
+// ParseExpr 解析格式为 "name:arg1:arg2" 的表达式
+func ParseExpr(expr string) Expr {
+	parts := strings.Split(strings.TrimSpace(expr), ":")
+	return Expr{Name: parts[0], Args: parts[1:]}
+}
+
+// String 获取表达式的字符串形式
+func (e Expr) String() string {
+	return strings.Join(append([]string{e.Name}, e.Args...), ":")
+}
+
+// MarshalJSON 将表达式序列化为字符串形式
+func (e Expr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+// UnmarshalJSON 从字符串形式反序列化表达式
+func (e *Expr) UnmarshalJSON(data []byte) error {
+	var expr string
+	if err := json.Unmarshal(data, &expr); err != nil {
+		return err
+	}
+	*e = ParseExpr(expr)
+	return nil
+}
+
+// Unmarshal 从策划配置表导出的 JSON 数据中反序列化对话，data 可以是单个对话或对话数组
+func Unmarshal(data []byte) ([]*Dialogue, error) {
+	var dialogues []*Dialogue
+	if err := json.Unmarshal(data, &dialogues); err != nil {
+		var dialogue = new(Dialogue)
+		if json.Unmarshal(data, dialogue) != nil {
+			return nil, err
+		}
+		dialogues = append(dialogues, dialogue)
+	}
+	return dialogues, nil
+}
+
+// GetNode 获取对话中的特定节点，当节点不存在时将返回 nil
+func (d *Dialogue) GetNode(id string) *Node {
+	return d.nodes[id]
+}
+
+// build 建立节点索引并检查节点图的完整性
+func (d *Dialogue) build() error {
+	d.nodes = make(map[string]*Node, len(d.Nodes))
+	for _, node := range d.Nodes {
+		d.nodes[node.ID] = node
+	}
+	check := func(from, to string) error {
+		if to == "" {
+			return nil
+		}
+		if _, exist := d.nodes[to]; !exist {
+			return fmt.Errorf("%w: dialogue %s node %s -> %s", ErrNodeNotExist, d.ID, from, to)
+		}
+		return nil
+	}
+	if d.Start == "" {
+		return fmt.Errorf("%w: dialogue %s start node is empty", ErrNodeNotExist, d.ID)
+	}
+	if err := check("start", d.Start); err != nil {
+		return err
+	}
+	for _, node := range d.Nodes {
+		if err := check(node.ID, node.Next); err != nil {
+			return err
+		}
+		for _, choice := range node.Choices {
+			if err := check(node.ID, choice.Next); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exprs 获取对话中使用的所有条件及效果表达式
+func (d *Dialogue) exprs() (conditions, effects []Expr) {
+	for _, node := range d.Nodes {
+		conditions = append(conditions, node.Conditions...)
+		effects = append(effects, node.Effects...)
+		for _, choice := range node.Choices {
+			conditions = append(conditions, choice.Conditions...)
+			effects = append(effects, choice.Effects...)
+		}
+	}
+	return
+}
@@ -0,0 +1,3 @@
+// Package dialogue 提供了数据驱动的 NPC 对话及分支功能，对话由策划配置表导出的节点图描述，
+// 节点及选项中的条件和效果通过注册的处理函数与任务、货币、道具等模块对接，使剧情内容无需编写硬编码的处理函数
+package dialogue
@@ -0,0 +1,130 @@
+package dialogue
+
+import (
+	"fmt"
+	"sync"
+)
+
+type (
+	// ConditionHandler 条件处理函数，返回 actor 是否满足条件，例如检查道具数量、任务状态等
+	ConditionHandler[Actor any] func(actor Actor, args []string) bool
+	// EffectHandler 效果处理函数，例如扣除货币、发放道具、推进任务等，返回错误时对话将停留在当前节点
+	EffectHandler[Actor any] func(actor Actor, args []string) error
+)
+
+// NewEngine 创建对话引擎 Engine 的实例
+func NewEngine[Actor any]() *Engine[Actor] {
+	return &Engine[Actor]{
+		dialogues:  make(map[string]*Dialogue),
+		conditions: make(map[string]ConditionHandler[Actor]),
+		effects:    make(map[string]EffectHandler[Actor]),
+	}
+}
+
+// Engine 对话引擎，负责加载对话配置并为玩家创建对话会话
+//   - 条件及效果需要在加载对话前通过 RegCondition 及 RegEffect 注册，加载时将检查对话中使用的条件及效果是否均已注册
+//   - 该实例是线程安全的，可在配置刷新时通过 Load 重新加载对话，已经开始的会话将继续使用旧的对话
+type Engine[Actor any] struct {
+	rw         sync.RWMutex
+	dialogues  map[string]*Dialogue
+	conditions map[string]ConditionHandler[Actor]
+	effects    map[string]EffectHandler[Actor]
+}
+
+// RegCondition 注册条件处理函数
+func (e *Engine[Actor]) RegCondition(name string, handler ConditionHandler[Actor]) *Engine[Actor] {
+	e.rw.Lock()
+	defer e.rw.Unlock()
+	e.conditions[name] = handler
+	return e
+}
+
+// RegEffect 注册效果处理函数
+func (e *Engine[Actor]) RegEffect(name string, handler EffectHandler[Actor]) *Engine[Actor] {
+	e.rw.Lock()
+	defer e.rw.Unlock()
+	e.effects[name] = handler
+	return e
+}
+
+// Load 加载对话，将替换当前所有已加载的对话
+//   - 当任一对话的节点引用不存在或使用了未注册的条件及效果时将返回错误，此时已加载的对话不会发生变化
+func (e *Engine[Actor]) Load(dialogues ...*Dialogue) error {
+	var loaded = make(map[string]*Dialogue, len(dialogues))
+	e.rw.RLock()
+	for _, dialogue := range dialogues {
+		if err := dialogue.build(); err != nil {
+			e.rw.RUnlock()
+			return err
+		}
+		conditions, effects := dialogue.exprs()
+		for _, expr := range conditions {
+			if _, exist := e.conditions[expr.Name]; !exist {
+				e.rw.RUnlock()
+				return fmt.Errorf("%w: dialogue %s %s", ErrUnknownCondition, dialogue.ID, expr)
+			}
+		}
+		for _, expr := range effects {
+			if _, exist := e.effects[expr.Name]; !exist {
+				e.rw.RUnlock()
+				return fmt.Errorf("%w: dialogue %s %s", ErrUnknownEffect, dialogue.ID, expr)
+			}
+		}
+		loaded[dialogue.ID] = dialogue
+	}
+	e.rw.RUnlock()
+
+	e.rw.Lock()
+	e.dialogues = loaded
+	e.rw.Unlock()
+	return nil
+}
+
+// GetDialogue 获取特定对话，当对话不存在时将返回 nil
+func (e *Engine[Actor]) GetDialogue(id string) *Dialogue {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	return e.dialogues[id]
+}
+
+// Start 使 actor 开始特定对话，将检查起始节点的条件并执行起始节点的效果
+func (e *Engine[Actor]) Start(actor Actor, dialogueId string) (*Session[Actor], error) {
+	dialogue := e.GetDialogue(dialogueId)
+	if dialogue == nil {
+		return nil, ErrDialogueNotExist
+	}
+	session := &Session[Actor]{engine: e, dialogue: dialogue, actor: actor}
+	if err := session.enter(dialogue.Start); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// check 检查 actor 是否满足所有条件
+func (e *Engine[Actor]) check(actor Actor, conditions []Expr) bool {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	for _, expr := range conditions {
+		handler, exist := e.conditions[expr.Name]
+		if !exist || !handler(actor, expr.Args) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply 依次执行所有效果，当任一效果返回错误时将停止执行并返回该错误
+func (e *Engine[Actor]) apply(actor Actor, effects []Expr) error {
+	for _, expr := range effects {
+		e.rw.RLock()
+		handler, exist := e.effects[expr.Name]
+		e.rw.RUnlock()
+		if !exist {
+			return fmt.Errorf("%w: %s", ErrUnknownEffect, expr)
+		}
+		if err := handler(actor, expr.Args); err != nil {
+			return fmt.Errorf("dialogue effect %s: %w", expr, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package dialogue_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/game/dialogue"
+	"strconv"
+	"testing"
+)
+
+type Player struct {
+	gold  int
+	items map[string]int
+}
+
+const merchant = `[{
+	"id": "merchant",
+	"start": "greet",
+	"nodes": [
+		{"id": "greet", "speaker": "merchant", "text": "dialogue.merchant.greet", "choices": [
+			{"text": "dialogue.merchant.buy", "conditions": ["gold:50"], "effects": ["pay:50", "give:potion:1"], "next": "thanks"},
+			{"text": "dialogue.merchant.leave"}
+		]},
+		{"id": "thanks", "speaker": "merchant", "text": "dialogue.merchant.thanks"}
+	]
+}]`
+
+func TestEngine_Start(t *testing.T) {
+	engine := dialogue.NewEngine[*Player]().
+		RegCondition("gold", func(player *Player, args []string) bool {
+			gold, _ := strconv.Atoi(args[0])
+			return player.gold >= gold
+		}).
+		RegEffect("pay", func(player *Player, args []string) error {
+			gold, _ := strconv.Atoi(args[0])
+			player.gold -= gold
+			return nil
+		})
+
+	dialogues, err := dialogue.Unmarshal([]byte(merchant))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = engine.Load(dialogues...); !errors.Is(err, dialogue.ErrUnknownEffect) {
+		t.Fatalf("load should fail with unregistered effect, got: %v", err)
+	}
+	engine.RegEffect("give", func(player *Player, args []string) error {
+		count, _ := strconv.Atoi(args[1])
+		player.items[args[0]] += count
+		return nil
+	})
+	if err = engine.Load(dialogues...); err != nil {
+		t.Fatal(err)
+	}
+
+	poor := &Player{gold: 10, items: map[string]int{}}
+	session, err := engine.Start(poor, "merchant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if choices := session.GetChoices(); len(choices) != 1 || choices[0] != 1 {
+		t.Fatalf("unexpected choices: %v", choices)
+	}
+	if err = session.Choose(0); !errors.Is(err, dialogue.ErrChoiceUnavailable) {
+		t.Fatalf("choice should be unavailable, got: %v", err)
+	}
+
+	rich := &Player{gold: 80, items: map[string]int{}}
+	session, _ = engine.Start(rich, "merchant")
+	if err = session.Choose(0); err != nil {
+		t.Fatal(err)
+	}
+	if session.GetNode().ID != "thanks" || rich.gold != 30 || rich.items["potion"] != 1 {
+		t.Fatalf("unexpected state, node: %s, gold: %d, items: %v", session.GetNode().ID, rich.gold, rich.items)
+	}
+	if err = session.Next(); err != nil || !session.IsEnded() {
+		t.Fatalf("dialogue should be ended, err: %v", err)
+	}
+}
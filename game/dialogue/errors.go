@@ -0,0 +1,20 @@
+package dialogue
+
+import "errors"
+
+var (
+	// ErrDialogueNotExist 对话不存在
+	ErrDialogueNotExist = errors.New("dialogue not exist")
+	// ErrNodeNotExist 对话节点不存在
+	ErrNodeNotExist = errors.New("dialogue node not exist")
+	// ErrUnknownCondition 未注册的条件
+	ErrUnknownCondition = errors.New("unknown dialogue condition")
+	// ErrUnknownEffect 未注册的效果
+	ErrUnknownEffect = errors.New("unknown dialogue effect")
+	// ErrConditionNotMet 条件不满足
+	ErrConditionNotMet = errors.New("dialogue condition not met")
+	// ErrChoiceUnavailable 选项不可用
+	ErrChoiceUnavailable = errors.New("dialogue choice unavailable")
+	// ErrDialogueEnded 对话已结束
+	ErrDialogueEnded = errors.New("dialogue ended")
+)
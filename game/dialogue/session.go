@@ -0,0 +1,90 @@
+package dialogue
+
+// Session 玩家的对话会话，记录玩家在对话节点图中的位置
+//   - 该实例不是线程安全的，通常应当在玩家所在的分流渠道中使用
+type Session[Actor any] struct {
+	engine   *Engine[Actor]
+	dialogue *Dialogue
+	actor    Actor
+	node     *Node
+}
+
+// GetDialogue 获取会话所属的对话
+func (s *Session[Actor]) GetDialogue() *Dialogue {
+	return s.dialogue
+}
+
+// GetNode 获取当前所在的对话节点，对话结束后将返回 nil
+func (s *Session[Actor]) GetNode() *Node {
+	return s.node
+}
+
+// IsEnded 检查对话是否已结束
+func (s *Session[Actor]) IsEnded() bool {
+	return s.node == nil
+}
+
+// GetChoices 获取当前节点中满足条件的选项，返回值为选项在 Node.Choices 中的索引
+func (s *Session[Actor]) GetChoices() []int {
+	if s.node == nil {
+		return nil
+	}
+	var indexes []int
+	for i, choice := range s.node.Choices {
+		if s.engine.check(s.actor, choice.Conditions) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Choose 选择当前节点中的特定选项，index 为选项在 Node.Choices 中的索引
+//   - 当选项不存在或不满足条件时返回 ErrChoiceUnavailable
+//   - 选项的效果将在进入下一个节点之前执行，当效果返回错误时将停留在当前节点
+func (s *Session[Actor]) Choose(index int) error {
+	if s.node == nil {
+		return ErrDialogueEnded
+	}
+	if index < 0 || index >= len(s.node.Choices) {
+		return ErrChoiceUnavailable
+	}
+	choice := s.node.Choices[index]
+	if !s.engine.check(s.actor, choice.Conditions) {
+		return ErrChoiceUnavailable
+	}
+	if err := s.engine.apply(s.actor, choice.Effects); err != nil {
+		return err
+	}
+	return s.enter(choice.Next)
+}
+
+// Next 在没有选项的节点中继续对话，当前节点存在选项时返回 ErrChoiceUnavailable
+func (s *Session[Actor]) Next() error {
+	if s.node == nil {
+		return ErrDialogueEnded
+	}
+	if len(s.node.Choices) > 0 {
+		return ErrChoiceUnavailable
+	}
+	return s.enter(s.node.Next)
+}
+
+// enter 进入特定节点，id 为空时对话结束
+func (s *Session[Actor]) enter(id string) error {
+	if id == "" {
+		s.node = nil
+		return nil
+	}
+	node := s.dialogue.GetNode(id)
+	if node == nil {
+		return ErrNodeNotExist
+	}
+	if !s.engine.check(s.actor, node.Conditions) {
+		return ErrConditionNotMet
+	}
+	if err := s.engine.apply(s.actor, node.Effects); err != nil {
+		return err
+	}
+	s.node = node
+	return nil
+}
@@ -0,0 +1,267 @@
+package ranking
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/cluster"
+	"github.com/kercylan98/minotaur/utils/generic"
+	"github.com/kercylan98/minotaur/utils/leaderboard"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync"
+	"sync/atomic"
+)
+
+// Entry 排行榜中竞争者的成绩
+type Entry[CompetitorID comparable, Score generic.Number] struct {
+	Competitor CompetitorID `json:"competitor"` // 竞争者
+	Score      Score        `json:"score"`      // 成绩
+}
+
+// Snapshot 获取本地排行榜的快照，可作为节点对外提供的排行榜数据，例如通过 server.RegRPC 注册的处理函数的返回值
+//   - 排行榜不是线程安全的，应当在操作排行榜的分流渠道中调用
+func Snapshot[CompetitorID comparable, Score generic.Number](board *leaderboard.BinarySearch[CompetitorID, Score]) []Entry[CompetitorID, Score] {
+	competitors := board.GetAllCompetitor()
+	entries := make([]Entry[CompetitorID, Score], 0, len(competitors))
+	for _, competitor := range competitors {
+		entries = append(entries, Entry[CompetitorID, Score]{Competitor: competitor, Score: board.GetScoreDefault(competitor, 0)})
+	}
+	return entries
+}
+
+// NodeProvider 提供参与聚合的节点，通常为 cluster.Cluster
+type NodeProvider interface {
+	// Nodes 获取当前所有的节点
+	Nodes() []cluster.Node
+}
+
+// Fetcher 从特定节点拉取排行榜数据，通常通过 RPC 调用节点上返回 Snapshot 的处理函数实现
+//   - Fetch 将在服务器的异步消息中执行，因此允许执行阻塞的 IO 操作
+type Fetcher[CompetitorID comparable, Score generic.Number] interface {
+	// Fetch 拉取节点的排行榜数据
+	Fetch(ctx context.Context, node cluster.Node) ([]Entry[CompetitorID, Score], error)
+}
+
+// FetcherFunc 函数形式的 Fetcher
+type FetcherFunc[CompetitorID comparable, Score generic.Number] func(ctx context.Context, node cluster.Node) ([]Entry[CompetitorID, Score], error)
+
+// Fetch 拉取节点的排行榜数据
+func (f FetcherFunc[CompetitorID, Score]) Fetch(ctx context.Context, node cluster.Node) ([]Entry[CompetitorID, Score], error) {
+	return f(ctx, node)
+}
+
+// NewAggregator 创建跨服排行榜聚合器 Aggregator 的实例，创建后将立即进行一次对账
+//   - 聚合器依赖服务器的异步消息及延迟消息，应当在服务器启动后创建
+func NewAggregator[CompetitorID comparable, Score generic.Number](srv *server.Server, nodes NodeProvider, fetcher Fetcher[CompetitorID, Score], options ...*Options) *Aggregator[CompetitorID, Score] {
+	a := &Aggregator[CompetitorID, Score]{
+		aggregatorEvents: new(aggregatorEvents[CompetitorID, Score]),
+		srv:              srv,
+		nodes:            nodes,
+		fetcher:          fetcher,
+		options:          mergeOptions(options...),
+		snapshots:        make(map[string]map[CompetitorID]Score),
+	}
+	a.board = a.newBoard()
+	a.Reconcile()
+	a.schedule()
+	return a
+}
+
+// Aggregator 跨服排行榜聚合器，将多个节点的排行榜数据按照 MergePolicy 合并为全局排行榜
+//   - 定期对账时将通过 Fetcher 拉取所有节点的完整数据并重建全局排行榜，拉取失败的节点将沿用上一次的数据，已离开集群的节点的数据将被移除
+//   - 两次对账之间可通过 Submit 增量更新特定节点的成绩
+//   - 该实例是线程安全的
+type Aggregator[CompetitorID comparable, Score generic.Number] struct {
+	*aggregatorEvents[CompetitorID, Score]
+	srv     *server.Server
+	nodes   NodeProvider
+	fetcher Fetcher[CompetitorID, Score]
+	options *Options
+
+	rw          sync.RWMutex
+	snapshots   map[string]map[CompetitorID]Score              // 各节点的竞争者成绩
+	board       *leaderboard.BinarySearch[CompetitorID, Score] // 全局排行榜
+	timer       *server.DelayedMessage                         // 定期对账的延迟消息
+	closed      bool
+	reconciling atomic.Bool // 是否正在对账
+}
+
+// Submit 增量更新特定节点中竞争者的成绩，并立即更新全局排行榜
+//   - 该成绩将在下一次对账时被节点的完整数据覆盖
+func (a *Aggregator[CompetitorID, Score]) Submit(node string, competitor CompetitorID, score Score) {
+	a.rw.Lock()
+	defer a.rw.Unlock()
+	snapshot, exist := a.snapshots[node]
+	if !exist {
+		snapshot = make(map[CompetitorID]Score)
+		a.snapshots[node] = snapshot
+	}
+	snapshot[competitor] = score
+	a.board.Competitor(competitor, a.merge(competitor))
+}
+
+// Reconcile 立即与所有节点进行对账，正在对账时将被忽略
+func (a *Aggregator[CompetitorID, Score]) Reconcile() {
+	if !a.reconciling.CompareAndSwap(false, true) {
+		return
+	}
+	nodes := a.nodes.Nodes()
+	var fetched = make(map[string][]Entry[CompetitorID, Score], len(nodes))
+	var failed = make(map[string]error)
+	a.srv.PushAsyncMessage(func() error {
+		for _, node := range nodes {
+			ctx, cancel := context.WithTimeout(context.Background(), a.options.timeout)
+			entries, err := a.fetcher.Fetch(ctx, node)
+			cancel()
+			if err != nil {
+				failed[node.ID] = err
+				log.Error("Ranking", log.String("Node", node.ID), log.Err(err))
+				continue
+			}
+			fetched[node.ID] = entries
+		}
+		return nil
+	}, func(err error) {
+		a.rebuild(nodes, fetched)
+		a.reconciling.Store(false)
+		a.OnReconcileEvent(a, failed)
+	}, log.String("Ranking", "reconcile"))
+}
+
+// GetRank 获取竞争者在全局排行榜中的排名，排名从 0 开始
+func (a *Aggregator[CompetitorID, Score]) GetRank(competitor CompetitorID) (int, error) {
+	a.rw.RLock()
+	defer a.rw.RUnlock()
+	return a.board.GetRank(competitor)
+}
+
+// GetScore 获取竞争者在全局排行榜中合并后的成绩
+func (a *Aggregator[CompetitorID, Score]) GetScore(competitor CompetitorID) (Score, error) {
+	a.rw.RLock()
+	defer a.rw.RUnlock()
+	return a.board.GetScore(competitor)
+}
+
+// GetTop 获取全局排行榜中排名前 n 的竞争者及其成绩
+func (a *Aggregator[CompetitorID, Score]) GetTop(n int) []Entry[CompetitorID, Score] {
+	a.rw.RLock()
+	defer a.rw.RUnlock()
+	competitors := a.board.GetAllCompetitor()
+	if n < len(competitors) {
+		competitors = competitors[:max(n, 0)]
+	}
+	entries := make([]Entry[CompetitorID, Score], 0, len(competitors))
+	for _, competitor := range competitors {
+		entries = append(entries, Entry[CompetitorID, Score]{Competitor: competitor, Score: a.board.GetScoreDefault(competitor, 0)})
+	}
+	return entries
+}
+
+// GetContributions 获取各节点对竞争者成绩的贡献，键为节点 ID
+func (a *Aggregator[CompetitorID, Score]) GetContributions(competitor CompetitorID) map[string]Score {
+	a.rw.RLock()
+	defer a.rw.RUnlock()
+	var contributions = make(map[string]Score)
+	for node, snapshot := range a.snapshots {
+		if score, exist := snapshot[competitor]; exist {
+			contributions[node] = score
+		}
+	}
+	return contributions
+}
+
+// GetNodeSize 获取特定节点参与聚合的竞争者数量
+func (a *Aggregator[CompetitorID, Score]) GetNodeSize(node string) int {
+	a.rw.RLock()
+	defer a.rw.RUnlock()
+	return len(a.snapshots[node])
+}
+
+// Close 停止定期对账
+func (a *Aggregator[CompetitorID, Score]) Close() {
+	a.rw.Lock()
+	defer a.rw.Unlock()
+	a.closed = true
+	if a.timer != nil {
+		a.timer.Cancel()
+	}
+}
+
+// schedule 调度下一次定期对账
+func (a *Aggregator[CompetitorID, Score]) schedule() {
+	a.rw.Lock()
+	defer a.rw.Unlock()
+	if a.closed {
+		return
+	}
+	a.timer = a.srv.PushDelayedMessage(a.options.interval, func() {
+		a.Reconcile()
+		a.schedule()
+	}, log.String("Ranking", "schedule"))
+}
+
+// rebuild 根据对账结果更新各节点的数据并重建全局排行榜
+func (a *Aggregator[CompetitorID, Score]) rebuild(nodes []cluster.Node, fetched map[string][]Entry[CompetitorID, Score]) {
+	a.rw.Lock()
+	defer a.rw.Unlock()
+	var snapshots = make(map[string]map[CompetitorID]Score, len(nodes))
+	for _, node := range nodes {
+		entries, exist := fetched[node.ID]
+		if !exist {
+			if snapshot, exist := a.snapshots[node.ID]; exist {
+				snapshots[node.ID] = snapshot
+			}
+			continue
+		}
+		snapshot := make(map[CompetitorID]Score, len(entries))
+		for _, entry := range entries {
+			snapshot[entry.Competitor] = entry.Score
+		}
+		snapshots[node.ID] = snapshot
+	}
+	a.snapshots = snapshots
+
+	a.board = a.newBoard()
+	var merged = make(map[CompetitorID]struct{})
+	for _, snapshot := range a.snapshots {
+		for competitor := range snapshot {
+			if _, exist := merged[competitor]; exist {
+				continue
+			}
+			merged[competitor] = struct{}{}
+			a.board.Competitor(competitor, a.merge(competitor))
+		}
+	}
+}
+
+// merge 根据合并策略计算竞争者在所有节点中的成绩，调用方需持有锁
+func (a *Aggregator[CompetitorID, Score]) merge(competitor CompetitorID) Score {
+	var result Score
+	var found bool
+	for _, snapshot := range a.snapshots {
+		score, exist := snapshot[competitor]
+		if !exist {
+			continue
+		}
+		switch {
+		case !found:
+			result = score
+		case a.options.policy == MergePolicySum:
+			result += score
+		case a.options.asc && score < result, !a.options.asc && score > result:
+			result = score
+		}
+		found = true
+	}
+	return result
+}
+
+// newBoard 创建空的全局排行榜
+func (a *Aggregator[CompetitorID, Score]) newBoard() *leaderboard.BinarySearch[CompetitorID, Score] {
+	options := []leaderboard.BinarySearchOption[CompetitorID, Score]{
+		leaderboard.WithBinarySearchCount[CompetitorID, Score](a.options.rankCount),
+	}
+	if a.options.asc {
+		options = append(options, leaderboard.WithBinarySearchASC[CompetitorID, Score]())
+	}
+	return leaderboard.NewBinarySearch[CompetitorID, Score](options...)
+}
@@ -0,0 +1,26 @@
+package ranking
+
+import (
+	"github.com/kercylan98/minotaur/utils/generic"
+)
+
+type (
+	ReconcileEventHandle[CompetitorID comparable, Score generic.Number] func(aggregator *Aggregator[CompetitorID, Score], failed map[string]error)
+)
+
+type aggregatorEvents[CompetitorID comparable, Score generic.Number] struct {
+	reconcileEventHandles []ReconcileEventHandle[CompetitorID, Score]
+}
+
+// RegReconcileEvent 注册对账完成事件，该事件将在全局排行榜重建完成后在服务器的系统分流渠道中执行
+//   - failed 为拉取数据失败的节点及其错误，这些节点将沿用上一次对账的数据
+func (ae *aggregatorEvents[CompetitorID, Score]) RegReconcileEvent(handle ReconcileEventHandle[CompetitorID, Score]) {
+	ae.reconcileEventHandles = append(ae.reconcileEventHandles, handle)
+}
+
+// OnReconcileEvent 对账完成事件
+func (ae *aggregatorEvents[CompetitorID, Score]) OnReconcileEvent(aggregator *Aggregator[CompetitorID, Score], failed map[string]error) {
+	for _, handle := range ae.reconcileEventHandles {
+		handle(aggregator, failed)
+	}
+}
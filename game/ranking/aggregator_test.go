@@ -0,0 +1,68 @@
+package ranking_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/game/ranking"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/cluster"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type nodes []cluster.Node
+
+func (n *nodes) Nodes() []cluster.Node {
+	return *n
+}
+
+func TestAggregator_Reconcile(t *testing.T) {
+	var round atomic.Int32
+	var providers = &nodes{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	var data = map[string][]ranking.Entry[string, int]{
+		"a": {{Competitor: "p1", Score: 10}, {Competitor: "p2", Score: 5}},
+		"b": {{Competitor: "p1", Score: 20}},
+		"c": {{Competitor: "p3", Score: 40}},
+	}
+	var results []string
+	srv := server.New(server.NetworkNone)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		fetcher := ranking.FetcherFunc[string, int](func(ctx context.Context, node cluster.Node) ([]ranking.Entry[string, int], error) {
+			if round.Load() > 0 && node.ID == "b" {
+				return nil, errors.New("unavailable")
+			}
+			return data[node.ID], nil
+		})
+		options := ranking.NewOptions().WithInterval(time.Millisecond * 10).WithMergePolicy(ranking.MergePolicySum)
+		aggregator := ranking.NewAggregator[string, int](srv, providers, fetcher, options)
+		aggregator.RegReconcileEvent(func(aggregator *ranking.Aggregator[string, int], failed map[string]error) {
+			switch round.Add(1) {
+			case 1:
+				results = append(results, fmt.Sprint(aggregator.GetTop(10), aggregator.GetContributions("p1")))
+				// 节点 b 不可用且节点 c 离开集群
+				*providers = nodes{{ID: "a"}, {ID: "b"}}
+				aggregator.Submit("a", "p2", 50)
+				results = append(results, fmt.Sprint(aggregator.GetTop(1)))
+			case 2:
+				rank, _ := aggregator.GetRank("p1")
+				results = append(results, fmt.Sprint(aggregator.GetTop(10), rank, len(failed), aggregator.GetNodeSize("c")))
+				aggregator.Close()
+				srv.Shutdown()
+			}
+		})
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"[{p3 40} {p1 30} {p2 5}] map[a:10 b:20]",
+		"[{p2 50}]",
+		"[{p1 30} {p2 5}] 0 1 0",
+	}
+	if fmt.Sprint(results) != fmt.Sprint(expected) {
+		t.Fatalf("unexpected results: %v", results)
+	}
+}
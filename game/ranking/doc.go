@@ -0,0 +1,3 @@
+// Package ranking 提供了跨服排行榜聚合器，用于将集群中多个服务器节点的排行榜数据合并为全局排行榜，
+// 聚合器将定期与所有节点进行对账，并记录每个节点对竞争者成绩的贡献
+package ranking
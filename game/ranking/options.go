@@ -0,0 +1,88 @@
+package ranking
+
+import "time"
+
+const (
+	DefaultInterval  = time.Minute      // 默认对账间隔
+	DefaultTimeout   = time.Second * 10 // 默认单次对账拉取数据的超时时长
+	DefaultRankCount = 100              // 默认全局排行榜的竞争者数量
+)
+
+const (
+	MergePolicyBest MergePolicy = iota // 取各节点中最优的成绩，适用于竞争者仅存在于单个节点或成绩为历史最高的排行榜
+	MergePolicySum                     // 累加各节点的成绩，适用于竞争者在多个节点均有贡献的排行榜，例如公会贡献
+)
+
+// MergePolicy 同一竞争者在多个节点中存在成绩时的合并策略
+type MergePolicy byte
+
+// NewOptions 创建聚合器选项
+func NewOptions() *Options {
+	return &Options{}
+}
+
+// mergeOptions 合并聚合器选项
+func mergeOptions(options ...*Options) *Options {
+	result := &Options{
+		interval:  DefaultInterval,
+		timeout:   DefaultTimeout,
+		rankCount: DefaultRankCount,
+	}
+	for _, option := range options {
+		if option.interval > 0 {
+			result.interval = option.interval
+		}
+		if option.timeout > 0 {
+			result.timeout = option.timeout
+		}
+		if option.rankCount > 0 {
+			result.rankCount = option.rankCount
+		}
+		if option.asc {
+			result.asc = true
+		}
+		if option.policy != MergePolicyBest {
+			result.policy = option.policy
+		}
+	}
+	return result
+}
+
+// Options 聚合器选项
+type Options struct {
+	interval  time.Duration // 对账间隔
+	timeout   time.Duration // 单次对账拉取数据的超时时长
+	rankCount int           // 全局排行榜的竞争者数量
+	asc       bool          // 是否为升序排行榜
+	policy    MergePolicy   // 成绩合并策略
+}
+
+// WithInterval 设置定期与所有节点对账的间隔，默认为 DefaultInterval
+func (o *Options) WithInterval(interval time.Duration) *Options {
+	o.interval = interval
+	return o
+}
+
+// WithTimeout 设置单次对账从每个节点拉取数据的超时时长，默认为 DefaultTimeout
+func (o *Options) WithTimeout(timeout time.Duration) *Options {
+	o.timeout = timeout
+	return o
+}
+
+// WithRankCount 设置全局排行榜的竞争者数量，默认为 DefaultRankCount
+func (o *Options) WithRankCount(rankCount int) *Options {
+	o.rankCount = rankCount
+	return o
+}
+
+// WithASC 设置全局排行榜为升序，此时 MergePolicyBest 将取各节点中最小的成绩，默认为降序
+func (o *Options) WithASC() *Options {
+	o.asc = true
+	return o
+}
+
+// WithMergePolicy 设置同一竞争者在多个节点中存在成绩时的合并策略，默认为 MergePolicyBest
+func (o *Options) WithMergePolicy(policy MergePolicy) *Options {
+	o.policy = policy
+	return o
+}
@@ -0,0 +1,207 @@
+package space
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/aoi"
+	"github.com/kercylan98/minotaur/utils/collection"
+	"github.com/kercylan98/minotaur/utils/generic"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SceneEntity 场景中的实体
+type SceneEntity[EID generic.Basic, PosType generic.SignedNumber] interface {
+	aoi.TwoDimensionalEntity[EID, PosType]
+	// GetConn 获取实体对应的玩家连接，非玩家实体应当返回 nil
+	GetConn() *server.Conn
+}
+
+// newScene 创建场景，场景将拥有独立的分流渠道，该分流渠道将由场景的生产者连接保持
+func newScene[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]](manager *SceneManager[SceneID, EID, PosType, E], id SceneID, options *SceneOptions) *Scene[SceneID, EID, PosType, E] {
+	scene := &Scene[SceneID, EID, PosType, E]{
+		manager:  manager,
+		id:       id,
+		shunt:    fmt.Sprintf("scene:%v", id),
+		producer: server.NewOfflineConn(manager.srv),
+		aoi:      aoi.NewTwoDimensional[EID, PosType, E](options.width, options.height, options.areaWidth, options.areaHeight),
+		entities: make(map[EID]E),
+		options:  options,
+	}
+	manager.srv.UseShunt(scene.producer, scene.shunt)
+	if options.tickInterval > 0 {
+		scene.ticker = time.NewTicker(options.tickInterval)
+		scene.tickStop = make(chan struct{})
+		go scene.tick()
+	}
+	return scene
+}
+
+// Scene 场景是由 SceneManager 管理的基本单元，例如游戏中的一张地图
+//   - 每个场景拥有独立的分流渠道、实体注册表及 AOI 实例，进入场景的玩家连接的数据包将在场景的分流渠道中处理
+//   - 通过 SceneOptions.WithTick 开启帧循环后，帧事件将在场景的分流渠道中执行，与场景内玩家的数据包处理串行
+type Scene[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]] struct {
+	manager  *SceneManager[SceneID, EID, PosType, E]
+	id       SceneID
+	shunt    string                               // 场景的分流渠道名称
+	producer *server.Conn                         // 保持场景分流渠道的离线连接
+	aoi      *aoi.TwoDimensional[EID, PosType, E] // 场景的 AOI 实例
+	rw       sync.RWMutex
+	entities map[EID]E
+	options  *SceneOptions
+	ticker   *time.Ticker
+	tickStop chan struct{}
+	ticking  atomic.Bool // 上一帧是否尚未执行完毕
+	frame    atomic.Int64
+}
+
+// GetID 获取场景 ID
+func (s *Scene[SceneID, EID, PosType, E]) GetID() SceneID {
+	return s.id
+}
+
+// GetShunt 获取场景所使用的分流渠道名称
+func (s *Scene[SceneID, EID, PosType, E]) GetShunt() string {
+	return s.shunt
+}
+
+// GetAOI 获取场景的 AOI 实例
+func (s *Scene[SceneID, EID, PosType, E]) GetAOI() *aoi.TwoDimensional[EID, PosType, E] {
+	return s.aoi
+}
+
+// GetFrame 获取场景已执行的帧数
+func (s *Scene[SceneID, EID, PosType, E]) GetFrame() int64 {
+	return s.frame.Load()
+}
+
+// GetEntity 获取场景中的特定实体，当实体不存在时 exist 为 false
+func (s *Scene[SceneID, EID, PosType, E]) GetEntity(id EID) (entity E, exist bool) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	entity, exist = s.entities[id]
+	return
+}
+
+// GetEntities 获取场景中的所有实体
+//   - 返回值的 map 为拷贝对象，可安全的对其进行增删等操作
+func (s *Scene[SceneID, EID, PosType, E]) GetEntities() map[EID]E {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	return collection.CloneMap(s.entities)
+}
+
+// GetEntityCount 获取场景中的实体数量
+func (s *Scene[SceneID, EID, PosType, E]) GetEntityCount() int {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	return len(s.entities)
+}
+
+// HasEntity 判断实体是否在场景中
+func (s *Scene[SceneID, EID, PosType, E]) HasEntity(id EID) bool {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	_, exist := s.entities[id]
+	return exist
+}
+
+// IsFull 判断场景是否已满
+func (s *Scene[SceneID, EID, PosType, E]) IsFull() bool {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	return s.options.maxEntityCount > 0 && len(s.entities) >= s.options.maxEntityCount
+}
+
+// Broadcast 向场景中所有玩家实体的连接发送数据包
+//   - conditions: 当任意条件返回 false 时将跳过该实体
+func (s *Scene[SceneID, EID, PosType, E]) Broadcast(packet []byte, conditions ...func(entity E) bool) {
+	s.rw.RLock()
+	entities := collection.CloneMap(s.entities)
+	s.rw.RUnlock()
+	for _, entity := range entities {
+		conn := entity.GetConn()
+		if conn == nil || !s.match(entity, conditions) {
+			continue
+		}
+		conn.Write(packet)
+	}
+}
+
+// BroadcastFocus 向特定实体视野范围内的所有玩家实体的连接发送数据包
+func (s *Scene[SceneID, EID, PosType, E]) BroadcastFocus(id EID, packet []byte, conditions ...func(entity E) bool) {
+	for _, entity := range s.aoi.GetFocus(id) {
+		conn := entity.GetConn()
+		if conn == nil || !s.match(entity, conditions) {
+			continue
+		}
+		conn.Write(packet)
+	}
+}
+
+// PushMessage 向场景的分流渠道中推送消息，caller 将与场景内玩家的数据包处理串行执行
+func (s *Scene[SceneID, EID, PosType, E]) PushMessage(caller func()) {
+	s.manager.srv.PushShuntMessage(s.producer, caller)
+}
+
+// match 检查实体是否满足所有条件
+func (s *Scene[SceneID, EID, PosType, E]) match(entity E, conditions []func(entity E) bool) bool {
+	for _, condition := range conditions {
+		if !condition(entity) {
+			return false
+		}
+	}
+	return true
+}
+
+// add 将实体加入场景，当场景已满时返回 ErrSceneFull
+func (s *Scene[SceneID, EID, PosType, E]) add(entity E) error {
+	s.rw.Lock()
+	if s.options.maxEntityCount > 0 && len(s.entities) >= s.options.maxEntityCount {
+		s.rw.Unlock()
+		return ErrSceneFull
+	}
+	s.entities[entity.GetTwoDimensionalEntityID()] = entity
+	s.rw.Unlock()
+	s.aoi.AddEntity(entity)
+	if conn := entity.GetConn(); conn != nil {
+		s.manager.srv.UseShunt(conn, s.shunt)
+	}
+	return nil
+}
+
+// remove 将实体移出场景
+func (s *Scene[SceneID, EID, PosType, E]) remove(entity E) {
+	s.rw.Lock()
+	delete(s.entities, entity.GetTwoDimensionalEntityID())
+	s.rw.Unlock()
+	s.aoi.DeleteEntity(entity)
+}
+
+// tick 按照帧间隔向场景的分流渠道中推送帧消息，当上一帧尚未执行完毕时将跳过本帧
+func (s *Scene[SceneID, EID, PosType, E]) tick() {
+	for {
+		select {
+		case <-s.ticker.C:
+			if !s.ticking.CompareAndSwap(false, true) {
+				continue
+			}
+			s.PushMessage(func() {
+				defer s.ticking.Store(false)
+				s.manager.OnSceneTickEvent(s, s.frame.Add(1))
+			})
+		case <-s.tickStop:
+			return
+		}
+	}
+}
+
+// release 停止场景的帧循环并释放场景的分流渠道
+func (s *Scene[SceneID, EID, PosType, E]) release() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+		close(s.tickStop)
+	}
+	s.producer.Close()
+}
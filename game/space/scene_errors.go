@@ -0,0 +1,16 @@
+package space
+
+import "errors"
+
+var (
+	// ErrSceneExist 场景已存在
+	ErrSceneExist = errors.New("scene already exists")
+	// ErrSceneNotExist 场景不存在
+	ErrSceneNotExist = errors.New("scene not exist")
+	// ErrSceneFull 场景已满
+	ErrSceneFull = errors.New("scene is full")
+	// ErrNotInScene 实体不在任何场景中
+	ErrNotInScene = errors.New("not in scene")
+	// ErrAlreadyInScene 实体已经在场景中
+	ErrAlreadyInScene = errors.New("already in scene")
+)
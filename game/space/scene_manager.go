@@ -0,0 +1,219 @@
+package space
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/collection"
+	"github.com/kercylan98/minotaur/utils/generic"
+	"sync"
+)
+
+// NewSceneManager 创建场景管理器 SceneManager 的实例
+//   - 玩家连接断开时，其对应的实体将自动退出所在场景
+func NewSceneManager[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]](srv *server.Server) *SceneManager[SceneID, EID, PosType, E] {
+	sm := &SceneManager[SceneID, EID, PosType, E]{
+		sceneManagerEvents: new(sceneManagerEvents[SceneID, EID, PosType, E]),
+		srv:                srv,
+		scenes:             make(map[SceneID]*Scene[SceneID, EID, PosType, E]),
+		entityScene:        make(map[EID]*Scene[SceneID, EID, PosType, E]),
+		connEntity:         make(map[string]EID),
+	}
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		sm.rw.RLock()
+		id, exist := sm.connEntity[conn.GetID()]
+		sm.rw.RUnlock()
+		if exist {
+			_ = sm.Leave(id)
+		}
+	})
+	return sm
+}
+
+// SceneManager 场景管理器是多地图游戏的基础，用于管理场景的创建、销毁以及实体在场景间的进入、退出和转移
+//   - 同一实体同一时间仅能存在于一个场景中
+//   - 场景依赖服务器的分流渠道，应当在服务器启动后创建场景
+//   - 该实例是线程安全的，实体的进入、退出和转移将被串行执行，事件将在发起操作的协程中同步触发，事件处理函数中不应再次进入、退出或转移实体
+type SceneManager[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]] struct {
+	*sceneManagerEvents[SceneID, EID, PosType, E]
+	srv         *server.Server
+	lock        sync.Mutex // 串行化场景及实体的变更操作
+	rw          sync.RWMutex
+	scenes      map[SceneID]*Scene[SceneID, EID, PosType, E]
+	entityScene map[EID]*Scene[SceneID, EID, PosType, E] // 实体 ID -> 所在场景
+	connEntity  map[string]EID                           // 连接 ID -> 实体 ID
+}
+
+// CreateScene 创建场景，当场景已存在时返回 ErrSceneExist
+func (sm *SceneManager[SceneID, EID, PosType, E]) CreateScene(id SceneID, options ...*SceneOptions) (*Scene[SceneID, EID, PosType, E], error) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	sm.rw.RLock()
+	_, exist := sm.scenes[id]
+	sm.rw.RUnlock()
+	if exist {
+		return nil, ErrSceneExist
+	}
+	scene := newScene(sm, id, mergeSceneOptions(options...))
+	sm.rw.Lock()
+	sm.scenes[id] = scene
+	sm.rw.Unlock()
+	sm.OnSceneCreateEvent(scene)
+	return scene, nil
+}
+
+// DestroyScene 销毁场景，场景中的所有实体将退出场景，场景的帧循环将停止并释放其分流渠道
+//   - 场景中的玩家连接将保持在该场景的分流渠道中，直至进入其他场景或断开连接
+func (sm *SceneManager[SceneID, EID, PosType, E]) DestroyScene(id SceneID) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	sm.rw.Lock()
+	scene, exist := sm.scenes[id]
+	if !exist {
+		sm.rw.Unlock()
+		return
+	}
+	delete(sm.scenes, id)
+	sm.rw.Unlock()
+
+	for _, entity := range scene.GetEntities() {
+		sm.leave(scene, entity)
+	}
+	scene.release()
+	sm.OnSceneDestroyEvent(scene)
+}
+
+// GetScene 获取特定场景，当场景不存在时将返回 nil
+func (sm *SceneManager[SceneID, EID, PosType, E]) GetScene(id SceneID) *Scene[SceneID, EID, PosType, E] {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	return sm.scenes[id]
+}
+
+// GetScenes 获取包含所有场景 ID 到对应场景的映射
+//   - 返回值的 map 为拷贝对象，可安全的对其进行增删等操作
+func (sm *SceneManager[SceneID, EID, PosType, E]) GetScenes() map[SceneID]*Scene[SceneID, EID, PosType, E] {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	return collection.CloneMap(sm.scenes)
+}
+
+// GetSceneCount 获取场景数量
+func (sm *SceneManager[SceneID, EID, PosType, E]) GetSceneCount() int {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	return len(sm.scenes)
+}
+
+// GetEntityScene 获取实体所在的场景，当实体不在任何场景中时将返回 nil
+func (sm *SceneManager[SceneID, EID, PosType, E]) GetEntityScene(id EID) *Scene[SceneID, EID, PosType, E] {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	return sm.entityScene[id]
+}
+
+// Enter 使实体进入特定场景，玩家实体的连接将切换至场景的分流渠道
+//   - 当场景不存在时返回 ErrSceneNotExist
+//   - 当实体已经在任一场景中时返回 ErrAlreadyInScene，此时应当使用 Transfer 转移场景
+//   - 当场景已满时返回 ErrSceneFull
+func (sm *SceneManager[SceneID, EID, PosType, E]) Enter(sceneId SceneID, entity E) error {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	sm.rw.RLock()
+	scene, exist := sm.scenes[sceneId]
+	_, inScene := sm.entityScene[entity.GetTwoDimensionalEntityID()]
+	sm.rw.RUnlock()
+	if !exist {
+		return ErrSceneNotExist
+	}
+	if inScene {
+		return ErrAlreadyInScene
+	}
+	return sm.enter(scene, entity)
+}
+
+// Leave 使实体退出所在场景，当实体不在任何场景中时返回 ErrNotInScene
+//   - 玩家实体的连接将保持在该场景的分流渠道中，直至进入其他场景或断开连接
+func (sm *SceneManager[SceneID, EID, PosType, E]) Leave(id EID) error {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	sm.rw.RLock()
+	scene, exist := sm.entityScene[id]
+	sm.rw.RUnlock()
+	if !exist {
+		return ErrNotInScene
+	}
+	entity, _ := scene.GetEntity(id)
+	sm.leave(scene, entity)
+	return nil
+}
+
+// Transfer 将实体从所在场景原子的转移至目标场景，转移过程中不会有其他实体的进入、退出或转移操作穿插执行
+//   - 转移将按照 退出原场景事件 -> handoff 状态交接 -> 进入目标场景事件 -> 转移事件 的顺序执行
+//   - handoff 可用于在场景间交接实体的状态，例如保存原场景数据、设置在目标场景中的出生点等，可为 nil
+//   - 当 handoff 返回错误或目标场景已满时，实体将重新进入原场景并返回该错误
+//   - 当实体不在任何场景中时返回 ErrNotInScene，当目标场景不存在时返回 ErrSceneNotExist，当实体已经在目标场景中时返回 ErrAlreadyInScene
+func (sm *SceneManager[SceneID, EID, PosType, E]) Transfer(id EID, to SceneID, handoff func(from, to *Scene[SceneID, EID, PosType, E], entity E) error) error {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	sm.rw.RLock()
+	from, inScene := sm.entityScene[id]
+	target, exist := sm.scenes[to]
+	sm.rw.RUnlock()
+	switch {
+	case !inScene:
+		return ErrNotInScene
+	case !exist:
+		return ErrSceneNotExist
+	case from == target:
+		return ErrAlreadyInScene
+	case target.IsFull():
+		return ErrSceneFull
+	}
+
+	entity, _ := from.GetEntity(id)
+	sm.leave(from, entity)
+	if handoff != nil {
+		if err := handoff(from, target, entity); err != nil {
+			sm.rollback(from, entity)
+			return err
+		}
+	}
+	if err := sm.enter(target, entity); err != nil {
+		sm.rollback(from, entity)
+		return err
+	}
+	sm.OnSceneTransferEvent(from, target, entity)
+	return nil
+}
+
+// enter 将实体加入场景并记录索引，成功后触发进入场景事件
+func (sm *SceneManager[SceneID, EID, PosType, E]) enter(scene *Scene[SceneID, EID, PosType, E], entity E) error {
+	if err := scene.add(entity); err != nil {
+		return err
+	}
+	id := entity.GetTwoDimensionalEntityID()
+	sm.rw.Lock()
+	sm.entityScene[id] = scene
+	if conn := entity.GetConn(); conn != nil {
+		sm.connEntity[conn.GetID()] = id
+	}
+	sm.rw.Unlock()
+	sm.OnSceneEnterEvent(scene, entity)
+	return nil
+}
+
+// leave 将实体移出场景并移除索引，随后触发退出场景事件
+func (sm *SceneManager[SceneID, EID, PosType, E]) leave(scene *Scene[SceneID, EID, PosType, E], entity E) {
+	scene.remove(entity)
+	sm.rw.Lock()
+	delete(sm.entityScene, entity.GetTwoDimensionalEntityID())
+	if conn := entity.GetConn(); conn != nil {
+		delete(sm.connEntity, conn.GetID())
+	}
+	sm.rw.Unlock()
+	sm.OnSceneExitEvent(scene, entity)
+}
+
+// rollback 在转移失败时使实体重新进入原场景，原场景的容量在实体退出时已被释放，因此不会失败
+func (sm *SceneManager[SceneID, EID, PosType, E]) rollback(from *Scene[SceneID, EID, PosType, E], entity E) {
+	_ = sm.enter(from, entity)
+}
@@ -0,0 +1,96 @@
+package space
+
+import "github.com/kercylan98/minotaur/utils/generic"
+
+type (
+	SceneCreateEventHandle[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]]   func(scene *Scene[SceneID, EID, PosType, E])
+	SceneDestroyEventHandle[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]]  func(scene *Scene[SceneID, EID, PosType, E])
+	SceneEnterEventHandle[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]]    func(scene *Scene[SceneID, EID, PosType, E], entity E)
+	SceneExitEventHandle[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]]     func(scene *Scene[SceneID, EID, PosType, E], entity E)
+	SceneTransferEventHandle[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]] func(from, to *Scene[SceneID, EID, PosType, E], entity E)
+	SceneTickEventHandle[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]]     func(scene *Scene[SceneID, EID, PosType, E], frame int64)
+)
+
+type sceneManagerEvents[SceneID comparable, EID generic.Basic, PosType generic.SignedNumber, E SceneEntity[EID, PosType]] struct {
+	sceneCreateEventHandles   []SceneCreateEventHandle[SceneID, EID, PosType, E]
+	sceneDestroyEventHandles  []SceneDestroyEventHandle[SceneID, EID, PosType, E]
+	sceneEnterEventHandles    []SceneEnterEventHandle[SceneID, EID, PosType, E]
+	sceneExitEventHandles     []SceneExitEventHandle[SceneID, EID, PosType, E]
+	sceneTransferEventHandles []SceneTransferEventHandle[SceneID, EID, PosType, E]
+	sceneTickEventHandles     []SceneTickEventHandle[SceneID, EID, PosType, E]
+}
+
+// RegSceneCreateEvent 注册场景创建事件
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) RegSceneCreateEvent(handle SceneCreateEventHandle[SceneID, EID, PosType, E]) {
+	sme.sceneCreateEventHandles = append(sme.sceneCreateEventHandles, handle)
+}
+
+// OnSceneCreateEvent 场景创建事件
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) OnSceneCreateEvent(scene *Scene[SceneID, EID, PosType, E]) {
+	for _, handle := range sme.sceneCreateEventHandles {
+		handle(scene)
+	}
+}
+
+// RegSceneDestroyEvent 注册场景销毁事件，当触发事件时，场景中的实体均已退出场景
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) RegSceneDestroyEvent(handle SceneDestroyEventHandle[SceneID, EID, PosType, E]) {
+	sme.sceneDestroyEventHandles = append(sme.sceneDestroyEventHandles, handle)
+}
+
+// OnSceneDestroyEvent 场景销毁事件
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) OnSceneDestroyEvent(scene *Scene[SceneID, EID, PosType, E]) {
+	for _, handle := range sme.sceneDestroyEventHandles {
+		handle(scene)
+	}
+}
+
+// RegSceneEnterEvent 注册实体进入场景事件，当触发事件时，实体已经加入场景的实体注册表及 AOI 实例
+//   - 通过 SceneManager.Transfer 转移场景时，该事件将在状态交接完成后触发
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) RegSceneEnterEvent(handle SceneEnterEventHandle[SceneID, EID, PosType, E]) {
+	sme.sceneEnterEventHandles = append(sme.sceneEnterEventHandles, handle)
+}
+
+// OnSceneEnterEvent 实体进入场景事件
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) OnSceneEnterEvent(scene *Scene[SceneID, EID, PosType, E], entity E) {
+	for _, handle := range sme.sceneEnterEventHandles {
+		handle(scene, entity)
+	}
+}
+
+// RegSceneExitEvent 注册实体退出场景事件，当触发事件时，实体已经从场景的实体注册表及 AOI 实例中移除
+//   - 通过 SceneManager.Transfer 转移场景时，该事件将在状态交接之前触发
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) RegSceneExitEvent(handle SceneExitEventHandle[SceneID, EID, PosType, E]) {
+	sme.sceneExitEventHandles = append(sme.sceneExitEventHandles, handle)
+}
+
+// OnSceneExitEvent 实体退出场景事件
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) OnSceneExitEvent(scene *Scene[SceneID, EID, PosType, E], entity E) {
+	for _, handle := range sme.sceneExitEventHandles {
+		handle(scene, entity)
+	}
+}
+
+// RegSceneTransferEvent 注册实体转移场景事件，该事件将在实体进入目标场景事件之后触发
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) RegSceneTransferEvent(handle SceneTransferEventHandle[SceneID, EID, PosType, E]) {
+	sme.sceneTransferEventHandles = append(sme.sceneTransferEventHandles, handle)
+}
+
+// OnSceneTransferEvent 实体转移场景事件
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) OnSceneTransferEvent(from, to *Scene[SceneID, EID, PosType, E], entity E) {
+	for _, handle := range sme.sceneTransferEventHandles {
+		handle(from, to, entity)
+	}
+}
+
+// RegSceneTickEvent 注册场景帧事件，该事件将在场景的分流渠道中执行
+//   - 仅在通过 SceneOptions.WithTick 开启帧循环的场景中触发
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) RegSceneTickEvent(handle SceneTickEventHandle[SceneID, EID, PosType, E]) {
+	sme.sceneTickEventHandles = append(sme.sceneTickEventHandles, handle)
+}
+
+// OnSceneTickEvent 场景帧事件
+func (sme *sceneManagerEvents[SceneID, EID, PosType, E]) OnSceneTickEvent(scene *Scene[SceneID, EID, PosType, E], frame int64) {
+	for _, handle := range sme.sceneTickEventHandles {
+		handle(scene, frame)
+	}
+}
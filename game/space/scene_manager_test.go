@@ -0,0 +1,89 @@
+package space_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/game/space"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"testing"
+	"time"
+)
+
+type SceneEntity struct {
+	id  int64
+	pos geometry.Point[int]
+}
+
+func (e *SceneEntity) GetTwoDimensionalEntityID() int64 {
+	return e.id
+}
+
+func (e *SceneEntity) GetVision() float64 {
+	return 10
+}
+
+func (e *SceneEntity) GetPosition() geometry.Point[int] {
+	return e.pos
+}
+
+func (e *SceneEntity) GetConn() *server.Conn {
+	return nil
+}
+
+func TestSceneManager_Transfer(t *testing.T) {
+	var steps []string
+	var frame int64
+	srv := server.New(server.NetworkNone)
+	sm := space.NewSceneManager[string, int64, int, *SceneEntity](srv)
+	sm.RegSceneEnterEvent(func(scene *space.Scene[string, int64, int, *SceneEntity], entity *SceneEntity) {
+		steps = append(steps, "enter:"+scene.GetID())
+	})
+	sm.RegSceneExitEvent(func(scene *space.Scene[string, int64, int, *SceneEntity], entity *SceneEntity) {
+		steps = append(steps, "exit:"+scene.GetID())
+	})
+	sm.RegSceneTickEvent(func(scene *space.Scene[string, int64, int, *SceneEntity], f int64) {
+		if frame == 0 {
+			frame = f
+			srv.Shutdown()
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		_, _ = sm.CreateScene("town")
+		_, _ = sm.CreateScene("dungeon", space.NewSceneOptions().WithMaxEntityCount(1).WithTick(time.Millisecond*10))
+		entity := &SceneEntity{id: 1, pos: geometry.NewPoint(1, 1)}
+		if err := sm.Enter("town", entity); err != nil {
+			t.Error(err)
+		}
+		failed := errors.New("handoff failed")
+		if err := sm.Transfer(1, "dungeon", func(from, to *space.Scene[string, int64, int, *SceneEntity], entity *SceneEntity) error {
+			return failed
+		}); !errors.Is(err, failed) || sm.GetEntityScene(1).GetID() != "town" {
+			t.Errorf("transfer should be rolled back, err: %v", err)
+		}
+		if err := sm.Transfer(1, "dungeon", func(from, to *space.Scene[string, int64, int, *SceneEntity], entity *SceneEntity) error {
+			steps = append(steps, "handoff")
+			entity.pos = geometry.NewPoint(5, 5)
+			return nil
+		}); err != nil {
+			t.Error(err)
+		}
+		if err := sm.Enter("dungeon", &SceneEntity{id: 2}); !errors.Is(err, space.ErrSceneFull) {
+			t.Errorf("dungeon should be full, err: %v", err)
+		}
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[enter:town exit:town enter:town exit:town handoff enter:dungeon]"
+	if fmt.Sprint(steps) != expected {
+		t.Fatalf("unexpected steps: %v", steps)
+	}
+	if scene := sm.GetEntityScene(1); scene == nil || scene.GetID() != "dungeon" || sm.GetScene("town").HasEntity(1) {
+		t.Fatalf("entity should be in dungeon")
+	}
+	if frame != 1 {
+		t.Fatalf("unexpected frame: %d", frame)
+	}
+}
@@ -0,0 +1,72 @@
+package space
+
+import "time"
+
+const (
+	DefaultSceneWidth      = 1000 // 默认场景宽度
+	DefaultSceneHeight     = 1000 // 默认场景高度
+	DefaultSceneAreaWidth  = 100  // 默认场景 AOI 区域宽度
+	DefaultSceneAreaHeight = 100  // 默认场景 AOI 区域高度
+)
+
+// NewSceneOptions 创建场景选项
+func NewSceneOptions() *SceneOptions {
+	return &SceneOptions{}
+}
+
+// mergeSceneOptions 合并场景选项
+func mergeSceneOptions(options ...*SceneOptions) *SceneOptions {
+	result := &SceneOptions{
+		width:      DefaultSceneWidth,
+		height:     DefaultSceneHeight,
+		areaWidth:  DefaultSceneAreaWidth,
+		areaHeight: DefaultSceneAreaHeight,
+	}
+	for _, option := range options {
+		if option.width > 0 && option.height > 0 {
+			result.width, result.height = option.width, option.height
+		}
+		if option.areaWidth > 0 && option.areaHeight > 0 {
+			result.areaWidth, result.areaHeight = option.areaWidth, option.areaHeight
+		}
+		if option.maxEntityCount > 0 {
+			result.maxEntityCount = option.maxEntityCount
+		}
+		if option.tickInterval > 0 {
+			result.tickInterval = option.tickInterval
+		}
+	}
+	return result
+}
+
+// SceneOptions 场景选项
+type SceneOptions struct {
+	width, height         int           // 场景尺寸
+	areaWidth, areaHeight int           // AOI 区域尺寸
+	maxEntityCount        int           // 场景最大实体数量
+	tickInterval          time.Duration // 场景帧间隔
+}
+
+// WithSize 设置场景的尺寸，默认为 DefaultSceneWidth * DefaultSceneHeight
+func (so *SceneOptions) WithSize(width, height int) *SceneOptions {
+	so.width, so.height = width, height
+	return so
+}
+
+// WithAreaSize 设置场景 AOI 区域的尺寸，默认为 DefaultSceneAreaWidth * DefaultSceneAreaHeight
+func (so *SceneOptions) WithAreaSize(width, height int) *SceneOptions {
+	so.areaWidth, so.areaHeight = width, height
+	return so
+}
+
+// WithMaxEntityCount 设置场景最大实体数量，默认不限制
+func (so *SceneOptions) WithMaxEntityCount(maxEntityCount int) *SceneOptions {
+	so.maxEntityCount = maxEntityCount
+	return so
+}
+
+// WithTick 设置场景的帧间隔，设置后场景将按照该间隔在场景的分流渠道中触发帧事件，默认不开启
+func (so *SceneOptions) WithTick(interval time.Duration) *SceneOptions {
+	so.tickInterval = interval
+	return so
+}
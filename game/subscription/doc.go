@@ -0,0 +1,2 @@
+// Package subscription 提供了基于主题的订阅推送管理器，适用于拍卖物品、公会事件、好友状态等需要向关注者推送变更的场景
+package subscription
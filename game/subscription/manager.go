@@ -0,0 +1,177 @@
+package subscription
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/collection"
+	"sync"
+)
+
+// NewManager 创建订阅推送管理器 Manager 的实例
+func NewManager[Topic comparable]() *Manager[Topic] {
+	return &Manager[Topic]{
+		managerEvents: new(managerEvents[Topic]),
+		topics:        make(map[Topic]map[string]*server.Conn),
+		conns:         make(map[string]map[Topic]struct{}),
+	}
+}
+
+// Manager 订阅推送管理器，玩家连接可以订阅任意主题，模块发布的更新将被推送给该主题的所有订阅者
+//   - 通过 Attach 绑定服务器后，连接断开时将自动取消其所有订阅
+//   - 订阅者以连接 ID 区分，同一连接重复订阅同一主题仅会生效一次
+//   - 该实例是线程安全的，事件将在变更订阅的协程中同步触发，事件处理函数中不应再次变更订阅
+type Manager[Topic comparable] struct {
+	*managerEvents[Topic]
+	rw     sync.RWMutex
+	topics map[Topic]map[string]*server.Conn // 主题 -> 连接 ID -> 连接
+	conns  map[string]map[Topic]struct{}     // 连接 ID -> 订阅的主题
+}
+
+// Attach 将管理器绑定到服务器，连接断开时将自动取消其所有订阅
+func (sm *Manager[Topic]) Attach(srv *server.Server) *Manager[Topic] {
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		sm.UnsubscribeAll(conn)
+	})
+	return sm
+}
+
+// Subscribe 使连接订阅特定主题
+func (sm *Manager[Topic]) Subscribe(conn *server.Conn, topics ...Topic) {
+	if len(topics) == 0 {
+		return
+	}
+	var subscribed, activated []Topic
+	sm.rw.Lock()
+	id := conn.GetID()
+	owned, exist := sm.conns[id]
+	if !exist {
+		owned = make(map[Topic]struct{})
+		sm.conns[id] = owned
+	}
+	for _, topic := range topics {
+		subscribers, exist := sm.topics[topic]
+		if !exist {
+			subscribers = make(map[string]*server.Conn)
+			sm.topics[topic] = subscribers
+			activated = append(activated, topic)
+		}
+		if _, exist = subscribers[id]; !exist {
+			subscribed = append(subscribed, topic)
+		}
+		subscribers[id] = conn
+		owned[topic] = struct{}{}
+	}
+	sm.rw.Unlock()
+
+	for _, topic := range activated {
+		sm.OnTopicActiveEvent(sm, topic)
+	}
+	for _, topic := range subscribed {
+		sm.OnSubscribeEvent(sm, conn, topic)
+	}
+}
+
+// Unsubscribe 使连接取消订阅特定主题
+func (sm *Manager[Topic]) Unsubscribe(conn *server.Conn, topics ...Topic) {
+	sm.rw.Lock()
+	unsubscribed, deactivated := sm.unsubscribe(conn.GetID(), topics)
+	sm.rw.Unlock()
+	sm.notifyUnsubscribe(conn, unsubscribed, deactivated)
+}
+
+// UnsubscribeAll 使连接取消订阅所有主题
+func (sm *Manager[Topic]) UnsubscribeAll(conn *server.Conn) {
+	sm.rw.Lock()
+	unsubscribed, deactivated := sm.unsubscribe(conn.GetID(), collection.ConvertMapKeysToSlice(sm.conns[conn.GetID()]))
+	sm.rw.Unlock()
+	sm.notifyUnsubscribe(conn, unsubscribed, deactivated)
+}
+
+// Publish 向主题的所有订阅者推送数据包，返回被推送的订阅者数量
+func (sm *Manager[Topic]) Publish(topic Topic, packet []byte) int {
+	return sm.PublishFilter(topic, packet, nil)
+}
+
+// PublishFilter 向主题中满足 filter 的订阅者推送数据包，返回被推送的订阅者数量，filter 为 nil 时将推送给所有订阅者
+//   - 推送通过 server.Conn.Write 异步写入，期间不会持有管理器的锁
+func (sm *Manager[Topic]) PublishFilter(topic Topic, packet []byte, filter func(conn *server.Conn) bool) int {
+	var count int
+	for _, conn := range sm.GetSubscribers(topic) {
+		if filter != nil && !filter(conn) {
+			continue
+		}
+		conn.Write(packet)
+		count++
+	}
+	return count
+}
+
+// GetSubscribers 获取主题的所有订阅者
+func (sm *Manager[Topic]) GetSubscribers(topic Topic) []*server.Conn {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	return collection.ConvertMapValuesToSlice(sm.topics[topic])
+}
+
+// GetSubscriberCount 获取主题的订阅者数量
+func (sm *Manager[Topic]) GetSubscriberCount(topic Topic) int {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	return len(sm.topics[topic])
+}
+
+// GetTopics 获取连接订阅的所有主题
+func (sm *Manager[Topic]) GetTopics(conn *server.Conn) []Topic {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	return collection.ConvertMapKeysToSlice(sm.conns[conn.GetID()])
+}
+
+// GetActiveTopics 获取所有存在订阅者的主题
+func (sm *Manager[Topic]) GetActiveTopics() []Topic {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	return collection.ConvertMapKeysToSlice(sm.topics)
+}
+
+// IsSubscribed 检查连接是否订阅了特定主题
+func (sm *Manager[Topic]) IsSubscribed(conn *server.Conn, topic Topic) bool {
+	sm.rw.RLock()
+	defer sm.rw.RUnlock()
+	_, exist := sm.conns[conn.GetID()][topic]
+	return exist
+}
+
+// unsubscribe 取消连接对主题的订阅，返回被取消订阅的主题及因此失去所有订阅者的主题，调用时应持有写锁
+func (sm *Manager[Topic]) unsubscribe(id string, topics []Topic) (unsubscribed, deactivated []Topic) {
+	owned, exist := sm.conns[id]
+	if !exist {
+		return
+	}
+	for _, topic := range topics {
+		if _, exist = owned[topic]; !exist {
+			continue
+		}
+		delete(owned, topic)
+		unsubscribed = append(unsubscribed, topic)
+		subscribers := sm.topics[topic]
+		delete(subscribers, id)
+		if len(subscribers) == 0 {
+			delete(sm.topics, topic)
+			deactivated = append(deactivated, topic)
+		}
+	}
+	if len(owned) == 0 {
+		delete(sm.conns, id)
+	}
+	return
+}
+
+// notifyUnsubscribe 触发取消订阅及主题不活跃事件
+func (sm *Manager[Topic]) notifyUnsubscribe(conn *server.Conn, unsubscribed, deactivated []Topic) {
+	for _, topic := range unsubscribed {
+		sm.OnUnsubscribeEvent(sm, conn, topic)
+	}
+	for _, topic := range deactivated {
+		sm.OnTopicInactiveEvent(sm, topic)
+	}
+}
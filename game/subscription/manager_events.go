@@ -0,0 +1,65 @@
+package subscription
+
+import "github.com/kercylan98/minotaur/server"
+
+type (
+	TopicActiveEventHandle[Topic comparable]   func(manager *Manager[Topic], topic Topic)
+	TopicInactiveEventHandle[Topic comparable] func(manager *Manager[Topic], topic Topic)
+	SubscribeEventHandle[Topic comparable]     func(manager *Manager[Topic], conn *server.Conn, topic Topic)
+	UnsubscribeEventHandle[Topic comparable]   func(manager *Manager[Topic], conn *server.Conn, topic Topic)
+)
+
+type managerEvents[Topic comparable] struct {
+	topicActiveEventHandles   []TopicActiveEventHandle[Topic]
+	topicInactiveEventHandles []TopicInactiveEventHandle[Topic]
+	subscribeEventHandles     []SubscribeEventHandle[Topic]
+	unsubscribeEventHandles   []UnsubscribeEventHandle[Topic]
+}
+
+// RegTopicActiveEvent 注册主题活跃事件，当主题出现第一个订阅者时触发，可用于开始生产该主题的更新
+func (me *managerEvents[Topic]) RegTopicActiveEvent(handle TopicActiveEventHandle[Topic]) {
+	me.topicActiveEventHandles = append(me.topicActiveEventHandles, handle)
+}
+
+// OnTopicActiveEvent 主题活跃事件
+func (me *managerEvents[Topic]) OnTopicActiveEvent(manager *Manager[Topic], topic Topic) {
+	for _, handle := range me.topicActiveEventHandles {
+		handle(manager, topic)
+	}
+}
+
+// RegTopicInactiveEvent 注册主题不活跃事件，当主题的最后一个订阅者取消订阅时触发，可用于停止生产该主题的更新
+func (me *managerEvents[Topic]) RegTopicInactiveEvent(handle TopicInactiveEventHandle[Topic]) {
+	me.topicInactiveEventHandles = append(me.topicInactiveEventHandles, handle)
+}
+
+// OnTopicInactiveEvent 主题不活跃事件
+func (me *managerEvents[Topic]) OnTopicInactiveEvent(manager *Manager[Topic], topic Topic) {
+	for _, handle := range me.topicInactiveEventHandles {
+		handle(manager, topic)
+	}
+}
+
+// RegSubscribeEvent 注册订阅事件，当连接订阅了此前未订阅的主题时触发
+func (me *managerEvents[Topic]) RegSubscribeEvent(handle SubscribeEventHandle[Topic]) {
+	me.subscribeEventHandles = append(me.subscribeEventHandles, handle)
+}
+
+// OnSubscribeEvent 订阅事件
+func (me *managerEvents[Topic]) OnSubscribeEvent(manager *Manager[Topic], conn *server.Conn, topic Topic) {
+	for _, handle := range me.subscribeEventHandles {
+		handle(manager, conn, topic)
+	}
+}
+
+// RegUnsubscribeEvent 注册取消订阅事件，包括连接断开时的自动取消订阅
+func (me *managerEvents[Topic]) RegUnsubscribeEvent(handle UnsubscribeEventHandle[Topic]) {
+	me.unsubscribeEventHandles = append(me.unsubscribeEventHandles, handle)
+}
+
+// OnUnsubscribeEvent 取消订阅事件
+func (me *managerEvents[Topic]) OnUnsubscribeEvent(manager *Manager[Topic], conn *server.Conn, topic Topic) {
+	for _, handle := range me.unsubscribeEventHandles {
+		handle(manager, conn, topic)
+	}
+}
@@ -0,0 +1,53 @@
+package subscription_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/game/subscription"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"testing"
+)
+
+func TestManager(t *testing.T) {
+	var inactive []string
+	var published []int
+	manager := subscription.NewManager[string]()
+	manager.RegTopicInactiveEvent(func(manager *subscription.Manager[string], topic string) {
+		inactive = append(inactive, topic)
+	})
+
+	srv := server.New(server.NetworkTcp)
+	manager.Attach(srv)
+	var conns []*server.Conn
+	var bots []*server.Bot
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		if conns = append(conns, conn); len(conns) != 2 {
+			return
+		}
+		a, b := conns[0], conns[1]
+		manager.Subscribe(a, "auction")
+		manager.Subscribe(b, "auction", "guild", "guild")
+		published = append(published, manager.Publish("auction", []byte("bid")), manager.Publish("guild", []byte("war")))
+		bots[1].LeaveServer()
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		defer srv.Shutdown()
+		published = append(published, manager.Publish("auction", []byte("bid")), manager.Publish("guild", []byte("war")))
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bots = append(bots, server.NewBot(srv), server.NewBot(srv))
+		for _, bot := range bots {
+			bot.JoinServer()
+		}
+	})
+	if err := srv.Run(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(published) != "[2 1 1 0]" {
+		t.Fatalf("unexpected published count: %v", published)
+	}
+	if fmt.Sprint(inactive) != "[guild]" || manager.GetSubscriberCount("auction") != 1 {
+		t.Fatalf("unexpected inactive topics: %v, auction subscribers: %d", inactive, manager.GetSubscriberCount("auction"))
+	}
+}
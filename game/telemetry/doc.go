@@ -0,0 +1,3 @@
+// Package telemetry 提供了用于数据分析的埋点事件管道，游戏模块上报的登录、购买、升级等类型化的分析事件将被缓冲并分批，
+// 随后通过服务器的异步消息投递至可插拔的 Sink，例如 Kafka、HTTP 或文件，避免阻塞游戏逻辑
+package telemetry
@@ -0,0 +1,57 @@
+package telemetry
+
+import "time"
+
+const (
+	EventNameLogin    = "login"    // 登录事件名称
+	EventNamePurchase = "purchase" // 购买事件名称
+	EventNameLevelUp  = "level_up" // 升级事件名称
+)
+
+// Event 分析事件，游戏模块可通过实现该接口定义自己的事件类型
+type Event interface {
+	// EventName 获取事件名称，Sink 可根据事件名称将事件投递至不同的主题或数据表
+	EventName() string
+}
+
+// Record 被缓冲等待投递的分析事件记录
+type Record struct {
+	Name  string    `json:"name"`  // 事件名称
+	Time  time.Time `json:"time"`  // 事件上报时间
+	Event Event     `json:"event"` // 事件内容
+}
+
+// LoginEvent 登录事件
+type LoginEvent struct {
+	PlayerID string `json:"player_id"` // 玩家 ID
+	IP       string `json:"ip"`        // 登录 IP
+	Device   string `json:"device"`    // 登录设备
+}
+
+func (e LoginEvent) EventName() string {
+	return EventNameLogin
+}
+
+// PurchaseEvent 购买事件
+type PurchaseEvent struct {
+	PlayerID string `json:"player_id"` // 玩家 ID
+	OrderID  string `json:"order_id"`  // 订单 ID
+	ItemID   string `json:"item_id"`   // 购买的商品 ID
+	Amount   int64  `json:"amount"`    // 支付金额，以货币的最小单位计
+	Currency string `json:"currency"`  // 货币类型
+}
+
+func (e PurchaseEvent) EventName() string {
+	return EventNamePurchase
+}
+
+// LevelUpEvent 升级事件
+type LevelUpEvent struct {
+	PlayerID string `json:"player_id"` // 玩家 ID
+	From     int    `json:"from"`      // 升级前的等级
+	To       int    `json:"to"`        // 升级后的等级
+}
+
+func (e LevelUpEvent) EventName() string {
+	return EventNameLevelUp
+}
@@ -0,0 +1,76 @@
+package telemetry
+
+import "time"
+
+const (
+	DefaultBatchSize     = 100              // 默认每批投递的记录数量
+	DefaultBufferSize    = 10000            // 默认缓冲的最大记录数量
+	DefaultFlushInterval = time.Second * 5  // 默认定时投递的间隔
+	DefaultFlushTimeout  = time.Second * 10 // 默认单次投递的超时时长
+)
+
+// NewOptions 创建分析事件管道选项
+func NewOptions() *Options {
+	return &Options{}
+}
+
+// mergeOptions 合并分析事件管道选项
+func mergeOptions(options ...*Options) *Options {
+	result := &Options{
+		batchSize:     DefaultBatchSize,
+		bufferSize:    DefaultBufferSize,
+		flushInterval: DefaultFlushInterval,
+		flushTimeout:  DefaultFlushTimeout,
+	}
+	for _, option := range options {
+		if option.batchSize > 0 {
+			result.batchSize = option.batchSize
+		}
+		if option.bufferSize > 0 {
+			result.bufferSize = option.bufferSize
+		}
+		if option.flushInterval > 0 {
+			result.flushInterval = option.flushInterval
+		}
+		if option.flushTimeout > 0 {
+			result.flushTimeout = option.flushTimeout
+		}
+	}
+	if result.bufferSize < result.batchSize {
+		result.bufferSize = result.batchSize
+	}
+	return result
+}
+
+// Options 分析事件管道选项
+type Options struct {
+	batchSize     int           // 每批投递的记录数量
+	bufferSize    int           // 缓冲的最大记录数量
+	flushInterval time.Duration // 定时投递的间隔
+	flushTimeout  time.Duration // 单次投递的超时时长
+}
+
+// WithBatchSize 设置每批投递的记录数量，缓冲的记录达到该数量时将立即投递，默认为 DefaultBatchSize
+func (o *Options) WithBatchSize(size int) *Options {
+	o.batchSize = size
+	return o
+}
+
+// WithBufferSize 设置缓冲的最大记录数量，超出时最早的记录将被丢弃，默认为 DefaultBufferSize
+//   - 当小于每批投递的记录数量时将使用每批投递的记录数量
+func (o *Options) WithBufferSize(size int) *Options {
+	o.bufferSize = size
+	return o
+}
+
+// WithFlushInterval 设置定时投递的间隔，即使缓冲的记录未达到每批投递的数量也将被投递，默认为 DefaultFlushInterval
+func (o *Options) WithFlushInterval(interval time.Duration) *Options {
+	o.flushInterval = interval
+	return o
+}
+
+// WithFlushTimeout 设置单次投递的超时时长，默认为 DefaultFlushTimeout
+func (o *Options) WithFlushTimeout(timeout time.Duration) *Options {
+	o.flushTimeout = timeout
+	return o
+}
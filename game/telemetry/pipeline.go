@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NewPipeline 创建分析事件管道 Pipeline 的实例，缓冲的记录将被分批投递至所有 sinks
+//   - 管道依赖服务器的异步消息及延迟消息，应当在服务器启动后创建
+func NewPipeline(srv *server.Server, sinks []Sink, options ...*Options) *Pipeline {
+	p := &Pipeline{
+		pipelineEvents: new(pipelineEvents),
+		srv:            srv,
+		sinks:          sinks,
+		options:        mergeOptions(options...),
+	}
+	p.schedule()
+	return p
+}
+
+// Pipeline 分析事件管道，游戏模块通过 Emit 上报的分析事件将被缓冲，并在达到每批投递的数量或定时投递的间隔到达时被投递
+//   - 投递将在服务器的异步消息中执行，不会阻塞游戏逻辑，不同批次之间的投递可能并发执行，因此不保证投递顺序
+//   - 该实例是线程安全的
+type Pipeline struct {
+	*pipelineEvents
+	srv     *server.Server
+	sinks   []Sink
+	options *Options
+
+	lock    sync.Mutex
+	buffer  []Record               // 等待投递的记录
+	timer   *server.DelayedMessage // 定时投递的延迟消息
+	closed  bool
+	dropped atomic.Int64 // 由于缓冲区已满而被丢弃的记录数量
+}
+
+// Emit 上报分析事件，事件将被缓冲并在随后异步投递，管道关闭后上报的事件将被忽略
+func (p *Pipeline) Emit(event Event) {
+	if event == nil {
+		return
+	}
+	record := Record{Name: event.EventName(), Time: time.Now(), Event: event}
+
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return
+	}
+	var dropped []Record
+	p.buffer = append(p.buffer, record)
+	if excess := len(p.buffer) - p.options.bufferSize; excess > 0 {
+		dropped = append(dropped, p.buffer[:excess]...)
+		p.buffer = append(p.buffer[:0], p.buffer[excess:]...)
+		p.dropped.Add(int64(excess))
+	}
+	var batch []Record
+	if len(p.buffer) >= p.options.batchSize {
+		batch = p.take(p.options.batchSize)
+	}
+	p.lock.Unlock()
+
+	for _, record := range dropped {
+		p.OnRecordDroppedEvent(p, record)
+	}
+	if batch != nil {
+		p.flush(batch)
+	}
+}
+
+// Flush 立即投递所有缓冲的记录，记录将按照每批投递的数量分批投递
+func (p *Pipeline) Flush() {
+	p.lock.Lock()
+	var batches [][]Record
+	for len(p.buffer) > 0 {
+		batches = append(batches, p.take(p.options.batchSize))
+	}
+	p.lock.Unlock()
+
+	for _, batch := range batches {
+		p.flush(batch)
+	}
+}
+
+// Len 获取当前缓冲的记录数量
+func (p *Pipeline) Len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.buffer)
+}
+
+// GetDroppedCount 获取由于缓冲区已满而被丢弃的记录数量
+func (p *Pipeline) GetDroppedCount() int64 {
+	return p.dropped.Load()
+}
+
+// Close 关闭管道并投递所有缓冲的记录，关闭后上报的事件将被忽略
+//   - 投递依赖服务器的异步消息，应当在服务器停止前关闭
+func (p *Pipeline) Close() {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return
+	}
+	p.closed = true
+	if p.timer != nil {
+		p.timer.Cancel()
+	}
+	p.lock.Unlock()
+	p.Flush()
+}
+
+// take 从缓冲区头部取出至多 n 条记录，调用方需持有锁
+func (p *Pipeline) take(n int) []Record {
+	n = min(n, len(p.buffer))
+	batch := make([]Record, n)
+	copy(batch, p.buffer)
+	p.buffer = append(p.buffer[:0], p.buffer[n:]...)
+	return batch
+}
+
+// schedule 调度下一次定时投递
+func (p *Pipeline) schedule() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return
+	}
+	p.timer = p.srv.PushDelayedMessage(p.options.flushInterval, func() {
+		p.Flush()
+		p.schedule()
+	}, log.String("Telemetry", "flush"))
+}
+
+// flush 在异步消息中将一批记录投递至所有 Sink，并在系统分流渠道中触发投递事件
+func (p *Pipeline) flush(batch []Record) {
+	var failed = make(map[int]error)
+	p.srv.PushAsyncMessage(func() error {
+		for i, sink := range p.sinks {
+			ctx, cancel := context.WithTimeout(context.Background(), p.options.flushTimeout)
+			if err := sink.Flush(ctx, batch); err != nil {
+				failed[i] = err
+				log.Error("Telemetry", log.Int("Records", len(batch)), log.Err(err))
+			}
+			cancel()
+		}
+		return nil
+	}, func(err error) {
+		for i, sink := range p.sinks {
+			if err, exist := failed[i]; exist {
+				p.OnFlushFailedEvent(p, sink, batch, err)
+			}
+		}
+		p.OnFlushEvent(p, batch)
+	}, log.String("Telemetry", "flush"))
+}
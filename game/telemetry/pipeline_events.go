@@ -0,0 +1,50 @@
+package telemetry
+
+type (
+	FlushEventHandle         func(pipeline *Pipeline, records []Record)
+	FlushFailedEventHandle   func(pipeline *Pipeline, sink Sink, records []Record, err error)
+	RecordDroppedEventHandle func(pipeline *Pipeline, record Record)
+)
+
+type pipelineEvents struct {
+	flushEventHandles         []FlushEventHandle
+	flushFailedEventHandles   []FlushFailedEventHandle
+	recordDroppedEventHandles []RecordDroppedEventHandle
+}
+
+// RegFlushEvent 注册投递完成事件，当一批记录被投递至所有 Sink 后将在服务器的系统分流渠道中执行，无论投递是否成功
+func (pe *pipelineEvents) RegFlushEvent(handle FlushEventHandle) {
+	pe.flushEventHandles = append(pe.flushEventHandles, handle)
+}
+
+// OnFlushEvent 投递完成事件
+func (pe *pipelineEvents) OnFlushEvent(pipeline *Pipeline, records []Record) {
+	for _, handle := range pe.flushEventHandles {
+		handle(pipeline, records)
+	}
+}
+
+// RegFlushFailedEvent 注册投递失败事件，当 Sink 投递失败时将在服务器的系统分流渠道中执行，可用于将失败的记录写入本地文件等待补发
+func (pe *pipelineEvents) RegFlushFailedEvent(handle FlushFailedEventHandle) {
+	pe.flushFailedEventHandles = append(pe.flushFailedEventHandles, handle)
+}
+
+// OnFlushFailedEvent 投递失败事件
+func (pe *pipelineEvents) OnFlushFailedEvent(pipeline *Pipeline, sink Sink, records []Record, err error) {
+	for _, handle := range pe.flushFailedEventHandles {
+		handle(pipeline, sink, records, err)
+	}
+}
+
+// RegRecordDroppedEvent 注册记录丢弃事件，当缓冲的记录超出 Options.WithBufferSize 的限制时，最早的记录将被丢弃
+//   - 该事件将在调用 Pipeline.Emit 的协程中同步执行，不应执行耗时操作
+func (pe *pipelineEvents) RegRecordDroppedEvent(handle RecordDroppedEventHandle) {
+	pe.recordDroppedEventHandles = append(pe.recordDroppedEventHandles, handle)
+}
+
+// OnRecordDroppedEvent 记录丢弃事件
+func (pe *pipelineEvents) OnRecordDroppedEvent(pipeline *Pipeline, record Record) {
+	for _, handle := range pe.recordDroppedEventHandles {
+		handle(pipeline, record)
+	}
+}
@@ -0,0 +1,64 @@
+package telemetry_test
+
+import (
+	"bufio"
+	"context"
+	"github.com/kercylan98/minotaur/game/telemetry"
+	"github.com/kercylan98/minotaur/server"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPipeline_Emit(t *testing.T) {
+	var lock sync.Mutex
+	var batches []int
+	var flushed int
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	srv := server.New(server.NetworkNone)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		pipeline := telemetry.NewPipeline(srv, []telemetry.Sink{
+			telemetry.SinkFunc(func(ctx context.Context, records []telemetry.Record) error {
+				lock.Lock()
+				batches = append(batches, len(records))
+				lock.Unlock()
+				return nil
+			}),
+			telemetry.NewFileSink(path),
+		}, telemetry.NewOptions().WithBatchSize(2).WithFlushInterval(time.Hour))
+		pipeline.RegFlushEvent(func(pipeline *telemetry.Pipeline, records []telemetry.Record) {
+			if flushed++; flushed == 2 {
+				srv.Shutdown()
+			}
+		})
+		pipeline.Emit(telemetry.LoginEvent{PlayerID: "a"})
+		pipeline.Emit(telemetry.LevelUpEvent{PlayerID: "a", From: 1, To: 2})
+		pipeline.Emit(telemetry.PurchaseEvent{PlayerID: "a", OrderID: "1", Amount: 600})
+		if n := pipeline.Len(); n != 1 {
+			t.Errorf("unexpected buffered records: %d", n)
+		}
+		pipeline.Close()
+		pipeline.Emit(telemetry.LoginEvent{PlayerID: "b"})
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batches) != 2 || batches[0]+batches[1] != 3 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var lines int
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		lines++
+	}
+	if lines != 3 {
+		t.Fatalf("unexpected lines: %d", lines)
+	}
+}
@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Sink 分析事件的投递目标，Flush 将在服务器的异步消息中执行，因此允许执行阻塞的 IO 操作
+//   - 同一个 Sink 的 Flush 可能会被并发调用，实现应当是线程安全的
+//   - 投递至 Kafka 等消息队列时，可通过 SinkFunc 包装对应客户端的生产者
+type Sink interface {
+	// Flush 投递一批分析事件记录，records 在函数返回后将不再被使用
+	Flush(ctx context.Context, records []Record) error
+}
+
+// SinkFunc 函数形式的 Sink
+type SinkFunc func(ctx context.Context, records []Record) error
+
+// Flush 投递一批分析事件记录
+func (f SinkFunc) Flush(ctx context.Context, records []Record) error {
+	return f(ctx, records)
+}
+
+// NewFileSink 创建以 JSON Lines 格式将分析事件记录追加写入文件的 Sink，文件不存在时将被创建
+func NewFileSink(path string) Sink {
+	return &fileSink{path: path}
+}
+
+// fileSink 以 JSON Lines 格式写入文件的 Sink
+type fileSink struct {
+	path string
+	lock sync.Mutex
+}
+
+func (s *fileSink) Flush(ctx context.Context, records []Record) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(buf.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// NewHTTPSink 创建将每一批分析事件记录以 JSON 数组的形式通过 POST 请求发送至 url 的 Sink
+//   - 当 client 为 nil 时将使用 http.DefaultClient
+//   - 响应状态码不为 2xx 时将返回错误
+func NewHTTPSink(url string, client *http.Client) Sink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpSink{url: url, client: client}
+}
+
+// httpSink 通过 HTTP 请求投递的 Sink
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpSink) Flush(ctx context.Context, records []Record) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("telemetry: unexpected status code %d", response.StatusCode)
+	}
+	return nil
+}
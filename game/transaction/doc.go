@@ -0,0 +1,3 @@
+// Package transaction 提供了跨模块的游戏事务辅助工具，可将扣除货币、发放道具、推进任务等操作组合为一个整体，
+// 当任一步骤失败或发生 panic 时，将按照相反的顺序执行已完成步骤的补偿函数，避免出现扣除货币后未发放道具等问题
+package transaction
@@ -0,0 +1,10 @@
+package transaction
+
+import "errors"
+
+var (
+	// ErrCommitted 事务已经提交过
+	ErrCommitted = errors.New("transaction already committed")
+	// ErrStepPanic 步骤执行时发生了 panic
+	ErrStepPanic = errors.New("transaction step panic")
+)
@@ -0,0 +1,36 @@
+package transaction
+
+// Option 事务选项
+type Option func(tx *Transaction)
+
+// Journal 事务日志，用于在进程崩溃后找回未完成的事务并执行补偿
+type Journal interface {
+	// Record 记录事务的进度，started 为已经开始执行的步骤名称，finished 为 true 时表示事务已提交或已完成回滚，可删除该记录
+	//   - 在每个步骤执行前都会进行记录，当记录失败时事务将不会继续执行该步骤，并回滚已完成的步骤
+	Record(id string, started []string, finished bool) error
+}
+
+// JournalFunc 函数形式的事务日志
+type JournalFunc func(id string, started []string, finished bool) error
+
+// Record 记录事务的进度
+func (f JournalFunc) Record(id string, started []string, finished bool) error {
+	return f(id, started, finished)
+}
+
+// WithJournal 设置事务日志，进程重启后可根据日志中未完成的记录重建事务，并通过 Transaction.Rollback 补偿已经开始执行的步骤
+//   - 由于步骤在开始执行前被记录，崩溃时记录中的最后一个步骤可能并未生效，因此补偿函数应当是幂等的
+func WithJournal(journal Journal) Option {
+	return func(tx *Transaction) {
+		tx.journal = journal
+	}
+}
+
+// WithCompensateRetry 设置补偿函数失败时的重试次数，默认不重试
+func WithCompensateRetry(retry int) Option {
+	return func(tx *Transaction) {
+		if retry > 0 {
+			tx.retry = retry
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package transaction
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/super"
+	"strings"
+	"sync"
+)
+
+// New 创建一个事务，id 用于在事务日志中区分不同的事务
+func New(id string, options ...Option) *Transaction {
+	tx := &Transaction{id: id}
+	for _, option := range options {
+		option(tx)
+	}
+	return tx
+}
+
+// step 事务中的步骤
+type step struct {
+	name       string
+	do         func() error
+	compensate func() error
+}
+
+// Transaction 由多个步骤组成的游戏事务，例如 扣除货币 -> 发放道具 -> 推进任务
+//   - 步骤将按照添加的顺序执行，当任一步骤返回错误或发生 panic 时，将按照相反的顺序执行已完成步骤的补偿函数
+//   - 事务仅能提交一次，该实例不应在多个协程中同时构建
+type Transaction struct {
+	id        string
+	steps     []*step
+	journal   Journal
+	retry     int
+	lock      sync.Mutex
+	committed bool
+}
+
+// GetID 获取事务 ID
+func (tx *Transaction) GetID() string {
+	return tx.id
+}
+
+// Then 添加一个步骤，compensate 为步骤执行成功后事务失败时的补偿函数，可为 nil
+//   - 步骤名称在同一事务中应当是唯一的，将用于错误信息、事务日志及 Rollback
+func (tx *Transaction) Then(name string, do func() error, compensate func() error) *Transaction {
+	tx.steps = append(tx.steps, &step{name: name, do: do, compensate: compensate})
+	return tx
+}
+
+// Commit 依次执行事务中的所有步骤，当所有步骤执行成功时返回 nil，否则返回 *Error
+//   - 重复提交将返回 ErrCommitted
+func (tx *Transaction) Commit() error {
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+	if tx.committed {
+		return ErrCommitted
+	}
+	tx.committed = true
+
+	var started = make([]string, 0, len(tx.steps))
+	for i, s := range tx.steps {
+		started = append(started, s.name)
+		err := tx.record(started, false)
+		if err == nil {
+			err = call(s.do)
+		}
+		if err != nil {
+			e := &Error{Transaction: tx.id, Step: s.name, Err: err}
+			tx.compensate(tx.steps[:i], e)
+			if len(e.CompensateErrors) == 0 {
+				_ = tx.record(started, true)
+			}
+			return e
+		}
+	}
+	if err := tx.record(started, true); err != nil {
+		log.Warn("Transaction", log.String("ID", tx.id), log.String("Journal", "finish"), log.Err(err))
+	}
+	return nil
+}
+
+// Rollback 按照相反的顺序执行特定步骤的补偿函数，通常用于进程重启后根据 Journal 中未完成的记录补偿已经开始执行的步骤
+//   - 当所有补偿函数执行成功时返回 nil，否则返回 *Error
+func (tx *Transaction) Rollback(started []string) error {
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+	tx.committed = true
+
+	var names = make(map[string]struct{}, len(started))
+	for _, name := range started {
+		names[name] = struct{}{}
+	}
+	var steps []*step
+	for _, s := range tx.steps {
+		if _, exist := names[s.name]; exist {
+			steps = append(steps, s)
+		}
+	}
+	e := &Error{Transaction: tx.id}
+	tx.compensate(steps, e)
+	if len(e.CompensateErrors) > 0 {
+		return e
+	}
+	_ = tx.record(started, true)
+	return nil
+}
+
+// compensate 按照相反的顺序执行步骤的补偿函数，并将结果记录到 e 中
+func (tx *Transaction) compensate(steps []*step, e *Error) {
+	for i := len(steps) - 1; i >= 0; i-- {
+		s := steps[i]
+		if s.compensate == nil {
+			continue
+		}
+		var err error
+		for attempt := 0; attempt <= tx.retry; attempt++ {
+			if err = call(s.compensate); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			if e.CompensateErrors == nil {
+				e.CompensateErrors = make(map[string]error)
+			}
+			e.CompensateErrors[s.name] = err
+			log.Error("Transaction", log.String("ID", tx.id), log.String("Compensate", s.name), log.Err(err))
+			continue
+		}
+		e.Compensated = append(e.Compensated, s.name)
+	}
+}
+
+// record 在设置了事务日志时记录事务的进度
+func (tx *Transaction) record(started []string, finished bool) error {
+	if tx.journal == nil {
+		return nil
+	}
+	return tx.journal.Record(tx.id, started, finished)
+}
+
+// call 执行函数，并将 panic 转换为 ErrStepPanic
+func call(f func() error) (err error) {
+	defer func() {
+		if e := super.RecoverTransform(recover()); e != nil {
+			err = fmt.Errorf("%w: %v", ErrStepPanic, e)
+		}
+	}()
+	return f()
+}
+
+// Error 事务执行失败的错误
+type Error struct {
+	Transaction      string           // 事务 ID
+	Step             string           // 执行失败的步骤，通过 Transaction.Rollback 回滚时为空
+	Err              error            // 步骤执行失败的原因
+	Compensated      []string         // 补偿成功的步骤，按照补偿的顺序排列
+	CompensateErrors map[string]error // 补偿失败的步骤及原因，此时数据可能已不一致，需要人工介入或重新执行 Rollback
+}
+
+// Error 获取错误信息
+func (e *Error) Error() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("transaction %s", e.Transaction))
+	if e.Step != "" {
+		builder.WriteString(fmt.Sprintf(" step %s failed: %v", e.Step, e.Err))
+	}
+	if len(e.CompensateErrors) > 0 {
+		builder.WriteString(fmt.Sprintf(", %d compensation failed", len(e.CompensateErrors)))
+	}
+	return builder.String()
+}
+
+// Unwrap 获取步骤执行失败的原因
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsConsistent 检查事务失败后所有补偿函数是否均执行成功
+func (e *Error) IsConsistent() bool {
+	return len(e.CompensateErrors) == 0
+}
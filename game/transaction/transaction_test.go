@@ -0,0 +1,68 @@
+package transaction_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/game/transaction"
+	"testing"
+)
+
+func TestTransaction_Commit(t *testing.T) {
+	var gold, items, progress = 100, 0, 0
+	var records []string
+	var journal = transaction.JournalFunc(func(id string, started []string, finished bool) error {
+		records = append(records, fmt.Sprintf("%v:%v", started, finished))
+		return nil
+	})
+
+	newTx := func(grant func() error) *transaction.Transaction {
+		return transaction.New("purchase", transaction.WithJournal(journal)).
+			Then("wallet", func() error {
+				gold -= 10
+				return nil
+			}, func() error {
+				gold += 10
+				return nil
+			}).
+			Then("inventory", grant, func() error {
+				items--
+				return nil
+			}).
+			Then("quest", func() error {
+				progress++
+				return nil
+			}, nil)
+	}
+
+	err := newTx(func() error {
+		panic("inventory full")
+	}).Commit()
+	var txErr *transaction.Error
+	if !errors.As(err, &txErr) || !errors.Is(err, transaction.ErrStepPanic) || txErr.Step != "inventory" || !txErr.IsConsistent() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gold != 100 || items != 0 || progress != 0 {
+		t.Fatalf("transaction should be compensated, gold: %d, items: %d, progress: %d", gold, items, progress)
+	}
+
+	tx := newTx(func() error {
+		items++
+		return nil
+	})
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); !errors.Is(err, transaction.ErrCommitted) {
+		t.Fatalf("commit twice should return ErrCommitted, got: %v", err)
+	}
+	if gold != 90 || items != 1 || progress != 1 {
+		t.Fatalf("unexpected result, gold: %d, items: %d, progress: %d", gold, items, progress)
+	}
+	if expected := "[[wallet]:false [wallet inventory]:false [wallet inventory]:true [wallet]:false [wallet inventory]:false [wallet inventory quest]:false [wallet inventory quest]:true]"; fmt.Sprint(records) != expected {
+		t.Fatalf("unexpected records: %v", records)
+	}
+
+	if err = newTx(nil).Rollback([]string{"wallet", "inventory"}); err != nil || gold != 100 || items != 0 {
+		t.Fatalf("rollback failed, err: %v, gold: %d, items: %d", err, gold, items)
+	}
+}
@@ -0,0 +1,185 @@
+package worldevent
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/collection"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync"
+	"time"
+)
+
+// Shunt 世界事件协调器所使用的分流渠道名称
+const Shunt = "world_event"
+
+// Mailer 结算奖励的邮件发送器，通常由游戏的邮件系统实现，根据参与者的结算信息组织奖励邮件并发送
+type Mailer[EventID comparable, PlayerID comparable] interface {
+	// SendSettlementMail 向参与者发送结算奖励邮件
+	SendSettlementMail(settlement Settlement[EventID, PlayerID]) error
+}
+
+// MailerFunc 函数形式的结算奖励邮件发送器
+type MailerFunc[EventID comparable, PlayerID comparable] func(settlement Settlement[EventID, PlayerID]) error
+
+// SendSettlementMail 向参与者发送结算奖励邮件
+func (f MailerFunc[EventID, PlayerID]) SendSettlementMail(settlement Settlement[EventID, PlayerID]) error {
+	return f(settlement)
+}
+
+// NewCoordinator 创建世界事件协调器 Coordinator 的实例
+//   - 协调器依赖服务器的分流渠道，应当在服务器启动后创建
+func NewCoordinator[EventID comparable, PlayerID comparable](srv *server.Server, mailer Mailer[EventID, PlayerID]) *Coordinator[EventID, PlayerID] {
+	c := &Coordinator[EventID, PlayerID]{
+		coordinatorEvents: new(coordinatorEvents[EventID, PlayerID]),
+		srv:               srv,
+		mailer:            mailer,
+		producer:          server.NewOfflineConn(srv),
+		encounters:        make(map[EventID]*Encounter[EventID, PlayerID]),
+	}
+	srv.UseShunt(c.producer, Shunt)
+	return c
+}
+
+// Coordinator 世界事件协调器，用于发起定时开启的全服首领等世界事件
+//   - 所有世界事件的伤害贡献汇总、开启及结束均在名为 Shunt 的独立分流渠道中串行执行，不会阻塞玩家所在的分流渠道
+//   - 世界事件结束后，将在异步消息中通过 Mailer 向所有参与者发送结算奖励邮件
+//   - 该实例是线程安全的
+type Coordinator[EventID comparable, PlayerID comparable] struct {
+	*coordinatorEvents[EventID, PlayerID]
+	srv        *server.Server
+	mailer     Mailer[EventID, PlayerID]
+	producer   *server.Conn // 保持分流渠道的离线连接
+	rw         sync.RWMutex
+	encounters map[EventID]*Encounter[EventID, PlayerID] // 尚未结束的世界事件
+}
+
+// Spawn 发起世界事件，世界事件将在 EncounterOptions.WithDelay 设置的延迟后开启，当相同 ID 的世界事件尚未结束时返回 ErrEncounterExist
+func (c *Coordinator[EventID, PlayerID]) Spawn(id EventID, options ...*EncounterOptions) (*Encounter[EventID, PlayerID], error) {
+	c.rw.Lock()
+	if _, exist := c.encounters[id]; exist {
+		c.rw.Unlock()
+		return nil, ErrEncounterExist
+	}
+	encounter := newEncounter[EventID, PlayerID](id, mergeEncounterOptions(options...))
+	c.encounters[id] = encounter
+	c.rw.Unlock()
+
+	c.OnEncounterSpawnEvent(c, encounter)
+	encounter.rw.Lock()
+	encounter.startMessage = c.srv.PushShuntDelayedMessage(c.producer, encounter.options.delay, func() {
+		c.start(encounter)
+	}, log.Any("WorldEvent", id))
+	encounter.rw.Unlock()
+	return encounter, nil
+}
+
+// Damage 记录参与者对世界事件造成的伤害，伤害将在协调器的分流渠道中异步汇总
+//   - 未开启、已结束的世界事件以及小于等于 0 的伤害将被忽略
+//   - 当世界事件不存在时返回 ErrEncounterNotExist
+func (c *Coordinator[EventID, PlayerID]) Damage(id EventID, player PlayerID, damage int64) error {
+	encounter := c.GetEncounter(id)
+	if encounter == nil {
+		return ErrEncounterNotExist
+	}
+	c.srv.PushShuntMessage(c.producer, func() {
+		if damage <= 0 || encounter.GetState() != EncounterStateActive {
+			return
+		}
+		killed := encounter.contribute(player, damage)
+		c.OnEncounterDamageEvent(c, encounter, player, damage)
+		if killed {
+			c.finish(encounter, EncounterStateKilled)
+		}
+	}, log.Any("WorldEvent", id))
+	return nil
+}
+
+// Cancel 取消尚未结束的世界事件，被取消的世界事件不会进行结算，当世界事件不存在时返回 ErrEncounterNotExist
+func (c *Coordinator[EventID, PlayerID]) Cancel(id EventID) error {
+	encounter := c.GetEncounter(id)
+	if encounter == nil {
+		return ErrEncounterNotExist
+	}
+	c.srv.PushShuntMessage(c.producer, func() {
+		c.finish(encounter, EncounterStateCanceled)
+	}, log.Any("WorldEvent", id))
+	return nil
+}
+
+// GetEncounter 获取尚未结束的世界事件，当世界事件不存在时将返回 nil
+func (c *Coordinator[EventID, PlayerID]) GetEncounter(id EventID) *Encounter[EventID, PlayerID] {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	return c.encounters[id]
+}
+
+// GetEncounters 获取所有尚未结束的世界事件
+//   - 返回值的 map 为拷贝对象，可安全的对其进行增删等操作
+func (c *Coordinator[EventID, PlayerID]) GetEncounters() map[EventID]*Encounter[EventID, PlayerID] {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	return collection.CloneMap(c.encounters)
+}
+
+// Close 取消所有尚未结束的世界事件并释放协调器的分流渠道
+func (c *Coordinator[EventID, PlayerID]) Close() {
+	for id := range c.GetEncounters() {
+		_ = c.Cancel(id)
+	}
+	c.producer.Close()
+}
+
+// start 开启世界事件，并在持续时长到达时以超时结束
+func (c *Coordinator[EventID, PlayerID]) start(encounter *Encounter[EventID, PlayerID]) {
+	if !encounter.state.CompareAndSwap(int32(EncounterStatePending), int32(EncounterStateActive)) {
+		return
+	}
+	encounter.rw.Lock()
+	encounter.startAt = time.Now()
+	encounter.endMessage = c.srv.PushShuntDelayedMessage(c.producer, encounter.options.duration, func() {
+		c.finish(encounter, EncounterStateTimeout)
+	}, log.Any("WorldEvent", encounter.id))
+	encounter.rw.Unlock()
+	c.OnEncounterStartEvent(c, encounter)
+}
+
+// finish 结束世界事件，除被取消外将通过 Mailer 发放结算奖励
+func (c *Coordinator[EventID, PlayerID]) finish(encounter *Encounter[EventID, PlayerID], state EncounterState) {
+	for {
+		curr := encounter.GetState()
+		if curr.IsEnded() {
+			return
+		}
+		if encounter.state.CompareAndSwap(int32(curr), int32(state)) {
+			break
+		}
+	}
+	encounter.rw.RLock()
+	for _, message := range []*server.DelayedMessage{encounter.startMessage, encounter.endMessage} {
+		if message != nil {
+			message.Cancel()
+		}
+	}
+	encounter.rw.RUnlock()
+	c.rw.Lock()
+	delete(c.encounters, encounter.id)
+	c.rw.Unlock()
+
+	settlements := encounter.settle(state)
+	c.OnEncounterEndEvent(c, encounter)
+	if state == EncounterStateCanceled {
+		return
+	}
+
+	var failed = make(map[PlayerID]error)
+	c.srv.PushShuntAsyncMessage(c.producer, func() error {
+		for _, settlement := range settlements {
+			if err := c.mailer.SendSettlementMail(settlement); err != nil {
+				failed[settlement.Player] = err
+				log.Error("WorldEvent", log.Any("ID", encounter.id), log.Any("Player", settlement.Player), log.Err(err))
+			}
+		}
+		return nil
+	}, func(err error) {
+		c.OnEncounterSettledEvent(c, encounter, settlements, failed)
+	}, log.Any("WorldEvent", encounter.id))
+}
@@ -0,0 +1,79 @@
+package worldevent
+
+type (
+	EncounterSpawnEventHandle[EventID comparable, PlayerID comparable]   func(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID])
+	EncounterStartEventHandle[EventID comparable, PlayerID comparable]   func(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID])
+	EncounterDamageEventHandle[EventID comparable, PlayerID comparable]  func(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID], player PlayerID, damage int64)
+	EncounterEndEventHandle[EventID comparable, PlayerID comparable]     func(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID])
+	EncounterSettledEventHandle[EventID comparable, PlayerID comparable] func(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID], settlements []Settlement[EventID, PlayerID], failed map[PlayerID]error)
+)
+
+type coordinatorEvents[EventID comparable, PlayerID comparable] struct {
+	encounterSpawnEventHandles   []EncounterSpawnEventHandle[EventID, PlayerID]
+	encounterStartEventHandles   []EncounterStartEventHandle[EventID, PlayerID]
+	encounterDamageEventHandles  []EncounterDamageEventHandle[EventID, PlayerID]
+	encounterEndEventHandles     []EncounterEndEventHandle[EventID, PlayerID]
+	encounterSettledEventHandles []EncounterSettledEventHandle[EventID, PlayerID]
+}
+
+// RegEncounterSpawnEvent 注册世界事件发起事件，此时世界事件处于等待开启状态
+func (ce *coordinatorEvents[EventID, PlayerID]) RegEncounterSpawnEvent(handle EncounterSpawnEventHandle[EventID, PlayerID]) {
+	ce.encounterSpawnEventHandles = append(ce.encounterSpawnEventHandles, handle)
+}
+
+// OnEncounterSpawnEvent 世界事件发起事件
+func (ce *coordinatorEvents[EventID, PlayerID]) OnEncounterSpawnEvent(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID]) {
+	for _, handle := range ce.encounterSpawnEventHandles {
+		handle(coordinator, encounter)
+	}
+}
+
+// RegEncounterStartEvent 注册世界事件开启事件，该事件将在 Coordinator 的分流渠道中执行，可用于向全服广播首领出现
+func (ce *coordinatorEvents[EventID, PlayerID]) RegEncounterStartEvent(handle EncounterStartEventHandle[EventID, PlayerID]) {
+	ce.encounterStartEventHandles = append(ce.encounterStartEventHandles, handle)
+}
+
+// OnEncounterStartEvent 世界事件开启事件
+func (ce *coordinatorEvents[EventID, PlayerID]) OnEncounterStartEvent(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID]) {
+	for _, handle := range ce.encounterStartEventHandles {
+		handle(coordinator, encounter)
+	}
+}
+
+// RegEncounterDamageEvent 注册世界事件伤害事件，当触发事件时，伤害贡献已经被记录，该事件将在 Coordinator 的分流渠道中执行
+func (ce *coordinatorEvents[EventID, PlayerID]) RegEncounterDamageEvent(handle EncounterDamageEventHandle[EventID, PlayerID]) {
+	ce.encounterDamageEventHandles = append(ce.encounterDamageEventHandles, handle)
+}
+
+// OnEncounterDamageEvent 世界事件伤害事件
+func (ce *coordinatorEvents[EventID, PlayerID]) OnEncounterDamageEvent(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID], player PlayerID, damage int64) {
+	for _, handle := range ce.encounterDamageEventHandles {
+		handle(coordinator, encounter, player, damage)
+	}
+}
+
+// RegEncounterEndEvent 注册世界事件结束事件，可通过 Encounter.GetState 获取结束的原因，该事件将在 Coordinator 的分流渠道中执行
+func (ce *coordinatorEvents[EventID, PlayerID]) RegEncounterEndEvent(handle EncounterEndEventHandle[EventID, PlayerID]) {
+	ce.encounterEndEventHandles = append(ce.encounterEndEventHandles, handle)
+}
+
+// OnEncounterEndEvent 世界事件结束事件
+func (ce *coordinatorEvents[EventID, PlayerID]) OnEncounterEndEvent(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID]) {
+	for _, handle := range ce.encounterEndEventHandles {
+		handle(coordinator, encounter)
+	}
+}
+
+// RegEncounterSettledEvent 注册世界事件结算完成事件，当触发事件时，所有参与者的奖励邮件均已发送
+//   - failed 中包含了发送奖励邮件失败的参与者及其错误，可用于补发
+//   - 被取消的世界事件不会触发该事件
+func (ce *coordinatorEvents[EventID, PlayerID]) RegEncounterSettledEvent(handle EncounterSettledEventHandle[EventID, PlayerID]) {
+	ce.encounterSettledEventHandles = append(ce.encounterSettledEventHandles, handle)
+}
+
+// OnEncounterSettledEvent 世界事件结算完成事件
+func (ce *coordinatorEvents[EventID, PlayerID]) OnEncounterSettledEvent(coordinator *Coordinator[EventID, PlayerID], encounter *Encounter[EventID, PlayerID], settlements []Settlement[EventID, PlayerID], failed map[PlayerID]error) {
+	for _, handle := range ce.encounterSettledEventHandles {
+		handle(coordinator, encounter, settlements, failed)
+	}
+}
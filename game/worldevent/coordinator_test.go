@@ -0,0 +1,47 @@
+package worldevent_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/game/worldevent"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestCoordinator_Damage(t *testing.T) {
+	var mails []string
+	var state worldevent.EncounterState
+	srv := server.New(server.NetworkNone)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		coordinator := worldevent.NewCoordinator[string, string](srv, worldevent.MailerFunc[string, string](func(settlement worldevent.Settlement[string, string]) error {
+			mails = append(mails, fmt.Sprintf("%s:%d:%d:%v", settlement.Player, settlement.Rank, settlement.Damage, settlement.Killer))
+			return nil
+		}))
+		coordinator.RegEncounterStartEvent(func(coordinator *worldevent.Coordinator[string, string], encounter *worldevent.Encounter[string, string]) {
+			for _, player := range []string{"a", "b", "a", "c", "b"} {
+				_ = coordinator.Damage(encounter.GetID(), player, 30)
+			}
+		})
+		coordinator.RegEncounterSettledEvent(func(coordinator *worldevent.Coordinator[string, string], encounter *worldevent.Encounter[string, string], settlements []worldevent.Settlement[string, string], failed map[string]error) {
+			state = encounter.GetState()
+			srv.Shutdown()
+		})
+		options := worldevent.NewEncounterOptions().WithDelay(time.Millisecond * 10).WithHealth(120).WithRankCount(2)
+		if _, err := coordinator.Spawn("dragon", options); err != nil {
+			t.Error(err)
+		}
+		if _, err := coordinator.Spawn("dragon"); err != worldevent.ErrEncounterExist {
+			t.Errorf("spawn twice should return ErrEncounterExist, got: %v", err)
+		}
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if state != worldevent.EncounterStateKilled {
+		t.Fatalf("unexpected state: %s", state)
+	}
+	if expected := "[a:1:60:false b:2:30:false c:0:30:true]"; fmt.Sprint(mails) != expected {
+		t.Fatalf("unexpected mails: %v", mails)
+	}
+}
@@ -0,0 +1,3 @@
+// Package worldevent 提供了世界事件协调器，用于发起定时开启的全服首领等世界事件，在独立的分流渠道中汇总大量玩家的伤害贡献，
+// 并在事件结束后根据贡献排名通过邮件发放结算奖励
+package worldevent
@@ -0,0 +1,206 @@
+package worldevent
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/leaderboard"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EncounterState 世界事件的状态
+type EncounterState int32
+
+const (
+	EncounterStatePending  EncounterState = iota // 等待开启
+	EncounterStateActive                         // 进行中
+	EncounterStateKilled                         // 首领被击杀
+	EncounterStateTimeout                        // 持续时长到达
+	EncounterStateCanceled                       // 被取消，不会进行结算
+)
+
+var encounterStateNames = map[EncounterState]string{
+	EncounterStatePending:  "Pending",
+	EncounterStateActive:   "Active",
+	EncounterStateKilled:   "Killed",
+	EncounterStateTimeout:  "Timeout",
+	EncounterStateCanceled: "Canceled",
+}
+
+// String 获取世界事件状态的名称
+func (s EncounterState) String() string {
+	return encounterStateNames[s]
+}
+
+// IsEnded 检查世界事件是否已结束
+func (s EncounterState) IsEnded() bool {
+	return s >= EncounterStateKilled
+}
+
+// Settlement 世界事件结束后单个参与者的结算信息
+type Settlement[EventID comparable, PlayerID comparable] struct {
+	EventID EventID        // 世界事件 ID
+	Player  PlayerID       // 参与者
+	Rank    int            // 贡献排名，从 1 开始，排名之外的参与者为 0
+	Damage  int64          // 累计伤害贡献
+	State   EncounterState // 世界事件的结束状态
+	Killer  bool           // 是否为击杀首领的参与者
+}
+
+// newEncounter 创建世界事件
+func newEncounter[EventID comparable, PlayerID comparable](id EventID, options *EncounterOptions) *Encounter[EventID, PlayerID] {
+	return &Encounter[EventID, PlayerID]{
+		id:            id,
+		options:       options,
+		contributions: make(map[PlayerID]int64),
+		ranking:       leaderboard.NewBinarySearch[PlayerID, int64](leaderboard.WithBinarySearchCount[PlayerID, int64](options.rankCount)),
+	}
+}
+
+// Encounter 由 Coordinator 发起的世界事件，例如全服首领
+//   - 伤害贡献的汇总及状态的变更均在 Coordinator 的分流渠道中执行，查询函数是线程安全的
+type Encounter[EventID comparable, PlayerID comparable] struct {
+	id            EventID
+	options       *EncounterOptions
+	state         atomic.Int32
+	rw            sync.RWMutex
+	contributions map[PlayerID]int64                         // 参与者 -> 累计伤害
+	ranking       *leaderboard.BinarySearch[PlayerID, int64] // 贡献排名
+	dealt         int64                                      // 累计伤害
+	killer        *PlayerID
+	startAt       time.Time
+	endAt         time.Time
+	startMessage  *server.DelayedMessage
+	endMessage    *server.DelayedMessage
+}
+
+// GetID 获取世界事件 ID
+func (e *Encounter[EventID, PlayerID]) GetID() EventID {
+	return e.id
+}
+
+// GetState 获取世界事件的状态
+func (e *Encounter[EventID, PlayerID]) GetState() EncounterState {
+	return EncounterState(e.state.Load())
+}
+
+// GetHealth 获取首领的生命值，未通过 EncounterOptions.WithHealth 设置时为 0
+func (e *Encounter[EventID, PlayerID]) GetHealth() int64 {
+	return e.options.health
+}
+
+// GetRemainingHealth 获取首领的剩余生命值，未通过 EncounterOptions.WithHealth 设置时为 0
+func (e *Encounter[EventID, PlayerID]) GetRemainingHealth() int64 {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	if remaining := e.options.health - e.dealt; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// GetDamage 获取所有参与者的累计伤害
+func (e *Encounter[EventID, PlayerID]) GetDamage() int64 {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	return e.dealt
+}
+
+// GetContribution 获取特定参与者的累计伤害贡献
+func (e *Encounter[EventID, PlayerID]) GetContribution(player PlayerID) int64 {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	return e.contributions[player]
+}
+
+// GetRank 获取特定参与者的贡献排名，排名从 1 开始，未参与或在排名之外时返回 0
+func (e *Encounter[EventID, PlayerID]) GetRank(player PlayerID) int {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	return e.ranking.GetRankDefault(player, -1) + 1
+}
+
+// GetTopContributors 获取贡献排名前 n 的参与者
+func (e *Encounter[EventID, PlayerID]) GetTopContributors(n int) []PlayerID {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	players, _ := e.ranking.GetCompetitorWithRange(1, n)
+	return players
+}
+
+// GetParticipantCount 获取参与者数量
+func (e *Encounter[EventID, PlayerID]) GetParticipantCount() int {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	return len(e.contributions)
+}
+
+// GetKiller 获取击杀首领的参与者，当首领未被击杀时 ok 为 false
+func (e *Encounter[EventID, PlayerID]) GetKiller() (player PlayerID, ok bool) {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	if e.killer == nil {
+		return player, false
+	}
+	return *e.killer, true
+}
+
+// GetStartTime 获取世界事件的开启时间，尚未开启时为零值
+func (e *Encounter[EventID, PlayerID]) GetStartTime() time.Time {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	return e.startAt
+}
+
+// GetEndTime 获取世界事件的结束时间，尚未结束时为零值
+func (e *Encounter[EventID, PlayerID]) GetEndTime() time.Time {
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+	return e.endAt
+}
+
+// contribute 记录参与者的伤害贡献，返回首领是否因此被击杀
+func (e *Encounter[EventID, PlayerID]) contribute(player PlayerID, damage int64) bool {
+	e.rw.Lock()
+	defer e.rw.Unlock()
+	total := e.contributions[player] + damage
+	e.contributions[player] = total
+	e.ranking.Competitor(player, total)
+	e.dealt += damage
+	if e.options.health > 0 && e.dealt >= e.options.health {
+		e.killer = &player
+		return true
+	}
+	return false
+}
+
+// settle 生成所有参与者的结算信息，按照贡献从高到低排序
+func (e *Encounter[EventID, PlayerID]) settle(state EncounterState) []Settlement[EventID, PlayerID] {
+	e.rw.Lock()
+	defer e.rw.Unlock()
+	e.endAt = time.Now()
+	settlements := make([]Settlement[EventID, PlayerID], 0, len(e.contributions))
+	for player, damage := range e.contributions {
+		settlements = append(settlements, Settlement[EventID, PlayerID]{
+			EventID: e.id,
+			Player:  player,
+			Rank:    e.ranking.GetRankDefault(player, -1) + 1,
+			Damage:  damage,
+			State:   state,
+			Killer:  e.killer != nil && *e.killer == player,
+		})
+	}
+	sort.SliceStable(settlements, func(i, j int) bool {
+		ri, rj := settlements[i].Rank, settlements[j].Rank
+		switch {
+		case ri > 0 && rj > 0:
+			return ri < rj
+		case ri > 0 || rj > 0:
+			return ri > 0
+		default:
+			return settlements[i].Damage > settlements[j].Damage
+		}
+	})
+	return settlements
+}
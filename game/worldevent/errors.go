@@ -0,0 +1,10 @@
+package worldevent
+
+import "errors"
+
+var (
+	// ErrEncounterExist 世界事件已存在
+	ErrEncounterExist = errors.New("encounter already exists")
+	// ErrEncounterNotExist 世界事件不存在
+	ErrEncounterNotExist = errors.New("encounter not exist")
+)
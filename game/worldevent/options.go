@@ -0,0 +1,68 @@
+package worldevent
+
+import "time"
+
+const (
+	DefaultDuration  = time.Hour // 默认世界事件持续时长
+	DefaultRankCount = 100       // 默认参与排名的玩家数量
+)
+
+// NewEncounterOptions 创建世界事件选项
+func NewEncounterOptions() *EncounterOptions {
+	return &EncounterOptions{}
+}
+
+// mergeEncounterOptions 合并世界事件选项
+func mergeEncounterOptions(options ...*EncounterOptions) *EncounterOptions {
+	result := &EncounterOptions{
+		duration:  DefaultDuration,
+		rankCount: DefaultRankCount,
+	}
+	for _, option := range options {
+		if option.delay > 0 {
+			result.delay = option.delay
+		}
+		if option.duration > 0 {
+			result.duration = option.duration
+		}
+		if option.health > 0 {
+			result.health = option.health
+		}
+		if option.rankCount > 0 {
+			result.rankCount = option.rankCount
+		}
+	}
+	return result
+}
+
+// EncounterOptions 世界事件选项
+type EncounterOptions struct {
+	delay     time.Duration // 开启前的延迟时长
+	duration  time.Duration // 开启后的持续时长
+	health    int64         // 首领生命值
+	rankCount int           // 参与排名的玩家数量
+}
+
+// WithDelay 设置世界事件在发起后延迟开启的时长，默认为立即开启
+func (eo *EncounterOptions) WithDelay(delay time.Duration) *EncounterOptions {
+	eo.delay = delay
+	return eo
+}
+
+// WithDuration 设置世界事件开启后的持续时长，到达时长后将以超时结束，默认为 DefaultDuration
+func (eo *EncounterOptions) WithDuration(duration time.Duration) *EncounterOptions {
+	eo.duration = duration
+	return eo
+}
+
+// WithHealth 设置首领的生命值，累计伤害达到生命值时将以击杀结束，默认不限制生命值，仅在持续时长到达时结束
+func (eo *EncounterOptions) WithHealth(health int64) *EncounterOptions {
+	eo.health = health
+	return eo
+}
+
+// WithRankCount 设置参与排名的玩家数量，排名之外的参与者在结算时的排名为 0，默认为 DefaultRankCount
+func (eo *EncounterOptions) WithRankCount(rankCount int) *EncounterOptions {
+	eo.rankCount = rankCount
+	return eo
+}
@@ -0,0 +1,124 @@
+package zone
+
+import (
+	"github.com/kercylan98/minotaur/game/activity"
+	"sync"
+	"time"
+)
+
+// MergeRecord 合服记录
+type MergeRecord struct {
+	Time    time.Time `json:"time"`    // 合服时间
+	Sources []string  `json:"sources"` // 被合并的区服
+}
+
+// NewCalendar 创建开服日历 Calendar 的实例
+//   - openTime 为开服时间，开服天数将在开服时间所在的时区中计算
+//   - resetOffset 为每日重置时间相对于零点的偏移，例如每日 05:00 重置时为 5 * time.Hour
+func NewCalendar(openTime time.Time, resetOffset time.Duration) *Calendar {
+	return &Calendar{
+		openTime:    openTime,
+		resetOffset: resetOffset,
+	}
+}
+
+// Calendar 开服日历，记录区服的开服时间及合服记录，并提供基于开服天数的活动开放判断
+//   - 开服天数从 1 开始，开服当日为第 1 天，每经过一次每日重置时间增加 1 天
+//   - 该实例是线程安全的
+type Calendar struct {
+	rw          sync.RWMutex
+	openTime    time.Time     // 开服时间
+	resetOffset time.Duration // 每日重置时间相对于零点的偏移
+	merges      []MergeRecord // 合服记录
+}
+
+// GetOpenTime 获取开服时间
+func (c *Calendar) GetOpenTime() time.Time {
+	return c.openTime
+}
+
+// IsOpened 检查特定时间是否已经开服
+func (c *Calendar) IsOpened(now time.Time) bool {
+	return !now.Before(c.openTime)
+}
+
+// GetOpenDay 获取特定时间的开服天数，开服前将返回 0
+func (c *Calendar) GetOpenDay(now time.Time) int {
+	if !c.IsOpened(now) {
+		return 0
+	}
+	return c.dayIndex(now) - c.dayIndex(c.openTime) + 1
+}
+
+// GetDayStart 获取第 day 个开服日的开始时间，即该日的每日重置时间
+//   - 第 1 天的开始时间早于开服时间
+func (c *Calendar) GetDayStart(day int) time.Time {
+	year, month, d := c.openTime.Add(-c.resetOffset).Date()
+	return time.Date(year, month, d+day-1, 0, 0, 0, 0, c.openTime.Location()).Add(c.resetOffset)
+}
+
+// InOpenDays 检查特定时间的开服天数是否在 [from, to] 之间，当 to 小于等于 0 时表示不限制结束天数
+func (c *Calendar) InOpenDays(now time.Time, from, to int) bool {
+	day := c.GetOpenDay(now)
+	if day == 0 || day < from {
+		return false
+	}
+	return to <= 0 || day <= to
+}
+
+// ActivityOptions 创建在第 from 个开服日开始、第 to 个开服日结束后结束的活动选项，可用于 activity.LoadOrRefreshActivity
+//   - 当 from 为 1 时，活动将在开服时开始
+//   - 当 to 小于等于 0 时活动不会结束
+func (c *Calendar) ActivityOptions(from, to int) *activity.Options {
+	start := c.GetDayStart(from)
+	if start.Before(c.openTime) {
+		start = c.openTime
+	}
+	options := activity.NewOptions().WithStartTime(start)
+	if to > 0 {
+		options.WithEndTime(c.GetDayStart(to + 1))
+	}
+	return options
+}
+
+// AddMerge 添加合服记录，通常由 Merger 在合服完成后调用，也可用于在启动时恢复持久化的合服记录
+func (c *Calendar) AddMerge(record MergeRecord) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+	c.merges = append(c.merges, record)
+}
+
+// GetMerges 获取所有合服记录
+func (c *Calendar) GetMerges() []MergeRecord {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	return append([]MergeRecord(nil), c.merges...)
+}
+
+// GetMergeCount 获取合服次数
+func (c *Calendar) GetMergeCount() int {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	return len(c.merges)
+}
+
+// GetMergeDay 获取特定时间距离最后一次合服的天数，合服当日为第 1 天，未合服或合服前将返回 0
+//   - 可用于合服活动的开放判断
+func (c *Calendar) GetMergeDay(now time.Time) int {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	if len(c.merges) == 0 {
+		return 0
+	}
+	last := c.merges[len(c.merges)-1].Time
+	if now.Before(last) {
+		return 0
+	}
+	return c.dayIndex(now) - c.dayIndex(last) + 1
+}
+
+// dayIndex 获取特定时间在开服时间所在时区中扣除每日重置偏移后的自然日序号
+func (c *Calendar) dayIndex(t time.Time) int {
+	year, month, day := t.In(c.openTime.Location()).Add(-c.resetOffset).Date()
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
@@ -0,0 +1,40 @@
+package zone_test
+
+import (
+	"github.com/kercylan98/minotaur/game/zone"
+	"testing"
+	"time"
+)
+
+func TestCalendar_GetOpenDay(t *testing.T) {
+	open := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	calendar := zone.NewCalendar(open, time.Hour*5)
+
+	var cases = []struct {
+		now time.Time
+		day int
+	}{
+		{open.Add(-time.Minute), 0},
+		{open, 1},
+		{time.Date(2024, 1, 2, 4, 59, 0, 0, time.UTC), 1},
+		{time.Date(2024, 1, 2, 5, 0, 0, 0, time.UTC), 2},
+		{time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC), 8},
+	}
+	for _, c := range cases {
+		if day := calendar.GetOpenDay(c.now); day != c.day {
+			t.Fatalf("%s: expected day %d, got %d", c.now, c.day, day)
+		}
+	}
+
+	if start := calendar.GetDayStart(3); !start.Equal(time.Date(2024, 1, 3, 5, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected day start: %s", start)
+	}
+	if !calendar.InOpenDays(time.Date(2024, 1, 3, 6, 0, 0, 0, time.UTC), 3, 7) || calendar.InOpenDays(open, 2, 0) {
+		t.Fatal("unexpected open days gating")
+	}
+
+	calendar.AddMerge(zone.MergeRecord{Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Sources: []string{"s2"}})
+	if day := calendar.GetMergeDay(time.Date(2024, 2, 1, 6, 0, 0, 0, time.UTC)); day != 2 {
+		t.Fatalf("unexpected merge day: %d", day)
+	}
+}
@@ -0,0 +1,3 @@
+// Package zone 提供了区服生命周期相关的辅助工具，包括记录开服时间并基于开服天数限制活动开放的开服日历 Calendar，
+// 以及在合服时重映射 ID 并按顺序触发排行榜、公会等数据对账回调的合服器 Merger
+package zone
@@ -0,0 +1,10 @@
+package zone
+
+import "errors"
+
+var (
+	// ErrNoSource 合服计划中没有被合并的区服
+	ErrNoSource = errors.New("merge plan has no source")
+	// ErrSourceMerged 被合并的区服已经被合并过
+	ErrSourceMerged = errors.New("source already merged")
+)
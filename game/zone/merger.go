@@ -0,0 +1,105 @@
+package zone
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Plan 合服计划
+type Plan struct {
+	Target  string    // 合并后的目标区服
+	Sources []string  // 被合并的区服
+	Time    time.Time // 合服时间
+}
+
+// RemapFunc 为被合并的区服生成 ID 映射，返回的映射中仅需包含发生变化的 ID，例如与目标区服冲突的玩家 ID
+type RemapFunc[ID comparable] func(plan Plan, source string) (map[ID]ID, error)
+
+// NewMerger 创建合服器 Merger 的实例，当 remap 为 nil 时所有 ID 均保持不变
+func NewMerger[ID comparable](calendar *Calendar, remap RemapFunc[ID]) *Merger[ID] {
+	return &Merger[ID]{
+		mergerEvents: new(mergerEvents[ID]),
+		calendar:     calendar,
+		remap:        remap,
+		mappings:     make(map[string]map[ID]ID),
+	}
+}
+
+// Merger 合服器，按照固定的顺序执行合服流程
+//   - 首先为所有被合并的区服生成 ID 映射，任一区服生成失败时将不会产生任何变更
+//   - 随后依次触发 ID 重映射事件、排行榜对账事件及公会对账事件，最终在开服日历中添加合服记录
+//   - 该实例是线程安全的，合服流程将在调用 Merge 的协程中同步执行
+type Merger[ID comparable] struct {
+	*mergerEvents[ID]
+	calendar *Calendar
+	remap    RemapFunc[ID]
+	merging  sync.Mutex           // 保证合服流程串行执行
+	rw       sync.RWMutex         // 保护 mappings，合服事件中可以调用 MapID 等查询函数
+	mappings map[string]map[ID]ID // 区服 -> 原 ID -> 新 ID
+}
+
+// Merge 执行合服计划
+func (m *Merger[ID]) Merge(plan Plan) error {
+	if len(plan.Sources) == 0 {
+		return ErrNoSource
+	}
+	m.merging.Lock()
+	defer m.merging.Unlock()
+
+	var mappings = make(map[string]map[ID]ID, len(plan.Sources))
+	for _, source := range plan.Sources {
+		if m.IsMerged(source) {
+			return fmt.Errorf("%w: %s", ErrSourceMerged, source)
+		}
+		if _, exist := mappings[source]; exist {
+			continue
+		}
+		var mapping map[ID]ID
+		if m.remap != nil {
+			var err error
+			if mapping, err = m.remap(plan, source); err != nil {
+				return fmt.Errorf("remap %s: %w", source, err)
+			}
+		}
+		if mapping == nil {
+			mapping = make(map[ID]ID)
+		}
+		mappings[source] = mapping
+	}
+
+	for _, source := range plan.Sources {
+		if mapping, exist := mappings[source]; exist {
+			m.rw.Lock()
+			m.mappings[source] = mapping
+			m.rw.Unlock()
+			delete(mappings, source)
+			m.OnIDRemapEvent(m, source, mapping)
+		}
+	}
+	m.OnLeaderboardReconcileEvent(m, plan)
+	m.OnGuildReconcileEvent(m, plan)
+	if m.calendar != nil {
+		m.calendar.AddMerge(MergeRecord{Time: plan.Time, Sources: append([]string(nil), plan.Sources...)})
+	}
+	return nil
+}
+
+// MapID 获取被合并区服中的 ID 在合服后的 ID，当 ID 未发生变化时将返回原 ID 及 false
+//   - 可用于处理合服后仍携带原 ID 的请求，例如客户端缓存的登录凭证或尚未领取的邮件
+func (m *Merger[ID]) MapID(source string, id ID) (ID, bool) {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	if mapped, exist := m.mappings[source][id]; exist {
+		return mapped, true
+	}
+	return id, false
+}
+
+// IsMerged 检查区服是否已经被合并
+func (m *Merger[ID]) IsMerged(source string) bool {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	_, exist := m.mappings[source]
+	return exist
+}
@@ -0,0 +1,50 @@
+package zone
+
+type (
+	IDRemapEventHandle[ID comparable]              func(merger *Merger[ID], source string, mapping map[ID]ID)
+	LeaderboardReconcileEventHandle[ID comparable] func(merger *Merger[ID], plan Plan)
+	GuildReconcileEventHandle[ID comparable]       func(merger *Merger[ID], plan Plan)
+)
+
+type mergerEvents[ID comparable] struct {
+	idRemapEventHandles              []IDRemapEventHandle[ID]
+	leaderboardReconcileEventHandles []LeaderboardReconcileEventHandle[ID]
+	guildReconcileEventHandles       []GuildReconcileEventHandle[ID]
+}
+
+// RegIDRemapEvent 注册 ID 重映射事件，当被合并区服的 ID 映射生成后触发，可用于更新玩家、道具等数据中引用的 ID
+//   - mapping 中仅包含发生变化的 ID
+func (me *mergerEvents[ID]) RegIDRemapEvent(handle IDRemapEventHandle[ID]) {
+	me.idRemapEventHandles = append(me.idRemapEventHandles, handle)
+}
+
+// OnIDRemapEvent ID 重映射事件
+func (me *mergerEvents[ID]) OnIDRemapEvent(merger *Merger[ID], source string, mapping map[ID]ID) {
+	for _, handle := range me.idRemapEventHandles {
+		handle(merger, source, mapping)
+	}
+}
+
+// RegLeaderboardReconcileEvent 注册排行榜对账事件，在所有 ID 重映射事件之后触发，可用于合并各区服的排行榜
+func (me *mergerEvents[ID]) RegLeaderboardReconcileEvent(handle LeaderboardReconcileEventHandle[ID]) {
+	me.leaderboardReconcileEventHandles = append(me.leaderboardReconcileEventHandles, handle)
+}
+
+// OnLeaderboardReconcileEvent 排行榜对账事件
+func (me *mergerEvents[ID]) OnLeaderboardReconcileEvent(merger *Merger[ID], plan Plan) {
+	for _, handle := range me.leaderboardReconcileEventHandles {
+		handle(merger, plan)
+	}
+}
+
+// RegGuildReconcileEvent 注册公会对账事件，在排行榜对账事件之后触发，可用于处理公会重名、成员上限等问题
+func (me *mergerEvents[ID]) RegGuildReconcileEvent(handle GuildReconcileEventHandle[ID]) {
+	me.guildReconcileEventHandles = append(me.guildReconcileEventHandles, handle)
+}
+
+// OnGuildReconcileEvent 公会对账事件
+func (me *mergerEvents[ID]) OnGuildReconcileEvent(merger *Merger[ID], plan Plan) {
+	for _, handle := range me.guildReconcileEventHandles {
+		handle(merger, plan)
+	}
+}
@@ -0,0 +1,50 @@
+package zone_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/game/zone"
+	"testing"
+	"time"
+)
+
+func TestMerger_Merge(t *testing.T) {
+	calendar := zone.NewCalendar(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+	merger := zone.NewMerger[int](calendar, func(plan zone.Plan, source string) (map[int]int, error) {
+		if source == "s3" {
+			return nil, errors.New("unavailable")
+		}
+		return map[int]int{1: 1001}, nil
+	})
+	var steps []string
+	merger.RegIDRemapEvent(func(merger *zone.Merger[int], source string, mapping map[int]int) {
+		steps = append(steps, fmt.Sprint("remap:", source, mapping))
+	})
+	merger.RegLeaderboardReconcileEvent(func(merger *zone.Merger[int], plan zone.Plan) {
+		id, _ := merger.MapID("s2", 1)
+		steps = append(steps, fmt.Sprint("leaderboard:", id))
+	})
+	merger.RegGuildReconcileEvent(func(merger *zone.Merger[int], plan zone.Plan) {
+		steps = append(steps, "guild")
+	})
+
+	if err := merger.Merge(zone.Plan{Target: "s1", Sources: []string{"s2", "s3"}}); err == nil || merger.IsMerged("s2") {
+		t.Fatalf("merge should fail without changes, err: %v", err)
+	}
+	if err := merger.Merge(zone.Plan{Target: "s1", Sources: []string{"s2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := merger.Merge(zone.Plan{Target: "s1", Sources: []string{"s2"}}); !errors.Is(err, zone.ErrSourceMerged) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "[remap:s2map[1:1001] leaderboard:1001 guild]"; fmt.Sprint(steps) != expected {
+		t.Fatalf("unexpected steps: %v", steps)
+	}
+	if id, ok := merger.MapID("s2", 2); id != 2 || ok {
+		t.Fatalf("unexpected id: %d", id)
+	}
+	if calendar.GetMergeCount() != 1 {
+		t.Fatalf("unexpected merge count: %d", calendar.GetMergeCount())
+	}
+}
@@ -66,7 +66,7 @@ func (slf *Xlsx) GetFields() []pce.DataField {
 			return field, false
 		}
 
-		if slf.checkFieldInvalid(field) {
+		if checkFieldInvalid(slf.exportType, field) {
 			return field, false
 		}
 
@@ -173,14 +173,15 @@ func (slf *Xlsx) get(x, y int) *xlsx.Cell {
 	return row.Cells[x]
 }
 
-func (slf *Xlsx) checkFieldInvalid(field pce.DataField) bool {
+// checkFieldInvalid 检查字段在特定导出类型下是否无效
+func checkFieldInvalid(exportType XlsxExportType, field pce.DataField) bool {
 	switch strings.ToLower(field.ExportType) {
 	case "s":
-		if slf.exportType != XlsxExportTypeServer {
+		if exportType != XlsxExportTypeServer {
 			return true
 		}
 	case "c":
-		if slf.exportType != XlsxExportTypeClient {
+		if exportType != XlsxExportTypeClient {
 			return true
 		}
 	case "sc", "cs":
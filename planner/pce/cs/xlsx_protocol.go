@@ -0,0 +1,76 @@
+package cs
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/planner/pce"
+	"github.com/kercylan98/minotaur/utils/str"
+	"github.com/tealeg/xlsx"
+	"strconv"
+	"strings"
+)
+
+// NewXlsxProtocol 创建内置的 Xlsx 协议定义表
+//   - 第一行为表头，从第二行开始每行声明一个字段，列依次为：协议号、消息名称、消息描述、字段名称、字段类型、字段描述、导出类型
+//   - 协议号不为空的行将开始声明新的消息，该行及其后协议号为空的行中的字段均属于该消息
+//   - 字段类型及导出类型与配置表一致，以 # 开头的行将被忽略
+func NewXlsxProtocol(sheet *xlsx.Sheet, exportType XlsxExportType) *XlsxProtocol {
+	return &XlsxProtocol{
+		sheet:      sheet,
+		exportType: exportType,
+	}
+}
+
+// XlsxProtocol 内置的 Xlsx 协议定义表
+type XlsxProtocol struct {
+	sheet      *xlsx.Sheet
+	exportType XlsxExportType
+}
+
+func (slf *XlsxProtocol) GetDisplayName() string {
+	return slf.sheet.Name
+}
+
+func (slf *XlsxProtocol) GetMessages() []pce.ProtocolMessage {
+	var messages []pce.ProtocolMessage
+	for y := 1; y < len(slf.sheet.Rows); y++ {
+		if strings.HasPrefix(slf.get(0, y), "#") {
+			continue
+		}
+		if opcode := slf.get(0, y); len(opcode) > 0 {
+			code, err := strconv.ParseUint(opcode, 10, 32)
+			if err != nil {
+				panic(fmt.Errorf("protocol %s row %d: invalid opcode %s", slf.GetDisplayName(), y+1, opcode))
+			}
+			messages = append(messages, pce.ProtocolMessage{
+				Opcode: uint32(code),
+				Name:   str.FirstUpper(slf.get(1, y)),
+				Desc:   slf.get(2, y),
+			})
+		}
+		if len(messages) == 0 {
+			continue
+		}
+		field := pce.DataField{
+			Index:      y,
+			Name:       str.FirstUpper(slf.get(3, y)),
+			Type:       slf.get(4, y),
+			Desc:       slf.get(5, y),
+			ExportType: slf.get(6, y),
+		}
+		if len(field.Name) == 0 || len(field.Type) == 0 || checkFieldInvalid(slf.exportType, field) {
+			continue
+		}
+		message := &messages[len(messages)-1]
+		message.Fields = append(message.Fields, field)
+	}
+	return messages
+}
+
+// get 获取单元格去除首尾空白及换行后的内容
+func (slf *XlsxProtocol) get(x, y int) string {
+	row := slf.sheet.Rows[y]
+	if x >= len(row.Cells) {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(strings.ReplaceAll(row.Cells[x].String(), "\r", " "), "\n", " "))
+}
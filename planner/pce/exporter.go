@@ -1,5 +1,7 @@
 package pce
 
+import "fmt"
+
 // NewExporter 创建导出器
 func NewExporter() *Exporter {
 	return &Exporter{}
@@ -26,3 +28,24 @@ func (slf *Exporter) ExportData(tmpl DataTmpl, data map[any]any) ([]byte, error)
 
 	return []byte(raw), nil
 }
+
+// ExportProtocol 导出协议，当存在重复的协议号或消息名称时将返回错误
+func (slf *Exporter) ExportProtocol(tmpl ProtocolTmpl, protocols ...*TmplProtocol) ([]byte, error) {
+	var opcodes = make(map[uint32]string, len(protocols))
+	var names = make(map[string]struct{}, len(protocols))
+	for _, protocol := range protocols {
+		if name, exist := opcodes[protocol.Opcode]; exist {
+			return nil, fmt.Errorf("opcode %d is duplicated: %s, %s", protocol.Opcode, name, protocol.Name)
+		}
+		if _, exist := names[protocol.Name]; exist {
+			return nil, fmt.Errorf("protocol message %s is duplicated", protocol.Name)
+		}
+		opcodes[protocol.Opcode] = protocol.Name
+		names[protocol.Name] = struct{}{}
+	}
+	raw, err := tmpl.Render(protocols...)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(raw), nil
+}
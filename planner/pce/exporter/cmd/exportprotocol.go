@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"github.com/kercylan98/minotaur/planner/pce"
+	"github.com/kercylan98/minotaur/planner/pce/cs"
+	"github.com/kercylan98/minotaur/planner/pce/tmpls"
+	"github.com/kercylan98/minotaur/utils/collection"
+	"github.com/kercylan98/minotaur/utils/file"
+	"github.com/kercylan98/minotaur/utils/str"
+	"github.com/spf13/cobra"
+	"github.com/tealeg/xlsx"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	var filePath, outPath, clientPath, exclude string
+
+	exportProtocol := &cobra.Command{
+		Use:   "protocol",
+		Short: "Export go language protocol code and optional typescript client classes | 导出 go 语言协议代码及可选的 typescript 客户端协议类",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fpd, err := file.IsDir(filePath)
+			if err != nil {
+				return err
+			}
+
+			var xlsxFiles []string
+			if fpd {
+				files, err := os.ReadDir(filePath)
+				if err != nil {
+					return err
+				}
+				for _, f := range files {
+					if f.IsDir() || !strings.HasSuffix(f.Name(), ".xlsx") || strings.HasPrefix(f.Name(), "~") {
+						continue
+					}
+					xlsxFiles = append(xlsxFiles, filepath.Join(filePath, f.Name()))
+				}
+			} else {
+				xlsxFiles = append(xlsxFiles, filePath)
+			}
+
+			var server, client []*pce.TmplProtocol
+			var exporter = pce.NewExporter()
+			loader := pce.NewLoader(pce.GetFields())
+
+			excludes := collection.ConvertSliceToBoolMap(str.SplitTrimSpace(exclude, ","))
+			for _, xlsxFile := range xlsxFiles {
+				xf, err := xlsx.OpenFile(xlsxFile)
+				if err != nil {
+					return err
+				}
+
+				for _, sheet := range xf.Sheets {
+					if strings.HasPrefix(sheet.Name, "#") || excludes[sheet.Name] {
+						continue
+					}
+					server = append(server, loader.LoadProtocol(cs.NewXlsxProtocol(sheet, cs.XlsxExportTypeServer))...)
+					client = append(client, loader.LoadProtocol(cs.NewXlsxProtocol(sheet, cs.XlsxExportTypeClient))...)
+				}
+			}
+
+			if filepath.Ext(outPath) == "" {
+				outPath = filepath.Join(outPath, "protocol.go")
+			}
+			_ = os.MkdirAll(filepath.Dir(outPath), os.ModePerm)
+			raw, err := exporter.ExportProtocol(tmpls.NewGolangProtocol(filepath.Base(filepath.Dir(outPath))), server...)
+			if err != nil {
+				return err
+			}
+			if err = file.WriterFile(outPath, raw); err != nil {
+				return err
+			}
+			_ = exec.Command("gofmt", "-w", outPath).Run()
+
+			if len(clientPath) == 0 {
+				return nil
+			}
+			if filepath.Ext(clientPath) == "" {
+				clientPath = filepath.Join(clientPath, "protocol.ts")
+			}
+			_ = os.MkdirAll(filepath.Dir(clientPath), os.ModePerm)
+			if raw, err = exporter.ExportProtocol(tmpls.NewTypeScriptProtocol(), client...); err != nil {
+				return err
+			}
+			return file.WriterFile(clientPath, raw)
+		},
+	}
+
+	exportProtocol.Flags().StringVarP(&filePath, "xlsx", "f", "", "xlsx file path or directory path | 协议定义 xlsx 文件路径或所在目录路径")
+	exportProtocol.Flags().StringVarP(&outPath, "output", "o", "", "output path | 输出的 go 文件路径")
+	exportProtocol.Flags().StringVarP(&clientPath, "client", "c", "", "typescript client output path, not exported when empty | 输出的 typescript 客户端协议文件路径，为空时不导出")
+	exportProtocol.Flags().StringVarP(&exclude, "exclude", "e", "", "excluded sheet names (comma separated) | 排除的工作表名称（英文逗号分隔）")
+	if err := exportProtocol.MarkFlagRequired("xlsx"); err != nil {
+		panic(err)
+	}
+	if err := exportProtocol.MarkFlagRequired("output"); err != nil {
+		panic(err)
+	}
+
+	rootCmd.AddCommand(exportProtocol)
+}
@@ -39,6 +39,22 @@ func (slf *Loader) LoadStruct(config Config) *TmplStruct {
 	return tmpl
 }
 
+// LoadProtocol 加载协议消息结构
+func (slf *Loader) LoadProtocol(protocol Protocol) []*TmplProtocol {
+	var protocols []*TmplProtocol
+	for _, message := range protocol.GetMessages() {
+		var tmpl = &TmplStruct{
+			Name: str.FirstUpper(message.Name),
+			Desc: message.Desc,
+		}
+		for _, field := range message.Fields {
+			tmpl.addField(tmpl.Name, str.FirstUpper(field.Name), field.Desc, field.Type, slf.fields)
+		}
+		protocols = append(protocols, &TmplProtocol{TmplStruct: tmpl, Opcode: message.Opcode})
+	}
+	return protocols
+}
+
 // LoadData 加载配置并得到配置数据
 func (slf *Loader) LoadData(config Config) map[any]any {
 	var source = make(map[any]any)
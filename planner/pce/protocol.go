@@ -0,0 +1,19 @@
+package pce
+
+// Protocol 协议解析接口
+//   - 用于将协议定义表解析为可供分析的协议消息，使协议与配置可以在同一套工具链中维护
+//   - 可以在 cs 包中找到内置提供的实现，例如 cs.XlsxProtocol
+type Protocol interface {
+	// GetDisplayName 协议显示名称
+	GetDisplayName() string
+	// GetMessages 获取协议消息
+	GetMessages() []ProtocolMessage
+}
+
+// ProtocolMessage 协议消息定义
+type ProtocolMessage struct {
+	Opcode uint32      // 协议号
+	Name   string      // 消息名称
+	Desc   string      // 消息描述
+	Fields []DataField // 消息字段
+}
@@ -0,0 +1,62 @@
+package pce_test
+
+import (
+	"github.com/kercylan98/minotaur/planner/pce"
+	"github.com/kercylan98/minotaur/planner/pce/tmpls"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+type protocol []pce.ProtocolMessage
+
+func (slf protocol) GetDisplayName() string {
+	return "protocol"
+}
+
+func (slf protocol) GetMessages() []pce.ProtocolMessage {
+	return slf
+}
+
+func TestExporter_ExportProtocol(t *testing.T) {
+	loader := pce.NewLoader(pce.GetFields())
+	protocols := loader.LoadProtocol(protocol{
+		{Opcode: 1001, Name: "loginReq", Desc: "登录请求", Fields: []pce.DataField{
+			{Name: "Account", Type: "string", Desc: "账号"},
+			{Name: "Device", Type: "{os:string,version:int}", Desc: "设备信息"},
+		}},
+		{Opcode: 1002, Name: "loginRes", Desc: "登录响应", Fields: []pce.DataField{
+			{Name: "Heroes", Type: "[]int", Desc: "英雄列表"},
+		}},
+	})
+
+	exporter := pce.NewExporter()
+	raw, err := exporter.ExportProtocol(tmpls.NewGolangProtocol("protocol"), protocols...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = parser.ParseFile(token.NewFileSet(), "protocol.go", raw, 0); err != nil {
+		t.Fatalf("generated code is invalid: %v\n%s", err, raw)
+	}
+	for _, expected := range []string{"LoginReqOpcode Opcode = 1001", "Device *LoginReqDevice", "Heroes []int"} {
+		if !strings.Contains(string(raw), expected) {
+			t.Fatalf("generated code should contain %q\n%s", expected, raw)
+		}
+	}
+
+	raw, err = exporter.ExportProtocol(tmpls.NewTypeScriptProtocol(), protocols...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"LoginRes = 1002", "Device: LoginReqDevice = new LoginReqDevice();", "Heroes: number[] = [];"} {
+		if !strings.Contains(string(raw), expected) {
+			t.Fatalf("generated client code should contain %q\n%s", expected, raw)
+		}
+	}
+
+	protocols[1].Opcode = 1001
+	if _, err = exporter.ExportProtocol(tmpls.NewGolangProtocol("protocol"), protocols...); err == nil {
+		t.Fatal("duplicated opcode should return error")
+	}
+}
@@ -0,0 +1,13 @@
+package pce
+
+// ProtocolTmpl 协议导出模板接口
+type ProtocolTmpl interface {
+	// Render 渲染模板
+	Render(protocols ...*TmplProtocol) (string, error)
+}
+
+// TmplProtocol 协议模板结构
+type TmplProtocol struct {
+	*TmplStruct        // 消息结构
+	Opcode      uint32 // 协议号
+}
@@ -0,0 +1,140 @@
+package tmpls
+
+import (
+	"github.com/kercylan98/minotaur/planner/pce"
+)
+
+// NewGolangProtocol 创建一个 Golang 协议导出模板
+func NewGolangProtocol(packageName string) *GolangProtocol {
+	return &GolangProtocol{
+		Package: packageName,
+	}
+}
+
+// GolangProtocol 协议导出模板，将生成协议号常量、消息结构及基于 JSON 的编解码函数
+type GolangProtocol struct {
+	Package   string
+	Protocols []*pce.TmplProtocol
+}
+
+func (slf *GolangProtocol) Render(protocols ...*pce.TmplProtocol) (string, error) {
+	slf.Protocols = protocols
+	return render(`// Code generated by minotaur. DO NOT EDIT.
+		package {{.Package}}
+
+		import (
+			"fmt"
+			jsonIter "github.com/json-iterator/go"
+		)
+
+		// Opcode 协议号
+		type Opcode uint32
+
+		const (
+			{{- range .Protocols}}
+				{{.Name}}Opcode Opcode = {{.Opcode}} // {{.Desc}}
+			{{- end}}
+		)
+
+		// Message 协议消息
+		type Message interface {
+			// Opcode 获取消息的协议号
+			Opcode() Opcode
+		}
+
+		var (
+			protocolJSON = jsonIter.ConfigCompatibleWithStandardLibrary
+			opcodes = []Opcode{
+				{{- range .Protocols}}
+					{{.Name}}Opcode,
+				{{- end}}
+			}
+			factories = map[Opcode]func() Message{
+				{{- range .Protocols}}
+					{{.Name}}Opcode: func() Message { return new({{.Name}}) },
+				{{- end}}
+			}
+		)
+
+		{{- range .Protocols}}
+			// {{.Name}} {{.Desc}}
+			type {{.Name}} struct {
+				{{- range .Fields}}
+					{{- if .IsSlice}}
+						{{- if .IsStruct}}
+							{{.Name}} []*{{.Struct.Name}} // {{.Desc}}
+						{{- else}}
+							{{.Name}} []{{.Type}} // {{.Desc}}
+						{{- end}}
+					{{- else}}
+						{{- if .IsStruct}}
+							{{.Name}} *{{.Struct.Name}} // {{.Desc}}
+						{{- else}}
+							{{.Name}} {{.Type}} // {{.Desc}}
+						{{- end}}
+					{{- end}}
+				{{- end}}
+			}
+
+			// Opcode 获取消息的协议号
+			func (slf *{{.Name}}) Opcode() Opcode {
+				return {{.Name}}Opcode
+			}
+		{{- end}}
+
+		{{- range .Protocols}}
+			{{- range .AllChildren}}
+				// {{.Name}} {{.Desc}}
+				type {{.Name}} struct {
+					{{- range .Fields}}
+						{{- if .IsSlice}}
+							{{- if .IsStruct}}
+								{{.Name}} []*{{.Struct.Name}} // {{.Desc}}
+							{{- else}}
+								{{.Name}} []{{.Type}} // {{.Desc}}
+							{{- end}}
+						{{- else}}
+							{{- if .IsStruct}}
+								{{.Name}} *{{.Struct.Name}} // {{.Desc}}
+							{{- else}}
+								{{.Name}} {{.Type}} // {{.Desc}}
+							{{- end}}
+						{{- end}}
+					{{- end}}
+				}
+			{{- end}}
+		{{- end}}
+
+		// Register 按照协议号顺序将所有消息注册到自定义的编解码器或路由器中
+		func Register(register func(opcode Opcode, factory func() Message)) {
+			for _, opcode := range opcodes {
+				register(opcode, factories[opcode])
+			}
+		}
+
+		// New 创建特定协议号的消息，当协议号不存在时将返回 nil
+		func New(opcode Opcode) Message {
+			if factory, exist := factories[opcode]; exist {
+				return factory()
+			}
+			return nil
+		}
+
+		// Marshal 将消息序列化为 JSON 数据
+		func Marshal(message Message) ([]byte, error) {
+			return protocolJSON.Marshal(message)
+		}
+
+		// Unmarshal 将 JSON 数据反序列化为特定协议号的消息
+		func Unmarshal(opcode Opcode, data []byte) (Message, error) {
+			message := New(opcode)
+			if message == nil {
+				return nil, fmt.Errorf("unknown opcode: %d", opcode)
+			}
+			if err := protocolJSON.Unmarshal(data, message); err != nil {
+				return nil, err
+			}
+			return message, nil
+		}
+	`, slf)
+}
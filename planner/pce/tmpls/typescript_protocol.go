@@ -0,0 +1,95 @@
+package tmpls
+
+import (
+	"github.com/kercylan98/minotaur/planner/pce"
+)
+
+// NewTypeScriptProtocol 创建一个 TypeScript 协议导出模板，用于生成客户端的协议类
+func NewTypeScriptProtocol() *TypeScriptProtocol {
+	return &TypeScriptProtocol{}
+}
+
+// TypeScriptProtocol 客户端协议导出模板，将生成协议号枚举及与服务端 JSON 结构一致的消息类
+type TypeScriptProtocol struct {
+	Protocols []*pce.TmplProtocol
+}
+
+func (slf *TypeScriptProtocol) Render(protocols ...*pce.TmplProtocol) (string, error) {
+	slf.Protocols = protocols
+	return render(`// Code generated by minotaur. DO NOT EDIT.
+
+export enum Opcode {
+{{- range .Protocols}}
+    {{.Name}} = {{.Opcode}}, // {{.Desc}}
+{{- end}}
+}
+{{- range .Protocols}}
+
+/** {{.Desc}} */
+export class {{.Name}} {
+    static readonly opcode = Opcode.{{.Name}};
+{{- range .Fields}}
+    /** {{.Desc}} */
+    {{.Name}}: {{$.GetType .}} = {{$.GetZero .}};
+{{- end}}
+}
+{{- end}}
+{{- range .Protocols}}
+{{- range .AllChildren}}
+
+/** {{.Desc}} */
+export class {{.Name}} {
+{{- range .Fields}}
+    /** {{.Desc}} */
+    {{.Name}}: {{$.GetType .}} = {{$.GetZero .}};
+{{- end}}
+}
+{{- end}}
+{{- end}}
+
+export const messages: { [opcode: number]: new () => object } = {
+{{- range .Protocols}}
+    [Opcode.{{.Name}}]: {{.Name}},
+{{- end}}
+};
+`, slf)
+}
+
+// GetType 获取字段的 TypeScript 类型
+func (slf *TypeScriptProtocol) GetType(field *pce.TmplField) string {
+	var t string
+	if field.IsStruct() {
+		t = field.Struct.Name
+	} else {
+		switch field.Type {
+		case "string":
+			t = "string"
+		case "bool":
+			t = "boolean"
+		default:
+			t = "number"
+		}
+	}
+	if field.IsSlice() {
+		t += "[]"
+	}
+	return t
+}
+
+// GetZero 获取字段的 TypeScript 零值
+func (slf *TypeScriptProtocol) GetZero(field *pce.TmplField) string {
+	switch {
+	case field.IsSlice():
+		return "[]"
+	case field.IsStruct():
+		return "new " + field.Struct.Name + "()"
+	}
+	switch field.Type {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	default:
+		return "0"
+	}
+}
@@ -0,0 +1,25 @@
+package simulator
+
+import (
+	"fmt"
+	jsonIter "github.com/json-iterator/go"
+	"os"
+	"path/filepath"
+)
+
+// ConfigLoader 创建一个从导出的 JSON 数据目录中加载配置的函数，可直接传入导出的配置代码中的 LoadWithHandle 函数
+//   - 配置文件路径为 dir/{prefix.}{sign}.json，与导出器 json 命令的输出一致
+//   - 例如：config.LoadWithHandle(simulator.ConfigLoader[config.Sign]("./json", "server")); config.Refresh()
+func ConfigLoader[Sign ~string](dir, prefix string) func(sign Sign, config any, json jsonIter.API) error {
+	return func(sign Sign, config any, json jsonIter.API) error {
+		name := fmt.Sprintf("%s.json", sign)
+		if len(prefix) > 0 {
+			name = fmt.Sprintf("%s.%s", prefix, name)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, config)
+	}
+}
@@ -0,0 +1,3 @@
+// Package simulator 提供了基于导出配置的离线数值模拟工具，例如掉落率统计及养成进度曲线，模拟结果可导出为 CSV 报告
+//   - 模拟过程中的所有随机数均来自调用方传入的 *rand.Rand，使用相同的种子可以复现模拟结果
+package simulator
@@ -0,0 +1,63 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Drop 进行 trials 次独立的掉落试验，统计各个物品的掉落次数及数量
+//   - roll 为单次掉落的逻辑，通常基于已加载的掉落配置实现，返回本次掉落的物品及其数量
+func Drop[Item comparable](r *rand.Rand, trials int, roll func(r *rand.Rand) map[Item]int64) *DropResult[Item] {
+	result := &DropResult[Item]{
+		Trials: trials,
+		Hits:   make(map[Item]int64),
+		Counts: make(map[Item]int64),
+	}
+	for i := 0; i < trials; i++ {
+		for item, count := range roll(r) {
+			result.Hits[item]++
+			result.Counts[item] += count
+		}
+	}
+	return result
+}
+
+// DropResult 掉落模拟结果
+type DropResult[Item comparable] struct {
+	Trials int            // 试验次数
+	Hits   map[Item]int64 // 各物品被掉落的试验次数
+	Counts map[Item]int64 // 各物品掉落的总数量
+}
+
+// Rate 获取物品的掉落率，即掉落该物品的试验次数占总试验次数的比例
+func (d *DropResult[Item]) Rate(item Item) float64 {
+	if d.Trials == 0 {
+		return 0
+	}
+	return float64(d.Hits[item]) / float64(d.Trials)
+}
+
+// Average 获取物品在每次试验中的平均掉落数量
+func (d *DropResult[Item]) Average(item Item) float64 {
+	if d.Trials == 0 {
+		return 0
+	}
+	return float64(d.Counts[item]) / float64(d.Trials)
+}
+
+// Report 生成掉落报告，各行按照物品的字符串形式排序
+func (d *DropResult[Item]) Report() *Report {
+	items := make([]Item, 0, len(d.Hits))
+	for item := range d.Hits {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return fmt.Sprint(items[i]) < fmt.Sprint(items[j])
+	})
+	report := NewReport("item", "hits", "rate", "count", "average")
+	for _, item := range items {
+		report.AddRow(item, d.Hits[item], d.Rate(item), d.Counts[item], d.Average(item))
+	}
+	return report
+}
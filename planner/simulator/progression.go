@@ -0,0 +1,101 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Progression 模拟 samples 个玩家 days 天的养成进度，统计各项指标每日的平均值、最小值及最大值
+//   - newState 用于创建每个玩家的初始状态
+//   - day 为玩家每日的模拟逻辑，day 从 1 开始，返回当日结束时需要记录的指标，例如等级、战力、累计货币等
+func Progression[State any](r *rand.Rand, samples, days int, newState func() State, day func(r *rand.Rand, day int, state State) map[string]float64) *ProgressionResult {
+	result := &ProgressionResult{Days: make([]map[string]*ProgressionMetric, days)}
+	for i := range result.Days {
+		result.Days[i] = make(map[string]*ProgressionMetric)
+	}
+	for i := 0; i < samples; i++ {
+		state := newState()
+		for d := 0; d < days; d++ {
+			for name, value := range day(r, d+1, state) {
+				metric, exist := result.Days[d][name]
+				if !exist {
+					metric = &ProgressionMetric{Min: math.Inf(1), Max: math.Inf(-1)}
+					result.Days[d][name] = metric
+				}
+				metric.add(value)
+			}
+		}
+	}
+	return result
+}
+
+// ProgressionMetric 某一天中特定指标的统计
+type ProgressionMetric struct {
+	Count int     // 记录次数
+	Sum   float64 // 总和
+	Min   float64 // 最小值
+	Max   float64 // 最大值
+}
+
+// Average 获取指标的平均值
+func (m *ProgressionMetric) Average() float64 {
+	if m.Count == 0 {
+		return 0
+	}
+	return m.Sum / float64(m.Count)
+}
+
+// add 记录一次指标
+func (m *ProgressionMetric) add(value float64) {
+	m.Count++
+	m.Sum += value
+	m.Min = math.Min(m.Min, value)
+	m.Max = math.Max(m.Max, value)
+}
+
+// ProgressionResult 养成进度模拟结果
+type ProgressionResult struct {
+	Days []map[string]*ProgressionMetric // 每日各项指标的统计，索引 0 为第 1 天
+}
+
+// Get 获取特定一天中特定指标的统计，day 从 1 开始，不存在时将返回 nil
+func (p *ProgressionResult) Get(day int, name string) *ProgressionMetric {
+	if day < 1 || day > len(p.Days) {
+		return nil
+	}
+	return p.Days[day-1][name]
+}
+
+// Report 生成进度曲线报告，每行为一天，每项指标包含平均值、最小值及最大值三列，指标按照名称排序
+func (p *ProgressionResult) Report() *Report {
+	var names []string
+	var exist = make(map[string]struct{})
+	for _, metrics := range p.Days {
+		for name := range metrics {
+			if _, ok := exist[name]; !ok {
+				exist[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	header := []string{"day"}
+	for _, name := range names {
+		header = append(header, name, name+"_min", name+"_max")
+	}
+	report := NewReport(header...)
+	for i, metrics := range p.Days {
+		row := []any{i + 1}
+		for _, name := range names {
+			if metric := metrics[name]; metric != nil {
+				row = append(row, metric.Average(), metric.Min, metric.Max)
+			} else {
+				row = append(row, "", "", "")
+			}
+		}
+		report.AddRow(row...)
+	}
+	return report
+}
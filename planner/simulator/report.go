@@ -0,0 +1,66 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// NewReport 创建一个模拟报告
+func NewReport(header ...string) *Report {
+	return &Report{Header: header}
+}
+
+// Report 模拟报告，由表头及多行数据组成
+type Report struct {
+	Header []string   // 表头
+	Rows   [][]string // 数据行
+}
+
+// AddRow 添加一行数据，浮点数将保留 6 位小数，其他类型将通过 fmt.Sprint 转换为字符串
+func (r *Report) AddRow(values ...any) *Report {
+	row := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case float64:
+			row[i] = strconv.FormatFloat(v, 'f', 6, 64)
+		case float32:
+			row[i] = strconv.FormatFloat(float64(v), 'f', 6, 32)
+		default:
+			row[i] = fmt.Sprint(v)
+		}
+	}
+	r.Rows = append(r.Rows, row)
+	return r
+}
+
+// WriteCSV 将报告以 CSV 格式写入 w
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(r.Header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(r.Rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// SaveCSV 将报告以 CSV 格式保存到特定路径，所在目录不存在时将自动创建
+func (r *Report) SaveCSV(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = r.WriteCSV(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,49 @@
+package simulator_test
+
+import (
+	"bytes"
+	"github.com/kercylan98/minotaur/planner/simulator"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestDrop(t *testing.T) {
+	result := simulator.Drop(rand.New(rand.NewSource(1)), 100000, func(r *rand.Rand) map[string]int64 {
+		if r.Intn(100) < 20 {
+			return map[string]int64{"rare": 1}
+		}
+		return map[string]int64{"common": 2}
+	})
+	if rate := result.Rate("rare"); math.Abs(rate-0.2) > 0.01 {
+		t.Fatalf("unexpected rare rate: %f", rate)
+	}
+	if average := result.Average("common"); math.Abs(average-1.6) > 0.02 {
+		t.Fatalf("unexpected common average: %f", average)
+	}
+	var buf bytes.Buffer
+	if err := result.Report().WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 3 || lines[0] != "item,hits,rate,count,average" || !strings.HasPrefix(lines[1], "common,") {
+		t.Fatalf("unexpected report: %s", buf.String())
+	}
+}
+
+func TestProgression(t *testing.T) {
+	type player struct{ exp float64 }
+	result := simulator.Progression(rand.New(rand.NewSource(1)), 10, 7, func() *player {
+		return new(player)
+	}, func(r *rand.Rand, day int, state *player) map[string]float64 {
+		state.exp += 100
+		return map[string]float64{"exp": state.exp, "level": math.Floor(state.exp / 300)}
+	})
+	if metric := result.Get(7, "exp"); metric == nil || metric.Average() != 700 || metric.Count != 10 {
+		t.Fatalf("unexpected metric: %+v", metric)
+	}
+	report := result.Report()
+	if strings.Join(report.Header, ",") != "day,exp,exp_min,exp_max,level,level_min,level_max" || len(report.Rows) != 7 {
+		t.Fatalf("unexpected report: %v", report)
+	}
+}
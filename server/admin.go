@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/kercylan98/minotaur/server/internal/dispatcher"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/super"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ShuntInfo 消息分流渠道信息
+type ShuntInfo struct {
+	Name      string `json:"name"`      // 分流渠道名称
+	Queued    int    `json:"queued"`    // 等待处理的消息数量
+	Producers int    `json:"producers"` // 正在使用该分流渠道的连接数量，系统通道始终为 0
+	Bytes     int64  `json:"bytes"`     // 等待处理的消息估算占用的内存字节数
+	Messages  int64  `json:"messages"`  // 当前 WithMessageStatistics 设置的 duration 期间的消息量，未开启消息统计时始终为 0
+}
+
+// GetShunts 获取当前所有消息分流渠道的信息，其中包含系统通道
+func (srv *Server) GetShunts() []ShuntInfo {
+	names := srv.dispatcherMgr.GetDispatcherNames()
+	infos := make([]ShuntInfo, 0, len(names))
+	for _, name := range names {
+		d := srv.dispatcherMgr.GetNamedDispatcher(name)
+		if d == nil {
+			continue
+		}
+		infos = append(infos, ShuntInfo{
+			Name:      name,
+			Queued:    d.Len(),
+			Producers: srv.dispatcherMgr.GetProducerNum(name),
+			Bytes:     d.Bytes(),
+			Messages:  srv.GetShuntDurationMessageCount(name),
+		})
+	}
+	return infos
+}
+
+type adminConn struct {
+	ID         string        `json:"id"`
+	IP         string        `json:"ip"`
+	Bot        bool          `json:"bot"`
+	Shunt      string        `json:"shunt"`
+	OpenTime   time.Time     `json:"open_time"`
+	OnlineTime time.Duration `json:"online_time"`
+	Stats      ConnStats     `json:"stats"`
+}
+
+// startAdminServer 在通过 WithAdminServer 指定了地址时启动运维管理接口
+func (srv *Server) startAdminServer() error {
+	if len(srv.adminAddr) == 0 {
+		return nil
+	}
+	name := url.QueryEscape("admin://" + srv.adminAddr)
+	l, err := inheritListener(name, false)
+	if err == nil && l == nil {
+		l, err = net.Listen(string(NetworkTcp), srv.adminAddr)
+	}
+	if err != nil {
+		return err
+	}
+	srv.trackListener(name, l)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/online", srv.adminOnline)
+	mux.HandleFunc("/messages", srv.adminMessages)
+	mux.HandleFunc("/shunts", srv.adminShunts)
+	mux.HandleFunc("/memory", srv.adminMemory)
+	mux.HandleFunc("/conns", srv.adminConns)
+	mux.HandleFunc("/conns/kick", srv.adminKick)
+	mux.HandleFunc("/shutdown/rolling", srv.adminRollingShutdown)
+	mux.HandleFunc("/shutdown/handover", srv.adminHandover)
+	srv.adminServer = &http.Server{Handler: mux}
+	go func(srv *Server, server *http.Server, l net.Listener) {
+		if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Server", log.String("Admin", srv.adminAddr), log.Err(err))
+		}
+	}(srv, srv.adminServer, l)
+	log.Info("Server", log.String("Admin", srv.adminAddr))
+	return nil
+}
+
+// stopAdminServer 停止运维管理接口
+func (srv *Server) stopAdminServer() {
+	if srv.adminServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := srv.adminServer.Shutdown(ctx); err != nil {
+		log.Error("Server", log.String("Admin", srv.adminAddr), log.Err(err))
+	}
+}
+
+func (srv *Server) adminOnline(writer http.ResponseWriter, request *http.Request) {
+	adminReply(writer, http.StatusOK, map[string]any{
+		"online": srv.GetOnlineCount(),
+		"user":   srv.GetOnlineUserCount(),
+		"bot":    srv.GetOnlineBotCount(),
+	})
+}
+
+func (srv *Server) adminMessages(writer http.ResponseWriter, request *http.Request) {
+	adminReply(writer, http.StatusOK, map[string]any{
+		"count":      srv.GetMessageCount(),
+		"statistics": srv.GetAllDurationMessageCount(),
+		"shunts":     srv.GetAllShuntDurationMessageCount(),
+		"pool":       srv.GetMessagePoolStats(),
+		"runtime":    srv.RuntimeStats(),
+		"traffic":    srv.GetConnStats(),
+	})
+}
+
+func (srv *Server) adminShunts(writer http.ResponseWriter, request *http.Request) {
+	shunts := srv.GetShunts()
+	sort.Slice(shunts, func(i, j int) bool {
+		if shunts[i].Name == dispatcher.SystemName || shunts[j].Name == dispatcher.SystemName {
+			return shunts[i].Name == dispatcher.SystemName
+		}
+		return shunts[i].Name < shunts[j].Name
+	})
+	adminReply(writer, http.StatusOK, shunts)
+}
+
+func (srv *Server) adminMemory(writer http.ResponseWriter, request *http.Request) {
+	adminReply(writer, http.StatusOK, srv.GetMemoryReport())
+}
+
+func (srv *Server) adminConns(writer http.ResponseWriter, request *http.Request) {
+	var online []*Conn
+	var query = request.URL.Query()
+	var paged = query.Has("limit")
+	if paged {
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		online = srv.GetOnlinePage(offset, limit)
+	} else {
+		online = make([]*Conn, 0, srv.GetOnlineCount())
+		srv.RangeOnline(func(id string, conn *Conn) bool {
+			online = append(online, conn)
+			return true
+		})
+	}
+	conns := make([]adminConn, 0, len(online))
+	for _, conn := range online {
+		conns = append(conns, adminConn{
+			ID:         conn.GetID(),
+			IP:         conn.GetIP(),
+			Bot:        conn.IsBot(),
+			Shunt:      srv.GetConnCurrShunt(conn),
+			OpenTime:   conn.GetOpenTime(),
+			OnlineTime: conn.GetOnlineTime(),
+			Stats:      conn.Stats(),
+		})
+	}
+	if !paged {
+		sort.Slice(conns, func(i, j int) bool {
+			return conns[i].OpenTime.Before(conns[j].OpenTime)
+		})
+	}
+	adminReply(writer, http.StatusOK, conns)
+}
+
+func (srv *Server) adminKick(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		adminReply(writer, http.StatusMethodNotAllowed, map[string]any{"error": http.StatusText(http.StatusMethodNotAllowed)})
+		return
+	}
+	id := request.URL.Query().Get("id")
+	conn := srv.GetOnline(id)
+	if conn == nil {
+		adminReply(writer, http.StatusNotFound, map[string]any{"error": "connection not online", "id": id})
+		return
+	}
+	conn.Close()
+	adminReply(writer, http.StatusOK, map[string]any{"id": id})
+}
+
+func (srv *Server) adminRollingShutdown(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		adminReply(writer, http.StatusMethodNotAllowed, map[string]any{"error": http.StatusText(http.StatusMethodNotAllowed)})
+		return
+	}
+	if srv.IsDraining() {
+		adminReply(writer, http.StatusConflict, map[string]any{"error": ErrRollingShutdownInProgress.Error()})
+		return
+	}
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout := request.URL.Query().Get("timeout"); len(timeout) > 0 {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			adminReply(writer, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		ctx, cancel = context.WithTimeout(ctx, duration)
+	}
+	go func() {
+		defer cancel()
+		_ = srv.RollingShutdown(ctx)
+	}()
+	adminReply(writer, http.StatusAccepted, map[string]any{"online": srv.GetOnlineUserCount()})
+}
+
+func (srv *Server) adminHandover(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		adminReply(writer, http.StatusMethodNotAllowed, map[string]any{"error": http.StatusText(http.StatusMethodNotAllowed)})
+		return
+	}
+	if srv.IsDraining() || srv.handingOver.Load() {
+		adminReply(writer, http.StatusConflict, map[string]any{"error": ErrHandoverInProgress.Error()})
+		return
+	}
+	if err := srv.checkHandover(); err != nil {
+		adminReply(writer, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout := request.URL.Query().Get("timeout"); len(timeout) > 0 {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			adminReply(writer, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		ctx, cancel = context.WithTimeout(ctx, duration)
+	}
+	go func() {
+		defer cancel()
+		_ = srv.HandoverAndRestart(ctx)
+	}()
+	adminReply(writer, http.StatusAccepted, map[string]any{"online": srv.GetOnlineUserCount()})
+}
+
+// adminReply 回复 JSON 格式的数据
+func adminReply(writer http.ResponseWriter, status int, data any) {
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writer.WriteHeader(status)
+	_, _ = writer.Write(super.MarshalJSON(data))
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Promise 通过 Async 或 ShuntAsync 推送的异步消息的执行结果
+type Promise[T any] struct {
+	done     chan struct{}
+	once     sync.Once
+	value    T
+	err      error
+	canceled bool
+}
+
+// Async 推送执行 caller 的异步消息，并以类型化的方式将执行结果交由 callback 处理，消息执行与 PushAsyncMessage 一致
+//   - callback 将通过系统消息执行，允许为 nil，当 Promise 被取消时将不会执行
+//   - 返回的 Promise 可用于在其他协程中等待执行结果，结果在 caller 执行结束后即可获取，不依赖 callback 的执行
+func Async[T any](srv *Server, caller func() (T, error), callback func(value T, err error)) *Promise[T] {
+	p := &Promise[T]{done: make(chan struct{})}
+	srv.PushAsyncMessage(p.wrapCaller(caller), p.wrapCallback(callback))
+	return p
+}
+
+// ShuntAsync 推送执行 caller 的分流异步消息，并以类型化的方式将执行结果交由 callback 处理，消息执行与 PushShuntAsyncMessage 一致
+//   - callback 将在连接所使用的消息分流渠道中执行，允许为 nil，当 Promise 被取消时将不会执行
+func ShuntAsync[T any](srv *Server, conn *Conn, caller func() (T, error), callback func(value T, err error)) *Promise[T] {
+	p := &Promise[T]{done: make(chan struct{})}
+	srv.PushShuntAsyncMessage(conn, p.wrapCaller(caller), p.wrapCallback(callback))
+	return p
+}
+
+// Await 阻塞等待执行结果，当 Promise 被取消时将返回 ErrAsyncCanceled
+//   - 不应在 callback 所在的消息分发器中等待其他 Promise，例如在系统消息中等待 Async 的结果，这将阻塞该分发器中的所有消息
+func (p *Promise[T]) Await() (T, error) {
+	<-p.done
+	return p.value, p.err
+}
+
+// AwaitTimeout 阻塞等待执行结果至多 timeout 时长，超时时将返回 ErrAsyncTimeout，Promise 将继续等待执行结果
+func (p *Promise[T]) AwaitTimeout(timeout time.Duration) (T, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+		return p.value, p.err
+	case <-timer.C:
+		var zero T
+		return zero, ErrAsyncTimeout
+	}
+}
+
+// Done 返回执行结束或被取消时将被关闭的通道
+func (p *Promise[T]) Done() <-chan struct{} {
+	return p.done
+}
+
+// Cancel 取消 Promise，取消后等待结果将立即返回 ErrAsyncCanceled，并且 callback 将不会被执行
+//   - 正在执行的 caller 不会被中断，当 caller 已执行结束时取消将不会生效
+func (p *Promise[T]) Cancel() {
+	var zero T
+	p.resolve(zero, ErrAsyncCanceled, true)
+}
+
+// resolve 设置执行结果，仅首次设置有效
+func (p *Promise[T]) resolve(value T, err error, canceled bool) {
+	p.once.Do(func() {
+		p.value, p.err, p.canceled = value, err, canceled
+		close(p.done)
+	})
+}
+
+// wrapCaller 包装 caller，在执行结束或发生 panic 时设置执行结果
+func (p *Promise[T]) wrapCaller(caller func() (T, error)) func() error {
+	return func() error {
+		defer func() {
+			if err := recover(); err != nil {
+				var zero T
+				p.resolve(zero, fmt.Errorf("%v", err), false)
+				panic(err)
+			}
+		}()
+		value, err := caller()
+		p.resolve(value, err, false)
+		return err
+	}
+}
+
+// wrapCallback 包装 callback，Promise 被取消时将不会执行
+func (p *Promise[T]) wrapCallback(callback func(value T, err error)) func(err error) {
+	if callback == nil {
+		return nil
+	}
+	return func(err error) {
+		<-p.done
+		if p.canceled {
+			return
+		}
+		callback(p.value, p.err)
+	}
+}
@@ -0,0 +1,42 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestAsync(t *testing.T) {
+	var callback int
+	var awaited int
+	var canceled, timeout error
+	srv := server.New(server.NetworkNone)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		p := server.Async(srv, func() (int, error) {
+			return 1, nil
+		}, func(value int, err error) {
+			callback = value
+		})
+		slow := server.Async(srv, func() (string, error) {
+			time.Sleep(time.Millisecond * 200)
+			return "slow", nil
+		}, func(value string, err error) {
+			t.Error("callback of canceled promise should not be executed")
+		})
+		go func() {
+			defer srv.Shutdown()
+			awaited, _ = p.Await()
+			_, timeout = slow.AwaitTimeout(time.Millisecond * 10)
+			slow.Cancel()
+			_, canceled = slow.Await()
+			time.Sleep(time.Millisecond * 300)
+		}()
+	})
+	if err := srv.Run(""); err != nil {
+		t.Fatal(err)
+	}
+	if callback != 1 || awaited != 1 || !errors.Is(timeout, server.ErrAsyncTimeout) || !errors.Is(canceled, server.ErrAsyncCanceled) {
+		t.Fatalf("callback: %d, awaited: %d, timeout: %v, canceled: %v", callback, awaited, timeout, canceled)
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/crypto"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync"
+	"time"
+)
+
+// AuditRecord 审计记录，用于记录 GM 操作、充值购买等敏感操作，以便进行合规审查及事后追溯
+type AuditRecord struct {
+	Time        time.Time      `json:"time"`            // 记录时间
+	Actor       string         `json:"actor"`           // 操作者，例如玩家 ID、GM 账号或连接 ID
+	Action      string         `json:"action"`          // 操作行为，例如 "gm.add_item"、"shop.purchase"
+	PayloadHash string         `json:"payloadHash"`     // 操作负载的 SHA256 摘要
+	Extra       map[string]any `json:"extra,omitempty"` // 额外信息
+}
+
+// AuditSink 审计记录的输出目标，例如文件、数据库或日志服务
+type AuditSink interface {
+	// WriteAuditRecords 批量写入审计记录，该函数将在独立的协程中被调用
+	WriteAuditRecords(records []*AuditRecord) error
+}
+
+// AuditSinkFunc 以函数的形式实现 AuditSink 接口
+type AuditSinkFunc func(records []*AuditRecord) error
+
+// WriteAuditRecords 批量写入审计记录
+func (slf AuditSinkFunc) WriteAuditRecords(records []*AuditRecord) error {
+	return slf(records)
+}
+
+// AuditFilter 审计记录过滤器，返回 false 时审计记录将被忽略
+type AuditFilter func(record *AuditRecord) bool
+
+// auditor 审计记录缓冲区
+type auditor struct {
+	sink      AuditSink
+	batchSize int
+	interval  time.Duration
+	filters   []AuditFilter
+	records   chan *AuditRecord
+	done      chan struct{}
+	closed    bool
+	rw        sync.RWMutex
+}
+
+// put 写入一条审计记录，当审计已停止或审计记录被过滤时将忽略该记录
+func (slf *auditor) put(record *AuditRecord) {
+	for _, filter := range slf.filters {
+		if !filter(record) {
+			return
+		}
+	}
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	if slf.closed {
+		log.Warn("Server", log.String("Audit", "closed"), log.String("actor", record.Actor), log.String("action", record.Action))
+		return
+	}
+	slf.records <- record
+}
+
+// run 持续收集审计记录，并在缓冲区已满或到达刷新间隔时写入 AuditSink，直到 records 被关闭
+func (slf *auditor) run() {
+	defer close(slf.done)
+	ticker := time.NewTicker(slf.interval)
+	defer ticker.Stop()
+	var batch = make([]*AuditRecord, 0, slf.batchSize)
+	for {
+		select {
+		case record, ok := <-slf.records:
+			if !ok {
+				slf.flush(batch)
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= slf.batchSize {
+				slf.flush(batch)
+				batch = make([]*AuditRecord, 0, slf.batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				slf.flush(batch)
+				batch = make([]*AuditRecord, 0, slf.batchSize)
+			}
+		}
+	}
+}
+
+// flush 将审计记录写入 AuditSink
+func (slf *auditor) flush(batch []*AuditRecord) {
+	if len(batch) == 0 {
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error("Server", log.String("Audit", "flush"), log.Int("count", len(batch)), log.Any("error", err))
+		}
+	}()
+	if err := slf.sink.WriteAuditRecords(batch); err != nil {
+		log.Error("Server", log.String("Audit", "flush"), log.Int("count", len(batch)), log.Err(err))
+	}
+}
+
+// close 停止收集审计记录，并等待剩余的审计记录写入完成
+func (slf *auditor) close() {
+	slf.rw.Lock()
+	if slf.closed {
+		slf.rw.Unlock()
+		return
+	}
+	slf.closed = true
+	close(slf.records)
+	slf.rw.Unlock()
+	<-slf.done
+}
+
+// startAudit 开始收集审计记录
+func (srv *Server) startAudit() {
+	if srv.auditor == nil {
+		return
+	}
+	go srv.auditor.run()
+}
+
+// stopAudit 停止收集审计记录并写入剩余的审计记录
+func (srv *Server) stopAudit() {
+	if srv.auditor == nil {
+		return
+	}
+	srv.auditor.close()
+}
+
+// Audit 记录一条审计记录，审计记录将被缓冲并异步批量写入通过 WithAudit 指定的 AuditSink
+//   - actor 为操作者，action 为操作行为，payload 为操作负载，仅会记录其 SHA256 摘要
+//   - 当未通过 WithAudit 开启审计或审计记录被过滤时，该函数不会产生任何效果
+//   - 当缓冲区已满时，该函数将阻塞直到缓冲区可写入，以避免审计记录丢失
+func (srv *Server) Audit(actor, action string, payload []byte, extra ...map[string]any) {
+	if srv.auditor == nil {
+		return
+	}
+	record := &AuditRecord{
+		Time:        time.Now(),
+		Actor:       actor,
+		Action:      action,
+		PayloadHash: crypto.DecodedSHA256(payload),
+	}
+	if len(extra) > 0 {
+		record.Extra = extra[0]
+	}
+	srv.auditor.put(record)
+}
+
+// Audit 以该连接的 ID 作为操作者记录一条审计记录，效果同 Server.Audit
+func (slf *Conn) Audit(action string, payload []byte, extra ...map[string]any) {
+	slf.server.Audit(slf.GetID(), action, payload, extra...)
+}
@@ -0,0 +1,61 @@
+package server_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/crypto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServer_Audit(t *testing.T) {
+	var lock sync.Mutex
+	var batches [][]*server.AuditRecord
+	sink := server.AuditSinkFunc(func(records []*server.AuditRecord) error {
+		lock.Lock()
+		defer lock.Unlock()
+		batches = append(batches, records)
+		return nil
+	})
+	filter := func(record *server.AuditRecord) bool {
+		return strings.HasPrefix(record.Action, "gm.")
+	}
+
+	// 刷新间隔足够长，审计记录仅会在缓冲区已满及服务器关闭时写入
+	srv := server.New(server.NetworkNone, server.WithAudit(sink, 2, time.Hour, filter))
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		srv.Audit("admin", "gm.add_item", []byte("item:1001"), map[string]any{"count": 10})
+		srv.Audit("player", "chat.say", []byte("hello"))
+		srv.Audit("admin", "gm.ban", []byte("player"))
+		srv.Audit("admin", "gm.kick", []byte("player"))
+		go srv.Shutdown()
+	})
+	if err := srv.Run(""); err != nil {
+		t.Fatal(err)
+	}
+	srv.Audit("admin", "gm.after_shutdown", nil)
+
+	lock.Lock()
+	defer lock.Unlock()
+	var actions []string
+	for _, batch := range batches {
+		var names []string
+		for _, record := range batch {
+			names = append(names, record.Action)
+		}
+		actions = append(actions, strings.Join(names, ","))
+	}
+	if expected := "[gm.add_item,gm.ban gm.kick]"; fmt.Sprint(actions) != expected {
+		t.Fatalf("unexpected batches: %v, expected: %s", actions, expected)
+	}
+
+	record := batches[0][0]
+	if record.Actor != "admin" || record.PayloadHash != crypto.DecodedSHA256([]byte("item:1001")) {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if record.Extra["count"] != 10 || record.Time.IsZero() {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+}
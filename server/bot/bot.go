@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"github.com/kercylan98/minotaur/server/client"
+	"time"
+)
+
+var (
+	ErrReceiveTimeout = errors.New("bot: receive timeout")
+	ErrBotStopped     = errors.New("bot: stopped")
+)
+
+// Behavior 机器人的行为脚本，将在机器人连接成功后执行，函数返回时机器人将断开连接
+//   - 脚本中应当通过 Bot.Context 或 Bot.Sleep 感知压测的结束，以便及时返回
+type Behavior func(bot *Bot)
+
+// Bot 压测机器人，每个机器人持有一个独立的客户端连接
+type Bot struct {
+	id     int
+	swarm  *Swarm
+	client *client.Client
+	inbox  chan []byte
+	ctx    context.Context
+}
+
+// GetID 获取机器人的编号，编号从 0 开始
+func (b *Bot) GetID() int {
+	return b.id
+}
+
+// GetClient 获取机器人所使用的客户端
+func (b *Bot) GetClient() *client.Client {
+	return b.client
+}
+
+// Context 获取机器人的上下文，当压测结束或连接断开时将被取消
+func (b *Bot) Context() context.Context {
+	return b.ctx
+}
+
+// Write 向服务器写入数据包
+func (b *Bot) Write(packet []byte) {
+	b.client.Write(packet)
+}
+
+// WriteWS 向服务器写入特定 websocket 消息类型的数据包
+func (b *Bot) WriteWS(wst int, packet []byte) {
+	b.client.WriteWS(wst, packet)
+}
+
+// Receive 等待接收一个来自服务器的数据包，当 timeout <= 0 时将一直等待直到机器人停止
+func (b *Bot) Receive(timeout time.Duration) ([]byte, error) {
+	var after <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		after = timer.C
+	}
+	select {
+	case packet := <-b.inbox:
+		return packet, nil
+	case <-after:
+		return nil, ErrReceiveTimeout
+	case <-b.ctx.Done():
+		return nil, ErrBotStopped
+	}
+}
+
+// Request 向服务器写入数据包并等待接收下一个数据包，从写入到接收的耗时将以 name 记录到压测报告中
+//   - 适用于一问一答的协议，当服务器存在主动推送时，应自行通过 Measure 进行统计
+func (b *Bot) Request(name string, packet []byte, timeout time.Duration) ([]byte, error) {
+	var reply []byte
+	err := b.Measure(name, func() (err error) {
+		b.Write(packet)
+		reply, err = b.Receive(timeout)
+		return err
+	})
+	return reply, err
+}
+
+// Measure 执行 f 并将其耗时以 name 记录到压测报告中，当 f 返回错误时将被记录为失败
+func (b *Bot) Measure(name string, f func() error) error {
+	start := time.Now()
+	err := f()
+	b.swarm.stats.record(name, time.Since(start), err)
+	return err
+}
+
+// Sleep 休眠特定时长，当机器人在休眠期间停止时将返回 false
+func (b *Bot) Sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-b.ctx.Done():
+		return false
+	}
+}
+
+// onReceive 接收数据包，当收件箱已满时将丢弃数据包
+//   - 部分客户端会复用读取缓冲区，因此需要复制数据包
+func (b *Bot) onReceive(packet []byte) {
+	select {
+	case b.inbox <- append([]byte(nil), packet...):
+	default:
+		b.swarm.stats.record(dropStatsName, 0, ErrInboxFull)
+	}
+}
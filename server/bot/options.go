@@ -0,0 +1,40 @@
+package bot
+
+import "time"
+
+// Option 机器人集群可选项
+type Option func(swarm *Swarm)
+
+// WithCount 通过指定机器人数量的方式创建机器人集群，默认为 1
+func WithCount(count int) Option {
+	return func(swarm *Swarm) {
+		if count > 0 {
+			swarm.count = count
+		}
+	}
+}
+
+// WithRampUp 通过指定爬坡时长的方式创建机器人集群，所有机器人将在 d 时间内均匀地依次启动，以避免瞬时的连接风暴
+//   - 默认为 0，即同时启动所有机器人
+func WithRampUp(d time.Duration) Option {
+	return func(swarm *Swarm) {
+		swarm.rampUp = d
+	}
+}
+
+// WithDuration 通过指定压测时长的方式创建机器人集群，达到压测时长后将停止所有机器人
+//   - 默认为 0，即直到所有机器人的行为脚本返回或调用 Swarm.Stop
+func WithDuration(d time.Duration) Option {
+	return func(swarm *Swarm) {
+		swarm.duration = d
+	}
+}
+
+// WithInboxSize 通过指定机器人收件箱大小的方式创建机器人集群，收件箱已满时新接收的数据包将被丢弃，默认为 DefaultInboxSize
+func WithInboxSize(size int) Option {
+	return func(swarm *Swarm) {
+		if size > 0 {
+			swarm.inboxSize = size
+		}
+	}
+}
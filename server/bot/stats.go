@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInboxFull 机器人收件箱已满，接收到的数据包被丢弃
+var ErrInboxFull = errors.New("bot: inbox full")
+
+const (
+	dialStatsName = "dial" // 连接建立耗时的统计名称
+	dropStatsName = "drop" // 丢弃数据包的统计名称
+)
+
+// Report 压测报告
+type Report struct {
+	Bots      int             // 机器人数量
+	Connected int             // 连接成功的机器人数量
+	Failed    int             // 连接失败的机器人数量
+	Elapsed   time.Duration   // 压测耗时
+	Latencies []LatencyReport // 按名称排序的耗时统计，其中 "dial" 为连接建立耗时，"drop" 为因收件箱已满而丢弃的数据包
+	latencies map[string]int  // 名称到 Latencies 下标的映射
+}
+
+// LatencyReport 特定名称的耗时统计，仅对成功的样本进行统计
+type LatencyReport struct {
+	Name   string        // 统计名称
+	Count  int           // 成功的样本数量
+	Errors int           // 失败的样本数量
+	Min    time.Duration // 最小耗时
+	Max    time.Duration // 最大耗时
+	Avg    time.Duration // 平均耗时
+	P50    time.Duration // 50 分位耗时
+	P90    time.Duration // 90 分位耗时
+	P99    time.Duration // 99 分位耗时
+}
+
+// GetLatency 获取特定名称的耗时统计
+func (r *Report) GetLatency(name string) (LatencyReport, bool) {
+	index, exist := r.latencies[name]
+	if !exist {
+		return LatencyReport{}, false
+	}
+	return r.Latencies[index], true
+}
+
+// String 返回压测报告的文本表示
+func (r *Report) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("bots: %d, connected: %d, failed: %d, elapsed: %s\n", r.Bots, r.Connected, r.Failed, r.Elapsed))
+	for _, l := range r.Latencies {
+		builder.WriteString(fmt.Sprintf("%s: count=%d errors=%d min=%s avg=%s max=%s p50=%s p90=%s p99=%s\n",
+			l.Name, l.Count, l.Errors, l.Min, l.Avg, l.Max, l.P50, l.P90, l.P99))
+	}
+	return builder.String()
+}
+
+// stats 压测过程中的统计数据
+type stats struct {
+	lock      sync.Mutex
+	connected int
+	failed    int
+	samples   map[string][]time.Duration
+	errors    map[string]int
+}
+
+func newStats() *stats {
+	return &stats{
+		samples: make(map[string][]time.Duration),
+		errors:  make(map[string]int),
+	}
+}
+
+// record 记录一个样本，err 不为空时将被记录为失败
+func (s *stats) record(name string, d time.Duration, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err != nil {
+		s.errors[name]++
+		return
+	}
+	s.samples[name] = append(s.samples[name], d)
+}
+
+// dial 记录一次连接建立的结果
+func (s *stats) dial(d time.Duration, err error) {
+	s.lock.Lock()
+	if err != nil {
+		s.failed++
+	} else {
+		s.connected++
+	}
+	s.lock.Unlock()
+	s.record(dialStatsName, d, err)
+}
+
+// report 生成压测报告
+func (s *stats) report(bots int, elapsed time.Duration) *Report {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	r := &Report{
+		Bots:      bots,
+		Connected: s.connected,
+		Failed:    s.failed,
+		Elapsed:   elapsed,
+		latencies: make(map[string]int),
+	}
+	var names = make(map[string]struct{})
+	for name := range s.samples {
+		names[name] = struct{}{}
+	}
+	for name := range s.errors {
+		names[name] = struct{}{}
+	}
+	for name := range names {
+		r.Latencies = append(r.Latencies, summarize(name, s.samples[name], s.errors[name]))
+	}
+	sort.Slice(r.Latencies, func(i, j int) bool {
+		return r.Latencies[i].Name < r.Latencies[j].Name
+	})
+	for i, l := range r.Latencies {
+		r.latencies[l.Name] = i
+	}
+	return r
+}
+
+// summarize 汇总特定名称的样本
+func summarize(name string, samples []time.Duration, errors int) LatencyReport {
+	l := LatencyReport{Name: name, Count: len(samples), Errors: errors}
+	if len(samples) == 0 {
+		return l
+	}
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	l.Min, l.Max, l.Avg = sorted[0], sorted[len(sorted)-1], total/time.Duration(len(sorted))
+	l.P50, l.P90, l.P99 = percentile(0.5), percentile(0.9), percentile(0.99)
+	return l
+}
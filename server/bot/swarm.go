@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/server/client"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/super"
+	"sync"
+	"time"
+)
+
+// DefaultInboxSize 默认的机器人收件箱大小
+const DefaultInboxSize = 1024
+
+// Dialer 为特定编号的机器人创建客户端
+type Dialer func(id int) *client.Client
+
+// TCP 创建连接到 TCP 服务器的 Dialer
+func TCP(addr string) Dialer {
+	return func(id int) *client.Client {
+		return client.NewTCP(addr)
+	}
+}
+
+// Websocket 创建连接到 Websocket 服务器的 Dialer，addr 应包含协议及路径，例如 "ws://127.0.0.1:8888/ws"
+func Websocket(addr string) Dialer {
+	return func(id int) *client.Client {
+		return client.NewWebsocket(addr)
+	}
+}
+
+// NewSwarm 创建一个机器人集群，集群中的每个机器人都将通过 dialer 连接到服务器并执行 behavior
+//   - 与 server.NewBot 创建的进程内机器人不同，集群中的机器人将通过真实的网络连接到服务器，适用于压力测试
+func NewSwarm(dialer Dialer, behavior Behavior, options ...Option) *Swarm {
+	swarm := &Swarm{
+		dialer:    dialer,
+		behavior:  behavior,
+		count:     1,
+		inboxSize: DefaultInboxSize,
+		stats:     newStats(),
+	}
+	for _, option := range options {
+		option(swarm)
+	}
+	swarm.ctx, swarm.cancel = context.WithCancel(context.Background())
+	return swarm
+}
+
+// Swarm 压测机器人集群
+type Swarm struct {
+	dialer    Dialer
+	behavior  Behavior
+	count     int
+	rampUp    time.Duration
+	duration  time.Duration
+	inboxSize int
+	stats     *stats
+	ctx       context.Context
+	cancel    context.CancelFunc
+	start     time.Time
+	runOnce   sync.Once
+}
+
+// Run 启动所有机器人并阻塞直到所有机器人的行为脚本返回、达到压测时长或调用 Stop，随后返回压测报告
+//   - 每个集群仅能运行一次，重复调用将直接返回当前的压测报告
+func (s *Swarm) Run() *Report {
+	s.runOnce.Do(func() {
+		s.start = time.Now()
+		if s.duration > 0 {
+			timer := time.AfterFunc(s.duration, s.Stop)
+			defer timer.Stop()
+		}
+
+		var wait sync.WaitGroup
+		var interval time.Duration
+		if s.count > 1 {
+			interval = s.rampUp / time.Duration(s.count-1)
+		}
+		for i := 0; i < s.count; i++ {
+			if i > 0 && interval > 0 {
+				select {
+				case <-time.After(interval):
+				case <-s.ctx.Done():
+				}
+			}
+			if s.ctx.Err() != nil {
+				break
+			}
+			wait.Add(1)
+			go func(id int) {
+				defer wait.Done()
+				s.run(id)
+			}(i)
+		}
+		wait.Wait()
+		s.Stop()
+	})
+	return s.GetReport()
+}
+
+// Stop 停止所有机器人，机器人的上下文将被取消
+func (s *Swarm) Stop() {
+	s.cancel()
+}
+
+// GetReport 获取当前的压测报告，可在压测过程中调用以观察实时数据
+func (s *Swarm) GetReport() *Report {
+	var elapsed time.Duration
+	if !s.start.IsZero() {
+		elapsed = time.Since(s.start)
+	}
+	return s.stats.report(s.count, elapsed)
+}
+
+// run 连接并运行特定编号的机器人
+func (s *Swarm) run(id int) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	b := &Bot{
+		id:     id,
+		swarm:  s,
+		client: s.dialer(id),
+		inbox:  make(chan []byte, s.inboxSize),
+		ctx:    ctx,
+	}
+	b.client.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
+		b.onReceive(packet)
+	})
+	b.client.RegConnectionClosedEvent(func(conn *client.Client, err any) {
+		cancel()
+	})
+
+	start := time.Now()
+	err := b.client.Run()
+	s.stats.dial(time.Since(start), err)
+	if err != nil {
+		log.Error("Bot", log.Int("id", id), log.Err(err))
+		return
+	}
+	defer b.client.Close()
+	defer func() {
+		if err := super.RecoverTransform(recover()); err != nil {
+			log.Error("Bot", log.Int("id", id), log.Err(err))
+		}
+	}()
+	s.behavior(b)
+}
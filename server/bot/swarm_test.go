@@ -0,0 +1,44 @@
+package bot_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/bot"
+	"github.com/kercylan98/minotaur/utils/random"
+	"testing"
+	"time"
+)
+
+func TestSwarm_Run(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkWebsocket)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	var report *bot.Report
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			report = bot.NewSwarm(bot.Websocket(fmt.Sprintf("ws://%s", addr)), func(b *bot.Bot) {
+				for i := 0; i < 3; i++ {
+					reply, err := b.Request("echo", []byte("ping"), time.Second)
+					if err != nil || string(reply) != "ping" {
+						t.Errorf("bot %d request failed, reply: %s, err: %v", b.GetID(), reply, err)
+						return
+					}
+				}
+			}, bot.WithCount(5), bot.WithRampUp(100*time.Millisecond)).Run()
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Connected != 5 || report.Failed != 0 {
+		t.Fatalf("connected: %d, failed: %d", report.Connected, report.Failed)
+	}
+	if echo, ok := report.GetLatency("echo"); !ok || echo.Count != 15 || echo.Errors != 0 {
+		t.Fatalf("echo latency: %+v", echo)
+	}
+	t.Log(report)
+}
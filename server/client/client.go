@@ -1,26 +1,44 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/server/writeloop"
 	"github.com/kercylan98/minotaur/utils/hub"
+	"github.com/kercylan98/minotaur/utils/super"
 	"sync"
+	"time"
 )
 
+var (
+	ErrSendQueueFull   = errors.New("client: send queue full")
+	ErrReconnectFailed = errors.New("client: reconnect failed")
+)
+
+// DefaultSendQueueSize 默认的重连期间发送队列大小
+const DefaultSendQueueSize = 1024
+
 // NewClient 创建客户端
 func NewClient(core Core) *Client {
 	client := &Client{
-		events: new(events),
-		core:   core,
-		closed: true,
+		events:        new(events),
+		core:          core,
+		closed:        true,
+		sendQueueSize: DefaultSendQueueSize,
+		rpcCaller:     server.NewRPCCaller(),
+		rpcRouter:     server.NewRPCRouter[*Client](),
 	}
 	return client
 }
 
-// CloneClient 克隆客户端
+// CloneClient 克隆客户端，自动重连策略、发送队列大小及协议版本将被一并克隆
 func CloneClient(client *Client) *Client {
 	cli := NewClient(client.core.Clone())
+	cli.reconnect = client.reconnect
+	cli.sendQueueSize = client.sendQueueSize
+	cli.protocol = client.protocol
 	return cli
 }
 
@@ -30,10 +48,62 @@ type Client struct {
 	core           Core
 	mutex          sync.Mutex
 	closed         bool                        // 是否已关闭
+	manual         bool                        // 是否为主动关闭，主动关闭的客户端不会自动重连
 	pool           *hub.ObjectPool[*Packet]    // 数据包缓冲池
 	loop           *writeloop.Channel[*Packet] // 写入循环
 	loopBufferSize int                         // 写入循环缓冲区大小
 	block          chan struct{}               // 以阻塞方式运行
+	reconnect      *reconnect                  // 自动重连策略
+	reconnecting   bool                        // 是否正在重连
+	pending        []*Packet                   // 重连期间等待发送的数据包
+	sendQueueSize  int                         // 重连期间发送队列大小
+	rpcCaller      *server.RPCCaller           // RPC 调用器
+	rpcRouter      *server.RPCRouter[*Client]  // RPC 路由器
+	protocol       uint32                      // 协议版本，为 0 时表示不进行握手
+}
+
+// reconnect 自动重连策略
+type reconnect struct {
+	initial     time.Duration // 首次重连的等待时间
+	max         time.Duration // 重连等待时间的上限
+	maxAttempts int           // 最大重连次数，<= 0 时表示不限制
+}
+
+// SetReconnect 设置自动重连策略，当连接非主动断开时将自动重连
+//   - 每次重连失败后等待时间将翻倍，直到达到 max，当 max <= 0 时将不设上限
+//   - 当 maxAttempts <= 0 时将无限重连，否则在连续失败 maxAttempts 次后放弃重连
+//   - 当 initial <= 0 时将关闭自动重连
+//   - 以阻塞方式运行时，Run 将在连接断开且不再重连后返回
+func (slf *Client) SetReconnect(initial, max time.Duration, maxAttempts int) *Client {
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	if initial <= 0 {
+		slf.reconnect = nil
+		return slf
+	}
+	slf.reconnect = &reconnect{initial: initial, max: max, maxAttempts: maxAttempts}
+	return slf
+}
+
+// SetProtocolVersion 设置客户端的协议版本，设置后每次建立连接时都将首先向服务器发送协议版本握手数据包
+//   - 适用于通过 server.WithProtocolVersion 开启了协议版本协商的服务器
+//   - 握手回复不会触发 ConnectionReceivePacketEvent 事件，版本不兼容时将触发 ProtocolRejectedEvent 事件
+//   - 当 version 为 0 时表示不进行握手
+func (slf *Client) SetProtocolVersion(version uint32) *Client {
+	slf.mutex.Lock()
+	slf.protocol = version
+	slf.mutex.Unlock()
+	return slf
+}
+
+// SetSendQueueSize 设置重连期间的发送队列大小，重连期间写入的数据包将在重连成功后按顺序发送，默认为 DefaultSendQueueSize
+//   - 超出队列大小的数据包将被丢弃，并以 ErrSendQueueFull 执行回调函数
+//   - 当 size <= 0 时重连期间写入的数据包将被直接丢弃
+func (slf *Client) SetSendQueueSize(size int) *Client {
+	slf.mutex.Lock()
+	slf.sendQueueSize = size
+	slf.mutex.Unlock()
+	return slf
 }
 
 // Run 运行客户端，当客户端已运行时，会先关闭客户端再重新运行
@@ -48,21 +118,38 @@ func (slf *Client) RunByBufferSize(size int, block ...bool) error {
 	if size <= 0 {
 		return errors.New("buffer size must be greater than 0")
 	}
-	slf.loopBufferSize = size
 	slf.mutex.Lock()
 	if !slf.closed {
 		slf.mutex.Unlock()
 		slf.Close()
 		slf.mutex.Lock()
 	}
+	slf.loopBufferSize = size
+	slf.manual = false
+	slf.block = nil
 	if len(block) > 0 && block[0] {
 		slf.block = make(chan struct{})
 	}
+	blocker := slf.block
+	slf.mutex.Unlock()
+
+	if err := slf.run(); err != nil {
+		return err
+	}
+	if blocker != nil {
+		<-blocker
+	}
+	return nil
+}
+
+// run 建立连接并启动写入循环
+func (slf *Client) run() error {
+	slf.mutex.Lock()
 	var runState = make(chan error)
 	go func(runState chan<- error) {
 		defer func() {
-			if err := recover(); err != nil {
-				slf.Close(err.(error))
+			if err := super.RecoverTransform(recover()); err != nil {
+				slf.close(err)
 			}
 		}()
 		slf.core.Run(runState, slf.onReceive)
@@ -81,20 +168,24 @@ func (slf *Client) RunByBufferSize(size int, block ...bool) error {
 		data.callback = nil
 	})
 	slf.loop = writeloop.NewChannel[*Packet](slf.pool, slf.loopBufferSize, func(message *Packet) error {
-		err := slf.core.Write(message)
-		if message.callback != nil {
-			message.callback(err)
-		}
-		return err
+		return slf.core.Write(message)
 	}, func(err any) {
-		slf.Close(errors.New(fmt.Sprint(err)))
+		slf.close(errors.New(fmt.Sprint(err)))
 	})
+	if slf.protocol > 0 {
+		cp := slf.pool.Get()
+		cp.data = server.MarshalProtocolHandshake(slf.protocol)
+		slf.loop.Put(cp)
+	}
+	for _, packet := range slf.pending {
+		cp := slf.pool.Get()
+		cp.wst, cp.data, cp.callback = packet.wst, packet.data, packet.callback
+		slf.loop.Put(cp)
+	}
+	slf.pending = nil
 	slf.mutex.Unlock()
 
 	slf.OnConnectionOpenedEvent(slf)
-	if slf.block != nil {
-		<-slf.block
-	}
 	return nil
 }
 
@@ -105,20 +196,85 @@ func (slf *Client) IsConnected() bool {
 	return !slf.closed
 }
 
-// Close 关闭
+// Close 关闭，重复关闭将被忽略，主动关闭的客户端不会自动重连
 func (slf *Client) Close(err ...error) {
 	slf.mutex.Lock()
+	slf.manual = true
+	slf.mutex.Unlock()
+	slf.close(err...)
+}
+
+// close 关闭连接，当连接非主动断开且设置了自动重连策略时将开始自动重连
+func (slf *Client) close(err ...error) {
+	slf.mutex.Lock()
+	if slf.closed {
+		slf.mutex.Unlock()
+		return
+	}
 	slf.closed = true
 	slf.core.Close()
 	slf.loop.Close()
+	var reconnecting = slf.reconnect != nil && !slf.manual && !slf.reconnecting
+	if reconnecting {
+		slf.reconnecting = true
+	}
+	var blocker = slf.block
 	slf.mutex.Unlock()
 	if len(err) > 0 {
 		slf.OnConnectionClosedEvent(slf, err[0])
 	} else {
 		slf.OnConnectionClosedEvent(slf, nil)
 	}
-	if slf.block != nil {
-		slf.block <- struct{}{}
+	if reconnecting {
+		go slf.doReconnect()
+		return
+	}
+	if blocker != nil {
+		blocker <- struct{}{}
+	}
+}
+
+// doReconnect 按照自动重连策略进行重连
+func (slf *Client) doReconnect() {
+	slf.mutex.Lock()
+	r := slf.reconnect
+	slf.mutex.Unlock()
+
+	var err = ErrReconnectFailed
+	var delay = r.initial
+	for attempt := 1; r.maxAttempts <= 0 || attempt <= r.maxAttempts; attempt++ {
+		time.Sleep(delay)
+		slf.mutex.Lock()
+		manual := slf.manual
+		slf.mutex.Unlock()
+		if manual {
+			break
+		}
+
+		slf.OnConnectionReconnectEvent(slf, attempt)
+		if err = slf.run(); err == nil {
+			slf.mutex.Lock()
+			slf.reconnecting = false
+			slf.mutex.Unlock()
+			return
+		}
+		if delay *= 2; r.max > 0 && delay > r.max {
+			delay = r.max
+		}
+	}
+
+	slf.mutex.Lock()
+	slf.reconnecting = false
+	pending, blocker := slf.pending, slf.block
+	slf.pending = nil
+	slf.mutex.Unlock()
+	for _, packet := range pending {
+		if packet.callback != nil {
+			packet.callback(fmt.Errorf("%w: %v", ErrReconnectFailed, err))
+		}
+	}
+	if blocker != nil {
+		blocker <- struct{}{}
 	}
 }
 
@@ -133,28 +289,94 @@ func (slf *Client) Write(packet []byte, callback ...func(err error)) {
 	slf.write(0, packet, callback...)
 }
 
-// write 向连接中写入数据
+// write 向连接中写入数据，重连期间的数据包将进入发送队列
 //   - messageType: websocket模式中指定消息类型
 func (slf *Client) write(wst int, packet []byte, callback ...func(err error)) {
 	slf.mutex.Lock()
 	defer slf.mutex.Unlock()
+	var cb func(err error)
+	if len(callback) > 0 {
+		cb = callback[0]
+	}
 	if slf.closed {
+		if !slf.reconnecting {
+			return
+		}
+		if len(slf.pending) >= slf.sendQueueSize {
+			if cb != nil {
+				cb(ErrSendQueueFull)
+			}
+			return
+		}
+		slf.pending = append(slf.pending, &Packet{wst: wst, data: packet, callback: cb})
 		return
 	}
 
 	cp := slf.pool.Get()
 	cp.wst = wst
 	cp.data = packet
-	if len(callback) > 0 {
-		cp.callback = callback[0]
-	}
+	cp.callback = cb
 	slf.loop.Put(cp)
 }
 
 func (slf *Client) onReceive(wst int, packet []byte) {
+	slf.mutex.Lock()
+	protocol := slf.protocol
+	slf.mutex.Unlock()
+	if protocol > 0 {
+		if reply, ok := server.UnmarshalProtocolReply(packet); ok {
+			if !reply.Accepted {
+				slf.mutex.Lock()
+				slf.manual = true
+				slf.mutex.Unlock()
+				slf.OnProtocolRejectedEvent(slf, reply)
+			}
+			return
+		}
+	}
+	if server.IsRPCPacket(packet) {
+		if p, err := server.UnmarshalRPCPacket(packet); err == nil {
+			if p.Response {
+				slf.rpcCaller.Resolve(p)
+			} else {
+				slf.WriteWS(wst, slf.rpcRouter.Serve(slf, p))
+			}
+			return
+		}
+	}
 	slf.OnConnectionReceivePacketEvent(slf, wst, packet)
 }
 
+// RegRPCHandler 注册特定路由的 RPC 处理函数，用于处理服务器通过 server.Server.Call 发起的调用
+//   - 处理函数将在接收数据包的协程中执行，RPC 请求及响应不会触发 ConnectionReceivePacketEvent 事件
+func (slf *Client) RegRPCHandler(route string, handler server.RPCHandler[*Client]) {
+	slf.rpcRouter.Handle(route, handler)
+}
+
+// Call 向服务器发起 RPC 调用并等待响应，当 timeout <= 0 时将使用 server.DefaultRPCTimeout
+//   - 重连期间的调用将进入发送队列，当发送队列已满或客户端已关闭时将立即返回错误
+func (slf *Client) Call(route string, payload []byte, timeout time.Duration) ([]byte, error) {
+	if timeout <= 0 {
+		timeout = server.DefaultRPCTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return slf.rpcCaller.Call(ctx, func(packet []byte) error {
+		slf.mutex.Lock()
+		unavailable := slf.closed && !slf.reconnecting
+		slf.mutex.Unlock()
+		if unavailable {
+			return server.ErrRPCWriteFailed
+		}
+		slf.Write(packet, func(err error) {
+			if err != nil {
+				cancel()
+			}
+		})
+		return nil
+	}, route, payload)
+}
+
 // GetServerAddr 获取服务器地址
 func (slf *Client) GetServerAddr() string {
 	return slf.core.GetServerAddr()
@@ -1,15 +1,21 @@
 package client
 
+import "github.com/kercylan98/minotaur/server"
+
 type (
 	ConnectionClosedEventHandle        func(conn *Client, err any)
 	ConnectionOpenedEventHandle        func(conn *Client)
 	ConnectionReceivePacketEventHandle func(conn *Client, wst int, packet []byte)
+	ConnectionReconnectEventHandle     func(conn *Client, attempt int)
+	ProtocolRejectedEventHandle        func(conn *Client, reply server.ProtocolReply)
 )
 
 type events struct {
 	ConnectionClosedEventHandles        []ConnectionClosedEventHandle
 	ConnectionOpenedEventHandles        []ConnectionOpenedEventHandle
 	ConnectionReceivePacketEventHandles []ConnectionReceivePacketEventHandle
+	ConnectionReconnectEventHandles     []ConnectionReconnectEventHandle
+	ProtocolRejectedEventHandles        []ProtocolRejectedEventHandle
 }
 
 // RegConnectionClosedEvent 注册连接关闭事件
@@ -44,3 +50,26 @@ func (slf *events) OnConnectionReceivePacketEvent(conn *Client, wst int, packet
 		handle(conn, wst, packet)
 	}
 }
+
+// RegConnectionReconnectEvent 注册连接重连事件，将在每次尝试重连前执行，attempt 为本次重连的次数
+func (slf *events) RegConnectionReconnectEvent(handle ConnectionReconnectEventHandle) {
+	slf.ConnectionReconnectEventHandles = append(slf.ConnectionReconnectEventHandles, handle)
+}
+
+func (slf *events) OnConnectionReconnectEvent(conn *Client, attempt int) {
+	for _, handle := range slf.ConnectionReconnectEventHandles {
+		handle(conn, attempt)
+	}
+}
+
+// RegProtocolRejectedEvent 注册协议版本被拒绝事件，将在服务器回复协议版本不兼容时执行，reply 中包含服务器支持的版本范围
+//   - 协议版本被拒绝后服务器将关闭连接，客户端将不再自动重连
+func (slf *events) RegProtocolRejectedEvent(handle ProtocolRejectedEventHandle) {
+	slf.ProtocolRejectedEventHandles = append(slf.ProtocolRejectedEventHandles, handle)
+}
+
+func (slf *events) OnProtocolRejectedEvent(conn *Client, reply server.ProtocolReply) {
+	for _, handle := range slf.ProtocolRejectedEventHandles {
+		handle(conn, reply)
+	}
+}
@@ -1,10 +1,14 @@
 package client_test
 
 import (
+	"fmt"
 	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/server/client"
+	"github.com/kercylan98/minotaur/utils/random"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestClient_WriteWS(t *testing.T) {
@@ -32,3 +36,157 @@ func TestClient_WriteWS(t *testing.T) {
 
 	wait.Wait()
 }
+
+func TestClient_SetReconnect(t *testing.T) {
+	var wait sync.WaitGroup
+	wait.Add(1)
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var opened, reconnected atomic.Int64
+	srv := server.New(server.NetworkWebsocket)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		if opened.Add(1) == 1 {
+			conn.Close()
+		}
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		srv.Shutdown()
+	})
+	srv.RegStopEvent(func(srv *server.Server) {
+		wait.Done()
+	})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		cli := client.NewWebsocket(fmt.Sprintf("ws://%s", addr)).SetReconnect(50*time.Millisecond, time.Second, 0)
+		cli.RegConnectionReconnectEvent(func(conn *client.Client, attempt int) {
+			reconnected.Add(1)
+		})
+		cli.RegConnectionClosedEvent(func(conn *client.Client, err any) {
+			conn.WriteWS(2, []byte("Hello"))
+		})
+		if err := cli.Run(); err != nil {
+			t.Error(err)
+			srv.Shutdown()
+		}
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	wait.Wait()
+	if reconnected.Load() == 0 {
+		t.Fatal("client not reconnected")
+	}
+}
+
+func TestClient_Call(t *testing.T) {
+	type AddRequest struct {
+		A, B int
+	}
+	var wait sync.WaitGroup
+	wait.Add(1)
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var result int
+	var echo string
+	var callErr error
+	srv := server.New(server.NetworkWebsocket, server.WithRPC())
+	server.RegRPC(srv, "add", func(conn *server.Conn, req AddRequest) (int, error) {
+		resp, err := srv.Call(conn, "echo", []byte("ping"), time.Second)
+		if err != nil {
+			return 0, err
+		}
+		echo = string(resp)
+		return req.A + req.B, nil
+	})
+	srv.RegStopEvent(func(srv *server.Server) {
+		wait.Done()
+	})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		cli := client.NewWebsocket(fmt.Sprintf("ws://%s", addr))
+		cli.RegRPCHandler("echo", func(caller *client.Client, payload []byte) ([]byte, error) {
+			return payload, nil
+		})
+		cli.RegConnectionOpenedEvent(func(conn *client.Client) {
+			go func() {
+				defer srv.Shutdown()
+				resp, err := conn.Call("add", []byte(`{"A":1,"B":2}`), time.Second)
+				if err != nil {
+					callErr = err
+					return
+				}
+				_, _ = fmt.Sscan(string(resp), &result)
+				if _, err = conn.Call("unknown", nil, time.Second); err == nil {
+					callErr = fmt.Errorf("call unknown route should return error")
+				}
+			}()
+		})
+		if err := cli.Run(); err != nil {
+			t.Error(err)
+			srv.Shutdown()
+		}
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	wait.Wait()
+	if callErr != nil {
+		t.Fatal(callErr)
+	}
+	if result != 3 || echo != "ping" {
+		t.Fatalf("unexpected result: %d, echo: %s", result, echo)
+	}
+}
+
+func TestClient_SetProtocolVersion(t *testing.T) {
+	var wait sync.WaitGroup
+	wait.Add(1)
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var reply server.ProtocolReply
+	var closedErr any
+	var accepted uint32
+	srv := server.New(server.NetworkWebsocket, server.WithProtocolVersion(2, 3))
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		if closedErr == nil {
+			closedErr = err
+		}
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		accepted = conn.GetProtocolVersion()
+		srv.Shutdown()
+	})
+	srv.RegStopEvent(func(srv *server.Server) {
+		wait.Done()
+	})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		cli := client.NewWebsocket(fmt.Sprintf("ws://%s", addr)).SetProtocolVersion(1)
+		cli.RegProtocolRejectedEvent(func(conn *client.Client, r server.ProtocolReply) {
+			reply = r
+			go func() {
+				cli := client.NewWebsocket(fmt.Sprintf("ws://%s", addr)).SetProtocolVersion(3)
+				if err := cli.Run(); err != nil {
+					t.Error(err)
+					srv.Shutdown()
+					return
+				}
+				cli.WriteWS(2, []byte("hello"))
+			}()
+		})
+		if err := cli.Run(); err != nil {
+			t.Error(err)
+			srv.Shutdown()
+		}
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	wait.Wait()
+	if reply.Accepted || reply.Min != 2 || reply.Max != 3 {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+	if closedErr != server.ErrProtocolVersionIncompatible {
+		t.Fatalf("unexpected closed reason: %v", closedErr)
+	}
+	if accepted != 3 {
+		t.Fatalf("unexpected protocol version: %d", accepted)
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/xtaci/kcp-go/v5"
+)
+
+// NewKCP 创建 KCP 客户端，config 应与服务器通过 server.WithKCPConfig 指定的配置保持一致，尤其是前向纠错的分片数量
+func NewKCP(addr string, config ...server.KCPConfig) *Client {
+	k := &KCP{addr: addr}
+	if len(config) > 0 {
+		k.config = &config[0]
+	}
+	return NewClient(k)
+}
+
+// KCP KCP 客户端
+type KCP struct {
+	addr   string
+	config *server.KCPConfig
+	conn   *kcp.UDPSession
+	closed bool
+}
+
+func (slf *KCP) Run(runState chan<- error, receive func(wst int, packet []byte)) {
+	var dataShards, parityShards int
+	if slf.config != nil {
+		dataShards, parityShards = slf.config.FECData, slf.config.FECParity
+	}
+	session, err := kcp.DialWithOptions(slf.addr, nil, dataShards, parityShards)
+	if err != nil {
+		runState <- err
+		return
+	}
+	if slf.config != nil {
+		slf.config.Apply(session)
+	}
+	slf.conn = session
+	slf.closed = false
+	runState <- nil
+	packet := make([]byte, 4096)
+	for !slf.closed {
+		n, readErr := session.Read(packet)
+		if readErr != nil {
+			panic(readErr)
+		}
+		receive(0, packet[:n])
+	}
+}
+
+func (slf *KCP) Write(packet *Packet) error {
+	_, err := slf.conn.Write(packet.data)
+	return err
+}
+
+func (slf *KCP) Close() {
+	slf.closed = true
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
+}
+
+func (slf *KCP) GetServerAddr() string {
+	return slf.addr
+}
+
+func (slf *KCP) Clone() Core {
+	return &KCP{
+		addr:   slf.addr,
+		config: slf.config,
+	}
+}
@@ -5,3 +5,10 @@ type Packet struct {
 	data     []byte          // 数据包
 	callback func(err error) // 回调函数
 }
+
+// Complete 写入完成后调用回调函数，由写循环在最终的写入结果确定后调用一次
+func (slf *Packet) Complete(err error) {
+	if slf.callback != nil {
+		slf.callback(err)
+	}
+}
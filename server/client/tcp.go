@@ -17,6 +17,7 @@ type TCP struct {
 func (slf *TCP) Run(runState chan<- error, receive func(wst int, packet []byte)) {
 	dial("tcp", slf.addr, runState, receive, func(conn net.Conn) {
 		slf.conn = conn
+		slf.closed = false
 	}, func() bool {
 		return slf.closed
 	})
@@ -29,6 +30,9 @@ func (slf *TCP) Write(packet *Packet) error {
 
 func (slf *TCP) Close() {
 	slf.closed = true
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
 }
 
 func (slf *TCP) GetServerAddr() string {
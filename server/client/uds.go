@@ -19,6 +19,7 @@ type UnixDomainSocket struct {
 func (slf *UnixDomainSocket) Run(runState chan<- error, receive func(wst int, packet []byte)) {
 	dial("unix", slf.addr, runState, receive, func(conn net.Conn) {
 		slf.conn = conn
+		slf.closed = false
 	}, func() bool {
 		return slf.closed
 	})
@@ -31,6 +32,9 @@ func (slf *UnixDomainSocket) Write(packet *Packet) error {
 
 func (slf *UnixDomainSocket) Close() {
 	slf.closed = true
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
 }
 
 func (slf *UnixDomainSocket) GetServerAddr() string {
@@ -56,6 +56,9 @@ func (slf *Websocket) Close() {
 	slf.mu.Lock()
 	defer slf.mu.Unlock()
 	slf.closed = true
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
 }
 
 func (slf *Websocket) GetServerAddr() string {
@@ -0,0 +1,351 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTTL     = 10 * time.Second // 默认的节点存活时间
+	DefaultTimeout = 3 * time.Second  // 默认的单次访问注册中心超时时间
+)
+
+var (
+	ErrClusterStarted = errors.New("cluster: already started")
+	ErrNodeIDEmpty    = errors.New("cluster: node id is empty")
+)
+
+type (
+	NodeJoinEventHandler      func(cluster *Cluster, node Node)
+	NodeLeaveEventHandler     func(cluster *Cluster, node Node)
+	LeaderChangedEventHandler func(cluster *Cluster, leader string)
+)
+
+// New 创建一个以 self 作为当前节点的集群
+func New(registry Registry, self Node, options ...Option) *Cluster {
+	cluster := &Cluster{
+		registry: registry,
+		self:     self.clone(),
+		ttl:      DefaultTTL,
+		timeout:  DefaultTimeout,
+		nodes:    make(map[string]Node),
+	}
+	for _, option := range options {
+		option(cluster)
+	}
+	if cluster.heartbeat <= 0 {
+		cluster.heartbeat = cluster.ttl / 3
+	}
+	return cluster
+}
+
+// Cluster 基于注册中心的服务器集群，负责当前节点的注册、续约，其他节点的发现以及领导者选举
+//   - 节点的加入、离开及领导者的变更将在心跳协程中通过事件进行通知
+type Cluster struct {
+	registry  Registry
+	self      Node
+	ttl       time.Duration
+	heartbeat time.Duration
+	timeout   time.Duration
+	election  string
+
+	lock     sync.RWMutex
+	nodes    map[string]Node
+	leader   string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	started  bool
+	eventMu  sync.RWMutex
+	joins    []NodeJoinEventHandler
+	leaves   []NodeLeaveEventHandler
+	changeds []LeaderChangedEventHandler
+}
+
+// Attach 将集群绑定到服务器，集群将在服务器启动完成时启动，在服务器停止时停止
+//   - 通过 server.Server.RollingShutdown 滚动停止服务器时，将在排空阶段通过 Drain 将当前节点标记为排空中
+func (slf *Cluster) Attach(srv *server.Server) *Cluster {
+	srv.RegRollingShutdownEvent(func(srv *server.Server, stage server.RollingShutdownStage) {
+		if stage != server.RollingShutdownStageDraining {
+			return
+		}
+		if err := slf.Drain(); err != nil {
+			log.Error("Cluster", log.String("node", slf.self.ID), log.String("state", "drain"), log.Err(err))
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		if err := slf.Start(); err != nil {
+			log.Error("Cluster", log.String("node", slf.self.ID), log.String("state", "start"), log.Err(err))
+		}
+	})
+	srv.RegStopEvent(func(srv *server.Server) {
+		slf.Stop()
+	})
+	return slf
+}
+
+// Start 注册当前节点并启动心跳，首次注册及节点发现将同步完成
+func (slf *Cluster) Start() error {
+	if slf.self.ID == "" {
+		return ErrNodeIDEmpty
+	}
+	slf.lock.Lock()
+	if slf.started {
+		slf.lock.Unlock()
+		return ErrClusterStarted
+	}
+	slf.started = true
+	slf.ctx, slf.cancel = context.WithCancel(context.Background())
+	slf.done = make(chan struct{})
+	slf.lock.Unlock()
+
+	if err := slf.register(); err != nil {
+		slf.lock.Lock()
+		slf.started = false
+		slf.cancel()
+		slf.lock.Unlock()
+		return err
+	}
+	slf.refresh()
+	go slf.loop()
+	return nil
+}
+
+// Stop 停止心跳，注销当前节点并放弃领导者身份
+//   - Stop 将等待心跳协程退出，因此不应在集群的事件处理函数中同步调用
+func (slf *Cluster) Stop() {
+	slf.lock.Lock()
+	if !slf.started {
+		slf.lock.Unlock()
+		return
+	}
+	slf.started = false
+	slf.cancel()
+	slf.lock.Unlock()
+	<-slf.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), slf.timeout)
+	defer cancel()
+	if slf.election != "" {
+		if err := slf.registry.Resign(ctx, slf.election, slf.self.ID); err != nil {
+			log.Error("Cluster", log.String("node", slf.self.ID), log.String("state", "resign"), log.Err(err))
+		}
+	}
+	if err := slf.registry.Deregister(ctx, slf.self.ID); err != nil {
+		log.Error("Cluster", log.String("node", slf.self.ID), log.String("state", "deregister"), log.Err(err))
+	}
+
+	slf.lock.Lock()
+	slf.nodes = make(map[string]Node)
+	slf.leader = ""
+	slf.lock.Unlock()
+}
+
+// Self 获取当前节点
+func (slf *Cluster) Self() Node {
+	slf.lock.RLock()
+	defer slf.lock.RUnlock()
+	return slf.self.clone()
+}
+
+// SetMetadata 设置当前节点的元数据，将在下一次心跳时同步至注册中心
+func (slf *Cluster) SetMetadata(key, value string) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if slf.self.Metadata == nil {
+		slf.self.Metadata = make(map[string]string)
+	}
+	slf.self.Metadata[key] = value
+}
+
+// Drain 将当前节点标记为排空中并立即同步至注册中心，其他节点及网关可通过 Node.IsDraining 停止向当前节点分配新的客户端
+//   - 集群未启动时仅标记当前节点，将在启动时同步至注册中心
+func (slf *Cluster) Drain() error {
+	slf.SetMetadata(MetadataState, StateDraining)
+	slf.lock.RLock()
+	started := slf.started
+	slf.lock.RUnlock()
+	if !started {
+		return nil
+	}
+	return slf.register()
+}
+
+// Nodes 获取最近一次心跳时发现的所有存活节点，包含当前节点
+func (slf *Cluster) Nodes() []Node {
+	slf.lock.RLock()
+	defer slf.lock.RUnlock()
+	var nodes = make([]Node, 0, len(slf.nodes))
+	for _, node := range slf.nodes {
+		nodes = append(nodes, node.clone())
+	}
+	return nodes
+}
+
+// GetNode 获取特定 id 的存活节点
+func (slf *Cluster) GetNode(id string) (Node, bool) {
+	slf.lock.RLock()
+	defer slf.lock.RUnlock()
+	node, exist := slf.nodes[id]
+	if !exist {
+		return Node{}, false
+	}
+	return node.clone(), true
+}
+
+// Leader 获取当前的领导者节点 id，当未参与选举或尚未产生领导者时返回 false
+func (slf *Cluster) Leader() (string, bool) {
+	slf.lock.RLock()
+	defer slf.lock.RUnlock()
+	return slf.leader, slf.leader != ""
+}
+
+// IsLeader 检查当前节点是否为领导者
+func (slf *Cluster) IsLeader() bool {
+	leader, ok := slf.Leader()
+	return ok && leader == slf.self.ID
+}
+
+// Shard 根据 key 从存活节点中选择一个节点，适用于将游戏世界、房间等按照 key 分片到不同的机器上
+//   - 采用最高随机权重（Rendezvous）哈希，节点变化时仅有归属于变化节点的 key 会被重新分配
+//   - 正在排空的节点不会被选择，当没有可选择的节点时返回 false
+func (slf *Cluster) Shard(key string) (Node, bool) {
+	slf.lock.RLock()
+	defer slf.lock.RUnlock()
+	var target Node
+	var best uint64
+	var found bool
+	for id, node := range slf.nodes {
+		if node.IsDraining() {
+			continue
+		}
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(id))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key))
+		if score := h.Sum64(); !found || score > best || (score == best && id < target.ID) {
+			target, best, found = node, score, true
+		}
+	}
+	if !found {
+		return Node{}, false
+	}
+	return target.clone(), true
+}
+
+// RegNodeJoinEvent 在发现新节点加入时将立即执行被注册的事件处理函数
+func (slf *Cluster) RegNodeJoinEvent(handler NodeJoinEventHandler) {
+	slf.eventMu.Lock()
+	slf.joins = append(slf.joins, handler)
+	slf.eventMu.Unlock()
+}
+
+// RegNodeLeaveEvent 在发现节点离开时将立即执行被注册的事件处理函数
+func (slf *Cluster) RegNodeLeaveEvent(handler NodeLeaveEventHandler) {
+	slf.eventMu.Lock()
+	slf.leaves = append(slf.leaves, handler)
+	slf.eventMu.Unlock()
+}
+
+// RegLeaderChangedEvent 在领导者发生变更时将立即执行被注册的事件处理函数，leader 为空时表示当前没有领导者
+func (slf *Cluster) RegLeaderChangedEvent(handler LeaderChangedEventHandler) {
+	slf.eventMu.Lock()
+	slf.changeds = append(slf.changeds, handler)
+	slf.eventMu.Unlock()
+}
+
+// loop 心跳循环
+func (slf *Cluster) loop() {
+	defer close(slf.done)
+	ticker := time.NewTicker(slf.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-slf.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := slf.register(); err != nil {
+				log.Error("Cluster", log.String("node", slf.self.ID), log.String("state", "register"), log.Err(err))
+			}
+			slf.refresh()
+		}
+	}
+}
+
+// register 注册或续约当前节点
+func (slf *Cluster) register() error {
+	ctx, cancel := context.WithTimeout(slf.ctx, slf.timeout)
+	defer cancel()
+	return slf.registry.Register(ctx, slf.Self(), slf.ttl)
+}
+
+// refresh 刷新节点列表并参与选举，同时触发相应的事件
+func (slf *Cluster) refresh() {
+	ctx, cancel := context.WithTimeout(slf.ctx, slf.timeout)
+	defer cancel()
+
+	nodes, err := slf.registry.Nodes(ctx)
+	if err != nil {
+		log.Error("Cluster", log.String("node", slf.self.ID), log.String("state", "discover"), log.Err(err))
+	} else {
+		slf.updateNodes(nodes)
+	}
+
+	if slf.election == "" {
+		return
+	}
+	leader, err := slf.registry.Campaign(ctx, slf.election, slf.self.ID, slf.ttl)
+	if err != nil {
+		log.Error("Cluster", log.String("node", slf.self.ID), log.String("state", "campaign"), log.Err(err))
+		return
+	}
+	slf.lock.Lock()
+	changed := slf.leader != leader
+	slf.leader = leader
+	slf.lock.Unlock()
+	if changed {
+		slf.eventMu.RLock()
+		defer slf.eventMu.RUnlock()
+		for _, handler := range slf.changeds {
+			handler(slf, leader)
+		}
+	}
+}
+
+// updateNodes 更新节点列表并触发节点加入及离开事件
+func (slf *Cluster) updateNodes(nodes []Node) {
+	var current = make(map[string]Node, len(nodes))
+	var joined, left []Node
+	slf.lock.Lock()
+	for _, node := range nodes {
+		current[node.ID] = node
+		if _, exist := slf.nodes[node.ID]; !exist {
+			joined = append(joined, node)
+		}
+	}
+	for id, node := range slf.nodes {
+		if _, exist := current[id]; !exist {
+			left = append(left, node)
+		}
+	}
+	slf.nodes = current
+	slf.lock.Unlock()
+
+	slf.eventMu.RLock()
+	defer slf.eventMu.RUnlock()
+	for _, node := range joined {
+		for _, handler := range slf.joins {
+			handler(slf, node.clone())
+		}
+	}
+	for _, node := range left {
+		for _, handler := range slf.leaves {
+			handler(slf, node.clone())
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cluster_test
+
+import (
+	"github.com/kercylan98/minotaur/server/cluster"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCluster(t *testing.T) {
+	registry := cluster.NewMemoryRegistry()
+	options := []cluster.Option{cluster.WithTTL(300 * time.Millisecond), cluster.WithHeartbeat(20 * time.Millisecond), cluster.WithElection("world")}
+	a := cluster.New(registry, cluster.Node{ID: "a", Addr: "127.0.0.1:9001"}, options...)
+	b := cluster.New(registry, cluster.Node{ID: "b", Addr: "127.0.0.1:9002", Metadata: map[string]string{"zone": "1"}}, options...)
+
+	var joined, left atomic.Int64
+	var leader atomic.Value
+	b.RegNodeJoinEvent(func(c *cluster.Cluster, node cluster.Node) {
+		joined.Add(1)
+	})
+	b.RegNodeLeaveEvent(func(c *cluster.Cluster, node cluster.Node) {
+		left.Add(1)
+	})
+	b.RegLeaderChangedEvent(func(c *cluster.Cluster, id string) {
+		leader.Store(id)
+	})
+
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatal("node a should be the leader")
+	}
+
+	waitFor(t, func() bool { return len(a.Nodes()) == 2 })
+	if node, ok := a.GetNode("b"); !ok || node.GetMetadata("zone") != "1" {
+		t.Fatal("node b metadata not discovered")
+	}
+	for _, key := range []string{"room-1", "room-2", "room-3"} {
+		na, _ := a.Shard(key)
+		nb, _ := b.Shard(key)
+		if na.ID != nb.ID {
+			t.Fatalf("shard %s mismatch: %s != %s", key, na.ID, nb.ID)
+		}
+	}
+
+	a.Stop()
+	waitFor(t, func() bool { return b.IsLeader() && len(b.Nodes()) == 1 })
+	if joined.Load() != 2 || left.Load() != 1 || leader.Load() != "b" {
+		t.Fatalf("unexpected events, joined: %d, left: %d, leader: %v", joined.Load(), left.Load(), leader.Load())
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(3 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not satisfied in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCluster_Drain(t *testing.T) {
+	registry := cluster.NewMemoryRegistry()
+	options := []cluster.Option{cluster.WithTTL(300 * time.Millisecond), cluster.WithHeartbeat(20 * time.Millisecond)}
+	a := cluster.New(registry, cluster.Node{ID: "a"}, options...)
+	b := cluster.New(registry, cluster.Node{ID: "b"}, options...)
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Stop()
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop()
+
+	if err := a.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		node, ok := b.GetNode("a")
+		return ok && node.IsDraining()
+	})
+	for _, key := range []string{"room-1", "room-2", "room-3", "room-4"} {
+		if node, ok := b.Shard(key); !ok || node.ID != "b" {
+			t.Fatalf("shard %s should skip draining node, got: %s", key, node.ID)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewMemoryRegistry 创建一个基于内存的注册中心，适用于单进程内的多服务器及测试场景
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		nodes:     make(map[string]memoryEntry[Node]),
+		elections: make(map[string]memoryEntry[string]),
+	}
+}
+
+// MemoryRegistry 基于内存的注册中心
+type MemoryRegistry struct {
+	lock      sync.Mutex
+	nodes     map[string]memoryEntry[Node]
+	elections map[string]memoryEntry[string]
+}
+
+type memoryEntry[V any] struct {
+	value    V
+	expireAt time.Time
+}
+
+func (slf *MemoryRegistry) Register(ctx context.Context, node Node, ttl time.Duration) error {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	slf.nodes[node.ID] = memoryEntry[Node]{value: node.clone(), expireAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (slf *MemoryRegistry) Deregister(ctx context.Context, id string) error {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	delete(slf.nodes, id)
+	return nil
+}
+
+func (slf *MemoryRegistry) Nodes(ctx context.Context) ([]Node, error) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	var now = time.Now()
+	var nodes = make([]Node, 0, len(slf.nodes))
+	for id, entry := range slf.nodes {
+		if now.After(entry.expireAt) {
+			delete(slf.nodes, id)
+			continue
+		}
+		nodes = append(nodes, entry.value.clone())
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes, nil
+}
+
+func (slf *MemoryRegistry) Campaign(ctx context.Context, election, id string, ttl time.Duration) (leader string, err error) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	var now = time.Now()
+	if entry, exist := slf.elections[election]; exist && entry.value != id && now.Before(entry.expireAt) {
+		return entry.value, nil
+	}
+	slf.elections[election] = memoryEntry[string]{value: id, expireAt: now.Add(ttl)}
+	return id, nil
+}
+
+func (slf *MemoryRegistry) Resign(ctx context.Context, election, id string) error {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if entry, exist := slf.elections[election]; exist && entry.value == id {
+		delete(slf.elections, election)
+	}
+	return nil
+}
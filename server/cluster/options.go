@@ -0,0 +1,43 @@
+package cluster
+
+import "time"
+
+// Option 集群可选项
+type Option func(cluster *Cluster)
+
+// WithTTL 通过指定节点存活时间的方式创建集群，节点及领导者身份在 ttl 时间内未续约时将失效，默认为 DefaultTTL
+//   - 当未通过 WithHeartbeat 指定心跳间隔时，心跳间隔为 ttl 的三分之一
+func WithTTL(ttl time.Duration) Option {
+	return func(cluster *Cluster) {
+		if ttl > 0 {
+			cluster.ttl = ttl
+		}
+	}
+}
+
+// WithHeartbeat 通过指定心跳间隔的方式创建集群，每次心跳都将续约节点、刷新节点列表并参与领导者选举
+//   - 心跳间隔应当小于 ttl，否则节点可能会被误判为离线
+func WithHeartbeat(interval time.Duration) Option {
+	return func(cluster *Cluster) {
+		if interval > 0 {
+			cluster.heartbeat = interval
+		}
+	}
+}
+
+// WithElection 通过指定选举名称的方式创建集群，集群节点将参与该选举的领导者竞选
+//   - 默认不参与选举，此时 Cluster.Leader 始终返回 false
+func WithElection(name string) Option {
+	return func(cluster *Cluster) {
+		cluster.election = name
+	}
+}
+
+// WithTimeout 通过指定单次访问注册中心超时时间的方式创建集群，默认为 DefaultTimeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(cluster *Cluster) {
+		if timeout > 0 {
+			cluster.timeout = timeout
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package cluster
+
+import (
+	"context"
+	"time"
+)
+
+// Node 集群中的节点
+type Node struct {
+	ID       string            `json:"id"`       // 节点唯一标识
+	Addr     string            `json:"addr"`     // 节点对外或对内的服务地址
+	Metadata map[string]string `json:"metadata"` // 节点元数据，例如所承载的世界、区域、负载等
+}
+
+const (
+	MetadataState = "minotaur.state" // 节点状态的元数据键
+	StateDraining = "draining"       // 节点正在排空，不应再被分配新的客户端或分片
+)
+
+// IsDraining 检查节点是否正在排空，网关等路由组件在发现节点时应当跳过正在排空的节点
+func (n Node) IsDraining() bool {
+	return n.Metadata[MetadataState] == StateDraining
+}
+
+// GetMetadata 获取节点特定键的元数据
+func (n Node) GetMetadata(key string) string {
+	return n.Metadata[key]
+}
+
+// clone 深拷贝节点，避免元数据被外部修改
+func (n Node) clone() Node {
+	var metadata = make(map[string]string, len(n.Metadata))
+	for k, v := range n.Metadata {
+		metadata[k] = v
+	}
+	n.Metadata = metadata
+	return n
+}
+
+// Registry 节点注册中心，集群通过定期调用注册中心完成节点的注册、续约、发现及领导者选举
+//   - 可基于 etcd（租约）、consul（会话及健康检查）、redis（带过期时间的键及 SET NX）等实现
+//   - 实现应当是并发安全的
+type Registry interface {
+	// Register 注册或续约节点，节点在 ttl 时间内未续约时应当被视为离线并移除
+	Register(ctx context.Context, node Node, ttl time.Duration) error
+
+	// Deregister 注销节点
+	Deregister(ctx context.Context, id string) error
+
+	// Nodes 获取所有存活的节点
+	Nodes(ctx context.Context) ([]Node, error)
+
+	// Campaign 以 id 的身份竞选 election 的领导者，当 id 已是领导者时将续约其身份，领导者在 ttl 时间内未续约时应当失去身份
+	//  - 返回当前的领导者 id，当竞选失败时返回其他节点的 id
+	Campaign(ctx context.Context, election, id string, ttl time.Duration) (leader string, err error)
+
+	// Resign 当 id 是 election 的领导者时放弃领导者身份
+	Resign(ctx context.Context, election, id string) error
+}
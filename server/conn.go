@@ -33,36 +33,32 @@ func newKcpConn(server *Server, session *kcp.UDPSession) *Conn {
 		ctx: server.ctx,
 		connection: &connection{
 			server:     server,
+			network:    NetworkKcp,
 			remoteAddr: session.RemoteAddr(),
-			ip:         session.RemoteAddr().String(),
+			ip:         addrIP(session.RemoteAddr()),
 			kcp:        session,
 			data:       map[any]any{},
 			openTime:   time.Now(),
 		},
 	}
-	if index := strings.LastIndex(c.ip, ":"); index != -1 {
-		c.ip = c.ip[0:index]
-	}
 	c.init()
 	return c
 }
 
 // newKcpConn 创建一个处理GNet的连接
-func newGNetConn(server *Server, conn gnet.Conn) *Conn {
+func newGNetConn(server *Server, network Network, conn gnet.Conn) *Conn {
 	c := &Conn{
 		ctx: server.ctx,
 		connection: &connection{
 			server:     server,
+			network:    network,
 			remoteAddr: conn.RemoteAddr(),
-			ip:         conn.RemoteAddr().String(),
+			ip:         addrIP(conn.RemoteAddr()),
 			gn:         conn,
 			data:       map[any]any{},
 			openTime:   time.Now(),
 		},
 	}
-	if index := strings.LastIndex(c.ip, ":"); index != -1 {
-		c.ip = c.ip[0:index]
-	}
 	c.init()
 	return c
 }
@@ -73,6 +69,7 @@ func newWebsocketConn(server *Server, ws *websocket.Conn, ip string) *Conn {
 		ctx: server.ctx,
 		connection: &connection{
 			server:     server,
+			network:    NetworkWebsocket,
 			remoteAddr: ws.RemoteAddr(),
 			ip:         ip,
 			ws:         ws,
@@ -85,6 +82,7 @@ func newWebsocketConn(server *Server, ws *websocket.Conn, ip string) *Conn {
 }
 
 // NewOfflineConn 创建一个离线连接
+//   - 服务器停止时，尚未关闭的离线连接将被关闭，以释放其所使用的分流渠道
 func NewOfflineConn(server *Server) *Conn {
 	addr := &net.TCPAddr{
 		IP:   net.ParseIP(random.IPv4()),
@@ -94,6 +92,7 @@ func NewOfflineConn(server *Server) *Conn {
 		ctx: server.ctx,
 		connection: &connection{
 			server:     server,
+			network:    server.network,
 			remoteAddr: addr,
 			ip:         addr.String(),
 			data:       map[any]any{},
@@ -101,6 +100,7 @@ func NewOfflineConn(server *Server) *Conn {
 			offline:    true,
 		},
 	}
+	server.offlineConns.Store(c.GetID(), c)
 	return c
 }
 
@@ -111,7 +111,8 @@ func newBotConn(server *Server) *Conn {
 	c := &Conn{
 		ctx: server.ctx,
 		connection: &connection{
-			server: server,
+			server:  server,
+			network: server.network,
 			remoteAddr: &net.TCPAddr{
 				IP:   ip,
 				Port: port,
@@ -123,6 +124,7 @@ func newBotConn(server *Server) *Conn {
 		},
 	}
 	c.botWriter.Store(&writer)
+	c.botMarked.Store(true)
 	c.init()
 	return c
 }
@@ -137,6 +139,7 @@ type Conn struct {
 // connection 长久保持的连接
 type connection struct {
 	server      *Server
+	network     Network // 连接所属的网络类型，在监听多个地址时可能与服务器的网络类型不同
 	ticker      *timer.Ticker
 	remoteAddr  net.Addr
 	ip          string
@@ -145,6 +148,8 @@ type connection struct {
 	kcp         *kcp.UDPSession
 	gw          func(packet []byte)
 	data        map[any]any
+	dataMu      sync.RWMutex                     // 连接数据锁
+	dataWatch   map[any][]ConnDataChangedHandler // 连接数据变更的监听函数
 	closed      bool
 	pool        *hub.ObjectPool[*connPacket]
 	loop        writeloop.WriteLoop[*connPacket]
@@ -154,6 +159,25 @@ type connection struct {
 	fluctuation time.Duration
 	botWriter   atomic.Pointer[io.Writer]
 	offline     bool
+	limited     bool                    // 是否占用了连接数限制的名额
+	botMarked   atomic.Bool             // 是否为通过 NewBot 创建或通过 MarkAsBot 标记的机器人连接
+	stats       connStats               // 流量统计
+	geo         atomic.Pointer[GeoInfo] // 地理位置信息
+
+	initLock    sync.Mutex       // 连接初始化锁
+	initGated   bool             // 是否正在暂存数据包
+	initDone    bool             // 连接初始化是否已完成
+	initPending []connInitPacket // 连接初始化期间暂存的数据包
+
+	protocolState   atomic.Int32  // 协议版本协商状态
+	protocolVersion atomic.Uint32 // 协商的协议版本
+
+	compressionIn  atomic.Bool    // 读取的数据包是否携带压缩标记位
+	compressionOut atomic.Bool    // 写入的数据包是否携带压缩标记位
+	coalescer      *connCoalescer // 写入合并器
+	udp            *udpSession    // UDP 虚拟会话
+	state          atomic.Value   // 连接状态
+	identity       string         // 通过 Server.BindIdentity 绑定的身份标识
 }
 
 // Ticker 获取定时器
@@ -166,6 +190,14 @@ func (slf *Conn) GetServer() *Server {
 	return slf.server
 }
 
+// GetPacketPoolStats 获取连接写入数据包对象池的统计信息
+func (slf *Conn) GetPacketPoolStats() hub.ObjectPoolStats {
+	if slf.pool == nil {
+		return hub.ObjectPoolStats{}
+	}
+	return slf.pool.GetStats()
+}
+
 // GetOpenTime 获取连接打开时间
 func (slf *Conn) GetOpenTime() time.Time {
 	return slf.openTime
@@ -181,9 +213,17 @@ func (slf *Conn) GetWebsocketRequest() *http.Request {
 	return slf.GetData(wsRequestKey).(*http.Request)
 }
 
-// IsBot 是否是机器人连接
+// GetWebsocketSubprotocol 获取 websocket 连接协商后的子协议，非 websocket 连接或未协商子协议时将返回空字符串
+func (slf *Conn) GetWebsocketSubprotocol() string {
+	if slf.ws == nil {
+		return ""
+	}
+	return slf.ws.Subprotocol()
+}
+
+// IsBot 是否是机器人连接，包括通过 NewBot 创建的连接及通过 MarkAsBot 标记的连接
 func (slf *Conn) IsBot() bool {
-	return slf != nil && slf.ws == nil && slf.gn == nil && slf.kcp == nil && slf.gw == nil
+	return slf.isVirtual() || (slf != nil && slf.botMarked.Load())
 }
 
 // RemoteAddr 获取远程地址
@@ -194,12 +234,27 @@ func (slf *Conn) RemoteAddr() net.Addr {
 // GetID 获取连接ID
 //   - 为远程地址的字符串形式
 func (slf *Conn) GetID() string {
-	if slf.IsBot() {
+	if slf.isVirtual() {
 		return slf.ip
 	}
 	return slf.remoteAddr.String()
 }
 
+// GetGeo 获取通过 WithGeoResolver 解析的连接地理位置信息，未指定解析器或解析失败时 ok 为 false
+//   - 地理位置将在连接打开时解析，因此在 ConnectionOpenedEvent 及之后的事件中均可获取
+func (slf *Conn) GetGeo() (info GeoInfo, ok bool) {
+	if geo := slf.geo.Load(); geo != nil {
+		return *geo, true
+	}
+	return info, false
+}
+
+// SetGeo 手动设置连接的地理位置信息，例如通过网关转发的连接可使用网关传递的真实 IP 解析后进行设置
+func (slf *Conn) SetGeo(info GeoInfo) *Conn {
+	slf.geo.Store(&info)
+	return slf
+}
+
 // GetIP 获取连接IP
 func (slf *Conn) GetIP() string {
 	return slf.ip
@@ -212,22 +267,108 @@ func (slf *Conn) IsClosed() bool {
 	return slf.closed
 }
 
+// ConnDataChangedHandler 连接数据变更的监听函数，当数据被删除时 value 为 nil
+type ConnDataChangedHandler func(conn *Conn, key, old, value any)
+
 // SetData 设置连接数据，该数据将在连接关闭前始终存在
+//   - 连接数据的读写是并发安全的，设置后将同步执行通过 WatchData 注册的监听函数
 func (slf *Conn) SetData(key, value any) *Conn {
+	slf.dataMu.Lock()
+	old := slf.data[key]
 	slf.data[key] = value
+	watchers := slf.dataWatch[key]
+	slf.dataMu.Unlock()
+	for _, watcher := range watchers {
+		watcher(slf, key, old, value)
+	}
+	return slf
+}
+
+// DelData 删除连接数据，删除后将同步执行通过 WatchData 注册的监听函数
+func (slf *Conn) DelData(key any) *Conn {
+	slf.dataMu.Lock()
+	old, exist := slf.data[key]
+	delete(slf.data, key)
+	watchers := slf.dataWatch[key]
+	slf.dataMu.Unlock()
+	if exist {
+		for _, watcher := range watchers {
+			watcher(slf, key, old, nil)
+		}
+	}
 	return slf
 }
 
 // GetData 获取连接数据
 func (slf *Conn) GetData(key any) any {
+	slf.dataMu.RLock()
+	defer slf.dataMu.RUnlock()
 	return slf.data[key]
 }
 
 // ViewData 查看只读的连接数据
 func (slf *Conn) ViewData() map[any]any {
+	slf.dataMu.RLock()
+	defer slf.dataMu.RUnlock()
 	return collection.CloneMap(slf.data)
 }
 
+// WatchData 监听特定键的连接数据变更，监听函数将在 SetData 或 DelData 的调用协程中同步执行
+//   - 并发修改同一连接的数据时，监听函数可能被并发执行
+//   - ReleaseData 不会触发监听函数
+func (slf *Conn) WatchData(key any, handler ConnDataChangedHandler) *Conn {
+	slf.dataMu.Lock()
+	if slf.dataWatch == nil {
+		slf.dataWatch = make(map[any][]ConnDataChangedHandler)
+	}
+	slf.dataWatch[key] = append(slf.dataWatch[key], handler)
+	slf.dataMu.Unlock()
+	return slf
+}
+
+// ConnData 获取连接中特定键的数据并转换为 T 类型，当数据不存在或类型不匹配时返回 T 的零值
+func ConnData[T any](conn *Conn, key any) T {
+	v, _ := LookupConnData[T](conn, key)
+	return v
+}
+
+// LookupConnData 获取连接中特定键的数据并转换为 T 类型，当数据不存在或类型不匹配时 ok 为 false
+func LookupConnData[T any](conn *Conn, key any) (v T, ok bool) {
+	v, ok = conn.GetData(key).(T)
+	return
+}
+
+// SetConnData 设置连接中特定键的数据
+func SetConnData[T any](conn *Conn, key any, value T) {
+	conn.SetData(key, value)
+}
+
+// UpdateConnData 在持有连接数据锁的情况下更新特定键的数据，适用于需要基于旧值计算新值的场景，避免读取与写入之间的竞态
+//   - 当数据不存在或类型不匹配时，old 为 T 的零值，exist 为 false
+//   - updater 中不应再访问该连接的数据，否则将产生死锁
+func UpdateConnData[T any](conn *Conn, key any, updater func(old T, exist bool) T) T {
+	conn.dataMu.Lock()
+	prev := conn.data[key]
+	old, exist := prev.(T)
+	value := updater(old, exist)
+	conn.data[key] = value
+	watchers := conn.dataWatch[key]
+	conn.dataMu.Unlock()
+	for _, watcher := range watchers {
+		watcher(conn, key, prev, value)
+	}
+	return value
+}
+
+// WatchConnData 以 T 类型监听特定键的连接数据变更，当数据被删除或类型不匹配时对应的值为 T 的零值
+func WatchConnData[T any](conn *Conn, key any, handler func(conn *Conn, old, value T)) {
+	conn.WatchData(key, func(conn *Conn, key, old, value any) {
+		o, _ := old.(T)
+		v, _ := value.(T)
+		handler(conn, o, v)
+	})
+}
+
 // SetMessageData 设置消息数据，该数据将在消息处理完成后释放
 func (slf *Conn) SetMessageData(key, value any) *Conn {
 	slf.ctx = context.WithValue(slf.ctx, key, value)
@@ -241,15 +382,27 @@ func (slf *Conn) GetMessageData(key any) any {
 
 // ReleaseData 释放数据
 func (slf *Conn) ReleaseData() *Conn {
+	slf.dataMu.Lock()
 	for k := range slf.data {
 		delete(slf.data, k)
 	}
+	slf.dataWatch = nil
+	slf.dataMu.Unlock()
 	return slf
 }
 
+// isUdp 是否是基于 gnet 的 UDP 连接
+func (slf *Conn) isUdp() bool {
+	switch slf.network {
+	case NetworkUdp, NetworkUdp4, NetworkUdp6:
+		return true
+	}
+	return false
+}
+
 // IsWebsocket 是否是websocket连接
 func (slf *Conn) IsWebsocket() bool {
-	return slf.server.network == NetworkWebsocket
+	return slf.network == NetworkWebsocket
 }
 
 // GetWST 获取本次 websocket 消息类型
@@ -279,26 +432,69 @@ func (slf *Conn) PushUniqueAsyncMessage(name string, caller func() error, callba
 
 // Write 向连接中写入数据
 func (slf *Conn) Write(packet []byte, callback ...func(err error)) {
-	if slf.offline {
+	if len(callback) > 0 {
+		slf.write(packet, callback[0])
 		return
 	}
+	slf.write(packet, nil)
+}
+
+// WriteSync 向连接中写入数据并阻塞至数据被写入连接或超时，适用于在 Close 前发送踢出通知等需要确保数据已发出的场景
+//   - 连接已关闭或离线时返回 ErrConnClosed，超时返回 ErrConnWriteTimeout，写入失败时返回写入时产生的错误
+//   - timeout <= 0 时将一直等待至写入完成
+//   - 超时后数据包仍可能在稍后被写入
+//   - 不应在写入回调中调用，否则将阻塞写循环直至超时
+func (slf *Conn) WriteSync(packet []byte, timeout time.Duration) error {
 	if slf.gw != nil {
 		slf.gw(packet)
-		return
+		return nil
+	}
+	var done = make(chan error, 1)
+	if !slf.write(packet, func(err error) {
+		done <- err
+	}) {
+		return ErrConnClosed
+	}
+	if timeout <= 0 {
+		return <-done
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return ErrConnWriteTimeout
+	}
+}
+
+// write 向连接中写入数据，返回数据是否进入写循环
+func (slf *Conn) write(packet []byte, callback func(err error)) bool {
+	return slf.writePacket(packet, callback, false)
+}
+
+// writePacket 向连接中写入数据，当 compress 为 true 时，该数据包写入后连接将开始对写入的数据包进行压缩
+func (slf *Conn) writePacket(packet []byte, callback func(err error), compress bool) bool {
+	if slf.offline {
+		return false
+	}
+	if slf.gw != nil {
+		slf.gw(packet)
+		return true
 	}
 	packet = slf.server.OnConnectionWritePacketBeforeEvent(slf, packet)
 	slf.mu.Lock()
 	defer slf.mu.Unlock()
 	if slf.closed {
-		return
+		return false
 	}
 	cp := slf.pool.Get()
 	cp.wst = slf.GetWST()
 	cp.packet = packet
-	if len(callback) > 0 {
-		cp.callback = callback[0]
-	}
+	cp.callback = callback
+	cp.compress = compress
 	slf.loop.Put(cp)
+	return true
 }
 
 func (slf *Conn) init() {
@@ -318,47 +514,78 @@ func (slf *Conn) init() {
 			data.wst = 0
 			data.packet = nil
 			data.callback = nil
+			data.compress = false
+			data.packed = false
 		},
 	)
-	slf.loop = writeloop.NewChannel[*connPacket](slf.pool, slf.server.connWriteBufferSize, func(data *connPacket) error {
-		if slf.server.runtime.packetWarnSize > 0 && len(data.packet) > slf.server.runtime.packetWarnSize {
-			log.Warn("Conn.Put", log.String("State", "PacketWarn"), log.String("Reason", "PacketSize"), log.String("ID", slf.GetID()), log.Int("PacketSize", len(data.packet)))
+	if slf.server.writeCoalescingMaxDelay > 0 && (slf.gn != nil || slf.kcp != nil) && !slf.isUdp() {
+		slf.coalescer = &connCoalescer{
+			conn:     slf,
+			maxBytes: slf.server.writeCoalescingMaxBytes,
+			maxDelay: slf.server.writeCoalescingMaxDelay,
 		}
+	}
+	// 机器人连接的写循环跟随 Bot 的生命周期，不计入服务器的写循环协程组
+	group := slf.server.writeLoopGroup
+	if slf.isVirtual() {
+		group = writeloop.RoutineGroup
+	}
+	slf.loop = writeloop.NewChannel[*connPacket](slf.pool, slf.server.connWriteBufferSize, func(data *connPacket) error {
 		var err error
+		if !data.packed {
+			if slf.server.runtime.packetWarnSize > 0 && len(data.packet) > slf.server.runtime.packetWarnSize {
+				log.Warn("Conn.Put", log.String("State", "PacketWarn"), log.String("Reason", "PacketSize"), log.String("ID", slf.GetID()), log.Int("PacketSize", len(data.packet)))
+			}
+			if data.packet, err = slf.packPacket(data.packet); err != nil {
+				return writeloop.Permanent(err)
+			}
+			data.packed = true
+			if data.compress {
+				slf.compressionOut.Store(true)
+			}
+		}
 		if slf.delay > 0 || slf.fluctuation > 0 {
 			time.Sleep(random.Duration(int64(slf.delay-slf.fluctuation), int64(slf.delay+slf.fluctuation)))
 			_, err = (*slf.botWriter.Load()).Write(data.packet)
-			if data.callback != nil {
-				data.callback(err)
-			}
 			return err
 		}
 		if slf.IsWebsocket() {
+			if data.wst == WebsocketMessageTypeText && slf.IsPacketCompression() {
+				data.wst = WebsocketMessageTypeBinary
+			}
 			err = slf.ws.WriteMessage(data.wst, data.packet)
+		} else if slf.coalescer != nil {
+			// 合并写入的回调将在数据包实际写入后由合并器调用
+			slf.recordOut(len(data.packet))
+			slf.coalescer.put(data.packet, data.callback)
+			data.callback = nil
+			return nil
 		} else {
 			if slf.gn != nil {
-				switch slf.server.network {
-				case NetworkUdp, NetworkUdp4, NetworkUdp6:
+				if slf.udp != nil {
+					err = slf.udp.peer.Load().(gnet.Conn).SendTo(data.packet)
+				} else if slf.isUdp() {
 					err = slf.gn.SendTo(data.packet)
-				default:
+				} else {
 					err = slf.gn.AsyncWrite(data.packet)
 				}
 			} else if slf.kcp != nil {
 				_, err = slf.kcp.Write(data.packet)
 			}
 		}
-		if data.callback != nil {
-			data.callback(err)
+		if err == nil {
+			slf.recordOut(len(data.packet))
 		}
 		return err
 	}, func(err any) {
 		slf.Close(errors.New(fmt.Sprint(err)))
-	})
+	}, writeloop.WithRoutineGroup(group))
 }
 
 // Close 关闭连接
 func (slf *Conn) Close(err ...error) {
 	if slf.offline {
+		slf.server.offlineConns.Delete(slf.GetID())
 		slf.server.dispatcherMgr.UnBindProducer(slf.GetID())
 		return
 	}
@@ -378,9 +605,12 @@ func (slf *Conn) Close(err ...error) {
 		}
 	}()
 	slf.closed = true
+	if slf.limited {
+		slf.server.limiter.release(slf.ip)
+	}
 	if slf.ws != nil {
 		_ = slf.ws.Close()
-	} else if slf.gn != nil {
+	} else if slf.gn != nil && slf.udp == nil {
 		_ = slf.gn.Close()
 	} else if slf.kcp != nil {
 		_ = slf.kcp.Close()
@@ -390,6 +620,9 @@ func (slf *Conn) Close(err ...error) {
 	}
 	slf.loop.Close()
 	slf.mu.Unlock()
+	if slf.udp != nil {
+		slf.udp.owner.remove(slf)
+	}
 	if len(err) > 0 {
 		slf.server.OnConnectionClosedEvent(slf, err[0])
 		return
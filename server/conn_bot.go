@@ -0,0 +1,54 @@
+package server
+
+// BotIdentifier 机器人连接识别函数，返回 true 时连接将被标记为机器人连接
+type BotIdentifier func(conn *Conn) bool
+
+// isVirtual 是否是不基于网络传输的虚拟连接，例如通过 NewBot 创建的机器人连接
+func (slf *Conn) isVirtual() bool {
+	return slf != nil && slf.ws == nil && slf.gn == nil && slf.kcp == nil && slf.gw == nil
+}
+
+// isMarkedBot 是否是通过 NewBot 创建或通过 MarkAsBot 标记的机器人连接，不包括通过 NewOfflineConn 创建的离线连接
+func (slf *Conn) isMarkedBot() bool {
+	return slf != nil && slf.botMarked.Load()
+}
+
+// MarkAsBot 将基于网络传输的连接标记为机器人连接，例如压力测试客户端，标记后 IsBot 将返回 true
+//   - 机器人连接将被计入 Server.GetOnlineBotCount，而不计入 Server.GetOnlineUserCount 及消息统计
+//   - 占用的连接数限制名额将被释放，此后不再受 WithConnectionLimit 限制
+//   - 连接 ID 等基于网络传输的行为不会发生变化
+func (slf *Conn) MarkAsBot() {
+	if slf.isVirtual() || !slf.server.markBot(slf) {
+		return
+	}
+	slf.mu.Lock()
+	if slf.limited && !slf.closed {
+		slf.server.limiter.release(slf.ip)
+		slf.limited = false
+	}
+	slf.mu.Unlock()
+}
+
+// markBot 标记机器人连接，已注册的连接将被计入机器人数量，重复标记时将返回 false
+func (h *connMgr) markBot(conn *Conn) bool {
+	shard := h.shard(conn.GetID())
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if !conn.botMarked.CompareAndSwap(false, true) {
+		return false
+	}
+	if shard.connections[conn.GetID()] == conn {
+		h.botCount.Add(1)
+	}
+	return true
+}
+
+// identifyBot 在通过 WithBotIdentifier 指定了识别函数时识别并标记机器人连接
+func (srv *Server) identifyBot(conn *Conn) {
+	if srv.botIdentifier == nil || conn.IsBot() {
+		return
+	}
+	if srv.botIdentifier(conn) {
+		conn.MarkAsBot()
+	}
+}
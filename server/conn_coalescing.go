@@ -0,0 +1,75 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// connCoalescer 连接写入合并器，将短时间内写入连接的多个数据包合并为一次系统调用
+type connCoalescer struct {
+	conn      *Conn
+	maxBytes  int               // 合并的最大字节数，超出后将立即写入
+	maxDelay  time.Duration     // 合并的最大延迟
+	packets   [][]byte          // 等待写入的数据包
+	callbacks []func(err error) // 等待写入的数据包回调
+	size      int               // 等待写入的数据包总字节数
+	timer     *time.Timer       // 最大延迟定时器
+	lock      sync.Mutex
+}
+
+// put 添加一个等待合并写入的数据包
+func (slf *connCoalescer) put(packet []byte, callback func(err error)) {
+	slf.lock.Lock()
+	slf.packets = append(slf.packets, packet)
+	slf.callbacks = append(slf.callbacks, callback)
+	slf.size += len(packet)
+	if slf.maxBytes > 0 && slf.size >= slf.maxBytes {
+		packets, callbacks := slf.take()
+		slf.lock.Unlock()
+		slf.write(packets, callbacks)
+		return
+	}
+	if len(slf.packets) == 1 {
+		slf.timer = time.AfterFunc(slf.maxDelay, slf.flush)
+	}
+	slf.lock.Unlock()
+}
+
+// flush 立即写入所有等待合并写入的数据包
+func (slf *connCoalescer) flush() {
+	slf.lock.Lock()
+	packets, callbacks := slf.take()
+	slf.lock.Unlock()
+	if len(packets) > 0 {
+		slf.write(packets, callbacks)
+	}
+}
+
+// take 取出所有等待合并写入的数据包，调用方需持有锁
+func (slf *connCoalescer) take() (packets [][]byte, callbacks []func(err error)) {
+	if slf.timer != nil {
+		slf.timer.Stop()
+		slf.timer = nil
+	}
+	packets, callbacks = slf.packets, slf.callbacks
+	slf.packets, slf.callbacks, slf.size = nil, nil, 0
+	return
+}
+
+// write 通过一次系统调用写入多个数据包，当写入失败时将关闭连接
+func (slf *connCoalescer) write(packets [][]byte, callbacks []func(err error)) {
+	var err error
+	if slf.conn.gn != nil {
+		err = slf.conn.gn.AsyncWritev(packets)
+	} else if slf.conn.kcp != nil {
+		_, err = slf.conn.kcp.WriteBuffers(packets)
+	}
+	for _, callback := range callbacks {
+		if callback != nil {
+			callback(err)
+		}
+	}
+	if err != nil && !slf.conn.IsClosed() {
+		slf.conn.Close(err)
+	}
+}
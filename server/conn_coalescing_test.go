@@ -0,0 +1,53 @@
+package server_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithWriteCoalescing(t *testing.T) {
+	var callbacks atomic.Int32
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkTcp, server.WithWriteCoalescing(1024, time.Millisecond*10))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		for _, packet := range []string{"a", "b", "c"} {
+			conn.Write([]byte(packet), func(err error) {
+				if err == nil {
+					callbacks.Add(1)
+				}
+			})
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second * 3))
+			var buf = make([]byte, 3)
+			if _, err = io.ReadFull(conn, buf); err != nil {
+				t.Error(err)
+				return
+			}
+			if string(buf) != "abc" {
+				t.Errorf("receive: %s", buf)
+			}
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if callbacks.Load() != 3 {
+		t.Fatalf("callbacks: %d", callbacks.Load())
+	}
+}
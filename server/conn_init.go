@@ -0,0 +1,97 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/super"
+)
+
+// ConnectionInitializer 连接初始化函数，将在异步消息中执行，返回错误时连接将被关闭
+type ConnectionInitializer func(srv *Server, conn *Conn) error
+
+// connInitPacket 连接初始化期间暂存的数据包
+type connInitPacket struct {
+	wst    int
+	packet []byte
+	mark   []log.Field
+}
+
+// IsInitialized 检查连接是否已完成 WithConnectionInitializer 指定的初始化，未指定初始化函数时始终返回 true
+func (slf *Conn) IsInitialized() bool {
+	if slf.server.connInitializer == nil {
+		return true
+	}
+	slf.initLock.Lock()
+	defer slf.initLock.Unlock()
+	return slf.initDone
+}
+
+// gateConnInit 在通过 WithConnectionInitializer 指定了初始化函数时暂停处理连接的数据包
+func (srv *Server) gateConnInit(conn *Conn) {
+	if srv.connInitializer == nil {
+		return
+	}
+	conn.initLock.Lock()
+	conn.initGated = true
+	conn.initLock.Unlock()
+}
+
+// holdInitPacket 在连接初始化完成前暂存数据包，返回数据包是否被暂存
+func (srv *Server) holdInitPacket(conn *Conn, wst int, packet []byte, mark []log.Field) bool {
+	if srv.connInitializer == nil {
+		return false
+	}
+	conn.initLock.Lock()
+	defer conn.initLock.Unlock()
+	if !conn.initGated {
+		return false
+	}
+	conn.initPending = append(conn.initPending, connInitPacket{wst: wst, packet: packet, mark: mark})
+	return true
+}
+
+// startConnInit 通过异步消息执行连接的初始化函数，并在初始化完成后按顺序推送暂存的数据包
+func (srv *Server) startConnInit(conn *Conn) {
+	if srv.connInitializer == nil {
+		return
+	}
+	srv.PushShuntAsyncMessage(conn, func() (err error) {
+		defer func() {
+			if e := super.RecoverTransform(recover()); e != nil {
+				err = e
+			}
+		}()
+		return srv.connInitializer(srv, conn)
+	}, func(err error) {
+		if err != nil {
+			conn.initLock.Lock()
+			dropped := len(conn.initPending)
+			conn.initPending = nil
+			conn.initLock.Unlock()
+			log.Error("Server", log.String("ConnectionInitializer", conn.GetID()), log.Int("dropped", dropped), log.Err(err))
+			conn.Close(err)
+			return
+		}
+		conn.initLock.Lock()
+		conn.initDone = true
+		conn.initLock.Unlock()
+		srv.releaseInitPackets(conn)
+	}, log.String("Event", "ConnectionInitializer"))
+}
+
+// releaseInitPackets 按照接收顺序推送暂存的数据包，推送期间收到的数据包将继续被暂存并在下一轮推送，直至没有暂存的数据包时解除暂停
+func (srv *Server) releaseInitPackets(conn *Conn) {
+	for {
+		conn.initLock.Lock()
+		pending := conn.initPending
+		conn.initPending = nil
+		if len(pending) == 0 {
+			conn.initGated = false
+			conn.initLock.Unlock()
+			return
+		}
+		conn.initLock.Unlock()
+		for _, p := range pending {
+			srv.pushPacketMessage(conn, p.wst, p.packet, p.mark...)
+		}
+	}
+}
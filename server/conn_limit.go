@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// ConnectionRejectedReasonTotalLimit 连接总数已达到上限
+	ConnectionRejectedReasonTotalLimit ConnectionRejectedReason = iota + 1
+	// ConnectionRejectedReasonIPLimit 来自同一 IP 的连接数已达到上限
+	ConnectionRejectedReasonIPLimit
+	// ConnectionRejectedReasonShutdown 服务器正在停止或已通过 HandoverAndRestart 将监听器交接给新进程
+	ConnectionRejectedReasonShutdown
+)
+
+var connectionRejectedReasonNames = map[ConnectionRejectedReason]string{
+	ConnectionRejectedReasonTotalLimit: "ConnectionRejectedReasonTotalLimit",
+	ConnectionRejectedReasonIPLimit:    "ConnectionRejectedReasonIPLimit",
+	ConnectionRejectedReasonShutdown:   "ConnectionRejectedReasonShutdown",
+}
+
+// ConnectionRejectedReason 连接被拒绝的原因
+type ConnectionRejectedReason byte
+
+// String 返回拒绝原因的字符串表示
+func (slf ConnectionRejectedReason) String() string {
+	return connectionRejectedReasonNames[slf]
+}
+
+// connLimiter 在接受连接时对连接总数及单个 IP 的连接数进行限制
+type connLimiter struct {
+	lock  sync.Mutex
+	total int            // 连接总数上限
+	perIP int            // 单个 IP 的连接数上限
+	count int            // 当前连接总数
+	ips   map[string]int // 各 IP 的当前连接数
+}
+
+// set 设置连接数上限，当值 <= 0 时表示不限制
+func (l *connLimiter) set(total, perIP int) {
+	l.lock.Lock()
+	l.total, l.perIP = total, perIP
+	l.lock.Unlock()
+}
+
+// get 获取连接数上限
+func (l *connLimiter) get() (total, perIP int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.total, l.perIP
+}
+
+// acquire 尝试为来自 ip 的连接占用名额，当超出限制时将返回拒绝原因
+//   - 连接数始终会被统计，以便在运行时调整限制后能够立即生效
+func (l *connLimiter) acquire(ip string) (reason ConnectionRejectedReason, ok bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.total > 0 && l.count >= l.total {
+		return ConnectionRejectedReasonTotalLimit, false
+	}
+	if l.perIP > 0 && l.ips[ip] >= l.perIP {
+		return ConnectionRejectedReasonIPLimit, false
+	}
+	if l.ips == nil {
+		l.ips = make(map[string]int)
+	}
+	l.count++
+	l.ips[ip]++
+	return 0, true
+}
+
+// release 释放来自 ip 的连接所占用的名额
+func (l *connLimiter) release(ip string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.count--
+	if n := l.ips[ip] - 1; n > 0 {
+		l.ips[ip] = n
+	} else {
+		delete(l.ips, ip)
+	}
+}
+
+// acceptConn 在接受来自 ip 的连接时检查连接数限制，当连接被拒绝时将触发 OnConnectionRejectedEvent 事件并返回 false
+//   - 服务器开始停止后的所有连接都将被拒绝
+func (srv *Server) acceptConn(ip string) bool {
+	if atomic.LoadUint32(&srv.closed) == 1 || srv.handedOver.Load() {
+		srv.OnConnectionRejectedEvent(ip, ConnectionRejectedReasonShutdown)
+		return false
+	}
+	reason, ok := srv.limiter.acquire(ip)
+	if !ok {
+		srv.OnConnectionRejectedEvent(ip, reason)
+	}
+	return ok
+}
+
+// SetConnectionLimit 在运行时调整服务器的连接总数上限及单个 IP 的连接数上限，当值 <= 0 时表示不限制
+//   - 调整仅对新连接生效，已建立的连接不会因此被关闭
+func (srv *Server) SetConnectionLimit(total, perIP int) {
+	srv.limiter.set(total, perIP)
+}
+
+// GetConnectionLimit 获取服务器的连接总数上限及单个 IP 的连接数上限
+func (srv *Server) GetConnectionLimit() (total, perIP int) {
+	return srv.limiter.get()
+}
+
+// addrIP 获取远程地址的 IP 部分，与 Conn.GetIP 的结果一致，以便在创建连接前检查连接数限制
+func addrIP(addr net.Addr) string {
+	ip := addr.String()
+	if index := strings.LastIndex(ip, ":"); index != -1 {
+		ip = ip[0:index]
+	}
+	return ip
+}
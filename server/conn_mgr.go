@@ -2,23 +2,30 @@ package server
 
 import (
 	"context"
-	"github.com/kercylan98/minotaur/utils/collection"
 	"sync"
+	"sync/atomic"
 )
 
-type connMgr struct {
-	connections map[string]*Conn // 所有连接
+// connShardCount 连接集合的分片数量，需要为 2 的幂
+const connShardCount = 64
 
-	register   chan *Conn        // 注册连接
-	unregister chan string       // 注销连接
-	broadcast  chan hubBroadcast // 广播消息
+type connMgr struct {
+	shards [connShardCount]connShard // 按连接 ID 哈希分片的连接集合
 
-	botCount    int // 机器人数量
-	onlineCount int // 在线人数
+	broadcast chan hubBroadcast // 广播消息
 
-	chanMutex sync.RWMutex // 避免外界函数导致的并发问题
+	botCount    atomic.Int64 // 机器人数量
+	onlineCount atomic.Int64 // 在线人数
+	limiter     connLimiter  // 连接数限制
+}
 
-	closed bool
+// connShard 连接集合的分片，连接的注册与注销仅会竞争所在分片的锁
+type connShard struct {
+	mu          sync.RWMutex
+	connections map[string]*Conn // 分片中的所有连接
+	connList    []*Conn          // 分片中所有连接的列表，用于分页获取
+	connIndex   map[string]int   // 连接在列表中的位置
+	closed      bool
 }
 
 type hubBroadcast struct {
@@ -27,94 +34,187 @@ type hubBroadcast struct {
 }
 
 func (h *connMgr) run(ctx context.Context) {
-	h.connections = make(map[string]*Conn)
-	h.register = make(chan *Conn, DefaultConnHubBufferSize)
-	h.unregister = make(chan string, DefaultConnHubBufferSize)
+	for i := range h.shards {
+		h.shards[i].connections = make(map[string]*Conn)
+		h.shards[i].connIndex = make(map[string]int)
+	}
 	h.broadcast = make(chan hubBroadcast, DefaultConnHubBufferSize)
 	go func(ctx context.Context, h *connMgr) {
 		for {
 			select {
-			case conn := <-h.register:
-				h.onRegister(conn)
-			case id := <-h.unregister:
-				h.onUnregister(id)
 			case packet := <-h.broadcast:
 				h.onBroadcast(packet)
 			case <-ctx.Done():
-				h.chanMutex.Lock()
-				close(h.register)
-				close(h.unregister)
-				h.closed = true
-				h.chanMutex.Unlock()
+				for i := range h.shards {
+					shard := &h.shards[i]
+					shard.mu.Lock()
+					shard.closed = true
+					shard.mu.Unlock()
+				}
 				return
-
 			}
 		}
 	}(ctx, h)
 }
 
+// shard 获取连接 ID 所在的分片
+func (h *connMgr) shard(id string) *connShard {
+	// FNV-1a
+	var hash uint32 = 2166136261
+	for i := 0; i < len(id); i++ {
+		hash ^= uint32(id[i])
+		hash *= 16777619
+	}
+	return &h.shards[hash&(connShardCount-1)]
+}
+
 // registerConn 注册连接
 func (h *connMgr) registerConn(conn *Conn) {
-	select {
-	case h.register <- conn:
-	default:
-		h.onRegister(conn)
+	id := conn.GetID()
+	shard := h.shard(id)
+	shard.mu.Lock()
+	if shard.closed {
+		shard.mu.Unlock()
+		conn.Close()
+		return
+	}
+	if old, exist := shard.connections[id]; exist {
+		shard.connList[shard.connIndex[id]] = conn
+		h.onlineCount.Add(-1)
+		if old.IsBot() {
+			h.botCount.Add(-1)
+		}
+	} else {
+		shard.connIndex[id] = len(shard.connList)
+		shard.connList = append(shard.connList, conn)
 	}
+	shard.connections[id] = conn
+	h.onlineCount.Add(1)
+	if conn.IsBot() {
+		h.botCount.Add(1)
+	}
+	shard.mu.Unlock()
 }
 
 // unregisterConn 注销连接
 func (h *connMgr) unregisterConn(id string) {
-	select {
-	case h.unregister <- id:
-	default:
-		h.onUnregister(id)
+	shard := h.shard(id)
+	shard.mu.Lock()
+	if conn, ok := shard.connections[id]; ok {
+		h.onlineCount.Add(-1)
+		delete(shard.connections, id)
+		shard.removeFromList(id)
+		if conn.IsBot() {
+			h.botCount.Add(-1)
+		}
 	}
+	shard.mu.Unlock()
 }
 
 // GetOnlineCount 获取在线人数
 func (h *connMgr) GetOnlineCount() int {
-	h.chanMutex.RLock()
-	defer h.chanMutex.RUnlock()
-	return h.onlineCount
+	return int(h.onlineCount.Load())
 }
 
 // GetOnlineBotCount 获取在线机器人数量
 func (h *connMgr) GetOnlineBotCount() int {
-	h.chanMutex.RLock()
-	defer h.chanMutex.RUnlock()
-	return h.botCount
+	return int(h.botCount.Load())
+}
+
+// GetOnlineUserCount 获取除机器人外的在线人数
+func (h *connMgr) GetOnlineUserCount() int {
+	return int(h.onlineCount.Load() - h.botCount.Load())
 }
 
 // IsOnline 是否在线
 func (h *connMgr) IsOnline(id string) bool {
-	h.chanMutex.RLock()
-	_, exist := h.connections[id]
-	h.chanMutex.RUnlock()
-	return exist
+	return h.GetOnline(id) != nil
 }
 
 // GetOnlineAll 获取所有在线连接
 func (h *connMgr) GetOnlineAll() map[string]*Conn {
-	h.chanMutex.RLock()
-	cop := collection.CloneMap(h.connections)
-	h.chanMutex.RUnlock()
+	cop := make(map[string]*Conn, h.GetOnlineCount())
+	for i := range h.shards {
+		shard := &h.shards[i]
+		shard.mu.RLock()
+		for id, conn := range shard.connections {
+			cop[id] = conn
+		}
+		shard.mu.RUnlock()
+	}
 	return cop
 }
 
+// RangeOnline 遍历所有在线连接，当 handler 返回 false 时将停止遍历，相较于 GetOnlineAll 不会产生连接集合的拷贝
+//   - 遍历时将在持有读锁的情况下逐个复制分片中的连接列表，handler 将在释放锁后执行，因此可以在 handler 中注册或注销连接
+//   - 在遍历期间上线或下线的连接可能不会被反映
+func (h *connMgr) RangeOnline(handler func(id string, conn *Conn) bool) {
+	var conns []*Conn
+	for i := range h.shards {
+		conns = h.shards[i].snapshot(conns[:0])
+		for _, conn := range conns {
+			if !handler(conn.GetID(), conn) {
+				return
+			}
+		}
+	}
+}
+
+// GetOnlinePage 分页获取在线连接，返回从 offset 开始的至多 limit 个连接
+//   - 连接按照所在分片排列，同一分片中大致按照上线顺序排列，连接下线时分片中最后一个连接将填补其位置，因此在分页期间有连接上线或下线时可能出现重复或遗漏
+func (h *connMgr) GetOnlinePage(offset, limit int) []*Conn {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		return nil
+	}
+	var page []*Conn
+	for i := range h.shards {
+		shard := &h.shards[i]
+		shard.mu.RLock()
+		if size := len(shard.connList); offset >= size {
+			offset -= size
+		} else {
+			end := offset + limit - len(page)
+			if end > size {
+				end = size
+			}
+			page = append(page, shard.connList[offset:end]...)
+			offset = 0
+		}
+		shard.mu.RUnlock()
+		if len(page) == limit {
+			break
+		}
+	}
+	return page
+}
+
+// CountOnline 统计满足 predicate 的在线连接数量
+func (h *connMgr) CountOnline(predicate func(conn *Conn) bool) int {
+	var count int
+	h.RangeOnline(func(id string, conn *Conn) bool {
+		if predicate(conn) {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
 // GetOnline 获取在线连接
 func (h *connMgr) GetOnline(id string) *Conn {
-	h.chanMutex.RLock()
-	conn := h.connections[id]
-	h.chanMutex.RUnlock()
+	shard := h.shard(id)
+	shard.mu.RLock()
+	conn := shard.connections[id]
+	shard.mu.RUnlock()
 	return conn
 }
 
 // CloseConn 关闭连接
 func (h *connMgr) CloseConn(id string) {
-	h.chanMutex.RLock()
-	conn := h.connections[id]
-	h.chanMutex.RUnlock()
-	if conn != nil {
+	if conn := h.GetOnline(id); conn != nil {
 		conn.Close()
 	}
 }
@@ -134,39 +234,31 @@ func (h *connMgr) Broadcast(packet []byte, filter ...func(conn *Conn) bool) {
 	}
 }
 
-func (h *connMgr) onRegister(conn *Conn) {
-	h.chanMutex.Lock()
-	if h.closed {
-		conn.Close()
-		return
-	}
-	h.connections[conn.GetID()] = conn
-	h.onlineCount++
-	if conn.IsBot() {
-		h.botCount++
-	}
-	h.chanMutex.Unlock()
+func (h *connMgr) onBroadcast(packet hubBroadcast) {
+	h.RangeOnline(func(id string, conn *Conn) bool {
+		if packet.filter == nil || packet.filter(conn) {
+			conn.Write(packet.packet)
+		}
+		return true
+	})
 }
 
-func (h *connMgr) onUnregister(id string) {
-	h.chanMutex.Lock()
-	if conn, ok := h.connections[id]; ok {
-		h.onlineCount--
-		delete(h.connections, conn.GetID())
-		if conn.IsBot() {
-			h.botCount--
-		}
-	}
-	h.chanMutex.Unlock()
+// snapshot 在持有读锁的情况下将分片中的连接追加到 conns 中并返回
+func (s *connShard) snapshot(conns []*Conn) []*Conn {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(conns, s.connList...)
 }
 
-func (h *connMgr) onBroadcast(packet hubBroadcast) {
-	h.chanMutex.RLock()
-	defer h.chanMutex.RUnlock()
-	for _, conn := range h.connections {
-		if packet.filter != nil && !packet.filter(conn) {
-			continue
-		}
-		conn.Write(packet.packet)
+// removeFromList 将连接从分片的列表中移除，最后一个连接将填补其位置，需要在持有写锁时调用
+func (s *connShard) removeFromList(id string) {
+	idx := s.connIndex[id]
+	last := len(s.connList) - 1
+	if idx != last {
+		s.connList[idx] = s.connList[last]
+		s.connIndex[s.connList[idx].GetID()] = idx
 	}
+	s.connList[last] = nil
+	s.connList = s.connList[:last]
+	delete(s.connIndex, id)
 }
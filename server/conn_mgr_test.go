@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestConnMgr 创建已注册 n 个连接的连接集合
+func newTestConnMgr(n int) (*connMgr, []*Conn, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mgr := &connMgr{}
+	mgr.run(ctx)
+	conns := make([]*Conn, n)
+	for i := range conns {
+		conns[i] = &Conn{connection: &connection{ip: fmt.Sprintf("BOT:%d", i)}}
+		mgr.registerConn(conns[i])
+	}
+	return mgr, conns, cancel
+}
+
+func TestConnMgr_GetOnlinePage(t *testing.T) {
+	mgr, conns, cancel := newTestConnMgr(1000)
+	defer cancel()
+	mgr.unregisterConn(conns[0].GetID())
+
+	var seen = make(map[string]struct{})
+	for offset := 0; ; offset += 7 {
+		page := mgr.GetOnlinePage(offset, 7)
+		if len(page) == 0 {
+			break
+		}
+		for _, conn := range page {
+			seen[conn.GetID()] = struct{}{}
+		}
+	}
+	if len(seen) != 999 || mgr.GetOnlineCount() != 999 || mgr.GetOnlineBotCount() != 999 {
+		t.Fatalf("seen: %d, online: %d, bot: %d", len(seen), mgr.GetOnlineCount(), mgr.GetOnlineBotCount())
+	}
+	if _, exist := seen[conns[0].GetID()]; exist || mgr.IsOnline(conns[0].GetID()) {
+		t.Fatal("unregistered connection is still online")
+	}
+}
+
+func TestConnMgr_RangeOnline(t *testing.T) {
+	mgr, _, cancel := newTestConnMgr(100)
+	defer cancel()
+
+	// handler 中注册或注销连接需要获取分片的写锁，遍历期间持有读锁时将发生死锁
+	done := make(chan int)
+	go func() {
+		var count int
+		mgr.RangeOnline(func(id string, conn *Conn) bool {
+			count++
+			mgr.unregisterConn(id)
+			mgr.registerConn(&Conn{connection: &connection{ip: "RANGE:" + id}})
+			return true
+		})
+		done <- count
+	}()
+	select {
+	case count := <-done:
+		if count < 100 || mgr.GetOnlineCount() != 100 || mgr.IsOnline("BOT:0") {
+			t.Fatalf("count: %d, online: %d", count, mgr.GetOnlineCount())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RangeOnline deadlock")
+	}
+}
+
+func BenchmarkConnMgr_Churn(b *testing.B) {
+	mgr, _, cancel := newTestConnMgr(100000)
+	defer cancel()
+	var seq atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			conn := &Conn{connection: &connection{ip: fmt.Sprintf("CHURN:%d", seq.Add(1))}}
+			mgr.registerConn(conn)
+			mgr.unregisterConn(conn.GetID())
+		}
+	})
+}
+
+func BenchmarkConnMgr_GetOnline(b *testing.B) {
+	mgr, conns, cancel := newTestConnMgr(100000)
+	defer cancel()
+	var seq atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mgr.GetOnline(conns[seq.Add(1)%int64(len(conns))].GetID())
+		}
+	})
+}
@@ -5,4 +5,13 @@ type connPacket struct {
 	wst      int             // websocket消息类型
 	packet   []byte          // 数据包
 	callback func(err error) // 回调函数
+	compress bool            // 是否为压缩协商回复，写入后连接将开始对数据包进行压缩
+	packed   bool            // 是否已完成压缩封装，重试时将不再进行封装
+}
+
+// Complete 写入完成后调用回调函数，由写循环在最终的写入结果确定后调用一次
+func (slf *connPacket) Complete(err error) {
+	if slf.callback != nil {
+		slf.callback(err)
+	}
 }
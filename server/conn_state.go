@@ -0,0 +1,54 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+)
+
+const (
+	// ConnStateConnected 连接建立后的初始状态
+	ConnStateConnected ConnState = "Connected"
+)
+
+// ConnState 连接状态，例如 Connected、Authenticated、InGame 等，可由业务自行定义
+type ConnState string
+
+// ConnStateOpcodeResolver 从数据包中解析消息协议号的函数，无法解析时 ok 为 false
+type ConnStateOpcodeResolver func(packet []byte) (opcode uint32, ok bool)
+
+// connStateGuard 按连接状态限制允许处理的消息协议号
+type connStateGuard struct {
+	resolver  ConnStateOpcodeResolver
+	whitelist map[ConnState]map[uint32]struct{}
+}
+
+// GetState 获取连接当前的状态，未设置过状态时返回 ConnStateConnected
+func (slf *Conn) GetState() ConnState {
+	if state, ok := slf.state.Load().(ConnState); ok {
+		return state
+	}
+	return ConnStateConnected
+}
+
+// SetState 设置连接的状态，此后接收到的数据包将根据新状态的白名单进行检查
+//   - 通常在鉴权成功、进入游戏等业务节点调用，例如在处理登录消息时调用 conn.SetState("Authenticated")
+func (slf *Conn) SetState(state ConnState) {
+	slf.state.Store(state)
+}
+
+// checkConnState 在通过 WithConnStateWhitelist 开启状态白名单时检查数据包的协议号是否被连接当前的状态允许，返回数据包是否应继续被处理
+//   - 无法解析协议号或状态未声明白名单时，数据包将被丢弃
+func (srv *Server) checkConnState(conn *Conn, packet []byte) bool {
+	guard := srv.connStateGuard
+	if guard == nil {
+		return true
+	}
+	state := conn.GetState()
+	opcode, resolved := guard.resolver(packet)
+	if resolved {
+		if _, allowed := guard.whitelist[state][opcode]; allowed {
+			return true
+		}
+	}
+	log.Warn("Server", log.String("State", "ConnStateRejected"), log.String("ID", conn.GetID()), log.String("ConnState", string(state)), log.Uint32("Opcode", opcode), log.Bool("Resolved", resolved))
+	return false
+}
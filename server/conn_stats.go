@@ -0,0 +1,103 @@
+package server
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// ConnStats 连接的流量统计信息，字节数为网络传输的实际字节数，包含压缩标记位等，但不包含传输层协议的头部
+type ConnStats struct {
+	BytesIn      int64     `json:"bytes_in"`      // 接收的字节数
+	BytesOut     int64     `json:"bytes_out"`     // 发送的字节数
+	PacketsIn    int64     `json:"packets_in"`    // 接收的数据包数量
+	PacketsOut   int64     `json:"packets_out"`   // 发送的数据包数量
+	LastActivity time.Time `json:"last_activity"` // 最近一次接收或发送数据包的时间，没有任何数据包时为零值
+}
+
+// Bytes 获取接收及发送的总字节数
+func (s ConnStats) Bytes() int64 {
+	return s.BytesIn + s.BytesOut
+}
+
+// connStats 并发安全的流量统计
+type connStats struct {
+	bytesIn      atomic.Int64
+	bytesOut     atomic.Int64
+	packetsIn    atomic.Int64
+	packetsOut   atomic.Int64
+	lastActivity atomic.Int64 // 纳秒时间戳
+}
+
+func (s *connStats) recordIn(n int, now int64) {
+	s.bytesIn.Add(int64(n))
+	s.packetsIn.Add(1)
+	s.lastActivity.Store(now)
+}
+
+func (s *connStats) recordOut(n int, now int64) {
+	s.bytesOut.Add(int64(n))
+	s.packetsOut.Add(1)
+	s.lastActivity.Store(now)
+}
+
+func (s *connStats) load() ConnStats {
+	stats := ConnStats{
+		BytesIn:    s.bytesIn.Load(),
+		BytesOut:   s.bytesOut.Load(),
+		PacketsIn:  s.packetsIn.Load(),
+		PacketsOut: s.packetsOut.Load(),
+	}
+	if last := s.lastActivity.Load(); last > 0 {
+		stats.LastActivity = time.Unix(0, last)
+	}
+	return stats
+}
+
+// Stats 获取连接自打开以来的流量统计信息，机器人连接等不基于网络传输的连接始终为空
+func (slf *Conn) Stats() ConnStats {
+	return slf.stats.load()
+}
+
+// recordIn 记录连接从网络接收的数据包
+func (slf *Conn) recordIn(n int) {
+	now := time.Now().UnixNano()
+	slf.stats.recordIn(n, now)
+	slf.server.connStats.recordIn(n, now)
+}
+
+// recordOut 记录连接向网络发送的数据包
+func (slf *Conn) recordOut(n int) {
+	now := time.Now().UnixNano()
+	slf.stats.recordOut(n, now)
+	slf.server.connStats.recordOut(n, now)
+}
+
+// GetConnStats 获取服务器自启动以来所有连接的流量统计信息总和，包含已关闭的连接
+func (srv *Server) GetConnStats() ConnStats {
+	return srv.connStats.load()
+}
+
+// GetTopTrafficConns 获取接收及发送的总字节数最多的至多 n 个在线连接，可用于发现占用大量带宽的客户端
+func (srv *Server) GetTopTrafficConns(n int) []*Conn {
+	if n <= 0 {
+		return nil
+	}
+	type entry struct {
+		conn  *Conn
+		bytes int64
+	}
+	var entries []entry
+	srv.RangeOnline(func(id string, conn *Conn) bool {
+		entries = append(entries, entry{conn: conn, bytes: conn.Stats().Bytes()})
+		return true
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].bytes > entries[j].bytes
+	})
+	conns := make([]*Conn, 0, min(n, len(entries)))
+	for i := 0; i < len(entries) && i < n; i++ {
+		conns = append(conns, entries[i].conn)
+	}
+	return conns
+}
@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConnData(t *testing.T) {
+	var changes []int
+	var changesLock sync.Mutex
+	var gold int
+	var name string
+	var exist bool
+	srv := server.New(server.NetworkWebsocket)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		defer srv.Shutdown()
+		server.WatchConnData(conn, "gold", func(conn *server.Conn, old, value int) {
+			changesLock.Lock()
+			changes = append(changes, value)
+			changesLock.Unlock()
+		})
+		server.SetConnData(conn, "name", "minotaur")
+
+		var wait sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				server.UpdateConnData(conn, "gold", func(old int, exist bool) int {
+					return old + 1
+				})
+				_ = server.ConnData[string](conn, "name")
+			}()
+		}
+		wait.Wait()
+
+		gold = server.ConnData[int](conn, "gold")
+		name = server.ConnData[string](conn, "name")
+		_, exist = server.LookupConnData[int](conn, "name")
+		conn.DelData("gold")
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		server.NewBot(srv).JoinServer()
+	})
+	if err := srv.Run(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+
+	if gold != 100 || name != "minotaur" || exist {
+		t.Fatalf("gold: %d, name: %s, exist: %v", gold, name, exist)
+	}
+	if len(changes) != 101 || changes[100] != 0 {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+}
+
+func TestConn_WriteSync(t *testing.T) {
+	var buf bytes.Buffer
+	var received string
+	var errs []error
+	srv := server.New(server.NetworkWebsocket)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		defer srv.Shutdown()
+		errs = append(errs, conn.WriteSync([]byte("kick"), time.Second))
+		received = buf.String()
+		errs = append(errs, conn.WriteSync([]byte("slow"), time.Millisecond))
+		conn.Close()
+		errs = append(errs, conn.WriteSync([]byte("closed"), time.Second))
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		server.NewBot(srv, server.WithBotNetworkDelay(50*time.Millisecond, 0), server.WithBotWriter(func(bot *server.Bot) io.Writer {
+			return &buf
+		})).JoinServer()
+	})
+	if err := srv.Run(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+
+	if received != "kick" {
+		t.Fatalf("expect packet flushed before WriteSync returns, got: %q", received)
+	}
+	expected := []error{nil, server.ErrConnWriteTimeout, server.ErrConnClosed}
+	for i, err := range expected {
+		if errs[i] != err {
+			t.Fatalf("unexpected error at %d: %v", i, errs[i])
+		}
+	}
+}
+
+func TestConn_Stats(t *testing.T) {
+	var stats, total server.ConnStats
+	var top []*server.Conn
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkWebsocket)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if string(packet) == "bye" {
+			stats, total, top = conn.Stats(), srv.GetConnStats(), srv.GetTopTrafficConns(10)
+			srv.Shutdown()
+			return
+		}
+		conn.Write(packet)
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			_ = conn.WriteMessage(websocket.BinaryMessage, []byte("hello"))
+			_, _, _ = conn.ReadMessage()
+			time.Sleep(time.Millisecond * 50)
+			_ = conn.WriteMessage(websocket.BinaryMessage, []byte("bye"))
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if stats.BytesIn != 8 || stats.PacketsIn != 2 || stats.BytesOut != 5 || stats.PacketsOut != 1 || stats.LastActivity.IsZero() {
+		t.Fatalf("unexpected conn stats: %+v", stats)
+	}
+	if total.Bytes() != stats.Bytes() || len(top) != 1 {
+		t.Fatalf("unexpected server stats: %+v, top: %d", total, len(top))
+	}
+}
@@ -12,14 +12,19 @@ const (
 )
 
 const (
-	DefaultAsyncPoolSize           = 256
-	DefaultWebsocketReadDeadline   = 30 * time.Second
-	DefaultPacketWarnSize          = 1024 * 1024 * 1 // 1MB
-	DefaultDispatcherBufferSize    = 1024 * 16
-	DefaultConnWriteBufferSize     = 1024 * 1
-	DefaultConnHubBufferSize       = 1024 * 1
-	DefaultLowMessageDuration      = 100 * time.Millisecond
-	DefaultAsyncLowMessageDuration = time.Second
+	DefaultAsyncPoolSize             = 256
+	DefaultWebsocketReadDeadline     = 30 * time.Second
+	DefaultPacketWarnSize            = 1024 * 1024 * 1 // 1MB
+	DefaultDispatcherBufferSize      = 1024 * 16
+	DefaultConnWriteBufferSize       = 1024 * 1
+	DefaultConnHubBufferSize         = 1024 * 1
+	DefaultLowMessageDuration        = 100 * time.Millisecond
+	DefaultAsyncLowMessageDuration   = time.Second
+	DefaultAuditBatchSize            = 128
+	DefaultAuditFlushInterval        = time.Second
+	DefaultRollingQuietPeriod        = 10 * time.Second
+	DefaultUDPSessionTimeout         = 30 * time.Second
+	DefaultGRPCMultiplexSniffTimeout = 5 * time.Second
 )
 
 func DefaultWebsocketUpgrader() *websocket.Upgrader {
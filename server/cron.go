@@ -0,0 +1,158 @@
+package server
+
+import (
+	"github.com/gorhill/cronexpr"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/times"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CronSchedule 基于 cron 表达式的定时消息调度句柄，可用于停止调度
+type CronSchedule struct {
+	srv      *Server
+	name     string
+	expr     *cronexpr.Expression
+	location *time.Location
+	handler  func()
+	push     func(name string, caller func())
+	timer    *time.Timer
+	stopped  atomic.Bool
+	lock     sync.Mutex
+}
+
+// GetName 获取调度名称
+func (slf *CronSchedule) GetName() string {
+	return slf.name
+}
+
+// Next 获取下一次执行的时间，当不存在下一次执行时间时将返回零值
+func (slf *CronSchedule) Next() time.Time {
+	return slf.expr.Next(time.Now().In(slf.location))
+}
+
+// Stop 停止调度，已推送到分发器中的消息仍然会被执行
+func (slf *CronSchedule) Stop() {
+	if !slf.stopped.CompareAndSwap(false, true) {
+		return
+	}
+	slf.lock.Lock()
+	if slf.timer != nil {
+		slf.timer.Stop()
+	}
+	slf.lock.Unlock()
+	slf.srv.cronLock.Lock()
+	if slf.srv.crons[slf.name] == slf {
+		delete(slf.srv.crons, slf.name)
+	}
+	slf.srv.cronLock.Unlock()
+}
+
+// IsStopped 检查调度是否已停止
+func (slf *CronSchedule) IsStopped() bool {
+	return slf.stopped.Load()
+}
+
+// schedule 计算下一次执行时间并设置定时器，当不存在下一次执行时间时将停止调度
+func (slf *CronSchedule) schedule() {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if slf.stopped.Load() {
+		return
+	}
+	now := time.Now().In(slf.location)
+	next := slf.expr.Next(now)
+	if next.IsZero() {
+		go slf.Stop()
+		return
+	}
+	slf.timer = time.AfterFunc(next.Sub(now), func() {
+		if slf.stopped.Load() {
+			return
+		}
+		if atomic.LoadUint32(&slf.srv.closed) == 1 {
+			slf.Stop()
+			return
+		}
+		slf.push(slf.name, slf.handler)
+		slf.schedule()
+	})
+}
+
+// scheduleCron 创建并开始一个基于 cron 表达式的调度，同名调度将被替换
+func (srv *Server) scheduleCron(expression, name string, handler func(), push func(name string, caller func()), location ...*time.Location) (*CronSchedule, error) {
+	expr, err := times.ParseCron(expression)
+	if err != nil {
+		return nil, err
+	}
+	cs := &CronSchedule{
+		srv:      srv,
+		name:     name,
+		expr:     expr,
+		location: time.Local,
+		handler:  handler,
+		push:     push,
+	}
+	if len(location) > 0 && location[0] != nil {
+		cs.location = location[0]
+	}
+
+	srv.cronLock.Lock()
+	if srv.crons == nil {
+		srv.crons = make(map[string]*CronSchedule)
+	}
+	prev := srv.crons[name]
+	srv.crons[name] = cs
+	srv.cronLock.Unlock()
+	if prev != nil {
+		prev.Stop()
+	}
+
+	cs.schedule()
+	return cs, nil
+}
+
+// ScheduleCron 通过 cron 表达式定时向服务器中推送 MessageTypeTicker 消息，当执行时间到达时将在系统分发器中执行 handler
+//   - expression 支持秒级精度，例如 "0 0 4 * * *" 表示每天凌晨 4 点执行，适用于每日重置等游戏逻辑，格式可参考 times.ParseCron
+//   - location 为可选的时区，默认为 time.Local，例如需要按照特定地区的时间进行每日重置时可指定该地区的时区
+//   - 相同 name 的调度将会替换之前的调度
+//   - 当 cron 表达式错误时将返回错误
+//   - 服务器关闭后调度将被自动停止
+func (srv *Server) ScheduleCron(expression, name string, handler func(), location ...*time.Location) (*CronSchedule, error) {
+	return srv.scheduleCron(expression, name, handler, func(name string, caller func()) {
+		srv.PushTickerMessage(name, caller, log.String("cron", name))
+	}, location...)
+}
+
+// ScheduleShuntCron 通过 cron 表达式定时向特定分发器中推送 MessageTypeShuntTicker 消息，当执行时间到达时将在连接所使用的分发器中执行 handler
+//   - 连接所使用的分发器以执行时间到达时为准
+//   - 其他说明同 ScheduleCron
+func (srv *Server) ScheduleShuntCron(conn *Conn, expression, name string, handler func(), location ...*time.Location) (*CronSchedule, error) {
+	return srv.scheduleCron(expression, name, handler, func(name string, caller func()) {
+		srv.PushShuntTickerMessage(conn, name, caller, log.String("cron", name))
+	}, location...)
+}
+
+// StopCron 停止特定名称的 cron 调度
+func (srv *Server) StopCron(name string) {
+	srv.cronLock.Lock()
+	cs := srv.crons[name]
+	srv.cronLock.Unlock()
+	if cs != nil {
+		cs.Stop()
+	}
+}
+
+// stopAllCron 停止所有的 cron 调度
+func (srv *Server) stopAllCron() {
+	srv.cronLock.Lock()
+	var schedules = make([]*CronSchedule, 0, len(srv.crons))
+	for _, cs := range srv.crons {
+		schedules = append(schedules, cs)
+	}
+	srv.cronLock.Unlock()
+	for _, cs := range schedules {
+		cs.Stop()
+	}
+}
@@ -0,0 +1,38 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestServer_ScheduleCron(t *testing.T) {
+	var executed int
+	srv := server.New(server.NetworkNone)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		if _, err := srv.ScheduleCron("invalid", "invalid", func() {}); err == nil {
+			t.Error("invalid cron expression should return error")
+		}
+		loc := time.FixedZone("UTC+8", 8*60*60)
+		cs, err := srv.ScheduleCron("* * * * * *", "every_second", func() {
+			executed++
+			if executed == 2 {
+				srv.Shutdown()
+			}
+		}, loc)
+		if err != nil {
+			t.Error(err)
+			srv.Shutdown()
+			return
+		}
+		if next := cs.Next(); next.Location() != loc {
+			t.Errorf("next location: %v", next.Location())
+		}
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+	if executed < 2 {
+		t.Fatalf("executed: %d", executed)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	goruntime "runtime"
+	"runtime/pprof"
+	"time"
+)
+
+// DeadlockReport 死锁检测报告，用于在 OnDeadlockDetectEvent 中进行事后分析
+type DeadlockReport struct {
+	Message      string        // 疑似死锁的消息描述
+	Type         MessageType   // 疑似死锁的消息类型
+	Timeout      time.Duration // 通过 WithDeadlockDetect 设置的检测时长
+	HandlerSites []string      // 消息处理函数的定义位置，数据包消息为所有 ConnectionReceivePacketEvent 事件处理函数的定义位置
+	Goroutines   []byte        // 检测触发时所有协程的堆栈，仅在通过 WithDeadlockGoroutineDump 开启时存在
+}
+
+// newDeadlockReport 生成消息的死锁检测报告，调用时应持有消息的读锁
+func (srv *Server) newDeadlockReport(msg *Message) *DeadlockReport {
+	report := &DeadlockReport{
+		Message: msg.String(),
+		Type:    msg.t,
+		Timeout: srv.deadlockDetect,
+	}
+	switch msg.t {
+	case MessageTypePacket:
+		srv.connectionReceivePacketEventHandlers.RangeValue(func(index int, value ConnectionReceivePacketEventHandler) bool {
+			report.HandlerSites = append(report.HandlerSites, funcSite(value))
+			return true
+		})
+	default:
+		for _, handler := range []any{msg.ordinaryHandler, msg.exceptionHandler, msg.errHandler} {
+			if site := funcSite(handler); len(site) > 0 {
+				report.HandlerSites = append(report.HandlerSites, site)
+			}
+		}
+	}
+	if srv.deadlockGoroutineDump {
+		var buf bytes.Buffer
+		if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err == nil {
+			report.Goroutines = buf.Bytes()
+		}
+	}
+	return report
+}
+
+// funcSite 获取函数的定义位置，格式为 "file:line name"，fn 为空时返回空字符串
+func funcSite(fn any) string {
+	v := reflect.ValueOf(fn)
+	if !v.IsValid() || v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := goruntime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	file, line := f.FileLine(f.Entry())
+	return fmt.Sprintf("%s:%d %s", file, line, f.Name())
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	delayedMessageStatePending int32 = iota
+	delayedMessageStateExecuted
+	delayedMessageStateCanceled
+)
+
+// DelayedMessage 延迟消息的句柄，可用于在消息执行前取消该消息
+type DelayedMessage struct {
+	timer *time.Timer
+	state atomic.Int32
+}
+
+// Cancel 取消延迟消息，返回是否在消息执行前成功取消
+//   - 即便延迟时间已到达且消息已被推送到分发器中，只要消息还未被执行，依旧可以取消成功
+func (slf *DelayedMessage) Cancel() bool {
+	if !slf.state.CompareAndSwap(delayedMessageStatePending, delayedMessageStateCanceled) {
+		return false
+	}
+	slf.timer.Stop()
+	return true
+}
+
+// IsExecuted 检查延迟消息是否已被执行
+func (slf *DelayedMessage) IsExecuted() bool {
+	return slf.state.Load() == delayedMessageStateExecuted
+}
+
+// IsCanceled 检查延迟消息是否已被取消
+func (slf *DelayedMessage) IsCanceled() bool {
+	return slf.state.Load() == delayedMessageStateCanceled
+}
+
+// wrap 包装消息处理函数，确保被取消的消息不会被执行
+func (slf *DelayedMessage) wrap(handler func()) func() {
+	return func() {
+		if slf.state.CompareAndSwap(delayedMessageStatePending, delayedMessageStateExecuted) {
+			handler()
+		}
+	}
+}
+
+// newDelayedMessage 创建一个延迟消息，当延迟时间到达时将通过 push 推送消息
+func (srv *Server) newDelayedMessage(delay time.Duration, handler func(), push func(handler func())) *DelayedMessage {
+	dm := new(DelayedMessage)
+	caller := dm.wrap(handler)
+	dm.timer = time.AfterFunc(delay, func() {
+		if atomic.LoadUint32(&srv.closed) == 1 {
+			dm.state.CompareAndSwap(delayedMessageStatePending, delayedMessageStateCanceled)
+			return
+		}
+		push(caller)
+	})
+	return dm
+}
+
+// PushDelayedMessage 向服务器中推送延迟执行的 MessageTypeSystem 消息，当延迟时间到达时将在系统分发器中执行 handler
+//   - 返回的 DelayedMessage 可用于在消息执行前取消该消息
+//   - 服务器关闭后到达延迟时间的消息将被自动取消
+//   - mark 为可选的日志标记，当发生异常时，将会在日志中进行体现
+func (srv *Server) PushDelayedMessage(delay time.Duration, handler func(), mark ...log.Field) *DelayedMessage {
+	return srv.newDelayedMessage(delay, handler, func(handler func()) {
+		srv.PushSystemMessage(handler, mark...)
+	})
+}
+
+// PushShuntDelayedMessage 向特定分发器中推送延迟执行的 MessageTypeShunt 消息，当延迟时间到达时将在连接所使用的分发器中执行 handler
+//   - 连接所使用的分发器以延迟时间到达时为准
+//   - 返回的 DelayedMessage 可用于在消息执行前取消该消息
+//   - 服务器关闭后到达延迟时间的消息将被自动取消
+//   - mark 为可选的日志标记，当发生异常时，将会在日志中进行体现
+func (srv *Server) PushShuntDelayedMessage(conn *Conn, delay time.Duration, handler func(), mark ...log.Field) *DelayedMessage {
+	return srv.newDelayedMessage(delay, handler, func(handler func()) {
+		srv.PushShuntMessage(conn, handler, mark...)
+	})
+}
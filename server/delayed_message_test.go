@@ -0,0 +1,31 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestServer_PushDelayedMessage(t *testing.T) {
+	var executed, canceled bool
+	srv := server.New(server.NetworkNone)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		start := time.Now()
+		dm := srv.PushDelayedMessage(time.Millisecond*50, func() {
+			canceled = true
+		})
+		if !dm.Cancel() {
+			t.Error("delayed message cancel failed")
+		}
+		srv.PushDelayedMessage(time.Millisecond*50, func() {
+			executed = time.Since(start) >= time.Millisecond*50
+			srv.Shutdown()
+		})
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+	if !executed || canceled {
+		t.Fatalf("executed: %v, canceled: %v", executed, canceled)
+	}
+}
@@ -3,11 +3,30 @@ package server
 import "errors"
 
 var (
-	ErrConstructed                 = errors.New("the Server must be constructed using the server.New function")
-	ErrCanNotSupportNetwork        = errors.New("can not support network")
-	ErrNetworkOnlySupportHttp      = errors.New("the current network mode is not compatible with HttpRouter, only NetworkHttp is supported")
-	ErrNetworkOnlySupportGRPC      = errors.New("the current network mode is not compatible with RegGrpcServer, only NetworkGRPC is supported")
-	ErrNetworkIncompatibleHttp     = errors.New("the current network mode is not compatible with NetworkHttp")
-	ErrWebsocketIllegalMessageType = errors.New("illegal message type")
-	ErrNoSupportTicker             = errors.New("the server does not support Ticker, please use the WithTicker option to create the server")
+	ErrConstructed                  = errors.New("the Server must be constructed using the server.New function")
+	ErrCanNotSupportNetwork         = errors.New("can not support network")
+	ErrNetworkOnlySupportHttp       = errors.New("the current network mode is not compatible with HttpRouter, only NetworkHttp and NetworkWebsocket are supported")
+	ErrHttpEngineNotGin             = errors.New("the server uses a custom HttpEngine, routes must be registered through HttpEngine")
+	ErrNetworkOnlySupportGRPC       = errors.New("the current network mode is not compatible with RegGrpcServer, only NetworkGRPC is supported")
+	ErrNetworkIncompatibleHttp      = errors.New("the current network mode is not compatible with NetworkHttp")
+	ErrWebsocketIllegalMessageType  = errors.New("illegal message type")
+	ErrNoSupportTicker              = errors.New("the server does not support Ticker, please use the WithTicker option to create the server")
+	ErrPacketOversize               = errors.New("packet size exceeds the limit")
+	ErrPacketCompressionUnsupported = errors.New("unsupported packet compression algorithm")
+	ErrPacketCompressionHeader      = errors.New("packet compression header missing")
+	ErrAdditionalListenUnsupported  = errors.New("unsupported network mode for additional listen, only socket network modes are supported")
+	ErrGeoNotFound                  = errors.New("geo location not found")
+	ErrProtocolVersionIncompatible  = errors.New("protocol version incompatible, please update the client")
+	ErrRollingShutdownInProgress    = errors.New("rolling shutdown is already in progress")
+	ErrConnClosed                   = errors.New("connection is closed")
+	ErrConnWriteTimeout             = errors.New("connection write timeout")
+	ErrUDPSessionTimeout            = errors.New("udp session inactive timeout")
+	ErrLoginConflictKicked          = errors.New("the identity has logged in elsewhere")
+	ErrLoginConflictRejected        = errors.New("the identity is already logged in")
+	ErrAsyncTimeout                 = errors.New("async message await timeout")
+	ErrAsyncCanceled                = errors.New("async message canceled")
+	ErrWebsocketAssemblyTimeout     = errors.New("websocket fragmented message assembly timeout")
+	ErrHandoverInProgress           = errors.New("handover is already in progress")
+	ErrHandoverUnsupported          = errors.New("listener does not support handover")
+	ErrHandoverProcessExited        = errors.New("handover process exited before ready")
 )
@@ -6,11 +6,11 @@ import (
 	"github.com/kercylan98/minotaur/utils/collection/listings"
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/runtimes"
+	"github.com/kercylan98/minotaur/utils/super"
 	"golang.org/x/crypto/ssh/terminal"
 	"net/url"
 	"os"
 	"reflect"
-	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
@@ -28,16 +28,26 @@ type (
 	ConnectionReceivePacketEventHandler     func(srv *Server, conn *Conn, packet []byte)
 	ConnectionWritePacketBeforeEventHandler func(srv *Server, conn *Conn, packet []byte) []byte
 	ConnectionClosedEventHandler            func(srv *Server, conn *Conn, err any)
-
-	ShuntChannelCreatedEventHandler func(srv *Server, name string)
-	ShuntChannelClosedEventHandler  func(srv *Server, name string)
-
-	MessageExecBeforeEventHandler func(srv *Server, message *Message) bool
-	MessageLowExecEventHandler    func(srv *Server, message *Message, cost time.Duration)
-	MessageErrorEventHandler      func(srv *Server, message *Message, err error)
+	ConnectionPacketOversizeEventHandler    func(srv *Server, conn *Conn, size int, policy PacketLimitPolicy)
+	ConnectionRejectedEventHandler          func(srv *Server, ip string, reason ConnectionRejectedReason)
+
+	ShuntChannelCreatedEventHandler   func(srv *Server, name string)
+	ShuntChannelClosedEventHandler    func(srv *Server, name string)
+	ShuntChannelOverflowEventHandler  func(srv *Server, name string, policy ShuntOverflowPolicy, dropped *Message)
+	MessageMemoryOverflowEventHandler func(srv *Server, dropped *Message, queuedBytes int64)
+	UniqueMessageDroppedEventHandler  func(srv *Server, dropped *Message, policy UniqueMessagePolicy)
+	LoginConflictEventHandler         func(srv *Server, identity string, old, new *Conn, policy LoginConflictPolicy)
+	RollingShutdownEventHandler       func(srv *Server, stage RollingShutdownStage)
+	AsyncPoolSaturatedEventHandler    func(srv *Server, message *Message, stats RuntimeStats)
+	LifeEndingEventHandler            func(srv *Server, remaining time.Duration)
+
+	MessageExecBeforeEventHandler    func(srv *Server, message *Message) bool
+	MessageLowExecEventHandler       func(srv *Server, message *Message, cost time.Duration)
+	MessageLowExecDetailEventHandler func(srv *Server, message *Message, cost, threshold time.Duration)
+	MessageErrorEventHandler         func(srv *Server, message *Message, err error)
 
 	ConsoleCommandEventHandler   func(srv *Server, command string, params ConsoleParams)
-	OnDeadlockDetectEventHandler func(srv *Server, message *Message)
+	OnDeadlockDetectEventHandler func(srv *Server, message *Message, report *DeadlockReport)
 )
 
 func newEvent(srv *Server) *event {
@@ -51,14 +61,24 @@ func newEvent(srv *Server) *event {
 		connectionClosedEventHandlers:           listings.NewPrioritySlice[ConnectionClosedEventHandler](),
 		messageErrorEventHandlers:               listings.NewPrioritySlice[MessageErrorEventHandler](),
 		messageLowExecEventHandlers:             listings.NewPrioritySlice[MessageLowExecEventHandler](),
+		messageLowExecDetailEventHandlers:       listings.NewPrioritySlice[MessageLowExecDetailEventHandler](),
 		connectionOpenedAfterEventHandlers:      listings.NewPrioritySlice[ConnectionOpenedAfterEventHandler](),
 		connectionWritePacketBeforeHandlers:     listings.NewPrioritySlice[ConnectionWritePacketBeforeEventHandler](),
 		shuntChannelCreatedEventHandlers:        listings.NewPrioritySlice[ShuntChannelCreatedEventHandler](),
 		shuntChannelClosedEventHandlers:         listings.NewPrioritySlice[ShuntChannelClosedEventHandler](),
+		shuntChannelOverflowEventHandlers:       listings.NewPrioritySlice[ShuntChannelOverflowEventHandler](),
+		messageMemoryOverflowEventHandlers:      listings.NewPrioritySlice[MessageMemoryOverflowEventHandler](),
+		uniqueMessageDroppedEventHandlers:       listings.NewPrioritySlice[UniqueMessageDroppedEventHandler](),
+		loginConflictEventHandlers:              listings.NewPrioritySlice[LoginConflictEventHandler](),
+		rollingShutdownEventHandlers:            listings.NewPrioritySlice[RollingShutdownEventHandler](),
+		asyncPoolSaturatedEventHandlers:         listings.NewPrioritySlice[AsyncPoolSaturatedEventHandler](),
+		lifeEndingEventHandlers:                 listings.NewPrioritySlice[LifeEndingEventHandler](),
 		connectionPacketPreprocessEventHandlers: listings.NewPrioritySlice[ConnectionPacketPreprocessEventHandler](),
 		messageExecBeforeEventHandlers:          listings.NewPrioritySlice[MessageExecBeforeEventHandler](),
 		messageReadyEventHandlers:               listings.NewPrioritySlice[MessageReadyEventHandler](),
 		deadlockDetectEventHandlers:             listings.NewPrioritySlice[OnDeadlockDetectEventHandler](),
+		connectionPacketOversizeEventHandlers:   listings.NewPrioritySlice[ConnectionPacketOversizeEventHandler](),
+		connectionRejectedEventHandlers:         listings.NewPrioritySlice[ConnectionRejectedEventHandler](),
 	}
 }
 
@@ -72,14 +92,24 @@ type event struct {
 	connectionClosedEventHandlers           *listings.PrioritySlice[ConnectionClosedEventHandler]
 	messageErrorEventHandlers               *listings.PrioritySlice[MessageErrorEventHandler]
 	messageLowExecEventHandlers             *listings.PrioritySlice[MessageLowExecEventHandler]
+	messageLowExecDetailEventHandlers       *listings.PrioritySlice[MessageLowExecDetailEventHandler]
 	connectionOpenedAfterEventHandlers      *listings.PrioritySlice[ConnectionOpenedAfterEventHandler]
 	connectionWritePacketBeforeHandlers     *listings.PrioritySlice[ConnectionWritePacketBeforeEventHandler]
 	shuntChannelCreatedEventHandlers        *listings.PrioritySlice[ShuntChannelCreatedEventHandler]
 	shuntChannelClosedEventHandlers         *listings.PrioritySlice[ShuntChannelClosedEventHandler]
+	shuntChannelOverflowEventHandlers       *listings.PrioritySlice[ShuntChannelOverflowEventHandler]
+	messageMemoryOverflowEventHandlers      *listings.PrioritySlice[MessageMemoryOverflowEventHandler]
+	uniqueMessageDroppedEventHandlers       *listings.PrioritySlice[UniqueMessageDroppedEventHandler]
+	loginConflictEventHandlers              *listings.PrioritySlice[LoginConflictEventHandler]
+	rollingShutdownEventHandlers            *listings.PrioritySlice[RollingShutdownEventHandler]
+	asyncPoolSaturatedEventHandlers         *listings.PrioritySlice[AsyncPoolSaturatedEventHandler]
+	lifeEndingEventHandlers                 *listings.PrioritySlice[LifeEndingEventHandler]
 	connectionPacketPreprocessEventHandlers *listings.PrioritySlice[ConnectionPacketPreprocessEventHandler]
 	messageExecBeforeEventHandlers          *listings.PrioritySlice[MessageExecBeforeEventHandler]
 	messageReadyEventHandlers               *listings.PrioritySlice[MessageReadyEventHandler]
 	deadlockDetectEventHandlers             *listings.PrioritySlice[OnDeadlockDetectEventHandler]
+	connectionPacketOversizeEventHandlers   *listings.PrioritySlice[ConnectionPacketOversizeEventHandler]
+	connectionRejectedEventHandlers         *listings.PrioritySlice[ConnectionRejectedEventHandler]
 
 	consoleCommandEventHandlers        map[string]*listings.PrioritySlice[ConsoleCommandEventHandler]
 	consoleCommandEventHandlerInitOnce sync.Once
@@ -168,9 +198,8 @@ func (slf *event) RegStartBeforeEvent(handler StartBeforeEventHandler, priority
 
 func (slf *event) OnStartBeforeEvent() {
 	defer func() {
-		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnStartBeforeEvent", fmt.Sprintf("%v", err)))
-			debug.PrintStack()
+		if err := super.RecoverPanic(recover()); err != nil {
+			log.Error("Server", log.String("OnStartBeforeEvent", err.Error()), log.String("stack", string(err.Stack)))
 		}
 	}()
 	slf.startBeforeEventHandlers.RangeValue(func(index int, value StartBeforeEventHandler) bool {
@@ -193,17 +222,29 @@ func (slf *event) OnStartFinishEvent() {
 			return true
 		})
 	}, log.String("Event", "OnStartFinishEvent"))
-	if slf.Server.limitLife > 0 {
-		go func() {
-			time.Sleep(slf.Server.limitLife)
-			slf.Shutdown()
-		}()
-	}
+	slf.Server.runLimitLife()
+}
+
+// RegLifeEndingEvent 在通过 WithLimitLife 限制了最大生命周期的服务器到达预警阈值时将立刻执行被注册的事件处理函数
+//   - remaining 为距离生命周期结束的剩余时长，可在事件处理函数中通过 Server.ExtendLife 延长生命周期，例如等待当前对局结束
+//   - 该事件将以系统消息的形式在系统分发器中执行
+func (slf *event) RegLifeEndingEvent(handler LifeEndingEventHandler, priority ...int) {
+	slf.lifeEndingEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnLifeEndingEvent(remaining time.Duration) {
+	slf.PushSystemMessage(func() {
+		slf.lifeEndingEventHandlers.RangeValue(func(index int, value LifeEndingEventHandler) bool {
+			value(slf.Server, remaining)
+			return true
+		})
+	}, log.String("Event", "OnLifeEndingEvent"))
 }
 
 // RegConnectionClosedEvent 在连接关闭后将立刻执行被注册的事件处理函数
 func (slf *event) RegConnectionClosedEvent(handler ConnectionClosedEventHandler, priority ...int) {
-	if slf.network == NetworkHttp {
+	if slf.network == NetworkHttp && len(slf.additionalListens) == 0 {
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionClosedEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
@@ -217,6 +258,7 @@ func (slf *event) OnConnectionClosedEvent(conn *Conn, err any) {
 			value(slf.Server, conn, err)
 			return true
 		})
+		slf.UnbindIdentity(conn)
 		slf.Server.dispatcherMgr.UnBindProducer(conn.GetID())
 	}, log.String("Event", "OnConnectionClosedEvent"))
 }
@@ -224,7 +266,7 @@ func (slf *event) OnConnectionClosedEvent(conn *Conn, err any) {
 // RegConnectionOpenedEvent 在连接打开后将立刻执行被注册的事件处理函数
 //   - 该阶段的事件将会在系统消息中进行处理，不适合处理耗时操作
 func (slf *event) RegConnectionOpenedEvent(handler ConnectionOpenedEventHandler, priority ...int) {
-	if slf.network == NetworkHttp {
+	if slf.network == NetworkHttp && len(slf.additionalListens) == 0 {
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionOpenedEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
@@ -232,6 +274,13 @@ func (slf *event) RegConnectionOpenedEvent(handler ConnectionOpenedEventHandler,
 }
 
 func (slf *event) OnConnectionOpenedEvent(conn *Conn) {
+	slf.identifyBot(conn)
+	slf.resolveGeo(conn)
+	slf.gateConnInit(conn)
+	if !conn.IsBot() {
+		slf.lastConnOpened.Store(time.Now().UnixNano())
+	}
+	slf.matchShunt(conn, nil)
 	slf.PushSystemMessage(func() {
 		slf.registerConn(conn)
 		slf.connectionOpenedEventHandlers.RangeValue(func(index int, value ConnectionOpenedEventHandler) bool {
@@ -239,12 +288,13 @@ func (slf *event) OnConnectionOpenedEvent(conn *Conn) {
 			return true
 		})
 		slf.OnConnectionOpenedAfterEvent(conn)
+		slf.startConnInit(conn)
 	}, log.String("Event", "OnConnectionOpenedEvent"))
 }
 
 // RegConnectionReceivePacketEvent 在接收到数据包时将立刻执行被注册的事件处理函数
 func (slf *event) RegConnectionReceivePacketEvent(handler ConnectionReceivePacketEventHandler, priority ...int) {
-	if slf.network == NetworkHttp {
+	if slf.network == NetworkHttp && len(slf.additionalListens) == 0 {
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionReceivePacketEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
@@ -261,7 +311,53 @@ func (slf *event) OnConnectionReceivePacketEvent(conn *Conn, packet []byte) {
 	})
 }
 
+// RegConnectionRejectedEvent 在连接由于超出 WithConnectionLimit 的连接数限制而被拒绝时将立即执行被注册的事件处理函数
+//   - 被拒绝的连接不会触发 OnConnectionOpenedEvent 及 OnConnectionClosedEvent 事件
+func (slf *event) RegConnectionRejectedEvent(handler ConnectionRejectedEventHandler, priority ...int) {
+	slf.connectionRejectedEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionRejectedEvent(ip string, reason ConnectionRejectedReason) {
+	log.Warn("Server", log.String("State", "ConnectionRejected"), log.String("IP", ip), log.String("Reason", reason.String()))
+	if slf.connectionRejectedEventHandlers.Len() == 0 {
+		return
+	}
+	slf.PushSystemMessage(func() {
+		slf.connectionRejectedEventHandlers.RangeValue(func(index int, value ConnectionRejectedEventHandler) bool {
+			value(slf.Server, ip, reason)
+			return true
+		})
+	}, log.String("Event", "OnConnectionRejectedEvent"))
+}
+
+// RegConnectionPacketOversizeEvent 在接收到超出 WithPacketLimitSize 大小限制的数据包时将立即执行被注册的事件处理函数
+//   - 超出限制的数据包已被丢弃，size 为数据包大小，policy 为当前的处理策略
+//   - 当 policy 为 PacketLimitPolicyClose 时，事件执行时连接可能已经关闭
+//   - 对于 NetworkWebsocket 而言，由于超出限制的数据帧不会被完整读取，size 将为限制大小 + 1
+func (slf *event) RegConnectionPacketOversizeEvent(handler ConnectionPacketOversizeEventHandler, priority ...int) {
+	if slf.network == NetworkHttp && len(slf.additionalListens) == 0 {
+		panic(ErrNetworkIncompatibleHttp)
+	}
+	slf.connectionPacketOversizeEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionPacketOversizeEvent(conn *Conn, size int) {
+	if slf.connectionPacketOversizeEventHandlers.Len() == 0 {
+		return
+	}
+	policy := slf.Server.runtime.packetLimitPolicy
+	slf.PushSystemMessage(func() {
+		slf.connectionPacketOversizeEventHandlers.RangeValue(func(index int, value ConnectionPacketOversizeEventHandler) bool {
+			value(slf.Server, conn, size, policy)
+			return true
+		})
+	}, log.String("Event", "OnConnectionPacketOversizeEvent"))
+}
+
 // RegMessageErrorEvent 在处理消息发生错误时将立即执行被注册的事件处理函数
+//   - 当消息处理函数发生 panic 时，err 为 *PanicReport，可通过 errors.As 获取
 func (slf *event) RegMessageErrorEvent(handler MessageErrorEventHandler, priority ...int) {
 	slf.messageErrorEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
 	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
@@ -272,9 +368,8 @@ func (slf *event) OnMessageErrorEvent(message *Message, err error) {
 		return
 	}
 	defer func() {
-		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnMessageErrorEvent", messageNames[message.t]), log.Any("Error", err))
-			debug.PrintStack()
+		if err := super.RecoverPanic(recover()); err != nil {
+			log.Error("Server", log.String("OnMessageErrorEvent", messageNames[message.t]), log.Any("Error", err.Err), log.String("stack", string(err.Stack)))
 		}
 	}()
 	slf.messageErrorEventHandlers.RangeValue(func(index int, value MessageErrorEventHandler) bool {
@@ -289,8 +384,15 @@ func (slf *event) RegMessageLowExecEvent(handler MessageLowExecEventHandler, pri
 	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
-func (slf *event) OnMessageLowExecEvent(message *Message, cost time.Duration) {
-	if slf.messageLowExecEventHandlers.Len() == 0 {
+// RegMessageLowExecDetailEvent 在处理消息缓慢时将立即执行被注册的事件处理函数，与 RegMessageLowExecEvent 不同的是，该事件将额外携带触发慢消息的阈值
+//   - threshold 为该消息所适用的慢消息时长，同步消息为 WithLowMessageDuration 设置的值，异步消息及 HTTP 请求为 WithAsyncLowMessageDuration 设置的值
+func (slf *event) RegMessageLowExecDetailEvent(handler MessageLowExecDetailEventHandler, priority ...int) {
+	slf.messageLowExecDetailEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnMessageLowExecEvent(message *Message, cost, threshold time.Duration) {
+	if slf.messageLowExecEventHandlers.Len() == 0 && slf.messageLowExecDetailEventHandlers.Len() == 0 {
 		return
 	}
 	// 慢消息不再占用消息通道
@@ -298,12 +400,16 @@ func (slf *event) OnMessageLowExecEvent(message *Message, cost time.Duration) {
 		value(slf.Server, message, cost)
 		return true
 	})
+	slf.messageLowExecDetailEventHandlers.RangeValue(func(index int, value MessageLowExecDetailEventHandler) bool {
+		value(slf.Server, message, cost, threshold)
+		return true
+	})
 }
 
 // RegConnectionOpenedAfterEvent 在连接打开事件处理完成后将立刻执行被注册的事件处理函数
 //   - 该阶段事件将会转到对应消息分流渠道中进行处理
 func (slf *event) RegConnectionOpenedAfterEvent(handler ConnectionOpenedAfterEventHandler, priority ...int) {
-	if slf.network == NetworkHttp {
+	if slf.network == NetworkHttp && len(slf.additionalListens) == 0 {
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionOpenedAfterEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
@@ -321,7 +427,7 @@ func (slf *event) OnConnectionOpenedAfterEvent(conn *Conn) {
 
 // RegConnectionWritePacketBeforeEvent 在发送数据包前将立刻执行被注册的事件处理函数
 func (slf *event) RegConnectionWritePacketBeforeEvent(handler ConnectionWritePacketBeforeEventHandler, priority ...int) {
-	if slf.network == NetworkHttp {
+	if slf.network == NetworkHttp && len(slf.additionalListens) == 0 {
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionWritePacketBeforeHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
@@ -370,6 +476,100 @@ func (slf *event) OnShuntChannelClosedEvent(name string) {
 	}, log.String("Event", "OnShuntChannelClosedEvent"))
 }
 
+// RegShuntChannelOverflowEvent 在分流通道缓冲区溢出时将立刻执行被注册的事件处理函数
+//   - 当溢出策略为 ShuntOverflowPolicyDropOldest 或 ShuntOverflowPolicyDropNew 时，dropped 为被丢弃的消息，否则为 nil
+//   - 被丢弃的消息将在事件处理函数执行完毕后被回收，不应在事件处理函数之外持有
+//   - 该事件将在推送消息的协程中同步执行，不会占用消息通道
+func (slf *event) RegShuntChannelOverflowEvent(handler ShuntChannelOverflowEventHandler, priority ...int) {
+	slf.shuntChannelOverflowEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnShuntChannelOverflowEvent(name string, policy ShuntOverflowPolicy, dropped *Message) {
+	slf.shuntChannelOverflowEventHandlers.RangeValue(func(index int, value ShuntChannelOverflowEventHandler) bool {
+		value(slf.Server, name, policy, dropped)
+		return true
+	})
+}
+
+// RegMessageMemoryOverflowEvent 在等待处理的消息估算占用的内存超出 WithMessageMemoryLimit 的限制而丢弃数据包消息时将立刻执行被注册的事件处理函数
+//   - dropped 为被丢弃的数据包消息，将在事件处理函数执行完毕后被回收，不应在事件处理函数之外持有
+//   - 该事件将在推送消息的协程中同步执行，不会占用消息通道
+func (slf *event) RegMessageMemoryOverflowEvent(handler MessageMemoryOverflowEventHandler, priority ...int) {
+	slf.messageMemoryOverflowEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnMessageMemoryOverflowEvent(dropped *Message, queuedBytes int64) {
+	slf.messageMemoryOverflowEventHandlers.RangeValue(func(index int, value MessageMemoryOverflowEventHandler) bool {
+		value(slf.Server, dropped, queuedBytes)
+		return true
+	})
+}
+
+// RegLoginConflictEvent 在不同连接通过 Server.BindIdentity 绑定相同身份时将立刻执行被注册的事件处理函数
+//   - old 为已绑定该身份的连接，new 为正在绑定该身份的连接，可在事件中进行状态迁移等处理
+//   - 当 policy 为 LoginConflictPolicyRejectNew 时 new 将绑定失败，否则 old 将在事件处理函数执行完毕后被踢出
+//   - 该事件将在调用 Server.BindIdentity 的协程中同步执行
+func (slf *event) RegLoginConflictEvent(handler LoginConflictEventHandler, priority ...int) {
+	slf.loginConflictEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnLoginConflictEvent(identity string, old, new *Conn) {
+	policy := slf.Server.runtime.loginConflictPolicy
+	slf.loginConflictEventHandlers.RangeValue(func(index int, value LoginConflictEventHandler) bool {
+		value(slf.Server, identity, old, new, policy)
+		return true
+	})
+}
+
+// RegUniqueMessageDroppedEvent 在唯一消息因同名唯一消息正在执行而被丢弃时将立刻执行被注册的事件处理函数
+//   - 当 policy 为 UniqueMessagePolicyDiscard 时，dropped 为新推送的唯一消息
+//   - 当 policy 为 UniqueMessagePolicyQueueLatest 时，dropped 为被更新的唯一消息合并的暂存消息
+//   - 被丢弃的消息将在事件处理函数执行完毕后被回收，不应在事件处理函数之外持有
+//   - 该事件将在推送消息的协程中同步执行，不会占用消息通道
+func (slf *event) RegUniqueMessageDroppedEvent(handler UniqueMessageDroppedEventHandler, priority ...int) {
+	slf.uniqueMessageDroppedEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnUniqueMessageDroppedEvent(dropped *Message, policy UniqueMessagePolicy) {
+	slf.uniqueMessageDroppedEventHandlers.RangeValue(func(index int, value UniqueMessageDroppedEventHandler) bool {
+		value(slf.Server, dropped, policy)
+		return true
+	})
+}
+
+// RegRollingShutdownEvent 在通过 Server.RollingShutdown 滚动停止服务器的过程中进入每一个阶段时将立刻执行被注册的事件处理函数
+//   - 事件处理函数将在调用 Server.RollingShutdown 的协程中同步执行，在 RollingShutdownStageDraining 阶段通知注册中心等外部组件时，函数返回前应当已完成通知
+func (slf *event) RegRollingShutdownEvent(handler RollingShutdownEventHandler, priority ...int) {
+	slf.rollingShutdownEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnRollingShutdownEvent(stage RollingShutdownStage) {
+	slf.rollingShutdownEventHandlers.RangeValue(func(index int, value RollingShutdownEventHandler) bool {
+		value(slf.Server, stage)
+		return true
+	})
+}
+
+// RegAsyncPoolSaturatedEvent 在异步消息协程池已饱和，提交异步消息将被阻塞时立刻执行被注册的事件处理函数
+//   - message 为即将提交的异步消息，stats 为提交前的运行时统计信息
+//   - 该事件将在提交异步消息的消息分发器协程中同步执行，事件处理函数不应执行耗时操作
+func (slf *event) RegAsyncPoolSaturatedEvent(handler AsyncPoolSaturatedEventHandler, priority ...int) {
+	slf.asyncPoolSaturatedEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnAsyncPoolSaturatedEvent(message *Message, stats RuntimeStats) {
+	slf.asyncPoolSaturatedEventHandlers.RangeValue(func(index int, value AsyncPoolSaturatedEventHandler) bool {
+		value(slf.Server, message, stats)
+		return true
+	})
+}
+
 // RegConnectionPacketPreprocessEvent 在接收到数据包后将立刻执行被注册的事件处理函数
 //   - 预处理函数可以用于对数据包进行预处理，如解密、解压缩等
 //   - 在调用 abort() 后，将不会再调用后续的预处理函数，也不会调用 OnConnectionReceivePacketEvent 函数
@@ -413,9 +613,8 @@ func (slf *event) OnMessageExecBeforeEvent(message *Message) bool {
 	}
 	var result = true
 	defer func() {
-		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnMessageExecBeforeEvent", fmt.Sprintf("%v", err)))
-			debug.PrintStack()
+		if err := super.RecoverPanic(recover()); err != nil {
+			log.Error("Server", log.String("OnMessageExecBeforeEvent", err.Error()), log.String("stack", string(err.Stack)))
 		}
 	}()
 	slf.messageExecBeforeEventHandlers.RangeValue(func(index int, value MessageExecBeforeEventHandler) bool {
@@ -435,9 +634,8 @@ func (slf *event) OnMessageReadyEvent() {
 		return
 	}
 	defer func() {
-		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnMessageReadyEvent", fmt.Sprintf("%v", err)))
-			debug.PrintStack()
+		if err := super.RecoverPanic(recover()); err != nil {
+			log.Error("Server", log.String("OnMessageReadyEvent", err.Error()), log.String("stack", string(err.Stack)))
 		}
 	}()
 	slf.messageReadyEventHandlers.RangeValue(func(index int, value MessageReadyEventHandler) bool {
@@ -447,22 +645,22 @@ func (slf *event) OnMessageReadyEvent() {
 }
 
 // RegDeadlockDetectEvent 在死锁检测触发时立即执行被注册的事件处理函数
+//   - report 中包含消息处理函数的定义位置，通过 WithDeadlockGoroutineDump 开启后还将包含所有协程的堆栈
 func (slf *event) RegDeadlockDetectEvent(handler OnDeadlockDetectEventHandler, priority ...int) {
 	slf.deadlockDetectEventHandlers.Append(handler, collection.FindFirstOrDefaultInSlice(priority, 0))
 }
 
-func (slf *event) OnDeadlockDetectEvent(message *Message) {
+func (slf *event) OnDeadlockDetectEvent(message *Message, report *DeadlockReport) {
 	if slf.deadlockDetectEventHandlers.Len() == 0 {
 		return
 	}
 	defer func() {
-		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnDeadlockDetectEvent", fmt.Sprintf("%v", err)))
-			debug.PrintStack()
+		if err := super.RecoverPanic(recover()); err != nil {
+			log.Error("Server", log.String("OnDeadlockDetectEvent", err.Error()), log.String("stack", string(err.Stack)))
 		}
 	}()
 	slf.deadlockDetectEventHandlers.RangeValue(func(index int, value OnDeadlockDetectEventHandler) bool {
-		value(slf.Server, message)
+		value(slf.Server, message, report)
 		return true
 	})
 }
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"github.com/alphadose/haxmap"
+	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/server/client"
 	"github.com/kercylan98/minotaur/utils/log"
@@ -16,6 +17,7 @@ func NewEndpoint(name string, cli *client.Client, options ...EndpointOption) *En
 		name:        name,
 		address:     cli.GetServerAddr(),
 		connections: haxmap.New[string, *server.Conn](),
+		opened:      haxmap.New[string, struct{}](),
 		rci:         DefaultEndpointReconnectInterval,
 		cps:         DefaultEndpointConnectionPoolSize,
 	}
@@ -38,6 +40,9 @@ func NewEndpoint(name string, cli *client.Client, options ...EndpointOption) *En
 //   - 每个端点会建立一个连接池，默认大小为 DefaultEndpointConnectionPoolSize，可通过 WithEndpointConnectionPoolSize 进行设置。
 //   - 网关在转发数据包时会自行根据延迟维护端点健康值，端点健康值越高，网关越倾向于将数据包转发到该端点。
 //   - 端点支持连接未中断前始终将数据包转发到特定端点，这样可以保证连接的状态维持。
+//   - 端点支持通过 WithEndpointCompression 对转发的数据包进行压缩，以降低网关与端点之间的带宽占用。
+//   - 端点支持通过 WithEndpointBatch 将短时间内转发的多个数据包合并发送，以降低高并发下的系统调用次数及单个数据包的开销。
+//   - 当网关通过 WithConnectionMetadata 开启了连接元数据传递时，端点会在客户端连接首次转发数据包前发送连接打开控制消息，并在客户端连接关闭时发送连接关闭控制消息。
 //
 // 连接池：
 //   - 连接池大小决定了网关服务器与端点服务器建立的连接数，例如当连接池大小为 1 时，那么所有连接到该端点的客户端都会共用一个连接。
@@ -50,8 +55,14 @@ type Endpoint struct {
 	state       atomic.Float64                     // 端点健康值（0为不可用，越高越优）
 	evaluator   func(costUnixNano float64) float64 // 端点健康值评估函数
 	connections *haxmap.Map[string, *server.Conn]  // 被该端点转发的连接列表
+	opened      *haxmap.Map[string, struct{}]      // 已发送连接打开控制消息的连接列表
 	rci         time.Duration                      // 端点重连间隔
 	cps         int                                // 端点连接池大小
+
+	compression          bool // 是否启用网关压缩数据包
+	compressionThreshold int  // 网关压缩数据包的最小压缩大小
+
+	batcher *endpointBatcher // 数据包合并器
 }
 
 // start 开始与目标服务端点建立连接
@@ -84,22 +95,25 @@ func (slf *Endpoint) connect(gateway *Gateway) {
 			})
 			cli.RegConnectionClosedEvent(func(conn *client.Client, err any) {
 				slf.gateway.OnEndpointConnectClosedEvent(slf.gateway, slf)
+				if !slf.isConnected() {
+					// 连接池中的连接全部关闭时，绑定到该端点的路由键将被解除，以便重新选择可用的端点
+					slf.gateway.unbindRoutingEndpoint(slf)
+				}
 				slf.start(cli)
 			})
 			cli.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
-				addr, sendTime, packet, err := UnmarshalGatewayInPacket(packet)
+				if !IsGatewayCompressedPacket(packet) {
+					slf.receive(wst, packet)
+					return
+				}
+				packets, err := UnmarshalGatewayCompressedPacket(packet)
 				if err != nil {
 					log.Error("Endpoint", log.String("Action", "ReceivePacket"), log.String("Name", slf.name), log.String("Addr", slf.address), log.Err(err))
 					return
 				}
-				slf.state.Swap(slf.evaluator(float64(time.Now().UnixNano() - sendTime)))
-				c, ok := slf.connections.Get(addr)
-				if !ok {
-					log.Error("Endpoint", log.String("Action", "ReceivePacket"), log.String("Name", slf.name), log.String("Addr", slf.address), log.String("ConnAddr", addr), log.Err(ErrConnectionNotFount))
-					return
+				for _, packet := range packets {
+					slf.receive(wst, packet)
 				}
-				c.SetWST(wst)
-				slf.gateway.OnEndpointConnectReceivePacketEvent(slf.gateway, slf, c, packet)
 			})
 			slf.start(cli)
 			leastOnce.Do(least.Done)
@@ -108,6 +122,33 @@ func (slf *Endpoint) connect(gateway *Gateway) {
 	least.Wait()
 }
 
+// receive 处理来自端点服务器的网关入网数据包
+func (slf *Endpoint) receive(wst int, packet []byte) {
+	addr, sendTime, packet, err := UnmarshalGatewayInPacket(packet)
+	if err != nil {
+		log.Error("Endpoint", log.String("Action", "ReceivePacket"), log.String("Name", slf.name), log.String("Addr", slf.address), log.Err(err))
+		return
+	}
+	slf.state.Swap(slf.evaluator(float64(time.Now().UnixNano() - sendTime)))
+	c, ok := slf.connections.Get(addr)
+	if !ok {
+		log.Error("Endpoint", log.String("Action", "ReceivePacket"), log.String("Name", slf.name), log.String("Addr", slf.address), log.String("ConnAddr", addr), log.Err(ErrConnectionNotFount))
+		return
+	}
+	c.SetWST(wst)
+	slf.gateway.OnEndpointConnectReceivePacketEvent(slf.gateway, slf, c, packet)
+}
+
+// isConnected 检查端点的连接池中是否存在已连接的连接
+func (slf *Endpoint) isConnected() bool {
+	for _, cli := range slf.client {
+		if cli.IsConnected() {
+			return true
+		}
+	}
+	return false
+}
+
 // GetName 获取端点名称
 func (slf *Endpoint) GetName() string {
 	return slf.name
@@ -135,14 +176,6 @@ func (slf *Endpoint) Forward(conn *server.Conn, packet []byte, callback ...func(
 		return
 	}
 
-	var superior *client.Client
-	for _, cli := range slf.client {
-		if cli.IsConnected() {
-			superior = cli
-			break
-		}
-	}
-
 	var cb = func(err error) {
 		if len(callback) > 0 {
 			callback[0](err)
@@ -157,9 +190,97 @@ func (slf *Endpoint) Forward(conn *server.Conn, packet []byte, callback ...func(
 		}
 	}
 
+	var opened []byte
+	if slf.gateway.cme {
+		if _, loaded := slf.opened.GetOrSet(conn.GetID(), struct{}{}); !loaded {
+			if opened, err = MarshalGatewayConnectionOpenedPacket(newConnectionMetadata(conn, slf.gateway.cmp)); err != nil {
+				slf.opened.Del(conn.GetID())
+				if len(callback) > 0 {
+					callback[0](err)
+				}
+				return
+			}
+		}
+	}
+	if opened != nil {
+		slf.control(opened, func(err error) {
+			if err != nil {
+				slf.opened.Del(conn.GetID())
+			}
+		})
+	}
+
+	if slf.batcher != nil {
+		slf.batcher.add(packet, cb)
+		return
+	}
+	var wst int
 	if conn.IsWebsocket() {
-		superior.WriteWS(conn.GetWST(), packet, cb)
+		wst = conn.GetWST()
+	}
+	slf.write(wst, [][]byte{packet}, []func(err error){cb})
+}
+
+// control 将网关控制数据包写入到端点，控制数据包与普通数据包保持相同的写入顺序
+func (slf *Endpoint) control(packet []byte, callback func(err error)) {
+	if slf.batcher != nil {
+		slf.batcher.add(packet, callback)
+		return
+	}
+	slf.write(websocket.BinaryMessage, [][]byte{packet}, []func(err error){callback})
+}
+
+// closeConnection 当客户端连接曾转发到该端点时，向端点发送连接关闭控制消息
+func (slf *Endpoint) closeConnection(conn *server.Conn) {
+	if _, exist := slf.opened.GetAndDel(conn.GetID()); !exist {
+		return
+	}
+	packet, err := MarshalGatewayConnectionClosedPacket(conn.GetID())
+	if err != nil {
+		log.Error("Endpoint", log.String("Action", "CloseConnection"), log.String("Name", slf.name), log.String("Addr", slf.address), log.String("ConnAddr", conn.GetID()), log.Err(err))
+		return
+	}
+	slf.control(packet, nil)
+}
+
+// write 将一个或多个网关出网数据包写入到端点，多个数据包将被合并为网关批量数据包
+//   - 当启用了压缩且数据包大小达到压缩阈值时，将被转换为网关压缩数据包
+//   - 网关批量数据包及网关压缩数据包将始终以 websocket.BinaryMessage 的形式写入
+func (slf *Endpoint) write(wst int, packets [][]byte, callbacks []func(err error)) {
+	var cb = func(err error) {
+		for _, callback := range callbacks {
+			if callback != nil {
+				callback(err)
+			}
+		}
+	}
+
+	var data []byte
+	if len(packets) == 1 {
+		data = packets[0]
 	} else {
-		superior.Write(packet, cb)
+		data = MarshalGatewayBatchPacket(packets...)
+		wst = websocket.BinaryMessage
+	}
+	if slf.compression && len(data) >= slf.compressionThreshold {
+		var err error
+		if data, err = MarshalGatewayCompressedPacket(packets...); err != nil {
+			cb(err)
+			return
+		}
+		wst = websocket.BinaryMessage
+	}
+
+	var superior *client.Client
+	for _, cli := range slf.client {
+		if cli.IsConnected() {
+			superior = cli
+			break
+		}
+	}
+	if superior == nil {
+		cb(ErrEndpointNotConnected)
+		return
 	}
+	superior.WriteWS(wst, data, cb)
 }
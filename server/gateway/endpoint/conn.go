@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/gateway"
+	"time"
+)
+
+// Conn 客户端连接，无论客户端是否通过网关连接到端点服务器，均可通过该结构进行相同的处理
+type Conn struct {
+	id       string                      // 客户端连接 ID，通过网关连接时为客户端在网关上的地址
+	link     *server.Conn                // 承载该客户端连接的连接，直连时为客户端连接本身，通过网关连接时为网关连接
+	metadata *gateway.ConnectionMetadata // 网关传递的客户端连接元数据
+}
+
+// GetID 获取客户端连接 ID
+func (slf *Conn) GetID() string {
+	return slf.id
+}
+
+// GetIP 获取客户端 IP
+//   - 当通过网关连接且网关未开启连接元数据传递时，将返回网关的 IP
+func (slf *Conn) GetIP() string {
+	if slf.metadata != nil {
+		return slf.metadata.IP
+	}
+	return slf.link.GetIP()
+}
+
+// GetMetadata 获取网关传递的客户端连接元数据，当客户端直连或网关未开启连接元数据传递时将返回 nil
+func (slf *Conn) GetMetadata() *gateway.ConnectionMetadata {
+	return slf.metadata
+}
+
+// IsGateway 检查客户端是否通过网关连接
+func (slf *Conn) IsGateway() bool {
+	return slf.link.GetID() != slf.id
+}
+
+// Link 获取承载该客户端连接的连接
+func (slf *Conn) Link() *server.Conn {
+	return slf.link
+}
+
+// Write 向客户端写入数据包，当客户端通过网关连接时，数据包将被转换为网关入网数据包后通过网关转发
+func (slf *Conn) Write(packet []byte, callback ...func(err error)) {
+	if !slf.IsGateway() {
+		slf.link.Write(packet, callback...)
+		return
+	}
+	packet, err := gateway.MarshalGatewayInPacket(slf.id, time.Now().Unix(), packet)
+	if err != nil {
+		if len(callback) > 0 {
+			callback[0](err)
+		}
+		return
+	}
+	slf.link.Write(packet, callback...)
+}
@@ -0,0 +1,220 @@
+package endpoint
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/gateway"
+	"github.com/kercylan98/minotaur/utils/collection"
+	"github.com/kercylan98/minotaur/utils/collection/listings"
+	"github.com/kercylan98/minotaur/utils/log"
+	"math"
+	"sync"
+)
+
+type (
+	ConnectionOpenedEventHandle        func(endpoint *Endpoint, conn *Conn)
+	ConnectionClosedEventHandle        func(endpoint *Endpoint, conn *Conn)
+	ConnectionReceivePacketEventHandle func(endpoint *Endpoint, conn *Conn, packet []byte)
+)
+
+// New 基于 server.Server 创建端点服务器辅助工具
+//   - 端点服务器辅助工具会将网关控制数据包、网关出网数据包及直连客户端的数据包统一转换为以客户端连接 ID 为标识的连接事件，使得端点服务器无论是否通过网关均可使用相同的逻辑
+//   - 需要注意的是，客户端连接打开事件将在首次接收到该客户端的数据包或网关连接打开控制消息时触发
+//   - 仅来自通过 WithGatewayVerifier 或 WithTrustedGatewayIP 指定的受信任网关连接的数据包会被作为网关数据包解析，未指定时所有连接均被视为直连客户端
+func New(srv *server.Server, options ...Option) *Endpoint {
+	endpoint := &Endpoint{
+		srv:                                 srv,
+		conns:                               make(map[string]*Conn),
+		links:                               make(map[string]map[string]*Conn),
+		connectionOpenedEventHandles:        listings.NewPrioritySlice[ConnectionOpenedEventHandle](),
+		connectionClosedEventHandles:        listings.NewPrioritySlice[ConnectionClosedEventHandle](),
+		connectionReceivePacketEventHandles: listings.NewPrioritySlice[ConnectionReceivePacketEventHandle](),
+	}
+	for _, option := range options {
+		option(endpoint)
+	}
+	srv.RegConnectionPacketPreprocessEvent(endpoint.onPacketPreprocess, math.MinInt)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		endpoint.OnConnectionReceivePacketEvent(endpoint.open(conn, conn.GetID(), nil), packet)
+	}, math.MinInt)
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		endpoint.closeLink(conn)
+	}, math.MinInt)
+	return endpoint
+}
+
+// Endpoint 端点服务器辅助工具
+type Endpoint struct {
+	srv   *server.Server
+	conns map[string]*Conn            // 客户端连接 [conn.ID]
+	links map[string]map[string]*Conn // 承载连接所承载的客户端连接 [link.ID][conn.ID]
+	lock  sync.RWMutex
+
+	verifiers []GatewayVerifier // 受信任网关连接的校验函数
+
+	connectionOpenedEventHandles        *listings.PrioritySlice[ConnectionOpenedEventHandle]
+	connectionClosedEventHandles        *listings.PrioritySlice[ConnectionClosedEventHandle]
+	connectionReceivePacketEventHandles *listings.PrioritySlice[ConnectionReceivePacketEventHandle]
+}
+
+// Server 获取端点服务器核心
+func (slf *Endpoint) Server() *server.Server {
+	return slf.srv
+}
+
+// GetConn 获取特定 ID 的客户端连接，当客户端连接不存在时将返回 nil
+func (slf *Endpoint) GetConn(id string) *Conn {
+	slf.lock.RLock()
+	defer slf.lock.RUnlock()
+	return slf.conns[id]
+}
+
+// isTrustedGateway 检查承载连接是否为受信任的网关连接
+func (slf *Endpoint) isTrustedGateway(link *server.Conn) bool {
+	for _, verifier := range slf.verifiers {
+		if verifier(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// onPacketPreprocess 拆分网关数据包，并将其转换为客户端连接事件，非网关数据包将交由直连客户端逻辑处理
+//   - 来自未受信任连接的数据包即便符合网关数据包格式，也将作为直连客户端的普通数据包处理
+func (slf *Endpoint) onPacketPreprocess(srv *server.Server, link *server.Conn, packet []byte, abort func(), usePacket func(newPacket []byte)) {
+	if !slf.isTrustedGateway(link) {
+		return
+	}
+	packets, err := gateway.UnpackGatewayPackets(packet)
+	if err != nil {
+		log.Error("Endpoint", log.String("Action", "UnpackGatewayPackets"), log.String("Link", link.GetID()), log.Err(err))
+		abort()
+		return
+	}
+	var handled bool
+	for _, packet := range packets {
+		switch {
+		case gateway.IsGatewayConnectionOpenedPacket(packet):
+			metadata, err := gateway.UnmarshalGatewayConnectionOpenedPacket(packet)
+			if err != nil {
+				log.Error("Endpoint", log.String("Action", "ConnectionOpened"), log.String("Link", link.GetID()), log.Err(err))
+				continue
+			}
+			slf.open(link, metadata.Addr, metadata)
+		case gateway.IsGatewayConnectionClosedPacket(packet):
+			addr, err := gateway.UnmarshalGatewayConnectionClosedPacket(packet)
+			if err != nil {
+				log.Error("Endpoint", log.String("Action", "ConnectionClosed"), log.String("Link", link.GetID()), log.Err(err))
+				continue
+			}
+			slf.close(addr)
+		default:
+			addr, packet, err := gateway.UnmarshalGatewayOutPacket(packet)
+			if err != nil {
+				if len(packets) == 1 {
+					// 非网关的普通数据包
+					return
+				}
+				log.Error("Endpoint", log.String("Action", "ReceivePacket"), log.String("Link", link.GetID()), log.Err(err))
+				continue
+			}
+			slf.OnConnectionReceivePacketEvent(slf.open(link, addr, nil), packet)
+		}
+		handled = true
+	}
+	if handled || len(packets) > 1 {
+		abort()
+	}
+}
+
+// open 获取客户端连接，当客户端连接不存在时将创建并触发连接打开事件
+func (slf *Endpoint) open(link *server.Conn, id string, metadata *gateway.ConnectionMetadata) *Conn {
+	slf.lock.Lock()
+	conn, exist := slf.conns[id]
+	if exist {
+		if metadata != nil && conn.link.GetID() == link.GetID() {
+			// 仅承载该客户端连接的网关可以更新其元数据
+			conn.metadata = metadata
+		}
+		slf.lock.Unlock()
+		return conn
+	}
+	conn = &Conn{id: id, link: link, metadata: metadata}
+	slf.conns[id] = conn
+	conns, exist := slf.links[link.GetID()]
+	if !exist {
+		conns = make(map[string]*Conn)
+		slf.links[link.GetID()] = conns
+	}
+	conns[id] = conn
+	slf.lock.Unlock()
+	slf.OnConnectionOpenedEvent(conn)
+	return conn
+}
+
+// close 关闭客户端连接并触发连接关闭事件
+func (slf *Endpoint) close(id string) {
+	slf.lock.Lock()
+	conn, exist := slf.conns[id]
+	if !exist {
+		slf.lock.Unlock()
+		return
+	}
+	delete(slf.conns, id)
+	if conns := slf.links[conn.link.GetID()]; conns != nil {
+		delete(conns, id)
+		if len(conns) == 0 {
+			delete(slf.links, conn.link.GetID())
+		}
+	}
+	slf.lock.Unlock()
+	slf.OnConnectionClosedEvent(conn)
+}
+
+// closeLink 关闭承载连接所承载的所有客户端连接，例如网关连接断开时
+func (slf *Endpoint) closeLink(link *server.Conn) {
+	slf.lock.RLock()
+	var ids = make([]string, 0, len(slf.links[link.GetID()]))
+	for id := range slf.links[link.GetID()] {
+		ids = append(ids, id)
+	}
+	slf.lock.RUnlock()
+	for _, id := range ids {
+		slf.close(id)
+	}
+}
+
+// RegConnectionOpenedEventHandle 注册客户端连接打开事件处理函数
+func (slf *Endpoint) RegConnectionOpenedEventHandle(handle ConnectionOpenedEventHandle, priority ...int) {
+	slf.connectionOpenedEventHandles.Append(handle, collection.FindFirstOrDefaultInSlice(priority, 0))
+}
+
+func (slf *Endpoint) OnConnectionOpenedEvent(conn *Conn) {
+	slf.connectionOpenedEventHandles.RangeValue(func(index int, value ConnectionOpenedEventHandle) bool {
+		value(slf, conn)
+		return true
+	})
+}
+
+// RegConnectionClosedEventHandle 注册客户端连接关闭事件处理函数
+func (slf *Endpoint) RegConnectionClosedEventHandle(handle ConnectionClosedEventHandle, priority ...int) {
+	slf.connectionClosedEventHandles.Append(handle, collection.FindFirstOrDefaultInSlice(priority, 0))
+}
+
+func (slf *Endpoint) OnConnectionClosedEvent(conn *Conn) {
+	slf.connectionClosedEventHandles.RangeValue(func(index int, value ConnectionClosedEventHandle) bool {
+		value(slf, conn)
+		return true
+	})
+}
+
+// RegConnectionReceivePacketEventHandle 注册客户端连接接收数据包事件处理函数
+func (slf *Endpoint) RegConnectionReceivePacketEventHandle(handle ConnectionReceivePacketEventHandle, priority ...int) {
+	slf.connectionReceivePacketEventHandles.Append(handle, collection.FindFirstOrDefaultInSlice(priority, 0))
+}
+
+func (slf *Endpoint) OnConnectionReceivePacketEvent(conn *Conn, packet []byte) {
+	slf.connectionReceivePacketEventHandles.RangeValue(func(index int, value ConnectionReceivePacketEventHandle) bool {
+		value(slf, conn, packet)
+		return true
+	})
+}
@@ -0,0 +1,86 @@
+package endpoint_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/gateway"
+	"github.com/kercylan98/minotaur/server/gateway/endpoint"
+	"github.com/kercylan98/minotaur/utils/random"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	var events []string
+	srv := server.New(server.NetworkTcp)
+	ep := endpoint.New(srv, endpoint.WithGatewayVerifier(func(link *server.Conn) bool {
+		return link.IsBot()
+	}))
+	ep.RegConnectionOpenedEventHandle(func(endpoint *endpoint.Endpoint, conn *endpoint.Conn) {
+		events = append(events, fmt.Sprintf("opened %s %s %v", conn.GetID(), conn.GetIP(), conn.IsGateway()))
+	})
+	ep.RegConnectionReceivePacketEventHandle(func(endpoint *endpoint.Endpoint, conn *endpoint.Conn, packet []byte) {
+		events = append(events, fmt.Sprintf("receive %s %s", conn.GetID(), packet))
+	})
+	ep.RegConnectionClosedEventHandle(func(endpoint *endpoint.Endpoint, conn *endpoint.Conn) {
+		events = append(events, fmt.Sprintf("closed %s", conn.GetID()))
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bot := server.NewBot(srv)
+		bot.JoinServer()
+		opened, _ := gateway.MarshalGatewayConnectionOpenedPacket(&gateway.ConnectionMetadata{Addr: "127.0.0.1:1024", IP: "127.0.0.2"})
+		packet, _ := gateway.MarshalGatewayOutPacket("127.0.0.1:1024", []byte("hello"))
+		closed, _ := gateway.MarshalGatewayConnectionClosedPacket("127.0.0.1:1024")
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			bot.SendPacket(gateway.MarshalGatewayBatchPacket(opened, packet))
+			bot.SendPacket(closed)
+		}()
+	})
+	go func() {
+		time.Sleep(time.Second * 3)
+		srv.Shutdown()
+	}()
+	if err := srv.Run(fmt.Sprintf(":%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"opened 127.0.0.1:1024 127.0.0.2 true", "receive 127.0.0.1:1024 hello", "closed 127.0.0.1:1024"}
+	if fmt.Sprint(events) != fmt.Sprint(expect) {
+		t.Fatalf("events: %v, expect: %v", events, expect)
+	}
+}
+
+func TestNew_UntrustedLink(t *testing.T) {
+	var events []string
+	srv := server.New(server.NetworkTcp)
+	ep := endpoint.New(srv, endpoint.WithTrustedGatewayIP("10.0.0.1"))
+	ep.RegConnectionOpenedEventHandle(func(endpoint *endpoint.Endpoint, conn *endpoint.Conn) {
+		events = append(events, fmt.Sprintf("opened %v %v", conn.IsGateway(), conn.GetMetadata() != nil))
+	})
+	ep.RegConnectionReceivePacketEventHandle(func(endpoint *endpoint.Endpoint, conn *endpoint.Conn, packet []byte) {
+		events = append(events, fmt.Sprintf("receive %v", gateway.IsGatewayConnectionOpenedPacket(packet)))
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bot := server.NewBot(srv)
+		bot.JoinServer()
+		// 直连客户端伪造的网关连接打开控制数据包不应被解析为其他客户端的元数据
+		opened, _ := gateway.MarshalGatewayConnectionOpenedPacket(&gateway.ConnectionMetadata{Addr: "127.0.0.1:1024", IP: "127.0.0.2", Identity: "admin"})
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			bot.SendPacket(opened)
+		}()
+	})
+	go func() {
+		time.Sleep(time.Second * 3)
+		srv.Shutdown()
+	}()
+	if err := srv.Run(fmt.Sprintf(":%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"opened false false", "receive true"}
+	if fmt.Sprint(events) != fmt.Sprint(expect) {
+		t.Fatalf("events: %v, expect: %v", events, expect)
+	}
+}
@@ -0,0 +1,34 @@
+package endpoint
+
+import (
+	"github.com/kercylan98/minotaur/server"
+)
+
+type (
+	// Option 端点服务器辅助工具选项
+	Option func(endpoint *Endpoint)
+
+	// GatewayVerifier 检查承载连接是否为受信任的网关连接
+	GatewayVerifier func(link *server.Conn) bool
+)
+
+// WithGatewayVerifier 设置受信任网关连接的校验函数，仅来自受信任网关连接的数据包会被作为网关数据包解析
+//   - 网关控制数据包携带了客户端的 IP、认证身份等元数据，来自未受信任连接的网关数据包将作为直连客户端的普通数据包处理，以避免客户端伪造其他客户端的身份
+//   - 可多次设置，任一校验函数返回 true 时即视为受信任
+func WithGatewayVerifier(verifier GatewayVerifier) Option {
+	return func(endpoint *Endpoint) {
+		endpoint.verifiers = append(endpoint.verifiers, verifier)
+	}
+}
+
+// WithTrustedGatewayIP 将来自特定 IP 的连接视为受信任的网关连接，效果同 WithGatewayVerifier
+func WithTrustedGatewayIP(ips ...string) Option {
+	var trusted = make(map[string]struct{}, len(ips))
+	for _, ip := range ips {
+		trusted[ip] = struct{}{}
+	}
+	return WithGatewayVerifier(func(link *server.Conn) bool {
+		_, exist := trusted[link.GetIP()]
+		return exist
+	})
+}
@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+)
+
+// endpointBatcher 端点数据包合并器，将一段时间窗口内转发到同一端点的多个数据包合并为一个网关批量数据包
+type endpointBatcher struct {
+	endpoint  *Endpoint
+	window    time.Duration     // 合并时间窗口
+	maxSize   int               // 合并的最大字节数，超出后将立即发送
+	packets   [][]byte          // 等待发送的数据包
+	callbacks []func(err error) // 等待发送的数据包回调
+	size      int               // 等待发送的数据包总字节数
+	timer     *time.Timer       // 时间窗口定时器
+	lock      sync.Mutex
+}
+
+// add 添加一个等待合并发送的数据包
+func (slf *endpointBatcher) add(packet []byte, callback func(err error)) {
+	slf.lock.Lock()
+	slf.packets = append(slf.packets, packet)
+	slf.callbacks = append(slf.callbacks, callback)
+	slf.size += len(packet)
+	if slf.maxSize > 0 && slf.size >= slf.maxSize {
+		packets, callbacks := slf.take()
+		slf.lock.Unlock()
+		slf.endpoint.write(0, packets, callbacks)
+		return
+	}
+	if len(slf.packets) == 1 {
+		slf.timer = time.AfterFunc(slf.window, slf.flush)
+	}
+	slf.lock.Unlock()
+}
+
+// flush 立即发送所有等待合并发送的数据包
+func (slf *endpointBatcher) flush() {
+	slf.lock.Lock()
+	packets, callbacks := slf.take()
+	slf.lock.Unlock()
+	if len(packets) > 0 {
+		slf.endpoint.write(0, packets, callbacks)
+	}
+}
+
+// take 取出所有等待合并发送的数据包，调用方需持有锁
+func (slf *endpointBatcher) take() (packets [][]byte, callbacks []func(err error)) {
+	if slf.timer != nil {
+		slf.timer.Stop()
+		slf.timer = nil
+	}
+	packets, callbacks = slf.packets, slf.callbacks
+	slf.packets, slf.callbacks, slf.size = nil, nil, 0
+	return
+}
@@ -37,3 +37,29 @@ func WithEndpointReconnectInterval(interval time.Duration) EndpointOption {
 		endpoint.rci = interval
 	}
 }
+
+// WithEndpointCompression 设置端点转发数据包时使用 zstd 压缩
+//   - threshold 为最小压缩大小，当转发的数据包小于该值时不会进行压缩，如果 <= 0 则所有数据包都会被压缩
+//   - 启用后端点服务器需要通过 IsGatewayCompressedPacket 及 UnmarshalGatewayCompressedPacket 对数据包进行解析
+//   - 端点服务器也可以通过 MarshalGatewayCompressedPacket 对回复给网关的数据包进行压缩，网关将自动识别并解析
+func WithEndpointCompression(threshold int) EndpointOption {
+	return func(endpoint *Endpoint) {
+		endpoint.compression = true
+		endpoint.compressionThreshold = threshold
+	}
+}
+
+// WithEndpointBatch 设置端点转发数据包时将时间窗口内的多个数据包合并为一个网关批量数据包发送
+//   - window 为合并时间窗口，第一个数据包进入等待后，将在该时间窗口到达时发送所有等待的数据包
+//   - maxSize 为合并的最大字节数，当等待的数据包总字节数达到该值时将立即发送，如果 <= 0 则仅根据时间窗口发送
+//   - 启用后端点服务器需要通过 UnpackGatewayPackets 对数据包进行拆分
+//   - 当同时启用 WithEndpointCompression 时，合并后的数据包将会被压缩
+func WithEndpointBatch(window time.Duration, maxSize int) EndpointOption {
+	return func(endpoint *Endpoint) {
+		endpoint.batcher = &endpointBatcher{
+			endpoint: endpoint,
+			window:   window,
+			maxSize:  maxSize,
+		}
+	}
+}
@@ -11,4 +11,6 @@ var (
 	ErrGatewayRunning = errors.New("gateway: gateway running")
 	// ErrConnectionNotFount 该端点下不存在该连接
 	ErrConnectionNotFount = errors.New("gateway: connection not found")
+	// ErrEndpointNotConnected 端点不存在任何可用的连接
+	ErrEndpointNotConnected = errors.New("gateway: endpoint not connected")
 )
@@ -24,6 +24,8 @@ func NewGateway(srv *server.Server, scanner Scanner, options ...Option) *Gateway
 			return endpoints[random.Int(0, len(endpoints)-1)]
 		},
 		cce: make(map[string]*Endpoint),
+		rk:  make(map[string]map[string]*routingBinding),
+		rkc: make(map[string]map[routingKeyRef]struct{}),
 	}
 	for _, option := range options {
 		option(gateway)
@@ -40,18 +42,26 @@ func NewGateway(srv *server.Server, scanner Scanner, options ...Option) *Gateway
 //   - 支持 server.Server 所支持的所有 Socket 网络类型
 //   - 支持将客户端网络类型进行不同的转换，例如：客户端使用 Websocket 连接，但是网关服务器可以将其转换为 TCP 端点的连接
 //   - 支持客户端消息绑定，在客户端未断开连接的情况下，可以将客户端的连接绑定到某个端点，这样该客户端的所有消息都会转发到该端点
+//   - 支持路由键亲和，例如将同一房间的所有流量汇聚到同一个端点，无论由哪个客户端发送
+//   - 支持将客户端 IP、连接时间、TLS 状态及认证身份等连接元数据传递到端点
 //   - 根据端点延迟实时调整端点状态评分，根据评分选择最优的端点，默认评分算法为：1 / (1 + 1.5 * ${DelaySeconds})
 type Gateway struct {
 	*events
-	srv     *server.Server                  // 网关服务器核心
-	scanner Scanner                         // 端点扫描器
-	es      map[string]map[string]*Endpoint // 端点列表 [name][address]
-	esm     sync.Mutex                      // 端点列表锁
-	ess     EndpointSelector                // 端点选择器
-	closed  bool                            // 网关是否已关闭
-	running bool                            // 网关是否正在运行
-	cce     map[string]*Endpoint            // 连接当前连接的端点 [conn.ID]
-	cceLock sync.RWMutex                    // 连接当前连接的端点锁
+	srv     *server.Server                        // 网关服务器核心
+	scanner Scanner                               // 端点扫描器
+	es      map[string]map[string]*Endpoint       // 端点列表 [name][address]
+	esm     sync.Mutex                            // 端点列表锁
+	ess     EndpointSelector                      // 端点选择器
+	closed  bool                                  // 网关是否已关闭
+	running bool                                  // 网关是否正在运行
+	cce     map[string]*Endpoint                  // 连接当前连接的端点 [conn.ID]
+	cceLock sync.RWMutex                          // 连接当前连接的端点锁
+	rke     RoutingKeyExtractor                   // 路由键提取器
+	rk      map[string]map[string]*routingBinding // 路由键的绑定 [name][key]
+	rkc     map[string]map[routingKeyRef]struct{} // 连接使用过的路由键 [conn.ID]
+	rkLock  sync.RWMutex                          // 路由键绑定的端点锁
+	cme     bool                                  // 是否传递客户端连接元数据
+	cmp     ConnectionMetadataProvider            // 客户端连接元数据提供函数
 }
 
 // Run 运行网关
@@ -95,6 +105,7 @@ func (slf *Gateway) Run(addr string) error {
 		slf.OnConnectionOpenedEvent(slf, conn)
 	}, math.MinInt)
 	slf.srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		slf.onConnectionClosed(conn)
 		slf.OnConnectionClosedEvent(slf, conn)
 	}, math.MinInt)
 	slf.srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
@@ -108,6 +119,23 @@ func (slf *Gateway) Run(addr string) error {
 	return nil
 }
 
+// onConnectionClosed 在客户端连接关闭时释放其在网关中的状态
+func (slf *Gateway) onConnectionClosed(conn *server.Conn) {
+	if slf.cme {
+		slf.esm.Lock()
+		for _, endpoints := range slf.es {
+			for _, endpoint := range endpoints {
+				endpoint.closeConnection(conn)
+			}
+		}
+		slf.esm.Unlock()
+	}
+	slf.cceLock.Lock()
+	delete(slf.cce, conn.GetID())
+	slf.cceLock.Unlock()
+	slf.unbindRoutingConn(conn)
+}
+
 // Shutdown 关闭网关
 func (slf *Gateway) Shutdown() {
 	if !slf.closed {
@@ -165,6 +193,7 @@ func (slf *Gateway) GetConnEndpoint(name string, conn *server.Conn) (*Endpoint,
 }
 
 // SwitchEndpoint 将端点端点的所有连接切换到另一个端点
+//   - 绑定到源端点的路由键也将一并切换到目标端点
 func (slf *Gateway) SwitchEndpoint(source, dest *Endpoint) {
 	if source.name == dest.name && source.address == dest.address || source.GetState() <= 0 || dest.GetState() <= 0 {
 		return
@@ -176,4 +205,11 @@ func (slf *Gateway) SwitchEndpoint(source, dest *Endpoint) {
 		}
 	}
 	slf.cceLock.Unlock()
+	slf.rkLock.Lock()
+	for _, binding := range slf.rk[source.name] {
+		if binding.endpoint == source {
+			binding.endpoint = dest
+		}
+	}
+	slf.rkLock.Unlock()
 }
@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/super"
+	"net"
+)
+
+var (
+	connectionOpenedPacketIdentifier = []byte{0xDE, 0xAD, 0xC0, 0x0E}
+	connectionClosedPacketIdentifier = []byte{0xDE, 0xAD, 0xC0, 0x0C}
+)
+
+type (
+	// ConnectionMetadataProvider 客户端连接元数据提供函数，可用于补充认证身份等网关层面才能获取的信息
+	ConnectionMetadataProvider func(conn *server.Conn, metadata *ConnectionMetadata)
+)
+
+// ConnectionMetadata 客户端连接元数据
+//   - 由于端点服务器仅能获取到网关服务器的地址，网关会在客户端连接首次转发数据包到端点前，通过连接打开控制消息将客户端连接元数据发送到端点
+//   - 元数据由网关生成，其可信程度等同于发送它的连接，端点服务器只应接受来自受信任网关连接的元数据，例如通过 endpoint.WithGatewayVerifier 指定受信任的网关连接
+//   - 网关转发的客户端数据包将被封装为网关出网数据包，因此客户端无法通过网关伪造控制数据包，但直连端点服务器的客户端可以发送任意格式的数据包
+type ConnectionMetadata struct {
+	Addr        string            `json:"addr"`               // 客户端连接 ID，与网关出网数据包中的地址一致
+	IP          string            `json:"ip"`                 // 客户端 IP
+	ConnectTime int64             `json:"connectTime"`        // 客户端连接时间（毫秒级时间戳）
+	TLS         bool              `json:"tls"`                // 客户端是否通过 TLS 连接
+	Identity    string            `json:"identity,omitempty"` // 客户端认证身份
+	Extra       map[string]string `json:"extra,omitempty"`    // 额外信息
+}
+
+// newConnectionMetadata 根据客户端连接创建连接元数据
+func newConnectionMetadata(conn *server.Conn, provider ConnectionMetadataProvider) *ConnectionMetadata {
+	metadata := &ConnectionMetadata{
+		Addr:        conn.GetID(),
+		IP:          conn.GetIP(),
+		ConnectTime: conn.GetOpenTime().UnixMilli(),
+	}
+	if request := conn.GetWebsocketRequest(); request != nil {
+		metadata.TLS = request.TLS != nil
+	}
+	if provider != nil {
+		provider(conn, metadata)
+	}
+	return metadata
+}
+
+// MarshalGatewayConnectionOpenedPacket 将客户端连接元数据转换为网关连接打开控制数据包
+//   - | identifier(4) | metadata(json) |
+func MarshalGatewayConnectionOpenedPacket(metadata *ConnectionMetadata) ([]byte, error) {
+	data, err := super.MarshalJSONE(metadata)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]byte, 0, len(connectionOpenedPacketIdentifier)+len(data))
+	result = append(result, connectionOpenedPacketIdentifier...)
+	result = append(result, data...)
+	return result, nil
+}
+
+// UnmarshalGatewayConnectionOpenedPacket 将网关连接打开控制数据包转换为客户端连接元数据
+//   - | identifier(4) | metadata(json) |
+//   - 该函数不会校验数据包的来源，调用方需要确保数据包来自受信任的网关连接
+func UnmarshalGatewayConnectionOpenedPacket(data []byte) (*ConnectionMetadata, error) {
+	if !IsGatewayConnectionOpenedPacket(data) {
+		return nil, errors.New("invalid connection opened packet identifier")
+	}
+	metadata := new(ConnectionMetadata)
+	if err := super.UnmarshalJSON(data[len(connectionOpenedPacketIdentifier):], metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// IsGatewayConnectionOpenedPacket 检查数据包是否为网关连接打开控制数据包
+//   - 端点服务器在处理网关数据包时，应优先检查是否为网关连接打开控制数据包，该数据包不包含客户端发送的数据
+func IsGatewayConnectionOpenedPacket(data []byte) bool {
+	return len(data) >= len(connectionOpenedPacketIdentifier) && compareBytes(data[:len(connectionOpenedPacketIdentifier)], connectionOpenedPacketIdentifier)
+}
+
+// MarshalGatewayConnectionClosedPacket 将客户端连接 ID 转换为网关连接关闭控制数据包
+//   - | identifier(4) | ipv4(4) | port(2) |
+func MarshalGatewayConnectionClosedPacket(addr string) ([]byte, error) {
+	packet, err := MarshalGatewayOutPacket(addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]byte, 0, len(packet))
+	result = append(result, connectionClosedPacketIdentifier...)
+	result = append(result, packet[len(packetIdentifier):]...)
+	return result, nil
+}
+
+// UnmarshalGatewayConnectionClosedPacket 将网关连接关闭控制数据包转换为客户端连接 ID
+//   - | identifier(4) | ipv4(4) | port(2) |
+func UnmarshalGatewayConnectionClosedPacket(data []byte) (addr string, err error) {
+	if !IsGatewayConnectionClosedPacket(data) || len(data) < 10 {
+		return "", errors.New("invalid connection closed packet")
+	}
+	return fmt.Sprintf("%s:%d", net.IP(data[4:8]).String(), uint16(data[8])<<8|uint16(data[9])), nil
+}
+
+// IsGatewayConnectionClosedPacket 检查数据包是否为网关连接关闭控制数据包
+func IsGatewayConnectionClosedPacket(data []byte) bool {
+	return len(data) >= len(connectionClosedPacketIdentifier) && compareBytes(data[:len(connectionClosedPacketIdentifier)], connectionClosedPacketIdentifier)
+}
@@ -0,0 +1,31 @@
+package gateway_test
+
+import (
+	"github.com/kercylan98/minotaur/server/gateway"
+	"testing"
+)
+
+func TestMarshalGatewayConnectionOpenedPacket(t *testing.T) {
+	data, err := gateway.MarshalGatewayConnectionOpenedPacket(&gateway.ConnectionMetadata{
+		Addr:     "127.0.0.1:1024",
+		IP:       "127.0.0.1",
+		TLS:      true,
+		Identity: "player-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gateway.IsGatewayConnectionOpenedPacket(data) {
+		t.Fatal("connection opened packet identify failed")
+	}
+	if _, _, err = gateway.UnmarshalGatewayOutPacket(data); err == nil {
+		t.Fatal("connection opened packet should not be an out packet")
+	}
+	metadata, err := gateway.UnmarshalGatewayConnectionOpenedPacket(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Addr != "127.0.0.1:1024" || !metadata.TLS || metadata.Identity != "player-1" {
+		t.Fatalf("metadata: %+v", metadata)
+	}
+}
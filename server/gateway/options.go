@@ -10,3 +10,22 @@ func WithEndpointSelector(selector EndpointSelector) Option {
 		gateway.ess = selector
 	}
 }
+
+// WithRoutingKeyExtractor 设置路由键提取器
+//   - 设置后可通过 Gateway.GetRoutingEndpoint 获取端点，网关会学习路由键与端点的映射关系，使得相同路由键（例如房间 ID）的数据包都会转发到同一个端点
+func WithRoutingKeyExtractor(extractor RoutingKeyExtractor) Option {
+	return func(gateway *Gateway) {
+		gateway.rke = extractor
+	}
+}
+
+// WithConnectionMetadata 设置网关在客户端连接首次转发数据包到端点前，通过连接打开控制消息将客户端连接元数据发送到端点
+//   - 默认情况下端点服务器仅能获取到网关服务器的地址，开启后端点服务器可通过 IsGatewayConnectionOpenedPacket 及 UnmarshalGatewayConnectionOpenedPacket 获取客户端 IP、连接时间、TLS 状态等信息
+//   - provider 为可选的元数据提供函数，可用于补充认证身份等信息，当 provider 为 nil 时将仅发送默认的元数据
+//   - 使用 endpoint 包的端点服务器需要通过 endpoint.WithGatewayVerifier 或 endpoint.WithTrustedGatewayIP 将网关连接指定为受信任的连接，否则元数据将被忽略
+func WithConnectionMetadata(provider ConnectionMetadataProvider) Option {
+	return func(gateway *Gateway) {
+		gateway.cme = true
+		gateway.cmp = provider
+	}
+}
@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var batchPacketIdentifier = []byte{0xDE, 0xAD, 0xBA, 0x7C}
+
+// MarshalGatewayBatchPacket 将多个网关数据包合并为一个网关批量数据包
+//   - | identifier(4) | length(4) | packet | length(4) | packet | ... |
+//   - packets 应为通过 MarshalGatewayOutPacket 转换后的网关出网数据包
+func MarshalGatewayBatchPacket(packets ...[]byte) []byte {
+	var result = make([]byte, 0, len(batchPacketIdentifier)+framesSize(packets))
+	result = append(result, batchPacketIdentifier...)
+	return appendFrames(result, packets)
+}
+
+// UnmarshalGatewayBatchPacket 将网关批量数据包转换为多个网关数据包
+//   - | identifier(4) | length(4) | packet | length(4) | packet | ... |
+func UnmarshalGatewayBatchPacket(data []byte) (packets [][]byte, err error) {
+	if !IsGatewayBatchPacket(data) {
+		return nil, errors.New("invalid batch packet identifier")
+	}
+	return splitFrames(data[len(batchPacketIdentifier):])
+}
+
+// IsGatewayBatchPacket 检查数据包是否为网关批量数据包
+func IsGatewayBatchPacket(data []byte) bool {
+	return len(data) >= len(batchPacketIdentifier) && compareBytes(data[:len(batchPacketIdentifier)], batchPacketIdentifier)
+}
+
+// UnpackGatewayPackets 将网关压缩数据包、网关批量数据包拆分为多个网关数据包，当数据包为普通的网关数据包时将原样返回
+//   - 端点服务器在启用了 WithEndpointCompression 或 WithEndpointBatch 的网关中，应通过该函数拆分数据包后逐个处理，例如通过 server.Server.PushPacketMessage 重新推送
+func UnpackGatewayPackets(data []byte) ([][]byte, error) {
+	switch {
+	case IsGatewayCompressedPacket(data):
+		return UnmarshalGatewayCompressedPacket(data)
+	case IsGatewayBatchPacket(data):
+		return UnmarshalGatewayBatchPacket(data)
+	default:
+		return [][]byte{data}, nil
+	}
+}
+
+// framesSize 计算多个数据包编码为帧后的总长度
+func framesSize(packets [][]byte) int {
+	var size int
+	for _, packet := range packets {
+		size += 4 + len(packet)
+	}
+	return size
+}
+
+// appendFrames 将多个数据包以 | length(4) | packet | 的形式编码后追加到 dst 中
+func appendFrames(dst []byte, packets [][]byte) []byte {
+	for _, packet := range packets {
+		dst = binary.BigEndian.AppendUint32(dst, uint32(len(packet)))
+		dst = append(dst, packet...)
+	}
+	return dst
+}
+
+// splitFrames 将以 | length(4) | packet | 的形式编码的帧拆分为多个数据包
+func splitFrames(frames []byte) (packets [][]byte, err error) {
+	for len(frames) > 0 {
+		if len(frames) < 4 {
+			return nil, errors.New("packet frame is too short")
+		}
+		length := int(binary.BigEndian.Uint32(frames[:4]))
+		frames = frames[4:]
+		if len(frames) < length {
+			return nil, errors.New("packet frame length out of range")
+		}
+		packets = append(packets, frames[:length])
+		frames = frames[length:]
+	}
+	return packets, nil
+}
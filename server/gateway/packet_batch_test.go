@@ -0,0 +1,29 @@
+package gateway_test
+
+import (
+	"github.com/kercylan98/minotaur/server/gateway"
+	"testing"
+)
+
+func TestUnpackGatewayPackets(t *testing.T) {
+	a, _ := gateway.MarshalGatewayOutPacket("127.0.0.1:1024", []byte("hello"))
+	b, _ := gateway.MarshalGatewayOutPacket("127.0.0.1:2048", []byte("world"))
+	compressed, _ := gateway.MarshalGatewayCompressedPacket(a, b)
+	for name, data := range map[string][]byte{
+		"Batch":      gateway.MarshalGatewayBatchPacket(a, b),
+		"Compressed": compressed,
+	} {
+		packets, err := gateway.UnpackGatewayPackets(data)
+		if err != nil || len(packets) != 2 {
+			t.Fatalf("%s: %d, %v", name, len(packets), err)
+		}
+		for i, expect := range []string{"hello", "world"} {
+			if _, packet, err := gateway.UnmarshalGatewayOutPacket(packets[i]); err != nil || string(packet) != expect {
+				t.Fatalf("%s packet %d: %s, %v", name, i, packet, err)
+			}
+		}
+	}
+	if packets, err := gateway.UnpackGatewayPackets(a); err != nil || len(packets) != 1 {
+		t.Fatalf("plain: %d, %v", len(packets), err)
+	}
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/utils/compress"
+)
+
+var (
+	compressedPacketIdentifier = []byte{0xDE, 0xAD, 0xC0, 0xDE}
+	zstdMagicNumber            = []byte{0x28, 0xB5, 0x2F, 0xFD}
+)
+
+// MarshalGatewayCompressedPacket 将一个或多个网关数据包合并为一个经过 zstd 压缩的网关压缩数据包
+//   - | identifier(4) | zstd( | length(4) | packet | length(4) | packet | ... ) |
+//   - packets 应为通过 MarshalGatewayOutPacket 或 MarshalGatewayInPacket 转换后的网关数据包
+//   - 由于网关与端点之间的连接由大量客户端复用，通常会成为跨地域部署时的带宽瓶颈，合并压缩后的数据包可有效的降低带宽占用
+func MarshalGatewayCompressedPacket(packets ...[]byte) ([]byte, error) {
+	frames := appendFrames(make([]byte, 0, framesSize(packets)), packets)
+	compressed, err := compress.ZstdCompress(frames)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]byte, 0, len(compressedPacketIdentifier)+len(compressed))
+	result = append(result, compressedPacketIdentifier...)
+	result = append(result, compressed...)
+	return result, nil
+}
+
+// UnmarshalGatewayCompressedPacket 将网关压缩数据包转换为一个或多个网关数据包
+//   - | identifier(4) | zstd( | length(4) | packet | length(4) | packet | ... ) |
+func UnmarshalGatewayCompressedPacket(data []byte) (packets [][]byte, err error) {
+	if !IsGatewayCompressedPacket(data) {
+		return nil, errors.New("invalid compressed packet identifier")
+	}
+	frames, err := compress.ZstdUnCompress(data[len(compressedPacketIdentifier):])
+	if err != nil {
+		return nil, err
+	}
+	return splitFrames(frames)
+}
+
+// IsGatewayCompressedPacket 检查数据包是否为网关压缩数据包
+//   - 端点服务器在处理网关数据包时，可通过 UnpackGatewayPackets 统一进行拆分
+func IsGatewayCompressedPacket(data []byte) bool {
+	var headerSize = len(compressedPacketIdentifier) + len(zstdMagicNumber)
+	if len(data) < headerSize {
+		return false
+	}
+	return compareBytes(data[:len(compressedPacketIdentifier)], compressedPacketIdentifier) &&
+		compareBytes(data[len(compressedPacketIdentifier):headerSize], zstdMagicNumber)
+}
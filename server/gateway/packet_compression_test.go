@@ -0,0 +1,31 @@
+package gateway_test
+
+import (
+	"github.com/kercylan98/minotaur/server/gateway"
+	"testing"
+)
+
+func TestMarshalGatewayCompressedPacket(t *testing.T) {
+	a, _ := gateway.MarshalGatewayOutPacket("127.0.0.1:1024", []byte("hello"))
+	b, _ := gateway.MarshalGatewayOutPacket("127.0.0.1:2048", []byte("world"))
+	data, err := gateway.MarshalGatewayCompressedPacket(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gateway.IsGatewayCompressedPacket(data) || gateway.IsGatewayCompressedPacket(a) {
+		t.Fatal("compressed packet identify failed")
+	}
+	packets, err := gateway.UnmarshalGatewayCompressedPacket(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 2 {
+		t.Fatalf("packets: %d", len(packets))
+	}
+	for i, expect := range []string{"hello", "world"} {
+		_, packet, err := gateway.UnmarshalGatewayOutPacket(packets[i])
+		if err != nil || string(packet) != expect {
+			t.Fatalf("packet %d: %s, %v", i, packet, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"github.com/kercylan98/minotaur/server"
+)
+
+type (
+	// RoutingKeyExtractor 路由键提取器，用于从客户端数据包中提取路由键，例如房间 ID，当数据包不包含路由键时应返回 false
+	RoutingKeyExtractor func(conn *server.Conn, packet []byte) (key string, ok bool)
+)
+
+// routingBinding 路由键的绑定
+type routingBinding struct {
+	endpoint *Endpoint           // 绑定的端点
+	conns    map[string]struct{} // 通过 GetRoutingEndpoint 使用该路由键且尚未关闭的连接
+	manual   bool                // 是否通过 BindRoutingKey 绑定，手动绑定的路由键不会随连接关闭而解除
+}
+
+// routingKeyRef 连接使用过的路由键
+type routingKeyRef struct {
+	name string // 端点名称
+	key  string // 路由键
+}
+
+// GetRoutingEndpoint 获取一个可用的端点，如果数据包中包含路由键，将优先返回该路由键所绑定的端点
+//   - 当路由键未绑定端点或已绑定的端点不可用时，将通过 GetConnEndpoint 选择一个端点并将路由键绑定到该端点
+//   - 当未通过 WithRoutingKeyExtractor 设置路由键提取器或数据包中不包含路由键时，效果同 GetConnEndpoint 相同
+//   - 当同一房间的流量需要汇聚到同一个端点时，推荐使用该方法
+//   - 通过该方法绑定的路由键将在所有使用过该路由键的连接关闭或绑定的端点关闭后自动解除
+func (slf *Gateway) GetRoutingEndpoint(name string, conn *server.Conn, packet []byte) (*Endpoint, error) {
+	if slf.rke == nil {
+		return slf.GetConnEndpoint(name, conn)
+	}
+	key, ok := slf.rke(conn, packet)
+	if !ok {
+		return slf.GetConnEndpoint(name, conn)
+	}
+
+	slf.rkLock.Lock()
+	defer slf.rkLock.Unlock()
+	binding := slf.rk[name][key]
+	if binding == nil || binding.endpoint.GetState() <= 0 {
+		endpoint, err := slf.GetConnEndpoint(name, conn)
+		if err != nil {
+			slf.unbindRoutingKey(name, key)
+			return nil, err
+		}
+		if binding == nil {
+			binding = slf.bindRoutingKey(name, key)
+		}
+		binding.endpoint = endpoint
+	}
+
+	id := conn.GetID()
+	binding.conns[id] = struct{}{}
+	refs, exist := slf.rkc[id]
+	if !exist {
+		refs = make(map[routingKeyRef]struct{})
+		slf.rkc[id] = refs
+	}
+	refs[routingKeyRef{name: name, key: key}] = struct{}{}
+	return binding.endpoint, nil
+}
+
+// BindRoutingKey 将路由键绑定到特定端点，绑定后该路由键的所有数据包都会转发到该端点
+//   - 通过该方法绑定的路由键不会随连接关闭而解除，需要通过 UnbindRoutingKey 解除，或在绑定的端点关闭后自动解除
+func (slf *Gateway) BindRoutingKey(key string, endpoint *Endpoint) {
+	slf.rkLock.Lock()
+	defer slf.rkLock.Unlock()
+	binding := slf.rk[endpoint.GetName()][key]
+	if binding == nil {
+		binding = slf.bindRoutingKey(endpoint.GetName(), key)
+	}
+	binding.endpoint = endpoint
+	binding.manual = true
+}
+
+// UnbindRoutingKey 解除路由键与特定名称端点的绑定，例如房间解散时
+func (slf *Gateway) UnbindRoutingKey(name, key string) {
+	slf.rkLock.Lock()
+	defer slf.rkLock.Unlock()
+	slf.unbindRoutingKey(name, key)
+}
+
+// GetRoutingKeyEndpoint 获取路由键在特定名称下所绑定的端点，当未绑定时返回 nil
+func (slf *Gateway) GetRoutingKeyEndpoint(name, key string) *Endpoint {
+	slf.rkLock.RLock()
+	defer slf.rkLock.RUnlock()
+	if binding := slf.rk[name][key]; binding != nil {
+		return binding.endpoint
+	}
+	return nil
+}
+
+// bindRoutingKey 创建路由键的绑定，需要在持有写锁时调用
+func (slf *Gateway) bindRoutingKey(name, key string) *routingBinding {
+	keys, exist := slf.rk[name]
+	if !exist {
+		keys = make(map[string]*routingBinding)
+		slf.rk[name] = keys
+	}
+	binding := &routingBinding{conns: make(map[string]struct{})}
+	keys[key] = binding
+	return binding
+}
+
+// unbindRoutingKey 解除路由键的绑定，并移除使用过该路由键的连接对其的引用，需要在持有写锁时调用
+func (slf *Gateway) unbindRoutingKey(name, key string) {
+	keys, exist := slf.rk[name]
+	if !exist {
+		return
+	}
+	if binding, exist := keys[key]; exist {
+		ref := routingKeyRef{name: name, key: key}
+		for id := range binding.conns {
+			if refs := slf.rkc[id]; refs != nil {
+				delete(refs, ref)
+				if len(refs) == 0 {
+					delete(slf.rkc, id)
+				}
+			}
+		}
+		delete(keys, key)
+	}
+	if len(keys) == 0 {
+		delete(slf.rk, name)
+	}
+}
+
+// unbindRoutingConn 在连接关闭时移除其对路由键的引用，当路由键不再被任何连接使用且不是手动绑定时将解除绑定
+func (slf *Gateway) unbindRoutingConn(conn *server.Conn) {
+	id := conn.GetID()
+	slf.rkLock.Lock()
+	defer slf.rkLock.Unlock()
+	for ref := range slf.rkc[id] {
+		binding := slf.rk[ref.name][ref.key]
+		if binding == nil {
+			continue
+		}
+		delete(binding.conns, id)
+		if len(binding.conns) == 0 && !binding.manual {
+			slf.unbindRoutingKey(ref.name, ref.key)
+		}
+	}
+	delete(slf.rkc, id)
+}
+
+// unbindRoutingEndpoint 在端点关闭时解除所有绑定到该端点的路由键
+func (slf *Gateway) unbindRoutingEndpoint(endpoint *Endpoint) {
+	slf.rkLock.Lock()
+	defer slf.rkLock.Unlock()
+	for key, binding := range slf.rk[endpoint.GetName()] {
+		if binding.endpoint == endpoint {
+			slf.unbindRoutingKey(endpoint.GetName(), key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"testing"
+)
+
+func TestGateway_GetRoutingEndpoint(t *testing.T) {
+	var selected string
+	gw := NewGateway(server.New(server.NetworkNone), nil,
+		WithEndpointSelector(func(endpoints []*Endpoint) *Endpoint {
+			for _, endpoint := range endpoints {
+				if endpoint.GetAddress() == selected {
+					return endpoint
+				}
+			}
+			return endpoints[0]
+		}),
+		WithRoutingKeyExtractor(func(conn *server.Conn, packet []byte) (key string, ok bool) {
+			return string(packet), len(packet) > 0
+		}),
+	)
+	a := NewEndpoint("room", client.NewWebsocket("ws://127.0.0.1:1"), WithEndpointConnectionPoolSize(1))
+	b := NewEndpoint("room", client.NewWebsocket("ws://127.0.0.1:2"), WithEndpointConnectionPoolSize(1))
+	a.state.Store(1)
+	b.state.Store(1)
+	gw.es["room"] = map[string]*Endpoint{a.GetAddress(): a, b.GetAddress(): b}
+	c1, c2 := server.NewOfflineConn(gw.srv), server.NewOfflineConn(gw.srv)
+
+	var route = func(conn *server.Conn, key string, expect *Endpoint) {
+		t.Helper()
+		endpoint, err := gw.GetRoutingEndpoint("room", conn, []byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if endpoint != expect {
+			t.Fatalf("key %q routed to %s, expect %s", key, endpoint.GetAddress(), expect.GetAddress())
+		}
+	}
+
+	// 路由键亲和：无论由哪个连接发送，同一路由键都将转发到首次绑定的端点
+	selected = a.GetAddress()
+	route(c1, "r1", a)
+	selected = b.GetAddress()
+	route(c2, "r1", a)
+	route(c2, "r2", b)
+
+	// 数据包不包含路由键时，将通过 GetConnEndpoint 选择端点
+	route(c1, "", b)
+
+	// 绑定的端点不可用时，路由键将重新绑定到可用的端点
+	a.state.Store(0)
+	route(c1, "r1", b)
+	if endpoint := gw.GetRoutingKeyEndpoint("room", "r1"); endpoint != b {
+		t.Fatal("r1 should be rebound to b")
+	}
+	a.state.Store(1)
+
+	// 路由键在所有使用过它的连接关闭后解除绑定，手动绑定的路由键将被保留
+	gw.BindRoutingKey("r3", a)
+	route(c1, "r3", a)
+	gw.onConnectionClosed(c1)
+	if gw.GetRoutingKeyEndpoint("room", "r1") == nil || gw.GetRoutingKeyEndpoint("room", "r3") == nil {
+		t.Fatal("routing key should be kept until all connections closed")
+	}
+	gw.onConnectionClosed(c2)
+	if gw.GetRoutingKeyEndpoint("room", "r1") != nil || gw.GetRoutingKeyEndpoint("room", "r2") != nil {
+		t.Fatal("routing key should be unbound after all connections closed")
+	}
+	if gw.GetRoutingKeyEndpoint("room", "r3") != a {
+		t.Fatal("manual routing key should not be unbound by connection closed")
+	}
+	if len(gw.rkc) != 0 || len(gw.cce) != 0 {
+		t.Fatalf("connection references leaked, rkc: %d, cce: %d", len(gw.rkc), len(gw.cce))
+	}
+
+	// 端点关闭后绑定到该端点的路由键将被解除
+	c3 := server.NewOfflineConn(gw.srv)
+	route(c3, "r4", b)
+	gw.unbindRoutingEndpoint(a)
+	if gw.GetRoutingKeyEndpoint("room", "r3") != nil || gw.GetRoutingKeyEndpoint("room", "r4") != b {
+		t.Fatal("routing keys bound to closed endpoint should be unbound")
+	}
+	gw.unbindRoutingEndpoint(b)
+	if len(gw.rk) != 0 || len(gw.rkc) != 0 {
+		t.Fatalf("routing keys leaked, rk: %d, rkc: %d", len(gw.rk), len(gw.rkc))
+	}
+}
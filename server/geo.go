@@ -0,0 +1,84 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+	"net"
+	"strings"
+	"sync"
+)
+
+// GeoInfo 连接的地理位置信息
+type GeoInfo struct {
+	Country string `json:"country"` // 国家或地区代码，例如 "CN"
+	Region  string `json:"region"`  // 省、州等行政区域
+	City    string `json:"city"`    // 城市
+}
+
+// GeoResolver 地理位置解析器，用于在连接打开时根据 IP 为连接标记国家及区域，可基于 GeoIP 数据库等方式实现
+//   - 解析将在接受连接的协程中同步执行，实现应当尽可能快速地返回，例如查询本地数据库而非远程服务
+type GeoResolver interface {
+	// Resolve 解析 ip 所属的地理位置，无法解析时应当返回错误
+	Resolve(ip string) (GeoInfo, error)
+}
+
+// GeoResolverFunc 函数形式的地理位置解析器
+type GeoResolverFunc func(ip string) (GeoInfo, error)
+
+// Resolve 解析 ip 所属的地理位置
+func (f GeoResolverFunc) Resolve(ip string) (GeoInfo, error) {
+	return f(ip)
+}
+
+// NewCIDRGeoResolver 创建一个基于 CIDR 网段的地理位置解析器，适用于内网、测试环境或自行维护的少量网段
+func NewCIDRGeoResolver() *CIDRGeoResolver {
+	return &CIDRGeoResolver{}
+}
+
+// CIDRGeoResolver 基于 CIDR 网段的地理位置解析器，将按照添加的顺序匹配第一个包含 ip 的网段
+type CIDRGeoResolver struct {
+	lock     sync.RWMutex
+	networks []*net.IPNet
+	infos    []GeoInfo
+}
+
+// Add 添加一个网段及其对应的地理位置信息，cidr 格式不正确时将返回错误
+func (slf *CIDRGeoResolver) Add(cidr string, info GeoInfo) error {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+	slf.lock.Lock()
+	slf.networks = append(slf.networks, network)
+	slf.infos = append(slf.infos, info)
+	slf.lock.Unlock()
+	return nil
+}
+
+// Resolve 解析 ip 所属的地理位置，当没有任何网段包含 ip 时返回 ErrGeoNotFound
+func (slf *CIDRGeoResolver) Resolve(ip string) (GeoInfo, error) {
+	addr := net.ParseIP(strings.Trim(ip, "[]"))
+	if addr == nil {
+		return GeoInfo{}, ErrGeoNotFound
+	}
+	slf.lock.RLock()
+	defer slf.lock.RUnlock()
+	for i, network := range slf.networks {
+		if network.Contains(addr) {
+			return slf.infos[i], nil
+		}
+	}
+	return GeoInfo{}, ErrGeoNotFound
+}
+
+// resolveGeo 在通过 WithGeoResolver 指定了解析器时解析连接的地理位置，机器人连接将被跳过
+func (srv *Server) resolveGeo(conn *Conn) {
+	if srv.geoResolver == nil || conn.IsBot() || conn.geo.Load() != nil {
+		return
+	}
+	info, err := srv.geoResolver.Resolve(conn.GetIP())
+	if err != nil {
+		log.Debug("Server", log.String("GeoResolve", conn.GetID()), log.Err(err))
+		return
+	}
+	conn.geo.Store(&info)
+}
@@ -8,7 +8,9 @@ import (
 
 type gNet struct {
 	*Server
-	state chan<- error
+	network  Network
+	state    chan<- error
+	sessions *udpSessions // UDP 模式下的虚拟会话表
 }
 
 func (g *gNet) OnInitComplete(server gnet.Server) (action gnet.Action) {
@@ -24,14 +26,22 @@ func (g *gNet) OnShutdown(server gnet.Server) {
 }
 
 func (g *gNet) OnOpened(c gnet.Conn) (out []byte, action gnet.Action) {
-	conn := newGNetConn(g.Server, c)
+	// 在创建连接前检查连接数限制，避免被拒绝的连接所启动的写循环等资源无法释放
+	if !g.acceptConn(addrIP(c.RemoteAddr())) {
+		return nil, gnet.Close
+	}
+	conn := newGNetConn(g.Server, g.network, c)
+	conn.limited = true
 	c.SetContext(conn)
 	g.OnConnectionOpenedEvent(conn)
 	return
 }
 
 func (g *gNet) OnClosed(c gnet.Conn, err error) (action gnet.Action) {
-	conn := c.Context().(*Conn)
+	conn, ok := c.Context().(*Conn)
+	if !ok {
+		return // 被拒绝的连接
+	}
 	conn.Close(err)
 	return
 }
@@ -45,7 +55,27 @@ func (g *gNet) AfterWrite(c gnet.Conn, b []byte) {
 }
 
 func (g *gNet) React(packet []byte, c gnet.Conn) (out []byte, action gnet.Action) {
-	g.Server.PushPacketMessage(c.Context().(*Conn), 0, bytes.Clone(packet))
+	var conn *Conn
+	if g.sessions != nil {
+		var ok bool
+		if conn, packet, ok = g.sessions.load(c, packet); !ok {
+			return nil, gnet.None
+		}
+	} else {
+		conn = c.Context().(*Conn)
+	}
+	packet, ok, err := conn.unpackPacket(0, bytes.Clone(packet))
+	if err != nil {
+		conn.Close(err)
+		if conn.udp != nil {
+			return nil, gnet.None // UDP 的 gnet.Conn 共享监听套接字，不能关闭
+		}
+		return nil, gnet.Close
+	}
+	if !ok {
+		return nil, gnet.None
+	}
+	g.Server.PushPacketMessage(conn, 0, packet)
 	return nil, gnet.None
 }
 
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPCHealth 获取通过 WithGRPCHealth 开启的健康检查服务，未开启时将返回 nil
+//   - 服务器启动完成后将自动把整体状态及所有已注册服务的状态设置为 SERVING，停止时将设置为 NOT_SERVING
+//   - 可通过该函数在运行期间设置特定服务的状态
+func (srv *Server) GRPCHealth() *health.Server {
+	return srv.grpcHealth
+}
+
+// registerGRPCBuiltin 在开始提供服务前向 GRPC 服务器注册内置的健康检查及反射服务
+//   - 由于 WithGRPCServerOptions 将重新创建 GRPC 服务器，内置服务需在监听前注册
+func (srv *Server) registerGRPCBuiltin() {
+	if srv.grpcHealth != nil {
+		grpc_health_v1.RegisterHealthServer(srv.grpcServer, srv.grpcHealth)
+	}
+	if srv.grpcReflection {
+		reflection.Register(srv.grpcServer)
+	}
+}
+
+// onGRPCServing 在服务器启动完成后将健康检查的整体状态及所有已注册服务的状态设置为 SERVING
+func (srv *Server) onGRPCServing() {
+	if srv.grpcHealth == nil || srv.grpcServer == nil {
+		return
+	}
+	for name := range srv.grpcServer.GetServiceInfo() {
+		srv.grpcHealth.SetServingStatus(name, grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+	srv.grpcHealth.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
+// onGRPCStopping 在服务器开始停止时将健康检查的所有状态设置为 NOT_SERVING，此后状态将不再发生变化
+func (srv *Server) onGRPCStopping() {
+	if srv.grpcHealth == nil {
+		return
+	}
+	srv.grpcHealth.Shutdown()
+}
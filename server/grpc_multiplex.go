@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"github.com/kercylan98/minotaur/utils/log"
+	"net"
+	"sync"
+	"time"
+)
+
+// grpcMultiplexPreface HTTP/2 客户端连接前言，GRPC 客户端建立连接后将首先发送该前言
+var grpcMultiplexPreface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// newGRPCMultiplexListener 创建通过嗅探连接的首个数据区分 GRPC 及 HTTP 流量的监听器
+func newGRPCMultiplexListener(root net.Listener, sniffTimeout time.Duration) *grpcMultiplexListener {
+	l := &grpcMultiplexListener{root: root, sniffTimeout: sniffTimeout}
+	l.grpc = &grpcMultiplexChild{parent: l, conns: make(chan net.Conn), done: make(chan struct{})}
+	l.http = &grpcMultiplexChild{parent: l, conns: make(chan net.Conn), done: make(chan struct{})}
+	go l.serve()
+	return l
+}
+
+// grpcMultiplexListener 在同一监听器上复用 GRPC 及 HTTP 流量的监听器
+type grpcMultiplexListener struct {
+	root         net.Listener
+	sniffTimeout time.Duration
+	grpc         *grpcMultiplexChild // 接收以 HTTP/2 连接前言开头的连接
+	http         *grpcMultiplexChild // 接收其他连接，例如 HTTP/1.x 及 Websocket 升级请求
+	closeOnce    sync.Once
+}
+
+func (l *grpcMultiplexListener) serve() {
+	defer func() {
+		l.grpc.close()
+		l.http.close()
+	}()
+	for {
+		conn, err := l.root.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return
+		}
+		go l.dispatch(conn)
+	}
+}
+
+// dispatch 读取连接的首个数据并将连接交由对应的子监听器，读取的数据将在之后被重新读取
+func (l *grpcMultiplexListener) dispatch(conn net.Conn) {
+	if l.sniffTimeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(l.sniffTimeout))
+	}
+	var buf = make([]byte, len(grpcMultiplexPreface))
+	var n int
+	var target = l.grpc
+	for n < len(buf) {
+		read, err := conn.Read(buf[n:])
+		n += read
+		if !bytes.Equal(buf[:n], grpcMultiplexPreface[:n]) {
+			target = l.http
+			break
+		}
+		if err != nil {
+			_ = conn.Close()
+			return
+		}
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	target.push(&grpcMultiplexConn{Conn: conn, sniffed: buf[:n]})
+}
+
+func (l *grpcMultiplexListener) close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		err = l.root.Close()
+	})
+	return err
+}
+
+// grpcMultiplexChild 复用监听器中特定协议的子监听器，关闭任一子监听器都将关闭底层的监听器
+type grpcMultiplexChild struct {
+	parent    *grpcMultiplexListener
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *grpcMultiplexChild) push(conn net.Conn) {
+	select {
+	case c.conns <- conn:
+	case <-c.done:
+		_ = conn.Close()
+	}
+}
+
+func (c *grpcMultiplexChild) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *grpcMultiplexChild) Accept() (net.Conn, error) {
+	select {
+	case conn := <-c.conns:
+		return conn, nil
+	case <-c.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (c *grpcMultiplexChild) Close() error {
+	c.close()
+	return c.parent.close()
+}
+
+func (c *grpcMultiplexChild) Addr() net.Addr {
+	return c.parent.root.Addr()
+}
+
+// grpcMultiplexConn 将嗅探时读取的数据重新交由后续读取的连接
+type grpcMultiplexConn struct {
+	net.Conn
+	sniffed []byte
+}
+
+func (c *grpcMultiplexConn) Read(b []byte) (int, error) {
+	if len(c.sniffed) > 0 {
+		n := copy(b, c.sniffed)
+		c.sniffed = c.sniffed[n:]
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
+
+// multiplexGRPC 在通过 WithGRPCMultiplex 开启复用时，在 lis 上开始提供 GRPC 服务，并返回仅接收其他流量的监听器
+//   - 未开启复用时将直接返回 lis
+//   - 需要在开始提供服务的协程之外同步调用，内置服务将在监听器开始接受连接前注册，以免与启动完成后读取已注册服务的 onGRPCServing 产生竞争
+func (srv *Server) multiplexGRPC(lis net.Listener) net.Listener {
+	if !srv.grpcMultiplex {
+		return lis
+	}
+	srv.registerGRPCBuiltin()
+	l := newGRPCMultiplexListener(lis, DefaultGRPCMultiplexSniffTimeout)
+	go func(srv *Server, l *grpcMultiplexListener) {
+		if err := srv.grpcServer.Serve(l.grpc); err != nil {
+			log.Error("Server", log.String("action", "grpc-multiplex"), log.Err(err))
+		}
+	}(srv, l)
+	return l.http
+}
@@ -0,0 +1,152 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWithGRPCHealth(t *testing.T) {
+	var started, stopped bool
+	var status grpc_health_v1.HealthCheckResponse_ServingStatus
+	var services []string
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkGRPC, server.WithGRPCHealth(), server.WithGRPCReflection())
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		started = true
+		go func() {
+			defer srv.Shutdown()
+			conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+
+			// 启动完成后状态将被异步设置为 SERVING
+			for status != grpc_health_v1.HealthCheckResponse_SERVING && ctx.Err() == nil {
+				resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				status = resp.GetStatus()
+			}
+
+			stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if err = stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+				MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+			}); err != nil {
+				t.Error(err)
+				return
+			}
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for _, service := range resp.GetListServicesResponse().GetService() {
+				services = append(services, service.GetName())
+			}
+		}()
+	})
+	srv.RegStopEvent(func(srv *server.Server) {
+		stopped = true
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if !started || !stopped {
+		t.Fatalf("started: %v, stopped: %v", started, stopped)
+	}
+	if status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("unexpected health status: %s", status)
+	}
+	var found bool
+	for _, service := range services {
+		if service == grpc_health_v1.Health_ServiceDesc.ServiceName {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("health service not found in reflection: %v", services)
+	}
+	if srv.GRPCHealth() == nil {
+		t.Fatal("health server is nil")
+	}
+}
+
+func TestWithGRPCMultiplex(t *testing.T) {
+	var opened bool
+	var status grpc_health_v1.HealthCheckResponse_ServingStatus
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkWebsocket, server.WithGRPCMultiplex(), server.WithGRPCHealth())
+	srv.HttpServer().GET("/healthz", func(ctx *server.HttpContext) {
+		ctx.String(http.StatusOK, "ok")
+	})
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened = true
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			resp, err := http.Get(fmt.Sprintf("http://%s/healthz", addr))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+				t.Errorf("unexpected response: %d %s", resp.StatusCode, body)
+			}
+
+			ws, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/ws", addr), nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer ws.Close()
+
+			conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+			for status != grpc_health_v1.HealthCheckResponse_SERVING && ctx.Err() == nil {
+				resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				status = resp.GetStatus()
+			}
+			time.Sleep(time.Millisecond * 100)
+		}()
+	})
+	if err := srv.Run(addr + "/ws"); err != nil {
+		t.Fatal(err)
+	}
+	if !opened || status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("opened: %v, status: %s", opened, status)
+	}
+}
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/panjf2000/gnet"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	handoverFdsStart = 3                            // 继承的文件描述符的起始值，与 systemd 的套接字激活协议一致
+	envListenFds     = "LISTEN_FDS"                 // 继承的监听器数量
+	envListenPid     = "LISTEN_PID"                 // 继承的监听器所属的进程 ID，为空时不进行校验
+	envListenFdNames = "LISTEN_FDNAMES"             // 继承的监听器名称，以 ":" 分隔
+	envHandoverReady = "MINOTAUR_HANDOVER_READY_FD" // 新进程启动完成后进行通知的文件描述符
+)
+
+// inheritance 当前进程继承的文件描述符，同一进程中的多个服务器共享
+var inheritance struct {
+	once  sync.Once
+	lock  sync.Mutex
+	files []*inheritedFile
+	ready *os.File
+}
+
+// inheritedFile 继承的文件描述符
+type inheritedFile struct {
+	name string
+	file *os.File
+}
+
+// handoverListener 可交接给新进程的监听器
+type handoverListener struct {
+	name     string
+	listener net.Listener
+}
+
+// handoverName 获取监听地址在交接时使用的名称，名称经过转义以避免包含 ":"
+func handoverName(network Network, addr string) string {
+	return url.QueryEscape(fmt.Sprintf("%s://%s", network, addr))
+}
+
+// loadInheritedFiles 从环境变量中加载继承的文件描述符，加载后将移除相关的环境变量
+func loadInheritedFiles() {
+	inheritance.once.Do(func() {
+		defer func() {
+			for _, key := range []string{envListenFds, envListenPid, envListenFdNames, envHandoverReady} {
+				_ = os.Unsetenv(key)
+			}
+		}()
+		if fd, err := strconv.Atoi(os.Getenv(envHandoverReady)); err == nil {
+			inheritance.ready = os.NewFile(uintptr(fd), envHandoverReady)
+		}
+		if pid := os.Getenv(envListenPid); len(pid) > 0 && pid != strconv.Itoa(os.Getpid()) {
+			return
+		}
+		count, err := strconv.Atoi(os.Getenv(envListenFds))
+		if err != nil {
+			return
+		}
+		names := strings.Split(os.Getenv(envListenFdNames), ":")
+		for i := 0; i < count; i++ {
+			var name string
+			if i < len(names) {
+				name = names[i]
+			}
+			inheritance.files = append(inheritance.files, &inheritedFile{
+				name: name,
+				file: os.NewFile(uintptr(handoverFdsStart+i), name),
+			})
+		}
+	})
+}
+
+// takeInheritedFile 获取特定名称的继承的文件描述符，每个文件描述符仅能被获取一次
+//   - 当 fallback 为 true 且没有匹配的名称时，将使用首个未命名的文件描述符，以便于 systemd 的套接字激活无需配置 FileDescriptorName
+func takeInheritedFile(name string, fallback bool) *os.File {
+	loadInheritedFiles()
+	inheritance.lock.Lock()
+	defer inheritance.lock.Unlock()
+	var candidate = -1
+	for i, file := range inheritance.files {
+		if file.name == name {
+			candidate = i
+			break
+		}
+		if fallback && candidate == -1 && (len(file.name) == 0 || file.name == "unknown") {
+			candidate = i
+		}
+	}
+	if candidate == -1 {
+		return nil
+	}
+	file := inheritance.files[candidate].file
+	inheritance.files = append(inheritance.files[:candidate], inheritance.files[candidate+1:]...)
+	return file
+}
+
+// inheritListener 获取继承的监听器，不存在时将返回 nil
+func inheritListener(name string, fallback bool) (net.Listener, error) {
+	file := takeInheritedFile(name, fallback)
+	if file == nil {
+		return nil, nil
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return net.FileListener(file)
+}
+
+// inheritPacketConn 获取继承的数据包连接，不存在时将返回 nil
+func inheritPacketConn(name string, fallback bool) (net.PacketConn, error) {
+	file := takeInheritedFile(name, fallback)
+	if file == nil {
+		return nil, nil
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return net.FilePacketConn(file)
+}
+
+// notifyHandoverReady 当前进程由 HandoverAndRestart 启动时，通知旧进程启动已完成
+func notifyHandoverReady() {
+	loadInheritedFiles()
+	inheritance.lock.Lock()
+	ready := inheritance.ready
+	inheritance.ready = nil
+	inheritance.lock.Unlock()
+	if ready == nil {
+		return
+	}
+	_, _ = ready.Write([]byte{1})
+	_ = ready.Close()
+}
+
+// trackListener 记录可交接给新进程的监听器
+func (srv *Server) trackListener(name string, listener net.Listener) {
+	srv.handoverLock.Lock()
+	srv.handoverListeners = append(srv.handoverListeners, handoverListener{name: name, listener: listener})
+	srv.handoverLock.Unlock()
+}
+
+// checkHandover 检查服务器的所有网络类型是否支持交接
+func (srv *Server) checkHandover() error {
+	var networks = []Network{srv.network}
+	for _, listen := range srv.additionalListens {
+		networks = append(networks, listen.network)
+	}
+	for _, network := range networks {
+		switch network {
+		case NetworkTcp, NetworkTcp4, NetworkTcp6:
+			options := new(gnet.Options)
+			for _, option := range srv.gnetOptions {
+				option(options)
+			}
+			if !options.ReusePort {
+				return fmt.Errorf("%w: %s requires gnet.WithReusePort", ErrHandoverUnsupported, network)
+			}
+		case NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix, NetworkKcp:
+			return fmt.Errorf("%w: %s", ErrHandoverUnsupported, network)
+		}
+	}
+	return nil
+}
+
+// HandoverAndRestart 将监听器交接给以相同参数启动的新进程，并在新进程启动完成后通过 RollingShutdown 排空当前进程，以实现不断开已有连接的重启
+//   - 新进程将通过与 systemd 套接字激活相同的 LISTEN_FDS 协议继承 Http、Websocket、GRPC 及运维管理接口的监听器，继承在 Run 时自动进行
+//   - 基于 gnet 的 Tcp、Tcp4、Tcp6 网络类型无法继承监听器，需要通过 WithGNetOptions(gnet.WithReusePort(true)) 开启端口复用，由新进程重新绑定地址
+//   - 基于 UDP 的网络类型及 Unix、Kcp 由于会话依赖于同一套接字，不支持交接，将返回 ErrHandoverUnsupported
+//   - 当 ctx 在新进程启动完成前结束或新进程提前退出时，新进程将被终止且当前进程继续提供服务
+//   - 新进程启动完成后，当前进程将关闭监听器并拒绝新的连接，随后等待已有连接断开，该函数将阻塞至当前进程开始停止，ctx 同时作用于 RollingShutdown
+func (srv *Server) HandoverAndRestart(ctx context.Context) error {
+	if srv.IsDraining() || !srv.handingOver.CompareAndSwap(false, true) {
+		return ErrHandoverInProgress
+	}
+	if err := srv.checkHandover(); err != nil {
+		srv.handingOver.Store(false)
+		return err
+	}
+	pid, err := srv.startHandoverProcess(ctx)
+	if err != nil {
+		srv.handingOver.Store(false)
+		log.Error("Server", log.String("Handover", "failed"), log.Err(err))
+		return err
+	}
+	log.Info("Server", log.String("Handover", "ready"), log.Int("pid", pid), log.String("listen", srv.addr))
+
+	srv.handedOver.Store(true)
+	srv.handoverLock.Lock()
+	for _, l := range srv.handoverListeners {
+		_ = l.listener.Close()
+	}
+	srv.handoverLock.Unlock()
+	return srv.RollingShutdown(ctx)
+}
+
+// startHandoverProcess 启动继承监听器的新进程，并等待其启动完成
+func (srv *Server) startHandoverProcess(ctx context.Context) (pid int, err error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	var files []*os.File
+	var names []string
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+	srv.handoverLock.Lock()
+	for _, l := range srv.handoverListeners {
+		listener, ok := l.listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			srv.handoverLock.Unlock()
+			return 0, fmt.Errorf("%w: %T", ErrHandoverUnsupported, l.listener)
+		}
+		file, err := listener.File()
+		if err != nil {
+			srv.handoverLock.Unlock()
+			return 0, err
+		}
+		files = append(files, file)
+		names = append(names, l.name)
+	}
+	srv.handoverLock.Unlock()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	for _, env := range os.Environ() {
+		switch strings.SplitN(env, "=", 2)[0] {
+		case envListenFds, envListenPid, envListenFdNames, envHandoverReady:
+		default:
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	cmd.Env = append(cmd.Env,
+		fmt.Sprintf("%s=%d", envListenFds, len(files)),
+		fmt.Sprintf("%s=%s", envListenFdNames, strings.Join(names, ":")),
+		fmt.Sprintf("%s=%d", envHandoverReady, handoverFdsStart+len(files)),
+	)
+	cmd.ExtraFiles = append(append([]*os.File(nil), files...), writer)
+	err = cmd.Start()
+	_ = writer.Close()
+	if err != nil {
+		_ = reader.Close()
+		return 0, err
+	}
+
+	var ready = make(chan error, 1)
+	go func() {
+		defer func() {
+			_ = reader.Close()
+		}()
+		if _, err := reader.Read(make([]byte, 1)); err != nil {
+			ready <- ErrHandoverProcessExited
+			return
+		}
+		ready <- nil
+	}()
+	select {
+	case err = <-ready:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+	}
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return cmd.Process.Pid, err
+}
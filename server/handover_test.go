@@ -0,0 +1,67 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+const handoverTestAddr = "MINOTAUR_HANDOVER_TEST_ADDR"
+
+func TestMain(m *testing.M) {
+	// 由 HandoverAndRestart 启动的新进程仅运行继承监听器的服务器
+	if addr := os.Getenv(handoverTestAddr); len(addr) > 0 {
+		srv := newHandoverTestServer("child", true)
+		if err := srv.Run(addr); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func newHandoverTestServer(name string, shutdown bool) *server.Server {
+	srv := server.New(server.NetworkHttp, server.WithRollingQuietPeriod(time.Millisecond*100))
+	srv.HttpEngine().Handle(http.MethodGet, "/", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(name))
+		if shutdown {
+			go srv.Shutdown()
+		}
+	})
+	return srv
+}
+
+func TestServer_HandoverAndRestart(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := newHandoverTestServer("parent", false)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			_ = os.Setenv(handoverTestAddr, addr)
+			defer os.Unsetenv(handoverTestAddr)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			defer cancel()
+			if err := srv.HandoverAndRestart(ctx); err != nil {
+				t.Error(err)
+				srv.Shutdown()
+			}
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/", addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "child" {
+		t.Fatalf("unexpected response: %s", body)
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HttpMiddleware 基于 net/http 的 HTTP 中间件
+type HttpMiddleware func(next http.Handler) http.Handler
+
+// HttpEngine HTTP 引擎接口，NetworkHttp 服务器默认使用基于 gin 的实现
+//   - 可通过 WithHttpEngine 替换为其他基于 net/http 的路由器实现，例如 NewServeMuxHttpEngine
+//   - 引擎将作为 http.Handler 运行于服务器内部的 http.Server 中，因此 fiber 等基于 fasthttp 的路由器无法直接接入，目前也未提供相应的适配器
+//   - 路径参数等路由语法由具体实现决定
+type HttpEngine interface {
+	http.Handler
+	// Handle 注册特定请求方法及路径的处理函数
+	Handle(method, path string, handler http.HandlerFunc)
+	// Use 添加作用于此后注册的路由的中间件
+	Use(middlewares ...HttpMiddleware)
+	// Shutdown 在服务器停止且 HTTP 服务器已停止接受请求后调用，用于释放引擎的资源
+	Shutdown(ctx context.Context) error
+}
+
+// NewGinHttpEngine 创建基于 gin.Engine 的 HTTP 引擎，NetworkHttp 服务器默认通过该函数包装内部的 gin.Engine
+func NewGinHttpEngine(engine *gin.Engine) HttpEngine {
+	return &ginHttpEngine{Engine: engine}
+}
+
+// ginHttpEngine 基于 gin.Engine 的 HTTP 引擎
+type ginHttpEngine struct {
+	*gin.Engine
+}
+
+func (e *ginHttpEngine) Handle(method, path string, handler http.HandlerFunc) {
+	e.Engine.Handle(method, path, gin.WrapF(handler))
+}
+
+// Use 将 net/http 中间件转换为 gin 中间件，当中间件没有调用 next 时将终止后续处理函数
+//   - 中间件对 http.ResponseWriter 的包装不会传递至后续的 gin 处理函数
+func (e *ginHttpEngine) Use(middlewares ...HttpMiddleware) {
+	for _, middleware := range middlewares {
+		middleware := middleware
+		e.Engine.Use(func(ctx *gin.Context) {
+			var called bool
+			middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				called = true
+				ctx.Request = request
+				ctx.Next()
+			})).ServeHTTP(ctx.Writer, ctx.Request)
+			if !called {
+				ctx.Abort()
+			}
+		})
+	}
+}
+
+func (e *ginHttpEngine) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// NewServeMuxHttpEngine 创建基于标准库 http.ServeMux 的 HTTP 引擎，当 mux 为 nil 时将创建新的 http.ServeMux
+//   - 路由将以 "METHOD /path" 的形式注册，路径参数等语法与 http.ServeMux 一致，例如 "/user/{id}"
+func NewServeMuxHttpEngine(mux *http.ServeMux) HttpEngine {
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	return &serveMuxHttpEngine{mux: mux}
+}
+
+// serveMuxHttpEngine 基于 http.ServeMux 的 HTTP 引擎
+type serveMuxHttpEngine struct {
+	mux         *http.ServeMux
+	lock        sync.RWMutex
+	middlewares []HttpMiddleware
+}
+
+func (e *serveMuxHttpEngine) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	e.mux.ServeHTTP(writer, request)
+}
+
+func (e *serveMuxHttpEngine) Handle(method, path string, handler http.HandlerFunc) {
+	e.lock.RLock()
+	var h http.Handler = handler
+	for i := len(e.middlewares) - 1; i >= 0; i-- {
+		h = e.middlewares[i](h)
+	}
+	e.lock.RUnlock()
+	e.mux.Handle(method+" "+path, h)
+}
+
+func (e *serveMuxHttpEngine) Use(middlewares ...HttpMiddleware) {
+	e.lock.Lock()
+	e.middlewares = append(e.middlewares, middlewares...)
+	e.lock.Unlock()
+}
+
+func (e *serveMuxHttpEngine) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// HttpEngine 获取服务器的 HTTP 引擎，当网络类型不为 NetworkHttp 或 NetworkWebsocket 时将会发生 panic
+//   - 未通过 WithHttpEngine 指定引擎时，将返回包装了内部 gin.Engine 的默认引擎
+func (srv *Server) HttpEngine() HttpEngine {
+	if srv.httpEngine != nil {
+		return srv.httpEngine
+	}
+	if srv.ginServer == nil {
+		panic(ErrNetworkOnlySupportHttp)
+	}
+	return NewGinHttpEngine(srv.ginServer)
+}
+
+// httpEngineHandler 为自定义 HTTP 引擎的请求进行消息统计，以便服务器停止时等待请求结束
+func (srv *Server) httpEngineHandler(engine HttpEngine) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		srv.hitMessageStatistics()
+		defer srv.messageCounter.Add(-1)
+		var now = time.Now()
+		engine.ServeHTTP(writer, request)
+		srv.low(nil, now, srv.asyncLowMessageDuration, true, "HTTP ["+request.Method+"] "+request.RequestURI)
+	})
+}
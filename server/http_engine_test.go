@@ -0,0 +1,49 @@
+package server_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestWithHttpEngine(t *testing.T) {
+	for _, engine := range []server.HttpEngine{nil, server.NewServeMuxHttpEngine(nil)} {
+		addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+		path := "/user/:id"
+		srv := server.New(server.NetworkHttp)
+		if engine != nil {
+			srv = server.New(server.NetworkHttp, server.WithHttpEngine(engine))
+			path = "/user/{id}"
+		}
+		srv.HttpEngine().Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("X-Engine", "minotaur")
+				next.ServeHTTP(writer, request)
+			})
+		})
+		srv.HttpEngine().Handle(http.MethodGet, path, func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = writer.Write([]byte("user"))
+		})
+		srv.RegStartFinishEvent(func(srv *server.Server) {
+			go func() {
+				defer srv.Shutdown()
+				resp, err := http.Get(fmt.Sprintf("http://%s/user/1", addr))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer resp.Body.Close()
+				body, _ := io.ReadAll(resp.Body)
+				if string(body) != "user" || resp.Header.Get("X-Engine") != "minotaur" {
+					t.Errorf("unexpected response: %s, %v", body, resp.Header)
+				}
+			}()
+		})
+		if err := srv.Run(addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"compress/gzip"
+	"github.com/gin-gonic/gin"
+	"github.com/kercylan98/minotaur/utils/log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HttpCORSConfig 跨域资源共享配置
+type HttpCORSConfig struct {
+	AllowOrigins     []string                 // 允许的来源，包含 "*" 时允许所有来源
+	AllowOriginFunc  func(origin string) bool // 自定义的来源校验函数，当 AllowOrigins 未匹配时将使用该函数校验
+	AllowMethods     []string                 // 允许的请求方法，为空时允许 GET、POST、PUT、PATCH、DELETE、HEAD
+	AllowHeaders     []string                 // 允许的请求头，为空时将允许预检请求中声明的所有请求头
+	ExposeHeaders    []string                 // 允许客户端读取的响应头
+	AllowCredentials bool                     // 是否允许携带凭证，开启后将始终返回具体的来源而不是 "*"
+	MaxAge           time.Duration            // 预检请求结果的缓存时长，为 0 时不设置
+}
+
+// allowOrigin 检查来源是否被允许
+func (c *HttpCORSConfig) allowOrigin(origin string) (allowed, wildcard bool) {
+	for _, allow := range c.AllowOrigins {
+		if allow == "*" {
+			return true, true
+		}
+		if strings.EqualFold(allow, origin) {
+			return true, false
+		}
+	}
+	if c.AllowOriginFunc != nil && c.AllowOriginFunc(origin) {
+		return true, false
+	}
+	return false, false
+}
+
+// httpCORS 创建跨域资源共享中间件
+func httpCORS(config HttpCORSConfig) gin.HandlerFunc {
+	methods := config.AllowMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge / time.Second))
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if len(origin) == 0 {
+			ctx.Next()
+			return
+		}
+		preflight := ctx.Request.Method == http.MethodOptions && len(ctx.GetHeader("Access-Control-Request-Method")) > 0
+		allowed, wildcard := config.allowOrigin(origin)
+		if !allowed {
+			if preflight {
+				ctx.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		header := ctx.Writer.Header()
+		if wildcard && !config.AllowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Add("Vary", "Origin")
+		}
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if len(exposeHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			ctx.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", allowMethods)
+		if len(allowHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if requestHeaders := ctx.GetHeader("Access-Control-Request-Headers"); len(requestHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", requestHeaders)
+		}
+		if config.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", maxAge)
+		}
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// httpGzipWriter 在首次写入时开始 gzip 压缩的响应写入器
+type httpGzipWriter struct {
+	gin.ResponseWriter
+	pool   *sync.Pool
+	writer *gzip.Writer
+}
+
+func (w *httpGzipWriter) Write(data []byte) (int, error) {
+	if w.writer == nil {
+		header := w.Header()
+		if len(header.Get("Content-Encoding")) > 0 {
+			return w.ResponseWriter.Write(data)
+		}
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		w.writer = w.pool.Get().(*gzip.Writer)
+		w.writer.Reset(w.ResponseWriter)
+	}
+	return w.writer.Write(data)
+}
+
+func (w *httpGzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *httpGzipWriter) Flush() {
+	if w.writer != nil {
+		_ = w.writer.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// close 结束压缩并将 gzip.Writer 归还至对象池
+func (w *httpGzipWriter) close() {
+	if w.writer == nil {
+		return
+	}
+	_ = w.writer.Close()
+	w.pool.Put(w.writer)
+	w.writer = nil
+}
+
+// httpGzip 创建 gzip 压缩中间件，仅对声明支持 gzip 的非 websocket 升级请求生效
+func httpGzip(level int) gin.HandlerFunc {
+	pool := &sync.Pool{New: func() any {
+		writer, err := gzip.NewWriterLevel(nil, level)
+		if err != nil {
+			writer = gzip.NewWriter(nil)
+		}
+		return writer
+	}}
+	return func(ctx *gin.Context) {
+		if !strings.Contains(ctx.GetHeader("Accept-Encoding"), "gzip") || strings.EqualFold(ctx.GetHeader("Upgrade"), "websocket") {
+			ctx.Next()
+			return
+		}
+		writer := &httpGzipWriter{ResponseWriter: ctx.Writer, pool: pool}
+		ctx.Writer = writer
+		defer func() {
+			writer.close()
+			ctx.Writer = writer.ResponseWriter
+		}()
+		ctx.Next()
+	}
+}
+
+// httpAccessLog 创建访问日志中间件
+func httpAccessLog() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		t := time.Now()
+		ctx.Next()
+		log.Info("Server", log.String("type", "http"),
+			log.String("method", ctx.Request.Method), log.Int("status", ctx.Writer.Status()),
+			log.String("ip", ctx.ClientIP()), log.String("path", ctx.Request.URL.Path),
+			log.Int("size", ctx.Writer.Size()), log.Duration("cost", time.Since(t)))
+	}
+}
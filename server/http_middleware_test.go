@@ -0,0 +1,75 @@
+package server_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithHttpCORS(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	content := strings.Repeat("minotaur", 128)
+	srv := server.New(server.NetworkHttp,
+		server.WithHttpCORS(server.HttpCORSConfig{AllowOrigins: []string{"https://game.example.com"}, MaxAge: time.Hour}),
+		server.WithHttpGzip(gzip.BestSpeed),
+		server.WithHttpAccessLog(),
+	)
+	srv.HttpServer().GET("/data", func(ctx *server.HttpContext) {
+		ctx.String(http.StatusOK, content)
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			url := fmt.Sprintf("http://%s/data", addr)
+
+			request, _ := http.NewRequest(http.MethodOptions, url, nil)
+			request.Header.Set("Origin", "https://game.example.com")
+			request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			resp, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "https://game.example.com" || resp.Header.Get("Access-Control-Max-Age") != "3600" {
+				t.Errorf("unexpected preflight response: %d %v", resp.StatusCode, resp.Header)
+			}
+
+			request.Header.Set("Origin", "https://evil.example.com")
+			if resp, err = http.DefaultClient.Do(request); err != nil || resp.StatusCode != http.StatusForbidden {
+				t.Errorf("preflight from disallowed origin should be forbidden, err: %v", err)
+				return
+			}
+			_ = resp.Body.Close()
+
+			request, _ = http.NewRequest(http.MethodGet, url, nil)
+			request.Header.Set("Accept-Encoding", "gzip")
+			if resp, err = http.DefaultClient.Do(request); err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			if resp.Header.Get("Content-Encoding") != "gzip" {
+				t.Errorf("response should be compressed: %v", resp.Header)
+				return
+			}
+			reader, err := gzip.NewReader(resp.Body)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if body, _ := io.ReadAll(reader); string(body) != content {
+				t.Errorf("unexpected body: %s", body)
+			}
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/alphadose/haxmap"
 	"github.com/kercylan98/minotaur/utils/buffer"
 	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/routines"
 	"github.com/kercylan98/minotaur/utils/super"
 	"sync"
 	"sync/atomic"
@@ -12,6 +13,9 @@ import (
 
 var unique = struct{}{}
 
+// RoutineGroup 消息分发器协程所属的协程组名称
+const RoutineGroup = "dispatcher"
+
 // Handler 消息处理器
 type Handler[P Producer, M Message[P]] func(dispatcher *Dispatcher[P, M], message M)
 
@@ -29,6 +33,7 @@ func NewDispatcher[P Producer, M Message[P]](bufferSize int, name string, handle
 		pmcF:    make(map[P]func(p P, dispatcher *Action[P, M])),
 		abort:   make(chan struct{}),
 	}
+	d.cond = sync.NewCond(&d.lock)
 	return d
 }
 
@@ -57,6 +62,63 @@ type Dispatcher[P Producer, M Message[P]] struct {
 	name          string
 	closedHandler atomic.Pointer[func(dispatcher *Action[P, M])]
 	abort         chan struct{}
+
+	queued          int                   // 缓冲区中等待处理的消息数量
+	limit           int                   // 缓冲区容量，超出后将触发溢出策略
+	policy          OverflowPolicy        // 缓冲区溢出策略
+	overflowHandler OverflowHandler[P, M] // 缓冲区溢出时的处理函数
+	cond            *sync.Cond            // 阻塞策略的等待条件
+	sizer           func(message M) int64 // 估算消息占用内存的函数
+	bytes           int64                 // 缓冲区中等待处理的消息估算占用的内存字节数
+	skip            int                   // 重启时缓冲区中等待丢弃的消息数量
+	discard         func(message M)       // 重启时被丢弃消息的处理函数
+}
+
+// SetOverflowPolicy 设置消息分发器缓冲区溢出策略
+//   - 当缓冲区中等待处理的消息数量达到 limit 时，将根据 policy 进行处理，并执行 handler
+//   - 当 limit <= 0 时，表示缓冲区不会溢出
+//   - 需要注意的是，当策略为 OverflowPolicyBlock 时，在消息处理函数中向自身写入消息可能会产生死锁
+func (d *Dispatcher[P, M]) SetOverflowPolicy(limit int, policy OverflowPolicy, handler OverflowHandler[P, M]) *Dispatcher[P, M] {
+	d.lock.Lock()
+	d.limit, d.policy, d.overflowHandler = limit, policy, handler
+	d.cond.Broadcast()
+	d.lock.Unlock()
+	return d
+}
+
+// SetSizer 设置估算消息占用内存字节数的函数，设置后可通过 Bytes 获取缓冲区中等待处理的消息估算占用的内存
+//   - 应当在写入消息之前设置，否则已写入的消息将不会被计入
+func (d *Dispatcher[P, M]) SetSizer(sizer func(message M) int64) *Dispatcher[P, M] {
+	d.lock.Lock()
+	d.sizer = sizer
+	d.lock.Unlock()
+	return d
+}
+
+// sizeOf 估算消息占用的内存字节数
+func (d *Dispatcher[P, M]) sizeOf(message M) int64 {
+	if d.sizer == nil {
+		return 0
+	}
+	return d.sizer(message)
+}
+
+// overflowed 检查缓冲区是否已溢出，调用方需持有锁
+func (d *Dispatcher[P, M]) overflowed() bool {
+	return d.limit > 0 && d.queued >= d.limit
+}
+
+// onOverflow 执行缓冲区溢出时的处理函数
+func (d *Dispatcher[P, M]) onOverflow(handler OverflowHandler[P, M], policy OverflowPolicy, dropped M) {
+	if handler == nil {
+		return
+	}
+	defer func() {
+		if err := super.RecoverTransform(recover()); err != nil {
+			log.Error("Dispatcher.OverflowHandler", log.String("name", d.name), log.Err(err))
+		}
+	}()
+	handler(d, policy, dropped)
 }
 
 // SetProducerDoneHandler 设置特定生产者的所有消息处理完成时的回调函数
@@ -152,17 +214,108 @@ func (d *Dispatcher[P, M]) IncrCount(producer P, i int64) {
 }
 
 // Put 将消息放入分发器
+//   - 当缓冲区溢出时，将根据 SetOverflowPolicy 设置的策略进行处理
 func (d *Dispatcher[P, M]) Put(message M) {
 	d.lock.Lock()
-	d.mc++
-	d.pmc[message.GetProducer()]++
+	if !d.overflowed() {
+		d.noLockPut(message)
+		d.lock.Unlock()
+		d.buf.Write(message)
+		return
+	}
+
+	var zero M
+	policy, handler := d.policy, d.overflowHandler
+	switch policy {
+	case OverflowPolicyDropNew:
+		d.lock.Unlock()
+		d.onOverflow(handler, policy, message)
+		return
+	case OverflowPolicyDropOldest:
+		// 先计入新消息，避免丢弃最早的消息后计数归零导致消息分发器被驱逐
+		d.noLockPut(message)
+		select {
+		case oldest, ok := <-d.buf.Read():
+			if ok {
+				d.queued--
+				d.bytes -= d.sizeOf(oldest)
+				if d.skip > 0 {
+					d.skip--
+				}
+				d.noLockDone(oldest.GetProducer())
+				d.lock.Unlock()
+				d.buf.Write(message)
+				d.onOverflow(handler, policy, oldest)
+				return
+			}
+		default:
+		}
+		d.queued--
+		d.bytes -= d.sizeOf(message)
+		d.noLockDone(message.GetProducer())
+		d.lock.Unlock()
+		d.onOverflow(handler, policy, message)
+		return
+	case OverflowPolicyBlock:
+		d.lock.Unlock()
+		d.onOverflow(handler, policy, zero)
+		d.lock.Lock()
+		for d.overflowed() && d.policy == OverflowPolicyBlock && !d.buf.Closed() {
+			d.cond.Wait()
+		}
+	default:
+		d.lock.Unlock()
+		d.onOverflow(handler, policy, zero)
+		d.lock.Lock()
+	}
+	d.noLockPut(message)
 	d.lock.Unlock()
 	d.buf.Write(message)
 }
 
+// noLockPut 计入一条新消息，调用方需持有锁
+func (d *Dispatcher[P, M]) noLockPut(message M) {
+	d.queued++
+	d.bytes += d.sizeOf(message)
+	d.mc++
+	d.pmc[message.GetProducer()]++
+}
+
+// noLockDone 计出一条消息，当生产者的所有消息处理完成时将执行生产者的回调函数，调用方需持有锁
+func (d *Dispatcher[P, M]) noLockDone(p P) {
+	d.mc--
+	pmc := d.pmc[p] - 1
+	d.pmc[p] = pmc
+	if f := d.pmcF[p]; f != nil && pmc <= 0 {
+		func(producer P) {
+			defer func(producer P) {
+				if err := super.RecoverTransform(recover()); err != nil {
+					log.Error("Dispatcher.ProducerDoneHandler", log.Any("producer", producer), log.Err(err))
+				}
+			}(p)
+			f(p, &Action[P, M]{d: d, unlock: true})
+		}(p)
+	}
+}
+
+// exec 执行消息处理器，当 discard 不为 nil 时将改为执行 discard
+//   - 发生的 panic 将被记录，消息分发器将继续运行，以免分发协程退出后生产者被永久阻塞
+func (d *Dispatcher[P, M]) exec(discard func(message M), message M) {
+	defer func() {
+		if err := super.RecoverPanic(recover()); err != nil {
+			log.Error("Dispatcher", log.String("name", d.name), log.String("State", "Panic"), log.Err(err.Err), log.String("Stack", string(err.Stack)))
+		}
+	}()
+	if discard != nil {
+		discard(message)
+		return
+	}
+	d.handler(d, message)
+}
+
 // Start 以非阻塞的方式开始进行消息分发，当消息分发器中没有任何消息并且处于驱逐计划 Expel 时，将会自动关闭
 func (d *Dispatcher[P, M]) Start() *Dispatcher[P, M] {
-	go func(d *Dispatcher[P, M]) {
+	routines.Named(RoutineGroup).Go(func() {
 	process:
 		for {
 			select {
@@ -172,21 +325,19 @@ func (d *Dispatcher[P, M]) Start() *Dispatcher[P, M] {
 			case message := <-d.buf.Read():
 				// 先取出生产者信息，避免处理函数中将消息释放
 				p := message.GetProducer()
-				d.handler(d, message)
 				d.lock.Lock()
-				d.mc--
-				pmc := d.pmc[p] - 1
-				d.pmc[p] = pmc
-				if f := d.pmcF[p]; f != nil && pmc <= 0 {
-					func(producer P) {
-						defer func(producer P) {
-							if err := super.RecoverTransform(recover()); err != nil {
-								log.Error("Dispatcher.ProducerDoneHandler", log.Any("producer", producer), log.Err(err))
-							}
-						}(p)
-						f(p, &Action[P, M]{d: d, unlock: true})
-					}(p)
+				d.queued--
+				d.bytes -= d.sizeOf(message)
+				d.cond.Signal()
+				var discard func(message M)
+				if d.skip > 0 {
+					d.skip--
+					discard = d.discard
 				}
+				d.lock.Unlock()
+				d.exec(discard, message)
+				d.lock.Lock()
+				d.noLockDone(p)
 				if d.mc <= 0 && d.expel {
 					d.buf.Close()
 					d.lock.Unlock()
@@ -195,13 +346,41 @@ func (d *Dispatcher[P, M]) Start() *Dispatcher[P, M] {
 				d.lock.Unlock()
 			}
 		}
+		// 唤醒所有因缓冲区溢出而阻塞的生产者
+		d.lock.Lock()
+		d.cond.Broadcast()
+		d.lock.Unlock()
 		if ch := d.closedHandler.Load(); ch != nil {
 			(*ch)(&Action[P, M]{d: d, unlock: true})
 		}
-	}(d)
+	})
 	return d
 }
 
+// Restart 重启消息分发器，缓冲区中所有等待处理的消息将被丢弃，随后写入的消息将被正常处理
+//   - 被丢弃的消息将在被读取时交由 discard 处理，而不会执行消息处理器，discard 可用于还原消息的计数及释放消息
+//   - 返回将被丢弃的消息数量
+func (d *Dispatcher[P, M]) Restart(discard func(message M)) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.skip, d.discard = d.queued, discard
+	return d.skip
+}
+
+// Len 获取缓冲区中等待处理的消息数量
+func (d *Dispatcher[P, M]) Len() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.queued
+}
+
+// Bytes 获取缓冲区中等待处理的消息估算占用的内存字节数，未通过 SetSizer 设置估算函数时始终为 0
+func (d *Dispatcher[P, M]) Bytes() int64 {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.bytes
+}
+
 // Closed 判断消息分发器是否已关闭
 func (d *Dispatcher[P, M]) Closed() bool {
 	return d.buf.Closed()
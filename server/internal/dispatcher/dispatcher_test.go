@@ -1,6 +1,7 @@
 package dispatcher_test
 
 import (
+	"fmt"
 	"github.com/kercylan98/minotaur/server/internal/dispatcher"
 	"sync"
 	"sync/atomic"
@@ -289,6 +290,33 @@ func TestDispatcher_Start(t *testing.T) {
 	}
 }
 
+func TestDispatcher_StartPanic(t *testing.T) {
+	var handled atomic.Int32
+	var closed = make(chan struct{})
+	d := dispatcher.NewDispatcher(1024, t.Name(), func(dispatcher *dispatcher.Dispatcher[string, *TestMessage], message *TestMessage) {
+		handled.Add(1)
+		if message.v == 0 {
+			panic("handler panic")
+		}
+	})
+	d.SetClosedHandler(func(dispatcher *dispatcher.Action[string, *TestMessage]) {
+		close(closed)
+	})
+	d.Start()
+	for i := 0; i < 3; i++ {
+		d.Put(&TestMessage{producer: "producer", v: i})
+	}
+	d.Expel()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher stopped after handler panic")
+	}
+	if handled.Load() != 3 {
+		t.Fatalf("handled: %d", handled.Load())
+	}
+}
+
 func TestDispatcher_Name(t *testing.T) {
 	var cases = []struct {
 		name string
@@ -330,3 +358,90 @@ func TestDispatcher_Closed(t *testing.T) {
 		})
 	}
 }
+
+func TestDispatcher_SetOverflowPolicy(t *testing.T) {
+	var cases = []struct {
+		name    string
+		policy  dispatcher.OverflowPolicy
+		dropped int
+		handled []int
+	}{
+		{name: "TestDispatcher_SetOverflowPolicy_Expand", policy: dispatcher.OverflowPolicyExpand, dropped: 0, handled: []int{1, 2, 3}},
+		{name: "TestDispatcher_SetOverflowPolicy_DropNew", policy: dispatcher.OverflowPolicyDropNew, dropped: 3, handled: []int{1, 2}},
+		{name: "TestDispatcher_SetOverflowPolicy_DropOldest", policy: dispatcher.OverflowPolicyDropOldest, dropped: 2, handled: []int{1, 3}},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			var handled []int
+			var dropped int
+			started, release := make(chan struct{}), make(chan struct{})
+			w := new(sync.WaitGroup)
+			w.Add(len(c.handled))
+			d := dispatcher.NewDispatcher(1, c.name, func(dispatcher *dispatcher.Dispatcher[string, *TestMessage], message *TestMessage) {
+				if message.v == 1 {
+					close(started)
+					<-release
+				}
+				handled = append(handled, message.v)
+				w.Done()
+			}).SetOverflowPolicy(1, c.policy, func(dispatcher *dispatcher.Dispatcher[string, *TestMessage], policy dispatcher.OverflowPolicy, message *TestMessage) {
+				if message != nil {
+					dropped = message.v
+				}
+			}).Start()
+			d.Put(&TestMessage{producer: "producer", v: 1})
+			<-started
+			d.Put(&TestMessage{producer: "producer", v: 2})
+			time.Sleep(time.Millisecond * 10)
+			d.Put(&TestMessage{producer: "producer", v: 3})
+			close(release)
+			w.Wait()
+			d.Expel()
+			if dropped != c.dropped {
+				t.Errorf("%s dropped: %d, expect: %d", c.name, dropped, c.dropped)
+			}
+			if len(handled) != len(c.handled) {
+				t.Fatalf("%s handled: %v, expect: %v", c.name, handled, c.handled)
+			}
+			for i, v := range c.handled {
+				if handled[i] != v {
+					t.Fatalf("%s handled: %v, expect: %v", c.name, handled, c.handled)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_Restart(t *testing.T) {
+	var handled, discarded []int
+	started, release := make(chan struct{}), make(chan struct{})
+	w := new(sync.WaitGroup)
+	w.Add(4)
+	d := dispatcher.NewDispatcher(1024, "TestDispatcher_Restart", func(dispatcher *dispatcher.Dispatcher[string, *TestMessage], message *TestMessage) {
+		if message.v == 1 {
+			close(started)
+			<-release
+		}
+		handled = append(handled, message.v)
+		w.Done()
+	}).Start()
+	d.Put(&TestMessage{producer: "producer", v: 1})
+	<-started
+	d.Put(&TestMessage{producer: "producer", v: 2})
+	d.Put(&TestMessage{producer: "producer", v: 3})
+	if dropped := d.Restart(func(message *TestMessage) {
+		discarded = append(discarded, message.v)
+		w.Done()
+	}); dropped != 2 {
+		t.Fatalf("dropped: %d, expect: 2", dropped)
+	}
+	d.Put(&TestMessage{producer: "producer", v: 4})
+	close(release)
+	w.Wait()
+	d.Expel()
+	if fmt.Sprint(handled) != "[1 4]" || fmt.Sprint(discarded) != "[2 3]" {
+		t.Fatalf("handled: %v, discarded: %v", handled, discarded)
+	}
+}
@@ -2,6 +2,7 @@ package dispatcher
 
 import (
 	"sync"
+	"time"
 )
 
 const SystemName = "*system"
@@ -15,6 +16,7 @@ func NewManager[P Producer, M Message[P]](bufferSize int, handler Handler[P, M])
 		sys:         NewDispatcher(bufferSize, SystemName, handler),
 		curr:        make(map[P]*Dispatcher[P, M]),
 		size:        bufferSize,
+		releases:    make(map[string]*time.Timer),
 	}
 	mgr.sys.SetClosedHandler(func(dispatcher *Action[P, M]) {
 		mgr.w.Done()
@@ -33,13 +35,28 @@ type Manager[P Producer, M Message[P]] struct {
 	lock        sync.RWMutex                 // 消息分发器锁
 	w           sync.WaitGroup               // 消息分发器等待组
 	size        int                          // 消息分发器缓冲区大小
+	delay       time.Duration                // 消息分发器没有任何生产者后延迟释放的时长
+	releases    map[string]*time.Timer       // 等待延迟释放的消息分发器
+	closing     bool                         // 是否正在等待所有消息分发器关闭
 
 	closedHandler  func(name string)
 	createdHandler func(name string)
+	initializer    func(dispatcher *Dispatcher[P, M])
+	sizer          func(message M) int64
 }
 
-// Wait 等待所有消息分发器关闭
+// Wait 等待所有消息分发器关闭，等待延迟释放的消息分发器将被立即驱逐
 func (m *Manager[P, M]) Wait() {
+	m.lock.Lock()
+	m.closing = true
+	for name, timer := range m.releases {
+		timer.Stop()
+		delete(m.releases, name)
+		if d, exist := m.dispatchers[name]; exist && len(m.member[name]) == 0 {
+			d.Expel()
+		}
+	}
+	m.lock.Unlock()
 	m.w.Wait()
 	m.w.Add(1)
 	m.sys.Expel()
@@ -52,12 +69,48 @@ func (m *Manager[P, M]) SetDispatcherClosedHandler(handler func(name string)) *M
 	return m
 }
 
+// SetReleaseDelay 设置消息分发器在没有任何生产者后延迟释放的时长，默认为 0，即立即驱逐
+//   - 在延迟期间重新绑定的生产者将继续使用该消息分发器，其中尚未处理的消息不会丢失
+func (m *Manager[P, M]) SetReleaseDelay(delay time.Duration) *Manager[P, M] {
+	m.lock.Lock()
+	m.delay = delay
+	m.lock.Unlock()
+	return m
+}
+
 // SetDispatcherCreatedHandler 设置消息分发器创建时的回调函数
 func (m *Manager[P, M]) SetDispatcherCreatedHandler(handler func(name string)) *Manager[P, M] {
 	m.createdHandler = handler
 	return m
 }
 
+// SetDispatcherSizer 设置所有消息分发器估算消息占用内存字节数的函数，对系统消息分发器及之后创建的消息分发器生效
+//   - 应当在写入消息之前设置
+func (m *Manager[P, M]) SetDispatcherSizer(sizer func(message M) int64) *Manager[P, M] {
+	m.lock.Lock()
+	m.sizer = sizer
+	m.lock.Unlock()
+	m.sys.SetSizer(sizer)
+	return m
+}
+
+// SetDispatcherInitializer 设置消息分发器的初始化函数，该函数将在非系统消息分发器创建后、开始分发消息前被调用
+//   - 可在初始化函数中对特定名称的消息分发器进行配置，例如通过 Dispatcher.SetOverflowPolicy 设置缓冲区溢出策略
+func (m *Manager[P, M]) SetDispatcherInitializer(initializer func(dispatcher *Dispatcher[P, M])) *Manager[P, M] {
+	m.initializer = initializer
+	return m
+}
+
+// GetNamedDispatcher 获取指定名称的消息分发器，当不存在时将返回 nil
+func (m *Manager[P, M]) GetNamedDispatcher(name string) *Dispatcher[P, M] {
+	if name == SystemName {
+		return m.sys
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.dispatchers[name]
+}
+
 // HasDispatcher 检查是否存在指定名称的消息分发器
 func (m *Manager[P, M]) HasDispatcher(name string) bool {
 	m.lock.RLock()
@@ -73,6 +126,25 @@ func (m *Manager[P, M]) GetDispatcherNum() int {
 	return len(m.dispatchers) + 1 // +1 系统消息分发器
 }
 
+// GetDispatcherNames 获取当前正在工作的所有消息分发器名称，其中包含系统消息分发器
+func (m *Manager[P, M]) GetDispatcherNames() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	names := make([]string, 0, len(m.dispatchers)+1)
+	names = append(names, SystemName)
+	for name := range m.dispatchers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// GetProducerNum 获取正在使用特定消息分发器的生产者数量，系统消息分发器将始终返回 0
+func (m *Manager[P, M]) GetProducerNum(name string) int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return len(m.member[name])
+}
+
 // GetSystemDispatcher 获取系统消息分发器
 func (m *Manager[P, M]) GetSystemDispatcher() *Dispatcher[P, M] {
 	return m.sys
@@ -80,8 +152,8 @@ func (m *Manager[P, M]) GetSystemDispatcher() *Dispatcher[P, M] {
 
 // GetDispatcher 获取生产者正在使用的消息分发器，如果生产者没有绑定消息分发器，则会返回系统消息分发器
 func (m *Manager[P, M]) GetDispatcher(p P) *Dispatcher[P, M] {
-	m.lock.Lock()
-	defer m.lock.Unlock()
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 
 	curr, exist := m.curr[p]
 	if exist {
@@ -105,6 +177,7 @@ func (m *Manager[P, M]) BindProducer(p P, name string) {
 	}
 
 	if _, exist = member[p]; exist {
+		m.cancelRelease(name)
 		d := m.dispatchers[name]
 		d.SetProducerDoneHandler(p, nil)
 		d.UnExpel()
@@ -116,14 +189,18 @@ func (m *Manager[P, M]) BindProducer(p P, name string) {
 	if exist {
 		delete(m.member[curr.name], p)
 		if len(m.member[curr.name]) == 0 {
-			curr.Expel()
+			m.release(curr, curr.Expel)
 		}
 	}
 
 	dispatcher, exist := m.dispatchers[name]
 	if !exist {
 		m.w.Add(1)
-		dispatcher = NewDispatcher(m.size, name, m.handler).SetClosedHandler(func(dispatcher *Action[P, M]) {
+		dispatcher = NewDispatcher(m.size, name, m.handler).SetSizer(m.sizer)
+		if m.initializer != nil {
+			m.initializer(dispatcher)
+		}
+		dispatcher.SetClosedHandler(func(dispatcher *Action[P, M]) {
 			// 消息分发器关闭时，将会将其从管理器中移除
 			m.lock.Lock()
 			delete(m.dispatchers, dispatcher.Name())
@@ -141,6 +218,7 @@ func (m *Manager[P, M]) BindProducer(p P, name string) {
 			}
 		}(m, dispatcher.Name())
 	}
+	m.cancelRelease(name)
 	m.curr[p] = dispatcher
 	member[p] = struct{}{}
 }
@@ -160,7 +238,38 @@ func (m *Manager[P, M]) UnBindProducer(p P) {
 		delete(m.member[dispatcher.Name()], p)
 		delete(m.curr, p)
 		if len(m.member[dispatcher.Name()]) == 0 {
-			dispatcher.Expel()
+			m.release(dispatcher.d, dispatcher.Expel)
 		}
 	})
 }
+
+// release 在消息分发器没有任何生产者时通过 expel 驱逐消息分发器，当设置了 SetReleaseDelay 时将在延迟后驱逐，需要在持有 m.lock 时调用
+func (m *Manager[P, M]) release(d *Dispatcher[P, M], expel func()) {
+	if m.delay <= 0 || m.closing {
+		expel()
+		return
+	}
+	name := d.Name()
+	m.cancelRelease(name)
+	var timer *time.Timer
+	timer = time.AfterFunc(m.delay, func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if m.releases[name] != timer {
+			return
+		}
+		delete(m.releases, name)
+		if len(m.member[name]) == 0 {
+			d.Expel()
+		}
+	})
+	m.releases[name] = timer
+}
+
+// cancelRelease 取消消息分发器的延迟释放，需要在持有 m.lock 时调用
+func (m *Manager[P, M]) cancelRelease(name string) {
+	if timer, exist := m.releases[name]; exist {
+		timer.Stop()
+		delete(m.releases, name)
+	}
+}
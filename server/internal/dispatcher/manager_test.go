@@ -5,6 +5,7 @@ import (
 	"github.com/kercylan98/minotaur/server/internal/dispatcher"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewManager(t *testing.T) {
@@ -223,3 +224,30 @@ func TestManager_UnBindProducer(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_SetReleaseDelay(t *testing.T) {
+	var closed atomic.Int32
+	m := dispatcher.NewManager[string, *TestMessage](1024, func(dispatcher *dispatcher.Dispatcher[string, *TestMessage], message *TestMessage) {})
+	m.SetReleaseDelay(time.Millisecond * 100).SetDispatcherClosedHandler(func(name string) {
+		closed.Add(1)
+	})
+
+	m.BindProducer("a", "room")
+	m.UnBindProducer("a")
+	time.Sleep(time.Millisecond * 20)
+	if !m.HasDispatcher("room") {
+		t.Fatalf("dispatcher should be kept during release delay")
+	}
+
+	m.BindProducer("b", "room")
+	time.Sleep(time.Millisecond * 150)
+	if !m.HasDispatcher("room") || closed.Load() != 0 {
+		t.Fatalf("dispatcher should not be released after producer rejoined")
+	}
+
+	m.UnBindProducer("b")
+	time.Sleep(time.Millisecond * 200)
+	if m.HasDispatcher("room") || closed.Load() != 1 {
+		t.Fatalf("dispatcher should be released after release delay")
+	}
+}
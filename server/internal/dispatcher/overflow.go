@@ -0,0 +1,20 @@
+package dispatcher
+
+const (
+	// OverflowPolicyExpand 扩容缓冲区，消息将继续写入，这是消息分发器的默认策略
+	OverflowPolicyExpand OverflowPolicy = iota
+	// OverflowPolicyBlock 阻塞生产者，直到缓冲区存在可用空间
+	OverflowPolicyBlock
+	// OverflowPolicyDropOldest 丢弃缓冲区中最早的消息，当最早的消息已被取出处理时，将丢弃新消息
+	OverflowPolicyDropOldest
+	// OverflowPolicyDropNew 丢弃新消息
+	OverflowPolicyDropNew
+)
+
+// OverflowPolicy 消息分发器缓冲区溢出策略
+type OverflowPolicy byte
+
+// OverflowHandler 消息分发器缓冲区溢出时的处理函数
+//   - 当策略为 OverflowPolicyDropOldest 或 OverflowPolicyDropNew 时，dropped 为被丢弃的消息，否则为零值
+//   - 处理函数执行时不会持有消息分发器的锁
+type OverflowHandler[P Producer, M Message[P]] func(dispatcher *Dispatcher[P, M], policy OverflowPolicy, dropped M)
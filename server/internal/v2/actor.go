@@ -0,0 +1,334 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrActorExists  = errors.New("server: actor already exists")
+	ErrActorStopped = errors.New("server: actor stopped")
+	ErrActorPanic   = errors.New("server: actor panic")
+)
+
+type (
+	// ActorHandler Actor 消息处理函数，同一 Actor 的消息将在其邮箱中串行处理
+	ActorHandler func(ctx *ActorContext)
+	// ActorProducer Actor 处理函数的生产者，在 Actor 创建及因 panic 重启时将重新生产处理函数，以丢弃可能已被破坏的状态
+	ActorProducer func() ActorHandler
+)
+
+// Actor 拥有独立邮箱的处理单元，是对通过 Conn.SetQueue 指定消息队列这一模式的正式化
+//   - 每个 Actor 的邮箱即为以其名称命名的消息队列，通过 conn.SetQueue(actor.GetName()) 可使连接的数据包与该 Actor 的消息串行处理
+type Actor interface {
+	// GetName 获取 Actor 名称，即邮箱的消息队列名称
+	GetName() string
+
+	// Tell 向 Actor 的邮箱投递一条消息，不等待处理结果
+	Tell(message any) error
+
+	// Ask 向 Actor 的邮箱投递一条消息，并等待通过 ActorContext.Respond 回复的结果
+	//  - 当处理函数未进行回复时，将在处理完毕后返回 nil
+	//  - 当处理函数发生 panic 时，将返回 ErrActorPanic
+	//  - 由于消息队列可能共享同一处理协程，不应在任何消息处理函数中同步调用 Ask，否则可能产生死锁
+	Ask(ctx context.Context, message any) (any, error)
+
+	// Stop 停止 Actor，停止后邮箱中尚未处理的消息将被丢弃
+	Stop()
+
+	// IsStopped 检查 Actor 是否已停止
+	IsStopped() bool
+
+	// GetRestarts 获取 Actor 因 panic 重启的次数
+	GetRestarts() int
+}
+
+// ActorContext Actor 消息处理上下文
+type ActorContext struct {
+	context.Context
+	actor   *actor
+	message any
+	reply   chan actorReply
+	replied bool
+	seq     uint64 // 日志序号，未启用日志时为 0
+	replay  bool   // 是否为重放的消息
+}
+
+// GetServer 获取 Actor 所属的服务器
+func (c *ActorContext) GetServer() Server {
+	return c.actor.srv
+}
+
+// GetActor 获取正在处理消息的 Actor
+func (c *ActorContext) GetActor() Actor {
+	return c.actor
+}
+
+// GetMessage 获取正在处理的消息
+func (c *ActorContext) GetMessage() any {
+	return c.message
+}
+
+// IsReplay 检查当前消息是否为 Actor 创建时从日志中重放的消息
+func (c *ActorContext) IsReplay() bool {
+	return c.replay
+}
+
+// IsAsk 检查当前消息是否通过 Ask 投递
+func (c *ActorContext) IsAsk() bool {
+	return c.reply != nil
+}
+
+// Respond 回复通过 Ask 投递的消息，重复回复或回复通过 Tell 投递的消息时将被忽略
+func (c *ActorContext) Respond(reply any) {
+	c.respond(reply, nil)
+}
+
+func (c *ActorContext) respond(reply any, err error) {
+	if c.reply == nil || c.replied {
+		return
+	}
+	c.replied = true
+	c.reply <- actorReply{reply: reply, err: err}
+}
+
+type actorReply struct {
+	reply any
+	err   error
+}
+
+// NewActorOptions 创建 Actor 可选项
+func NewActorOptions() *ActorOptions {
+	return &ActorOptions{
+		maxRestarts:   10,
+		restartWindow: time.Minute,
+	}
+}
+
+// ActorOptions Actor 可选项
+type ActorOptions struct {
+	maxRestarts   int           // 时间窗口内允许的最大重启次数
+	restartWindow time.Duration // 重启次数统计的时间窗口
+	journal       ActorJournal  // 消息日志
+}
+
+// WithSupervisor 设置 Actor 的监督策略，当 Actor 在 window 时间内因 panic 重启的次数超过 maxRestarts 时，Actor 将被停止
+//   - 默认在 1 分钟内最多重启 10 次
+//   - 当 maxRestarts < 0 时，Actor 将始终重启
+func (o *ActorOptions) WithSupervisor(maxRestarts int, window time.Duration) *ActorOptions {
+	o.maxRestarts = maxRestarts
+	o.restartWindow = window
+	return o
+}
+
+// WithJournal 设置 Actor 的消息日志，投递到邮箱的消息将先写入日志，并在处理完毕后确认
+//   - 在创建 Actor 时，日志中尚未确认的消息将在其他消息之前被重放，处理函数应当是幂等的
+//   - 适用于不允许丢失已排队指令的场景，例如服务器崩溃后恢复房间内尚未处理的玩家指令
+func (o *ActorOptions) WithJournal(journal ActorJournal) *ActorOptions {
+	o.journal = journal
+	return o
+}
+
+type actor struct {
+	srv      *server
+	name     string
+	producer ActorProducer
+	options  *ActorOptions
+	handler  ActorHandler
+	stopped  atomic.Bool
+	restarts atomic.Int64
+	failures []time.Time
+}
+
+func (a *actor) GetName() string {
+	return a.name
+}
+
+func (a *actor) Tell(message any) error {
+	if a.IsStopped() {
+		return ErrActorStopped
+	}
+	seq, err := a.journal(message)
+	if err != nil {
+		return err
+	}
+	a.srv.PublishSyncMessage(a.name, func(ctx context.Context) {
+		a.process(&ActorContext{Context: ctx, actor: a, message: message, seq: seq})
+	})
+	return nil
+}
+
+func (a *actor) Ask(ctx context.Context, message any) (any, error) {
+	if a.IsStopped() {
+		return nil, ErrActorStopped
+	}
+	seq, err := a.journal(message)
+	if err != nil {
+		return nil, err
+	}
+	reply := make(chan actorReply, 1)
+	a.srv.PublishSyncMessage(a.name, func(c context.Context) {
+		a.process(&ActorContext{Context: c, actor: a, message: message, reply: reply, seq: seq})
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-reply:
+		return r.reply, r.err
+	}
+}
+
+// journal 在启用了消息日志时将消息写入日志
+func (a *actor) journal(message any) (seq uint64, err error) {
+	if a.options.journal == nil {
+		return 0, nil
+	}
+	return a.options.journal.Append(a.name, message)
+}
+
+// replay 重放日志中尚未确认的消息
+func (a *actor) replay() error {
+	if a.options.journal == nil {
+		return nil
+	}
+	entries, err := a.options.journal.Pending(a.name)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entry := entry
+		a.srv.PublishSyncMessage(a.name, func(ctx context.Context) {
+			a.process(&ActorContext{Context: ctx, actor: a, message: entry.Message, seq: entry.Seq, replay: true})
+		})
+	}
+	return nil
+}
+
+func (a *actor) Stop() {
+	if a.stopped.CompareAndSwap(false, true) {
+		a.srv.deleteActor(a.name)
+	}
+}
+
+func (a *actor) IsStopped() bool {
+	return a.stopped.Load()
+}
+
+func (a *actor) GetRestarts() int {
+	return int(a.restarts.Load())
+}
+
+// process 在 Actor 的邮箱中处理消息，发生 panic 时将根据监督策略重启或停止 Actor
+//   - 启用了消息日志时，无论处理是否发生 panic，消息都将被确认，以避免重放导致 panic 的消息
+func (a *actor) process(ctx *ActorContext) {
+	if a.IsStopped() {
+		ctx.respond(nil, ErrActorStopped)
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("%w: %v", ErrActorPanic, r)
+			ctx.respond(nil, err)
+			a.commit(ctx.seq)
+			a.supervise(err)
+			return
+		}
+		a.commit(ctx.seq)
+		ctx.respond(nil, nil)
+	}()
+	a.handler(ctx)
+}
+
+// commit 在启用了消息日志时确认消息已处理
+func (a *actor) commit(seq uint64) {
+	if a.options.journal == nil {
+		return
+	}
+	if err := a.options.journal.Commit(a.name, seq); err != nil {
+		a.srv.GetLogger().Error("Actor", log.String("name", a.name), log.String("state", "commit"), log.Err(err))
+	}
+}
+
+// supervise 根据监督策略重启或停止 Actor
+func (a *actor) supervise(err error) {
+	now := time.Now()
+	if a.options.maxRestarts >= 0 {
+		var failures = a.failures[:0]
+		for _, t := range a.failures {
+			if now.Sub(t) < a.options.restartWindow {
+				failures = append(failures, t)
+			}
+		}
+		a.failures = append(failures, now)
+		if len(a.failures) > a.options.maxRestarts {
+			a.Stop()
+			a.srv.GetLogger().Error("Actor", log.String("name", a.name), log.String("state", "stopped"), log.Err(err))
+			return
+		}
+	}
+	a.handler = a.producer()
+	a.restarts.Add(1)
+	a.srv.GetLogger().Warn("Actor", log.String("name", a.name), log.String("state", "restarted"), log.Err(err))
+}
+
+// actors Actor 注册表
+type actors struct {
+	srv    *server
+	lock   sync.RWMutex
+	actors map[string]*actor
+}
+
+func (s *actors) init(srv *server) *actors {
+	s.srv = srv
+	s.actors = make(map[string]*actor)
+	return s
+}
+
+// SpawnActor 创建一个以 name 命名的 Actor，name 同时作为其邮箱的消息队列名称
+//   - 当启用了消息日志时，将重放日志中尚未确认的消息，读取日志失败时将返回错误
+func (s *actors) SpawnActor(name string, producer ActorProducer, options ...*ActorOptions) (Actor, error) {
+	opts := NewActorOptions()
+	if len(options) > 0 && options[0] != nil {
+		opts = options[0]
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, exist := s.actors[name]; exist {
+		return nil, ErrActorExists
+	}
+	a := &actor{
+		srv:      s.srv,
+		name:     name,
+		producer: producer,
+		options:  opts,
+		handler:  producer(),
+	}
+	if err := a.replay(); err != nil {
+		return nil, err
+	}
+	s.actors[name] = a
+	return a, nil
+}
+
+// GetActor 获取特定名称的 Actor
+func (s *actors) GetActor(name string) (Actor, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	a, exist := s.actors[name]
+	if !exist {
+		return nil, false
+	}
+	return a, true
+}
+
+// deleteActor 删除特定名称的 Actor
+func (s *actors) deleteActor(name string) {
+	s.lock.Lock()
+	delete(s.actors, name)
+	s.lock.Unlock()
+}
@@ -0,0 +1,22 @@
+package server
+
+// ActorJournalEntry Actor 消息日志中的一条记录
+type ActorJournalEntry struct {
+	Seq     uint64 // 日志序号
+	Message any    // 消息
+}
+
+// ActorJournal Actor 消息日志，以预写日志的方式记录投递到 Actor 邮箱的消息，以便在崩溃后重放尚未处理的消息
+//   - 实现应当将消息持久化，并在 Pending 中按写入顺序返回尚未确认的消息
+//   - 消息的序列化由实现负责，重放时 Message 应当能够被 Actor 的处理函数识别
+//   - 所有函数都可能被并发调用
+type ActorJournal interface {
+	// Append 在消息投递到邮箱前写入日志，返回该消息的日志序号，当返回错误时消息将不会被投递
+	Append(actor string, message any) (seq uint64, err error)
+
+	// Commit 在消息处理完毕后确认日志
+	Commit(actor string, seq uint64) error
+
+	// Pending 按写入顺序获取特定 Actor 尚未确认的日志
+	Pending(actor string) ([]ActorJournalEntry, error)
+}
@@ -0,0 +1,146 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/network"
+	"github.com/kercylan98/minotaur/utils/random"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServer_SpawnActor(t *testing.T) {
+	srv := server.NewServer(network.Http(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())), server.NewOptions().WithLifeCycleLimit(time.Second))
+
+	var restarts int
+	srv.RegisterLaunchedEvent(func(srv server.Server, ip string, launchedAt time.Time) {
+		actor, err := srv.SpawnActor("counter", func() server.ActorHandler {
+			var count int
+			return func(ctx *server.ActorContext) {
+				switch ctx.GetMessage() {
+				case "incr":
+					count++
+				case "panic":
+					panic("boom")
+				case "get":
+					ctx.Respond(count)
+				}
+			}
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err = srv.SpawnActor("counter", nil); err != server.ErrActorExists {
+			t.Errorf("expected ErrActorExists, got %v", err)
+		}
+
+		go func() {
+			ctx := context.Background()
+			for i := 0; i < 3; i++ {
+				_ = actor.Tell("incr")
+			}
+			if count, err := actor.Ask(ctx, "get"); err != nil || count != 3 {
+				t.Errorf("count: %v, err: %v", count, err)
+			}
+			if _, err := actor.Ask(ctx, "panic"); err == nil {
+				t.Error("expected panic error")
+			}
+			if count, err := actor.Ask(ctx, "get"); err != nil || count != 0 {
+				t.Errorf("count after restart: %v, err: %v", count, err)
+			}
+			restarts = actor.GetRestarts()
+			actor.Stop()
+			if err := actor.Tell("incr"); err != server.ErrActorStopped {
+				t.Errorf("expected ErrActorStopped, got %v", err)
+			}
+		}()
+	})
+
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if restarts != 1 {
+		t.Fatalf("restarts: %d", restarts)
+	}
+}
+
+type memoryActorJournal struct {
+	sync.Mutex
+	seq     uint64
+	entries []server.ActorJournalEntry
+}
+
+func (j *memoryActorJournal) Append(actor string, message any) (uint64, error) {
+	j.Lock()
+	defer j.Unlock()
+	j.seq++
+	j.entries = append(j.entries, server.ActorJournalEntry{Seq: j.seq, Message: message})
+	return j.seq, nil
+}
+
+func (j *memoryActorJournal) Commit(actor string, seq uint64) error {
+	j.Lock()
+	defer j.Unlock()
+	for i, entry := range j.entries {
+		if entry.Seq == seq {
+			j.entries = append(j.entries[:i], j.entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (j *memoryActorJournal) Pending(actor string) ([]server.ActorJournalEntry, error) {
+	j.Lock()
+	defer j.Unlock()
+	return append([]server.ActorJournalEntry(nil), j.entries...), nil
+}
+
+func TestActorOptions_WithJournal(t *testing.T) {
+	srv := server.NewServer(network.Http(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())), server.NewOptions().WithLifeCycleLimit(time.Second))
+
+	// 模拟崩溃前尚未处理的消息
+	journal := new(memoryActorJournal)
+	_, _ = journal.Append("room", 1)
+	_, _ = journal.Append("room", 2)
+
+	var sum, replayed int
+	srv.RegisterLaunchedEvent(func(srv server.Server, ip string, launchedAt time.Time) {
+		actor, err := srv.SpawnActor("room", func() server.ActorHandler {
+			return func(ctx *server.ActorContext) {
+				if ctx.IsReplay() {
+					replayed++
+				}
+				switch message := ctx.GetMessage().(type) {
+				case int:
+					sum += message
+				case string:
+					ctx.Respond(sum)
+				}
+			}
+		}, server.NewActorOptions().WithJournal(journal))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			_ = actor.Tell(3)
+			if _, err := actor.Ask(context.Background(), "sum"); err != nil {
+				t.Error(err)
+			}
+		}()
+	})
+
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 6 || replayed != 2 {
+		t.Fatalf("sum: %d, replayed: %d", sum, replayed)
+	}
+	if pending, _ := journal.Pending("room"); len(pending) != 0 {
+		t.Fatalf("pending: %d", len(pending))
+	}
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"github.com/kercylan98/minotaur/utils/log/v2"
 	"go.uber.org/atomic"
 	"net"
 )
@@ -9,6 +11,9 @@ import (
 type ConnWriter func(packet Packet) error
 
 type Conn interface {
+	// GetID 获取连接 ID，连接 ID 为连接的远端地址
+	GetID() string
+
 	// SetQueue 设置连接使用的消息队列名称
 	SetQueue(queue string)
 
@@ -29,13 +34,23 @@ type Conn interface {
 
 	// WriteContext 写入数据
 	WriteContext(data []byte, context interface{}) error
+
+	// GetContext 获取连接的上下文，上下文中注入了连接 ID 日志字段，可通过 log.WithContext 记录可按连接检索的日志
+	GetContext() context.Context
+
+	// Close 关闭连接，err 将作为 ConnectionClosedEvent 事件的关闭原因
+	//  - 多次调用时仅首次传入的原因生效
+	Close(err error) error
 }
 
 func newConn(srv *server, c net.Conn, connWriter ConnWriter) *conn {
+	id := c.RemoteAddr().String()
 	return &conn{
 		server: srv,
 		conn:   c,
 		writer: connWriter,
+		id:     id,
+		ctx:    log.ContextWithConnID(srv.ctx, id),
 	}
 }
 
@@ -43,7 +58,14 @@ type conn struct {
 	server *server
 	conn   net.Conn               // 连接
 	writer ConnWriter             // 写入器
+	id     string                 // 连接 ID
 	queue  atomic.Pointer[string] // Actor 名称
+	ctx    context.Context        // 上下文
+	reason atomic.Error           // 主动关闭连接的原因
+}
+
+func (c *conn) GetID() string {
+	return c.id
 }
 
 func (c *conn) SetQueue(queue string) {
@@ -63,18 +85,30 @@ func (c *conn) GetQueue() string {
 }
 
 func (c *conn) WritePacket(packet Packet) error {
-	return c.writer(packet)
+	return c.writer(c.server.events.onConnectionWritePacketBefore(c, packet))
 }
 
+// Write 写入数据，当 ConnectionWritePacketBeforeEvent 事件修改了数据包时，返回的 n 为实际写入的数据长度
 func (c *conn) Write(data []byte) (n int, err error) {
-	return c.conn.Write(data)
+	return c.conn.Write(c.server.events.onConnectionWritePacketBefore(c, NewPacket(data)).GetBytes())
 }
 
 func (c *conn) WriteBytes(data []byte) error {
-	_, err := c.conn.Write(data)
+	_, err := c.Write(data)
 	return err
 }
 
 func (c *conn) WriteContext(data []byte, context interface{}) error {
-	return c.writer(NewPacket(data).SetContext(context))
+	return c.WritePacket(NewPacket(data).SetContext(context))
+}
+
+func (c *conn) Close(err error) error {
+	if err != nil {
+		c.reason.CompareAndSwap(nil, err)
+	}
+	return c.conn.Close()
+}
+
+func (c *conn) GetContext() context.Context {
+	return c.ctx
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/panjf2000/ants/v2"
 	"net"
+	"sync"
 )
 
 // Controller 控制器是暴露 Server 对用户非公开的接口信息，适用于功能性的拓展
@@ -22,12 +23,15 @@ type Controller interface {
 
 type controller struct {
 	*server
-	connections map[net.Conn]*conn
+	connections map[net.Conn]*conn // 所有连接
+	online      map[string]*conn   // 以连接 ID 索引的在线连接
+	onlineRW    sync.RWMutex       // 连接读写锁
 }
 
 func (s *controller) init(srv *server) *controller {
 	s.server = srv
 	s.connections = make(map[net.Conn]*conn)
+	s.online = make(map[string]*conn)
 	return s
 }
 
@@ -42,25 +46,40 @@ func (s *controller) GetAnts() *ants.Pool {
 func (s *controller) RegisterConnection(conn net.Conn, writer ConnWriter) {
 	s.server.PublishSyncMessage(s.getSysQueue(), func(ctx context.Context) {
 		c := newConn(s.server, conn, writer)
-		s.server.connections[conn] = c
+		s.onlineRW.Lock()
+		s.connections[conn] = c
+		s.online[c.GetID()] = c
+		s.onlineRW.Unlock()
 		s.events.onConnectionOpened(c)
 	})
 }
 
 func (s *controller) EliminateConnection(conn net.Conn, err error) {
 	s.server.PublishSyncMessage(s.getSysQueue(), func(ctx context.Context) {
-		c, exist := s.server.connections[conn]
+		s.onlineRW.Lock()
+		c, exist := s.connections[conn]
+		if exist {
+			delete(s.connections, conn)
+			if s.online[c.GetID()] == c {
+				delete(s.online, c.GetID())
+			}
+		}
+		s.onlineRW.Unlock()
 		if !exist {
 			return
 		}
-		delete(s.server.connections, conn)
+		if reason := c.reason.Load(); reason != nil {
+			err = reason
+		}
 		s.server.events.onConnectionClosed(c, err)
 	})
 }
 
 func (s *controller) ReactPacket(conn net.Conn, packet Packet) {
 	s.server.PublishSyncMessage(s.getSysQueue(), func(ctx context.Context) {
-		c, exist := s.server.connections[conn]
+		s.onlineRW.RLock()
+		c, exist := s.connections[conn]
+		s.onlineRW.RUnlock()
 		if !exist {
 			return
 		}
@@ -11,11 +11,14 @@ import (
 )
 
 type (
-	LaunchedEventHandler                func(srv Server, ip string, t time.Time)
-	ShutdownEventHandler                func(srv Server)
-	ConnectionOpenedEventHandler        func(srv Server, conn Conn)
-	ConnectionClosedEventHandler        func(srv Server, conn Conn, err error)
-	ConnectionReceivePacketEventHandler func(srv Server, conn Conn, packet Packet)
+	LaunchedEventHandler                    func(srv Server, ip string, t time.Time)
+	ShutdownEventHandler                    func(srv Server)
+	StopEventHandler                        func(srv Server)
+	ConnectionOpenedEventHandler            func(srv Server, conn Conn)
+	ConnectionClosedEventHandler            func(srv Server, conn Conn, err error)
+	ConnectionPacketPreprocessEventHandler  func(srv Server, conn Conn, packet Packet, abort func(), usePacket func(newPacket Packet))
+	ConnectionReceivePacketEventHandler     func(srv Server, conn Conn, packet Packet)
+	ConnectionWritePacketBeforeEventHandler func(srv Server, conn Conn, packet Packet) Packet
 )
 
 type Events interface {
@@ -28,31 +31,58 @@ type Events interface {
 	//  - 该事件未执行完毕前，服务器的一切均正常运行
 	RegisterShutdownEvent(handler ShutdownEventHandler, priority ...int)
 
+	// RegisterStopEvent 注册服务器停止事件，当服务器的网络及消息队列均已关闭后将会触发该事件
+	//  - 该事件将在调用 Shutdown 的协程中运行，此时已无法发布消息
+	//  - 当关闭超时时，该事件依旧会被触发
+	RegisterStopEvent(handler StopEventHandler, priority ...int)
+
 	// RegisterConnectionOpenedEvent 注册连接打开事件，当新连接创建完毕时将会触发该事件
 	//  - 该事件将在系统级 Actor 中运行，不应执行阻塞操作
 	RegisterConnectionOpenedEvent(handler ConnectionOpenedEventHandler, priority ...int)
 
 	// RegisterConnectionClosedEvent 注册连接关闭事件，当连接关闭后将会触发该事件
 	//  - 该事件将在系统级 Actor 中运行，不应执行阻塞操作
+	//  - 当连接通过 Conn.Close 关闭时，err 为调用时传入的原因，否则为网络层报告的错误
 	RegisterConnectionClosedEvent(handler ConnectionClosedEventHandler, priority ...int)
 
+	// RegisterConnectionPacketPreprocessEvent 注册连接数据包预处理事件，该事件将在数据包中间件及 ConnectionReceivePacketEvent 事件之前触发
+	//  - 调用 abort 将中断数据包的后续处理，调用 usePacket 将使用新的数据包进行后续处理
+	//  - 该事件将在连接的 Actor 中运行，不应执行阻塞操作
+	RegisterConnectionPacketPreprocessEvent(handler ConnectionPacketPreprocessEventHandler, priority ...int)
+
 	// RegisterConnectionReceivePacketEvent 注册连接接收数据包事件，当连接接收到数据包后将会触发该事件
 	//  - 该事件将在连接的 Actor 中运行，不应执行阻塞操作
 	RegisterConnectionReceivePacketEvent(handler ConnectionReceivePacketEventHandler, priority ...int)
+
+	// RegisterConnectionWritePacketBeforeEvent 注册连接写入数据包前事件，当连接写入数据包前将会触发该事件，返回的数据包将作为实际写入的数据包
+	//  - 该事件将在调用写入函数的协程中运行，不应执行阻塞操作
+	RegisterConnectionWritePacketBeforeEvent(handler ConnectionWritePacketBeforeEventHandler, priority ...int)
+
+	// UsePacketMiddleware 注册数据包中间件，中间件将在连接的 Actor 中围绕 ConnectionReceivePacketEvent 事件执行
+	//  - 中间件按照注册顺序由外至内执行，即首个注册的中间件最先执行
+	//  - 该函数应在服务器运行前调用
+	UsePacketMiddleware(middlewares ...PacketMiddleware)
 }
 
 type events struct {
 	*server
 
-	launchedEventHandlers                listings.SyncPrioritySlice[LaunchedEventHandler]
-	shutdownEventHandlers                listings.SyncPrioritySlice[ShutdownEventHandler]
-	connectionOpenedEventHandlers        listings.SyncPrioritySlice[ConnectionOpenedEventHandler]
-	connectionClosedEventHandlers        listings.SyncPrioritySlice[ConnectionClosedEventHandler]
-	connectionReceivePacketEventHandlers listings.SyncPrioritySlice[ConnectionReceivePacketEventHandler]
+	launchedEventHandlers                    listings.SyncPrioritySlice[LaunchedEventHandler]
+	shutdownEventHandlers                    listings.SyncPrioritySlice[ShutdownEventHandler]
+	stopEventHandlers                        listings.SyncPrioritySlice[StopEventHandler]
+	connectionOpenedEventHandlers            listings.SyncPrioritySlice[ConnectionOpenedEventHandler]
+	connectionClosedEventHandlers            listings.SyncPrioritySlice[ConnectionClosedEventHandler]
+	connectionPacketPreprocessEventHandlers  listings.SyncPrioritySlice[ConnectionPacketPreprocessEventHandler]
+	connectionReceivePacketEventHandlers     listings.SyncPrioritySlice[ConnectionReceivePacketEventHandler]
+	connectionWritePacketBeforeEventHandlers listings.SyncPrioritySlice[ConnectionWritePacketBeforeEventHandler]
+
+	packetMiddlewares []PacketMiddleware // 数据包中间件
+	packetHandler     PacketHandler      // 经过中间件包装的数据包处理函数
 }
 
 func (s *events) init(srv *server) *events {
 	s.server = srv
+	s.packetHandler = s.receivePacket
 	return s
 }
 
@@ -105,13 +135,41 @@ func (s *events) RegisterConnectionReceivePacketEvent(handler ConnectionReceiveP
 	s.connectionReceivePacketEventHandlers.AppendByOptionalPriority(handler, priority...)
 }
 
+func (s *events) RegisterConnectionPacketPreprocessEvent(handler ConnectionPacketPreprocessEventHandler, priority ...int) {
+	s.connectionPacketPreprocessEventHandlers.AppendByOptionalPriority(handler, priority...)
+}
+
+// onConnectionPacketPreprocess 触发 ConnectionPacketPreprocessEvent 事件，返回经过预处理的数据包及是否中断后续处理
+func (s *events) onConnectionPacketPreprocess(conn *conn, packet Packet) (Packet, bool) {
+	var abort bool
+	s.connectionPacketPreprocessEventHandlers.RangeValue(func(index int, value ConnectionPacketPreprocessEventHandler) bool {
+		value(s, conn, packet, func() { abort = true }, func(newPacket Packet) { packet = newPacket })
+		return !abort
+	})
+	return packet, abort
+}
+
 func (s *events) onConnectionReceivePacket(conn *conn, packet Packet) {
 	s.PublishSyncMessage(conn.GetQueue(), func(ctx context.Context) {
-		s.connectionReceivePacketEventHandlers.RangeValue(func(index int, value ConnectionReceivePacketEventHandler) bool {
-			value(s, conn, packet)
-			return true
-		})
+		packet, abort := s.onConnectionPacketPreprocess(conn, packet)
+		if abort {
+			return
+		}
+		s.packetHandler(s, conn, packet)
+	})
+}
+
+func (s *events) RegisterConnectionWritePacketBeforeEvent(handler ConnectionWritePacketBeforeEventHandler, priority ...int) {
+	s.connectionWritePacketBeforeEventHandlers.AppendByOptionalPriority(handler, priority...)
+}
+
+// onConnectionWritePacketBefore 触发 ConnectionWritePacketBeforeEvent 事件，返回实际写入的数据包
+func (s *events) onConnectionWritePacketBefore(conn *conn, packet Packet) Packet {
+	s.connectionWritePacketBeforeEventHandlers.RangeValue(func(index int, value ConnectionWritePacketBeforeEventHandler) bool {
+		packet = value(s, conn, packet)
+		return true
 	})
+	return packet
 }
 
 func (s *events) RegisterShutdownEvent(handler ShutdownEventHandler, priority ...int) {
@@ -126,3 +184,14 @@ func (s *events) onShutdown() {
 		})
 	})
 }
+
+func (s *events) RegisterStopEvent(handler StopEventHandler, priority ...int) {
+	s.stopEventHandlers.AppendByOptionalPriority(handler, priority...)
+}
+
+func (s *events) onStop() {
+	s.stopEventHandlers.RangeValue(func(index int, value StopEventHandler) bool {
+		value(s)
+		return true
+	})
+}
@@ -0,0 +1,82 @@
+package server_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/network"
+	"github.com/kercylan98/minotaur/utils/random"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvents_ConnectionLifecycle(t *testing.T) {
+	var errBye = errors.New("bye")
+	var closedErr atomic.Value
+	var stopped atomic.Bool
+
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.NewServer(network.TCP(addr), server.NewOptions().WithLifeCycleLimit(time.Second))
+	srv.RegisterConnectionPacketPreprocessEvent(func(srv server.Server, conn server.Conn, packet server.Packet, abort func(), usePacket func(newPacket server.Packet)) {
+		switch string(packet.GetBytes()) {
+		case "drop":
+			abort()
+		case "x":
+			usePacket(server.NewPacket([]byte("y")))
+		}
+	})
+	srv.RegisterConnectionReceivePacketEvent(func(srv server.Server, conn server.Conn, packet server.Packet) {
+		switch data := string(packet.GetBytes()); data {
+		case "drop":
+			t.Error("packet should be aborted")
+		case "bye":
+			_ = conn.Close(errBye)
+		default:
+			_ = conn.WritePacket(packet)
+		}
+	})
+	srv.RegisterConnectionWritePacketBeforeEvent(func(srv server.Server, conn server.Conn, packet server.Packet) server.Packet {
+		return server.NewPacket(append(packet.GetBytes(), '!'))
+	})
+	srv.RegisterConnectionClosedEvent(func(srv server.Server, conn server.Conn, err error) {
+		closedErr.Store(err)
+	})
+	srv.RegisterStopEvent(func(srv server.Server) {
+		stopped.Store(true)
+	})
+
+	go func() {
+		time.Sleep(time.Millisecond * 200)
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		for _, data := range []string{"drop", "x", "bye"} {
+			if _, err = conn.Write([]byte(data)); err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(time.Millisecond * 100)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond * 300))
+		var buf = make([]byte, 16)
+		n, _ := conn.Read(buf)
+		if string(buf[:n]) != "y!" {
+			t.Errorf("unexpected data: %s", buf[:n])
+		}
+	}()
+
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err, _ := closedErr.Load().(error); !errors.Is(err, errBye) {
+		t.Fatalf("unexpected closed reason: %v", err)
+	}
+	if !stopped.Load() {
+		t.Fatal("stop event not triggered")
+	}
+}
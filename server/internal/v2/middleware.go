@@ -0,0 +1,33 @@
+package server
+
+type (
+	// PacketHandler 数据包处理函数
+	PacketHandler func(srv Server, conn Conn, packet Packet)
+
+	// PacketMiddleware 数据包中间件，通过包装 next 的方式组合数据包处理逻辑
+	//  - 需要继续处理数据包时应调用 next，否则该数据包将被中断处理，不会触发 ConnectionReceivePacketEvent 事件
+	PacketMiddleware func(next PacketHandler) PacketHandler
+)
+
+// UsePacketMiddleware 注册数据包中间件，中间件将在连接的 Actor 中围绕 ConnectionReceivePacketEvent 事件执行
+//   - 中间件按照注册顺序由外至内执行，即首个注册的中间件最先执行
+//   - 该函数应在服务器运行前调用
+func (s *events) UsePacketMiddleware(middlewares ...PacketMiddleware) {
+	s.packetMiddlewares = append(s.packetMiddlewares, middlewares...)
+	var handler PacketHandler = s.receivePacket
+	for i := len(s.packetMiddlewares) - 1; i >= 0; i-- {
+		handler = s.packetMiddlewares[i](handler)
+	}
+	s.packetHandler = handler
+}
+
+// receivePacket 将数据包分发至 Route 注册的路由，未命中路由时触发 ConnectionReceivePacketEvent 事件
+func (s *events) receivePacket(srv Server, conn Conn, packet Packet) {
+	if s.router.dispatch(conn, packet) {
+		return
+	}
+	s.connectionReceivePacketEventHandlers.RangeValue(func(index int, value ConnectionReceivePacketEventHandler) bool {
+		value(srv, conn, packet)
+		return true
+	})
+}
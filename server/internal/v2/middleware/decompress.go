@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/utils/log/v2"
+	"io"
+)
+
+type (
+	// Decompressor 数据包解压函数，当数据未经压缩时应返回 compressed 为 false，此时数据包将原样传递
+	Decompressor func(data []byte) (decompressed []byte, compressed bool, err error)
+
+	// DecompressFailedHandler 数据包解压失败时的处理函数
+	DecompressFailedHandler func(srv server.Server, conn server.Conn, packet server.Packet, err error)
+)
+
+// GzipDecompressor 创建 gzip 解压函数，仅对以 gzip 魔数开头的数据进行解压
+func GzipDecompressor() Decompressor {
+	return func(data []byte) ([]byte, bool, error) {
+		if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+			return data, false, nil
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, true, err
+		}
+		defer reader.Close()
+		decompressed, err := io.ReadAll(reader)
+		return decompressed, true, err
+	}
+}
+
+// Decompress 创建数据包解压中间件，经过解压的数据包将沿用原数据包的上下文（例如 WebSocket 的消息类型）
+//   - 解压失败的数据包将不会触发 ConnectionReceivePacketEvent 事件，当 failed 为 nil 时将记录警告日志
+func Decompress(decompressor Decompressor, failed DecompressFailedHandler) server.PacketMiddleware {
+	return func(next server.PacketHandler) server.PacketHandler {
+		return func(srv server.Server, conn server.Conn, packet server.Packet) {
+			data, compressed, err := decompressor(packet.GetBytes())
+			if err != nil {
+				if failed != nil {
+					failed(srv, conn, packet, err)
+				} else {
+					log.WithContext(conn.GetContext()).Warn("Middleware", log.String("type", "decompress"), log.Int("size", len(packet.GetBytes())), log.Err(err))
+				}
+				return
+			}
+			if compressed {
+				packet = server.NewPacket(data).SetContext(packet.GetContext())
+			}
+			next(srv, conn, packet)
+		}
+	}
+}
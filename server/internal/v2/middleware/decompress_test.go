@@ -0,0 +1,36 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/middleware"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	var received []string
+	var failed int
+	metrics := middleware.NewMetrics()
+	handler := metrics.Middleware()(middleware.Decompress(middleware.GzipDecompressor(), func(srv server.Server, conn server.Conn, packet server.Packet, err error) {
+		failed++
+	})(func(srv server.Server, conn server.Conn, packet server.Packet) {
+		received = append(received, string(packet.GetBytes()))
+	}))
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, _ = writer.Write([]byte("compressed"))
+	_ = writer.Close()
+
+	c := new(conn)
+	for _, data := range [][]byte{buf.Bytes(), []byte("plain"), {0x1f, 0x8b, 0x00}} {
+		handler(nil, c, server.NewPacket(data))
+	}
+	if len(received) != 2 || received[0] != "compressed" || received[1] != "plain" || failed != 1 {
+		t.Fatalf("received: %v, failed: %d", received, failed)
+	}
+	if metrics.GetPacketCount() != 3 || metrics.GetPacketBytes() != int64(buf.Len()+len("plain")+3) {
+		t.Fatalf("count: %d, bytes: %d", metrics.GetPacketCount(), metrics.GetPacketBytes())
+	}
+}
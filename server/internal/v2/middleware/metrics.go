@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"sync/atomic"
+	"time"
+)
+
+// NewMetrics 创建数据包统计
+func NewMetrics() *Metrics {
+	return new(Metrics)
+}
+
+// Metrics 数据包统计，统计经过中间件的数据包数量、字节数及后续处理耗时，是协程安全的
+type Metrics struct {
+	count   atomic.Int64 // 数据包数量
+	bytes   atomic.Int64 // 数据包字节数
+	cost    atomic.Int64 // 处理总耗时
+	maxCost atomic.Int64 // 最大处理耗时
+}
+
+// Middleware 创建统计中间件，统计的耗时包含后续中间件及 ConnectionReceivePacketEvent 事件的处理耗时
+//   - 应作为首个中间件注册，以便统计到所有数据包
+func (m *Metrics) Middleware() server.PacketMiddleware {
+	return func(next server.PacketHandler) server.PacketHandler {
+		return func(srv server.Server, conn server.Conn, packet server.Packet) {
+			m.count.Add(1)
+			m.bytes.Add(int64(len(packet.GetBytes())))
+			start := time.Now()
+			defer func() {
+				cost := int64(time.Since(start))
+				m.cost.Add(cost)
+				for {
+					current := m.maxCost.Load()
+					if cost <= current || m.maxCost.CompareAndSwap(current, cost) {
+						break
+					}
+				}
+			}()
+			next(srv, conn, packet)
+		}
+	}
+}
+
+// GetPacketCount 获取数据包数量
+func (m *Metrics) GetPacketCount() int64 {
+	return m.count.Load()
+}
+
+// GetPacketBytes 获取数据包字节数
+func (m *Metrics) GetPacketBytes() int64 {
+	return m.bytes.Load()
+}
+
+// GetAverageCost 获取数据包的平均处理耗时
+func (m *Metrics) GetAverageCost() time.Duration {
+	count := m.count.Load()
+	if count == 0 {
+		return 0
+	}
+	return time.Duration(m.cost.Load() / count)
+}
+
+// GetMaxCost 获取数据包的最大处理耗时
+func (m *Metrics) GetMaxCost() time.Duration {
+	return time.Duration(m.maxCost.Load())
+}
+
+// Reset 重置统计数据
+func (m *Metrics) Reset() {
+	m.count.Store(0)
+	m.bytes.Store(0)
+	m.cost.Store(0)
+	m.maxCost.Store(0)
+}
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"sync"
+	"time"
+)
+
+type (
+	// RateLimitOpcodeParser 从数据包中解析操作码，当无法解析时应返回 false，此时仅进行连接级别的限流
+	RateLimitOpcodeParser func(packet server.Packet) (opcode string, ok bool)
+
+	// RateLimitExceededHandler 数据包超出限流时的处理函数，opcode 为空时表示超出了连接级别的限流
+	RateLimitExceededHandler func(srv server.Server, conn server.Conn, packet server.Packet, opcode string)
+)
+
+// NewRateLimitOptions 创建限流中间件可选项
+func NewRateLimitOptions() *RateLimitOptions {
+	return &RateLimitOptions{
+		opcodes: make(map[string]rateLimit),
+	}
+}
+
+// RateLimitOptions 限流中间件可选项
+type RateLimitOptions struct {
+	conn     *rateLimit               // 连接级别的限流
+	opcodes  map[string]rateLimit     // 操作码级别的限流
+	parser   RateLimitOpcodeParser    // 操作码解析器
+	exceeded RateLimitExceededHandler // 超出限流时的处理函数
+}
+
+type rateLimit struct {
+	rate  float64 // 每秒生成的令牌数
+	burst float64 // 令牌桶容量
+}
+
+// WithConnLimit 设置连接级别的限流，每个连接每秒最多处理 rate 个数据包，允许瞬时处理 burst 个数据包
+//   - 当 rate <= 0 或 burst <= 0 时表示不限制
+func (o *RateLimitOptions) WithConnLimit(rate float64, burst int) *RateLimitOptions {
+	if rate <= 0 || burst <= 0 {
+		o.conn = nil
+		return o
+	}
+	o.conn = &rateLimit{rate: rate, burst: float64(burst)}
+	return o
+}
+
+// WithOpcodeLimit 设置特定操作码的限流，每个连接每秒最多处理 rate 个该操作码的数据包，允许瞬时处理 burst 个数据包
+//   - 需要配合 WithOpcodeParser 使用
+//   - 当 rate <= 0 或 burst <= 0 时表示不限制
+func (o *RateLimitOptions) WithOpcodeLimit(opcode string, rate float64, burst int) *RateLimitOptions {
+	if rate <= 0 || burst <= 0 {
+		delete(o.opcodes, opcode)
+		return o
+	}
+	o.opcodes[opcode] = rateLimit{rate: rate, burst: float64(burst)}
+	return o
+}
+
+// WithOpcodeParser 设置操作码解析器
+func (o *RateLimitOptions) WithOpcodeParser(parser RateLimitOpcodeParser) *RateLimitOptions {
+	o.parser = parser
+	return o
+}
+
+// WithExceededHandler 设置数据包超出限流时的处理函数，默认将直接丢弃数据包
+//   - 可使用 RateLimitReply 回复标准的限流响应
+func (o *RateLimitOptions) WithExceededHandler(handler RateLimitExceededHandler) *RateLimitOptions {
+	o.exceeded = handler
+	return o
+}
+
+// RateLimitReply 创建一个向连接回复 data 的限流处理函数，回复的数据包将沿用原数据包的上下文（例如 WebSocket 的消息类型）
+func RateLimitReply(data []byte) RateLimitExceededHandler {
+	return func(srv server.Server, conn server.Conn, packet server.Packet, opcode string) {
+		_ = conn.WritePacket(server.NewPacket(data).SetContext(packet.GetContext()))
+	}
+}
+
+// RateLimit 创建基于令牌桶算法的限流中间件，对每个连接及每个连接的特定操作码进行限流
+//   - 超出限流的数据包将不会触发 ConnectionReceivePacketEvent 事件
+//   - 处于满载状态的令牌桶将被定期清理，因此无需关注连接的关闭
+func RateLimit(options *RateLimitOptions) server.PacketMiddleware {
+	limiter := &rateLimiter{
+		options: options,
+		buckets: make(map[rateLimitKey]*tokenBucket),
+	}
+	return func(next server.PacketHandler) server.PacketHandler {
+		return func(srv server.Server, conn server.Conn, packet server.Packet) {
+			if opcode, allowed := limiter.allow(conn, packet); !allowed {
+				if options.exceeded != nil {
+					options.exceeded(srv, conn, packet, opcode)
+				}
+				return
+			}
+			next(srv, conn, packet)
+		}
+	}
+}
+
+// rateLimitSweepInterval 清理满载令牌桶的间隔
+const rateLimitSweepInterval = time.Minute
+
+type rateLimitKey struct {
+	conn   server.Conn
+	opcode string
+}
+
+type rateLimiter struct {
+	options *RateLimitOptions
+	lock    sync.Mutex
+	buckets map[rateLimitKey]*tokenBucket
+	sweepAt time.Time
+}
+
+// allow 检查数据包是否允许被处理，不允许时将返回超出限流的操作码
+func (l *rateLimiter) allow(conn server.Conn, packet server.Packet) (opcode string, allowed bool) {
+	var limit *rateLimit
+	if l.options.parser != nil && len(l.options.opcodes) > 0 {
+		if code, ok := l.options.parser(packet); ok {
+			if opcodeLimit, exist := l.options.opcodes[code]; exist {
+				opcode, limit = code, &opcodeLimit
+			}
+		}
+	}
+
+	now := time.Now()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.sweep(now)
+
+	var connBucket, opcodeBucket *tokenBucket
+	if l.options.conn != nil {
+		connBucket = l.bucket(rateLimitKey{conn: conn}, *l.options.conn, now)
+		if connBucket.tokens < 1 {
+			return "", false
+		}
+	}
+	if limit != nil {
+		opcodeBucket = l.bucket(rateLimitKey{conn: conn, opcode: opcode}, *limit, now)
+		if opcodeBucket.tokens < 1 {
+			return opcode, false
+		}
+	}
+	if connBucket != nil {
+		connBucket.tokens--
+	}
+	if opcodeBucket != nil {
+		opcodeBucket.tokens--
+	}
+	return opcode, true
+}
+
+// bucket 获取并填充令牌桶
+func (l *rateLimiter) bucket(key rateLimitKey, limit rateLimit, now time.Time) *tokenBucket {
+	bucket, exist := l.buckets[key]
+	if !exist {
+		bucket = &tokenBucket{limit: limit, tokens: limit.burst, updatedAt: now}
+		l.buckets[key] = bucket
+		return bucket
+	}
+	bucket.refill(now)
+	return bucket
+}
+
+// sweep 清理已满载的令牌桶，满载的令牌桶与新建的令牌桶等价
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.sweepAt) < rateLimitSweepInterval {
+		return
+	}
+	l.sweepAt = now
+	for key, bucket := range l.buckets {
+		if bucket.refill(now); bucket.tokens >= bucket.limit.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+type tokenBucket struct {
+	limit     rateLimit
+	tokens    float64
+	updatedAt time.Time
+}
+
+// refill 根据经过的时间填充令牌
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.updatedAt).Seconds() * b.limit.rate
+	if b.tokens > b.limit.burst {
+		b.tokens = b.limit.burst
+	}
+	b.updatedAt = now
+}
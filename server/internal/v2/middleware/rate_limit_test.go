@@ -0,0 +1,45 @@
+package middleware_test
+
+import (
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/middleware"
+	"testing"
+)
+
+type conn struct {
+	server.Conn
+	written int
+}
+
+func (c *conn) WritePacket(packet server.Packet) error {
+	c.written++
+	return nil
+}
+
+func TestRateLimit(t *testing.T) {
+	var handled int
+	handler := middleware.RateLimit(middleware.NewRateLimitOptions().
+		WithConnLimit(0.001, 3).
+		WithOpcodeLimit("move", 0.001, 1).
+		WithOpcodeParser(func(packet server.Packet) (string, bool) {
+			return string(packet.GetBytes()), true
+		}).
+		WithExceededHandler(middleware.RateLimitReply([]byte("rate limited"))),
+	)(func(srv server.Server, conn server.Conn, packet server.Packet) {
+		handled++
+	})
+
+	c := new(conn)
+	for _, opcode := range []string{"move", "move", "chat", "chat", "chat"} {
+		handler(nil, c, server.NewPacket([]byte(opcode)))
+	}
+	if handled != 3 || c.written != 2 {
+		t.Fatalf("handled: %d, written: %d", handled, c.written)
+	}
+
+	other := new(conn)
+	handler(nil, other, server.NewPacket([]byte("move")))
+	if handled != 4 {
+		t.Fatalf("handled: %d", handled)
+	}
+}
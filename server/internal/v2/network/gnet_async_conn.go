@@ -0,0 +1,25 @@
+package network
+
+import (
+	"github.com/panjf2000/gnet/v2"
+)
+
+// newGNetAsyncConn 创建写入通过事件循环进行的 gnet 连接
+func newGNetAsyncConn(conn gnet.Conn) *gnetAsyncConn {
+	return &gnetAsyncConn{Conn: conn}
+}
+
+// gnetAsyncConn 写入通过事件循环进行的 gnet 连接，gnet.Conn 的 Write 仅能在事件循环中调用，而连接的写入通常发生在消息队列中
+type gnetAsyncConn struct {
+	gnet.Conn
+}
+
+// Write 复制数据并通过事件循环异步写入，因此不会返回写入时发生的错误
+func (c *gnetAsyncConn) Write(data []byte) (n int, err error) {
+	buf := make([]byte, len(data))
+	copy(buf, data)
+	if err = c.Conn.AsyncWrite(buf, nil); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
@@ -26,11 +26,24 @@ func HttpWithHandler[H http.Handler](addr string, handler H) server.Network {
 	return c
 }
 
+// HttpTLS 创建启用 TLS 的 Http 网络，证书支持热重载，当 TLS 设置了客户端 CA 时将开启双向认证
+func HttpTLS(addr string, tls *TLS) server.Network {
+	return HttpTLSWithHandler(addr, tls, &HttpServe{ServeMux: http.NewServeMux()})
+}
+
+// HttpTLSWithHandler 创建启用 TLS 并使用特定处理器的 Http 网络
+func HttpTLSWithHandler[H http.Handler](addr string, tls *TLS, handler H) server.Network {
+	c := HttpWithHandler(addr, handler).(*httpCore[H])
+	c.tls = tls
+	return c
+}
+
 type httpCore[H http.Handler] struct {
 	addr       string
 	handler    H
 	srv        *http.Server
 	controller server.Controller
+	tls        *TLS
 }
 
 func (h *httpCore[H]) OnSetup(ctx context.Context, controller server.Controller) (err error) {
@@ -38,11 +51,23 @@ func (h *httpCore[H]) OnSetup(ctx context.Context, controller server.Controller)
 	h.srv.BaseContext = func(listener net.Listener) context.Context {
 		return ctx
 	}
+	if h.tls != nil {
+		h.srv.TLSConfig, err = h.tls.ServerConfig()
+		if h.tls.timeout > 0 && h.srv.ReadHeaderTimeout == 0 {
+			// 握手及请求头需要在超时时间内读取完成
+			h.srv.ReadHeaderTimeout = h.tls.timeout
+		}
+	}
 	return
 }
 
 func (h *httpCore[H]) OnRun() (err error) {
-	if err = h.srv.ListenAndServe(); errors.Is(err, http.ErrServerClosed) {
+	if h.tls != nil {
+		err = h.srv.ListenAndServeTLS("", "")
+	} else {
+		err = h.srv.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
 		err = nil
 	}
 	return
@@ -0,0 +1,76 @@
+package network
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/xtaci/kcp-go/v5"
+	"sync/atomic"
+)
+
+// KCP 创建基于 kcp-go 的 KCP 网络，每个 KCP 消息将作为一个数据包传递
+func KCP(addr string) server.Network {
+	return &kcpCore{addr: addr}
+}
+
+type kcpCore struct {
+	ctx        context.Context
+	controller server.Controller
+	addr       string
+	listener   *kcp.Listener
+	closed     atomic.Bool
+}
+
+func (k *kcpCore) OnSetup(ctx context.Context, controller server.Controller) (err error) {
+	k.ctx = ctx
+	k.controller = controller
+	k.listener, err = kcp.ListenWithOptions(k.addr, nil, 0, 0)
+	return
+}
+
+func (k *kcpCore) OnRun() (err error) {
+	for {
+		session, err := k.listener.AcceptKCP()
+		if err != nil {
+			if k.closed.Load() {
+				return nil
+			}
+			return err
+		}
+		go k.serve(session)
+	}
+}
+
+func (k *kcpCore) OnShutdown() error {
+	if !k.closed.CompareAndSwap(false, true) || k.listener == nil {
+		return nil
+	}
+	return k.listener.Close()
+}
+
+func (k *kcpCore) Schema() string {
+	return "kcp"
+}
+
+func (k *kcpCore) Address() string {
+	return k.addr
+}
+
+// serve 注册会话并持续读取会话的数据，会话的写入是协程安全的
+func (k *kcpCore) serve(session *kcp.UDPSession) {
+	k.controller.RegisterConnection(session, func(packet server.Packet) error {
+		_, err := session.Write(packet.GetBytes())
+		return err
+	})
+	buf := make([]byte, 4096)
+	for {
+		n, err := session.Read(buf)
+		if err != nil {
+			_ = session.Close()
+			k.controller.EliminateConnection(session, err)
+			return
+		}
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		k.controller.ReactPacket(session, server.NewPacket(data))
+	}
+}
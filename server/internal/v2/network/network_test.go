@@ -0,0 +1,71 @@
+package network_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/network"
+	"github.com/kercylan98/minotaur/utils/random"
+	"github.com/xtaci/kcp-go/v5"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNetwork_Echo(t *testing.T) {
+	var cases = []struct {
+		name    string
+		network func(addr string) server.Network
+		dial    func(addr string) (net.Conn, error)
+	}{
+		{"tcp", network.TCP, func(addr string) (net.Conn, error) { return net.Dial("tcp", addr) }},
+		{"udp", func(addr string) server.Network { return network.UDP(addr) }, func(addr string) (net.Conn, error) { return net.Dial("udp", addr) }},
+		{"kcp", network.KCP, func(addr string) (net.Conn, error) { return kcp.Dial(addr) }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+			srv := server.NewServer(c.network(addr), server.NewOptions().WithLifeCycleLimit(time.Second))
+			srv.RegisterConnectionReceivePacketEvent(func(srv server.Server, conn server.Conn, packet server.Packet) {
+				if err := conn.WritePacket(packet); err != nil {
+					t.Error(err)
+				}
+			})
+
+			var received = make(chan string, 1)
+			go func() {
+				time.Sleep(time.Millisecond * 200)
+				conn, err := c.dial(addr)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer conn.Close()
+				if _, err = conn.Write([]byte("hello")); err != nil {
+					t.Error(err)
+					return
+				}
+				_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond * 500))
+				var buf = make([]byte, 16)
+				n, err := conn.Read(buf)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				received <- string(buf[:n])
+			}()
+
+			if err := srv.Run(); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case data := <-received:
+				if data != "hello" {
+					t.Fatalf("unexpected data: %s", data)
+				}
+			default:
+				t.Fatal("no data received")
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/panjf2000/gnet/v2"
+	"time"
+)
+
+// TCP 创建基于 gnet 的 TCP 网络，读取到的数据将原样作为数据包传递，写入将通过 gnet 的事件循环异步进行
+func TCP(addr string) server.Network {
+	return &tcpCore{schema: "tcp", addr: addr}
+}
+
+// TCP4 创建仅监听 IPv4 地址的 TCP 网络
+func TCP4(addr string) server.Network {
+	return &tcpCore{schema: "tcp4", addr: addr}
+}
+
+// TCP6 创建仅监听 IPv6 地址的 TCP 网络
+func TCP6(addr string) server.Network {
+	return &tcpCore{schema: "tcp6", addr: addr}
+}
+
+type tcpCore struct {
+	ctx        context.Context
+	controller server.Controller
+	handler    *tcpHandler
+	schema     string
+	addr       string
+}
+
+func (t *tcpCore) OnSetup(ctx context.Context, controller server.Controller) (err error) {
+	t.ctx = ctx
+	t.controller = controller
+	t.handler = &tcpHandler{tcpCore: t}
+	return
+}
+
+func (t *tcpCore) OnRun() (err error) {
+	return gnet.Run(t.handler, fmt.Sprintf("%s://%s", t.schema, t.addr), gnet.WithMulticore(true))
+}
+
+func (t *tcpCore) OnShutdown() error {
+	if t.handler.engine != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return t.handler.engine.Stop(ctx)
+	}
+	return nil
+}
+
+func (t *tcpCore) Schema() string {
+	return t.schema
+}
+
+func (t *tcpCore) Address() string {
+	return t.addr
+}
+
+// tcpHandler 基于 gnet 的事件处理器
+type tcpHandler struct {
+	gnet.BuiltinEventEngine
+	*tcpCore
+	engine *gnet.Engine
+}
+
+func (t *tcpHandler) OnBoot(eng gnet.Engine) (action gnet.Action) {
+	t.engine = &eng
+	return
+}
+
+func (t *tcpHandler) OnShutdown(eng gnet.Engine) {
+
+}
+
+func (t *tcpHandler) OnOpen(c gnet.Conn) (out []byte, action gnet.Action) {
+	wrapper := newGNetAsyncConn(c)
+	c.SetContext(wrapper)
+	t.controller.RegisterConnection(wrapper, func(packet server.Packet) error {
+		_, err := wrapper.Write(packet.GetBytes())
+		return err
+	})
+	return
+}
+
+func (t *tcpHandler) OnClose(c gnet.Conn, err error) (action gnet.Action) {
+	t.controller.EliminateConnection(c.Context().(*gnetAsyncConn), err)
+	return
+}
+
+func (t *tcpHandler) OnTraffic(c gnet.Conn) (action gnet.Action) {
+	buf, err := c.Next(-1)
+	if err != nil {
+		return gnet.Close
+	}
+	data := make([]byte, len(buf))
+	copy(data, buf)
+	t.controller.ReactPacket(c.Context().(*gnetAsyncConn), server.NewPacket(data))
+	return
+}
@@ -0,0 +1,189 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTLSReloadInterval   = time.Second      // 默认的证书热重载检查间隔
+	DefaultTLSHandshakeTimeout = 10 * time.Second // 默认的握手超时时间
+)
+
+// NewTLS 通过证书及私钥文件创建 TLS 配置，证书文件发生变更时将在下一次握手时自动重新加载
+//   - 通过 WithClientCA 设置客户端 CA 证书后将开启双向认证（mTLS），适用于服务器之间的链路
+func NewTLS(certFile, keyFile string) *TLS {
+	return &TLS{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: DefaultTLSReloadInterval,
+		timeout:  DefaultTLSHandshakeTimeout,
+	}
+}
+
+// TLS 支持证书热重载及双向认证的传输层安全配置
+type TLS struct {
+	certFile string        // 证书文件
+	keyFile  string        // 私钥文件
+	caFile   string        // CA 证书文件，设置后开启双向认证
+	interval time.Duration // 热重载检查间隔
+	timeout  time.Duration // 握手超时时间
+
+	lock    sync.RWMutex
+	cert    *tls.Certificate // 当前证书
+	pool    *x509.CertPool   // 当前 CA 证书池
+	modTime time.Time        // 已加载文件的最后修改时间
+	checked time.Time        // 最后一次检查时间
+}
+
+// WithClientCA 设置用于验证对端证书的 CA 证书文件，设置后服务端将要求并验证客户端证书（mTLS）
+func (t *TLS) WithClientCA(caFile string) *TLS {
+	t.caFile = caFile
+	return t
+}
+
+// WithReloadInterval 设置证书热重载的检查间隔，当 interval <= 0 时将在每次握手时检查
+func (t *TLS) WithReloadInterval(interval time.Duration) *TLS {
+	t.interval = interval
+	return t
+}
+
+// WithHandshakeTimeout 设置握手超时时间，连接需要在该时间内完成 TLS 握手及协议升级（或读取完请求头），否则将被关闭，当 timeout <= 0 时不限制
+//   - 用于避免恶意客户端建立连接后缓慢发送或不发送数据而长期占用连接
+func (t *TLS) WithHandshakeTimeout(timeout time.Duration) *TLS {
+	t.timeout = timeout
+	return t
+}
+
+// ServerConfig 获取服务端使用的 *tls.Config
+func (t *TLS) ServerConfig() (*tls.Config, error) {
+	if err := t.reload(true); err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(info *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if err := t.reload(false); err != nil {
+				return nil, err
+			}
+			t.lock.RLock()
+			defer t.lock.RUnlock()
+			return t.cert, nil
+		},
+	}
+	if t.caFile != "" {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = t.pool
+		config.GetConfigForClient = func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+			if err := t.reload(false); err != nil {
+				return nil, err
+			}
+			t.lock.RLock()
+			defer t.lock.RUnlock()
+			c := config.Clone()
+			c.ClientCAs = t.pool
+			c.GetConfigForClient = nil
+			return c, nil
+		}
+	}
+	return config, nil
+}
+
+// ClientConfig 获取服务器之间建立链路时客户端使用的 *tls.Config，将携带当前证书并使用 CA 证书验证服务端
+func (t *TLS) ClientConfig(serverName string) (*tls.Config, error) {
+	if err := t.reload(true); err != nil {
+		return nil, err
+	}
+	t.lock.RLock()
+	pool := t.pool
+	t.lock.RUnlock()
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		RootCAs:    pool,
+		GetClientCertificate: func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if err := t.reload(false); err != nil {
+				return nil, err
+			}
+			t.lock.RLock()
+			defer t.lock.RUnlock()
+			return t.cert, nil
+		},
+	}, nil
+}
+
+// reload 检查证书文件是否发生变更，发生变更时重新加载
+//   - force 为 true 时将忽略检查间隔
+//   - 重新加载失败时将继续使用已加载的证书
+func (t *TLS) reload(force bool) error {
+	now := time.Now()
+	t.lock.RLock()
+	loaded := t.cert != nil
+	skip := loaded && !force && t.interval > 0 && now.Sub(t.checked) < t.interval
+	t.lock.RUnlock()
+	if skip {
+		return nil
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.checked = now
+	modTime, err := t.latestModTime()
+	if err != nil {
+		if loaded {
+			return nil
+		}
+		return err
+	}
+	if loaded && !modTime.After(t.modTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+	if err != nil {
+		if loaded {
+			return nil
+		}
+		return err
+	}
+	var pool *x509.CertPool
+	if t.caFile != "" {
+		ca, err := os.ReadFile(t.caFile)
+		if err != nil {
+			if loaded {
+				return nil
+			}
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			if loaded {
+				return nil
+			}
+			return errors.New("network: failed to parse client ca certificate")
+		}
+	}
+	t.cert, t.pool, t.modTime = &cert, pool, modTime
+	return nil
+}
+
+// latestModTime 获取证书相关文件中最晚的修改时间
+func (t *TLS) latestModTime() (latest time.Time, err error) {
+	for _, file := range []string{t.certFile, t.keyFile, t.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return
+}
@@ -0,0 +1,286 @@
+package network_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/network"
+	"github.com/kercylan98/minotaur/utils/random"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA 测试用的自签名 CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+func newTestCA(t *testing.T, dir string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "minotaur test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &testCA{cert: cert, key: key, file: filepath.Join(dir, "ca.pem")}
+	writePEM(t, ca.file, "CERTIFICATE", der)
+	return ca
+}
+
+// issue 使用 CA 签发特定序列号的证书，并写入 name.pem 及 name.key
+func (ca *testCA) issue(t *testing.T, dir, name string, serial int64) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, name+".pem"), filepath.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDer)
+	return
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runEcho 运行回显服务器，并在服务器启动后执行 client，服务器将在 1 秒后关闭
+func runEcho(t *testing.T, srvNetwork server.Network, client func()) {
+	t.Helper()
+	srv := server.NewServer(srvNetwork, server.NewOptions().WithLifeCycleLimit(time.Second))
+	srv.RegisterConnectionReceivePacketEvent(func(srv server.Server, conn server.Conn, packet server.Packet) {
+		if err := conn.WritePacket(packet); err != nil {
+			t.Error(err)
+		}
+	})
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(time.Millisecond * 200)
+		client()
+	}()
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+}
+
+// echo 通过 wss 发送数据并读取回显
+func echo(addr string, config *tls.Config) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+	conn, _, _, err := ws.Dialer{TLSConfig: config}.Dial(ctx, "wss://"+addr+"/")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err = wsutil.WriteClientMessage(conn, ws.OpText, []byte("hello")); err != nil {
+		return "", err
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond * 500))
+	data, err := wsutil.ReadServerText(conn)
+	return string(data), err
+}
+
+func TestWebSocketTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "server", 2)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	runEcho(t, network.WebSocketTLS(addr, network.NewTLS(certFile, keyFile)), func() {
+		if data, err := echo(addr, &tls.Config{RootCAs: roots}); err != nil || data != "hello" {
+			t.Errorf("echo failed, data: %s, err: %v", data, err)
+		}
+		if _, err := echo(addr, &tls.Config{}); err == nil {
+			t.Error("untrusted server certificate should be rejected")
+		}
+	})
+}
+
+func TestWebSocketTLS_Mutual(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	serverCert, serverKey := ca.issue(t, dir, "server", 2)
+	clientCert, clientKey := ca.issue(t, dir, "client", 3)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	runEcho(t, network.WebSocketTLS(addr, network.NewTLS(serverCert, serverKey).WithClientCA(ca.file)), func() {
+		// 未携带客户端证书时握手将失败
+		if _, err := echo(addr, &tls.Config{RootCAs: roots}); err == nil {
+			t.Error("connection without client certificate should be rejected")
+		}
+
+		config, err := network.NewTLS(clientCert, clientKey).WithClientCA(ca.file).ClientConfig("127.0.0.1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if data, err := echo(addr, config); err != nil || data != "hello" {
+			t.Errorf("echo failed, data: %s, err: %v", data, err)
+		}
+	})
+}
+
+func TestWebSocketTLS_HandshakeTimeout(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "server", 2)
+
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	tlsConfig := network.NewTLS(certFile, keyFile).WithHandshakeTimeout(time.Millisecond * 100)
+	runEcho(t, network.WebSocketTLS(addr, tlsConfig), func() {
+		// 建立连接后不发送任何数据，连接应在握手超时后被服务端关闭
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond * 500))
+		if _, err = conn.Read(make([]byte, 1)); err == nil {
+			t.Error("unexpected data received")
+		} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			t.Error("connection should be closed by server after handshake timeout")
+		}
+	})
+}
+
+func TestTLS_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "server", 2)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	config, err := network.NewTLS(certFile, keyFile).WithReloadInterval(0).ServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	var serial = func() int64 {
+		t.Helper()
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{RootCAs: roots})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	if s := serial(); s != 2 {
+		t.Fatalf("unexpected serial: %d", s)
+	}
+
+	// 替换证书文件后，下一次握手将使用新的证书
+	ca.issue(t, dir, "server", 4)
+	future := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err = os.Chtimes(file, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := serial(); s != 4 {
+		t.Fatalf("certificate not reloaded, serial: %d", s)
+	}
+
+	// 新的证书无法加载时将继续使用已加载的证书
+	if err = os.WriteFile(certFile, []byte("broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future = future.Add(time.Minute)
+	if err = os.Chtimes(certFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if s := serial(); s != 4 {
+		t.Fatalf("loaded certificate should be kept, serial: %d", s)
+	}
+}
+
+func TestWebSocketTLS_ShutdownImmediately(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "server", 2)
+
+	// 服务器可能在开始接受连接前停止，此时监听器同样需要被关闭
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.NewServer(network.WebSocketTLS(addr, network.NewTLS(certFile, keyFile)), server.NewOptions().WithLifeCycleLimit(time.Millisecond))
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("listener should be closed after shutdown: %v", err)
+	}
+	_ = listener.Close()
+}
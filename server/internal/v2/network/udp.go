@@ -0,0 +1,201 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/utils/collection"
+	"github.com/panjf2000/gnet/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultUDPSessionTimeout 默认的 UDP 虚拟会话不活跃超时时长
+const DefaultUDPSessionTimeout = time.Minute
+
+// ErrUDPSessionTimeout UDP 虚拟会话不活跃超时
+var ErrUDPSessionTimeout = errors.New("udp session inactive timeout")
+
+// UDP 创建基于 gnet 的 UDP 网络，来自同一远端地址的数据报将被视为同一连接，每个数据报将作为一个数据包传递
+//   - 连接在 sessionTimeout 时长内没有收到数据报时将被关闭，默认为 DefaultUDPSessionTimeout
+func UDP(addr string, sessionTimeout ...time.Duration) server.Network {
+	return newUDPCore("udp", addr, sessionTimeout...)
+}
+
+// UDP4 创建仅监听 IPv4 地址的 UDP 网络
+func UDP4(addr string, sessionTimeout ...time.Duration) server.Network {
+	return newUDPCore("udp4", addr, sessionTimeout...)
+}
+
+// UDP6 创建仅监听 IPv6 地址的 UDP 网络
+func UDP6(addr string, sessionTimeout ...time.Duration) server.Network {
+	return newUDPCore("udp6", addr, sessionTimeout...)
+}
+
+func newUDPCore(schema, addr string, sessionTimeout ...time.Duration) *udpCore {
+	u := &udpCore{
+		schema:   schema,
+		addr:     addr,
+		timeout:  collection.FindFirstOrDefaultInSlice(sessionTimeout, DefaultUDPSessionTimeout),
+		sessions: make(map[string]*udpSession),
+	}
+	if u.timeout <= 0 {
+		u.timeout = DefaultUDPSessionTimeout
+	}
+	return u
+}
+
+type udpCore struct {
+	ctx        context.Context
+	controller server.Controller
+	handler    *udpHandler
+	schema     string
+	addr       string
+	timeout    time.Duration
+	lock       sync.Mutex
+	sessions   map[string]*udpSession
+}
+
+func (u *udpCore) OnSetup(ctx context.Context, controller server.Controller) (err error) {
+	u.ctx = ctx
+	u.controller = controller
+	u.handler = &udpHandler{udpCore: u}
+	return
+}
+
+func (u *udpCore) OnRun() (err error) {
+	return gnet.Run(u.handler, fmt.Sprintf("%s://%s", u.schema, u.addr), gnet.WithMulticore(true), gnet.WithTicker(true))
+}
+
+func (u *udpCore) OnShutdown() error {
+	if u.handler.engine != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return u.handler.engine.Stop(ctx)
+	}
+	return nil
+}
+
+func (u *udpCore) Schema() string {
+	return u.schema
+}
+
+func (u *udpCore) Address() string {
+	return u.addr
+}
+
+// udpHandler 基于 gnet 的事件处理器
+type udpHandler struct {
+	gnet.BuiltinEventEngine
+	*udpCore
+	engine *gnet.Engine
+}
+
+func (u *udpHandler) OnBoot(eng gnet.Engine) (action gnet.Action) {
+	u.engine = &eng
+	return
+}
+
+func (u *udpHandler) OnShutdown(eng gnet.Engine) {
+
+}
+
+func (u *udpHandler) OnTraffic(c gnet.Conn) (action gnet.Action) {
+	buf, err := c.Next(-1)
+	if err != nil {
+		return
+	}
+	data := make([]byte, len(buf))
+	copy(data, buf)
+
+	remote := c.RemoteAddr()
+	u.lock.Lock()
+	session, exist := u.sessions[remote.String()]
+	if !exist {
+		session = &udpSession{core: u.udpCore, conn: c, local: c.LocalAddr(), remote: remote}
+		u.sessions[remote.String()] = session
+		u.controller.RegisterConnection(session, func(packet server.Packet) error {
+			_, err := session.Write(packet.GetBytes())
+			return err
+		})
+	}
+	session.active = time.Now()
+	u.lock.Unlock()
+
+	u.controller.ReactPacket(session, server.NewPacket(data))
+	return
+}
+
+func (u *udpHandler) OnTick() (delay time.Duration, action gnet.Action) {
+	var expired []*udpSession
+	var now = time.Now()
+	u.lock.Lock()
+	for key, session := range u.sessions {
+		if now.Sub(session.active) >= u.timeout {
+			delete(u.sessions, key)
+			expired = append(expired, session)
+		}
+	}
+	u.lock.Unlock()
+	for _, session := range expired {
+		u.controller.EliminateConnection(session, ErrUDPSessionTimeout)
+	}
+	return u.timeout / 2, gnet.None
+}
+
+// eliminate 移除虚拟会话
+func (u *udpCore) eliminate(session *udpSession, err error) {
+	u.lock.Lock()
+	current, exist := u.sessions[session.remote.String()]
+	if exist && current == session {
+		delete(u.sessions, session.remote.String())
+	}
+	u.lock.Unlock()
+	if exist && current == session {
+		u.controller.EliminateConnection(session, err)
+	}
+}
+
+// udpSession 以远端地址区分的 UDP 虚拟会话
+type udpSession struct {
+	core   *udpCore
+	conn   gnet.Conn // 首个数据报的连接，数据报连接的写入将直接发送至远端地址，是协程安全的
+	local  net.Addr
+	remote net.Addr
+	active time.Time // 最后一次收到数据报的时间
+}
+
+func (s *udpSession) Read(b []byte) (n int, err error) {
+	return 0, errors.ErrUnsupported
+}
+
+func (s *udpSession) Write(b []byte) (n int, err error) {
+	return s.conn.Write(b)
+}
+
+func (s *udpSession) Close() error {
+	s.core.eliminate(s, nil)
+	return nil
+}
+
+func (s *udpSession) LocalAddr() net.Addr {
+	return s.local
+}
+
+func (s *udpSession) RemoteAddr() net.Addr {
+	return s.remote
+}
+
+func (s *udpSession) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (s *udpSession) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (s *udpSession) SetWriteDeadline(t time.Time) error {
+	return nil
+}
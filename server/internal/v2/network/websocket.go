@@ -23,21 +23,33 @@ type websocketCore struct {
 	handler    *websocketHandler
 	addr       string
 	pattern    string
+	tls        *TLS
+	listener   *websocketTLSListener
 }
 
 func (w *websocketCore) OnSetup(ctx context.Context, controller server.Controller) (err error) {
 	w.ctx = ctx
 	w.handler = newWebsocketHandler(w)
 	w.controller = controller
+	if w.tls != nil {
+		// 监听器需要在 OnRun 之前创建，以便在启动前后的任意时刻停止服务器时均可关闭监听器
+		w.listener, err = newWebsocketTLSListener(w)
+	}
 	return
 }
 
 func (w *websocketCore) OnRun() (err error) {
+	if w.listener != nil {
+		return w.listener.serve()
+	}
 	err = gnet.Run(w.handler, fmt.Sprintf("tcp://%s", w.addr))
 	return
 }
 
 func (w *websocketCore) OnShutdown() error {
+	if w.listener != nil {
+		return w.listener.close()
+	}
 	if w.handler.engine != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -47,6 +59,9 @@ func (w *websocketCore) OnShutdown() error {
 }
 
 func (w *websocketCore) Schema() string {
+	if w.tls != nil {
+		return "wss"
+	}
 	return "ws"
 }
 
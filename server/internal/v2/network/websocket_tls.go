@@ -0,0 +1,129 @@
+package network
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// WebSocketTLS 创建启用 TLS 的 WebSocket 网络，证书支持热重载，当 TLS 设置了客户端 CA 时将开启双向认证
+//   - 由于 gnet 不支持 TLS，启用 TLS 后将使用标准库监听并为每个连接启动独立的读取协程
+func WebSocketTLS(addr string, tls *TLS, pattern ...string) server.Network {
+	core := WebSocket(addr, pattern...).(*websocketCore)
+	core.tls = tls
+	return core
+}
+
+// newWebsocketTLSListener 创建 TLS 监听器
+func newWebsocketTLSListener(core *websocketCore) (*websocketTLSListener, error) {
+	config, err := core.tls.ServerConfig()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := tls.Listen("tcp", core.addr, config)
+	if err != nil {
+		return nil, err
+	}
+	return &websocketTLSListener{
+		websocketCore: core,
+		listener:      listener,
+		conns:         make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// websocketTLSListener 基于标准库的 TLS WebSocket 监听器
+type websocketTLSListener struct {
+	*websocketCore
+	listener net.Listener
+	lock     sync.Mutex
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// serve 开始接受连接，直到监听器被关闭
+func (l *websocketTLSListener) serve() error {
+	upgrader := ws.Upgrader{
+		OnRequest: func(uri []byte) (err error) {
+			if string(uri) != l.pattern {
+				err = errors.New("bad request")
+			}
+			return
+		},
+	}
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			l.lock.Lock()
+			closed := l.closed
+			l.lock.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go l.handle(upgrader, conn)
+	}
+}
+
+// handle 处理单个连接的升级及读取
+func (l *websocketTLSListener) handle(upgrader ws.Upgrader, conn net.Conn) {
+	l.lock.Lock()
+	if l.closed {
+		l.lock.Unlock()
+		_ = conn.Close()
+		return
+	}
+	l.conns[conn] = struct{}{}
+	l.lock.Unlock()
+	defer func() {
+		l.lock.Lock()
+		delete(l.conns, conn)
+		l.lock.Unlock()
+		_ = conn.Close()
+	}()
+
+	// 握手及升级需要在超时时间内完成，升级完成后取消期限，避免连接被长期占用
+	if l.tls.timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(l.tls.timeout))
+	}
+	if _, err := upgrader.Upgrade(conn); err != nil {
+		return
+	}
+	if l.tls.timeout > 0 {
+		_ = conn.SetDeadline(time.Time{})
+	}
+
+	var writeLock sync.Mutex
+	l.controller.RegisterConnection(conn, func(packet server.Packet) error {
+		writeLock.Lock()
+		defer writeLock.Unlock()
+		return wsutil.WriteServerMessage(conn, packet.GetContext().(ws.OpCode), packet.GetBytes())
+	})
+
+	for {
+		data, op, err := wsutil.ReadClientData(conn)
+		if err != nil {
+			l.controller.EliminateConnection(conn, err)
+			return
+		}
+		packet := server.NewPacket(data)
+		packet.SetContext(op)
+		l.controller.ReactPacket(conn, packet)
+	}
+}
+
+// close 关闭监听器及所有连接
+func (l *websocketTLSListener) close() error {
+	l.lock.Lock()
+	l.closed = true
+	for conn := range l.conns {
+		_ = conn.Close()
+	}
+	l.lock.Unlock()
+	return l.listener.Close()
+}
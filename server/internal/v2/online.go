@@ -0,0 +1,62 @@
+package server
+
+// GetOnline 获取特定 ID 的在线连接
+func (s *controller) GetOnline(id string) (Conn, bool) {
+	s.onlineRW.RLock()
+	c, exist := s.online[id]
+	s.onlineRW.RUnlock()
+	if !exist {
+		return nil, false
+	}
+	return c, true
+}
+
+// IsOnline 检查特定 ID 的连接是否在线
+func (s *controller) IsOnline(id string) bool {
+	_, exist := s.GetOnline(id)
+	return exist
+}
+
+// GetOnlineCount 获取在线连接数量
+func (s *controller) GetOnlineCount() int {
+	s.onlineRW.RLock()
+	defer s.onlineRW.RUnlock()
+	return len(s.online)
+}
+
+// GetOnlineAll 获取所有在线连接的快照
+func (s *controller) GetOnlineAll() []Conn {
+	s.onlineRW.RLock()
+	defer s.onlineRW.RUnlock()
+	conns := make([]Conn, 0, len(s.online))
+	for _, c := range s.online {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+// RangeOnline 遍历所有在线连接，当 handler 返回 false 时将停止遍历
+//   - 遍历的是调用时在线连接的快照，handler 中可安全地关闭连接或发布消息
+func (s *controller) RangeOnline(handler func(conn Conn) bool) {
+	for _, c := range s.GetOnlineAll() {
+		if !handler(c) {
+			return
+		}
+	}
+}
+
+// Broadcast 向所有在线连接广播数据包
+func (s *controller) Broadcast(packet Packet) {
+	s.BroadcastFilter(packet, nil)
+}
+
+// BroadcastFilter 向满足 filter 的在线连接广播数据包，当 filter 为 nil 时将向所有在线连接广播
+//   - 写入失败的连接将被忽略，连接的关闭将由网络层通过 ConnectionClosedEvent 事件报告
+func (s *controller) BroadcastFilter(packet Packet, filter func(conn Conn) bool) {
+	s.RangeOnline(func(conn Conn) bool {
+		if filter == nil || filter(conn) {
+			_ = conn.WritePacket(packet)
+		}
+		return true
+	})
+}
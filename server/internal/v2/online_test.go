@@ -0,0 +1,68 @@
+package server_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/network"
+	"github.com/kercylan98/minotaur/utils/random"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServer_Broadcast(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.NewServer(network.TCP(addr), server.NewOptions().WithLifeCycleLimit(time.Second))
+
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(time.Millisecond * 200)
+		var clients []net.Conn
+		for i := 0; i < 2; i++ {
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			clients = append(clients, conn)
+		}
+		time.Sleep(time.Millisecond * 100)
+
+		if count := srv.GetOnlineCount(); count != 2 {
+			t.Errorf("unexpected online count: %d", count)
+			return
+		}
+		first := clients[0].LocalAddr().String()
+		if !srv.IsOnline(first) {
+			t.Errorf("%s should be online", first)
+		}
+		srv.BroadcastFilter(server.NewPacket([]byte("a")), func(conn server.Conn) bool {
+			return conn.GetID() == first
+		})
+		time.Sleep(time.Millisecond * 50)
+		srv.Broadcast(server.NewPacket([]byte("b")))
+
+		for i, expected := range []string{"ab", "b"} {
+			_ = clients[i].SetReadDeadline(time.Now().Add(time.Millisecond * 300))
+			var buf = make([]byte, 16)
+			var received string
+			for len(received) < len(expected) {
+				n, err := clients[i].Read(buf)
+				if err != nil {
+					break
+				}
+				received += string(buf[:n])
+			}
+			if received != expected {
+				t.Errorf("client %d unexpected data: %s", i, received)
+			}
+		}
+	}()
+
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+}
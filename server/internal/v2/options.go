@@ -1,8 +1,11 @@
 package server
 
 import (
+	"github.com/kercylan98/minotaur/toolkit/nexus/queues"
 	"github.com/kercylan98/minotaur/utils/log/v2"
+	"github.com/panjf2000/ants/v2"
 	"os"
+	"runtime"
 	"sync"
 	"time"
 )
@@ -13,11 +16,14 @@ func NewOptions() *Options {
 
 func DefaultOptions() *Options {
 	return &Options{
-		serverMessageChannelSize:       1024 * 4,
+		serverMessageChannelSize:       1024 * 8,
 		actorMessageChannelSize:        1024,
 		serverMessageBufferInitialSize: 1024,
 		actorMessageBufferInitialSize:  1024,
 		lifeCycleLimit:                 0,
+		antsPoolSize:                   ants.DefaultAntsPoolSize,
+		serverMessageQueueNum:          runtime.NumCPU(),
+		shutdownTimeout:                0,
 		logger:                         log.NewLogger(log.NewHandler(os.Stdout, log.DefaultOptions().WithCallerSkip(-1).WithLevel(log.LevelInfo))),
 	}
 }
@@ -25,15 +31,26 @@ func DefaultOptions() *Options {
 type Options struct {
 	server                         *server
 	rw                             sync.RWMutex
-	serverMessageChannelSize       int           // 服务器 Actor 消息处理管道大小
-	actorMessageChannelSize        int           // Actor 消息处理管道大小
-	serverMessageBufferInitialSize int           // 服务器 Actor 消息写入缓冲区初始化大小
-	actorMessageBufferInitialSize  int           // Actor 消息写入缓冲区初始化大小
-	lifeCycleLimit                 time.Duration // 服务器生命周期上限，在服务器启动后达到生命周期上限将关闭服务器
-	logger                         *log.Logger   // 日志记录器
-	debug                          bool          // Debug 模式
-	syncLowMessageDuration         time.Duration // 同步慢消息时间
-	asyncLowMessageDuration        time.Duration // 异步慢消息时间
+	serverMessageChannelSize       int                            // 服务器 Actor 消息处理管道大小
+	actorMessageChannelSize        int                            // Actor 消息处理管道大小
+	serverMessageBufferInitialSize int                            // 服务器 Actor 消息写入缓冲区初始化大小
+	actorMessageBufferInitialSize  int                            // Actor 消息写入缓冲区初始化大小
+	lifeCycleLimit                 time.Duration                  // 服务器生命周期上限，在服务器启动后达到生命周期上限将关闭服务器
+	logger                         *log.Logger                    // 日志记录器
+	debug                          bool                           // Debug 模式
+	syncLowMessageDuration         time.Duration                  // 同步慢消息时间
+	asyncLowMessageDuration        time.Duration                  // 异步慢消息时间
+	antsPoolSize                   int                            // 异步消息协程池大小
+	serverMessageQueueNum          int                            // 服务器消息队列数量，即处理消息的工作协程数量
+	shutdownTimeout                time.Duration                  // 服务器关闭超时时间
+	launchedHooks                  []LaunchedEventHandler         // 服务器启动钩子
+	shutdownHooks                  []ShutdownEventHandler         // 服务器关闭钩子
+	packetOpcodeParser             PacketOpcodeParser             // 数据包操作码解析器
+	serverMessageLimit             int                            // 服务器消息队列缓冲区消息数量上限
+	serverMessageOverflowPolicy    queues.OverflowPolicy          // 服务器消息队列缓冲区溢出策略
+	serverMessageOverflowTimeout   time.Duration                  // 服务器消息队列缓冲区溢出阻塞超时时间
+	serverMessageSpillDir          string                         // 服务器消息队列溢出消息的暂存目录
+	serverMessageSpillCodec        queues.SpillCodec[int, string] // 服务器消息队列溢出消息的编解码器
 }
 
 func (opt *Options) init(srv *server) *Options {
@@ -43,7 +60,6 @@ func (opt *Options) init(srv *server) *Options {
 
 func (opt *Options) Apply(options ...*Options) {
 	opt.rw.Lock()
-	defer opt.rw.Unlock()
 	for _, option := range options {
 		option.rw.RLock()
 
@@ -56,9 +72,26 @@ func (opt *Options) Apply(options ...*Options) {
 		opt.debug = option.debug
 		opt.syncLowMessageDuration = option.syncLowMessageDuration
 		opt.asyncLowMessageDuration = option.asyncLowMessageDuration
+		opt.antsPoolSize = option.antsPoolSize
+		opt.serverMessageQueueNum = option.serverMessageQueueNum
+		opt.shutdownTimeout = option.shutdownTimeout
+		opt.packetOpcodeParser = option.packetOpcodeParser
+		opt.serverMessageLimit = option.serverMessageLimit
+		opt.serverMessageOverflowPolicy = option.serverMessageOverflowPolicy
+		opt.serverMessageOverflowTimeout = option.serverMessageOverflowTimeout
+		opt.serverMessageSpillDir = option.serverMessageSpillDir
+		opt.serverMessageSpillCodec = option.serverMessageSpillCodec
+		opt.launchedHooks = append(opt.launchedHooks, option.launchedHooks...)
+		opt.shutdownHooks = append(opt.shutdownHooks, option.shutdownHooks...)
 
 		option.rw.RUnlock()
 	}
+	var antsPoolSize = opt.antsPoolSize
+	opt.rw.Unlock()
+
+	if opt.server != nil && opt.server.ants != nil {
+		opt.server.ants.Tune(antsPoolSize)
+	}
 	if opt.server != nil && !opt.server.state.LaunchedAt.IsZero() {
 		opt.active()
 	}
@@ -207,6 +240,118 @@ func (opt *Options) GetLifeCycleLimit() time.Duration {
 	})
 }
 
+// WithAntsPoolSize 设置服务器处理异步消息的协程池大小，当 size <= 0 时将不限制协程池大小
+//   - 默认值为 ants.DefaultAntsPoolSize
+//   - 该函数支持运行时设置，运行时设置为 <= 0 的值将被忽略
+func (opt *Options) WithAntsPoolSize(size int) *Options {
+	return opt.modifyOptionsValue(func(opt *Options) {
+		opt.antsPoolSize = size
+	})
+}
+
+func (opt *Options) GetAntsPoolSize() int {
+	return getOptionsValue(opt, func(opt *Options) int {
+		return opt.antsPoolSize
+	})
+}
+
+// WithServerMessageQueueNum 设置服务器消息队列的数量，每个队列将由独立的协程进行处理，同一 Topic 的消息总是在同一队列中顺序处理
+//   - 默认值为 runtime.NumCPU()，当 num <= 0 时将使用默认值
+//   - 该函数仅在服务器创建时生效
+func (opt *Options) WithServerMessageQueueNum(num int) *Options {
+	return opt.modifyOptionsValue(func(opt *Options) {
+		opt.serverMessageQueueNum = num
+	})
+}
+
+func (opt *Options) GetServerMessageQueueNum() int {
+	return getOptionsValue(opt, func(opt *Options) int {
+		return opt.serverMessageQueueNum
+	})
+}
+
+// WithShutdownTimeout 设置服务器关闭的超时时间，当关闭服务器的网络及消息队列超过该时间时将放弃等待并返回 ErrShutdownTimeout
+//   - 如果设置为 <= 0 的值，将一直等待至关闭完成
+//   - 该函数支持运行时设置
+func (opt *Options) WithShutdownTimeout(timeout time.Duration) *Options {
+	return opt.modifyOptionsValue(func(opt *Options) {
+		opt.shutdownTimeout = timeout
+	})
+}
+
+func (opt *Options) GetShutdownTimeout() time.Duration {
+	return getOptionsValue(opt, func(opt *Options) time.Duration {
+		return opt.shutdownTimeout
+	})
+}
+
+// WithLaunchedHook 添加服务器启动时执行的钩子函数，效果等同于在创建服务器后通过 RegisterLaunchedEvent 注册
+//   - 该函数仅在服务器创建时生效
+func (opt *Options) WithLaunchedHook(hooks ...LaunchedEventHandler) *Options {
+	return opt.modifyOptionsValue(func(opt *Options) {
+		opt.launchedHooks = append(opt.launchedHooks, hooks...)
+	})
+}
+
+// WithShutdownHook 添加服务器关闭时执行的钩子函数，效果等同于在创建服务器后通过 RegisterShutdownEvent 注册
+//   - 该函数仅在服务器创建时生效
+func (opt *Options) WithShutdownHook(hooks ...ShutdownEventHandler) *Options {
+	return opt.modifyOptionsValue(func(opt *Options) {
+		opt.shutdownHooks = append(opt.shutdownHooks, hooks...)
+	})
+}
+
+// WithPacketOpcodeParser 设置数据包操作码解析器，解析出的操作码将用于匹配通过 Route 注册的路由
+//   - 该函数支持运行时设置
+func (opt *Options) WithPacketOpcodeParser(parser PacketOpcodeParser) *Options {
+	return opt.modifyOptionsValue(func(opt *Options) {
+		opt.packetOpcodeParser = parser
+	})
+}
+
+func (opt *Options) GetPacketOpcodeParser() PacketOpcodeParser {
+	return getOptionsValue(opt, func(opt *Options) PacketOpcodeParser {
+		return opt.packetOpcodeParser
+	})
+}
+
+// WithServerMessageOverflowPolicy 设置服务器每个消息队列缓冲区中等待处理的消息数量上限及溢出策略，默认为 queues.OverflowPolicyExpand
+//   - timeout 仅在 queues.OverflowPolicyBlock 策略下生效，当 timeout <= 0 时将一直阻塞
+//   - 溢出的消息将被丢弃并记录警告日志，使用 queues.OverflowPolicySpill 策略时需要通过 WithServerMessageSpill 设置暂存溢出消息的目录
+//   - 该函数仅在服务器创建时生效
+func (opt *Options) WithServerMessageOverflowPolicy(limit int, policy queues.OverflowPolicy, timeout time.Duration) *Options {
+	return opt.modifyOptionsValue(func(opt *Options) {
+		opt.serverMessageLimit = limit
+		opt.serverMessageOverflowPolicy = policy
+		opt.serverMessageOverflowTimeout = timeout
+	})
+}
+
+func (opt *Options) GetServerMessageOverflowPolicy() (limit int, policy queues.OverflowPolicy, timeout time.Duration) {
+	opt.getManyOptions(func(opt *Options) {
+		limit, policy, timeout = opt.serverMessageLimit, opt.serverMessageOverflowPolicy, opt.serverMessageOverflowTimeout
+	})
+	return
+}
+
+// WithServerMessageSpill 设置服务器消息队列在 queues.OverflowPolicySpill 策略下暂存溢出消息的目录及编解码器，每个消息队列将在目录中使用独立的溢出文件
+//   - 服务器内部产生的消息通常无法被编码，编解码器应当仅处理自定义的消息，无法编码的消息将保留在内存中
+//   - 溢出文件仅用于缓解短时间的下游阻塞，并非持久化存储，文件将在服务器创建时清空，并在服务器关闭后删除
+//   - 该函数仅在服务器创建时生效
+func (opt *Options) WithServerMessageSpill(dir string, codec queues.SpillCodec[int, string]) *Options {
+	return opt.modifyOptionsValue(func(opt *Options) {
+		opt.serverMessageSpillDir = dir
+		opt.serverMessageSpillCodec = codec
+	})
+}
+
+func (opt *Options) GetServerMessageSpill() (dir string, codec queues.SpillCodec[int, string]) {
+	opt.getManyOptions(func(opt *Options) {
+		dir, codec = opt.serverMessageSpillDir, opt.serverMessageSpillCodec
+	})
+	return
+}
+
 func (opt *Options) modifyOptionsValue(handler func(opt *Options)) *Options {
 	opt.rw.Lock()
 	handler(opt)
@@ -0,0 +1,39 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/network"
+	"github.com/kercylan98/minotaur/utils/random"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOptions_Hooks(t *testing.T) {
+	var launched, shutdown, handled atomic.Bool
+	srv := server.NewServer(network.Http(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())), server.NewOptions().
+		WithLifeCycleLimit(time.Second).
+		WithAntsPoolSize(16).
+		WithServerMessageQueueNum(2).
+		WithShutdownTimeout(time.Second*5).
+		WithLaunchedHook(func(srv server.Server, ip string, launchedAt time.Time) {
+			launched.Store(true)
+			srv.PublishAsyncMessage("options", func(ctx context.Context) error {
+				handled.Store(true)
+				return nil
+			})
+		}).
+		WithShutdownHook(func(srv server.Server) {
+			shutdown.Store(true)
+		}),
+	)
+
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !launched.Load() || !shutdown.Load() || !handled.Load() {
+		t.Fatalf("launched: %v, shutdown: %v, handled: %v", launched.Load(), shutdown.Load(), handled.Load())
+	}
+}
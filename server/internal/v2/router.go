@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log/v2"
+	"sync"
+)
+
+type (
+	// PacketOpcodeParser 从数据包中解析操作码及用于解码消息的数据，当无法解析时应返回 false，此时数据包将交由 ConnectionReceivePacketEvent 事件处理
+	PacketOpcodeParser func(packet Packet) (opcode string, data []byte, ok bool)
+
+	// PacketDecoder 将数据包中的数据解码为特定类型的消息
+	//  - 对于 protobuf 等需要预先创建消息实例的协议，可通过如下方式创建解码器：
+	//    func(data []byte) (*pb.Message, error) { m := new(pb.Message); return m, proto.Unmarshal(data, m) }
+	PacketDecoder[T any] func(data []byte) (T, error)
+
+	// RouteHandler 经过解码的消息处理函数
+	RouteHandler[T any] func(conn Conn, message T)
+)
+
+// JSONDecoder 创建基于 encoding/json 的解码器
+func JSONDecoder[T any]() PacketDecoder[T] {
+	return func(data []byte) (message T, err error) {
+		err = json.Unmarshal(data, &message)
+		return
+	}
+}
+
+// Route 为特定操作码注册消息处理函数，当连接接收到该操作码的数据包时，将通过 decoder 解码后交由 handler 处理
+//   - 需要通过 Options.WithPacketOpcodeParser 设置操作码解析器，否则路由不会生效
+//   - 路由将在连接的 Actor 中经过数据包中间件后执行，命中路由的数据包将不再触发 ConnectionReceivePacketEvent 事件
+//   - 解码失败的数据包将被丢弃并记录警告日志
+//   - 重复注册同一操作码将会发生 panic
+func Route[T any](srv Server, opcode string, decoder PacketDecoder[T], handler RouteHandler[T]) {
+	srv.(interface{ getRouter() *router }).getRouter().route(opcode, func(conn Conn, data []byte) error {
+		message, err := decoder(data)
+		if err != nil {
+			return err
+		}
+		handler(conn, message)
+		return nil
+	})
+}
+
+// router 基于操作码的数据包路由器
+type router struct {
+	*server
+	routesRW sync.RWMutex
+	routes   map[string]func(conn Conn, data []byte) error
+}
+
+func (r *router) init(srv *server) *router {
+	r.server = srv
+	r.routes = make(map[string]func(conn Conn, data []byte) error)
+	return r
+}
+
+func (r *router) getRouter() *router {
+	return r
+}
+
+func (r *router) route(opcode string, handler func(conn Conn, data []byte) error) {
+	r.routesRW.Lock()
+	defer r.routesRW.Unlock()
+	if _, exist := r.routes[opcode]; exist {
+		panic(fmt.Errorf("server: route %s already registered", opcode))
+	}
+	r.routes[opcode] = handler
+}
+
+// dispatch 将数据包分发至操作码对应的处理函数，当数据包未命中任何路由时返回 false
+func (r *router) dispatch(conn Conn, packet Packet) bool {
+	parser := r.GetPacketOpcodeParser()
+	if parser == nil {
+		return false
+	}
+	opcode, data, ok := parser(packet)
+	if !ok {
+		return false
+	}
+	r.routesRW.RLock()
+	handler, exist := r.routes[opcode]
+	r.routesRW.RUnlock()
+	if !exist {
+		return false
+	}
+	if err := handler(conn, data); err != nil {
+		r.GetLogger().WarnContext(conn.GetContext(), "Router", log.String("opcode", opcode), log.String("state", "decode"), log.Err(err))
+	}
+	return true
+}
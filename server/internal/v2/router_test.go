@@ -0,0 +1,61 @@
+package server_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/network"
+	"github.com/kercylan98/minotaur/utils/random"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRoute(t *testing.T) {
+	type Login struct {
+		Name string `json:"name"`
+	}
+
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.NewServer(network.TCP(addr), server.NewOptions().
+		WithLifeCycleLimit(time.Second).
+		WithPacketOpcodeParser(func(packet server.Packet) (opcode string, data []byte, ok bool) {
+			before, after, found := bytes.Cut(packet.GetBytes(), []byte("|"))
+			return string(before), after, found
+		}),
+	)
+	server.Route(srv, "login", server.JSONDecoder[Login](), func(conn server.Conn, message Login) {
+		_ = conn.WriteBytes([]byte("hello " + message.Name))
+	})
+	srv.RegisterConnectionReceivePacketEvent(func(srv server.Server, conn server.Conn, packet server.Packet) {
+		_ = conn.WriteBytes([]byte("unrouted"))
+	})
+
+	var received []string
+	go func() {
+		time.Sleep(time.Millisecond * 200)
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		for _, data := range []string{`login|{"name":"minotaur"}`, `login|{`, `chat|hi`} {
+			if _, err = conn.Write([]byte(data)); err != nil {
+				t.Error(err)
+				return
+			}
+			_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond * 100))
+			var buf = make([]byte, 32)
+			n, _ := conn.Read(buf)
+			received = append(received, string(buf[:n]))
+		}
+	}()
+
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"hello minotaur", "", "unrouted"}; fmt.Sprint(received) != fmt.Sprint(expected) {
+		t.Fatalf("unexpected received: %q", received)
+	}
+}
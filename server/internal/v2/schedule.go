@@ -0,0 +1,167 @@
+package server
+
+import (
+	messageEvents "github.com/kercylan98/minotaur/toolkit/nexus/events"
+	"github.com/kercylan98/minotaur/utils/times"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Schedule 定时任务句柄，可用于停止定时任务，服务器关闭时所有定时任务将被自动停止
+type Schedule struct {
+	scheduler *scheduler
+	queue     string
+	next      func(now time.Time) time.Time // 计算下一次执行时间，为 nil 时表示仅执行一次
+	handler   messageEvents.SynchronousHandler
+	timer     *time.Timer
+	stopped   atomic.Bool
+	lock      sync.Mutex
+}
+
+// GetQueue 获取定时任务执行所在的消息队列名称
+func (s *Schedule) GetQueue() string {
+	return s.queue
+}
+
+// Stop 停止定时任务，已发布到消息队列中的任务仍然会被执行
+func (s *Schedule) Stop() {
+	if !s.stopped.CompareAndSwap(false, true) {
+		return
+	}
+	s.lock.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.lock.Unlock()
+	s.scheduler.remove(s)
+}
+
+// IsStopped 检查定时任务是否已停止
+func (s *Schedule) IsStopped() bool {
+	return s.stopped.Load()
+}
+
+// schedule 在 delay 后将任务发布至消息队列，存在下一次执行时间时将继续调度
+func (s *Schedule) schedule(delay time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopped.Load() {
+		return
+	}
+	s.timer = time.AfterFunc(delay, func() {
+		if s.stopped.Load() {
+			return
+		}
+		s.scheduler.PublishSyncMessage(s.queue, s.handler)
+		if s.next == nil {
+			s.Stop()
+			return
+		}
+		now := time.Now()
+		next := s.next(now)
+		if next.IsZero() {
+			s.Stop()
+			return
+		}
+		s.schedule(next.Sub(now))
+	})
+}
+
+// scheduler 服务器的定时任务调度器，通过 time.AfterFunc 在执行时间到达时将任务发布至消息队列
+//   - 定时任务需要通过句柄单独停止、发布至各自的消息队列中执行，并支持按照时区计算 cron 表达式的执行时间
+//   - timer.Ticker 的调度器以名称区分且同名替换，同一 Ticker 仅能设置一个处理函数，且 cron 表达式不支持指定时区，因此未使用 timer.Ticker
+type scheduler struct {
+	*server
+	schedules     map[*Schedule]struct{} // 未停止的定时任务
+	schedulesLock sync.Mutex             // 定时任务锁
+}
+
+func (s *scheduler) init(srv *server) *scheduler {
+	s.server = srv
+	s.schedules = make(map[*Schedule]struct{})
+	return s
+}
+
+// After 在 delay 后于 queue 消息队列中执行一次 handler，当 queue 为空时将在系统队列中执行
+//   - 同一消息队列中的定时任务与其他消息顺序执行，无需额外的同步
+func (s *scheduler) After(queue string, delay time.Duration, handler messageEvents.SynchronousHandler) *Schedule {
+	schedule := s.add(queue, nil, handler)
+	schedule.schedule(delay)
+	return schedule
+}
+
+// Every 每隔 interval 于 queue 消息队列中执行一次 handler，当 queue 为空时将在系统队列中执行
+//   - 首次执行将在 interval 后进行
+//   - 当 interval <= 0 时将会发生 panic
+func (s *scheduler) Every(queue string, interval time.Duration, handler messageEvents.SynchronousHandler) *Schedule {
+	if interval <= 0 {
+		panic("server: non-positive interval for Every")
+	}
+	schedule := s.add(queue, func(now time.Time) time.Time {
+		return now.Add(interval)
+	}, handler)
+	schedule.schedule(interval)
+	return schedule
+}
+
+// Cron 通过 cron 表达式定时于 queue 消息队列中执行 handler，当 queue 为空时将在系统队列中执行
+//   - expression 支持秒级精度，例如 "0 0 4 * * *" 表示每天凌晨 4 点执行，格式可参考 times.ParseCron
+//   - location 为可选的时区，默认为 time.Local
+//   - 当 cron 表达式错误时将返回错误
+func (s *scheduler) Cron(queue, expression string, handler messageEvents.SynchronousHandler, location ...*time.Location) (*Schedule, error) {
+	expr, err := times.ParseCron(expression)
+	if err != nil {
+		return nil, err
+	}
+	var loc = time.Local
+	if len(location) > 0 && location[0] != nil {
+		loc = location[0]
+	}
+	next := func(now time.Time) time.Time {
+		return expr.Next(now.In(loc))
+	}
+	schedule := s.add(queue, next, handler)
+	now := time.Now()
+	if at := next(now); at.IsZero() {
+		schedule.Stop()
+	} else {
+		schedule.schedule(at.Sub(now))
+	}
+	return schedule, nil
+}
+
+func (s *scheduler) add(queue string, next func(now time.Time) time.Time, handler messageEvents.SynchronousHandler) *Schedule {
+	if queue == "" {
+		queue = s.getSysQueue()
+	}
+	schedule := &Schedule{
+		scheduler: s,
+		queue:     queue,
+		next:      next,
+		handler:   handler,
+	}
+	s.schedulesLock.Lock()
+	s.schedules[schedule] = struct{}{}
+	s.schedulesLock.Unlock()
+	return schedule
+}
+
+func (s *scheduler) remove(schedule *Schedule) {
+	s.schedulesLock.Lock()
+	delete(s.schedules, schedule)
+	s.schedulesLock.Unlock()
+}
+
+// stopAll 停止所有定时任务
+func (s *scheduler) stopAll() {
+	s.schedulesLock.Lock()
+	var schedules = make([]*Schedule, 0, len(s.schedules))
+	for schedule := range s.schedules {
+		schedules = append(schedules, schedule)
+	}
+	s.schedulesLock.Unlock()
+	for _, schedule := range schedules {
+		schedule.Stop()
+	}
+}
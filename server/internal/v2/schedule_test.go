@@ -0,0 +1,44 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/server/internal/v2"
+	"github.com/kercylan98/minotaur/server/internal/v2/network"
+	"github.com/kercylan98/minotaur/utils/random"
+	"testing"
+	"time"
+)
+
+func TestServer_Schedule(t *testing.T) {
+	srv := server.NewServer(network.Http(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())), server.NewOptions().WithLifeCycleLimit(time.Millisecond*1500))
+
+	// 所有任务均在同一消息队列中执行，因此无需同步
+	var after, every, cron int
+	srv.RegisterLaunchedEvent(func(srv server.Server, ip string, launchedAt time.Time) {
+		srv.After("player", time.Millisecond*100, func(ctx context.Context) {
+			after++
+		})
+		srv.After("player", time.Millisecond*100, func(ctx context.Context) {
+			t.Error("stopped schedule should not be executed")
+		}).Stop()
+		srv.Every("player", time.Millisecond*200, func(ctx context.Context) {
+			every++
+		})
+		if _, err := srv.Cron("player", "* * * * * *", func(ctx context.Context) {
+			cron++
+		}); err != nil {
+			t.Error(err)
+		}
+		if _, err := srv.Cron("player", "invalid", nil); err == nil {
+			t.Error("invalid expression should return error")
+		}
+	})
+
+	if err := srv.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if after != 1 || every < 5 || cron < 1 {
+		t.Fatalf("after: %d, every: %d, cron: %d", after, every, cron)
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/kercylan98/minotaur/toolkit/nexus"
 	"github.com/kercylan98/minotaur/toolkit/nexus/brokers"
@@ -11,12 +12,17 @@ import (
 	"github.com/kercylan98/minotaur/utils/log/v2"
 	"github.com/kercylan98/minotaur/utils/random"
 	"github.com/panjf2000/ants/v2"
+	"path/filepath"
 	"reflect"
 	"time"
 
 	"github.com/kercylan98/minotaur/utils/network"
 )
 
+var (
+	ErrShutdownTimeout = errors.New("server: shutdown timeout")
+)
+
 type Server interface {
 	Events
 
@@ -34,11 +40,51 @@ type Server interface {
 
 	// PublishAsyncMessage 发布异步消息，当包含多个 callback 时，仅首个生效
 	PublishAsyncMessage(topic string, handler messageEvents.AsynchronousHandler, callback ...messageEvents.AsynchronousCallbackHandler)
+
+	// SpawnActor 创建一个拥有独立邮箱的 Actor，当 Actor 处理消息发生 panic 时将根据监督策略通过 producer 重启
+	//  - name 同时作为 Actor 邮箱的消息队列名称，当 name 已存在时将返回 ErrActorExists
+	SpawnActor(name string, producer ActorProducer, options ...*ActorOptions) (Actor, error)
+
+	// GetActor 获取特定名称的 Actor
+	GetActor(name string) (Actor, bool)
+
+	// After 在 delay 后于 queue 消息队列中执行一次 handler，当 queue 为空时将在系统队列中执行
+	After(queue string, delay time.Duration, handler messageEvents.SynchronousHandler) *Schedule
+
+	// Every 每隔 interval 于 queue 消息队列中执行一次 handler，当 queue 为空时将在系统队列中执行
+	Every(queue string, interval time.Duration, handler messageEvents.SynchronousHandler) *Schedule
+
+	// Cron 通过支持秒级精度的 cron 表达式定时于 queue 消息队列中执行 handler，当 queue 为空时将在系统队列中执行
+	Cron(queue, expression string, handler messageEvents.SynchronousHandler, location ...*time.Location) (*Schedule, error)
+
+	// GetOnline 获取特定 ID 的在线连接，连接 ID 可通过 Conn.GetID 获取
+	GetOnline(id string) (Conn, bool)
+
+	// IsOnline 检查特定 ID 的连接是否在线
+	IsOnline(id string) bool
+
+	// GetOnlineCount 获取在线连接数量
+	GetOnlineCount() int
+
+	// GetOnlineAll 获取所有在线连接的快照
+	GetOnlineAll() []Conn
+
+	// RangeOnline 遍历所有在线连接，当 handler 返回 false 时将停止遍历
+	RangeOnline(handler func(conn Conn) bool)
+
+	// Broadcast 向所有在线连接广播数据包，可在任意协程中调用
+	Broadcast(packet Packet)
+
+	// BroadcastFilter 向满足 filter 的在线连接广播数据包，可在任意协程中调用
+	BroadcastFilter(packet Packet, filter func(conn Conn) bool)
 }
 
 type server struct {
 	*controller
 	*events
+	*actors
+	*router
+	*scheduler
 	*Options
 	queue   string
 	ants    *ants.Pool
@@ -60,14 +106,27 @@ func NewServer(network Network, options ...*Options) Server {
 	srv.notify = new(notify).init(srv)
 	srv.controller = new(controller).init(srv)
 	srv.events = new(events).init(srv)
+	srv.actors = new(actors).init(srv)
+	srv.router = new(router).init(srv)
+	srv.scheduler = new(scheduler).init(srv)
 	srv.state = new(State).init(srv)
-	srv.broker = brokers.NewSparseGoroutine(func(index int) nexus.Queue[int, string] {
-		return queues.NewNonBlockingRW[int, string](index, 1024*8, 1024)
+	srv.Options.init(srv).Apply(options...)
+	srv.broker = brokers.NewSparseGoroutineWithQueueNum(srv.GetServerMessageQueueNum(), func(index int) nexus.Queue[int, string] {
+		queue := queues.NewNonBlockingRW[int, string](index, srv.GetServerMessageChannelSize(), srv.GetServerMessageBufferInitialSize()).(*queues.NonBlockingRW[int, string])
+		queue.SetOverflowPolicy(srv.GetServerMessageOverflowPolicy())
+		queue.SetOverflowHandler(srv.onMessageOverflow)
+		if dir, codec := srv.GetServerMessageSpill(); len(dir) > 0 && codec != nil {
+			spool, err := queues.NewFileSpool(filepath.Join(dir, fmt.Sprintf("queue-%d.spill", index)))
+			if err != nil {
+				panic(err)
+			}
+			queue.SetSpill(spool, codec)
+		}
+		return queue
 	}, func(handler nexus.EventExecutor) {
 		handler()
 	})
-	srv.Options.init(srv).Apply(options...)
-	antsPool, err := ants.NewPool(ants.DefaultAntsPoolSize, ants.WithOptions(ants.Options{
+	antsPool, err := ants.NewPool(srv.GetAntsPoolSize(), ants.WithOptions(ants.Options{
 		ExpiryDuration: 10 * time.Second,
 		Nonblocking:    true,
 		//Logger:         &antsLogger{logging.GetDefaultLogger()},
@@ -78,6 +137,15 @@ func NewServer(network Network, options ...*Options) Server {
 	if err != nil {
 		panic(err)
 	}
+	srv.Options.modifyOptionsValue(func(opt *Options) {
+		for _, hook := range opt.launchedHooks {
+			srv.RegisterLaunchedEvent(hook)
+		}
+		for _, hook := range opt.shutdownHooks {
+			srv.RegisterShutdownEvent(hook)
+		}
+		opt.launchedHooks, opt.shutdownHooks = nil, nil
+	})
 	srv.ants = antsPool
 	return srv
 }
@@ -91,7 +159,7 @@ func (s *server) Run() (err error) {
 	ip, _ := network.IP()
 	s.state.onLaunched(ip.String(), time.Now())
 	go func(s *server) {
-		if err = s.network.OnRun(); err != nil {
+		if err := s.network.OnRun(); err != nil {
 			panic(err)
 		}
 	}(s)
@@ -108,18 +176,45 @@ func (s *server) Shutdown() (err error) {
 	}(time.Now())
 
 	defer s.cancel()
+	defer s.events.onStop()
 	DisableHttpPProf()
 	s.events.onShutdown()
-	err = s.network.OnShutdown()
-	s.broker.Close()
+	s.scheduler.stopAll()
+
+	var done = make(chan error, 1)
+	go func(s *server) {
+		err := s.network.OnShutdown()
+		s.broker.Close()
+		done <- err
+	}(s)
+	if timeout := s.GetShutdownTimeout(); timeout > 0 {
+		select {
+		case err = <-done:
+		case <-time.After(timeout):
+			err = ErrShutdownTimeout
+			s.GetLogger().Warn("Minotaur Server", log.String("", "ShutdownInfo"), log.String("state", "timeout"), log.String("timeout", timeout.String()))
+		}
+		return
+	}
+	err = <-done
 	return
 }
 
+// onMessageOverflow 服务器消息队列缓冲区溢出时记录警告日志
+func (s *server) onMessageOverflow(queue int, topic string, policy queues.OverflowPolicy, event nexus.Event[int, string]) {
+	s.GetLogger().Warn("Minotaur Server", log.String("", "MessageOverflow"), log.Int("queue", queue), log.String("topic", topic), log.String("policy", policy.String()))
+}
+
 func (s *server) getSysQueue() string {
 	return s.queue
 }
 
 func (s *server) PublishMessage(topic string, event nexus.Event[int, string]) {
+	if topic == s.getSysQueue() {
+		// 系统队列的消息以高优先级发布，避免在与繁忙的连接共用队列时被阻塞
+		s.broker.PublishPriority(topic, event)
+		return
+	}
 	s.broker.Publish(topic, event)
 }
 
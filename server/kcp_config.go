@@ -0,0 +1,41 @@
+package server
+
+import (
+	"github.com/xtaci/kcp-go/v5"
+)
+
+// KCPConfig KCP 会话配置，将在接受连接时应用于每个 kcp.UDPSession
+//   - 实时性要求较高的游戏通常使用极速模式：NoDelay: true, Interval: 10, Resend: 2, NC: true
+type KCPConfig struct {
+	NoDelay   bool // 是否启用 nodelay 模式
+	Interval  int  // 内部工作的间隔（毫秒），当值 <= 0 时使用 KCP 默认值
+	Resend    int  // 快速重传的 ACK 跨越次数，0 表示关闭快速重传
+	NC        bool // 是否关闭拥塞控制
+	SndWnd    int  // 发送窗口大小，当值 <= 0 时使用 KCP 默认值
+	RcvWnd    int  // 接收窗口大小，当值 <= 0 时使用 KCP 默认值
+	MTU       int  // 最大传输单元，当值 <= 0 时使用 KCP 默认值
+	FECData   int  // 前向纠错的数据分片数量，需要与客户端保持一致
+	FECParity int  // 前向纠错的校验分片数量，需要与客户端保持一致
+}
+
+// Apply 将配置应用于 KCP 会话，同样适用于客户端通过 kcp.DialWithOptions 创建的会话
+func (slf *KCPConfig) Apply(session *kcp.UDPSession) {
+	var nodelay, nc int
+	if slf.NoDelay {
+		nodelay = 1
+	}
+	if slf.NC {
+		nc = 1
+	}
+	interval := slf.Interval
+	if interval <= 0 {
+		interval = -1 // 小于 0 时 SetNoDelay 将保持原有值
+	}
+	session.SetNoDelay(nodelay, interval, slf.Resend, nc)
+	if slf.SndWnd > 0 || slf.RcvWnd > 0 {
+		session.SetWindowSize(slf.SndWnd, slf.RcvWnd)
+	}
+	if slf.MTU > 0 {
+		session.SetMtu(slf.MTU)
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+	"sort"
+	"time"
+)
+
+// runLimitLife 在通过 WithLimitLife 限制了最大生命周期时开始倒计时，并在到达每个预警阈值时触发 OnLifeEndingEvent 事件
+func (srv *Server) runLimitLife() {
+	if srv.limitLife <= 0 {
+		return
+	}
+	srv.lifeDeadline.Store(time.Now().Add(srv.limitLife).UnixNano())
+	thresholds := make([]time.Duration, 0, len(srv.lifeThresholds))
+	for _, threshold := range srv.lifeThresholds {
+		if threshold > 0 {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	sort.Slice(thresholds, func(i, j int) bool {
+		return thresholds[i] > thresholds[j]
+	})
+
+	go func(srv *Server, thresholds []time.Duration) {
+		fired := make([]bool, len(thresholds))
+		for {
+			now := time.Now()
+			deadline := time.Unix(0, srv.lifeDeadline.Load())
+			if !now.Before(deadline) {
+				log.Info("Server", log.String("listen", srv.addr), log.String("action", "shutdown"), log.String("state", "life-ended"))
+				srv.Shutdown()
+				return
+			}
+
+			next := deadline
+			for i, threshold := range thresholds {
+				at := deadline.Add(-threshold)
+				if now.Before(at) {
+					// 延长生命周期后，尚未到达的阈值将重新生效
+					fired[i] = false
+					if at.Before(next) {
+						next = at
+					}
+					continue
+				}
+				if !fired[i] {
+					fired[i] = true
+					srv.OnLifeEndingEvent(deadline.Sub(now))
+				}
+			}
+
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-srv.ctx.Done():
+				timer.Stop()
+				return
+			case <-srv.lifeExtended:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}(srv, thresholds)
+}
+
+// ExtendLife 延长通过 WithLimitLife 限制的最大生命周期，例如在 OnLifeEndingEvent 事件中延长以完成当前对局
+//   - 延长后尚未到达的预警阈值将重新生效
+//   - 当服务器未限制最大生命周期或尚未启动时将返回 false
+func (srv *Server) ExtendLife(d time.Duration) bool {
+	if srv.limitLife <= 0 || srv.lifeDeadline.Load() == 0 {
+		return false
+	}
+	srv.lifeDeadline.Add(int64(d))
+	select {
+	case srv.lifeExtended <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// GetLifeRemaining 获取通过 WithLimitLife 限制的最大生命周期的剩余时长，当服务器未限制最大生命周期或尚未启动时将返回 0
+func (srv *Server) GetLifeRemaining() time.Duration {
+	deadline := srv.lifeDeadline.Load()
+	if srv.limitLife <= 0 || deadline == 0 {
+		return 0
+	}
+	return max(time.Until(time.Unix(0, deadline)), 0)
+}
@@ -16,7 +16,7 @@ type listener struct {
 }
 
 func (l *listener) init() *listener {
-	l.srv.OnStartBeforeEvent()
+	l.srv.startBeforeOnce.Do(l.srv.OnStartBeforeEvent)
 	return l
 }
 
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+)
+
+const (
+	// LoginConflictPolicyKickOld 踢出已绑定相同身份的连接，这是默认策略
+	LoginConflictPolicyKickOld LoginConflictPolicy = iota
+	// LoginConflictPolicyRejectNew 拒绝新连接绑定身份，已绑定的连接不受影响
+	LoginConflictPolicyRejectNew
+	// LoginConflictPolicyMultiSession 允许相同身份同时绑定多个连接，超出数量限制时将踢出最早绑定的连接
+	LoginConflictPolicyMultiSession
+)
+
+var loginConflictPolicyNames = map[LoginConflictPolicy]string{
+	LoginConflictPolicyKickOld:      "LoginConflictPolicyKickOld",
+	LoginConflictPolicyRejectNew:    "LoginConflictPolicyRejectNew",
+	LoginConflictPolicyMultiSession: "LoginConflictPolicyMultiSession",
+}
+
+// LoginConflictPolicy 不同连接绑定相同身份时的处理策略
+type LoginConflictPolicy byte
+
+// String 返回处理策略的字符串表示
+func (slf LoginConflictPolicy) String() string {
+	return loginConflictPolicyNames[slf]
+}
+
+// BindIdentity 将连接与身份标识进行绑定，通常在连接鉴权成功后调用，身份标识可以是玩家 ID、账号等
+//   - 当已存在绑定相同身份的连接时，将根据 WithLoginConflictPolicy 指定的策略进行处理，并触发 OnLoginConflictEvent 事件
+//   - 被踢出的连接将在收到通过 WithLoginConflictPolicy 指定的通知数据包后以 ErrLoginConflictKicked 关闭
+//   - 当策略为 LoginConflictPolicyRejectNew 时，新连接将绑定失败并返回 ErrLoginConflictRejected，是否关闭新连接由调用方决定
+//   - 连接已绑定其他身份时将先解除原有的绑定，连接关闭时将自动解除绑定
+func (srv *Server) BindIdentity(conn *Conn, identity string) error {
+	srv.identityLock.Lock()
+	if conn.identity == identity {
+		srv.identityLock.Unlock()
+		return nil
+	}
+	srv.noLockUnbindIdentity(conn)
+
+	var sessions = make([]*Conn, 0, len(srv.identities[identity])+1)
+	for _, session := range srv.identities[identity] {
+		if !session.IsClosed() {
+			sessions = append(sessions, session)
+		}
+	}
+	var kicked []*Conn
+	switch srv.loginConflictPolicy {
+	case LoginConflictPolicyRejectNew:
+		if len(sessions) > 0 {
+			srv.identityLock.Unlock()
+			log.Warn("Server", log.String("State", "LoginConflict"), log.String("Identity", identity), log.String("ID", conn.GetID()), log.String("Policy", LoginConflictPolicyRejectNew.String()))
+			srv.OnLoginConflictEvent(identity, sessions[0], conn)
+			return ErrLoginConflictRejected
+		}
+	case LoginConflictPolicyMultiSession:
+		if srv.loginConflictLimit > 0 && len(sessions) >= srv.loginConflictLimit {
+			kicked = sessions[:len(sessions)-srv.loginConflictLimit+1]
+		}
+	default:
+		kicked = sessions
+	}
+	for _, old := range kicked {
+		old.identity = ""
+	}
+	sessions = append(sessions[len(kicked):], conn)
+	srv.identities[identity] = sessions
+	conn.identity = identity
+	srv.identityLock.Unlock()
+
+	for _, old := range kicked {
+		log.Warn("Server", log.String("State", "LoginConflict"), log.String("Identity", identity), log.String("ID", conn.GetID()), log.String("Kicked", old.GetID()), log.String("Policy", srv.loginConflictPolicy.String()))
+		srv.OnLoginConflictEvent(identity, old, conn)
+		srv.kickLoginConflict(old)
+	}
+	return nil
+}
+
+// UnbindIdentity 解除连接与身份标识的绑定
+func (srv *Server) UnbindIdentity(conn *Conn) {
+	srv.identityLock.Lock()
+	srv.noLockUnbindIdentity(conn)
+	srv.identityLock.Unlock()
+}
+
+// GetIdentityConns 获取绑定了特定身份标识的所有连接，按照绑定的先后顺序排列
+func (srv *Server) GetIdentityConns(identity string) []*Conn {
+	srv.identityLock.Lock()
+	defer srv.identityLock.Unlock()
+	sessions := srv.identities[identity]
+	if len(sessions) == 0 {
+		return nil
+	}
+	return append(make([]*Conn, 0, len(sessions)), sessions...)
+}
+
+// GetIdentity 获取连接通过 Server.BindIdentity 绑定的身份标识，未绑定时返回空字符串
+func (slf *Conn) GetIdentity() string {
+	slf.server.identityLock.Lock()
+	defer slf.server.identityLock.Unlock()
+	return slf.identity
+}
+
+// noLockUnbindIdentity 解除连接与身份标识的绑定，需要在持有 identityLock 时调用
+func (srv *Server) noLockUnbindIdentity(conn *Conn) {
+	if len(conn.identity) == 0 {
+		return
+	}
+	sessions := srv.identities[conn.identity]
+	for i, session := range sessions {
+		if session.connection == conn.connection {
+			sessions = append(sessions[:i], sessions[i+1:]...)
+			break
+		}
+	}
+	if len(sessions) == 0 {
+		delete(srv.identities, conn.identity)
+	} else {
+		srv.identities[conn.identity] = sessions
+	}
+	conn.identity = ""
+}
+
+// kickLoginConflict 向因身份冲突被踢出的连接发送通知数据包，并在发送后关闭连接
+func (srv *Server) kickLoginConflict(conn *Conn) {
+	if srv.loginConflictNotice == nil {
+		conn.Close(ErrLoginConflictKicked)
+		return
+	}
+	conn.Write(srv.loginConflictNotice, func(err error) {
+		go conn.Close(ErrLoginConflictKicked)
+	})
+}
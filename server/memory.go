@@ -0,0 +1,93 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+	goruntime "runtime"
+	"time"
+	"unsafe"
+)
+
+// messageBaseSize 消息结构体本身占用的内存字节数
+const messageBaseSize = int64(unsafe.Sizeof(Message{}))
+
+// MemoryReport 服务器内存及 GC 压力报告
+type MemoryReport struct {
+	QueuedBytes   int64         `json:"queued_bytes"`    // 所有消息分发器中等待处理的消息估算占用的内存字节数
+	Limit         int64         `json:"limit"`           // 通过 WithMessageMemoryLimit 设置的上限，0 表示不限制
+	Shedding      bool          `json:"shedding"`        // 是否正在丢弃数据包消息
+	Dropped       int64         `json:"dropped"`         // 因超出上限而被丢弃的数据包消息数量
+	HeapAlloc     uint64        `json:"heap_alloc"`      // 堆上已分配对象占用的字节数
+	HeapInuse     uint64        `json:"heap_inuse"`      // 正在使用的堆内存字节数
+	Sys           uint64        `json:"sys"`             // 从操作系统获取的内存字节数
+	NextGC        uint64        `json:"next_gc"`         // 下一次 GC 的目标堆大小
+	NumGC         uint32        `json:"num_gc"`          // 已完成的 GC 次数
+	LastGCPause   time.Duration `json:"last_gc_pause"`   // 最近一次 GC 的暂停时长
+	PauseTotal    time.Duration `json:"pause_total"`     // GC 的累计暂停时长
+	GCCPUFraction float64       `json:"gc_cpu_fraction"` // 程序启动以来 GC 占用的 CPU 时间比例
+}
+
+// GetQueuedMessageBytes 获取所有消息分发器中等待处理的消息估算占用的内存字节数
+func (srv *Server) GetQueuedMessageBytes() int64 {
+	return srv.queuedBytes.Load()
+}
+
+// GetMemoryReport 获取服务器内存及 GC 压力报告，可用于观察消息队列的增长是否即将耗尽进程内存
+//   - 该函数将调用 runtime.ReadMemStats，会产生短暂的全局暂停，不应频繁调用
+func (srv *Server) GetMemoryReport() MemoryReport {
+	var stats goruntime.MemStats
+	goruntime.ReadMemStats(&stats)
+	report := MemoryReport{
+		QueuedBytes:   srv.queuedBytes.Load(),
+		Limit:         srv.messageMemoryLimit,
+		Shedding:      srv.shedding.Load(),
+		Dropped:       srv.sheddingDropped.Load(),
+		HeapAlloc:     stats.HeapAlloc,
+		HeapInuse:     stats.HeapInuse,
+		Sys:           stats.Sys,
+		NextGC:        stats.NextGC,
+		NumGC:         stats.NumGC,
+		PauseTotal:    time.Duration(stats.PauseTotalNs),
+		GCCPUFraction: stats.GCCPUFraction,
+	}
+	if stats.NumGC > 0 {
+		report.LastGCPause = time.Duration(stats.PauseNs[(stats.NumGC+255)%256])
+	}
+	return report
+}
+
+// messageSize 获取消息推送时估算的内存占用字节数
+func messageSize(msg *Message) int64 {
+	return msg.size
+}
+
+// estimateMessageSize 估算消息占用的内存字节数
+func estimateMessageSize(msg *Message) int64 {
+	return messageBaseSize + int64(cap(msg.packet)) + int64(len(msg.name))
+}
+
+// shedMessage 记录消息的内存占用，并在超出 WithMessageMemoryLimit 的限制时丢弃数据包消息，返回消息是否被丢弃
+func (srv *Server) shedMessage(msg *Message) bool {
+	msg.size = estimateMessageSize(msg)
+	if srv.messageMemoryLimit <= 0 {
+		srv.queuedBytes.Add(msg.size)
+		return false
+	}
+	queued := srv.queuedBytes.Load()
+	if queued < srv.messageMemoryLimit {
+		if srv.shedding.CompareAndSwap(true, false) {
+			log.Info("Server", log.String("MessageMemory", "recovered"), log.Int64("queued", queued), log.Int64("limit", srv.messageMemoryLimit))
+		}
+		srv.queuedBytes.Add(msg.size)
+		return false
+	}
+	if msg.t != MessageTypePacket {
+		srv.queuedBytes.Add(msg.size)
+		return false
+	}
+	if srv.shedding.CompareAndSwap(false, true) {
+		log.Warn("Server", log.String("MessageMemory", "shedding"), log.Int64("queued", queued), log.Int64("limit", srv.messageMemoryLimit))
+	}
+	srv.sheddingDropped.Add(1)
+	srv.OnMessageMemoryOverflowEvent(msg, queued)
+	return true
+}
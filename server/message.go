@@ -89,6 +89,7 @@ type Message struct {
 	name             string
 	t                MessageType
 	l                *sync.RWMutex
+	size             int64 // 推送时估算的内存占用字节数
 }
 
 // bindDispatcher 绑定分发器
@@ -118,6 +119,7 @@ func (slf *Message) reset() {
 	slf.marks = nil
 	slf.producer = ""
 	slf.dis = nil
+	slf.size = 0
 }
 
 // MessageType 返回消息类型
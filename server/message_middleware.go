@@ -0,0 +1,29 @@
+package server
+
+type (
+	// MessageHandler 消息处理函数
+	MessageHandler func(srv *Server, message *Message)
+
+	// MessageMiddleware 消息中间件，通过包装 next 的方式组合消息处理逻辑
+	//   - 需要继续处理消息时应调用 next，否则该消息将被中断处理
+	MessageMiddleware func(next MessageHandler) MessageHandler
+)
+
+// UseMessageMiddleware 注册消息中间件，中间件将在消息分发器中围绕消息处理函数执行
+//   - 中间件对除异步消息（MessageTypeAsync 等在协程池中执行的消息）外的所有消息生效，包括数据包消息、系统消息、定时器消息及异步回调消息等
+//   - 中间件按照注册顺序由外至内执行，即首个注册的中间件最先执行
+//   - 适用于日志、指标统计、鉴权、异常标记等横切关注点的组合，相较于 RegConnectionPacketPreprocessEvent 可覆盖更多的消息类型
+//   - 该函数应在服务器运行前调用
+func (srv *Server) UseMessageMiddleware(middlewares ...MessageMiddleware) {
+	srv.messageMiddlewares = append(srv.messageMiddlewares, middlewares...)
+	srv.buildMessageHandler()
+}
+
+// buildMessageHandler 根据已注册的消息中间件构建消息处理函数
+func (srv *Server) buildMessageHandler() {
+	var handler MessageHandler = execMessage
+	for i := len(srv.messageMiddlewares) - 1; i >= 0; i-- {
+		handler = srv.messageMiddlewares[i](handler)
+	}
+	srv.messageHandler = handler
+}
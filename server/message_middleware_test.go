@@ -0,0 +1,32 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+)
+
+func TestServer_UseMessageMiddleware(t *testing.T) {
+	var orders []string
+	srv := server.New(server.NetworkNone)
+	srv.UseMessageMiddleware(func(next server.MessageHandler) server.MessageHandler {
+		return func(srv *server.Server, message *server.Message) {
+			orders = append(orders, "first")
+			next(srv, message)
+		}
+	}, func(next server.MessageHandler) server.MessageHandler {
+		return func(srv *server.Server, message *server.Message) {
+			orders = append(orders, "second")
+			next(srv, message)
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		orders = append(orders, "handler")
+		srv.Shutdown()
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) < 3 || orders[0] != "first" || orders[1] != "second" || orders[2] != "handler" {
+		t.Fatalf("unexpected middleware orders: %v", orders)
+	}
+}
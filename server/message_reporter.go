@@ -0,0 +1,127 @@
+package server
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// messageReporterSampleSize 每个报告周期内用于计算处理耗时百分位的最大采样数量，超出后将通过蓄水池抽样保留
+const messageReporterSampleSize = 4096
+
+// MessageStatisticsReport 消息统计报告，由 WithMessageStatisticsReporter 周期性生成
+type MessageStatisticsReport struct {
+	Time        time.Time     `json:"time"`         // 报告生成时间
+	Interval    time.Duration `json:"interval"`     // 报告周期
+	Handled     int64         `json:"handled"`      // 报告周期内处理完成的消息数量
+	Throughput  float64       `json:"throughput"`   // 报告周期内每秒处理完成的消息数量
+	Pending     int64         `json:"pending"`      // 等待处理及正在处理的消息数量
+	QueuedBytes int64         `json:"queued_bytes"` // 等待处理的消息估算占用的内存字节数
+	Shunts      []ShuntInfo   `json:"shunts"`       // 所有消息分流渠道的队列信息，其中包含系统通道
+	P50         time.Duration `json:"p50"`          // 报告周期内消息处理耗时的 50 百分位
+	P95         time.Duration `json:"p95"`          // 报告周期内消息处理耗时的 95 百分位
+	P99         time.Duration `json:"p99"`          // 报告周期内消息处理耗时的 99 百分位
+	Max         time.Duration `json:"max"`          // 报告周期内消息处理耗时的最大值
+}
+
+// MessageStatisticsReporter 消息统计报告的处理函数，可用于将报告推送至外部监控系统，该函数将在独立的协程中被调用
+type MessageStatisticsReporter func(report MessageStatisticsReport)
+
+// messageReporter 消息处理耗时收集器
+type messageReporter struct {
+	interval time.Duration
+	reporter MessageStatisticsReporter
+	lock     sync.Mutex
+	handled  int64
+	longest  time.Duration
+	samples  []time.Duration
+}
+
+// record 记录一条消息的处理耗时
+func (slf *messageReporter) record(cost time.Duration) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	slf.handled++
+	if cost > slf.longest {
+		slf.longest = cost
+	}
+	if len(slf.samples) < messageReporterSampleSize {
+		slf.samples = append(slf.samples, cost)
+		return
+	}
+	if i := rand.Int63n(slf.handled); i < messageReporterSampleSize {
+		slf.samples[i] = cost
+	}
+}
+
+// reset 取出当前周期的统计数据并开始新的周期
+func (slf *messageReporter) reset() (handled int64, longest time.Duration, samples []time.Duration) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	handled, longest, samples = slf.handled, slf.longest, slf.samples
+	slf.handled, slf.longest, slf.samples = 0, 0, make([]time.Duration, 0, len(samples))
+	return
+}
+
+// percentile 获取已排序的耗时中特定百分位的值
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// recordMessageLatency 在通过 WithMessageStatisticsReporter 开启报告时记录消息的处理耗时
+func (srv *Server) recordMessageLatency(present time.Time) {
+	if srv.messageReporter == nil {
+		return
+	}
+	srv.messageReporter.record(time.Since(present))
+}
+
+// startMessageReporter 开始周期性生成消息统计报告
+func (srv *Server) startMessageReporter() {
+	if srv.messageReporter == nil {
+		return
+	}
+	ticker := time.NewTicker(srv.messageReporter.interval)
+	go func(srv *Server, ticker *time.Ticker) {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				srv.messageReporter.reporter(srv.newMessageStatisticsReport())
+			case <-srv.ctx.Done():
+				return
+			}
+		}
+	}(srv, ticker)
+}
+
+// newMessageStatisticsReport 生成当前周期的消息统计报告
+func (srv *Server) newMessageStatisticsReport() MessageStatisticsReport {
+	handled, longest, samples := srv.messageReporter.reset()
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	interval := srv.messageReporter.interval
+	return MessageStatisticsReport{
+		Time:        time.Now(),
+		Interval:    interval,
+		Handled:     handled,
+		Throughput:  float64(handled) / interval.Seconds(),
+		Pending:     srv.messageCounter.Load(),
+		QueuedBytes: srv.queuedBytes.Load(),
+		Shunts:      srv.GetShunts(),
+		P50:         percentile(samples, 0.50),
+		P95:         percentile(samples, 0.95),
+		P99:         percentile(samples, 0.99),
+		Max:         longest,
+	}
+}
@@ -0,0 +1,44 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithMessageStatisticsReporter(t *testing.T) {
+	var once sync.Once
+	var report server.MessageStatisticsReport
+	var reported = make(chan struct{})
+	srv := server.New(server.NetworkNone, server.WithMessageStatisticsReporter(time.Millisecond*200, func(r server.MessageStatisticsReport) {
+		if r.Handled < 10 {
+			return
+		}
+		once.Do(func() {
+			report = r
+			close(reported)
+		})
+	}))
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		for i := 0; i < 10; i++ {
+			srv.PushSystemMessage(func() {
+				time.Sleep(time.Millisecond * 5)
+			})
+		}
+		go func() {
+			<-reported
+			srv.Shutdown()
+		}()
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.P50 < time.Millisecond*5 || report.P99 < report.P50 || report.Max < report.P99 {
+		t.Fatalf("unexpected percentiles: p50 %v, p99 %v, max %v", report.P50, report.P99, report.Max)
+	}
+	if report.Throughput <= 0 || len(report.Shunts) == 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// messageStatistic 单个 WithMessageStatistics 设置的 duration 期间的消息统计
+type messageStatistic struct {
+	count  atomic.Int64 // 期间内的消息量
+	shunts sync.Map     // 分流渠道名称 -> *shuntMessageStatistic
+}
+
+// shuntMessageStatistic 单个分流渠道在统计期间内的消息统计
+type shuntMessageStatistic struct {
+	count atomic.Int64                       // 期间内的消息量
+	types [MessageTypeShunt + 1]atomic.Int64 // 期间内各消息类型的消息量
+}
+
+// hitShuntMessageStatistics 命中分流渠道的消息统计
+func (srv *Server) hitShuntMessageStatistics(name string, t MessageType) {
+	if !srv.HasMessageStatistics() {
+		return
+	}
+	srv.runtime.messageStatisticsLock.RLock()
+	statistic := srv.runtime.messageStatistics[0]
+	srv.runtime.messageStatisticsLock.RUnlock()
+	v, exist := statistic.shunts.Load(name)
+	if !exist {
+		v, _ = statistic.shunts.LoadOrStore(name, new(shuntMessageStatistic))
+	}
+	shunt := v.(*shuntMessageStatistic)
+	shunt.count.Add(1)
+	if int(t) < len(shunt.types) {
+		shunt.types[t].Add(1)
+	}
+}
+
+// getShuntMessageStatistic 获取特定偏移次数的期间内特定分流渠道的消息统计，不存在时返回 nil
+func (srv *Server) getShuntMessageStatistic(name string, offset int) *shuntMessageStatistic {
+	if !srv.HasMessageStatistics() {
+		return nil
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	srv.runtime.messageStatisticsLock.RLock()
+	if offset >= len(srv.runtime.messageStatistics) {
+		srv.runtime.messageStatisticsLock.RUnlock()
+		return nil
+	}
+	statistic := srv.runtime.messageStatistics[offset]
+	srv.runtime.messageStatisticsLock.RUnlock()
+	v, exist := statistic.shunts.Load(name)
+	if !exist {
+		return nil
+	}
+	return v.(*shuntMessageStatistic)
+}
+
+// GetShuntDurationMessageCount 获取当前 WithMessageStatistics 设置的 duration 期间特定分流渠道的消息量
+//   - 系统通道的名称为 "*system"
+func (srv *Server) GetShuntDurationMessageCount(name string) int64 {
+	return srv.GetShuntDurationMessageCountByOffset(name, 0)
+}
+
+// GetShuntDurationMessageCountByOffset 获取特定偏移次数的 WithMessageStatistics 设置的 duration 期间特定分流渠道的消息量
+//   - offset 为 0 时为当前期间，为 n 时为向前第 n 个期间，超出保留的期间数量时将视为无消息
+func (srv *Server) GetShuntDurationMessageCountByOffset(name string, offset int) int64 {
+	statistic := srv.getShuntMessageStatistic(name, offset)
+	if statistic == nil {
+		return 0
+	}
+	return statistic.count.Load()
+}
+
+// GetShuntDurationMessageTypeCount 获取当前 WithMessageStatistics 设置的 duration 期间特定分流渠道各消息类型的消息量
+//   - 返回值中仅包含消息量大于 0 的消息类型
+func (srv *Server) GetShuntDurationMessageTypeCount(name string) map[MessageType]int64 {
+	return srv.GetShuntDurationMessageTypeCountByOffset(name, 0)
+}
+
+// GetShuntDurationMessageTypeCountByOffset 获取特定偏移次数的 WithMessageStatistics 设置的 duration 期间特定分流渠道各消息类型的消息量
+//   - offset 为 0 时为当前期间，为 n 时为向前第 n 个期间，超出保留的期间数量时将视为无消息
+func (srv *Server) GetShuntDurationMessageTypeCountByOffset(name string, offset int) map[MessageType]int64 {
+	var counts = make(map[MessageType]int64)
+	statistic := srv.getShuntMessageStatistic(name, offset)
+	if statistic == nil {
+		return counts
+	}
+	for t := range statistic.types {
+		if v := statistic.types[t].Load(); v > 0 {
+			counts[MessageType(t)] = v
+		}
+	}
+	return counts
+}
+
+// GetAllShuntDurationMessageCount 获取当前 WithMessageStatistics 设置的 duration 期间所有分流渠道的消息量，可用于定位消息量异常的房间等热点
+func (srv *Server) GetAllShuntDurationMessageCount() map[string]int64 {
+	var counts = make(map[string]int64)
+	if !srv.HasMessageStatistics() {
+		return counts
+	}
+	srv.runtime.messageStatisticsLock.RLock()
+	statistic := srv.runtime.messageStatistics[0]
+	srv.runtime.messageStatisticsLock.RUnlock()
+	statistic.shunts.Range(func(key, value any) bool {
+		counts[key.(string)] = value.(*shuntMessageStatistic).count.Load()
+		return true
+	})
+	return counts
+}
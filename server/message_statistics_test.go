@@ -0,0 +1,42 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestServer_GetShuntDurationMessageCount(t *testing.T) {
+	var shunt, types, all string
+	var count int64
+	srv := server.New(server.NetworkNone, server.WithMessageStatistics(time.Minute, 3))
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		conn := server.NewOfflineConn(srv)
+		srv.UseShunt(conn, "room")
+		srv.PushShuntMessage(conn, func() {})
+		srv.PushShuntAsyncMessage(conn, func() error { return nil }, nil)
+		srv.PushShuntMessage(conn, func() {
+			shunt = srv.GetConnCurrShunt(conn)
+			count = srv.GetShuntDurationMessageCount("room")
+			for typ, n := range srv.GetShuntDurationMessageTypeCount("room") {
+				if typ == server.MessageTypeShuntAsync && n == 1 {
+					types += "async"
+				}
+			}
+			if _, exist := srv.GetAllShuntDurationMessageCount()["room"]; exist {
+				all = "room"
+			}
+			conn.Close()
+			srv.Shutdown()
+		})
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+	if shunt != "room" || count < 3 || types != "async" || all != "room" {
+		t.Fatalf("shunt: %s, count: %d, types: %s, all: %s", shunt, count, types, all)
+	}
+	if srv.GetShuntDurationMessageCountByOffset("room", 3) != 0 {
+		t.Fatal("offset beyond limit should be 0")
+	}
+}
@@ -48,7 +48,7 @@ func (slf *MultipleServer) Run() {
 	var hasKcp bool
 	for i := 0; i < len(slf.servers); i++ {
 		wait.Add(1)
-		if slf.servers[i].network == NetworkKcp {
+		if slf.servers[i].network == NetworkKcp || slf.servers[i].isAdditionalListen(NetworkKcp) {
 			hasKcp = true
 		}
 		go func(address string, server *Server) {
@@ -1,11 +1,12 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/server/internal/logger"
 	"github.com/kercylan98/minotaur/utils/collection"
-	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/super"
 	"github.com/panjf2000/gnet"
 	"github.com/xtaci/kcp-go/v5"
@@ -108,6 +109,8 @@ func (n Network) preprocessing(srv *Server) {
 		}
 	case NetworkWebsocket:
 		srv.websocketReadDeadline = DefaultWebsocketReadDeadline
+		gin.SetMode(gin.ReleaseMode)
+		srv.ginServer = gin.New()
 	case NetworkKcp:
 	case NetworkGRPC:
 		srv.grpcServer = grpc.NewServer()
@@ -122,25 +125,25 @@ func (n Network) adaptation(srv *Server) <-chan error {
 		srv.addr = "-"
 		state <- nil
 	case NetworkTcp:
-		n.gNetMode(state, srv)
+		n.gNetMode(state, srv, srv.addr)
 	case NetworkTcp4:
-		n.gNetMode(state, srv)
+		n.gNetMode(state, srv, srv.addr)
 	case NetworkTcp6:
-		n.gNetMode(state, srv)
+		n.gNetMode(state, srv, srv.addr)
 	case NetworkUdp:
-		n.gNetMode(state, srv)
+		n.gNetMode(state, srv, srv.addr)
 	case NetworkUdp4:
-		n.gNetMode(state, srv)
+		n.gNetMode(state, srv, srv.addr)
 	case NetworkUdp6:
-		n.gNetMode(state, srv)
+		n.gNetMode(state, srv, srv.addr)
 	case NetworkUnix:
-		n.gNetMode(state, srv)
+		n.gNetMode(state, srv, srv.addr)
 	case NetworkHttp:
 		n.httpMode(state, srv)
 	case NetworkWebsocket:
-		n.websocketMode(state, srv)
+		n.websocketMode(state, srv, srv.addr)
 	case NetworkKcp:
-		n.kcpMode(state, srv)
+		n.kcpMode(state, srv, srv.addr)
 	case NetworkGRPC:
 		n.grpcMode(state, srv)
 	default:
@@ -149,28 +152,71 @@ func (n Network) adaptation(srv *Server) <-chan error {
 	return state
 }
 
+// additionalAdaptation 额外监听地址的服务器适配，仅支持 socket 类网络模式
+func (n Network) additionalAdaptation(srv *Server, addr string) <-chan error {
+	state := make(chan error, 1)
+	switch n {
+	case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix:
+		n.gNetMode(state, srv, addr)
+	case NetworkWebsocket:
+		n.websocketMode(state, srv, addr)
+	case NetworkKcp:
+		n.kcpMode(state, srv, addr)
+	default:
+		state <- fmt.Errorf("%w: %s", ErrAdditionalListenUnsupported, n)
+	}
+	return state
+}
+
 // gNetMode gNet模式
-func (n Network) gNetMode(state chan<- error, srv *Server) {
-	srv.gServer = &gNet{Server: srv, state: state}
-	go func(srv *Server) {
-		if err := gnet.Serve(srv.gServer, fmt.Sprintf("%s://%s", srv.network, srv.addr),
+func (n Network) gNetMode(state chan<- error, srv *Server, addr string) {
+	protoAddr := fmt.Sprintf("%s://%s", n, addr)
+	srv.gnetAddrs = append(srv.gnetAddrs, protoAddr)
+	g := &gNet{Server: srv, network: n, state: state}
+	switch n {
+	case NetworkUdp, NetworkUdp4, NetworkUdp6:
+		g.sessions = newUDPSessions(srv, n)
+		srv.udpSessions = append(srv.udpSessions, g.sessions)
+	}
+	go func(srv *Server, g *gNet) {
+		options := append([]gnet.Option{
 			gnet.WithLogger(new(logger.GNet)),
 			gnet.WithTicker(true),
 			gnet.WithMulticore(true),
-		); err != nil {
-			super.TryWriteChannel(srv.gServer.state, err)
+		}, srv.gnetOptions...)
+		if err := gnet.Serve(g, protoAddr, options...); err != nil {
+			super.TryWriteChannel(g.state, err)
 		}
-	}(srv)
+	}(srv, g)
+}
+
+// listen 监听特定地址，addr 为 Run 或 WithAdditionalListen 指定的地址，address 为实际监听的地址
+//   - 对于 Run 指定的地址，当通过 WithListener 指定了监听器时将直接使用该监听器
+//   - 当存在继承的监听器时将使用继承的监听器，Run 指定的地址在没有匹配名称的监听器时将使用首个未命名的监听器
+func (n Network) listen(srv *Server, addr, address string) (l net.Listener, err error) {
+	main := !srv.isAdditionalListen(n, addr)
+	name := handoverName(n, addr)
+	if main && srv.listener != nil {
+		l = srv.listener
+	} else if l, err = inheritListener(name, main); err == nil && l == nil {
+		l, err = net.Listen(string(NetworkTcp), address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	srv.trackListener(name, l)
+	return l, nil
 }
 
 // grpcMode grpc模式
 func (n Network) grpcMode(state chan<- error, srv *Server) {
-	l, err := net.Listen(string(NetworkTcp), srv.addr)
+	l, err := n.listen(srv, srv.addr, srv.addr)
 	if err != nil {
 		state <- err
 		return
 	}
 	lis := (&listener{srv: srv, Listener: l, state: state}).init()
+	srv.registerGRPCBuiltin()
 	go func(srv *Server, lis *listener) {
 		if err = srv.grpcServer.Serve(lis); err != nil {
 			super.TryWriteChannel(lis.state, err)
@@ -179,8 +225,26 @@ func (n Network) grpcMode(state chan<- error, srv *Server) {
 }
 
 // kcpMode kcp模式
-func (n Network) kcpMode(state chan<- error, srv *Server) {
-	l, err := kcp.ListenWithOptions(srv.addr, nil, 0, 0)
+func (n Network) kcpMode(state chan<- error, srv *Server, addr string) {
+	var dataShards, parityShards int
+	if srv.kcpConfig != nil {
+		dataShards, parityShards = srv.kcpConfig.FECData, srv.kcpConfig.FECParity
+	}
+	var l *kcp.Listener
+	var err error
+	main := !srv.isAdditionalListen(n, addr)
+	conn := srv.packetConn
+	if conn == nil || !main {
+		if conn, err = inheritPacketConn(handoverName(n, addr), main); err != nil {
+			super.TryWriteChannel(state, err)
+			return
+		}
+	}
+	if conn != nil {
+		l, err = kcp.ServeConn(nil, dataShards, parityShards, conn)
+	} else {
+		l, err = kcp.ListenWithOptions(addr, nil, dataShards, parityShards)
+	}
 	if err != nil {
 		super.TryWriteChannel(state, err)
 		return
@@ -193,7 +257,15 @@ func (n Network) kcpMode(state chan<- error, srv *Server) {
 				continue
 			}
 
+			if lis.srv.kcpConfig != nil {
+				lis.srv.kcpConfig.Apply(session)
+			}
+			if !lis.srv.acceptConn(addrIP(session.RemoteAddr())) {
+				_ = session.Close()
+				continue
+			}
 			conn := newKcpConn(lis.srv, session)
+			conn.limited = true
 			lis.srv.OnConnectionOpenedEvent(conn)
 
 			go func(conn *Conn) {
@@ -212,7 +284,14 @@ func (n Network) kcpMode(state chan<- error, srv *Server) {
 						}
 						panic(err)
 					}
-					lis.srv.PushPacketMessage(conn, 0, buf[:n])
+					packet, ok, err := conn.unpackPacket(0, buf[:n])
+					if err != nil {
+						conn.Close(err)
+						break
+					}
+					if ok {
+						lis.srv.PushPacketMessage(conn, 0, packet)
+					}
 				}
 			}(conn)
 		}
@@ -223,46 +302,43 @@ func (n Network) kcpMode(state chan<- error, srv *Server) {
 // httpMode http模式
 func (n Network) httpMode(state chan<- error, srv *Server) {
 	srv.httpServer.Addr = srv.addr
-	l, err := net.Listen(string(NetworkTcp), srv.addr)
+	l, err := n.listen(srv, srv.addr, srv.addr)
 	if err != nil {
 		super.TryWriteChannel(state, err)
 		return
 	}
-	gin.SetMode(gin.ReleaseMode)
-	srv.ginServer.Use(func(c *gin.Context) {
-		t := time.Now()
-		c.Next()
-		log.Info("Server", log.String("type", "http"),
-			log.String("method", c.Request.Method), log.Int("status", c.Writer.Status()),
-			log.String("ip", c.ClientIP()), log.String("path", c.Request.URL.Path),
-			log.Duration("cost", time.Since(t)))
-	})
-	go func(lis *listener) {
+	lis := (&listener{srv: srv, Listener: l, state: state}).init()
+	tls := len(srv.certFile)+len(srv.keyFile) > 0
+	var serve net.Listener = lis
+	if !tls {
+		serve = srv.multiplexGRPC(lis)
+	}
+	go func(lis *listener, serve net.Listener) {
 		var err error
-		if len(lis.srv.certFile)+len(srv.keyFile) > 0 {
+		if tls {
 			err = lis.srv.httpServer.ServeTLS(lis, lis.srv.certFile, lis.srv.keyFile)
 		} else {
-			err = lis.srv.httpServer.Serve(lis)
+			err = lis.srv.httpServer.Serve(serve)
 		}
 		if err != nil {
 			super.TryWriteChannel(lis.state, err)
 		}
-	}((&listener{srv: srv, Listener: l, state: state}).init())
+	}(lis, serve)
 }
 
 // websocketMode websocket模式
-func (n Network) websocketMode(state chan<- error, srv *Server) {
+func (n Network) websocketMode(state chan<- error, srv *Server, addr string) {
 	var pattern string
 	var address string
-	var index = strings.Index(srv.addr, "/")
+	var index = strings.Index(addr, "/")
 	if index == -1 {
 		pattern = "/"
-		address = srv.addr
+		address = addr
 	} else {
-		pattern = srv.addr[index:]
-		address = srv.addr[:index]
+		pattern = addr[index:]
+		address = addr[:index]
 	}
-	l, err := net.Listen(string(NetworkTcp), address)
+	l, err := n.listen(srv, addr, address)
 	if err != nil {
 		super.TryWriteChannel(state, err)
 		return
@@ -271,28 +347,46 @@ func (n Network) websocketMode(state chan<- error, srv *Server) {
 		srv.websocketUpgrader = DefaultWebsocketUpgrader()
 	}
 	mux := http.NewServeMux()
+	if pattern != "/" {
+		mux.Handle("/", srv.ginServer)
+	}
 	mux.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
+		if !websocket.IsWebSocketUpgrade(request) {
+			// 非升级请求交由通过 HttpServer 注册的路由处理，例如健康检查
+			srv.ginServer.ServeHTTP(writer, request)
+			return
+		}
 		ip := request.Header.Get("X-Real-IP")
+		if len(ip) == 0 {
+			addr := request.RemoteAddr
+			if index := strings.LastIndex(addr, ":"); index != -1 {
+				ip = addr[0:index]
+			}
+		}
+		if !srv.acceptConn(ip) {
+			http.Error(writer, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
 		ws, err := srv.websocketUpgrader.Upgrade(writer, request, nil)
 		if err != nil {
+			srv.limiter.release(ip)
 			return
 		}
 		if srv.websocketConnInitializer != nil {
 			if err = srv.websocketConnInitializer(writer, request, ws); err != nil {
+				srv.limiter.release(ip)
 				return
 			}
 		}
-		if len(ip) == 0 {
-			addr := ws.RemoteAddr().String()
-			if index := strings.LastIndex(addr, ":"); index != -1 {
-				ip = addr[0:index]
-			}
-		}
 		if srv.websocketCompression > 0 {
 			_ = ws.SetCompressionLevel(srv.websocketCompression)
 		}
 		ws.EnableWriteCompression(srv.websocketWriteCompression)
+		if srv.websocketAssembly == nil && srv.packetLimitSize > 0 {
+			ws.SetReadLimit(int64(srv.packetLimitSize))
+		}
 		conn := newWebsocketConn(srv, ws, ip)
+		conn.limited = true
 		conn.SetData(wsRequestKey, request)
 		for k, v := range request.URL.Query() {
 			if len(v) == 1 {
@@ -314,30 +408,63 @@ func (n Network) websocketMode(state chan<- error, srv *Server) {
 					panic(err)
 				}
 			}
-			messageType, packet, readErr := ws.ReadMessage()
+			var messageType int
+			var packet []byte
+			var discarded bool
+			var readErr error
+			if srv.websocketAssembly != nil {
+				messageType, packet, discarded, readErr = srv.readWebsocketMessage(conn, ws)
+			} else {
+				messageType, packet, readErr = ws.ReadMessage()
+			}
 			if readErr != nil {
 				if conn.IsClosed() {
 					break
 				}
+				if errors.Is(readErr, websocket.ErrReadLimit) {
+					srv.onPacketOversize(conn, srv.packetLimitSize+1)
+					conn.Close(ErrPacketOversize)
+					break
+				}
+				if errors.Is(readErr, ErrPacketOversize) || errors.Is(readErr, ErrWebsocketAssemblyTimeout) {
+					conn.Close(readErr)
+					break
+				}
 				panic(readErr)
 			}
+			if discarded {
+				continue
+			}
 			if len(srv.supportMessageTypes) > 0 && !srv.supportMessageTypes[messageType] {
 				panic(ErrWebsocketIllegalMessageType)
 			}
-			srv.PushPacketMessage(conn, messageType, packet)
+			var ok bool
+			if packet, ok, readErr = conn.unpackPacket(messageType, packet); readErr != nil {
+				conn.Close(readErr)
+				break
+			}
+			if ok {
+				srv.PushPacketMessage(conn, messageType, packet)
+			}
 		}
 	})
-	go func(lis *listener, mux *http.ServeMux) {
+	lis := (&listener{srv: srv, Listener: l, state: state}).init()
+	tls := len(srv.certFile)+len(srv.keyFile) > 0
+	var serve net.Listener = lis
+	if !tls && !srv.isAdditionalListen(n, addr) {
+		serve = srv.multiplexGRPC(lis)
+	}
+	go func(lis *listener, serve net.Listener, mux *http.ServeMux) {
 		var err error
-		if len(lis.srv.certFile)+len(lis.srv.keyFile) > 0 {
+		if tls {
 			err = http.ServeTLS(lis, mux, lis.srv.certFile, lis.srv.keyFile)
 		} else {
-			err = http.Serve(lis, mux)
+			err = http.Serve(serve, mux)
 		}
 		if err != nil {
 			super.TryWriteChannel(lis.state, err)
 		}
-	}((&listener{srv: srv, Listener: l, state: state}).init(), mux)
+	}(lis, serve, mux)
 }
 
 // IsSocket 返回当前服务器的网络模式是否为 Socket 模式，目前为止仅有如下几种模式为 Socket 模式：
@@ -1,14 +1,18 @@
 package server
 
 import (
+	"fmt"
 	"github.com/gin-contrib/pprof"
 	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/timer"
+	"github.com/panjf2000/gnet"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"net"
 	"net/http"
 	"sync"
-	"sync/atomic"
 	"time"
 )
 
@@ -32,28 +36,75 @@ type option struct {
 }
 
 type runtime struct {
-	deadlockDetect            time.Duration                                                                       // 是否开启死锁检测
-	supportMessageTypes       map[int]bool                                                                        // websocket 模式下支持的消息类型
-	certFile, keyFile         string                                                                              // TLS文件
-	tickerPool                *timer.Pool                                                                         // 定时器池
-	ticker                    *timer.Ticker                                                                       // 定时器
-	tickerAutonomy            bool                                                                                // 定时器是否独立运行
-	connTickerSize            int                                                                                 // 连接定时器大小
-	websocketReadDeadline     time.Duration                                                                       // websocket 连接超时时间
-	websocketCompression      int                                                                                 // websocket 压缩等级
-	websocketWriteCompression bool                                                                                // websocket 写入压缩
-	limitLife                 time.Duration                                                                       // 限制最大生命周期
-	packetWarnSize            int                                                                                 // 数据包大小警告
-	messageStatisticsDuration time.Duration                                                                       // 消息统计时长
-	messageStatisticsLimit    int                                                                                 // 消息统计数量
-	messageStatistics         []*atomic.Int64                                                                     // 消息统计数量
-	messageStatisticsLock     *sync.RWMutex                                                                       // 消息统计锁
-	connWriteBufferSize       int                                                                                 // 连接写入缓冲区大小
-	websocketUpgrader         *websocket.Upgrader                                                                 // websocket 升级器
-	websocketConnInitializer  func(writer http.ResponseWriter, request *http.Request, conn *websocket.Conn) error // websocket 连接初始化
-	dispatcherBufferSize      int                                                                                 // 消息分发器缓冲区大小
-	lowMessageDuration        time.Duration                                                                       // 慢消息时长
-	asyncLowMessageDuration   time.Duration                                                                       // 异步慢消息时长
+	deadlockDetect             time.Duration                                                                       // 是否开启死锁检测
+	deadlockGoroutineDump      bool                                                                                // 死锁检测触发时是否捕获所有协程的堆栈
+	rpc                        bool                                                                                // 是否开启 RPC
+	supportMessageTypes        map[int]bool                                                                        // websocket 模式下支持的消息类型
+	certFile, keyFile          string                                                                              // TLS文件
+	tickerPool                 *timer.Pool                                                                         // 定时器池
+	ticker                     *timer.Ticker                                                                       // 定时器
+	tickerAutonomy             bool                                                                                // 定时器是否独立运行
+	connTickerSize             int                                                                                 // 连接定时器大小
+	websocketReadDeadline      time.Duration                                                                       // websocket 连接超时时间
+	websocketCompression       int                                                                                 // websocket 压缩等级
+	websocketWriteCompression  bool                                                                                // websocket 写入压缩
+	websocketAssembly          *websocketAssembly                                                                  // websocket 分片消息组装配置
+	limitLife                  time.Duration                                                                       // 限制最大生命周期
+	lifeThresholds             []time.Duration                                                                     // 生命周期结束前的预警阈值
+	packetWarnSize             int                                                                                 // 数据包大小警告
+	packetLimitSize            int                                                                                 // 数据包大小限制
+	packetLimitPolicy          PacketLimitPolicy                                                                   // 数据包超出大小限制时的处理策略
+	messageStatisticsDuration  time.Duration                                                                       // 消息统计时长
+	messageStatisticsLimit     int                                                                                 // 消息统计数量
+	messageStatistics          []*messageStatistic                                                                 // 消息统计数量
+	messageStatisticsLock      *sync.RWMutex                                                                       // 消息统计锁
+	connWriteBufferSize        int                                                                                 // 连接写入缓冲区大小
+	websocketUpgrader          *websocket.Upgrader                                                                 // websocket 升级器
+	websocketConnInitializer   func(writer http.ResponseWriter, request *http.Request, conn *websocket.Conn) error // websocket 连接初始化
+	dispatcherBufferSize       int                                                                                 // 消息分发器缓冲区大小
+	lowMessageDuration         time.Duration                                                                       // 慢消息时长
+	asyncLowMessageDuration    time.Duration                                                                       // 异步慢消息时长
+	packetCompression          PacketCompression                                                                   // 数据包压缩算法
+	packetCompressionThreshold int                                                                                 // 数据包压缩阈值
+	panicPolicy                PanicPolicy                                                                         // 默认的消息 panic 处理策略
+	panicPolicies              map[MessageType]PanicPolicy                                                         // 特定消息类型的 panic 处理策略
+	auditor                    *auditor                                                                            // 审计记录缓冲区
+	writeCoalescingMaxBytes    int                                                                                 // 写入合并的最大字节数
+	writeCoalescingMaxDelay    time.Duration                                                                       // 写入合并的最大延迟
+	gnetOptions                []gnet.Option                                                                       // gnet 可选项
+	kcpConfig                  *KCPConfig                                                                          // KCP 会话配置
+	listener                   net.Listener                                                                        // 预先创建的监听器
+	packetConn                 net.PacketConn                                                                      // 预先创建的数据包连接
+	additionalListens          []additionalListen                                                                  // 额外的监听地址
+	adminAddr                  string                                                                              // 运维管理接口的监听地址
+	messageMemoryLimit         int64                                                                               // 等待处理的消息估算占用内存的上限
+	geoResolver                GeoResolver                                                                         // 地理位置解析器
+	protocolVersionMin         uint32                                                                              // 支持的最低协议版本
+	protocolVersionMax         uint32                                                                              // 支持的最高协议版本，为 0 时表示不进行协商
+	botIdentifier              BotIdentifier                                                                       // 机器人连接识别函数
+	rollingQuietPeriod         time.Duration                                                                       // 滚动停止时判定网关已停止路由新客户端的静默时长
+	shutdownTimeout            time.Duration                                                                       // 停止服务器时等待消息及消息分发器结束的超时时间
+	shutdownCloseConn          bool                                                                                // 停止服务器时是否主动关闭在线连接并等待写循环结束
+	connInitializer            ConnectionInitializer                                                               // 连接初始化函数
+	messageReporter            *messageReporter                                                                    // 消息统计报告
+	shuntReleaseDelay          time.Duration                                                                       // 分流渠道没有任何连接后延迟释放的时长
+	udpSessionTimeout          time.Duration                                                                       // UDP 虚拟会话的不活跃超时时长
+	udpSessionToken            UDPSessionTokenResolver                                                             // UDP 虚拟会话令牌解析函数
+	httpEngine                 HttpEngine                                                                          // 自定义的 HTTP 引擎
+	connStateGuard             *connStateGuard                                                                     // 按连接状态限制的消息协议号白名单
+	grpcHealth                 *health.Server                                                                      // GRPC 健康检查服务
+	grpcReflection             bool                                                                                // 是否开启 GRPC 服务反射
+	grpcMultiplex              bool                                                                                // 是否在 HTTP 监听地址上复用 GRPC 服务
+	shuntMatcher               ShuntMatcher                                                                        // 自动选择连接消息分流渠道的匹配函数
+	uniqueMessagePolicy        UniqueMessagePolicy                                                                 // 同名唯一消息正在执行时推送唯一消息的处理策略
+	loginConflictPolicy        LoginConflictPolicy                                                                 // 不同连接绑定相同身份时的处理策略
+	loginConflictLimit         int                                                                                 // 相同身份允许同时绑定的连接数量
+	loginConflictNotice        []byte                                                                              // 因身份冲突被踢出的连接在关闭前收到的通知数据包
+}
+
+type additionalListen struct {
+	network Network // 网络类型
+	addr    string  // 监听地址
 }
 
 // WithLowMessageDuration 通过指定慢消息时长的方式创建服务器，当消息处理时间超过指定时长时，将会输出 WARN 类型的日志
@@ -74,11 +125,21 @@ func WithAsyncLowMessageDuration(duration time.Duration) Option {
 	}
 }
 
+// WithLowMessageDurations 通过同时指定同步消息及异步消息的慢消息时长的方式创建服务器，效果等同于同时使用 WithLowMessageDuration 及 WithAsyncLowMessageDuration
+//   - 适用于帧逻辑较重的项目对慢消息告警进行调整，配置的时长可通过 RegMessageLowExecDetailEvent 注册的事件获取
+//   - 当 sync 或 async <= 0 时，表示关闭对应类型消息的慢消息检测
+func WithLowMessageDurations(sync, async time.Duration) Option {
+	return func(srv *Server) {
+		srv.lowMessageDuration = sync
+		srv.asyncLowMessageDuration = async
+	}
+}
+
 // WithWebsocketConnInitializer 通过 websocket 连接初始化的方式创建服务器，当 initializer 返回错误时，服务器将不会处理该连接的后续逻辑
 //   - 该选项仅在创建 NetworkWebsocket 服务器时有效
 func WithWebsocketConnInitializer(initializer func(writer http.ResponseWriter, request *http.Request, conn *websocket.Conn) error) Option {
 	return func(srv *Server) {
-		if srv.network != NetworkWebsocket {
+		if !srv.hasNetwork(NetworkWebsocket) {
 			return
 		}
 		srv.websocketConnInitializer = initializer
@@ -90,13 +151,28 @@ func WithWebsocketConnInitializer(initializer func(writer http.ResponseWriter, r
 //   - 该选项仅在创建 NetworkWebsocket 服务器时有效
 func WithWebsocketUpgrade(upgrader *websocket.Upgrader) Option {
 	return func(srv *Server) {
-		if srv.network != NetworkWebsocket {
+		if !srv.hasNetwork(NetworkWebsocket) {
 			return
 		}
 		srv.websocketUpgrader = upgrader
 	}
 }
 
+// WithWebsocketUpgradeConfig 通过指定升级参数的方式创建服务器，是 WithWebsocketUpgrade 的快捷方式
+//   - checkOrigin 用于在浏览器部署时校验请求来源，当 checkOrigin 为 nil 时将仅允许与 Host 相同的来源，这与 DefaultWebsocketUpgrader 允许所有来源的行为不同
+//   - subprotocols 为服务器支持的子协议，按照优先级排列，协商后的子协议可通过 Conn.GetWebsocketSubprotocol 获取
+//   - 当 readBufferSize 或 writeBufferSize <= 0 时将使用 websocket 库的默认值
+//   - 该选项仅在创建 NetworkWebsocket 服务器时有效
+func WithWebsocketUpgradeConfig(checkOrigin func(r *http.Request) bool, subprotocols []string, handshakeTimeout time.Duration, readBufferSize, writeBufferSize int) Option {
+	return WithWebsocketUpgrade(&websocket.Upgrader{
+		HandshakeTimeout: handshakeTimeout,
+		ReadBufferSize:   readBufferSize,
+		WriteBufferSize:  writeBufferSize,
+		Subprotocols:     subprotocols,
+		CheckOrigin:      checkOrigin,
+	})
+}
+
 // WithConnWriteBufferSize 通过连接写入缓冲区大小的方式创建服务器
 //   - 默认值为 DefaultConnWriteBufferSize
 //   - 设置合适的缓冲区大小可以提高服务器性能，但是会占用更多的内存
@@ -134,6 +210,84 @@ func WithMessageStatistics(duration time.Duration, limit int) Option {
 	}
 }
 
+// WithShuntReleaseDelay 通过延迟释放分流渠道的方式创建服务器
+//   - 默认情况下，当分流渠道中的最后一个连接离开后，分流渠道将在消息处理完毕后立即关闭
+//   - 设置 delay 后，没有任何连接的分流渠道将保留 delay 时长，在此期间重新加入的连接将继续使用该分流渠道及其中尚未处理的消息，超时后才会关闭并触发 OnShuntChannelClosedEvent
+//   - 服务器停止时，等待释放的分流渠道将被立即关闭
+func WithShuntReleaseDelay(delay time.Duration) Option {
+	return func(srv *Server) {
+		srv.shuntReleaseDelay = delay
+	}
+}
+
+// WithShuntMatcher 通过匹配函数自动选择连接消息分流渠道的方式创建服务器，适用于按房间、战场等维度划分分流渠道的场景，无需在处理函数中调用 UseShunt
+//   - 连接打开时将以 nil 数据包进行匹配，匹配结果将在 ConnectionOpenedEvent 触发前生效
+//   - 每个数据包在进入消息队列前都将进行匹配，当匹配的分流渠道与连接当前的分流渠道不同时将切换分流渠道，该数据包将在新的分流渠道中处理
+//   - 切换分流渠道时，原分流渠道中尚未处理的消息将继续在原分流渠道中处理，因此可能与新分流渠道中的消息并发执行
+//   - 匹配函数将在接收数据包的协程中执行，不应执行耗时操作
+func WithShuntMatcher(matcher ShuntMatcher) Option {
+	return func(srv *Server) {
+		srv.shuntMatcher = matcher
+	}
+}
+
+// WithUniqueMessagePolicy 通过指定同名唯一消息正在执行时推送唯一消息的处理策略的方式创建服务器，对 PushUniqueAsyncMessage 及 PushUniqueShuntAsyncMessage 均有效
+//   - 默认策略为 UniqueMessagePolicyDiscard，新的唯一消息将被丢弃
+//   - 策略为 UniqueMessagePolicyQueueLatest 时，最新的唯一消息将被暂存，并在正在执行的同名唯一消息（包括其回调函数）结束后执行
+//   - 唯一消息被丢弃或被更新的消息合并时，将触发 OnUniqueMessageDroppedEvent 事件
+func WithUniqueMessagePolicy(policy UniqueMessagePolicy) Option {
+	return func(srv *Server) {
+		srv.uniqueMessagePolicy = policy
+	}
+}
+
+// WithLoginConflictPolicy 通过指定不同连接通过 Server.BindIdentity 绑定相同身份时的处理策略的方式创建服务器
+//   - 默认策略为 LoginConflictPolicyKickOld
+//   - limit 为策略为 LoginConflictPolicyMultiSession 时相同身份允许同时绑定的连接数量，当 limit <= 0 时不限制数量
+//   - notice 为被踢出的连接在关闭前将收到的通知数据包，为 nil 时将直接关闭连接
+func WithLoginConflictPolicy(policy LoginConflictPolicy, limit int, notice []byte) Option {
+	return func(srv *Server) {
+		srv.loginConflictPolicy = policy
+		srv.loginConflictLimit = limit
+		srv.loginConflictNotice = notice
+	}
+}
+
+// WithUDPSessionTimeout 通过指定 UDP 虚拟会话不活跃超时时长的方式创建服务器
+//   - UDP 模式下，来自同一远程地址的数据报将共享同一个连接，首个数据报到达时将触发 OnConnectionOpenedEvent
+//   - 当会话超过 timeout 未收到任何数据报时，连接将被关闭并以 ErrUDPSessionTimeout 触发 OnConnectionClosedEvent
+//   - 默认值为 DefaultUDPSessionTimeout，当 timeout <= 0 时会话将不会因不活跃而关闭
+func WithUDPSessionTimeout(timeout time.Duration) Option {
+	return func(srv *Server) {
+		srv.udpSessionTimeout = timeout
+	}
+}
+
+// WithUDPSessionToken 通过令牌识别 UDP 虚拟会话的方式创建服务器
+//   - 默认情况下 UDP 虚拟会话通过远程地址识别，当客户端的地址因 NAT 重绑定等原因发生变化时将被视为新的会话
+//   - 设置 resolver 后将通过数据报中的令牌识别会话，地址变化后连接保持不变，并向最新的远程地址发送数据
+func WithUDPSessionToken(resolver UDPSessionTokenResolver) Option {
+	return func(srv *Server) {
+		srv.udpSessionToken = resolver
+	}
+}
+
+// WithMessageStatisticsReporter 通过周期性生成消息统计报告的方式创建服务器，报告中包含吞吐量、各分流渠道的队列深度及消息处理耗时的百分位
+//   - 默认不开启，当 interval 大于 0 且 reporter 不为 nil 时，服务器将每隔 interval 在独立的协程中调用 reporter，可用于将统计数据推送至外部监控系统
+//   - 处理耗时的百分位基于消息分发器中同步消息及异步消息的实际处理耗时计算，当周期内消息量较大时将进行抽样
+func WithMessageStatisticsReporter(interval time.Duration, reporter MessageStatisticsReporter) Option {
+	return func(srv *Server) {
+		if interval <= 0 || reporter == nil {
+			return
+		}
+		srv.messageReporter = &messageReporter{
+			interval: interval,
+			reporter: reporter,
+			samples:  make([]time.Duration, 0, messageReporterSampleSize),
+		}
+	}
+}
+
 // WithPacketWarnSize 通过数据包大小警告的方式创建服务器，当数据包大小超过指定大小时，将会输出 WARN 类型的日志
 //   - 默认值为 DefaultPacketWarnSize
 //   - 当 size <= 0 时，表示不设置警告
@@ -148,11 +302,29 @@ func WithPacketWarnSize(size int) Option {
 	}
 }
 
+// WithPacketLimitSize 通过限制数据包大小的方式创建服务器，当接收到的数据包大小超过 size 时，将根据 policy 进行处理并触发 OnConnectionPacketOversizeEvent 事件
+//   - 与 WithPacketWarnSize 仅输出日志不同，超出限制的数据包将被丢弃，不会进入消息处理流程
+//   - 对于 NetworkWebsocket 而言，将设置连接的读取限制，超出限制的数据帧不会被完整读入内存，同时连接将被关闭，可通过 WithWebsocketMessageAssembly 改为丢弃剩余的延续帧
+//   - 当 size <= 0 时，表示不限制数据包大小
+func WithPacketLimitSize(size int, policy PacketLimitPolicy) Option {
+	return func(srv *Server) {
+		if size <= 0 {
+			srv.packetLimitSize = 0
+			return
+		}
+		srv.packetLimitSize = size
+		srv.packetLimitPolicy = policy
+	}
+}
+
 // WithLimitLife 通过限制最大生命周期的方式创建服务器
-//   - 通常用于测试服务器，服务器将在到达最大生命周期时自动关闭
-func WithLimitLife(t time.Duration) Option {
+//   - 通常用于测试服务器或基于对局的专用服务器，服务器将在到达最大生命周期时自动关闭
+//   - thresholds 为生命周期结束前的预警阈值，当剩余时长到达各个阈值时将触发 OnLifeEndingEvent 事件，<= 0 的阈值将被忽略
+//   - 可通过 Server.ExtendLife 延长生命周期
+func WithLimitLife(t time.Duration, thresholds ...time.Duration) Option {
 	return func(srv *Server) {
 		srv.limitLife = t
+		srv.lifeThresholds = thresholds
 	}
 }
 
@@ -160,7 +332,7 @@ func WithLimitLife(t time.Duration) Option {
 //   - 默认不开启数据压缩
 func WithWebsocketWriteCompression() Option {
 	return func(srv *Server) {
-		if srv.network != NetworkWebsocket {
+		if !srv.hasNetwork(NetworkWebsocket) {
 			return
 		}
 		srv.websocketWriteCompression = true
@@ -171,7 +343,7 @@ func WithWebsocketWriteCompression() Option {
 //   - 默认不开启数据压缩
 func WithWebsocketCompression(level int) Option {
 	return func(srv *Server) {
-		if srv.network != NetworkWebsocket {
+		if !srv.hasNetwork(NetworkWebsocket) {
 			return
 		}
 		if !(-2 <= level && level <= 9) {
@@ -182,7 +354,8 @@ func WithWebsocketCompression(level int) Option {
 }
 
 // WithDeadlockDetect 通过死锁、死循环、永久阻塞检测的方式创建服务器
-//   - 当检测到死锁、死循环、永久阻塞时，服务器将会生成 WARN 类型的日志，关键字为 "SuspectedDeadlock"
+//   - 当检测到死锁、死循环、永久阻塞时，服务器将会生成 WARN 类型的日志，关键字为 "SuspectedDeadlock"，日志中包含消息处理函数的定义位置
+//   - 可通过 RegDeadlockDetectEvent 获取死锁检测报告，通过 WithDeadlockGoroutineDump 在报告中包含所有协程的堆栈
 //   - 默认不开启死锁检测
 func WithDeadlockDetect(t time.Duration) Option {
 	return func(srv *Server) {
@@ -214,13 +387,29 @@ func WithAsyncPoolSize(size int) Option {
 //   - 当 t <= 0 时，表示不设置超时时间
 func WithWebsocketReadDeadline(t time.Duration) Option {
 	return func(srv *Server) {
-		if srv.network != NetworkWebsocket {
+		if !srv.hasNetwork(NetworkWebsocket) {
 			return
 		}
 		srv.websocketReadDeadline = t
 	}
 }
 
+// WithWebsocketMessageAssembly 通过显式组装 websocket 分片消息的方式创建服务器，首帧及所有延续帧将被组装为一个完整的数据包后交由消息处理流程
+//   - maxSize：组装后的消息大小上限，当 maxSize <= 0 时将使用 WithPacketLimitSize 设置的大小限制，均未设置时不限制
+//   - timeout：接收到首帧后组装完整消息的超时时间，超时后连接将以 ErrWebsocketAssemblyTimeout 关闭，当 timeout <= 0 时表示不限制
+//
+// 与仅通过 WithPacketLimitSize 限制大小不同，超出大小限制的消息的剩余延续帧将被读取并丢弃，因此 PacketLimitPolicyDiscard 策略下连接将保持可用
+//   - 超出大小限制时 OnConnectionPacketOversizeEvent 事件中的 size 为消息的完整大小
+//   - 组装完成后将恢复 WithWebsocketReadDeadline 设置的读取超时时间
+func WithWebsocketMessageAssembly(maxSize int, timeout time.Duration) Option {
+	return func(srv *Server) {
+		if !srv.hasNetwork(NetworkWebsocket) {
+			return
+		}
+		srv.websocketAssembly = &websocketAssembly{limit: maxSize, timeout: timeout}
+	}
+}
+
 // WithTicker 通过定时器创建服务器，为服务器添加定时器功能
 //   - poolSize：指定服务器定时器池大小，当池子内的定时器数量超出该值后，多余的定时器在释放时将被回收，该值小于等于 0 时将使用 timer.DefaultTickerPoolSize
 //   - size：服务器定时器时间轮大小
@@ -244,12 +433,37 @@ func WithTicker(poolSize, size, connSize int, autonomy bool) Option {
 	}
 }
 
+// WithGNetOptions 通过 gnet 的可选项创建服务器，可选项将被传递给底层的 gnet.Serve 调用
+//   - 支持：Tcp、Tcp4、Tcp6、Udp、Udp4、Udp6、Unix
+//   - 默认已启用 gnet.WithTicker(true) 及 gnet.WithMulticore(true)，传入的可选项将在默认可选项之后应用，可用于覆盖默认行为
+//   - 可用于开启 SO_REUSEPORT、指定事件循环数量、设置 TCP KeepAlive 及 Socket 缓冲区大小等，例如：
+//     server.WithGNetOptions(gnet.WithReusePort(true), gnet.WithNumEventLoop(8), gnet.WithTCPKeepAlive(time.Minute), gnet.WithSocketRecvBuffer(1<<20))
+//   - 需要注意的是，gnet.WithLogger 及 gnet.WithTicker(false) 可能会影响服务器的正常运行
+func WithGNetOptions(options ...gnet.Option) Option {
+	return func(srv *Server) {
+		if srv.hasNetwork(NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix) {
+			srv.gnetOptions = append(srv.gnetOptions, options...)
+		}
+	}
+}
+
+// WithKCPConfig 通过指定 KCP 会话配置的方式创建服务器，配置将在接受连接时应用于每个 kcp.UDPSession
+//   - 支持：Kcp
+//   - 默认的 KCP 参数偏向于节省带宽，对于实时性要求较高的游戏，通常需要开启 nodelay 模式并调整窗口大小
+func WithKCPConfig(config KCPConfig) Option {
+	return func(srv *Server) {
+		if !srv.hasNetwork(NetworkKcp) {
+			return
+		}
+		srv.kcpConfig = &config
+	}
+}
+
 // WithTLS 通过安全传输层协议TLS创建服务器
 //   - 支持：Http、Websocket
 func WithTLS(certFile, keyFile string) Option {
 	return func(srv *Server) {
-		switch srv.network {
-		case NetworkHttp, NetworkWebsocket:
+		if srv.hasNetwork(NetworkHttp, NetworkWebsocket) {
 			srv.certFile = certFile
 			srv.keyFile = keyFile
 		}
@@ -266,10 +480,50 @@ func WithGRPCServerOptions(options ...grpc.ServerOption) Option {
 	}
 }
 
+// WithGRPCHealth 通过注册 GRPC 健康检查服务的方式创建服务器，可用于 grpc-health-probe 及 Kubernetes 的 GRPC 探针
+//   - 该选项仅在创建 NetworkGRPC 服务器或通过 WithGRPCMultiplex 复用 GRPC 服务时有效
+//   - 服务器启动完成前及开始停止后，整体状态及所有服务的状态为 NOT_SERVING，启动完成后为 SERVING
+//   - 可通过 Server.GRPCHealth 设置特定服务的状态
+func WithGRPCHealth() Option {
+	return func(srv *Server) {
+		if srv.network != NetworkGRPC && srv.network != NetworkHttp && srv.network != NetworkWebsocket {
+			return
+		}
+		srv.grpcHealth = health.NewServer()
+		srv.grpcHealth.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// WithGRPCReflection 通过注册 GRPC 服务反射的方式创建服务器，开启后可通过 grpcurl 等工具在没有 proto 文件的情况下调用服务
+//   - 该选项仅在创建 NetworkGRPC 服务器或通过 WithGRPCMultiplex 复用 GRPC 服务时有效
+func WithGRPCReflection() Option {
+	return func(srv *Server) {
+		if srv.network != NetworkGRPC && srv.network != NetworkHttp && srv.network != NetworkWebsocket {
+			return
+		}
+		srv.grpcReflection = true
+	}
+}
+
+// WithGRPCMultiplex 通过在同一监听地址上同时提供 GRPC 及 HTTP 服务的方式创建服务器，适用于不希望为 GRPC 额外开放端口的小型部署
+//   - 该选项仅在创建 NetworkHttp 或 NetworkWebsocket 服务器时有效，Websocket 连接及通过 HttpRouter、HttpServer 注册的路由均不受影响
+//   - 服务器将嗅探连接的首个数据，以 HTTP/2 连接前言开头的连接将交由 GRPC 服务器处理，其他连接交由 HTTP 服务器处理
+//   - 由于嗅探需在明文数据上进行，开启 TLS 时将无法区分 GRPC 流量，需要 TLS 时可由网关等前置服务终止 TLS
+//   - 可通过 Server.GRPCServer 注册 GRPC 服务，options 将用于创建 GRPC 服务器
+func WithGRPCMultiplex(options ...grpc.ServerOption) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp && srv.network != NetworkWebsocket {
+			return
+		}
+		srv.grpcServer = grpc.NewServer(options...)
+		srv.grpcMultiplex = true
+	}
+}
+
 // WithWebsocketMessageType 设置仅支持特定类型的Websocket消息
 func WithWebsocketMessageType(messageTypes ...int) Option {
 	return func(srv *Server) {
-		if srv.network != NetworkWebsocket {
+		if !srv.hasNetwork(NetworkWebsocket) {
 			return
 		}
 		var supports = make(map[int]bool)
@@ -283,12 +537,339 @@ func WithWebsocketMessageType(messageTypes ...int) Option {
 	}
 }
 
+// WithHttpEngine 通过指定 HTTP 引擎的方式创建服务器，使用其他基于 net/http 的路由器的项目同样可以使用 NetworkHttp
+//   - 指定后服务器将不再使用内部的 gin.Engine，HttpServer 及 HttpRouter 将会发生 panic，路由需要通过 HttpEngine 注册
+//   - 基于 gin 的 WithHttpCORS、WithHttpGzip、WithHttpAccessLog 及 WithPProf 选项将不再生效
+//   - 该选项仅在创建 NetworkHttp 服务器时有效
+func WithHttpEngine(engine HttpEngine) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp || engine == nil {
+			return
+		}
+		srv.httpEngine = engine
+		srv.ginServer = nil
+		srv.httpServer.Handler = srv.httpEngineHandler(engine)
+	}
+}
+
+// WithHttpCORS 通过跨域资源共享配置创建服务器，预检请求将直接由中间件响应
+//   - 该选项仅在创建 NetworkHttp 或 NetworkWebsocket 服务器时有效，且仅对此后注册的路由生效
+func WithHttpCORS(config HttpCORSConfig) Option {
+	return func(srv *Server) {
+		if srv.ginServer == nil {
+			return
+		}
+		srv.ginServer.Use(httpCORS(config))
+	}
+}
+
+// WithHttpGzip 通过对 HTTP 响应进行 gzip 压缩的方式创建服务器，level 为 compress/gzip 中的压缩级别，非法的级别将使用默认级别
+//   - 该选项仅在创建 NetworkHttp 或 NetworkWebsocket 服务器时有效，且仅对此后注册的路由生效
+func WithHttpGzip(level int) Option {
+	return func(srv *Server) {
+		if srv.ginServer == nil {
+			return
+		}
+		srv.ginServer.Use(httpGzip(level))
+	}
+}
+
+// WithHttpAccessLog 通过记录 HTTP 访问日志的方式创建服务器，日志中将包含请求方法、状态码、来源 IP、路径、响应大小及耗时
+//   - 该选项仅在创建 NetworkHttp 或 NetworkWebsocket 服务器时有效，且仅对此后注册的路由生效
+func WithHttpAccessLog() Option {
+	return func(srv *Server) {
+		if srv.ginServer == nil {
+			return
+		}
+		srv.ginServer.Use(httpAccessLog())
+	}
+}
+
 // WithPProf 通过性能分析工具PProf创建服务器
+//   - 该选项仅在创建 NetworkHttp 或 NetworkWebsocket 服务器时有效
 func WithPProf(pattern ...string) Option {
 	return func(srv *Server) {
-		if srv.network != NetworkHttp {
+		if srv.ginServer == nil {
 			return
 		}
 		pprof.Register(srv.ginServer, pattern...)
 	}
 }
+
+// WithPacketCompression 通过数据包压缩的方式创建服务器，对 TCP、UDP、Unix、KCP、Websocket 等 Socket 服务器均有效
+//   - algorithm 为写入数据包时所使用的压缩算法，读取时将根据数据包标记位自动选择解压缩算法
+//   - threshold 为压缩阈值，仅当数据包大小大于等于该值时才会进行压缩，当 threshold <= 0 时将压缩所有数据包
+//   - 连接默认不启用压缩，客户端需通过 MarshalPacketCompressionHandshake 进行协商，协商后该连接的数据包头部都将携带 1 字节的压缩算法标记位，因此不支持压缩的客户端不受影响
+//   - 解压缩后的数据包大小同样受 WithPacketLimitSize 限制，超出限制时连接将以 ErrPacketOversize 关闭
+func WithPacketCompression(algorithm PacketCompression, threshold int) Option {
+	return func(srv *Server) {
+		if !srv.IsSocket() {
+			return
+		}
+		if _, exist := packetCompressionNames[algorithm]; !exist {
+			panic(ErrPacketCompressionUnsupported)
+		}
+		srv.packetCompression = algorithm
+		srv.packetCompressionThreshold = threshold
+	}
+}
+
+// WithPanicPolicy 通过指定消息处理函数发生 panic 时的处理策略的方式创建服务器
+//   - 默认策略为 PanicPolicyLog，即仅记录日志
+//   - 当未指定 messageTypes 时，将作为所有消息类型的默认策略，否则仅对指定的消息类型生效
+//   - 可多次使用该选项为不同的消息类型指定不同的策略，指定了消息类型的策略优先级高于默认策略
+func WithPanicPolicy(policy PanicPolicy, messageTypes ...MessageType) Option {
+	return func(srv *Server) {
+		if len(messageTypes) == 0 {
+			srv.panicPolicy = policy
+			return
+		}
+		if srv.panicPolicies == nil {
+			srv.panicPolicies = make(map[MessageType]PanicPolicy)
+		}
+		for _, messageType := range messageTypes {
+			srv.panicPolicies[messageType] = policy
+		}
+	}
+}
+
+// WithAudit 通过开启审计记录的方式创建服务器，开启后可通过 Server.Audit 或 Conn.Audit 记录敏感操作
+//   - sink 为审计记录的输出目标，审计记录将在独立的协程中被批量写入
+//   - batchSize 为单批次写入的最大审计记录数量，同时作为缓冲区大小，当 batchSize <= 0 时将使用 DefaultAuditBatchSize
+//   - interval 为缓冲区的刷新间隔，当 interval <= 0 时将使用 DefaultAuditFlushInterval
+//   - filters 为审计记录过滤器，仅当所有过滤器均返回 true 时审计记录才会被写入，可用于仅审计特定的操作行为或操作者
+//   - 服务器关闭时将等待剩余的审计记录写入完成
+func WithAudit(sink AuditSink, batchSize int, interval time.Duration, filters ...AuditFilter) Option {
+	return func(srv *Server) {
+		if sink == nil {
+			return
+		}
+		if batchSize <= 0 {
+			batchSize = DefaultAuditBatchSize
+		}
+		if interval <= 0 {
+			interval = DefaultAuditFlushInterval
+		}
+		srv.auditor = &auditor{
+			sink:      sink,
+			batchSize: batchSize,
+			interval:  interval,
+			filters:   filters,
+			records:   make(chan *AuditRecord, batchSize),
+			done:      make(chan struct{}),
+		}
+	}
+}
+
+// WithWriteCoalescing 通过开启写入合并的方式创建服务器，连接在 maxDelay 内写入的多个数据包将被合并为一次系统调用写入
+//   - 仅支持 NetworkTcp、NetworkTcp4、NetworkTcp6、NetworkUnix 及 NetworkKcp 这类流式网络，其他网络类型将忽略该选项
+//   - maxBytes 为合并的最大字节数，当等待写入的数据包总字节数达到该值时将立即写入，当 maxBytes <= 0 时仅根据 maxDelay 写入
+//   - maxDelay 为合并的最大延迟，当 maxDelay <= 0 时将关闭写入合并
+//   - 适用于 MMO 等需要频繁发送大量小型状态同步数据包的场景，但会为每个数据包带来至多 maxDelay 的额外延迟
+func WithWriteCoalescing(maxBytes int, maxDelay time.Duration) Option {
+	return func(srv *Server) {
+		if srv.hasNetwork(NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUnix, NetworkKcp) {
+			srv.writeCoalescingMaxBytes = maxBytes
+			srv.writeCoalescingMaxDelay = maxDelay
+		}
+	}
+}
+
+// WithConnectionLimit 通过限制连接数的方式创建服务器，在接受连接时超出限制的连接将被拒绝，并触发 OnConnectionRejectedEvent 事件
+//   - total 为连接总数上限，perIP 为来自同一 IP 的连接数上限，当值 <= 0 时表示不限制
+//   - 适用于 NetworkWebsocket、NetworkKcp 及基于 gnet 的网络类型，机器人连接不受限制
+//   - 可在运行时通过 Server.SetConnectionLimit 进行调整，以便在流量高峰时保护服务器
+func WithConnectionLimit(total, perIP int) Option {
+	return func(srv *Server) {
+		srv.limiter.set(total, perIP)
+	}
+}
+
+// WithListener 通过预先创建的监听器创建服务器，服务器运行时将使用该监听器而不再自行绑定地址
+//   - 支持：Http、Websocket、GRPC
+//   - 适用于测试或由父进程共享端口等场景，对于 systemd 的套接字激活（socket activation）而言，服务器将在 Run 时自动继承 LISTEN_FDS 中的监听器，无需使用该选项
+//   - 对于 NetworkWebsocket 而言，Run 的参数仍将用于解析路由，例如 Run("/ws")
+//   - 由于 gnet 不支持使用外部监听器，基于 gnet 的网络类型将忽略该选项
+func WithListener(listener net.Listener) Option {
+	return func(srv *Server) {
+		switch srv.network {
+		case NetworkHttp, NetworkWebsocket, NetworkGRPC:
+			srv.listener = listener
+		}
+	}
+}
+
+// WithPacketConn 通过预先创建的数据包连接创建服务器，服务器运行时将使用该连接而不再自行绑定地址
+//   - 支持：Kcp
+//   - 适用于测试、套接字激活（socket activation）或由父进程共享端口等场景
+func WithPacketConn(conn net.PacketConn) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkKcp {
+			return
+		}
+		srv.packetConn = conn
+	}
+}
+
+// WithAdditionalListen 通过在额外地址上监听的方式创建服务器，所有地址上的连接将共享同一服务器的消息分发器、事件及在线连接
+//   - 支持：Tcp、Tcp4、Tcp6、Udp、Udp4、Udp6、Unix、Websocket、Kcp，地址格式与 Run 的参数相同，例如 ":8889/ws"
+//   - 可多次使用以监听多个地址，额外的监听地址将在 Run 指定的地址启动完成后依次启动，任一地址启动失败时 Run 将返回错误
+//   - 与网络类型相关的可选项（例如 WithWebsocketReadDeadline、WithKCPConfig）将同时作用于额外的监听地址，但应在该选项之后使用
+//   - WithListener 及 WithPacketConn 仅作用于 Run 指定的地址
+//   - 适用于同一服务器同时向不同平台的客户端提供 TCP 及 Websocket 等接入方式的场景，无需通过 MultipleServer 维护多份在线连接
+func WithAdditionalListen(network Network, addr string) Option {
+	return func(srv *Server) {
+		if _, exist := socketNetworks[network]; !exist {
+			panic(fmt.Errorf("%w: %s", ErrAdditionalListenUnsupported, network))
+		}
+		if !srv.hasNetwork(network) {
+			network.preprocessing(srv)
+		}
+		srv.additionalListens = append(srv.additionalListens, additionalListen{network: network, addr: addr})
+	}
+}
+
+// WithMessageMemoryLimit 通过限制等待处理的消息估算占用内存的方式创建服务器，以避免消息队列的增长导致进程内存耗尽
+//   - 当所有消息分发器中等待处理的消息估算占用的内存达到 limit 字节时，新推送的数据包消息将被丢弃，并触发 OnMessageMemoryOverflowEvent 事件
+//   - 为了保证服务器内部状态的正确性，除数据包外的其他消息类型不会被丢弃
+//   - 估算值仅包含消息结构体、数据包及消息名称，不包含闭包捕获的数据，可通过 Server.GetMemoryReport 观察
+//   - 默认值为 0，表示不限制
+func WithMessageMemoryLimit(limit int64) Option {
+	return func(srv *Server) {
+		srv.messageMemoryLimit = limit
+	}
+}
+
+// WithGeoResolver 通过在连接打开时解析其地理位置的方式创建服务器，解析结果可通过 Conn.GetGeo 获取，以便于匹配、合规及路由等逻辑使用
+//   - 解析将在连接打开事件被处理之前、于接受连接的协程中同步执行，解析失败时连接将不会被标记地理位置
+//   - 机器人连接将不会被解析
+func WithGeoResolver(resolver GeoResolver) Option {
+	return func(srv *Server) {
+		srv.geoResolver = resolver
+	}
+}
+
+// WithProtocolVersion 通过声明支持的协议版本范围 [min, max] 的方式创建服务器，连接建立后的首个数据包必须为协议版本握手数据包
+//   - 客户端可通过 MarshalProtocolHandshake 构建握手数据包，并通过 UnmarshalProtocolReply 解析服务器的回复
+//   - 版本兼容时将回复协商成功的数据包，协商的版本可通过 Conn.GetProtocolVersion 获取
+//   - 版本不兼容或首个数据包不是握手数据包时，将回复包含支持范围的 "请更新" 数据包，并以 ErrProtocolVersionIncompatible 关闭连接，而不是将数据包交由业务解码
+//   - 握手数据包不会触发 ConnectionReceivePacketEvent 事件，机器人连接将不进行协商
+//   - 当 max 为 0 或 min > max 时表示不进行协商
+func WithProtocolVersion(min, max uint32) Option {
+	return func(srv *Server) {
+		if max == 0 || min > max {
+			srv.protocolVersionMin, srv.protocolVersionMax = 0, 0
+			return
+		}
+		srv.protocolVersionMin, srv.protocolVersionMax = min, max
+	}
+}
+
+// WithConnStateWhitelist 通过按连接状态限制允许处理的消息协议号的方式创建服务器，使未鉴权的连接无法触达游戏逻辑的处理函数
+//   - 连接的初始状态为 ConnStateConnected，可通过 Conn.SetState 切换状态，例如 Connected → Authenticated → InGame
+//   - 数据包在触发 ConnectionReceivePacketEvent 事件前将通过 resolver 解析协议号，协议号不在连接当前状态的白名单中、无法解析或状态未声明白名单时，数据包将被丢弃
+//   - 检查在连接的消息分发器中进行，因此在处理函数中调用 Conn.SetState 后，同一连接后续的数据包将立即按新状态检查
+//   - resolver 接收的是经过 ConnectionPacketPreprocessEvent 处理后的数据包，RPC 请求不受限制
+//   - 当 resolver 为 nil 时表示不进行限制
+func WithConnStateWhitelist(resolver ConnStateOpcodeResolver, whitelist map[ConnState][]uint32) Option {
+	return func(srv *Server) {
+		if resolver == nil {
+			srv.connStateGuard = nil
+			return
+		}
+		guard := &connStateGuard{resolver: resolver, whitelist: make(map[ConnState]map[uint32]struct{}, len(whitelist))}
+		for state, opcodes := range whitelist {
+			set := make(map[uint32]struct{}, len(opcodes))
+			for _, opcode := range opcodes {
+				set[opcode] = struct{}{}
+			}
+			guard.whitelist[state] = set
+		}
+		srv.connStateGuard = guard
+	}
+}
+
+// WithAdminServer 通过在 addr 上开启运维管理接口的方式创建服务器，接口将以 JSON 格式返回服务器的运行状态，适用于所有网络类型
+//   - GET /online 在线连接数量及机器人数量
+//   - GET /messages 消息数量、WithMessageStatistics 的消息统计及消息对象池统计
+//   - GET /shunts 所有消息分流渠道及其等待处理的消息数量
+//   - GET /conns 所有在线连接的信息，可通过 offset 及 limit 参数进行分页，例如 /conns?offset=0&limit=100
+//   - GET /memory 等待处理的消息估算占用的内存及 GC 压力报告
+//   - POST /conns/kick?id=xxx 断开特定连接
+//   - POST /shutdown/rolling?timeout=60s 在后台开始滚动停止服务器，timeout 为空时将一直等待在线连接断开，参考 Server.RollingShutdown
+//   - POST /shutdown/handover?timeout=60s 在后台将监听器交接给新进程并排空当前进程，参考 Server.HandoverAndRestart
+//
+// 运维管理接口不包含任何鉴权措施，应当仅监听内网地址，例如 "127.0.0.1:9999"
+func WithAdminServer(addr string) Option {
+	return func(srv *Server) {
+		srv.adminAddr = addr
+	}
+}
+
+// WithBotIdentifier 通过在连接打开时识别机器人连接的方式创建服务器，identifier 返回 true 的连接将通过 Conn.MarkAsBot 被标记为机器人连接
+//   - 识别函数将在 OnConnectionOpenedEvent 事件处理之前执行，此时已经可以获取 websocket 连接的请求参数等连接数据
+//   - 机器人连接不计入 Server.GetOnlineUserCount、消息统计及连接数限制，也不会影响滚动停止时对新连接的判定
+func WithBotIdentifier(identifier BotIdentifier) Option {
+	return func(srv *Server) {
+		srv.botIdentifier = identifier
+	}
+}
+
+// WithRollingQuietPeriod 通过指定滚动停止时等待网关停止路由新客户端的静默时长的方式创建服务器
+//   - 调用 Server.RollingShutdown 后，当持续 period 时长没有新的非机器人连接打开时，将视为网关已停止向该节点路由新的客户端
+//   - period 应当大于网关发现节点状态变更所需的时长，例如注册中心的心跳间隔与网关扫描间隔之和
+//   - 默认值为 DefaultRollingQuietPeriod
+func WithRollingQuietPeriod(period time.Duration) Option {
+	return func(srv *Server) {
+		srv.rollingQuietPeriod = period
+	}
+}
+
+// WithShutdownTimeout 通过指定停止服务器时的全局超时时间的方式创建服务器
+//   - 停止服务器时将等待正在处理的消息及消息分发器结束，超过 timeout 后将放弃等待并继续停止，未执行完毕的消息将被丢弃
+//   - timeout 同样作为 RegisterShutdownHook 注册的钩子函数的上下文截止时间
+//   - 默认不设置超时时间，将一直等待消息执行完毕
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(srv *Server) {
+		srv.shutdownTimeout = timeout
+	}
+}
+
+// WithShutdownCloseConn 通过在停止服务器时主动关闭所有在线连接的方式创建服务器
+//   - 默认情况下在线连接将随网络的关闭而断开，但升级后的 websocket 连接不会随 HTTP 服务器关闭，开启后将由服务器主动关闭并等待连接的写循环结束
+//   - 主动关闭的每个连接都将触发 OnConnectionClosedEvent 事件，依赖停止时连接关闭事件数量的业务逻辑需要注意
+//   - 等待写循环结束同样受 WithShutdownTimeout 约束
+func WithShutdownCloseConn() Option {
+	return func(srv *Server) {
+		srv.shutdownCloseConn = true
+	}
+}
+
+// WithConnectionInitializer 通过在连接打开后以异步消息执行初始化函数的方式创建服务器，适用于加载玩家数据等耗时的连接初始化
+//   - 初始化函数将在 OnConnectionOpenedEvent 及 OnConnectionOpenedAfterEvent 事件处理完成后通过 Server.PushShuntAsyncMessage 执行
+//   - 初始化完成前连接收到的数据包将被暂存，并在初始化完成后按照接收顺序推送，避免数据包先于初始化被处理
+//   - 初始化函数返回错误或发生 panic 时，暂存的数据包将被丢弃，并以该错误关闭连接
+func WithConnectionInitializer(initializer ConnectionInitializer) Option {
+	return func(srv *Server) {
+		srv.connInitializer = initializer
+	}
+}
+
+// WithDeadlockGoroutineDump 通过在死锁检测触发时捕获所有协程堆栈的方式创建服务器，堆栈将通过 DeadlockReport.Goroutines 提供给 OnDeadlockDetectEvent 事件
+//   - 需要配合 WithDeadlockDetect 使用
+//   - 捕获堆栈期间将短暂地暂停所有协程，在协程数量较多时可能产生明显的停顿
+func WithDeadlockGoroutineDump() Option {
+	return func(srv *Server) {
+		srv.deadlockGoroutineDump = true
+	}
+}
+
+// WithRPC 通过开启 RPC 的方式创建服务器，开启后可通过 RegRPCHandler 注册处理函数及通过 Server.Call 向连接发起调用
+//   - 未开启时，以 RPC 标识开头的数据包将作为普通数据包处理
+//   - 开启后所有连接均可发起 RPC 请求，处理函数应当自行校验调用方的身份
+//   - 调用的响应仅会从被调用的连接接受，来自其他连接的响应将被丢弃
+func WithRPC() Option {
+	return func(srv *Server) {
+		srv.rpc = true
+	}
+}
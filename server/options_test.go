@@ -1,9 +1,20 @@
 package server_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/utils/random"
+	"github.com/panjf2000/gnet"
+	"github.com/xtaci/kcp-go/v5"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -57,6 +68,29 @@ func TestWithLowMessageDuration(t *testing.T) {
 	}
 }
 
+func TestWithLowMessageDurations(t *testing.T) {
+	var threshold time.Duration
+	srv := server.New(server.NetworkNone,
+		server.WithLowMessageDurations(time.Millisecond*10, time.Millisecond*20),
+	)
+	srv.RegMessageLowExecDetailEvent(func(srv *server.Server, message *server.Message, cost, t time.Duration) {
+		threshold = t
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		srv.PushAsyncMessage(func() error {
+			time.Sleep(time.Millisecond * 30)
+			return nil
+		}, nil)
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+	if threshold != time.Millisecond*20 {
+		t.Fatalf("threshold: %s", threshold)
+	}
+}
+
 func TestWithAsyncLowMessageDuration(t *testing.T) {
 	var cases = []struct {
 		name     string
@@ -107,3 +141,777 @@ func TestWithAsyncLowMessageDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestWithPacketLimitSize(t *testing.T) {
+	var received, oversize int
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkWebsocket, server.WithPacketLimitSize(4, server.PacketLimitPolicyDiscard))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received = len(packet)
+		conn.Write(packet)
+	})
+	srv.RegConnectionPacketOversizeEvent(func(srv *server.Server, conn *server.Conn, size int, policy server.PacketLimitPolicy) {
+		oversize = size
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			_ = conn.WriteMessage(websocket.BinaryMessage, []byte("ok"))
+			_, _, _ = conn.ReadMessage()
+			_ = conn.WriteMessage(websocket.BinaryMessage, []byte("oversize"))
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if received != 2 || oversize != 5 {
+		t.Fatalf("received: %d, oversize: %d", received, oversize)
+	}
+}
+
+func TestWithWebsocketMessageAssembly(t *testing.T) {
+	var received []int
+	var oversize int
+	var closeErr error
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkWebsocket,
+		server.WithPacketLimitSize(64, server.PacketLimitPolicyDiscard),
+		server.WithWebsocketMessageAssembly(0, time.Millisecond*100),
+	)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received = append(received, len(packet))
+		conn.Write(packet)
+	})
+	srv.RegConnectionPacketOversizeEvent(func(srv *server.Server, conn *server.Conn, size int, policy server.PacketLimitPolicy) {
+		oversize = size
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		if e, ok := err.(error); ok && errors.Is(e, server.ErrWebsocketAssemblyTimeout) {
+			closeErr = e
+			srv.Shutdown()
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			// 较小的写缓冲区将使客户端以多个延续帧发送消息
+			dialer := &websocket.Dialer{WriteBufferSize: 32}
+			conn, _, err := dialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			for _, size := range []int{50, 200, 2} {
+				_ = conn.WriteMessage(websocket.BinaryMessage, bytes.Repeat([]byte{1}, size))
+			}
+			for i := 0; i < 2; i++ {
+				_, _, _ = conn.ReadMessage()
+			}
+
+			writer, err := conn.NextWriter(websocket.BinaryMessage)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			_, _ = writer.Write(bytes.Repeat([]byte{1}, 40))
+			time.Sleep(time.Millisecond * 300)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(received) != "[50 2]" || oversize != 200 || closeErr == nil {
+		t.Fatalf("received: %v, oversize: %d, close: %v", received, oversize, closeErr)
+	}
+}
+
+func TestWithLimitLife(t *testing.T) {
+	var remains []time.Duration
+	srv := server.New(server.NetworkNone, server.WithLimitLife(time.Millisecond*300, time.Millisecond*200, time.Millisecond*100))
+	srv.RegLifeEndingEvent(func(srv *server.Server, remaining time.Duration) {
+		remains = append(remains, remaining)
+		if len(remains) == 1 && !srv.ExtendLife(time.Millisecond*200) {
+			t.Error("extend life failed")
+		}
+	})
+	start := time.Now()
+	if err := srv.Run(""); err != nil {
+		t.Fatal(err)
+	}
+	// 延长生命周期后，剩余 200 毫秒的阈值将再次触发
+	if len(remains) != 3 || time.Since(start) < time.Millisecond*450 {
+		t.Fatalf("remains: %v, cost: %s", remains, time.Since(start))
+	}
+}
+
+func TestWithBotIdentifier(t *testing.T) {
+	var online, user, bot int
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkWebsocket,
+		server.WithConnectionLimit(0, 1),
+		server.WithBotIdentifier(func(conn *server.Conn) bool {
+			return conn.GetData("bot") == "1"
+		}),
+	)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		if conn.IsBot() {
+			return
+		}
+		online, user, bot = srv.GetOnlineCount(), srv.GetOnlineUserCount(), srv.GetOnlineBotCount()
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			// 机器人连接不占用连接数限制的名额，因此来自相同 IP 的玩家连接不会被拒绝
+			for _, query := range []string{"?bot=1", ""} {
+				conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/%s", addr, query), nil)
+				if err != nil {
+					t.Error(err)
+					srv.Shutdown()
+					return
+				}
+				defer conn.Close()
+				time.Sleep(time.Millisecond * 50)
+			}
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if online != 2 || user != 1 || bot != 1 {
+		t.Fatalf("online: %d, user: %d, bot: %d", online, user, bot)
+	}
+}
+
+func TestWithConnectionLimit(t *testing.T) {
+	var reason server.ConnectionRejectedReason
+	var before, loops int64
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkTcp, server.WithConnectionLimit(0, 1))
+	srv.RegConnectionRejectedEvent(func(srv *server.Server, ip string, r server.ConnectionRejectedReason) {
+		reason = r
+		loops = srv.GetWriteLoopCount() - before
+		srv.Shutdown()
+	})
+	var conns = make(chan net.Conn, 2)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		before = srv.GetWriteLoopCount()
+		go func() {
+			// 连接需要保持到服务器停止，否则第一个连接关闭后第二个连接将不会被拒绝
+			for i := 0; i < 2; i++ {
+				conn, err := net.Dial("tcp", addr)
+				if err != nil {
+					t.Error(err)
+					srv.Shutdown()
+					return
+				}
+				conns <- conn
+			}
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	for len(conns) > 0 {
+		_ = (<-conns).Close()
+	}
+	if reason != server.ConnectionRejectedReasonIPLimit {
+		t.Fatalf("reason: %s", reason)
+	}
+	if loops != 1 {
+		// 被拒绝的连接不应创建写循环
+		t.Fatalf("write loops: %d", loops)
+	}
+}
+
+func TestWithGNetOptions(t *testing.T) {
+	var opened bool
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkTcp, server.WithGNetOptions(gnet.WithReusePort(true), gnet.WithNumEventLoop(2)))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened = true
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if !opened {
+		t.Fatal("connection not opened")
+	}
+}
+
+func TestWithKCPConfig(t *testing.T) {
+	var received string
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkKcp, server.WithKCPConfig(server.KCPConfig{
+		NoDelay: true, Interval: 10, Resend: 2, NC: true,
+		SndWnd: 256, RcvWnd: 256, MTU: 1200,
+		FECData: 10, FECParity: 3,
+	}))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received = string(packet)
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			session, err := kcp.DialWithOptions(addr, nil, 10, 3)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer session.Close()
+			_, _ = session.Write([]byte("hello"))
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if received != "hello" {
+		t.Fatalf("received: %s", received)
+	}
+}
+
+func TestWithListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opened bool
+	srv := server.New(server.NetworkWebsocket, server.WithListener(listener))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened = true
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/ws", listener.Addr()), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run("/ws"); err != nil {
+		t.Fatal(err)
+	}
+	if !opened {
+		t.Fatal("connection not opened")
+	}
+}
+
+func TestWithAdditionalListen(t *testing.T) {
+	tcpAddr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	wsAddr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var opened = make(map[bool]bool)
+	srv := server.New(server.NetworkTcp, server.WithAdditionalListen(server.NetworkWebsocket, wsAddr+"/ws"))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened[conn.IsWebsocket()] = true
+		if len(opened) == 2 {
+			srv.Shutdown()
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, err := net.Dial("tcp", tcpAddr)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			ws, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/ws", wsAddr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer ws.Close()
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(tcpAddr); err != nil {
+		t.Fatal(err)
+	}
+	if !opened[false] || !opened[true] {
+		t.Fatal("connection not opened on all listen addresses")
+	}
+}
+
+func TestWithAdminServer(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	adminAddr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var kicked bool
+	srv := server.New(server.NetworkWebsocket, server.WithAdminServer(adminAddr))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		go func(id string) {
+			resp, err := http.Post(fmt.Sprintf("http://%s/conns/kick?id=%s", adminAddr, url.QueryEscape(id)), "", nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("kick status: %d", resp.StatusCode)
+				srv.Shutdown()
+			}
+		}(conn.GetID())
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		kicked = true
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			resp, err := http.Get(fmt.Sprintf("http://%s/online", adminAddr))
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			var online map[string]int
+			if err = json.NewDecoder(resp.Body).Decode(&online); err != nil || online["online"] != 0 {
+				t.Errorf("online: %v, err: %v", online, err)
+			}
+			_ = resp.Body.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/ws", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr + "/ws"); err != nil {
+		t.Fatal(err)
+	}
+	if !kicked {
+		t.Fatal("connection not kicked")
+	}
+}
+
+func TestWithMessageMemoryLimit(t *testing.T) {
+	var received, dropped atomic.Int64
+	srv := server.New(server.NetworkWebsocket, server.WithMessageMemoryLimit(1))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received.Add(1)
+		time.Sleep(time.Millisecond * 50)
+	})
+	srv.RegMessageMemoryOverflowEvent(func(srv *server.Server, message *server.Message, queuedBytes int64) {
+		dropped.Add(1)
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			bot := server.NewBot(srv)
+			bot.JoinServer()
+			for srv.GetOnlineCount() == 0 {
+				time.Sleep(time.Millisecond * 10)
+			}
+			for i := 0; i < 10; i++ {
+				bot.SendPacket([]byte("hello"))
+			}
+			for received.Load()+dropped.Load() != 10 || srv.GetQueuedMessageBytes() != 0 {
+				time.Sleep(time.Millisecond * 10)
+			}
+			if report := srv.GetMemoryReport(); report.Dropped != dropped.Load() {
+				t.Errorf("unexpected memory report: %+v", report)
+			}
+		}()
+	})
+	if err := srv.Run(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+	if dropped.Load() == 0 {
+		t.Fatal("no packet dropped")
+	}
+}
+
+func TestWithGeoResolver(t *testing.T) {
+	resolver := server.NewCIDRGeoResolver()
+	if err := resolver.Add("127.0.0.0/8", server.GeoInfo{Country: "LO", Region: "loopback"}); err != nil {
+		t.Fatal(err)
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var geo server.GeoInfo
+	var tagged bool
+	srv := server.New(server.NetworkWebsocket, server.WithGeoResolver(resolver))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		geo, tagged = conn.GetGeo()
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if !tagged || geo.Country != "LO" || geo.Region != "loopback" {
+		t.Fatalf("unexpected geo: %+v, tagged: %v", geo, tagged)
+	}
+}
+
+func TestWithConnectionInitializer(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var loaded atomic.Bool
+	var packets []string
+	var early bool
+	srv := server.New(server.NetworkWebsocket, server.WithConnectionInitializer(func(srv *server.Server, conn *server.Conn) error {
+		time.Sleep(time.Millisecond * 100)
+		loaded.Store(true)
+		return nil
+	}))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if !loaded.Load() || !conn.IsInitialized() {
+			early = true
+		}
+		if packets = append(packets, string(packet)); len(packets) == 5 {
+			srv.Shutdown()
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			for i := 0; i < 5; i++ {
+				if err = conn.WriteMessage(websocket.BinaryMessage, []byte(fmt.Sprint(i))); err != nil {
+					t.Error(err)
+					srv.Shutdown()
+					return
+				}
+			}
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if early {
+		t.Fatal("packet processed before initialization completed")
+	}
+	if fmt.Sprint(packets) != "[0 1 2 3 4]" {
+		t.Fatalf("unexpected packets order: %v", packets)
+	}
+}
+
+func TestWithDeadlockGoroutineDump(t *testing.T) {
+	var report atomic.Pointer[server.DeadlockReport]
+	srv := server.New(server.NetworkWebsocket, server.WithDeadlockDetect(time.Millisecond*50), server.WithDeadlockGoroutineDump())
+	srv.RegDeadlockDetectEvent(func(srv *server.Server, message *server.Message, r *server.DeadlockReport) {
+		report.Store(r)
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		srv.PushSystemMessage(func() {
+			defer srv.Shutdown()
+			time.Sleep(time.Millisecond * 200)
+		})
+	})
+	if err := srv.Run(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+	r := report.Load()
+	if r == nil {
+		t.Fatal("deadlock not detected")
+	}
+	if len(r.HandlerSites) != 1 || !strings.Contains(r.HandlerSites[0], "options_test.go") {
+		t.Fatalf("unexpected handler sites: %v", r.HandlerSites)
+	}
+	if !bytes.Contains(r.Goroutines, []byte("TestWithDeadlockGoroutineDump")) {
+		t.Fatalf("goroutine dump missing: %s", r.Goroutines)
+	}
+}
+
+func TestWithPanicPolicy(t *testing.T) {
+	var report *server.PanicReport
+	srv := server.New(server.NetworkWebsocket, server.WithPanicPolicy(server.PanicPolicyRestartShunt, server.MessageTypeSystem))
+	srv.RegMessageErrorEvent(func(srv *server.Server, message *server.Message, err error) {
+		errors.As(err, &report)
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		srv.PushSystemMessage(func() {
+			panic("boom")
+		})
+		srv.PushSystemMessage(func() {
+			srv.Shutdown()
+		})
+	})
+	if err := srv.Run(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+	if report == nil {
+		t.Fatal("panic report not received")
+	}
+	if report.Error() != "boom" || report.MessageType != server.MessageTypeSystem || report.Policy != server.PanicPolicyRestartShunt || len(report.Stack) == 0 {
+		t.Fatalf("unexpected panic report: %+v", report)
+	}
+}
+
+func TestWithWebsocketUpgradeConfig(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var subprotocol string
+	srv := server.New(server.NetworkWebsocket, server.WithWebsocketUpgradeConfig(func(r *http.Request) bool {
+		return r.Header.Get("Origin") == "https://game.example.com"
+	}, []string{"v2", "v1"}, time.Second, 1024, 1024))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		subprotocol = conn.GetWebsocketSubprotocol()
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			dialer := &websocket.Dialer{Subprotocols: []string{"v1", "v2"}}
+			if _, _, err := dialer.Dial(fmt.Sprintf("ws://%s", addr), http.Header{"Origin": {"https://evil.example.com"}}); err == nil {
+				t.Error("origin should be rejected")
+			}
+			conn, _, err := dialer.Dial(fmt.Sprintf("ws://%s", addr), http.Header{"Origin": {"https://game.example.com"}})
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if subprotocol != "v2" {
+		t.Fatalf("unexpected subprotocol: %s", subprotocol)
+	}
+}
+
+func TestWithConnStateWhitelist(t *testing.T) {
+	const authenticated server.ConnState = "Authenticated"
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var received []byte
+	srv := server.New(server.NetworkWebsocket, server.WithConnStateWhitelist(func(packet []byte) (opcode uint32, ok bool) {
+		if len(packet) == 0 {
+			return 0, false
+		}
+		return uint32(packet[0]), true
+	}, map[server.ConnState][]uint32{
+		server.ConnStateConnected: {1},
+		authenticated:             {1, 2},
+	}))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received = append(received, packet[0])
+		if packet[0] == 1 {
+			conn.SetState(authenticated)
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			for _, opcode := range []byte{2, 1, 2, 3} {
+				if err = conn.WriteMessage(websocket.BinaryMessage, []byte{opcode}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			time.Sleep(time.Millisecond * 500)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(received) != "[1 2]" {
+		t.Fatalf("unexpected received opcodes: %v", received)
+	}
+}
+
+func TestWithShuntMatcher(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var opened string
+	var received []string
+	srv := server.New(server.NetworkWebsocket, server.WithShuntMatcher(func(conn *server.Conn, packet []byte) string {
+		switch {
+		case packet == nil:
+			return "lobby"
+		case string(packet) == "join":
+			return "room"
+		}
+		return ""
+	}))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened = srv.GetConnCurrShunt(conn)
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if received = append(received, srv.GetConnCurrShunt(conn)); len(received) == 3 {
+			srv.Shutdown()
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			for _, packet := range []string{"chat", "join", "move"} {
+				if err = conn.WriteMessage(websocket.BinaryMessage, []byte(packet)); err != nil {
+					t.Error(err)
+					srv.Shutdown()
+					return
+				}
+				time.Sleep(time.Millisecond * 50)
+			}
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if opened != "lobby" || fmt.Sprint(received) != "[lobby room room]" {
+		t.Fatalf("opened: %s, received: %v", opened, received)
+	}
+}
+
+func TestWithUniqueMessagePolicy(t *testing.T) {
+	var executed []int
+	var dropped int
+	srv := server.New(server.NetworkNone, server.WithUniqueMessagePolicy(server.UniqueMessagePolicyQueueLatest))
+	srv.RegUniqueMessageDroppedEvent(func(srv *server.Server, message *server.Message, policy server.UniqueMessagePolicy) {
+		dropped++
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		var block = make(chan struct{})
+		var done = make(chan struct{})
+		for i := 0; i < 4; i++ {
+			i := i
+			srv.PushUniqueAsyncMessage("save", func() error {
+				if i == 0 {
+					<-block
+				}
+				return nil
+			}, func(err error) {
+				if executed = append(executed, i); i == 3 {
+					close(done)
+				}
+			})
+		}
+		close(block)
+		go func() {
+			select {
+			case <-done:
+			case <-time.After(time.Second * 3):
+				t.Error("queued unique message not executed")
+			}
+			srv.Shutdown()
+		}()
+	})
+	if err := srv.Run(""); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(executed) != "[0 3]" || dropped != 2 {
+		t.Fatalf("executed: %v, dropped: %d", executed, dropped)
+	}
+}
+
+func TestWithLoginConflictPolicy(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var conflicts atomic.Int32
+	var kicked atomic.Bool
+	var notice string
+	srv := server.New(server.NetworkWebsocket, server.WithLoginConflictPolicy(server.LoginConflictPolicyKickOld, 0, []byte("kicked")))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if err := srv.BindIdentity(conn, string(packet)); err != nil {
+			t.Error(err)
+		}
+	})
+	srv.RegLoginConflictEvent(func(srv *server.Server, identity string, old, new *server.Conn, policy server.LoginConflictPolicy) {
+		conflicts.Add(1)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		if e, ok := err.(error); ok && errors.Is(e, server.ErrLoginConflictKicked) {
+			kicked.Store(true)
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			var conns []*websocket.Conn
+			defer func() {
+				for _, conn := range conns {
+					_ = conn.Close()
+				}
+			}()
+			for i := 0; i < 2; i++ {
+				conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				conns = append(conns, conn)
+				if err = conn.WriteMessage(websocket.BinaryMessage, []byte("player")); err != nil {
+					t.Error(err)
+					return
+				}
+				time.Sleep(time.Millisecond * 100)
+			}
+			_ = conns[0].SetReadDeadline(time.Now().Add(time.Second * 3))
+			_, packet, _ := conns[0].ReadMessage()
+			notice = string(packet)
+			time.Sleep(time.Millisecond * 100)
+			if identityConns := srv.GetIdentityConns("player"); len(identityConns) != 1 || identityConns[0].GetIdentity() != "player" {
+				t.Errorf("unexpected identity conns: %d", len(identityConns))
+			}
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts.Load() != 1 || !kicked.Load() || notice != "kicked" {
+		t.Fatalf("conflicts: %d, kicked: %v, notice: %s", conflicts.Load(), kicked.Load(), notice)
+	}
+}
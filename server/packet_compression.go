@@ -0,0 +1,172 @@
+package server
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/utils/compress"
+)
+
+const (
+	// PacketCompressionNone 不进行压缩
+	PacketCompressionNone PacketCompression = iota
+	// PacketCompressionGzip 使用 Gzip 算法进行压缩
+	PacketCompressionGzip
+	// PacketCompressionSnappy 使用 Snappy 算法进行压缩
+	PacketCompressionSnappy
+	// PacketCompressionZstd 使用 Zstd 算法进行压缩
+	PacketCompressionZstd
+)
+
+var packetCompressionNames = map[PacketCompression]string{
+	PacketCompressionNone:   "none",
+	PacketCompressionGzip:   "gzip",
+	PacketCompressionSnappy: "snappy",
+	PacketCompressionZstd:   "zstd",
+}
+
+// PacketCompression 数据包压缩算法
+//   - 连接协商压缩后，每个数据包头部将携带 1 字节的标记位，用于标识该数据包所使用的压缩算法，当标记位为 PacketCompressionNone 时表示数据包未被压缩
+//   - 读取数据包时将根据标记位进行解压缩，因此客户端可自行决定是否对发送的数据包进行压缩
+type PacketCompression byte
+
+// String 返回压缩算法的字符串表示
+func (slf PacketCompression) String() string {
+	return packetCompressionNames[slf]
+}
+
+// compress 使用该算法对数据包进行压缩
+func (slf PacketCompression) compress(packet []byte) ([]byte, error) {
+	switch slf {
+	case PacketCompressionNone:
+		return packet, nil
+	case PacketCompressionGzip:
+		buf, err := compress.GZipCompress(packet)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case PacketCompressionSnappy:
+		return compress.SnappyCompress(packet), nil
+	case PacketCompressionZstd:
+		return compress.ZstdCompress(packet)
+	default:
+		return nil, ErrPacketCompressionUnsupported
+	}
+}
+
+// uncompress 使用该算法对数据包进行解压缩，当 limit > 0 且解压缩后的大小超过 limit 时返回 ErrPacketOversize
+func (slf PacketCompression) uncompress(packet []byte, limit int) (data []byte, err error) {
+	switch slf {
+	case PacketCompressionNone:
+		return packet, nil
+	case PacketCompressionGzip:
+		data, err = compress.GZipUnCompressLimit(packet, limit)
+	case PacketCompressionSnappy:
+		data, err = compress.SnappyUnCompressLimit(packet, limit)
+	case PacketCompressionZstd:
+		data, err = compress.ZstdUnCompressLimit(packet, limit)
+	default:
+		return nil, ErrPacketCompressionUnsupported
+	}
+	if errors.Is(err, compress.ErrUnCompressOversize) {
+		return nil, ErrPacketOversize
+	}
+	return data, err
+}
+
+// MarshalPacketCompressionHandshake 构建客户端的压缩协商握手数据包，客户端应当在支持数据包压缩时于连接建立后发送该数据包
+//   - | identifier(4) | kind(1) |
+//   - 握手数据包本身不携带标记位，发送后客户端的数据包均需携带标记位
+//   - 服务器未通过 WithPacketCompression 开启压缩时，握手数据包将作为普通数据包处理，不会收到回复
+func MarshalPacketCompressionHandshake() []byte {
+	var data = make([]byte, 5)
+	copy(data, protocolPacketIdentifier)
+	data[4] = protocolKindCompression
+	return data
+}
+
+// UnmarshalPacketCompressionReply 反序列化服务器对压缩协商握手的回复，当数据包不是压缩协商回复时 ok 为 false
+//   - | identifier(4) | kind(1) | algorithm(1) |
+//   - 回复本身不携带标记位，此后服务器写入的数据包均将携带标记位
+func UnmarshalPacketCompressionReply(packet []byte) (algorithm PacketCompression, ok bool) {
+	if len(packet) != 6 || [4]byte(packet[:4]) != [4]byte(protocolPacketIdentifier) || packet[4] != protocolKindCompressionAccepted {
+		return PacketCompressionNone, false
+	}
+	return PacketCompression(packet[5]), true
+}
+
+// isPacketCompressionHandshake 检查数据包是否为客户端的压缩协商握手数据包
+func isPacketCompressionHandshake(packet []byte) bool {
+	return len(packet) == 5 && [4]byte(packet[:4]) == [4]byte(protocolPacketIdentifier) && packet[4] == protocolKindCompression
+}
+
+// packPacket 对即将写入连接的数据包进行压缩封装
+//   - 当服务器未开启压缩或连接未启用压缩时，将原样返回数据包
+//   - 数据包大小未达到压缩阈值时，仅添加 PacketCompressionNone 标记位
+func (slf *Conn) packPacket(packet []byte) ([]byte, error) {
+	algorithm := slf.server.packetCompression
+	if !slf.IsPacketCompression() {
+		return packet, nil
+	}
+	if len(packet) < slf.server.packetCompressionThreshold {
+		algorithm = PacketCompressionNone
+	}
+	compressed, err := algorithm.compress(packet)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{byte(algorithm)}, compressed...), nil
+}
+
+// unpackPacket 对从连接读取到的数据包进行解压缩，当 ok 为 false 时表示数据包已被消费或无法读取，不应继续处理
+//   - 所有从网络读取的数据包都将经过该函数，因此将在此记录连接的接收流量
+//   - 当服务器未开启压缩或连接未启用压缩时，将原样返回数据包
+//   - 连接未启用压缩时读取到压缩协商握手数据包将启用压缩，并使用 wst 回复客户端
+//   - 解压缩后的大小超出 WithPacketLimitSize 的限制时将返回 ErrPacketOversize，不会将超出限制的数据读入内存
+func (slf *Conn) unpackPacket(wst int, packet []byte) (data []byte, ok bool, err error) {
+	slf.recordIn(len(packet))
+	if slf.server.packetCompression == PacketCompressionNone {
+		return packet, true, nil
+	}
+	if !slf.compressionIn.Load() {
+		if !isPacketCompressionHandshake(packet) {
+			return packet, true, nil
+		}
+		slf.acceptPacketCompression(wst)
+		return nil, false, nil
+	}
+	if len(packet) == 0 {
+		return nil, false, ErrPacketCompressionHeader
+	}
+	if data, err = PacketCompression(packet[0]).uncompress(packet[1:], slf.server.packetLimitSize); err != nil {
+		if errors.Is(err, ErrPacketOversize) {
+			slf.server.onPacketOversize(slf, slf.server.packetLimitSize+1)
+		}
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// acceptPacketCompression 接受客户端的压缩协商握手，此后读取的数据包均需携带标记位
+//   - 回复将不携带标记位，回复被写入后，此后写入的数据包才会携带标记位
+func (slf *Conn) acceptPacketCompression(wst int) {
+	slf.compressionIn.Store(true)
+	var reply = make([]byte, 6)
+	copy(reply, protocolPacketIdentifier)
+	reply[4] = protocolKindCompressionAccepted
+	reply[5] = byte(slf.server.packetCompression)
+	(&Conn{wst: wst, connection: slf.connection}).writePacket(reply, nil, true)
+}
+
+// SetPacketCompression 设置该连接是否启用数据包压缩，启用后该连接读写的数据包均将携带标记位
+//   - 仅在通过 WithPacketCompression 开启压缩时有效，默认所有连接均不启用，以兼容不支持压缩的客户端
+//   - 通常客户端将通过 MarshalPacketCompressionHandshake 进行协商，仅在已通过其他方式与客户端约定时才需要直接设置
+func (slf *Conn) SetPacketCompression(enable bool) *Conn {
+	slf.compressionIn.Store(enable)
+	slf.compressionOut.Store(enable)
+	return slf
+}
+
+// IsPacketCompression 检查该连接写入的数据包是否将携带标记位
+func (slf *Conn) IsPacketCompression() bool {
+	return slf.server.packetCompression != PacketCompressionNone && slf.compressionOut.Load()
+}
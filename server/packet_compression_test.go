@@ -0,0 +1,113 @@
+package server_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/compress"
+	"github.com/kercylan98/minotaur/utils/random"
+	"testing"
+)
+
+func TestWithPacketCompression(t *testing.T) {
+	var cases = []struct {
+		name       string
+		algorithm  server.PacketCompression
+		compress   func(data []byte) ([]byte, error)
+		uncompress func(data []byte) ([]byte, error)
+	}{
+		{name: "Gzip", algorithm: server.PacketCompressionGzip, compress: func(data []byte) ([]byte, error) {
+			buf, err := compress.GZipCompress(data)
+			return buf.Bytes(), err
+		}, uncompress: compress.GZipUnCompress},
+		{name: "Snappy", algorithm: server.PacketCompressionSnappy, compress: func(data []byte) ([]byte, error) {
+			return compress.SnappyCompress(data), nil
+		}, uncompress: compress.SnappyUnCompress},
+		{name: "Zstd", algorithm: server.PacketCompressionZstd, compress: compress.ZstdCompress, uncompress: compress.ZstdUnCompress},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var received []int
+			var oversize int
+			var closeErr error
+			var replies [][]byte
+			large := bytes.Repeat([]byte("minotaur"), 16)
+			addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+			srv := server.New(server.NetworkWebsocket,
+				server.WithPacketCompression(c.algorithm, 32),
+				server.WithPacketLimitSize(512, server.PacketLimitPolicyDiscard),
+			)
+			srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+				received = append(received, len(packet))
+				conn.Write(packet)
+			})
+			srv.RegConnectionPacketOversizeEvent(func(srv *server.Server, conn *server.Conn, size int, policy server.PacketLimitPolicy) {
+				oversize = size
+			})
+			srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+				closeErr, _ = err.(error)
+				srv.Shutdown()
+			})
+			srv.RegStartFinishEvent(func(srv *server.Server) {
+				go func() {
+					conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+					if err != nil {
+						t.Error(err)
+						srv.Shutdown()
+						return
+					}
+					defer conn.Close()
+					compressed, _ := c.compress(large)
+					bomb, _ := c.compress(bytes.Repeat([]byte{0}, 4096))
+					for _, packet := range [][]byte{
+						[]byte("legacy"),
+						server.MarshalPacketCompressionHandshake(),
+						append([]byte{byte(server.PacketCompressionNone)}, "small"...),
+						append([]byte{byte(c.algorithm)}, compressed...),
+					} {
+						_ = conn.WriteMessage(websocket.BinaryMessage, packet)
+						_, reply, err := conn.ReadMessage()
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						replies = append(replies, reply)
+					}
+					_ = conn.WriteMessage(websocket.BinaryMessage, append([]byte{byte(c.algorithm)}, bomb...))
+					_, _, _ = conn.ReadMessage()
+				}()
+			})
+			if err := srv.Run(addr); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(replies) != 4 {
+				t.Fatalf("replies: %d", len(replies))
+			}
+			if string(replies[0]) != "legacy" {
+				t.Fatalf("legacy reply: %v", replies[0])
+			}
+			if algorithm, ok := server.UnmarshalPacketCompressionReply(replies[1]); !ok || algorithm != c.algorithm {
+				t.Fatalf("handshake reply: %v", replies[1])
+			}
+			if replies[2][0] != byte(server.PacketCompressionNone) || string(replies[2][1:]) != "small" {
+				t.Fatalf("below threshold reply: %v", replies[2])
+			}
+			if replies[3][0] != byte(c.algorithm) {
+				t.Fatalf("above threshold reply flag: %d", replies[3][0])
+			}
+			if data, err := c.uncompress(replies[3][1:]); err != nil || !bytes.Equal(data, large) {
+				t.Fatalf("above threshold reply: %v", err)
+			}
+			if fmt.Sprint(received) != fmt.Sprintf("[6 5 %d]", len(large)) {
+				t.Fatalf("received: %v", received)
+			}
+			if oversize != 513 || !errors.Is(closeErr, server.ErrPacketOversize) {
+				t.Fatalf("oversize: %d, close: %v", oversize, closeErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+)
+
+const (
+	// PacketLimitPolicyDiscard 丢弃超出大小限制的数据包，连接将保持可用
+	//   - 对于 NetworkWebsocket 而言，由于超出读取限制后无法继续读取后续数据，连接将始终被关闭，通过 WithWebsocketMessageAssembly 显式组装分片消息时除外
+	PacketLimitPolicyDiscard PacketLimitPolicy = iota
+	// PacketLimitPolicyClose 丢弃超出大小限制的数据包并关闭连接
+	PacketLimitPolicyClose
+)
+
+var packetLimitPolicyNames = map[PacketLimitPolicy]string{
+	PacketLimitPolicyDiscard: "PacketLimitPolicyDiscard",
+	PacketLimitPolicyClose:   "PacketLimitPolicyClose",
+}
+
+// PacketLimitPolicy 数据包超出大小限制时的处理策略
+type PacketLimitPolicy byte
+
+// String 返回处理策略的字符串表示
+func (slf PacketLimitPolicy) String() string {
+	return packetLimitPolicyNames[slf]
+}
+
+// checkPacketLimit 检查数据包是否超出大小限制，超出限制时将根据策略进行处理并返回 false
+func (srv *Server) checkPacketLimit(conn *Conn, size int) bool {
+	if srv.packetLimitSize <= 0 || size <= srv.packetLimitSize {
+		return true
+	}
+	srv.onPacketOversize(conn, size)
+	if srv.packetLimitPolicy == PacketLimitPolicyClose {
+		conn.Close(ErrPacketOversize)
+	}
+	return false
+}
+
+// onPacketOversize 输出数据包超出大小限制的日志并触发 OnConnectionPacketOversizeEvent 事件
+func (srv *Server) onPacketOversize(conn *Conn, size int) {
+	log.Warn("Server", log.String("State", "PacketOversize"), log.String("ID", conn.GetID()), log.Int("PacketSize", size), log.Int("LimitSize", srv.packetLimitSize), log.String("Policy", srv.packetLimitPolicy.String()))
+	srv.OnConnectionPacketOversizeEvent(conn, size)
+}
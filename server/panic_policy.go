@@ -0,0 +1,107 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/server/internal/dispatcher"
+	"github.com/kercylan98/minotaur/utils/log"
+	"time"
+)
+
+const (
+	// PanicPolicyLog 仅记录日志，消息处理函数发生 panic 后服务器将继续运行，这是默认的处理策略
+	PanicPolicyLog PanicPolicy = iota
+	// PanicPolicyCloseConn 关闭产生该消息的连接，当消息不存在关联的连接时，行为与 PanicPolicyLog 一致
+	PanicPolicyCloseConn
+	// PanicPolicyShutdown 以异常状态关闭服务器，适用于灰度环境等需要快速失败的场景
+	PanicPolicyShutdown
+	// PanicPolicyRestartShunt 重启执行该消息的分流渠道，丢弃该渠道中所有等待处理的消息，避免后续消息基于异常状态继续执行
+	//   - 当消息在系统分发器中执行时，行为与 PanicPolicyLog 一致
+	PanicPolicyRestartShunt
+)
+
+var panicPolicyNames = map[PanicPolicy]string{
+	PanicPolicyLog:          "PanicPolicyLog",
+	PanicPolicyCloseConn:    "PanicPolicyCloseConn",
+	PanicPolicyShutdown:     "PanicPolicyShutdown",
+	PanicPolicyRestartShunt: "PanicPolicyRestartShunt",
+}
+
+// PanicPolicy 消息处理函数发生 panic 时的处理策略
+type PanicPolicy byte
+
+// String 返回处理策略的字符串表示
+func (slf PanicPolicy) String() string {
+	return panicPolicyNames[slf]
+}
+
+// PanicReport 消息处理函数发生 panic 时的报告，将作为 error 传递给 OnMessageErrorEvent 事件，可通过 errors.As 获取
+type PanicReport struct {
+	Err         error       // panic 转换后的错误
+	MessageType MessageType // 发生 panic 的消息类型
+	Message     string      // 发生 panic 的消息描述
+	Conn        string      // 消息关联的连接 ID，不存在关联的连接时为空
+	Shunt       string      // 执行消息的分流渠道名称
+	Policy      PanicPolicy // 适用的处理策略
+	Stack       string      // 发生 panic 时的堆栈
+	Time        time.Time   // 发生 panic 的时间
+}
+
+// Error 返回 panic 转换后的错误信息
+func (slf *PanicReport) Error() string {
+	return slf.Err.Error()
+}
+
+// Unwrap 返回 panic 转换后的错误
+func (slf *PanicReport) Unwrap() error {
+	return slf.Err
+}
+
+// getPanicPolicy 获取特定消息类型的 panic 处理策略
+func (srv *Server) getPanicPolicy(messageType MessageType) PanicPolicy {
+	if policy, exist := srv.panicPolicies[messageType]; exist {
+		return policy
+	}
+	return srv.panicPolicy
+}
+
+// newPanicReport 生成消息执行过程中发生 panic 的报告
+func (srv *Server) newPanicReport(d *dispatcher.Dispatcher[string, *Message], msg *Message, err error, stack string) *PanicReport {
+	report := &PanicReport{
+		Err:         err,
+		MessageType: msg.t,
+		Message:     msg.String(),
+		Policy:      srv.getPanicPolicy(msg.t),
+		Stack:       stack,
+		Time:        time.Now(),
+	}
+	if msg.conn != nil {
+		report.Conn = msg.conn.GetID()
+	}
+	if d != nil {
+		report.Shunt = d.Name()
+	}
+	return report
+}
+
+// onMessagePanic 根据报告中的策略处理消息执行过程中发生的 panic
+//   - 该函数应在日志记录及 OnMessageErrorEvent 之后调用
+func (srv *Server) onMessagePanic(d *dispatcher.Dispatcher[string, *Message], msg *Message, report *PanicReport) {
+	switch report.Policy {
+	case PanicPolicyCloseConn:
+		if msg.conn != nil && !msg.conn.IsClosed() {
+			log.Warn("Server", log.String("PanicPolicy", PanicPolicyCloseConn.String()), log.String("conn", msg.conn.GetID()), log.Err(report.Err))
+			msg.conn.Close(report.Err)
+		}
+	case PanicPolicyRestartShunt:
+		if d == nil || d == srv.dispatcherMgr.GetSystemDispatcher() {
+			return
+		}
+		dropped := d.Restart(func(message *Message) {
+			srv.discardMessage(d, message)
+		})
+		log.Warn("Server", log.String("PanicPolicy", PanicPolicyRestartShunt.String()), log.String("shunt", d.Name()), log.Int("dropped", dropped), log.Err(report.Err))
+	case PanicPolicyShutdown:
+		log.Warn("Server", log.String("PanicPolicy", PanicPolicyShutdown.String()), log.String("MessageType", msg.t.String()), log.Err(report.Err))
+		// 当前正处于消息处理过程中，需要异步关闭以避免等待消息计数归零时产生阻塞
+		go srv.shutdown(report.Err)
+	}
+}
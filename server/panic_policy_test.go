@@ -0,0 +1,116 @@
+package server_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithPanicPolicy_CloseConn(t *testing.T) {
+	var lock sync.Mutex
+	var reports []*server.PanicReport
+	var closed bool
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	// 默认关闭产生 panic 的连接，系统消息仅记录日志
+	srv := server.New(server.NetworkWebsocket,
+		server.WithPanicPolicy(server.PanicPolicyCloseConn),
+		server.WithPanicPolicy(server.PanicPolicyLog, server.MessageTypeSystem),
+	)
+	srv.RegMessageErrorEvent(func(srv *server.Server, message *server.Message, err error) {
+		var report *server.PanicReport
+		if !errors.As(err, &report) {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		lock.Lock()
+		reports = append(reports, report)
+		lock.Unlock()
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		panic("boom")
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		closed = true
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		srv.PushSystemMessage(func() {
+			panic("system")
+		})
+		go func() {
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			_ = conn.WriteMessage(websocket.BinaryMessage, []byte("panic"))
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+			if _, _, err = conn.ReadMessage(); err == nil {
+				t.Error("connection should be closed after panic")
+			}
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if !closed || len(reports) != 2 {
+		t.Fatalf("closed: %v, reports: %d", closed, len(reports))
+	}
+	system, packet := reports[0], reports[1]
+	if system.Error() != "system" || system.MessageType != server.MessageTypeSystem || system.Policy != server.PanicPolicyLog || system.Conn != "" {
+		t.Fatalf("unexpected system panic report: %+v", system)
+	}
+	if packet.Error() != "boom" || packet.MessageType != server.MessageTypePacket || packet.Policy != server.PanicPolicyCloseConn || packet.Conn == "" {
+		t.Fatalf("unexpected packet panic report: %+v", packet)
+	}
+	if len(packet.Stack) == 0 || packet.Time.IsZero() {
+		t.Fatalf("unexpected packet panic report: %+v", packet)
+	}
+}
+
+func TestWithPanicPolicy_Shutdown(t *testing.T) {
+	// PanicPolicyShutdown 将以异常状态关闭服务器并终止进程，因此在子进程中运行服务器
+	if os.Getenv("MINOTAUR_PANIC_POLICY_SHUTDOWN") == "1" {
+		srv := server.New(server.NetworkNone, server.WithPanicPolicy(server.PanicPolicyShutdown))
+		srv.RegStartFinishEvent(func(srv *server.Server) {
+			srv.PushSystemMessage(func() {
+				panic("boom")
+			})
+		})
+		_ = srv.RunNone()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWithPanicPolicy_Shutdown$")
+	cmd.Env = append(os.Environ(), "MINOTAUR_PANIC_POLICY_SHUTDOWN=1")
+	var done = make(chan error, 1)
+	var output []byte
+	go func() {
+		var err error
+		output, err = cmd.CombinedOutput()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || !bytes.Contains(output, []byte(server.PanicPolicyShutdown.String())) {
+			t.Fatalf("server should exit abnormally, err: %v, output: %s", err, output)
+		}
+	case <-time.After(time.Second * 10):
+		_ = cmd.Process.Kill()
+		t.Fatal("server not shutdown after panic")
+	}
+}
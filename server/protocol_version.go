@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/binary"
+	"github.com/kercylan98/minotaur/utils/log"
+)
+
+var protocolPacketIdentifier = []byte{0xDE, 0xAD, 0x7E, 0x50}
+
+const (
+	protocolKindHandshake           byte = iota + 1 // 客户端声明协议版本
+	protocolKindAccepted                            // 协议版本兼容
+	protocolKindRejected                            // 协议版本不兼容，请更新客户端
+	protocolKindCompression                         // 客户端声明支持数据包压缩
+	protocolKindCompressionAccepted                 // 服务器已为连接启用数据包压缩
+)
+
+const (
+	protocolStatePending  int32 = iota // 尚未协商
+	protocolStateAccepted              // 协商成功
+	protocolStateRejected              // 协商失败，等待关闭
+)
+
+// ProtocolReply 服务器对客户端协议版本握手的回复
+type ProtocolReply struct {
+	Accepted bool   // 协议版本是否兼容
+	Version  uint32 // 协商成功时为客户端声明的协议版本
+	Min      uint32 // 协商失败时为服务器支持的最低协议版本
+	Max      uint32 // 协商失败时为服务器支持的最高协议版本
+}
+
+// MarshalProtocolHandshake 将客户端的协议版本序列化为握手数据包，客户端应当在连接建立后首先发送该数据包
+//   - | identifier(4) | kind(1) | version(4) |
+func MarshalProtocolHandshake(version uint32) []byte {
+	var data = make([]byte, 9)
+	copy(data, protocolPacketIdentifier)
+	data[4] = protocolKindHandshake
+	binary.BigEndian.PutUint32(data[5:], version)
+	return data
+}
+
+// UnmarshalProtocolReply 反序列化服务器对协议版本握手的回复，当数据包不是握手回复时 ok 为 false
+//   - 兼容时为 | identifier(4) | kind(1) | version(4) |
+//   - 不兼容时为 | identifier(4) | kind(1) | min(4) | max(4) |
+func UnmarshalProtocolReply(packet []byte) (reply ProtocolReply, ok bool) {
+	if len(packet) < 9 || [4]byte(packet[:4]) != [4]byte(protocolPacketIdentifier) {
+		return reply, false
+	}
+	switch packet[4] {
+	case protocolKindAccepted:
+		return ProtocolReply{Accepted: true, Version: binary.BigEndian.Uint32(packet[5:])}, true
+	case protocolKindRejected:
+		if len(packet) < 13 {
+			return reply, false
+		}
+		return ProtocolReply{Min: binary.BigEndian.Uint32(packet[5:]), Max: binary.BigEndian.Uint32(packet[9:])}, true
+	}
+	return reply, false
+}
+
+// unmarshalProtocolHandshake 反序列化客户端的握手数据包
+func unmarshalProtocolHandshake(packet []byte) (version uint32, ok bool) {
+	if len(packet) != 9 || [4]byte(packet[:4]) != [4]byte(protocolPacketIdentifier) || packet[4] != protocolKindHandshake {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(packet[5:]), true
+}
+
+// GetProtocolVersion 获取连接通过握手协商的协议版本，未通过 WithProtocolVersion 开启协商或尚未协商时返回 0
+func (slf *Conn) GetProtocolVersion() uint32 {
+	return slf.protocolVersion.Load()
+}
+
+// negotiateProtocol 在通过 WithProtocolVersion 开启协商时处理连接的协议版本握手，返回数据包是否应继续被处理
+//   - 连接的首个数据包必须为握手数据包，否则将视为不兼容的客户端
+//   - 回复将使用与握手数据包相同的 websocket 消息类型
+//   - 不兼容时将回复服务器支持的版本范围，并在回复发送后以 ErrProtocolVersionIncompatible 关闭连接
+func (srv *Server) negotiateProtocol(conn *Conn, wst int, packet []byte) bool {
+	if srv.protocolVersionMax == 0 || conn.isVirtual() {
+		return true
+	}
+	switch conn.protocolState.Load() {
+	case protocolStateAccepted:
+		return true
+	case protocolStateRejected:
+		return false
+	}
+
+	version, ok := unmarshalProtocolHandshake(packet)
+	if ok && version >= srv.protocolVersionMin && version <= srv.protocolVersionMax {
+		conn.protocolVersion.Store(version)
+		conn.protocolState.Store(protocolStateAccepted)
+		var reply = make([]byte, 9)
+		copy(reply, protocolPacketIdentifier)
+		reply[4] = protocolKindAccepted
+		binary.BigEndian.PutUint32(reply[5:], version)
+		(&Conn{wst: wst, connection: conn.connection}).Write(reply)
+		return false
+	}
+
+	conn.protocolState.Store(protocolStateRejected)
+	log.Warn("Server", log.String("State", "ProtocolVersionIncompatible"), log.String("ID", conn.GetID()), log.Uint32("Version", version), log.Bool("Handshake", ok))
+	var reply = make([]byte, 13)
+	copy(reply, protocolPacketIdentifier)
+	reply[4] = protocolKindRejected
+	binary.BigEndian.PutUint32(reply[5:], srv.protocolVersionMin)
+	binary.BigEndian.PutUint32(reply[9:], srv.protocolVersionMax)
+	(&Conn{wst: wst, connection: conn.connection}).Write(reply, func(err error) {
+		go conn.Close(ErrProtocolVersionIncompatible)
+	})
+	return false
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/utils/log"
+	"time"
+)
+
+// rollingPollInterval 滚动停止时检查在线连接数量的间隔
+const rollingPollInterval = 100 * time.Millisecond
+
+// RollingShutdownStage 滚动停止的阶段
+type RollingShutdownStage int
+
+const (
+	RollingShutdownStageDraining         RollingShutdownStage = iota + 1 // 标记为排空中，应当在该阶段通知注册中心，使网关停止向该节点路由新的客户端
+	RollingShutdownStageWaitRouting                                      // 等待网关停止路由新的客户端
+	RollingShutdownStageDrainConnections                                 // 等待在线连接断开
+	RollingShutdownStageExit                                             // 停止服务器
+)
+
+func (s RollingShutdownStage) String() string {
+	switch s {
+	case RollingShutdownStageDraining:
+		return "draining"
+	case RollingShutdownStageWaitRouting:
+		return "wait-routing"
+	case RollingShutdownStageDrainConnections:
+		return "drain-connections"
+	case RollingShutdownStageExit:
+		return "exit"
+	}
+	return "unknown"
+}
+
+// IsDraining 检查服务器是否正在通过 RollingShutdown 滚动停止
+func (srv *Server) IsDraining() bool {
+	return srv.draining.Load()
+}
+
+// RollingShutdown 滚动停止服务器，适用于在部署脚本中以一次调用完成无损的滚动更新，该函数将阻塞至服务器开始停止
+//   - RollingShutdownStageDraining：将服务器标记为排空中，通过 cluster.Cluster.Attach 绑定的集群将在注册中心中将该节点标记为排空中
+//   - RollingShutdownStageWaitRouting：等待持续 WithRollingQuietPeriod 时长没有新的非机器人连接打开，视为网关已停止路由新的客户端
+//   - RollingShutdownStageDrainConnections：等待所有非机器人连接断开
+//   - RollingShutdownStageExit：通过 Shutdown 停止服务器
+//
+// 进入每个阶段时都将触发 OnRollingShutdownEvent 事件。当 ctx 在排空完成前结束时，将直接进入 RollingShutdownStageExit 阶段并返回 ctx 的错误，剩余的连接将随服务器停止而断开
+//   - 重复调用将返回 ErrRollingShutdownInProgress
+func (srv *Server) RollingShutdown(ctx context.Context) error {
+	if !srv.draining.CompareAndSwap(false, true) {
+		return ErrRollingShutdownInProgress
+	}
+	start := time.Now().UnixNano()
+	srv.enterRollingStage(RollingShutdownStageDraining)
+
+	srv.enterRollingStage(RollingShutdownStageWaitRouting)
+	err := srv.waitRollingQuiet(ctx, start)
+	if err == nil {
+		srv.enterRollingStage(RollingShutdownStageDrainConnections)
+		err = srv.waitRollingDrain(ctx)
+	}
+	if err != nil {
+		log.Warn("Server", log.String("RollingShutdown", "forced"), log.Int("online", srv.GetOnlineUserCount()), log.Err(err))
+	}
+
+	srv.enterRollingStage(RollingShutdownStageExit)
+	srv.Shutdown()
+	return err
+}
+
+// enterRollingStage 进入滚动停止的特定阶段
+func (srv *Server) enterRollingStage(stage RollingShutdownStage) {
+	log.Info("Server", log.String("RollingShutdown", stage.String()), log.String("listen", srv.addr))
+	srv.OnRollingShutdownEvent(stage)
+}
+
+// waitRollingQuiet 等待自 start 及最近一次连接打开起持续 rollingQuietPeriod 时长没有新的连接打开
+func (srv *Server) waitRollingQuiet(ctx context.Context, start int64) error {
+	for {
+		since := srv.lastConnOpened.Load()
+		if since < start {
+			since = start
+		}
+		idle := time.Duration(time.Now().UnixNano() - since)
+		if idle >= srv.rollingQuietPeriod {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(srv.rollingQuietPeriod - idle):
+		}
+	}
+}
+
+// waitRollingDrain 等待所有非机器人连接断开
+func (srv *Server) waitRollingDrain(ctx context.Context) error {
+	ticker := time.NewTicker(rollingPollInterval)
+	defer ticker.Stop()
+	for srv.GetOnlineUserCount() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
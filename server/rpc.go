@@ -0,0 +1,334 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRPCTimeout 默认的 RPC 调用超时时间
+const DefaultRPCTimeout = 5 * time.Second
+
+var (
+	ErrRPCTimeout       = errors.New("rpc: call timeout")
+	ErrRPCRouteNotFound = errors.New("rpc: route not found")
+	ErrRPCPacketInvalid = errors.New("rpc: invalid packet")
+	ErrRPCWriteFailed   = errors.New("rpc: write failed")
+	ErrRPCDisabled      = errors.New("rpc: disabled, use WithRPC to enable")
+)
+
+var rpcPacketIdentifier = []byte{0xDE, 0xAD, 0xCA, 0x11}
+
+const (
+	rpcKindRequest  byte = iota + 1 // 请求
+	rpcKindResponse                 // 正常响应
+	rpcKindError                    // 错误响应
+)
+
+const rpcHeaderSize = 4 + 1 + 8 // identifier(4) | kind(1) | id(8)
+
+// RPCError 远程处理函数返回的错误
+type RPCError struct {
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return "rpc: remote error: " + e.Message
+}
+
+// RPCPacket 经过解析的 RPC 数据包
+type RPCPacket struct {
+	ID       uint64 // 关联请求与响应的序号
+	Route    string // 请求的路由，仅请求有效
+	Payload  []byte // 请求或响应的数据
+	Err      string // 远程处理函数返回的错误信息，仅响应有效
+	Response bool   // 是否为响应
+}
+
+// IsRPCPacket 检查数据包是否为 RPC 数据包
+func IsRPCPacket(packet []byte) bool {
+	return len(packet) >= rpcHeaderSize && [4]byte(packet[:4]) == [4]byte(rpcPacketIdentifier)
+}
+
+// MarshalRPCRequest 将 RPC 请求序列化为数据包
+//   - | identifier(4) | kind(1) | id(8) | routeLen(2) | route(routeLen) | payload |
+func MarshalRPCRequest(id uint64, route string, payload []byte) []byte {
+	var data = make([]byte, rpcHeaderSize+2+len(route)+len(payload))
+	copy(data, rpcPacketIdentifier)
+	data[4] = rpcKindRequest
+	binary.BigEndian.PutUint64(data[5:], id)
+	binary.BigEndian.PutUint16(data[rpcHeaderSize:], uint16(len(route)))
+	copy(data[rpcHeaderSize+2:], route)
+	copy(data[rpcHeaderSize+2+len(route):], payload)
+	return data
+}
+
+// MarshalRPCResponse 将 RPC 响应序列化为数据包，当 err 不为空时将仅携带错误信息
+//   - | identifier(4) | kind(1) | id(8) | payload or error |
+func MarshalRPCResponse(id uint64, payload []byte, err error) []byte {
+	var kind = rpcKindResponse
+	if err != nil {
+		kind = rpcKindError
+		payload = []byte(err.Error())
+	}
+	var data = make([]byte, rpcHeaderSize+len(payload))
+	copy(data, rpcPacketIdentifier)
+	data[4] = kind
+	binary.BigEndian.PutUint64(data[5:], id)
+	copy(data[rpcHeaderSize:], payload)
+	return data
+}
+
+// UnmarshalRPCPacket 反序列化 RPC 数据包，返回的 Payload 将引用 packet 的底层数组
+func UnmarshalRPCPacket(packet []byte) (*RPCPacket, error) {
+	if !IsRPCPacket(packet) {
+		return nil, ErrRPCPacketInvalid
+	}
+	var p = &RPCPacket{ID: binary.BigEndian.Uint64(packet[5:])}
+	var body = packet[rpcHeaderSize:]
+	switch packet[4] {
+	case rpcKindRequest:
+		if len(body) < 2 {
+			return nil, ErrRPCPacketInvalid
+		}
+		var routeLen = int(binary.BigEndian.Uint16(body))
+		if len(body) < 2+routeLen {
+			return nil, ErrRPCPacketInvalid
+		}
+		p.Route = string(body[2 : 2+routeLen])
+		p.Payload = body[2+routeLen:]
+	case rpcKindResponse:
+		p.Response = true
+		p.Payload = body
+	case rpcKindError:
+		p.Response = true
+		p.Err = string(body)
+	default:
+		return nil, ErrRPCPacketInvalid
+	}
+	return p, nil
+}
+
+// RPCHandler RPC 处理函数，caller 为发起调用的一方，例如服务器中的 *Conn 或客户端中的 *client.Client
+type RPCHandler[C any] func(caller C, payload []byte) ([]byte, error)
+
+// NewRPCCaller 创建一个 RPC 调用器
+func NewRPCCaller() *RPCCaller {
+	return &RPCCaller{pending: make(map[rpcPendingKey]chan *RPCPacket)}
+}
+
+// rpcPendingKey 等待中的调用的键，响应需要来自被调用的一方才能被交付
+type rpcPendingKey struct {
+	peer any    // 被调用的一方
+	id   uint64 // 关联序号
+}
+
+// RPCCaller RPC 调用器，负责生成关联序号并等待对应的响应，可在服务器与客户端之间复用
+type RPCCaller struct {
+	seq     atomic.Uint64
+	lock    sync.Mutex
+	pending map[rpcPendingKey]chan *RPCPacket
+}
+
+// Call 通过 write 发送 RPC 请求，并等待通过 Resolve 交付的响应
+//   - write 返回错误时将立即结束调用，当 ctx 结束时将返回 ErrRPCTimeout 或 ctx 的错误
+//   - 远程处理函数返回的错误将以 *RPCError 的形式返回
+//   - 适用于仅存在一个被调用方的情况，例如客户端，存在多个被调用方时应使用 CallPeer
+func (slf *RPCCaller) Call(ctx context.Context, write func(packet []byte) error, route string, payload []byte) ([]byte, error) {
+	return slf.CallPeer(ctx, nil, write, route, payload)
+}
+
+// CallPeer 与 Call 相同，但仅接受通过 ResolvePeer 交付的来自 peer 的响应，peer 需要是可比较的
+func (slf *RPCCaller) CallPeer(ctx context.Context, peer any, write func(packet []byte) error, route string, payload []byte) ([]byte, error) {
+	var id = slf.seq.Add(1)
+	var key = rpcPendingKey{peer: peer, id: id}
+	var ch = make(chan *RPCPacket, 1)
+	slf.lock.Lock()
+	slf.pending[key] = ch
+	slf.lock.Unlock()
+	defer func() {
+		slf.lock.Lock()
+		delete(slf.pending, key)
+		slf.lock.Unlock()
+	}()
+
+	if err := write(MarshalRPCRequest(id, route, payload)); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrRPCTimeout
+		}
+		return nil, ctx.Err()
+	case resp := <-ch:
+		if resp.Err != "" {
+			return nil, &RPCError{Message: resp.Err}
+		}
+		return resp.Payload, nil
+	}
+}
+
+// Resolve 交付通过 Call 发起的调用的 RPC 响应，当响应没有对应的等待中的调用时（例如已超时）将返回 false
+func (slf *RPCCaller) Resolve(packet *RPCPacket) bool {
+	return slf.ResolvePeer(nil, packet)
+}
+
+// ResolvePeer 交付来自 peer 的 RPC 响应，仅向 peer 发起的等待中的调用会被交付，否则将返回 false
+func (slf *RPCCaller) ResolvePeer(peer any, packet *RPCPacket) bool {
+	var key = rpcPendingKey{peer: peer, id: packet.ID}
+	slf.lock.Lock()
+	ch, exist := slf.pending[key]
+	delete(slf.pending, key)
+	slf.lock.Unlock()
+	if exist {
+		ch <- packet
+	}
+	return exist
+}
+
+// Pending 获取等待响应中的调用数量
+func (slf *RPCCaller) Pending() int {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	return len(slf.pending)
+}
+
+// NewRPCRouter 创建一个 RPC 路由器
+func NewRPCRouter[C any]() *RPCRouter[C] {
+	return &RPCRouter[C]{handlers: make(map[string]RPCHandler[C])}
+}
+
+// RPCRouter RPC 路由器，负责将请求分发至对应路由的处理函数，可在服务器与客户端之间复用
+type RPCRouter[C any] struct {
+	lock     sync.RWMutex
+	handlers map[string]RPCHandler[C]
+}
+
+// Handle 注册特定路由的处理函数，重复注册将覆盖之前的处理函数
+func (slf *RPCRouter[C]) Handle(route string, handler RPCHandler[C]) {
+	slf.lock.Lock()
+	slf.handlers[route] = handler
+	slf.lock.Unlock()
+}
+
+// Serve 处理 RPC 请求并返回序列化后的响应数据包
+//   - 未注册的路由将返回 ErrRPCRouteNotFound，处理函数发生的 panic 将作为错误返回
+func (slf *RPCRouter[C]) Serve(caller C, request *RPCPacket) (response []byte) {
+	slf.lock.RLock()
+	handler, exist := slf.handlers[request.Route]
+	slf.lock.RUnlock()
+	if !exist {
+		return MarshalRPCResponse(request.ID, nil, fmt.Errorf("%w: %s", ErrRPCRouteNotFound, request.Route))
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error("RPC", log.String("route", request.Route), log.Any("error", err))
+			response = MarshalRPCResponse(request.ID, nil, fmt.Errorf("%v", err))
+		}
+	}()
+	payload, err := handler(caller, request.Payload)
+	return MarshalRPCResponse(request.ID, payload, err)
+}
+
+// RegRPCHandler 注册特定路由的 RPC 处理函数，需要通过 WithRPC 开启 RPC
+//   - 处理函数将在连接数据包所在的分发器中执行，并在 ConnectionPacketPreprocessEvent 之后、ConnectionReceivePacketEvent 之前被拦截
+//   - RPC 请求不会触发 ConnectionReceivePacketEvent 事件
+func (srv *Server) RegRPCHandler(route string, handler RPCHandler[*Conn]) {
+	srv.rpcRouter.Handle(route, handler)
+}
+
+// Call 向连接发起 RPC 调用并等待响应，当 timeout <= 0 时将使用 DefaultRPCTimeout
+//   - 需要通过 WithRPC 开启 RPC，否则将返回 ErrRPCDisabled
+//   - 连接可以是直连的客户端或服务器，也可以是通过网关接入的连接
+//   - 响应将在进入消息队列前被拦截，因此允许在处理函数中发起调用，但调用期间将阻塞所在的分发器
+//   - 仅接受来自该连接的响应
+func (srv *Server) Call(conn *Conn, route string, payload []byte, timeout time.Duration) ([]byte, error) {
+	if !srv.rpc {
+		return nil, ErrRPCDisabled
+	}
+	if timeout <= 0 {
+		timeout = DefaultRPCTimeout
+	}
+	ctx, cancel := context.WithTimeout(srv.ctx, timeout)
+	defer cancel()
+	return srv.rpcCaller.CallPeer(ctx, conn.connection, func(packet []byte) error {
+		if conn.IsClosed() {
+			return ErrRPCWriteFailed
+		}
+		conn.Write(packet)
+		return nil
+	}, route, payload)
+}
+
+// RegRPC 注册特定路由的 RPC 处理函数，请求及响应将通过 JSON 进行序列化
+func RegRPC[Req, Resp any](srv *Server, route string, handler func(conn *Conn, req Req) (Resp, error)) {
+	srv.RegRPCHandler(route, func(conn *Conn, payload []byte) ([]byte, error) {
+		var req Req
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return nil, err
+		}
+		resp, err := handler(conn, req)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(resp)
+	})
+}
+
+// CallRPC 向连接发起 RPC 调用，请求及响应将通过 JSON 进行序列化
+func CallRPC[Req, Resp any](srv *Server, conn *Conn, route string, req Req, timeout time.Duration) (resp Resp, err error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	data, err := srv.Call(conn, route, payload, timeout)
+	if err != nil {
+		return resp, err
+	}
+	err = json.Unmarshal(data, &resp)
+	return resp, err
+}
+
+// resolveRPCResponse 尝试将来自连接的数据包作为 RPC 响应交付给向该连接发起的等待中的调用
+func (srv *Server) resolveRPCResponse(conn *Conn, packet []byte) bool {
+	if !srv.rpc || !IsRPCPacket(packet) {
+		return false
+	}
+	p, err := UnmarshalRPCPacket(packet)
+	if err != nil || !p.Response {
+		return false
+	}
+	srv.resolveRPC(conn, p)
+	return true
+}
+
+// resolveRPC 交付来自连接的 RPC 响应，没有对应的调用或调用并非发往该连接时响应将被丢弃
+func (srv *Server) resolveRPC(conn *Conn, p *RPCPacket) {
+	if !srv.rpcCaller.ResolvePeer(conn.connection, p) {
+		log.Warn("RPC", log.String("state", "discard"), log.String("conn", conn.GetID()), log.Uint64("id", p.ID))
+	}
+}
+
+// serveRPCRequest 尝试将数据包作为 RPC 请求进行处理
+func (srv *Server) serveRPCRequest(conn *Conn, packet []byte) bool {
+	if !srv.rpc || !IsRPCPacket(packet) {
+		return false
+	}
+	p, err := UnmarshalRPCPacket(packet)
+	if err != nil {
+		return false
+	}
+	if p.Response {
+		srv.resolveRPC(conn, p)
+		return true
+	}
+	conn.Write(srv.rpcRouter.Serve(conn, p))
+	return true
+}
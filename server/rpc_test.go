@@ -0,0 +1,56 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestRPCCaller_ResolvePeer(t *testing.T) {
+	type peer struct{ name string }
+	var callee, other = &peer{name: "callee"}, &peer{name: "other"}
+	var caller = server.NewRPCCaller()
+	var requests = make(chan *server.RPCPacket, 1)
+	var result = make(chan []byte, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err := caller.CallPeer(ctx, callee, func(packet []byte) error {
+			p, err := server.UnmarshalRPCPacket(packet)
+			if err != nil {
+				return err
+			}
+			requests <- p
+			return nil
+		}, "route", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		result <- resp
+	}()
+
+	request := <-requests
+	response, err := server.UnmarshalRPCPacket(server.MarshalRPCResponse(request.ID, []byte("forged"), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caller.ResolvePeer(other, response) || caller.Resolve(response) {
+		t.Fatal("response from other peer should be rejected")
+	}
+	response, _ = server.UnmarshalRPCPacket(server.MarshalRPCResponse(request.ID, []byte("ok"), nil))
+	if !caller.ResolvePeer(callee, response) {
+		t.Fatal("response from callee should be resolved")
+	}
+	if resp := <-result; string(resp) != "ok" {
+		t.Fatalf("unexpected response: %s", resp)
+	}
+}
+
+func TestWithRPC(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	if _, err := srv.Call(nil, "route", nil, time.Second); !errors.Is(err, server.ErrRPCDisabled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
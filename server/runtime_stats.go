@@ -0,0 +1,36 @@
+package server
+
+// RuntimeStats 服务器运行时的消息对象池及异步消息协程池的统计信息，可用于诊断池大小是否合理
+type RuntimeStats struct {
+	MessageInUse      int64 `json:"message_in_use"`      // 已从消息对象池中取出且尚未放回的消息对象数量
+	MessagePoolMisses int64 `json:"message_pool_misses"` // 消息对象池中无可用对象而新生成对象的次数
+	AsyncCapacity     int   `json:"async_capacity"`      // 异步消息协程池的容量，未启用协程池时为 0
+	AsyncRunning      int   `json:"async_running"`       // 异步消息协程池中存活的工作协程数量，空闲的工作协程在过期清理前同样被计算在内
+	AsyncWaiting      int   `json:"async_waiting"`       // 由于异步消息协程池已饱和而阻塞等待提交的任务数量
+}
+
+// RuntimeStats 获取服务器运行时的消息对象池及异步消息协程池的统计信息
+//   - 当 MessageInUse 持续增长时通常意味着消息积压或存在未被回收的消息
+//   - 当 AsyncWaiting 持续大于 0 时应当考虑通过 WithAsyncPoolSize 调整协程池大小，亦可通过 RegAsyncPoolSaturatedEvent 观察饱和情况
+func (srv *Server) RuntimeStats() RuntimeStats {
+	var stats RuntimeStats
+	if srv.messagePool != nil {
+		pool := srv.messagePool.GetStats()
+		stats.MessageInUse = pool.Gets - pool.Releases
+		stats.MessagePoolMisses = pool.Misses
+	}
+	if ants := srv.ants; ants != nil {
+		stats.AsyncCapacity = ants.Cap()
+		stats.AsyncRunning = ants.Running()
+		stats.AsyncWaiting = ants.Waiting()
+	}
+	return stats
+}
+
+// checkAsyncPoolSaturated 在提交异步消息前检查协程池是否已饱和，饱和时提交将被阻塞
+func (srv *Server) checkAsyncPoolSaturated(message *Message) {
+	if srv.asyncPoolSaturatedEventHandlers.Len() == 0 || srv.ants.Free() != 0 {
+		return
+	}
+	srv.OnAsyncPoolSaturatedEvent(message, srv.RuntimeStats())
+}
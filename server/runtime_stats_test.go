@@ -0,0 +1,41 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestServer_RuntimeStats(t *testing.T) {
+	var saturated server.RuntimeStats
+	var idle server.RuntimeStats
+	release := make(chan struct{})
+	srv := server.New(server.NetworkNone, server.WithAsyncPoolSize(1))
+	srv.RegAsyncPoolSaturatedEvent(func(srv *server.Server, message *server.Message, stats server.RuntimeStats) {
+		saturated = stats
+		close(release)
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		srv.PushAsyncMessage(func() error {
+			<-release
+			return nil
+		}, nil)
+		srv.PushAsyncMessage(func() error {
+			return nil
+		}, nil)
+		go func() {
+			defer srv.Shutdown()
+			time.Sleep(time.Millisecond * 200)
+			idle = srv.RuntimeStats()
+		}()
+	})
+	if err := srv.Run(""); err != nil {
+		t.Fatal(err)
+	}
+	if saturated.AsyncCapacity != 1 || saturated.AsyncRunning != 1 || saturated.MessageInUse <= 0 {
+		t.Fatalf("unexpected saturated stats: %+v", saturated)
+	}
+	if idle.AsyncWaiting != 0 || idle.MessageInUse != 0 {
+		t.Fatalf("unexpected idle stats: %+v", idle)
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/kercylan98/minotaur/server/internal/dispatcher"
 	"github.com/kercylan98/minotaur/server/internal/logger"
+	"github.com/kercylan98/minotaur/server/writeloop"
 	"github.com/kercylan98/minotaur/utils/collection"
 	"github.com/kercylan98/minotaur/utils/hub"
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/network"
+	"github.com/kercylan98/minotaur/utils/routines"
 	"github.com/kercylan98/minotaur/utils/str"
 	"github.com/kercylan98/minotaur/utils/super"
 	"github.com/kercylan98/minotaur/utils/timer"
@@ -28,6 +30,8 @@ import (
 	"time"
 )
 
+var serverSeq atomic.Int64 // 服务器序号，用于区分各个服务器的协程组
+
 // New 根据特定网络类型创建一个服务器
 func New(network Network, options ...Option) *Server {
 	network.check()
@@ -38,12 +42,21 @@ func New(network Network, options ...Option) *Server {
 			dispatcherBufferSize:    DefaultDispatcherBufferSize,
 			lowMessageDuration:      DefaultLowMessageDuration,
 			asyncLowMessageDuration: DefaultAsyncLowMessageDuration,
+			rollingQuietPeriod:      DefaultRollingQuietPeriod,
+			udpSessionTimeout:       DefaultUDPSessionTimeout,
 		},
 		connMgr:      &connMgr{},
 		option:       &option{},
 		network:      network,
 		closeChannel: make(chan struct{}, 1),
 		systemSignal: make(chan os.Signal, 1),
+		lifeExtended: make(chan struct{}, 1),
+		rpcCaller:    NewRPCCaller(),
+		rpcRouter:    NewRPCRouter[*Conn](),
+
+		uniqueMessagePending: make(map[uniqueMessageKey]*Message),
+		identities:           make(map[string][]*Conn),
+		writeLoopGroup:       fmt.Sprintf("%s#%d", writeloop.RoutineGroup, serverSeq.Add(1)),
 	}
 	server.ctx, server.cancel = context.WithCancel(context.Background())
 	server.event = newEvent(server)
@@ -75,8 +88,9 @@ type Server struct {
 	dispatcherMgr            *dispatcher.Manager[string, *Message] // 消息分发器管理器
 	ginServer                *gin.Engine                           // HTTP模式下的路由器
 	httpServer               *http.Server                          // HTTP模式下的服务器
+	adminServer              *http.Server                          // 运维管理接口服务器
 	grpcServer               *grpc.Server                          // GRPC模式下的服务器
-	gServer                  *gNet                                 // TCP或UDP模式下的服务器
+	gnetAddrs                []string                              // TCP、UDP或Unix模式下通过 gnet 监听的所有地址
 	multiple                 *MultipleServer                       // 多服务器模式下的服务器
 	ants                     *ants.Pool                            // 协程池
 	messagePool              *hub.ObjectPool[*Message]             // 消息池
@@ -86,12 +100,56 @@ type Server struct {
 	closeChannel             chan struct{}                         // 关闭信号
 	multipleRuntimeErrorChan chan error                            // 多服务器模式下的运行时错误
 	data                     map[string]any                        // 服务器全局数据
-
-	messageCounter atomic.Int64 // 消息计数器
-	addr           string       // 侦听地址
-	network        Network      // 网络类型
-	closed         uint32       // 服务器是否已关闭
-	services       []func()     // 服务
+	messageMiddlewares       []MessageMiddleware                   // 消息中间件
+	messageHandler           MessageHandler                        // 经过消息中间件包装后的消息处理函数
+	crons                    map[string]*CronSchedule              // cron 调度
+	cronLock                 sync.Mutex                            // cron 调度锁
+	shuntChannelConfigs      map[string]shuntChannelConfig         // 分流通道配置
+	shuntChannelConfigLock   sync.RWMutex                          // 分流通道配置锁
+	startBeforeOnce          sync.Once                             // 确保多个监听地址仅触发一次启动前事件
+	rpcCaller                *RPCCaller                            // RPC 调用器
+	rpcRouter                *RPCRouter[*Conn]                     // RPC 路由器
+	offlineConns             sync.Map                              // 通过 NewOfflineConn 创建且尚未关闭的离线连接
+	udpSessions              []*udpSessions                        // UDP 模式下各监听地址的虚拟会话表
+	uniqueMessagePending     map[uniqueMessageKey]*Message         // 等待同名唯一消息执行结束的唯一消息
+	uniqueMessageLock        sync.Mutex                            // 唯一消息暂存锁
+	identities               map[string][]*Conn                    // 通过 BindIdentity 绑定身份标识的连接
+	identityLock             sync.Mutex                            // 身份标识绑定锁
+	shutdownHooks            map[ShutdownStage][]ShutdownHook      // 服务器停止时各阶段的钩子函数
+	shutdownHookLock         sync.Mutex                            // 停止钩子函数锁
+	handoverListeners        []handoverListener                    // 可交接给新进程的监听器
+	handoverLock             sync.Mutex                            // 交接监听器锁
+	writeLoopGroup           string                                // 连接写循环协程所属的协程组名称
+
+	messageCounter  atomic.Int64  // 消息计数器
+	queuedBytes     atomic.Int64  // 等待处理的消息估算占用的内存字节数
+	shedding        atomic.Bool   // 是否正在因内存超限丢弃数据包消息
+	sheddingDropped atomic.Int64  // 因内存超限丢弃的数据包消息数量
+	draining        atomic.Bool   // 是否正在滚动停止
+	handingOver     atomic.Bool   // 是否正在通过 HandoverAndRestart 交接
+	handedOver      atomic.Bool   // 是否已将监听器交接给新进程
+	lastConnOpened  atomic.Int64  // 最近一次非机器人连接打开的时间戳（纳秒）
+	connStats       connStats     // 所有连接的流量统计
+	lifeDeadline    atomic.Int64  // 通过 WithLimitLife 限制的生命周期结束的时间戳（纳秒）
+	lifeExtended    chan struct{} // 生命周期被延长的信号
+	addr            string        // 侦听地址
+	network         Network       // 网络类型
+	closed          uint32        // 服务器是否已关闭
+	services        []func()      // 服务
+}
+
+// GetLowMessageDurations 返回服务器当前配置的同步消息及异步消息的慢消息时长
+func (srv *Server) GetLowMessageDurations() (sync, async time.Duration) {
+	return srv.lowMessageDuration, srv.asyncLowMessageDuration
+}
+
+// GetMessagePoolStats 获取服务器消息对象池的统计信息，可用于观察高频消息场景下对象的复用情况
+//   - 服务器未运行时将返回空的统计信息
+func (srv *Server) GetMessagePoolStats() hub.ObjectPoolStats {
+	if srv.messagePool == nil {
+		return hub.ObjectPoolStats{}
+	}
+	return srv.messagePool.GetStats()
 }
 
 // LoadData 加载绑定的服务器数据
@@ -132,7 +190,7 @@ func (srv *Server) preCheckAndAdaptation(addr string) (startState <-chan error,
 		return nil, ErrConstructed
 	}
 	srv.addr = addr
-	if srv.multiple == nil && srv.network != NetworkKcp {
+	if srv.multiple == nil && srv.network != NetworkKcp && !srv.isAdditionalListen(NetworkKcp) {
 		kcp.SystemTimedSched.Close()
 	}
 
@@ -140,6 +198,36 @@ func (srv *Server) preCheckAndAdaptation(addr string) (startState <-chan error,
 	return srv.network.adaptation(srv), nil
 }
 
+// additionalAdaptation 在主监听地址启动完成后依次启动额外的监听地址
+func (srv *Server) additionalAdaptation() error {
+	for _, listen := range srv.additionalListens {
+		if err := <-listen.network.additionalAdaptation(srv, listen.addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasNetwork 检查服务器的网络类型或通过 WithAdditionalListen 指定的额外监听地址的网络类型是否为 networks 之一
+func (srv *Server) hasNetwork(networks ...Network) bool {
+	for _, network := range networks {
+		if srv.network == network || srv.isAdditionalListen(network) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdditionalListen 检查特定网络模式是否存在通过 WithAdditionalListen 指定的额外监听地址，当指定了 addr 时将同时匹配监听地址
+func (srv *Server) isAdditionalListen(network Network, addr ...string) bool {
+	for _, listen := range srv.additionalListens {
+		if listen.network == network && (len(addr) == 0 || listen.addr == addr[0]) {
+			return true
+		}
+	}
+	return false
+}
+
 // Run 使用特定地址运行服务器
 //   - server.NetworkTcp (addr:":8888")
 //   - server.NetworkTcp4 (addr:":8888")
@@ -165,7 +253,15 @@ func (srv *Server) Run(addr string) (err error) {
 	if err = <-startState; err != nil {
 		return err
 	}
+	if err = srv.additionalAdaptation(); err != nil {
+		return err
+	}
+	if err = srv.startAdminServer(); err != nil {
+		return err
+	}
 	srv.OnStartFinishEvent()
+	srv.onGRPCServing()
+	notifyHandoverReady()
 
 	if srv.multiple == nil {
 		signal.Notify(srv.systemSignal, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT)
@@ -198,6 +294,12 @@ func (srv *Server) Context() context.Context {
 	return srv.ctx
 }
 
+// GetWriteLoopCount 获取服务器中正在运行的连接写循环协程数量，通过 WithShutdownCloseConn 开启后，服务器停止时将关闭所有连接并等待这些写循环协程结束
+//   - 机器人连接的写循环跟随 Bot 的生命周期，不包含在内
+func (srv *Server) GetWriteLoopCount() int64 {
+	return routines.Named(srv.writeLoopGroup).Count()
+}
+
 // TimeoutContext 获取服务器超时上下文，context.WithTimeout 的简写
 func (srv *Server) TimeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(srv.ctx, timeout)
@@ -224,8 +326,14 @@ func (srv *Server) shutdown(err error) {
 	if err != nil {
 		log.Error("Server", log.String("state", "shutdown"), log.Err(err))
 	}
+	srv.onGRPCStopping()
+	ctx, cancel := srv.shutdownContext()
+	defer cancel()
+	srv.enterShutdownStage(ctx, ShutdownStageStopAccept)
+	srv.enterShutdownStage(ctx, ShutdownStageDrain)
 
 	var infoCount int
+drain:
 	for srv.messageCounter.Load() > 0 {
 		if infoCount%10 == 0 || infoCount == 0 {
 			log.Info("Server",
@@ -235,41 +343,63 @@ func (srv *Server) shutdown(err error) {
 				log.String("state", "waiting"),
 				log.Int64("message", srv.messageCounter.Load()))
 		}
-		time.Sleep(time.Second)
+		select {
+		case <-ctx.Done():
+			log.Warn("Server", log.String("listen", srv.addr), log.String("action", "shutdown"),
+				log.String("state", "timeout"), log.Int64("message", srv.messageCounter.Load()))
+			break drain
+		case <-time.After(time.Second):
+		}
 		infoCount++
 	}
-	dispatcherMgrStopSignal := make(chan struct{})
-	go func(srv *Server, c <-chan struct{}) {
-		var infoCount int
-		for {
-			select {
-			case <-c:
-				return
-			case <-time.After(time.Second):
-				if infoCount%10 == 0 || infoCount == 0 {
-					log.Info("Server",
-						log.Any("network", srv.network),
-						log.String("listen", srv.addr),
-						log.String("action", "shutdown"),
-						log.String("state", "waiting"),
-						log.Int64("dispatcher", srv.dispatcherMgr.GetDispatcherNum()))
-				}
-				infoCount++
-			}
+	srv.enterShutdownStage(ctx, ShutdownStageBeforeClose)
+	srv.offlineConns.Range(func(key, value any) bool {
+		value.(*Conn).Close()
+		return true
+	})
+	if srv.shutdownCloseConn {
+		srv.RangeOnline(func(id string, conn *Conn) bool {
+			conn.Close()
+			return true
+		})
+	}
+	for _, sessions := range srv.udpSessions {
+		sessions.close()
+	}
+	dispatcherMgrDone := make(chan struct{})
+	go func(srv *Server, done chan<- struct{}) {
+		srv.dispatcherMgr.Wait()
+		close(done)
+	}(srv, dispatcherMgrDone)
+	if !waitShutdown(ctx, dispatcherMgrDone, func() {
+		log.Info("Server",
+			log.Any("network", srv.network),
+			log.String("listen", srv.addr),
+			log.String("action", "shutdown"),
+			log.String("state", "waiting"),
+			log.Int64("dispatcher", srv.dispatcherMgr.GetDispatcherNum()))
+	}) {
+		log.Warn("Server", log.String("listen", srv.addr), log.String("action", "shutdown"),
+			log.String("state", "timeout"), log.Int64("dispatcher", srv.dispatcherMgr.GetDispatcherNum()))
+	}
+	if srv.shutdownCloseConn {
+		if stopErr := routines.Named(srv.writeLoopGroup).Stop(ctx); stopErr != nil {
+			log.Warn("Server", log.String("listen", srv.addr), log.String("action", "shutdown"),
+				log.String("state", "timeout"), log.Err(stopErr))
 		}
-	}(srv, dispatcherMgrStopSignal)
-	srv.dispatcherMgr.Wait()
-	close(dispatcherMgrStopSignal)
+	}
+	srv.stopAudit()
 	if srv.multiple == nil {
 		srv.OnStopEvent()
 	}
 	defer super.TryWriteChannel(srv.multipleRuntimeErrorChan, err)
 	srv.cancel()
-	if srv.gServer != nil {
-		if shutdownErr := gnet.Stop(context.Background(), fmt.Sprintf("%s://%s", srv.network, srv.addr)); err != nil {
+	for _, protoAddr := range srv.gnetAddrs {
+		if shutdownErr := gnet.Stop(context.Background(), protoAddr); shutdownErr != nil {
 			log.Error("Server", log.Err(shutdownErr))
 		}
 	}
+	srv.stopAllCron()
 	if srv.tickerPool != nil {
 		srv.tickerPool.Release()
 	}
@@ -289,7 +419,14 @@ func (srv *Server) shutdown(err error) {
 		if shutdownErr := srv.httpServer.Shutdown(ctx); shutdownErr != nil {
 			log.Error("Server", log.Err(shutdownErr))
 		}
+		if srv.httpEngine != nil {
+			if shutdownErr := srv.httpEngine.Shutdown(ctx); shutdownErr != nil {
+				log.Error("Server", log.Err(shutdownErr))
+			}
+		}
 	}
+	srv.stopAdminServer()
+	srv.enterShutdownStage(ctx, ShutdownStageAfterClose)
 
 	if err != nil {
 		if srv.multiple != nil {
@@ -309,12 +446,12 @@ func (srv *Server) shutdown(err error) {
 		}
 	} else {
 		log.Info("Server", log.Any("network", srv.network), log.String("listen", srv.addr),
-			log.String("action", "shutdown"), log.String("state", "normal"))
+			log.String("action", "shutdown"), log.String("state", "normal"), log.Int64("writeloop", srv.GetWriteLoopCount()))
 	}
 	super.TryWriteChannel(srv.closeChannel, struct{}{})
 }
 
-// GRPCServer 当网络类型为 NetworkGRPC 时将被允许获取 grpc 服务器，否则将会发生 panic
+// GRPCServer 当网络类型为 NetworkGRPC 或通过 WithGRPCMultiplex 复用 GRPC 服务时将被允许获取 grpc 服务器，否则将会发生 panic
 func (srv *Server) GRPCServer() *grpc.Server {
 	if srv.grpcServer == nil {
 		panic(ErrNetworkOnlySupportGRPC)
@@ -322,11 +459,14 @@ func (srv *Server) GRPCServer() *grpc.Server {
 	return srv.grpcServer
 }
 
-// HttpRouter 当网络类型为 NetworkHttp 时将被允许获取路由器进行路由注册，否则将会发生 panic
+// HttpRouter 当网络类型为 NetworkHttp 或 NetworkWebsocket 时将被允许获取路由器进行路由注册，否则将会发生 panic
 //   - 通过该函数注册的路由将无法在服务器关闭时正常等待请求结束
 //
 // Deprecated: 从 Minotaur 0.0.29 开始，由于设计原因已弃用，该函数将直接返回 *gin.Server 对象，导致无法正常的对请求结束时进行处理
 func (srv *Server) HttpRouter() gin.IRouter {
+	if srv.httpEngine != nil {
+		panic(ErrHttpEngineNotGin)
+	}
 	if srv.ginServer == nil {
 		panic(ErrNetworkOnlySupportHttp)
 	}
@@ -335,8 +475,12 @@ func (srv *Server) HttpRouter() gin.IRouter {
 
 // HttpServer 替代 HttpRouter 的函数，返回一个 *Http[*HttpContext] 对象
 //   - 通过该函数注册的路由将在服务器关闭时正常等待请求结束
+//   - 当网络类型为 NetworkWebsocket 时，注册的路由将与 websocket 共用监听地址，非 websocket 升级请求将交由这些路由处理，可用于健康检查等场景
 //   - 如果需要自行包装 Context 对象，可以使用 NewHttpHandleWrapper 方法
 func (srv *Server) HttpServer() *Http[*HttpContext] {
+	if srv.httpEngine != nil {
+		panic(ErrHttpEngineNotGin)
+	}
 	if srv.ginServer == nil {
 		panic(ErrNetworkOnlySupportHttp)
 	}
@@ -393,7 +537,15 @@ func (srv *Server) pushMessage(message *Message) {
 	if d == nil {
 		return
 	}
-	if (message.t == MessageTypeUniqueShuntAsync || message.t == MessageTypeUniqueAsync) && d.Unique(message.name) {
+	if srv.holdUniqueMessage(d, message) {
+		return
+	}
+	srv.putMessage(d, message)
+}
+
+// putMessage 将已确定分发器的消息写入分发器
+func (srv *Server) putMessage(d *dispatcher.Dispatcher[string, *Message], message *Message) {
+	if srv.shedMessage(message) {
 		srv.messagePool.Release(message)
 		return
 	}
@@ -401,7 +553,13 @@ func (srv *Server) pushMessage(message *Message) {
 	case MessageTypeShuntAsync, MessageTypeUniqueShuntAsync:
 		d.IncrCount(message.conn.GetID(), 1)
 	}
-	srv.hitMessageStatistics()
+	if message.conn.isMarkedBot() {
+		// 机器人连接的消息不计入消息统计，离线连接的消息依旧需要计入
+		srv.messageCounter.Add(1)
+	} else {
+		srv.hitMessageStatistics()
+		srv.hitShuntMessageStatistics(d.Name(), message.t)
+	}
 	d.Put(message)
 }
 
@@ -416,7 +574,7 @@ func (srv *Server) low(message *Message, present time.Time, expect time.Duration
 	if cost > expect {
 		if message == nil {
 			log.Warn("ServerLowMessage", log.String("type", "HTTP"), log.String("cost", cost.String()), log.Any("message", messageReplace))
-			srv.OnMessageLowExecEvent(nil, cost)
+			srv.OnMessageLowExecEvent(nil, cost, expect)
 			return
 		}
 		if len(messageReplace) > 0 {
@@ -432,7 +590,7 @@ func (srv *Server) low(message *Message, present time.Time, expect time.Duration
 		fields = append(fields, message.marks...)
 		//fields = append(fields, log.Stack("stack"))
 		log.Warn("ServerLowMessage", collection.ConvertSliceToAny(fields)...)
-		srv.OnMessageLowExecEvent(message, cost)
+		srv.OnMessageLowExecEvent(message, cost, expect)
 	}
 }
 
@@ -442,6 +600,7 @@ func (srv *Server) dispatchMessage(dispatcherIns *dispatcher.Dispatcher[string,
 		ctx    context.Context
 		cancel context.CancelFunc
 	)
+	srv.queuedBytes.Add(-msg.size)
 	if srv.deadlockDetect > 0 {
 		msg.l = new(sync.RWMutex)
 		ctx, cancel = context.WithTimeout(context.Background(), srv.deadlockDetect)
@@ -450,8 +609,9 @@ func (srv *Server) dispatchMessage(dispatcherIns *dispatcher.Dispatcher[string,
 			case <-ctx.Done():
 				if err := ctx.Err(); errors.Is(err, context.DeadlineExceeded) {
 					msg.l.RLock()
-					log.Warn("Server", log.String("SuspectedDeadlock", msg.String()))
-					srv.OnDeadlockDetectEvent(msg)
+					report := srv.newDeadlockReport(msg)
+					log.Warn("Server", log.String("SuspectedDeadlock", report.Message), log.Any("HandlerSites", report.HandlerSites), log.Int("Goroutines", len(report.Goroutines)))
+					srv.OnDeadlockDetectEvent(msg, report)
 					msg.l.RUnlock()
 				}
 			}
@@ -462,21 +622,24 @@ func (srv *Server) dispatchMessage(dispatcherIns *dispatcher.Dispatcher[string,
 	if msg.t != MessageTypeAsync && msg.t != MessageTypeUniqueAsync && msg.t != MessageTypeShuntAsync && msg.t != MessageTypeUniqueShuntAsync {
 		defer func(cancel context.CancelFunc, srv *Server, dispatcherIns *dispatcher.Dispatcher[string, *Message], msg *Message, present time.Time) {
 			super.Handle(cancel)
-			if err := super.RecoverTransform(recover()); err != nil {
-				stack := string(debug.Stack())
-				log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.String("Info", msg.String()), log.Any("error", err), log.String("stack", stack))
+			if err := super.RecoverPanic(recover()); err != nil {
+				stack := string(err.Stack)
+				log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.String("Info", msg.String()), log.Any("error", err.Err), log.String("stack", stack))
 				fmt.Println(stack)
-				srv.OnMessageErrorEvent(msg, err)
+				report := srv.newPanicReport(dispatcherIns, msg, err.Err, stack)
+				srv.OnMessageErrorEvent(msg, report)
+				srv.onMessagePanic(dispatcherIns, msg, report)
 			}
 			switch msg.t {
 			case MessageTypeAsyncCallback, MessageTypeShuntAsyncCallback:
 				dispatcherIns.IncrCount(msg.producer, -1)
 			case MessageTypeUniqueAsyncCallback, MessageTypeUniqueShuntAsyncCallback:
-				dispatcherIns.AntiUnique(msg.name)
+				srv.antiUnique(dispatcherIns, msg.name)
 				dispatcherIns.IncrCount(msg.producer, -1)
 			}
 
 			srv.low(msg, present, srv.lowMessageDuration, false)
+			srv.recordMessageLatency(present)
 			srv.messageCounter.Add(-1)
 
 			if atomic.CompareAndSwapUint32(&srv.closed, 0, 0) {
@@ -490,32 +653,28 @@ func (srv *Server) dispatchMessage(dispatcherIns *dispatcher.Dispatcher[string,
 	}
 
 	switch msg.t {
-	case MessageTypePacket:
-		if !srv.OnConnectionPacketPreprocessEvent(msg.conn, msg.packet, func(newPacket []byte) {
-			msg.packet = newPacket
-		}) {
-			srv.OnConnectionReceivePacketEvent(msg.conn, msg.packet)
-		}
-	case MessageTypeTicker, MessageTypeShuntTicker:
-		msg.ordinaryHandler()
 	case MessageTypeAsync, MessageTypeShuntAsync, MessageTypeUniqueAsync, MessageTypeUniqueShuntAsync:
+		srv.checkAsyncPoolSaturated(msg)
 		if err := srv.ants.Submit(func() {
 			defer func(cancel context.CancelFunc, srv *Server, dispatcherIns *dispatcher.Dispatcher[string, *Message], msg *Message, present time.Time) {
 				switch msg.t {
 				case MessageTypeShuntAsync, MessageTypeUniqueShuntAsync:
 					dispatcherIns.IncrCount(msg.conn.GetID(), -1)
 				}
-				if err := super.RecoverTransform(recover()); err != nil {
+				if err := super.RecoverPanic(recover()); err != nil {
 					if msg.t == MessageTypeUniqueAsync || msg.t == MessageTypeUniqueShuntAsync {
-						dispatcherIns.AntiUnique(msg.name)
+						srv.antiUnique(dispatcherIns, msg.name)
 					}
-					stack := string(debug.Stack())
-					log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.Any("error", err), log.String("stack", stack))
+					stack := string(err.Stack)
+					log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.Any("error", err.Err), log.String("stack", stack))
 					fmt.Println(stack)
-					srv.OnMessageErrorEvent(msg, err)
+					report := srv.newPanicReport(dispatcherIns, msg, err.Err, stack)
+					srv.OnMessageErrorEvent(msg, report)
+					srv.onMessagePanic(dispatcherIns, msg, report)
 				}
 				super.Handle(cancel)
 				srv.low(msg, present, srv.asyncLowMessageDuration, true)
+				srv.recordMessageLatency(present)
 				srv.messageCounter.Add(-1)
 
 				if atomic.CompareAndSwapUint32(&srv.closed, 0, 0) {
@@ -543,7 +702,7 @@ func (srv *Server) dispatchMessage(dispatcherIns *dispatcher.Dispatcher[string,
 				srv.pushShuntAsyncCallbackMessage(dispatcherIns, msg.conn, err, msg.errHandler)
 				return
 			}
-			dispatcherIns.AntiUnique(msg.name)
+			srv.antiUnique(dispatcherIns, msg.name)
 			dispatcherIns.IncrCount(msg.producer, -1)
 			if err != nil {
 				log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.Any("error", err), log.String("stack", string(debug.Stack())))
@@ -551,6 +710,22 @@ func (srv *Server) dispatchMessage(dispatcherIns *dispatcher.Dispatcher[string,
 		}); err != nil {
 			panic(err)
 		}
+	default:
+		srv.messageHandler(srv, msg)
+	}
+}
+
+// execMessage 执行除异步消息外的消息处理函数，该函数将作为消息中间件链的末端被调用
+func execMessage(srv *Server, msg *Message) {
+	switch msg.t {
+	case MessageTypePacket:
+		if !srv.OnConnectionPacketPreprocessEvent(msg.conn, msg.packet, func(newPacket []byte) {
+			msg.packet = newPacket
+		}) && !srv.serveRPCRequest(msg.conn, msg.packet) && srv.checkConnState(msg.conn, msg.packet) {
+			srv.OnConnectionReceivePacketEvent(msg.conn, msg.packet)
+		}
+	case MessageTypeTicker, MessageTypeShuntTicker:
+		msg.ordinaryHandler()
 	case MessageTypeAsyncCallback, MessageTypeShuntAsyncCallback, MessageTypeUniqueAsyncCallback, MessageTypeUniqueShuntAsyncCallback:
 		msg.errHandler(msg.err)
 	case MessageTypeSystem, MessageTypeShunt:
@@ -597,8 +772,21 @@ func (srv *Server) pushShuntAsyncCallbackMessage(dis *dispatcher.Dispatcher[stri
 }
 
 // PushPacketMessage 向服务器中推送 MessageTypePacket 消息
-//   - 当存在 UseShunt 的选项时，将会根据选项中的 shuntMatcher 进行分发，否则将在系统分发器中处理消息
+//   - 当数据包超出 WithPacketLimitSize 的大小限制时，数据包将被丢弃
+//   - 当连接通过 UseShunt 或 WithShuntMatcher 指定了消息分流渠道时，将在该分流渠道中处理消息，否则将在系统分发器中处理消息
+//   - 通过 WithRPC 开启 RPC 时，RPC 响应将直接交付给等待中的调用，不会进入消息队列
+//   - 通过 WithProtocolVersion 开启协议版本协商时，握手数据包及协商失败后的数据包不会进入消息队列
+//   - 通过 WithConnectionInitializer 指定了连接初始化函数时，初始化完成前的数据包将被暂存至初始化完成
 func (srv *Server) PushPacketMessage(conn *Conn, wst int, packet []byte, mark ...log.Field) {
+	if !srv.checkPacketLimit(conn, len(packet)) || !srv.negotiateProtocol(conn, wst, packet) || srv.resolveRPCResponse(conn, packet) || srv.holdInitPacket(conn, wst, packet, mark) {
+		return
+	}
+	srv.pushPacketMessage(conn, wst, packet, mark...)
+}
+
+// pushPacketMessage 向服务器中推送已通过检查的 MessageTypePacket 消息
+func (srv *Server) pushPacketMessage(conn *Conn, wst int, packet []byte, mark ...log.Field) {
+	srv.matchShunt(conn, packet)
 	srv.pushMessage(srv.messagePool.Get().castToPacketMessage(
 		&Conn{wst: wst, connection: conn.connection},
 		packet, mark...,
@@ -624,7 +812,7 @@ func (srv *Server) PushShuntTickerMessage(conn *Conn, name string, caller func()
 }
 
 // PushUniqueAsyncMessage 向服务器中推送 MessageTypeAsync 消息，消息执行与 MessageTypeAsync 一致
-//   - 不同的是当上一个相同的 unique 消息未执行完成时，将会忽略该消息
+//   - 不同的是当上一个相同的 unique 消息未执行完成时，将会忽略该消息，可通过 WithUniqueMessagePolicy 改为暂存最新的消息
 func (srv *Server) PushUniqueAsyncMessage(unique string, caller func() error, callback func(err error), mark ...log.Field) {
 	srv.pushMessage(srv.messagePool.Get().castToUniqueAsyncMessage(unique, caller, callback, mark...))
 }
@@ -636,7 +824,7 @@ func (srv *Server) pushUniqueAsyncCallbackMessage(dis *dispatcher.Dispatcher[str
 
 // PushUniqueShuntAsyncMessage 向特定分发器中推送 MessageTypeAsync 消息，消息执行与 MessageTypeAsync 一致
 //   - 需要注意的是，当未指定 UseShunt 时，将会通过系统分流渠道进行转发
-//   - 不同的是当上一个相同的 unique 消息未执行完成时，将会忽略该消息
+//   - 不同的是当上一个相同的 unique 消息未执行完成时，将会忽略该消息，可通过 WithUniqueMessagePolicy 改为暂存最新的消息
 func (srv *Server) PushUniqueShuntAsyncMessage(conn *Conn, unique string, caller func() error, callback func(err error), mark ...log.Field) {
 	srv.pushMessage(srv.messagePool.Get().castToUniqueShuntAsyncMessage(conn, unique, caller, callback, mark...))
 }
@@ -657,7 +845,7 @@ func (srv *Server) startMessageStatistics() {
 	if !srv.HasMessageStatistics() {
 		return
 	}
-	srv.runtime.messageStatistics = append(srv.runtime.messageStatistics, new(atomic.Int64))
+	srv.runtime.messageStatistics = append(srv.runtime.messageStatistics, new(messageStatistic))
 	ticker := time.NewTicker(srv.runtime.messageStatisticsDuration)
 	go func(ctx context.Context, ticker *time.Ticker, r *runtime) {
 		defer ticker.Stop()
@@ -665,7 +853,7 @@ func (srv *Server) startMessageStatistics() {
 			select {
 			case <-ticker.C:
 				r.messageStatisticsLock.Lock()
-				r.messageStatistics = append([]*atomic.Int64{new(atomic.Int64)}, r.messageStatistics...)
+				r.messageStatistics = append([]*messageStatistic{new(messageStatistic)}, r.messageStatistics...)
 				if len(r.messageStatistics) > r.messageStatisticsLimit {
 					r.messageStatistics = r.messageStatistics[:r.messageStatisticsLimit]
 				}
@@ -684,7 +872,7 @@ func (srv *Server) hitMessageStatistics() {
 		return
 	}
 	srv.runtime.messageStatisticsLock.RLock()
-	srv.runtime.messageStatistics[0].Add(1)
+	srv.runtime.messageStatistics[0].count.Add(1)
 	srv.runtime.messageStatisticsLock.RUnlock()
 }
 
@@ -704,7 +892,7 @@ func (srv *Server) GetDurationMessageCountByOffset(offset int) int64 {
 		srv.runtime.messageStatisticsLock.Unlock()
 		return 0
 	}
-	v := srv.runtime.messageStatistics[offset].Load()
+	v := srv.runtime.messageStatistics[offset].count.Load()
 	srv.runtime.messageStatisticsLock.Unlock()
 	return v
 }
@@ -717,7 +905,7 @@ func (srv *Server) GetAllDurationMessageCount() []int64 {
 	srv.runtime.messageStatisticsLock.Lock()
 	var vs = make([]int64, len(srv.runtime.messageStatistics))
 	for i, statistic := range srv.runtime.messageStatistics {
-		vs[i] = statistic.Load()
+		vs[i] = statistic.count.Load()
 	}
 	srv.runtime.messageStatisticsLock.Unlock()
 	return vs
@@ -736,6 +924,9 @@ func showServersInfo(mark string, servers ...*Server) {
 		srv := srv
 		serverInfos = append(serverInfos, func() {
 			log.Info(mark, log.String("", "RunningInfo"), log.Any("network", srv.network), log.String("ip", ip.String()), log.String("listen", srv.addr))
+			for _, listen := range srv.additionalListens {
+				log.Info(mark, log.String("", "RunningInfo"), log.Any("network", listen.network), log.String("ip", ip.String()), log.String("listen", listen.addr))
+			}
 		})
 	}
 	log.Info(mark, log.String("", "===================================================================="))
@@ -763,8 +954,14 @@ func onMessageSystemInit(srv *Server) {
 		},
 	)
 	srv.startMessageStatistics()
+	srv.startMessageReporter()
+	srv.startAudit()
+	srv.buildMessageHandler()
 	srv.dispatcherMgr = dispatcher.NewManager[string, *Message](srv.dispatcherBufferSize, srv.dispatchMessage).
+		SetDispatcherSizer(messageSize).
 		SetDispatcherCreatedHandler(srv.OnShuntChannelCreatedEvent).
-		SetDispatcherClosedHandler(srv.OnShuntChannelClosedEvent)
+		SetDispatcherClosedHandler(srv.OnShuntChannelClosedEvent).
+		SetDispatcherInitializer(srv.initShuntChannel).
+		SetReleaseDelay(srv.shuntReleaseDelay)
 	srv.OnMessageReadyEvent()
 }
@@ -1,8 +1,14 @@
 package server_test
 
 import (
+	"context"
+	"fmt"
+	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
 	"github.com/kercylan98/minotaur/utils/super"
+	"io"
+	"net/http"
 	"runtime/debug"
 	"testing"
 	"time"
@@ -99,3 +105,137 @@ func TestServer_IsSocket(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_GetOnlinePage(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	var bots []*server.Bot
+	var waitOnline = func(count int) {
+		for srv.GetOnlineCount() != count {
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			for i := 0; i < 5; i++ {
+				bot := server.NewBot(srv)
+				bot.JoinServer()
+				bots = append(bots, bot)
+			}
+			waitOnline(5)
+
+			var ids = make(map[string]struct{})
+			for offset := 0; offset < 5; offset += 2 {
+				for _, conn := range srv.GetOnlinePage(offset, 2) {
+					ids[conn.GetID()] = struct{}{}
+				}
+			}
+			if len(ids) != 5 {
+				t.Errorf("expect 5 distinct connections, got: %d", len(ids))
+			}
+			if page := srv.GetOnlinePage(5, 2); len(page) != 0 {
+				t.Errorf("expect empty page, got: %d", len(page))
+			}
+			if count := srv.CountOnline(func(conn *server.Conn) bool { return conn.IsBot() }); count != 5 {
+				t.Errorf("expect 5 bots, got: %d", count)
+			}
+			var ranged int
+			srv.RangeOnline(func(id string, conn *server.Conn) bool {
+				ranged++
+				return ranged < 3
+			})
+			if ranged != 3 {
+				t.Errorf("expect range stopped at 3, got: %d", ranged)
+			}
+
+			bots[0].LeaveServer()
+			waitOnline(4)
+			if page := srv.GetOnlinePage(0, 10); len(page) != 4 {
+				t.Errorf("expect 4 connections, got: %d", len(page))
+			}
+		}()
+	})
+	if err := srv.Run(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer_RollingShutdown(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithRollingQuietPeriod(50*time.Millisecond))
+	var stages []server.RollingShutdownStage
+	var repeatErr error
+	var result = make(chan error, 1)
+	srv.RegRollingShutdownEvent(func(srv *server.Server, stage server.RollingShutdownStage) {
+		stages = append(stages, stage)
+		if stage == server.RollingShutdownStageDraining {
+			repeatErr = srv.RollingShutdown(context.Background())
+		}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		server.NewBot(srv).JoinServer()
+		go func() {
+			result <- srv.RollingShutdown(context.Background())
+		}()
+	})
+	if err := srv.Run(fmt.Sprintf("127.0.0.1:%d", random.UsablePort())); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+	if repeatErr != server.ErrRollingShutdownInProgress {
+		t.Fatalf("expect ErrRollingShutdownInProgress, got: %v", repeatErr)
+	}
+	expected := []server.RollingShutdownStage{
+		server.RollingShutdownStageDraining,
+		server.RollingShutdownStageWaitRouting,
+		server.RollingShutdownStageDrainConnections,
+		server.RollingShutdownStageExit,
+	}
+	if fmt.Sprint(stages) != fmt.Sprint(expected) {
+		t.Fatalf("unexpected stages: %v", stages)
+	}
+}
+
+func TestServer_HttpServer(t *testing.T) {
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	var opened bool
+	srv := server.New(server.NetworkWebsocket)
+	srv.HttpServer().GET("/healthz", func(ctx *server.HttpContext) {
+		ctx.String(http.StatusOK, "ok")
+	})
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened = true
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			resp, err := http.Get(fmt.Sprintf("http://%s/healthz", addr))
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+				t.Errorf("unexpected response: %d %s", resp.StatusCode, body)
+			}
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/ws", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr + "/ws"); err != nil {
+		t.Fatal(err)
+	}
+	if !opened {
+		t.Fatal("websocket connection should be opened")
+	}
+}
@@ -0,0 +1,17 @@
+package server
+
+// ShuntMatcher 分流渠道匹配函数，返回连接应当使用的消息分流渠道名称，返回空字符串时连接将保持当前的分流渠道
+//   - 连接打开时 packet 为 nil，此后每次接收到数据包时都将以该数据包进行匹配
+type ShuntMatcher func(conn *Conn, packet []byte) (name string)
+
+// matchShunt 在通过 WithShuntMatcher 指定了匹配函数时，将连接切换至匹配的消息分流渠道
+func (srv *Server) matchShunt(conn *Conn, packet []byte) {
+	if srv.shuntMatcher == nil {
+		return
+	}
+	name := srv.shuntMatcher(conn, packet)
+	if len(name) == 0 || srv.GetConnCurrShunt(conn) == name {
+		return
+	}
+	srv.UseShunt(conn, name)
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/server/internal/dispatcher"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync/atomic"
+)
+
+const (
+	// ShuntOverflowPolicyExpand 扩容缓冲区，消息将继续写入，这是分流通道的默认策略
+	ShuntOverflowPolicyExpand = ShuntOverflowPolicy(dispatcher.OverflowPolicyExpand)
+	// ShuntOverflowPolicyBlock 阻塞推送消息的协程，直到缓冲区存在可用空间
+	ShuntOverflowPolicyBlock = ShuntOverflowPolicy(dispatcher.OverflowPolicyBlock)
+	// ShuntOverflowPolicyDropOldest 丢弃缓冲区中最早的消息，当最早的消息已被取出处理时，将丢弃新消息
+	ShuntOverflowPolicyDropOldest = ShuntOverflowPolicy(dispatcher.OverflowPolicyDropOldest)
+	// ShuntOverflowPolicyDropNew 丢弃新消息
+	ShuntOverflowPolicyDropNew = ShuntOverflowPolicy(dispatcher.OverflowPolicyDropNew)
+)
+
+var shuntOverflowPolicyNames = map[ShuntOverflowPolicy]string{
+	ShuntOverflowPolicyExpand:     "ShuntOverflowPolicyExpand",
+	ShuntOverflowPolicyBlock:      "ShuntOverflowPolicyBlock",
+	ShuntOverflowPolicyDropOldest: "ShuntOverflowPolicyDropOldest",
+	ShuntOverflowPolicyDropNew:    "ShuntOverflowPolicyDropNew",
+}
+
+// ShuntOverflowPolicy 分流通道缓冲区溢出策略
+type ShuntOverflowPolicy byte
+
+// String 返回溢出策略的字符串表示
+func (slf ShuntOverflowPolicy) String() string {
+	return shuntOverflowPolicyNames[slf]
+}
+
+// shuntChannelConfig 分流通道配置
+type shuntChannelConfig struct {
+	bufferSize int
+	policy     ShuntOverflowPolicy
+}
+
+// SetShuntChannelOverflowPolicy 设置特定名称的分流通道的缓冲区大小及溢出策略，以便在繁忙的房间中保护服务器而不影响其他分流通道
+//   - 当分流通道中等待处理的消息数量达到 bufferSize 时，将根据 policy 进行处理，并触发 OnShuntChannelOverflowEvent 事件
+//   - 当 bufferSize <= 0 时，表示该分流通道的缓冲区不会溢出
+//   - 该函数可在服务器运行前后调用，对已存在的分流通道将立即生效
+//   - 需要注意的是，当策略为 ShuntOverflowPolicyBlock 时，在该分流通道的消息中向自身推送消息可能会产生死锁
+func (srv *Server) SetShuntChannelOverflowPolicy(name string, bufferSize int, policy ShuntOverflowPolicy) {
+	srv.shuntChannelConfigLock.Lock()
+	if srv.shuntChannelConfigs == nil {
+		srv.shuntChannelConfigs = make(map[string]shuntChannelConfig)
+	}
+	srv.shuntChannelConfigs[name] = shuntChannelConfig{bufferSize: bufferSize, policy: policy}
+	srv.shuntChannelConfigLock.Unlock()
+
+	if srv.dispatcherMgr == nil || name == dispatcher.SystemName {
+		return
+	}
+	if d := srv.dispatcherMgr.GetNamedDispatcher(name); d != nil {
+		srv.initShuntChannel(d)
+	}
+}
+
+// initShuntChannel 根据分流通道配置初始化分流通道
+func (srv *Server) initShuntChannel(d *dispatcher.Dispatcher[string, *Message]) {
+	srv.shuntChannelConfigLock.RLock()
+	config, exist := srv.shuntChannelConfigs[d.Name()]
+	srv.shuntChannelConfigLock.RUnlock()
+	if !exist {
+		return
+	}
+	d.SetOverflowPolicy(config.bufferSize, dispatcher.OverflowPolicy(config.policy), srv.onShuntChannelOverflow)
+}
+
+// onShuntChannelOverflow 处理分流通道缓冲区溢出，被丢弃的消息将被回收
+func (srv *Server) onShuntChannelOverflow(d *dispatcher.Dispatcher[string, *Message], policy dispatcher.OverflowPolicy, dropped *Message) {
+	p := ShuntOverflowPolicy(policy)
+	if dropped == nil {
+		log.Warn("Server", log.String("ShuntChannelOverflow", d.Name()), log.String("policy", p.String()))
+	} else {
+		log.Warn("Server", log.String("ShuntChannelOverflow", d.Name()), log.String("policy", p.String()), log.String("dropped", dropped.String()))
+	}
+	srv.OnShuntChannelOverflowEvent(d.Name(), p, dropped)
+	if dropped != nil {
+		srv.discardMessage(d, dropped)
+	}
+}
+
+// discardMessage 丢弃一条已推送但未被执行的消息，并还原推送时产生的计数
+func (srv *Server) discardMessage(d *dispatcher.Dispatcher[string, *Message], msg *Message) {
+	switch msg.t {
+	case MessageTypeShuntAsync:
+		d.IncrCount(msg.conn.GetID(), -1)
+	case MessageTypeUniqueShuntAsync:
+		srv.antiUnique(d, msg.name)
+		d.IncrCount(msg.conn.GetID(), -1)
+	case MessageTypeAsyncCallback, MessageTypeShuntAsyncCallback:
+		d.IncrCount(msg.producer, -1)
+	case MessageTypeUniqueAsyncCallback, MessageTypeUniqueShuntAsyncCallback:
+		srv.antiUnique(d, msg.name)
+		d.IncrCount(msg.producer, -1)
+	}
+	srv.messageCounter.Add(-1)
+	srv.queuedBytes.Add(-msg.size)
+	if atomic.CompareAndSwapUint32(&srv.closed, 0, 0) {
+		srv.messagePool.Release(msg)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/super"
+	"time"
+)
+
+// ShutdownStage 服务器停止的阶段，各阶段按照定义的顺序依次执行
+type ShutdownStage int
+
+const (
+	ShutdownStageStopAccept  ShutdownStage = iota + 1 // 停止接受新连接，此后新连接将以 ConnectionRejectedReasonShutdown 被拒绝
+	ShutdownStageDrain                                // 等待正在处理的消息执行完毕，可在该阶段停止产生新的消息
+	ShutdownStageBeforeClose                          // 即将关闭所有连接并等待消息分发器结束
+	ShutdownStageAfterClose                           // 所有连接、监听器及 HTTP 服务器均已关闭
+)
+
+func (s ShutdownStage) String() string {
+	switch s {
+	case ShutdownStageStopAccept:
+		return "stop-accept"
+	case ShutdownStageDrain:
+		return "drain"
+	case ShutdownStageBeforeClose:
+		return "before-close"
+	case ShutdownStageAfterClose:
+		return "after-close"
+	}
+	return "unknown"
+}
+
+// ShutdownHook 服务器停止时在特定阶段执行的钩子函数
+//   - 通过 WithShutdownTimeout 设置了超时时间时，ctx 将在超时时结束，钩子函数应当在 ctx 结束时尽快返回
+type ShutdownHook func(ctx context.Context, srv *Server)
+
+// RegisterShutdownHook 注册在服务器停止的特定阶段执行的钩子函数，同一阶段的钩子函数将按照注册顺序在停止服务器的协程中依次执行
+//   - 钩子函数发生的 panic 将被记录，不会中断服务器的停止
+func (srv *Server) RegisterShutdownHook(stage ShutdownStage, hook ShutdownHook) {
+	srv.shutdownHookLock.Lock()
+	defer srv.shutdownHookLock.Unlock()
+	if srv.shutdownHooks == nil {
+		srv.shutdownHooks = make(map[ShutdownStage][]ShutdownHook)
+	}
+	srv.shutdownHooks[stage] = append(srv.shutdownHooks[stage], hook)
+}
+
+// enterShutdownStage 进入服务器停止的特定阶段并执行该阶段的钩子函数
+func (srv *Server) enterShutdownStage(ctx context.Context, stage ShutdownStage) {
+	srv.shutdownHookLock.Lock()
+	hooks := srv.shutdownHooks[stage]
+	srv.shutdownHookLock.Unlock()
+	if len(hooks) == 0 {
+		return
+	}
+	log.Info("Server", log.String("listen", srv.addr), log.String("action", "shutdown"), log.String("stage", stage.String()))
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if err := super.RecoverTransform(recover()); err != nil {
+					log.Error("Server", log.String("ShutdownHook", stage.String()), log.Err(err))
+				}
+			}()
+			hook(ctx, srv)
+		}()
+	}
+}
+
+// shutdownContext 创建服务器停止过程所使用的上下文，未通过 WithShutdownTimeout 设置超时时间时将不会超时
+func (srv *Server) shutdownContext() (context.Context, context.CancelFunc) {
+	if srv.shutdownTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), srv.shutdownTimeout)
+}
+
+// waitShutdown 在停止服务器时等待 done 结束，当 ctx 先结束时将返回 false
+//   - 等待期间将每隔一段时间通过 info 输出等待中的日志
+func waitShutdown(ctx context.Context, done <-chan struct{}, info func()) bool {
+	var infoCount int
+	for {
+		select {
+		case <-done:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-time.After(time.Second):
+			if infoCount%10 == 0 {
+				info()
+			}
+			infoCount++
+		}
+	}
+}
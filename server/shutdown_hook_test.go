@@ -0,0 +1,80 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"testing"
+	"time"
+)
+
+func TestServer_RegisterShutdownHook(t *testing.T) {
+	var stages []string
+	srv := server.New(server.NetworkNone, server.WithShutdownTimeout(time.Millisecond*300))
+	for _, stage := range []server.ShutdownStage{server.ShutdownStageAfterClose, server.ShutdownStageDrain, server.ShutdownStageBeforeClose, server.ShutdownStageStopAccept} {
+		stage := stage
+		srv.RegisterShutdownHook(stage, func(ctx context.Context, srv *server.Server) {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("shutdown hook context should have deadline")
+			}
+			stages = append(stages, stage.String())
+		})
+	}
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		srv.PushAsyncMessage(func() error {
+			time.Sleep(time.Second * 3)
+			return nil
+		}, nil)
+		go srv.Shutdown()
+	})
+
+	start := time.Now()
+	if err := srv.Run(""); err != nil {
+		t.Fatal(err)
+	}
+	if cost := time.Since(start); cost > time.Second*2 {
+		t.Fatalf("shutdown should stop waiting after timeout, cost: %s", cost)
+	}
+	if expected := "[stop-accept drain before-close after-close]"; fmt.Sprint(stages) != expected {
+		t.Fatalf("unexpected stages: %v", stages)
+	}
+}
+
+func TestServer_ShutdownCloseConn(t *testing.T) {
+	var closed = make(chan error, 1)
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkWebsocket, server.WithShutdownCloseConn())
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s", addr), nil)
+			if err != nil {
+				t.Error(err)
+				srv.Shutdown()
+				return
+			}
+			defer conn.Close()
+			_, _, err = conn.ReadMessage()
+			closed <- err
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	// 升级后的 websocket 连接不会随 HTTP 服务器关闭，需要由服务器停止时主动关闭
+	if count := srv.GetWriteLoopCount(); count != 0 {
+		t.Fatalf("write loops: %d", count)
+	}
+	select {
+	case err := <-closed:
+		if err == nil {
+			t.Fatal("connection should be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection is not closed after shutdown")
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"github.com/panjf2000/gnet"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// UDPSessionTokenResolver 从 UDP 数据报中解析会话令牌的函数
+//   - 返回的 payload 为去除令牌后的数据包，将继续进行解包及消息处理
+//   - 当 ok 为 false 时该数据报将被丢弃
+type UDPSessionTokenResolver func(packet []byte) (token string, payload []byte, ok bool)
+
+// udpSession 连接所属的 UDP 虚拟会话
+type udpSession struct {
+	owner  *udpSessions
+	key    string       // 会话在会话表中的键，即远程地址或会话令牌
+	active atomic.Int64 // 最近一次收到数据报的时间戳（纳秒）
+	peer   atomic.Value // 最近一次收到数据报的 gnet.Conn，用于向最新的远程地址发送数据
+}
+
+// udpSessions UDP 虚拟会话表，为同一远程地址或会话令牌的数据报维持同一个连接
+type udpSessions struct {
+	srv     *Server
+	network Network
+	lock    sync.Mutex
+	conns   map[string]*Conn
+}
+
+// newUDPSessions 创建 UDP 虚拟会话表，并开始定期清理超过不活跃时长的会话
+func newUDPSessions(srv *Server, network Network) *udpSessions {
+	s := &udpSessions{
+		srv:     srv,
+		network: network,
+		conns:   make(map[string]*Conn),
+	}
+	if srv.udpSessionTimeout > 0 {
+		go s.sweep(srv.udpSessionTimeout)
+	}
+	return s
+}
+
+// load 获取数据报所属的会话连接，当会话不存在时将创建新的连接并触发连接打开事件
+func (s *udpSessions) load(c gnet.Conn, packet []byte) (conn *Conn, payload []byte, ok bool) {
+	key, payload := c.RemoteAddr().String(), packet
+	if s.srv.udpSessionToken != nil {
+		if key, payload, ok = s.srv.udpSessionToken(packet); !ok {
+			return nil, nil, false
+		}
+	}
+
+	var opened bool
+	s.lock.Lock()
+	conn = s.conns[key]
+	if conn == nil {
+		// 在创建连接前检查连接数限制，伪造来源地址的数据报被拒绝时不会产生任何需要释放的资源
+		if !s.srv.acceptConn(addrIP(c.RemoteAddr())) {
+			s.lock.Unlock()
+			return nil, nil, false
+		}
+		conn = newGNetConn(s.srv, s.network, c)
+		if addr, ok := c.RemoteAddr().(*net.UDPAddr); ok {
+			// gnet 将在数据报处理完毕后复用远程地址的内存
+			conn.remoteAddr = &net.UDPAddr{IP: append(net.IP(nil), addr.IP...), Port: addr.Port, Zone: addr.Zone}
+		}
+		conn.limited = true
+		conn.udp = &udpSession{owner: s, key: key}
+		s.conns[key] = conn
+		opened = true
+	}
+	conn.udp.active.Store(time.Now().UnixNano())
+	conn.udp.peer.Store(c)
+	s.lock.Unlock()
+
+	if opened {
+		s.srv.OnConnectionOpenedEvent(conn)
+	}
+	return conn, payload, true
+}
+
+// remove 从会话表中移除已关闭的连接
+func (s *udpSessions) remove(conn *Conn) {
+	s.lock.Lock()
+	if s.conns[conn.udp.key] == conn {
+		delete(s.conns, conn.udp.key)
+	}
+	s.lock.Unlock()
+}
+
+// count 获取当前会话数量
+func (s *udpSessions) count() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.conns)
+}
+
+// close 关闭所有会话
+func (s *udpSessions) close() {
+	s.lock.Lock()
+	var conns = make([]*Conn, 0, len(s.conns))
+	for _, conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.lock.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// sweep 定期关闭超过 timeout 未收到数据报的会话，直到服务器停止
+func (s *udpSessions) sweep(timeout time.Duration) {
+	interval := timeout / 2
+	if interval > time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.srv.ctx.Done():
+			return
+		case now := <-ticker.C:
+			var expired []*Conn
+			deadline := now.Add(-timeout).UnixNano()
+			s.lock.Lock()
+			for _, conn := range s.conns {
+				if conn.udp.active.Load() < deadline {
+					expired = append(expired, conn)
+				}
+			}
+			s.lock.Unlock()
+			for _, conn := range expired {
+				conn.Close(ErrUDPSessionTimeout)
+			}
+		}
+	}
+}
+
+// GetUDPSessionCount 获取服务器所有 UDP 监听地址中当前的虚拟会话数量
+func (srv *Server) GetUDPSessionCount() int {
+	var count int
+	for _, sessions := range srv.udpSessions {
+		count += sessions.count()
+	}
+	return count
+}
@@ -0,0 +1,110 @@
+package server_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithUDPSessionToken(t *testing.T) {
+	var opened, closed atomic.Int32
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkUdp,
+		server.WithUDPSessionTimeout(time.Millisecond*200),
+		server.WithUDPSessionToken(func(packet []byte) (token string, payload []byte, ok bool) {
+			before, after, found := bytes.Cut(packet, []byte("|"))
+			return string(before), after, found
+		}),
+	)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened.Add(1)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		if e, ok := err.(error); ok && errors.Is(e, server.ErrUDPSessionTimeout) {
+			closed.Add(1)
+		}
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer srv.Shutdown()
+			for _, packet := range []string{"a", "b"} {
+				// 每次使用新的本地地址发送，模拟客户端地址发生变化
+				conn, err := net.Dial("udp", addr)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err = conn.Write([]byte("player|" + packet)); err != nil {
+					t.Error(err)
+					return
+				}
+				_ = conn.SetReadDeadline(time.Now().Add(time.Second * 3))
+				var buf = make([]byte, 16)
+				n, err := conn.Read(buf)
+				_ = conn.Close()
+				if err != nil || string(buf[:n]) != packet {
+					t.Errorf("receive: %s, err: %v", buf[:n], err)
+					return
+				}
+			}
+			if count := srv.GetUDPSessionCount(); count != 1 {
+				t.Errorf("session count: %d", count)
+			}
+			time.Sleep(time.Second)
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if opened.Load() != 1 || closed.Load() != 1 {
+		t.Fatalf("opened: %d, closed: %d", opened.Load(), closed.Load())
+	}
+}
+
+func TestUDPSession_ConnectionLimit(t *testing.T) {
+	var reason server.ConnectionRejectedReason
+	var before, loops int64
+	addr := fmt.Sprintf("127.0.0.1:%d", random.UsablePort())
+	srv := server.New(server.NetworkUdp, server.WithConnectionLimit(1, 0))
+	srv.RegConnectionRejectedEvent(func(srv *server.Server, ip string, r server.ConnectionRejectedReason) {
+		reason = r
+		loops = srv.GetWriteLoopCount() - before
+		srv.Shutdown()
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		before = srv.GetWriteLoopCount()
+		go func() {
+			// 每个本地地址都将创建新的会话，第二个会话将超出连接总数限制
+			for i := 0; i < 2; i++ {
+				conn, err := net.Dial("udp", addr)
+				if err != nil {
+					t.Error(err)
+					srv.Shutdown()
+					return
+				}
+				_, _ = conn.Write([]byte("ping"))
+				_ = conn.Close()
+				time.Sleep(time.Millisecond * 50)
+			}
+		}()
+	})
+	if err := srv.Run(addr); err != nil {
+		t.Fatal(err)
+	}
+	if reason != server.ConnectionRejectedReasonTotalLimit {
+		t.Fatalf("reason: %s", reason)
+	}
+	if loops != 1 {
+		// 被拒绝的数据报不应创建写循环
+		t.Fatalf("write loops: %d", loops)
+	}
+}
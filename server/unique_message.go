@@ -0,0 +1,81 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/server/internal/dispatcher"
+)
+
+const (
+	// UniqueMessagePolicyDiscard 丢弃新的唯一消息，这是唯一消息的默认策略
+	UniqueMessagePolicyDiscard UniqueMessagePolicy = iota
+	// UniqueMessagePolicyQueueLatest 暂存最新的唯一消息，并在正在执行的同名唯一消息结束后执行，暂存期间更早的消息将被合并丢弃
+	UniqueMessagePolicyQueueLatest
+)
+
+var uniqueMessagePolicyNames = map[UniqueMessagePolicy]string{
+	UniqueMessagePolicyDiscard:     "UniqueMessagePolicyDiscard",
+	UniqueMessagePolicyQueueLatest: "UniqueMessagePolicyQueueLatest",
+}
+
+// UniqueMessagePolicy 同名唯一消息正在执行时推送唯一消息的处理策略
+type UniqueMessagePolicy byte
+
+// String 返回处理策略的字符串表示
+func (slf UniqueMessagePolicy) String() string {
+	return uniqueMessagePolicyNames[slf]
+}
+
+// uniqueMessageKey 等待执行的唯一消息键
+type uniqueMessageKey struct {
+	dis    *dispatcher.Dispatcher[string, *Message]
+	unique string
+}
+
+// holdUniqueMessage 检查唯一消息是否存在正在执行的同名消息，返回消息是否已被丢弃或暂存
+//   - 非唯一消息将始终返回 false
+func (srv *Server) holdUniqueMessage(d *dispatcher.Dispatcher[string, *Message], message *Message) bool {
+	if message.t != MessageTypeUniqueShuntAsync && message.t != MessageTypeUniqueAsync {
+		return false
+	}
+	if srv.uniqueMessagePolicy != UniqueMessagePolicyQueueLatest {
+		if !d.Unique(message.name) {
+			return false
+		}
+		srv.OnUniqueMessageDroppedEvent(message, UniqueMessagePolicyDiscard)
+		srv.messagePool.Release(message)
+		return true
+	}
+
+	srv.uniqueMessageLock.Lock()
+	if !d.Unique(message.name) {
+		srv.uniqueMessageLock.Unlock()
+		return false
+	}
+	key := uniqueMessageKey{dis: d, unique: message.name}
+	dropped := srv.uniqueMessagePending[key]
+	srv.uniqueMessagePending[key] = message
+	srv.uniqueMessageLock.Unlock()
+	if dropped != nil {
+		srv.OnUniqueMessageDroppedEvent(dropped, UniqueMessagePolicyQueueLatest)
+		srv.messagePool.Release(dropped)
+	}
+	return true
+}
+
+// antiUnique 在唯一消息执行结束时取消唯一消息键，当存在暂存的同名消息时将保持唯一消息键并将暂存的消息写入分发器
+func (srv *Server) antiUnique(d *dispatcher.Dispatcher[string, *Message], unique string) {
+	if srv.uniqueMessagePolicy != UniqueMessagePolicyQueueLatest {
+		d.AntiUnique(unique)
+		return
+	}
+	srv.uniqueMessageLock.Lock()
+	key := uniqueMessageKey{dis: d, unique: unique}
+	pending, exist := srv.uniqueMessagePending[key]
+	if !exist {
+		d.AntiUnique(unique)
+		srv.uniqueMessageLock.Unlock()
+		return
+	}
+	delete(srv.uniqueMessagePending, key)
+	srv.uniqueMessageLock.Unlock()
+	srv.putMessage(d, pending)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gorilla/websocket"
+	"io"
+	"net"
+	"time"
+)
+
+// websocketAssembly 通过 WithWebsocketMessageAssembly 设置的 websocket 分片消息组装配置
+type websocketAssembly struct {
+	limit   int           // 组装后的消息大小上限，<= 0 时表示不限制
+	timeout time.Duration // 接收到首个数据帧后组装完整消息的超时时间，<= 0 时表示不限制
+}
+
+// readWebsocketMessage 读取一条完整的 websocket 消息，分片消息的首帧及所有延续帧将被组装为一个数据包
+//   - 当组装后的消息超出大小限制时，剩余的延续帧将被读取并丢弃，随后触发 OnConnectionPacketOversizeEvent 事件
+//   - 当策略为 PacketLimitPolicyDiscard 时，discarded 将为 true 以跳过该消息，连接将保持可用，否则返回 ErrPacketOversize
+//   - 组装超时将返回 ErrWebsocketAssemblyTimeout
+//   - 组装完成后，后续的读取超时时间将由读取循环通过 WithWebsocketReadDeadline 重新设置
+func (srv *Server) readWebsocketMessage(conn *Conn, ws *websocket.Conn) (messageType int, packet []byte, discarded bool, err error) {
+	assembly := srv.websocketAssembly
+	messageType, reader, err := ws.NextReader()
+	if err != nil {
+		return messageType, nil, false, err
+	}
+	if assembly.timeout > 0 {
+		if err = ws.SetReadDeadline(time.Now().Add(assembly.timeout)); err != nil {
+			return messageType, nil, false, err
+		}
+		if srv.websocketReadDeadline <= 0 {
+			// 未设置读取超时时间时，组装完成后需取消组装超时时间，避免影响下一条消息的读取
+			defer func() {
+				_ = ws.SetReadDeadline(time.Time{})
+			}()
+		}
+	}
+
+	var buf bytes.Buffer
+	limit := assembly.limit
+	if limit <= 0 {
+		limit = srv.packetLimitSize
+	}
+	if limit <= 0 {
+		_, err = buf.ReadFrom(reader)
+		return messageType, buf.Bytes(), false, assemblyError(err)
+	}
+	n, err := buf.ReadFrom(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return messageType, nil, false, assemblyError(err)
+	}
+	if n <= int64(limit) {
+		return messageType, buf.Bytes(), false, nil
+	}
+
+	remain, err := io.Copy(io.Discard, reader)
+	if err != nil {
+		return messageType, nil, false, assemblyError(err)
+	}
+	srv.onPacketOversize(conn, int(n+remain))
+	if srv.packetLimitPolicy == PacketLimitPolicyClose {
+		return messageType, nil, false, ErrPacketOversize
+	}
+	return messageType, nil, true, nil
+}
+
+// assemblyError 将组装分片消息时发生的超时错误转换为 ErrWebsocketAssemblyTimeout
+func assemblyError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrWebsocketAssemblyTimeout
+	}
+	return err
+}
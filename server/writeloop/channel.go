@@ -2,7 +2,7 @@ package writeloop
 
 import (
 	"github.com/kercylan98/minotaur/utils/hub"
-	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/routines"
 )
 
 // NewChannel 创建基于 Channel 的写循环
@@ -10,13 +10,15 @@ import (
 //   - channelSize Channel 的大小
 //   - writeHandler 写入处理函数
 //   - errorHandler 错误处理函数
+//   - opts 写循环选项，可通过 WithRetry 及 WithErrorPolicy 设置写入失败后的重试次数及处理策略，通过 WithRoutineGroup 设置写循环协程所属的协程组
 //
 // 传入 writeHandler 的消息对象是从 Channel 中获取的，因此 writeHandler 不应该持有消息对象的引用，同时也不应该主动释放消息对象
-func NewChannel[Message any](pool *hub.ObjectPool[Message], channelSize int, writeHandler func(message Message) error, errorHandler func(err any)) *Channel[Message] {
+func NewChannel[Message any](pool *hub.ObjectPool[Message], channelSize int, writeHandler func(message Message) error, errorHandler func(err any), opts ...Option) *Channel[Message] {
 	wl := &Channel[Message]{
-		c: make(chan Message, channelSize),
+		c:        make(chan Message, channelSize),
+		executor: newExecutor("Channel", pool, writeHandler, errorHandler, opts...),
 	}
-	go func() {
+	routines.Named(wl.group).Go(func() {
 		for {
 			select {
 			case message, ok := <-wl.c:
@@ -24,24 +26,17 @@ func NewChannel[Message any](pool *hub.ObjectPool[Message], channelSize int, wri
 					return
 				}
 
-				err := writeHandler(message)
-				pool.Release(message)
-				if err != nil {
-					if errorHandler == nil {
-						log.Error("Channel", log.Err(err))
-						continue
-					}
-					errorHandler(err)
-				}
+				wl.exec(message)
 			}
 		}
-	}()
+	})
 
 	return wl
 }
 
 // Channel 基于 chan 的写循环，与 Unbounded 相同，但是使用 Channel 实现
 type Channel[T any] struct {
+	*executor[T]
 	c chan T
 }
 
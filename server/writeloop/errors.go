@@ -0,0 +1,27 @@
+package writeloop
+
+import "errors"
+
+var (
+	ErrWriteLoopBroken = errors.New("write loop is broken") // 写循环已通过 ErrorPolicyClose 停止写入
+)
+
+// Permanent 包装 writeHandler 返回的错误，被包装的错误将不会被重试，适用于编码失败等重试无法恢复的错误
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,120 @@
+package writeloop
+
+import (
+	"github.com/kercylan98/minotaur/utils/hub"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/super"
+	"sync/atomic"
+	"time"
+)
+
+func newExecutor[Message any](name string, pool *hub.ObjectPool[Message], writeHandler func(message Message) error, errorHandler func(err any), opts ...Option) *executor[Message] {
+	e := &executor[Message]{
+		options:      options{group: RoutineGroup},
+		name:         name,
+		pool:         pool,
+		writeHandler: writeHandler,
+		errorHandler: errorHandler,
+	}
+	for _, opt := range opts {
+		opt(&e.options)
+	}
+	return e
+}
+
+// executor 按照重试次数及错误策略执行写入
+type executor[Message any] struct {
+	options
+	name         string                   // 写循环名称，用于日志记录
+	pool         *hub.ObjectPool[Message] // 消息缓冲池
+	writeHandler func(message Message) error
+	errorHandler func(err any)
+	retried      atomic.Int64 // 重试次数
+	dropped      atomic.Int64 // 丢弃的消息数量
+	closed       atomic.Bool  // 是否已通过 ErrorPolicyClose 关闭
+}
+
+// exec 写入消息并在写入完成后将消息放回缓冲池，仅在写循环协程中调用
+func (e *executor[Message]) exec(message Message) {
+	defer e.pool.Release(message)
+	if e.closed.Load() {
+		e.dropped.Add(1)
+		e.complete(message, ErrWriteLoopBroken)
+		return
+	}
+
+	err := e.write(message)
+	backoff := e.backoff
+	for i := 0; err != nil && i < e.retry; i++ {
+		if _, permanent := err.(*permanentError); permanent {
+			break
+		}
+		e.retried.Add(1)
+		if backoff > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		err = e.write(message)
+	}
+	if permanent, ok := err.(*permanentError); ok {
+		err = permanent.err
+	}
+	e.complete(message, err)
+	if err == nil {
+		return
+	}
+
+	switch e.policy {
+	case ErrorPolicyDrop:
+		e.dropped.Add(1)
+	case ErrorPolicyClose:
+		e.dropped.Add(1)
+		e.closed.Store(true)
+		e.report(err)
+	default:
+		e.report(err)
+	}
+}
+
+// write 调用 writeHandler 写入消息，writeHandler 发生的 panic 将被记录并转换为不会被重试的错误
+//   - 写循环协程将继续运行，以免后续的 Put 被永久阻塞，错误将按照写入失败的处理策略处理
+func (e *executor[Message]) write(message Message) (err error) {
+	defer func() {
+		if panicErr := super.RecoverPanic(recover()); panicErr != nil {
+			log.Error(e.name, log.String("State", "Panic"), log.Err(panicErr.Err), log.String("Stack", string(panicErr.Stack)))
+			err = Permanent(panicErr.Err)
+		}
+	}()
+	return e.writeHandler(message)
+}
+
+// complete 当消息实现了 Completer 时通知其最终的写入结果
+func (e *executor[Message]) complete(message Message, err error) {
+	if completer, ok := any(message).(Completer); ok {
+		completer.Complete(err)
+	}
+}
+
+// report 将错误交由 errorHandler 处理，未设置 errorHandler 时将记录错误日志
+func (e *executor[Message]) report(err error) {
+	if e.errorHandler == nil {
+		log.Error(e.name, log.Err(err))
+		return
+	}
+	e.errorHandler(err)
+}
+
+// GetRetriedCount 获取写入失败后的重试次数
+func (e *executor[Message]) GetRetriedCount() int64 {
+	return e.retried.Load()
+}
+
+// GetDroppedCount 获取由于写入失败或写循环已通过 ErrorPolicyClose 关闭而丢弃的消息数量
+func (e *executor[Message]) GetDroppedCount() int64 {
+	return e.dropped.Load()
+}
+
+// IsBroken 判断写循环是否已由于 ErrorPolicyClose 策略而停止写入
+func (e *executor[Message]) IsBroken() bool {
+	return e.closed.Load()
+}
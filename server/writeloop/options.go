@@ -0,0 +1,52 @@
+package writeloop
+
+import "time"
+
+const (
+	// ErrorPolicyReport 将写入错误交由 errorHandler 处理，写循环将继续运行，这是写循环的默认策略
+	ErrorPolicyReport ErrorPolicy = iota
+	// ErrorPolicyDrop 丢弃写入失败的消息，写循环将继续运行，错误不会交由 errorHandler 处理
+	ErrorPolicyDrop
+	// ErrorPolicyClose 将写入错误交由 errorHandler 处理后关闭写循环，此后的消息将被丢弃，实现了 Completer 的消息将以 ErrWriteLoopBroken 完成
+	ErrorPolicyClose
+)
+
+// ErrorPolicy 写入失败且重试次数耗尽后的处理策略
+type ErrorPolicy byte
+
+// Option 写循环选项
+type Option func(opt *options)
+
+type options struct {
+	retry   int           // 写入失败后的重试次数
+	backoff time.Duration // 首次重试前的等待时间
+	policy  ErrorPolicy   // 写入失败的处理策略
+	group   string        // 写循环协程所属的协程组名称
+}
+
+// WithRetry 设置写入失败后的重试次数及首次重试前的等待时间，此后每次重试的等待时间将翻倍，当 backoff <= 0 时将立即重试
+//   - 重试将在写循环协程中进行，期间后续的消息将等待写入
+//   - 重试时将使用同一消息再次调用 writeHandler，因此 writeHandler 需要能够重复处理同一消息，对于仅需执行一次的操作，可在消息中记录执行状态
+//   - 写入回调应通过 Completer 实现，而不是在 writeHandler 中调用，以确保回调仅在最终结果确定后被调用一次
+//   - 通过 Permanent 包装的错误将不会被重试
+func WithRetry(times int, backoff time.Duration) Option {
+	return func(opt *options) {
+		opt.retry = times
+		opt.backoff = backoff
+	}
+}
+
+// WithErrorPolicy 设置写入失败且重试次数耗尽后的处理策略，默认为 ErrorPolicyReport
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(opt *options) {
+		opt.policy = policy
+	}
+}
+
+// WithRoutineGroup 设置写循环协程所属的协程组名称，默认为 RoutineGroup
+//   - 可通过为不同的使用方设置不同的协程组，以便通过 routines.Group.Stop 单独等待其写循环结束
+func WithRoutineGroup(name string) Option {
+	return func(opt *options) {
+		opt.group = name
+	}
+}
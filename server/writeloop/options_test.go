@@ -0,0 +1,184 @@
+package writeloop_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server/writeloop"
+	"github.com/kercylan98/minotaur/utils/hub"
+	"github.com/kercylan98/minotaur/utils/routines"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	var attempts atomic.Int32
+	var reported atomic.Int32
+	done := make(chan struct{})
+	wl := writeloop.NewUnbounded(wp, func(message *Message) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("temporary")
+		}
+		close(done)
+		return nil
+	}, func(err any) {
+		reported.Add(1)
+	}, writeloop.WithRetry(3, time.Millisecond))
+	defer wl.Close()
+
+	wl.Put(wp.Get())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("write timeout")
+	}
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.Equal(t, int64(2), wl.GetRetriedCount())
+	assert.Equal(t, int32(0), reported.Load())
+}
+
+func TestWithErrorPolicy(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		policy   writeloop.ErrorPolicy
+		written  int32
+		reported int32
+		dropped  int64
+	}{
+		{name: "Report", policy: writeloop.ErrorPolicyReport, written: 3, reported: 3, dropped: 0},
+		{name: "Drop", policy: writeloop.ErrorPolicyDrop, written: 3, reported: 0, dropped: 3},
+		{name: "Close", policy: writeloop.ErrorPolicyClose, written: 1, reported: 1, dropped: 3},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var written, reported atomic.Int32
+			wl := writeloop.NewChannel(wp, 8, func(message *Message) error {
+				written.Add(1)
+				return errors.New("broken")
+			}, func(err any) {
+				reported.Add(1)
+			}, writeloop.WithRetry(1, 0), writeloop.WithErrorPolicy(c.policy))
+			defer wl.Close()
+
+			for i := 0; i < 3; i++ {
+				wl.Put(wp.Get())
+			}
+			deadline := time.Now().Add(time.Second)
+			for wl.GetDroppedCount()+int64(reported.Load()) < c.dropped+int64(c.reported) && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			assert.Equal(t, c.written*2, written.Load())
+			assert.Equal(t, c.reported, reported.Load())
+			assert.Equal(t, c.dropped, wl.GetDroppedCount())
+			assert.Equal(t, c.policy == writeloop.ErrorPolicyClose, wl.IsBroken())
+		})
+	}
+}
+
+type completerMessage struct {
+	done chan error
+}
+
+func (slf *completerMessage) Complete(err error) {
+	slf.done <- err
+}
+
+var cp = hub.NewObjectPool(func() *completerMessage {
+	return &completerMessage{}
+}, func(data *completerMessage) {
+	data.done = nil
+})
+
+// put 写入消息并返回接收最终写入结果的 chan，模拟 Conn.WriteSync
+func put(wl writeloop.WriteLoop[*completerMessage]) chan error {
+	message := cp.Get()
+	message.done = make(chan error, 2)
+	wl.Put(message)
+	return message.done
+}
+
+func wait(t *testing.T, done chan error) error {
+	select {
+	case err := <-done:
+		select {
+		case <-done:
+			t.Fatal("complete called more than once")
+		case <-time.After(time.Millisecond * 10):
+		}
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("complete timeout")
+		return nil
+	}
+}
+
+func TestCompleter(t *testing.T) {
+	broken := errors.New("broken")
+
+	t.Run("Retry", func(t *testing.T) {
+		var attempts atomic.Int32
+		wl := writeloop.NewChannel(cp, 8, func(message *completerMessage) error {
+			if attempts.Add(1) < 3 {
+				return broken
+			}
+			return nil
+		}, nil, writeloop.WithRetry(3, 0))
+		defer wl.Close()
+
+		assert.NoError(t, wait(t, put(wl)))
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("Permanent", func(t *testing.T) {
+		var attempts atomic.Int32
+		wl := writeloop.NewChannel(cp, 8, func(message *completerMessage) error {
+			attempts.Add(1)
+			return writeloop.Permanent(broken)
+		}, func(err any) {}, writeloop.WithRetry(3, 0))
+		defer wl.Close()
+
+		assert.Equal(t, broken, wait(t, put(wl)))
+		assert.Equal(t, int32(1), attempts.Load())
+		assert.Equal(t, int64(0), wl.GetRetriedCount())
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		var reported atomic.Int32
+		wl := writeloop.NewChannel(cp, 1, func(message *completerMessage) error {
+			if reported.Load() == 0 {
+				panic(broken)
+			}
+			return nil
+		}, func(err any) {
+			reported.Add(1)
+		}, writeloop.WithRetry(3, 0), writeloop.WithRoutineGroup(t.Name()))
+		defer wl.Close()
+
+		// 写循环在 panic 后仍需继续运行，否则后续的 Put 将被永久阻塞
+		assert.Equal(t, broken, wait(t, put(wl)))
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, wait(t, put(wl)))
+		}
+		assert.Equal(t, int32(1), reported.Load())
+		assert.Equal(t, int64(0), wl.GetRetriedCount())
+		assert.Equal(t, int64(1), routines.Named(t.Name()).Count())
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		var attempts atomic.Int32
+		wl := writeloop.NewChannel(cp, 8, func(message *completerMessage) error {
+			attempts.Add(1)
+			return broken
+		}, func(err any) {}, writeloop.WithRetry(1, 0), writeloop.WithErrorPolicy(writeloop.ErrorPolicyClose))
+		defer wl.Close()
+
+		var pending []chan error
+		for i := 0; i < 3; i++ {
+			pending = append(pending, put(wl))
+		}
+		assert.Equal(t, broken, wait(t, pending[0]))
+		for _, done := range pending[1:] {
+			assert.Equal(t, writeloop.ErrWriteLoopBroken, wait(t, done))
+		}
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+}
@@ -3,20 +3,22 @@ package writeloop
 import (
 	"github.com/kercylan98/minotaur/utils/buffer"
 	"github.com/kercylan98/minotaur/utils/hub"
-	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/routines"
 )
 
 // NewUnbounded 创建写循环
 //   - pool 用于管理 Message 对象的缓冲池，在创建 Message 对象时也应该使用该缓冲池，以便复用 Message 对象。 Unbounded 会在写入完成后将 Message 对象放回缓冲池
 //   - writeHandler 写入处理函数
 //   - errorHandler 错误处理函数
+//   - opts 写循环选项，可通过 WithRetry 及 WithErrorPolicy 设置写入失败后的重试次数及处理策略，通过 WithRoutineGroup 设置写循环协程所属的协程组
 //
 // 传入 writeHandler 的消息对象是从 pool 中获取的，并且在 writeHandler 执行完成后会被放回 pool 中，因此 writeHandler 不应该持有消息对象的引用，同时也不应该主动释放消息对象
-func NewUnbounded[Message any](pool *hub.ObjectPool[Message], writeHandler func(message Message) error, errorHandler func(err any)) *Unbounded[Message] {
+func NewUnbounded[Message any](pool *hub.ObjectPool[Message], writeHandler func(message Message) error, errorHandler func(err any), opts ...Option) *Unbounded[Message] {
 	wl := &Unbounded[Message]{
-		buf: buffer.NewUnbounded[Message](),
+		buf:      buffer.NewUnbounded[Message](),
+		executor: newExecutor("Unbounded", pool, writeHandler, errorHandler, opts...),
 	}
-	go func() {
+	routines.Named(wl.group).Go(func() {
 		for {
 			select {
 			case message, ok := <-wl.buf.Get():
@@ -25,18 +27,10 @@ func NewUnbounded[Message any](pool *hub.ObjectPool[Message], writeHandler func(
 				}
 				wl.buf.Load()
 
-				err := writeHandler(message)
-				pool.Release(message)
-				if err != nil {
-					if errorHandler == nil {
-						log.Error("Unbounded", log.Err(err))
-						continue
-					}
-					errorHandler(err)
-				}
+				wl.exec(message)
 			}
 		}
-	}()
+	})
 
 	return wl
 }
@@ -44,6 +38,7 @@ func NewUnbounded[Message any](pool *hub.ObjectPool[Message], writeHandler func(
 // Unbounded 写循环
 //   - 用于将数据并发安全的写入到底层连接
 type Unbounded[Message any] struct {
+	*executor[Message]
 	buf *buffer.Unbounded[Message]
 }
 
@@ -1,6 +1,16 @@
 package writeloop
 
+// RoutineGroup 写循环协程默认所属的协程组名称，可通过 routines.Named(RoutineGroup) 获取运行中的写循环协程数量
+const RoutineGroup = "writeloop"
+
 type WriteLoop[Message any] interface {
 	Put(message Message)
 	Close()
 }
+
+// Completer 当消息实现了该接口时，写循环将在消息写入成功、重试耗尽或被丢弃后调用一次 Complete，适用于在消息中携带写入回调的场景
+//   - 重试时不会调用 Complete，err 为最终的写入结果，写循环已通过 ErrorPolicyClose 停止写入时为 ErrWriteLoopBroken
+//   - Complete 将在消息被放回缓冲池之前于写循环协程中调用
+type Completer interface {
+	Complete(err error)
+}
@@ -5,4 +5,6 @@ type Broker[I, T comparable] interface {
 	Run()
 	Close()
 	Publish(topic T, event Event[I, T]) error
+	// PublishPriority 将高优先级消息分发到特定 topic，高优先级消息将先于该 topic 所在队列中的普通消息处理
+	PublishPriority(topic T, event Event[I, T]) error
 }
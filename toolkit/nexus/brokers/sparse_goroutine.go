@@ -22,17 +22,26 @@ type (
 )
 
 func NewSparseGoroutine[I, T comparable](queueFactory func(index int) nexus.Queue[I, T], handler SparseGoroutineMessageHandler) nexus.Broker[I, T] {
+	return NewSparseGoroutineWithQueueNum(runtime.NumCPU(), queueFactory, handler)
+}
+
+// NewSparseGoroutineWithQueueNum 创建特定队列数量的 SparseGoroutine，当 num <= 0 时将使用 runtime.NumCPU() 作为队列数量
+func NewSparseGoroutineWithQueueNum[I, T comparable](num int, queueFactory func(index int) nexus.Queue[I, T], handler SparseGoroutineMessageHandler) nexus.Broker[I, T] {
+	if num <= 0 {
+		num = runtime.NumCPU()
+	}
 	s := &SparseGoroutine[I, T]{
 		lb:           loadbalancer.NewRoundRobin[I, nexus.Queue[I, T]](),
 		queues:       make(map[I]nexus.Queue[I, T]),
 		state:        sparseGoroutineStatusNone,
 		location:     make(map[T]I),
+		migrations:   make(map[T]*sparseGoroutineMigration[I, T]),
+		publishing:   make(map[T]int),
 		handler:      handler,
 		queueFactory: queueFactory,
 	}
-	defaultNum := runtime.NumCPU()
 	s.queueRW.Lock()
-	for i := 0; i < defaultNum; i++ {
+	for i := 0; i < num; i++ {
 		queue := s.queueFactory(i)
 		s.lb.Add(queue) // 运行前添加到负载均衡器，未运行时允许接收消息
 		queueId := queue.GetId()
@@ -57,6 +66,9 @@ type SparseGoroutine[I, T comparable] struct {
 	lb              *loadbalancer.RoundRobin[I, nexus.Queue[I, T]] // 负载均衡器
 	wg              sync.WaitGroup                                 // 等待组
 	handler         SparseGoroutineMessageHandler                  // 消息处理器
+	metrics         sparseGoroutineMetrics[I]                      // 运行指标
+	migrations      map[T]*sparseGoroutineMigration[I, T]          // 迁移中的 topic，受 locationRW 保护
+	publishing      map[T]int                                      // topic 正在写入队列的消息数量，受 locationRW 保护
 
 	queueFactory func(index int) nexus.Queue[I, T]
 }
@@ -75,6 +87,7 @@ func (s *SparseGoroutine[I, T]) Run() {
 		go func(r *SparseGoroutine[I, T], queue nexus.Queue[I, T]) {
 			defer r.wg.Done()
 			for h := range queue.Consume() {
+				s.metrics.processed.Add(1)
 				h.Exec(
 					// onProcess
 					func(topic T, event nexus.EventExecutor) {
@@ -87,7 +100,11 @@ func (s *SparseGoroutine[I, T]) Run() {
 						}
 						s.locationRW.Lock()
 						defer s.locationRW.Unlock()
-						delete(s.location, topic)
+						// topic 迁移后将位于其他队列，迁移中或存在正在写入队列的消息时同样不应移除其所在队列
+						_, migrating := s.migrations[topic]
+						if i, exist := s.location[topic]; exist && i == queue.GetId() && !migrating && s.publishing[topic] == 0 {
+							delete(s.location, topic)
+						}
 					},
 				)
 			}
@@ -101,10 +118,17 @@ func (s *SparseGoroutine[I, T]) Close() {
 	if !atomic.CompareAndSwapInt32(&s.state, sparseGoroutineStatusRunning, sparseGoroutineStatusClosing) {
 		return
 	}
-	s.queueRW.Lock()
-	var wg sync.WaitGroup
-	wg.Add(len(s.queues))
+	// 关闭期间不持有队列锁，以便异步消息的回调仍可发布至队列中，避免队列等待计数归零时发生死锁
+	s.queueRW.RLock()
+	var queues = make([]nexus.Queue[I, T], 0, len(s.queues))
 	for _, queue := range s.queues {
+		queues = append(queues, queue)
+	}
+	s.queueRW.RUnlock()
+
+	var wg sync.WaitGroup
+	wg.Add(len(queues))
+	for _, queue := range queues {
 		go func(queue nexus.Queue[I, T]) {
 			defer wg.Done()
 			queue.Close()
@@ -112,36 +136,57 @@ func (s *SparseGoroutine[I, T]) Close() {
 	}
 	wg.Wait()
 	atomic.StoreInt32(&s.state, sparseGoroutineStatusClosed)
-	s.queueRW.Unlock()
 }
 
 // Publish 将消息分发到特定 topic，当 topic 首次使用时，将会根据负载均衡策略选择一个队列
 //   - 设置 count 会增加消息的外部计数，当 SparseGoroutine 关闭时会等待外部计数归零
 func (s *SparseGoroutine[I, T]) Publish(topic T, event nexus.Event[I, T]) error {
+	return s.publish(topic, event, false)
+}
+
+// PublishPriority 将高优先级消息分发到特定 topic，高优先级消息将先于该 topic 所在队列中尚未开始处理的普通消息处理
+//   - 适用于系统级 topic 等不应被繁忙的 topic 阻塞的场景，同一 topic 中高优先级消息与普通消息之间将不再保证顺序
+func (s *SparseGoroutine[I, T]) PublishPriority(topic T, event nexus.Event[I, T]) error {
+	return s.publish(topic, event, true)
+}
+
+func (s *SparseGoroutine[I, T]) publish(topic T, event nexus.Event[I, T], priority bool) error {
 	s.queueRW.RLock()
 	if atomic.LoadInt32(&s.state) > sparseGoroutineStatusClosing {
 		s.queueRW.RUnlock()
 		return fmt.Errorf("broker closing or closed")
 	}
+	event.OnInitialize(context.Background(), s)
 
+	// 确定队列的同时记录 topic 正在写入队列的消息，写入队列期间不持有锁，避免队列阻塞写入时影响其他 topic 的发布及迁移
 	var next nexus.Queue[I, T]
-	s.locationRW.RLock()
-	i, exist := s.location[topic]
-	s.locationRW.RUnlock()
-	if !exist {
-		s.locationRW.Lock()
-		if i, exist = s.location[topic]; !exist {
-			next = s.lb.Next()
-			s.location[topic] = next.GetId()
-		} else {
-			next = s.queues[i]
-		}
+	s.locationRW.Lock()
+	if migration, migrating := s.migrations[topic]; migrating {
+		migration.held = append(migration.held, sparseGoroutineHeldEvent[I, T]{event: event, priority: priority})
 		s.locationRW.Unlock()
+		s.queueRW.RUnlock()
+		return nil
+	}
+	if i, exist := s.location[topic]; !exist {
+		next = s.lb.Next()
+		s.location[topic] = next.GetId()
 	} else {
 		next = s.queues[i]
 	}
+	s.publishing[topic]++
+	s.locationRW.Unlock()
 	s.queueRW.RUnlock()
 
-	event.OnInitialize(context.Background(), s)
-	return next.Publish(topic, event)
+	var err error
+	if priority {
+		err = next.PublishPriority(topic, event)
+	} else {
+		err = next.Publish(topic, event)
+	}
+	s.published(topic)
+	if err != nil {
+		return err
+	}
+	s.checkThreshold(next)
+	return nil
 }
@@ -0,0 +1,133 @@
+package brokers
+
+import (
+	"github.com/kercylan98/minotaur/toolkit/nexus"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SparseGoroutineThresholdHandler 队列中未处理完毕的消息数量超过阈值时的处理函数
+type SparseGoroutineThresholdHandler[I comparable] func(queue I, count int64)
+
+type sparseGoroutineMetrics[I comparable] struct {
+	processed atomic.Int64 // 已开始处理的消息数量
+
+	rateLock     sync.Mutex
+	rate         float64   // 最近一次采样的处理速率
+	rateSampleAt time.Time // 最近一次采样的时间
+	rateSample   int64     // 最近一次采样时已处理的消息数量
+
+	thresholdLock    sync.RWMutex
+	threshold        int64                              // 队列消息数量阈值
+	thresholdHandler SparseGoroutineThresholdHandler[I] // 超过阈值时的处理函数
+	exceeded         map[I]bool                         // 处于超过阈值状态的队列
+}
+
+// GetTopicCount 获取当前活跃的 Topic 数量，即存在未处理完毕消息的 Topic 数量
+func (s *SparseGoroutine[I, T]) GetTopicCount() int {
+	s.locationRW.RLock()
+	defer s.locationRW.RUnlock()
+	return len(s.location)
+}
+
+// GetTopicMessageCount 获取特定 Topic 未处理完毕的消息数量
+func (s *SparseGoroutine[I, T]) GetTopicMessageCount(topic T) int64 {
+	s.locationRW.RLock()
+	i, exist := s.location[topic]
+	s.locationRW.RUnlock()
+	if !exist {
+		return 0
+	}
+	s.queueRW.RLock()
+	queue := s.queues[i]
+	s.queueRW.RUnlock()
+	return queue.GetTopicMessageCount(topic)
+}
+
+// GetQueueMessageCount 获取每个队列未处理完毕的消息数量
+func (s *SparseGoroutine[I, T]) GetQueueMessageCount() map[I]int64 {
+	s.queueRW.RLock()
+	defer s.queueRW.RUnlock()
+	counts := make(map[I]int64, len(s.queues))
+	for id, queue := range s.queues {
+		counts[id] = queue.GetMessageCount()
+	}
+	return counts
+}
+
+// GetMessageCount 获取所有队列未处理完毕的消息总数
+func (s *SparseGoroutine[I, T]) GetMessageCount() int64 {
+	var total int64
+	for _, count := range s.GetQueueMessageCount() {
+		total += count
+	}
+	return total
+}
+
+// GetProcessedCount 获取自运行以来已开始处理的消息数量
+func (s *SparseGoroutine[I, T]) GetProcessedCount() int64 {
+	return s.metrics.processed.Load()
+}
+
+// GetProcessRate 获取每秒处理的消息数量
+//   - 速率以相邻两次调用之间的处理数量计算，两次调用间隔不足 1 秒时将返回上一次计算的结果，首次调用将返回 0
+func (s *SparseGoroutine[I, T]) GetProcessRate() float64 {
+	m := &s.metrics
+	m.rateLock.Lock()
+	defer m.rateLock.Unlock()
+	now, processed := time.Now(), m.processed.Load()
+	if m.rateSampleAt.IsZero() {
+		m.rateSampleAt, m.rateSample = now, processed
+		return 0
+	}
+	if elapsed := now.Sub(m.rateSampleAt); elapsed >= time.Second {
+		m.rate = float64(processed-m.rateSample) / elapsed.Seconds()
+		m.rateSampleAt, m.rateSample = now, processed
+	}
+	return m.rate
+}
+
+// SetQueueThreshold 设置队列消息数量阈值，当发布消息后队列中未处理完毕的消息数量超过 threshold 时将调用 handler
+//   - 队列超过阈值后，直到消息数量回落至阈值以内前不会重复调用 handler
+//   - handler 将在发布消息的协程中执行，不应执行阻塞操作
+//   - 当 threshold <= 0 或 handler 为 nil 时将取消阈值检查
+func (s *SparseGoroutine[I, T]) SetQueueThreshold(threshold int64, handler SparseGoroutineThresholdHandler[I]) {
+	m := &s.metrics
+	m.thresholdLock.Lock()
+	defer m.thresholdLock.Unlock()
+	if threshold <= 0 || handler == nil {
+		m.threshold, m.thresholdHandler, m.exceeded = 0, nil, nil
+		return
+	}
+	m.threshold, m.thresholdHandler, m.exceeded = threshold, handler, make(map[I]bool)
+}
+
+// checkThreshold 检查队列的消息数量是否超过阈值
+func (s *SparseGoroutine[I, T]) checkThreshold(queue nexus.Queue[I, T]) {
+	m := &s.metrics
+	m.thresholdLock.RLock()
+	handler, threshold := m.thresholdHandler, m.threshold
+	m.thresholdLock.RUnlock()
+	if handler == nil {
+		return
+	}
+
+	id, count := queue.GetId(), queue.GetMessageCount()
+	m.thresholdLock.Lock()
+	if m.exceeded == nil {
+		m.thresholdLock.Unlock()
+		return
+	}
+	exceeded := count > threshold
+	notify := exceeded && !m.exceeded[id]
+	if exceeded {
+		m.exceeded[id] = true
+	} else {
+		delete(m.exceeded, id)
+	}
+	m.thresholdLock.Unlock()
+	if notify {
+		handler(id, count)
+	}
+}
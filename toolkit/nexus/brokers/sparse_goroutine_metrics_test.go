@@ -0,0 +1,85 @@
+package brokers_test
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSparseGoroutine_Metrics(t *testing.T) {
+	broker := newSparseGoroutine(t, 1)
+	rec := newRecorder()
+
+	var lock sync.Mutex
+	var notified []int64
+	broker.SetQueueThreshold(3, func(queue int, count int64) {
+		lock.Lock()
+		notified = append(notified, count)
+		lock.Unlock()
+	})
+
+	// 首条消息阻塞队列，使后续的消息均处于未处理完毕的状态
+	gate := make(chan struct{})
+	for i, topic := range []string{"a", "a", "a", "b", "b"} {
+		var g chan struct{}
+		if i == 0 {
+			g = gate
+		}
+		if err := broker.Publish(topic, rec.event(topic, g)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if count := broker.GetTopicCount(); count != 2 {
+		t.Fatalf("unexpected topic count: %d", count)
+	}
+	if count := broker.GetTopicMessageCount("a"); count != 3 {
+		t.Fatalf("unexpected topic message count: %d", count)
+	}
+	if counts := broker.GetQueueMessageCount(); counts[0] != 5 {
+		t.Fatalf("unexpected queue message count: %v", counts)
+	}
+	if count := broker.GetMessageCount(); count != 5 {
+		t.Fatalf("unexpected message count: %d", count)
+	}
+	if rate := broker.GetProcessRate(); rate != 0 {
+		t.Fatalf("first process rate should be 0, got: %f", rate)
+	}
+
+	// 超过阈值后，直到消息数量回落至阈值以内前仅通知一次
+	lock.Lock()
+	if len(notified) != 1 || notified[0] != 4 {
+		t.Fatalf("unexpected threshold notify: %v", notified)
+	}
+	lock.Unlock()
+
+	close(gate)
+	rec.wait(t, 5)
+	if count := broker.GetProcessedCount(); count != 5 {
+		t.Fatalf("unexpected processed count: %d", count)
+	}
+	if count := broker.GetMessageCount(); count != 0 {
+		t.Fatalf("unexpected message count after processed: %d", count)
+	}
+	if count := broker.GetTopicMessageCount("a"); count != 0 {
+		t.Fatalf("unexpected topic message count after processed: %d", count)
+	}
+
+	// 消息数量回落后再次超过阈值时将重新通知
+	gate = make(chan struct{})
+	for i := 0; i < 4; i++ {
+		var g chan struct{}
+		if i == 0 {
+			g = gate
+		}
+		if err := broker.Publish("c", rec.event("c", g)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lock.Lock()
+	if len(notified) != 2 || notified[1] != 4 {
+		t.Fatalf("unexpected threshold notify: %v", notified)
+	}
+	lock.Unlock()
+	close(gate)
+	rec.wait(t, 4)
+}
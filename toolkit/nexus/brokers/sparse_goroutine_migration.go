@@ -0,0 +1,157 @@
+package brokers
+
+import (
+	"context"
+	"errors"
+	"github.com/kercylan98/minotaur/toolkit/nexus"
+	"github.com/kercylan98/minotaur/toolkit/nexus/events"
+	"github.com/kercylan98/minotaur/toolkit/nexus/queues"
+)
+
+var (
+	ErrQueueNotFound  = errors.New("queue not found")    // 队列不存在
+	ErrTopicMigrating = errors.New("topic is migrating") // topic 正在迁移中
+)
+
+// sparseGoroutineMigration 迁移中的 topic 信息
+type sparseGoroutineMigration[I, T comparable] struct {
+	source nexus.Queue[I, T]                // 迁移的原队列
+	target nexus.Queue[I, T]                // 迁移的目标队列
+	held   []sparseGoroutineHeldEvent[I, T] // 迁移期间暂存的消息
+	marked bool                             // 是否已在原队列中发布标记消息
+}
+
+type sparseGoroutineHeldEvent[I, T comparable] struct {
+	event    nexus.Event[I, T]
+	priority bool
+}
+
+// GetTopicQueue 获取 topic 当前所在的队列 Id，当 topic 不存在未处理完毕的消息时将返回 false
+func (s *SparseGoroutine[I, T]) GetTopicQueue(topic T) (queue I, exist bool) {
+	s.locationRW.RLock()
+	defer s.locationRW.RUnlock()
+	queue, exist = s.location[topic]
+	return
+}
+
+// GetQueueIds 获取所有队列的 Id
+func (s *SparseGoroutine[I, T]) GetQueueIds() []I {
+	s.queueRW.RLock()
+	defer s.queueRW.RUnlock()
+	ids := make([]I, 0, len(s.queues))
+	for id := range s.queues {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Migrate 将 topic 迁移至特定队列，适用于将长期繁忙的 topic 在队列之间重新平衡
+//   - 迁移仅作用于此后发布的消息，topic 在原队列中已发布的消息仍将在原队列中处理，不会被移动至目标队列
+//   - 迁移期间发布的消息将被暂存，直到 topic 在原队列中已发布的消息全部处理完毕后再按照发布顺序发布至目标队列，因此迁移不会破坏 topic 的消息顺序
+//   - 暂存的消息无法发布至目标队列时将按照目标队列的溢出策略处理，当目标队列已关闭时，暂存的消息将回退至原队列，topic 也将继续位于原队列
+//   - 当 topic 不存在未处理完毕的消息时，将直接绑定至目标队列
+//   - 当目标队列不存在时将返回 ErrQueueNotFound，当 topic 正在迁移中时将返回 ErrTopicMigrating
+func (s *SparseGoroutine[I, T]) Migrate(topic T, queue I) error {
+	s.queueRW.RLock()
+	target, exist := s.queues[queue]
+	if !exist {
+		s.queueRW.RUnlock()
+		return ErrQueueNotFound
+	}
+
+	s.locationRW.Lock()
+	if _, migrating := s.migrations[topic]; migrating {
+		s.locationRW.Unlock()
+		s.queueRW.RUnlock()
+		return ErrTopicMigrating
+	}
+	current, exist := s.location[topic]
+	s.location[topic] = queue
+	if !exist || current == queue {
+		s.locationRW.Unlock()
+		s.queueRW.RUnlock()
+		return nil
+	}
+	// 此后发布的消息均会被暂存，当存在正在写入原队列的消息时，标记消息将在其写入完毕后发布
+	migration := &sparseGoroutineMigration[I, T]{source: s.queues[current], target: target}
+	migration.marked = s.publishing[topic] == 0
+	s.migrations[topic] = migration
+	s.locationRW.Unlock()
+	s.queueRW.RUnlock()
+
+	if migration.marked {
+		s.mark(topic, migration.source)
+	}
+	return nil
+}
+
+// published 结束 topic 正在写入队列的消息的记录，当 topic 正在迁移且不再存在正在写入的消息时，将发布迁移的标记消息
+func (s *SparseGoroutine[I, T]) published(topic T) {
+	s.locationRW.Lock()
+	if s.publishing[topic]--; s.publishing[topic] > 0 {
+		s.locationRW.Unlock()
+		return
+	}
+	delete(s.publishing, topic)
+	migration, migrating := s.migrations[topic]
+	mark := migrating && !migration.marked
+	if mark {
+		migration.marked = true
+	}
+	s.locationRW.Unlock()
+
+	if mark {
+		s.mark(topic, migration.source)
+	}
+}
+
+// mark 在原队列中发布一条标记消息，当标记消息被处理时，topic 在原队列中已发布的消息均已处理完毕
+func (s *SparseGoroutine[I, T]) mark(topic T, source nexus.Queue[I, T]) {
+	marker := events.Synchronous[I, T](func(ctx context.Context) {
+		s.completeMigration(topic)
+	})
+	marker.OnInitialize(context.Background(), s)
+	if err := source.Publish(topic, marker); err != nil {
+		s.completeMigration(topic)
+	}
+}
+
+// completeMigration 将迁移期间暂存的消息按顺序发布至目标队列并结束迁移，当目标队列已关闭时将回退至原队列
+func (s *SparseGoroutine[I, T]) completeMigration(topic T) {
+	for {
+		s.locationRW.Lock()
+		migration := s.migrations[topic]
+		held := migration.held
+		migration.held = nil
+		if len(held) == 0 {
+			delete(s.migrations, topic)
+			s.locationRW.Unlock()
+			return
+		}
+		target := migration.target
+		s.locationRW.Unlock()
+
+		for _, h := range held {
+			err := s.publishHeld(target, topic, h)
+			if errors.Is(err, queues.ErrorQueueClosed) && target != migration.source {
+				// 目标队列关闭时其中的消息均已处理完毕，回退至原队列不会破坏消息顺序
+				s.locationRW.Lock()
+				target, migration.target = migration.source, migration.source
+				s.location[topic] = target.GetId()
+				s.locationRW.Unlock()
+				err = s.publishHeld(target, topic, h)
+			}
+			if err == nil {
+				s.checkThreshold(target)
+			}
+		}
+	}
+}
+
+// publishHeld 将暂存的消息发布至队列
+func (s *SparseGoroutine[I, T]) publishHeld(queue nexus.Queue[I, T], topic T, h sparseGoroutineHeldEvent[I, T]) error {
+	if h.priority {
+		return queue.PublishPriority(topic, h.event)
+	}
+	return queue.Publish(topic, h.event)
+}
@@ -0,0 +1,165 @@
+package brokers_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/toolkit/nexus/brokers"
+	"github.com/kercylan98/minotaur/toolkit/nexus/queues"
+	"testing"
+	"time"
+)
+
+func TestSparseGoroutine_Migrate(t *testing.T) {
+	broker := newSparseGoroutine(t, 2)
+	rec := newRecorder()
+
+	// 首条消息阻塞原队列，使迁移开始时 topic 在原队列中仍存在未处理完毕的消息
+	gate := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		var g chan struct{}
+		if i == 0 {
+			g = gate
+		}
+		if err := broker.Publish("topic", rec.event(fmt.Sprint(i), g)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source, _ := broker.GetTopicQueue("topic")
+	target := 1 - source
+
+	if err := broker.Migrate("topic", 2); err != brokers.ErrQueueNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := broker.Migrate("topic", target); err != nil {
+		t.Fatal(err)
+	}
+	if err := broker.Migrate("topic", source); err != brokers.ErrTopicMigrating {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queue, _ := broker.GetTopicQueue("topic"); queue != target {
+		t.Fatalf("topic should be located at target queue %d, got: %d", target, queue)
+	}
+
+	// 迁移期间发布的消息将在原队列中的消息处理完毕后于目标队列中处理
+	for i := 10; i < 20; i++ {
+		if err := broker.Publish("topic", rec.event(fmt.Sprint(i), nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(gate)
+	rec.wait(t, 20)
+	assertMigrated(t, rec, 10, source, target)
+}
+
+func TestSparseGoroutine_MigrateIdle(t *testing.T) {
+	broker := newSparseGoroutine(t, 2)
+	rec := newRecorder()
+
+	// topic 不存在未处理完毕的消息时将直接绑定至目标队列
+	for _, target := range []int{0, 1} {
+		if err := broker.Migrate("topic", target); err != nil {
+			t.Fatal(err)
+		}
+		if err := broker.Publish("topic", rec.event(fmt.Sprint(target), nil)); err != nil {
+			t.Fatal(err)
+		}
+		rec.wait(t, 1)
+		rec.lock.Lock()
+		queue := rec.queues[len(rec.queues)-1]
+		rec.lock.Unlock()
+		if queue != target {
+			t.Fatalf("message processed in queue %d, expected: %d", queue, target)
+		}
+	}
+}
+
+// assertMigrated 检查消息按照名称的顺序处理，且前 n 条消息在 source 队列中处理，其余消息在 target 队列中处理
+func assertMigrated(t *testing.T, rec *recorder, n, source, target int) {
+	t.Helper()
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+	for i, name := range rec.names {
+		if name != fmt.Sprint(i) {
+			t.Fatalf("message order broken: %v", rec.names)
+		}
+		if expected := map[bool]int{true: source, false: target}[i < n]; rec.queues[i] != expected {
+			t.Fatalf("message %s processed in queue %d, expected: %d", name, rec.queues[i], expected)
+		}
+	}
+}
+
+func TestSparseGoroutine_MigrateBlocking(t *testing.T) {
+	broker, qs := newSparseGoroutineWithQueues(t, 2, 0)
+	for _, queue := range qs {
+		queue.SetOverflowPolicy(1, queues.OverflowPolicyBlock, 0)
+	}
+	rec := newRecorder()
+
+	// 首条消息阻塞原队列，第二条消息等待进入读取通道，第三条消息占满缓冲区，使第四条消息阻塞在写入原队列的位置
+	gate := make(chan struct{})
+	if err := broker.Publish("topic", rec.event("0", gate)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < 3; i++ {
+		if err := broker.Publish("topic", rec.event(fmt.Sprint(i), nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	go func() {
+		if err := broker.Publish("topic", rec.event("3", nil)); err != nil {
+			t.Error(err)
+		}
+	}()
+	time.Sleep(time.Millisecond * 100)
+	source, _ := broker.GetTopicQueue("topic")
+	target := 1 - source
+
+	// 迁移及此后的发布不应等待阻塞中的写入
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := broker.Migrate("topic", target); err != nil {
+			t.Error(err)
+		}
+		if err := broker.Publish("topic", rec.event("4", nil)); err != nil {
+			t.Error(err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("migrate blocked by pending publish")
+	}
+
+	close(gate)
+	rec.wait(t, 5)
+	assertMigrated(t, rec, 4, source, target)
+}
+
+func TestSparseGoroutine_MigrateTargetClosed(t *testing.T) {
+	broker, qs := newSparseGoroutineWithQueues(t, 2, 1)
+	rec := newRecorder()
+
+	gate := make(chan struct{})
+	if err := broker.Publish("topic", rec.event("0", gate)); err != nil {
+		t.Fatal(err)
+	}
+	source, _ := broker.GetTopicQueue("topic")
+	target := 1 - source
+	if err := broker.Migrate("topic", target); err != nil {
+		t.Fatal(err)
+	}
+
+	// 目标队列关闭后，暂存的消息将回退至原队列，而不会丢失
+	qs[target].Close()
+	for i := 1; i < 4; i++ {
+		if err := broker.Publish("topic", rec.event(fmt.Sprint(i), nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(gate)
+	rec.wait(t, 4)
+	assertMigrated(t, rec, 4, source, target)
+	if queue, exist := broker.GetTopicQueue("topic"); exist && queue != source {
+		t.Fatalf("topic should be located at source queue %d, got: %d", source, queue)
+	}
+}
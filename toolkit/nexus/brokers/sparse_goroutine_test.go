@@ -0,0 +1,97 @@
+package brokers_test
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/toolkit/nexus"
+	"github.com/kercylan98/minotaur/toolkit/nexus/brokers"
+	"github.com/kercylan98/minotaur/toolkit/nexus/queues"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder 按处理顺序记录消息名称及处理消息的队列
+type recorder struct {
+	lock   sync.Mutex
+	names  []string
+	queues []int
+	done   chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{done: make(chan struct{}, 1024)}
+}
+
+// event 创建一条消息，当 gate 不为 nil 时，消息将在 gate 关闭后才处理完毕
+func (r *recorder) event(name string, gate chan struct{}) nexus.Event[int, string] {
+	return &testEvent{name: name, gate: gate, recorder: r}
+}
+
+// wait 等待 n 条消息处理完毕
+func (r *recorder) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.done:
+		case <-time.After(time.Second):
+			t.Fatalf("wait timeout, processed: %v", r.processed())
+		}
+	}
+}
+
+func (r *recorder) processed() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string(nil), r.names...)
+}
+
+type testEvent struct {
+	name     string
+	gate     chan struct{}
+	recorder *recorder
+}
+
+func (e *testEvent) OnInitialize(ctx context.Context, broker nexus.Broker[int, string]) {}
+
+func (e *testEvent) OnPublished(topic string, queue nexus.Queue[int, string]) {}
+
+func (e *testEvent) OnProcess(topic string, queue nexus.Queue[int, string], startAt time.Time) {
+	if e.gate != nil {
+		<-e.gate
+	}
+	e.recorder.lock.Lock()
+	e.recorder.names = append(e.recorder.names, e.name)
+	e.recorder.queues = append(e.recorder.queues, queue.GetId())
+	e.recorder.lock.Unlock()
+}
+
+func (e *testEvent) OnProcessed(topic string, queue nexus.Queue[int, string], endAt time.Time) {
+	e.recorder.done <- struct{}{}
+}
+
+// newSparseGoroutine 创建并运行包含 num 个队列的 SparseGoroutine
+func newSparseGoroutine(t *testing.T, num int) *brokers.SparseGoroutine[int, string] {
+	t.Helper()
+	broker, _ := newSparseGoroutineWithQueues(t, num, 1)
+	return broker
+}
+
+// newSparseGoroutineWithQueues 创建并运行包含 num 个读取通道大小为 chanSize 的队列的 SparseGoroutine，返回的队列以 Id 作为索引
+func newSparseGoroutineWithQueues(t *testing.T, num, chanSize int) (*brokers.SparseGoroutine[int, string], []*queues.NonBlockingRW[int, string]) {
+	t.Helper()
+	var qs = make([]*queues.NonBlockingRW[int, string], num)
+	broker := brokers.NewSparseGoroutineWithQueueNum(num, func(index int) nexus.Queue[int, string] {
+		qs[index] = queues.NewNonBlockingRW[int, string](index, chanSize, 16).(*queues.NonBlockingRW[int, string])
+		return qs[index]
+	}, func(handler nexus.EventExecutor) {
+		handler()
+	}).(*brokers.SparseGoroutine[int, string])
+	go broker.Run()
+	t.Cleanup(broker.Close)
+	for _, queue := range qs {
+		for !queue.IsRunning() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return broker, qs
+}
@@ -5,8 +5,14 @@ type Queue[I, T comparable] interface {
 	GetId() I
 	// Publish 向队列中推送消息
 	Publish(topic T, event Event[I, T]) error
+	// PublishPriority 向队列中推送高优先级消息，高优先级消息将先于普通消息处理
+	PublishPriority(topic T, event Event[I, T]) error
 	// IncrementCustomMessageCount 增加自定义消息计数
 	IncrementCustomMessageCount(topic T, delta int64)
+	// GetMessageCount 获取队列中未处理完毕的消息数量
+	GetMessageCount() int64
+	// GetTopicMessageCount 获取队列中特定主题未处理完毕的消息数量
+	GetTopicMessageCount(topic T) int64
 	// Run 运行队列
 	Run()
 	// Consume 消费消息
@@ -45,10 +45,15 @@ func NewNonBlockingRW[I, T comparable](id I, chanSize, bufferSize int) nexus.Que
 		status: NonBlockingRWStatusNone,
 		c:      make(chan nexus.EventInfo[I, T], chanSize),
 		buf:    buffer.NewRing[nonBlockingRWEventInfo[I, T]](bufferSize),
+		pbuf:   buffer.NewRing[nonBlockingRWEventInfo[I, T]](),
+		cs:     make(chan struct{}),
 		condRW: &sync.RWMutex{},
 		topics: make(map[T]int64),
+		spills: buffer.NewRing[T](),
+		stpc:   make(map[T]int),
 	}
 	q.cond = sync.NewCond(q.condRW)
+	q.space = sync.NewCond(q.condRW)
 	return q
 }
 
@@ -56,12 +61,25 @@ func NewNonBlockingRW[I, T comparable](id I, chanSize, bufferSize int) nexus.Que
 //   - 该队列接收自定义的消息 M，并将消息有序的传入 Read 函数所返回的 channel 中以供处理
 //   - 该结构主要实现目标为读写分离且并发安全的非阻塞传输队列，当消费阻塞时以牺牲内存为代价换取消息的生产不阻塞，适用于服务器消息处理等
 //   - 该队列保证了消息的完整性，确保消息不丢失，在队列关闭后会等待所有消息处理完毕后进行关闭，并提供 SetClosedHandler 函数来监听队列的关闭信号
+//   - 通过 PublishPriority 推送的高优先级消息将先于尚未进入读取通道的普通消息处理，读取通道越小优先级的效果越明显
 type NonBlockingRW[I, T comparable] struct {
 	id     I                                          // 队列 ID
 	status int32                                      // 状态标志
 	total  int64                                      // 消息总计数
 	topics map[T]int64                                // 主题对应的消息计数映射
 	buf    *buffer.Ring[nonBlockingRWEventInfo[I, T]] // 消息缓冲区
+	pbuf   *buffer.Ring[nonBlockingRWEventInfo[I, T]] // 高优先级消息缓冲区
+	pnum   atomic.Int64                               // 高优先级消息缓冲区中的消息数量
+	weight int                                        // 高优先级消息权重
+	limit  int                                        // 缓冲区消息数量上限
+	policy OverflowPolicy                             // 缓冲区溢出策略
+	wait   time.Duration                              // OverflowPolicyBlock 策略的超时时间
+	over   OverflowHandler[I, T]                      // 缓冲区溢出处理函数
+	spool  Spool                                      // OverflowPolicySpill 策略下暂存消息的 Spool
+	codec  SpillCodec[I, T]                           // 写入 Spool 的消息编解码器
+	spills *buffer.Ring[T]                            // 按写入顺序记录的 Spool 中消息的主题
+	stpc   map[T]int                                  // 主题对应的 Spool 中的消息数量
+	space  *sync.Cond                                 // 缓冲区可用空间的条件变量
 	c      chan nexus.EventInfo[I, T]                 // 消息读取通道
 	cs     chan struct{}                              // 关闭信号
 	cond   *sync.Cond                                 // 条件变量
@@ -79,11 +97,18 @@ func (n *NonBlockingRW[I, T]) Run() {
 		panic(ErrorQueueInvalid)
 	}
 	atomic.StoreInt32(&n.status, NonBlockingRWStatusRunning)
+	var pending []nonBlockingRWEventInfo[I, T] // 已从缓冲区读出但尚未进入读取通道的普通消息
+	var sent int                               // 自上一条普通消息以来进入读取通道的高优先级消息数量
 	for {
+		n.replay()
 		n.cond.L.Lock()
-		for n.buf.IsEmpty() {
+		for n.pbuf.IsEmpty() && n.buf.IsEmpty() && len(pending) == 0 && n.spills.IsEmpty() {
 			if atomic.LoadInt32(&n.status) >= NonBlockingRWStatusClosing && n.total == 0 {
+				spool := n.spool
 				n.cond.L.Unlock()
+				if spool != nil {
+					_ = spool.Close()
+				}
 				atomic.StoreInt32(&n.status, NonBlockingRWStatusClosed)
 				close(n.c)
 				close(n.cs)
@@ -91,11 +116,27 @@ func (n *NonBlockingRW[I, T]) Run() {
 			}
 			n.cond.Wait()
 		}
-		items := n.buf.ReadAll()
+		priorities := n.pbuf.ReadAll()
+		n.pnum.Store(0)
+		if len(pending) == 0 {
+			pending = n.buf.ReadAll()
+		}
+		weight := n.weight
+		n.space.Broadcast()
 		n.cond.L.Unlock()
-		for i := 0; i < len(items); i++ {
-			ei := &items[i]
-			n.c <- ei
+
+		for i := 0; i < len(priorities); i++ {
+			n.c <- &priorities[i]
+		}
+		sent += len(priorities)
+		for len(pending) > 0 {
+			// 存在新的高优先级消息时优先处理，当设置了权重且已连续处理足够的高优先级消息时，将先处理一条普通消息
+			if n.pnum.Load() > 0 && (weight <= 0 || sent < weight) {
+				break
+			}
+			n.c <- &pending[0]
+			pending = pending[1:]
+			sent = 0
 		}
 	}
 }
@@ -108,7 +149,6 @@ func (n *NonBlockingRW[I, T]) Consume() <-chan nexus.EventInfo[I, T] {
 // Close 关闭队列
 func (n *NonBlockingRW[I, T]) Close() {
 	if atomic.CompareAndSwapInt32(&n.status, NonBlockingRWStatusRunning, NonBlockingRWStatusClosing) {
-		n.cs = make(chan struct{})
 		n.cond.Broadcast()
 		<-n.cs
 	}
@@ -129,11 +169,213 @@ func (n *NonBlockingRW[I, T]) GetTopicMessageCount(topic T) int64 {
 }
 
 func (n *NonBlockingRW[I, T]) Publish(topic T, event nexus.Event[I, T]) error {
-	if atomic.LoadInt32(&n.status) > NonBlockingRWStatusClosing {
-		return ErrorQueueClosed
+	return n.publish(topic, event, false)
+}
+
+// PublishPriority 推送高优先级消息，高优先级消息将先于尚未进入读取通道的普通消息处理
+func (n *NonBlockingRW[I, T]) PublishPriority(topic T, event nexus.Event[I, T]) error {
+	return n.publish(topic, event, true)
+}
+
+// SetPriorityWeight 设置高优先级消息的权重，当高优先级消息与普通消息同时等待处理时，每连续处理 weight 条高优先级消息后将处理一条普通消息，以避免普通消息饥饿
+//   - 高优先级消息以批次为单位进入读取通道，因此权重是近似生效的
+//   - 当 weight <= 0 时，普通消息将始终在不存在等待处理的高优先级消息时才会被处理，默认为 0
+func (n *NonBlockingRW[I, T]) SetPriorityWeight(weight int) {
+	n.cond.L.Lock()
+	n.weight = weight
+	n.cond.L.Unlock()
+}
+
+// SetOverflowPolicy 设置缓冲区中等待进入读取通道的消息数量上限及溢出策略，默认为 OverflowPolicyExpand
+//   - 当 limit <= 0 或 policy 为 OverflowPolicyExpand 时，缓冲区将不限制消息数量
+//   - timeout 仅在 OverflowPolicyBlock 策略下生效，当 timeout <= 0 时将一直阻塞，此时不应在该队列的消费者中向该队列推送消息，否则可能发生死锁
+func (n *NonBlockingRW[I, T]) SetOverflowPolicy(limit int, policy OverflowPolicy, timeout time.Duration) {
+	n.cond.L.Lock()
+	n.limit, n.policy, n.wait = limit, policy, timeout
+	n.space.Broadcast()
+	n.cond.L.Unlock()
+}
+
+// SetOverflowHandler 设置缓冲区溢出时的处理函数，处理函数将在推送消息的协程中执行
+func (n *NonBlockingRW[I, T]) SetOverflowHandler(handler OverflowHandler[I, T]) {
+	n.cond.L.Lock()
+	n.over = handler
+	n.cond.L.Unlock()
+}
+
+// SetSpill 设置 OverflowPolicySpill 策略下暂存消息的 Spool 及消息编解码器，Spool 将在队列关闭后被关闭
+//   - 当 topic 存在尚未重放的消息时，该 topic 后续的普通消息同样会写入 Spool，以保证消息的顺序
+//   - 无法编码或写入 Spool 的消息将保留在内存中，当 topic 存在尚未重放的消息时将返回 ErrorQueueOverflow
+//   - 高优先级消息不会写入 Spool
+//   - Spool 读取失败时，所有尚未重放的消息都将丢失，并通过 SetOverflowHandler 设置的处理函数逐条通知
+func (n *NonBlockingRW[I, T]) SetSpill(spool Spool, codec SpillCodec[I, T]) {
+	n.cond.L.Lock()
+	n.spool, n.codec = spool, codec
+	n.cond.L.Unlock()
+}
+
+// GetSpillCount 获取 Spool 中尚未重放的消息数量
+func (n *NonBlockingRW[I, T]) GetSpillCount() int {
+	n.condRW.RLock()
+	defer n.condRW.RUnlock()
+	return n.spills.Len()
+}
+
+// overflow 检查缓冲区是否溢出并根据溢出策略进行处理，需要在持有锁时调用，返回的被丢弃消息需要在释放锁后通过 dropped 进行处理
+func (n *NonBlockingRW[I, T]) overflow() (dropped *nonBlockingRWEventInfo[I, T], err error) {
+	if n.limit <= 0 || n.policy == OverflowPolicyExpand || n.policy == OverflowPolicySpill {
+		return nil, nil
+	}
+	full := func() bool {
+		return n.buf.Len()+n.pbuf.Len() >= n.limit
+	}
+	if !full() {
+		return nil, nil
+	}
+	switch n.policy {
+	case OverflowPolicyBlock:
+		var expired bool
+		if n.wait > 0 {
+			timer := time.AfterFunc(n.wait, func() {
+				n.cond.L.Lock()
+				expired = true
+				n.space.Broadcast()
+				n.cond.L.Unlock()
+			})
+			defer timer.Stop()
+		}
+		for full() && n.policy == OverflowPolicyBlock && n.limit > 0 {
+			if expired || atomic.LoadInt32(&n.status) > NonBlockingRWStatusClosing {
+				return nil, ErrorQueueOverflow
+			}
+			n.space.Wait()
+		}
+		return nil, nil
+	case OverflowPolicyDropOldest:
+		ring := n.buf
+		if ring.IsEmpty() {
+			ring = n.pbuf
+		}
+		ei, _ := ring.Read()
+		if ring == n.pbuf {
+			n.pnum.Add(-1)
+		}
+		n.total--
+		if curr := n.topics[ei.topic] - 1; curr != 0 {
+			n.topics[ei.topic] = curr
+		} else {
+			delete(n.topics, ei.topic)
+		}
+		return &ei, nil
+	default:
+		return nil, ErrorQueueOverflow
 	}
+}
+
+// dropped 处理被丢弃的消息，使自定义消息计数等随消息一同被释放
+func (n *NonBlockingRW[I, T]) dropped(ei *nonBlockingRWEventInfo[I, T]) {
+	ei.event.OnProcessed(ei.topic, n, time.Now())
+}
 
-	ei := nonBlockingRWEventInfo[I, T]{
+// spill 在 OverflowPolicySpill 策略下将消息写入 Spool，需要在持有锁时调用
+func (n *NonBlockingRW[I, T]) spill(topic T, event nexus.Event[I, T], priority bool) (spilled bool, err error) {
+	if priority || n.policy != OverflowPolicySpill || n.limit <= 0 || n.spool == nil || n.codec == nil {
+		return false, nil
+	}
+	pending := n.stpc[topic] > 0
+	if !pending && n.buf.Len()+n.pbuf.Len() < n.limit {
+		return false, nil
+	}
+	data, err := n.codec.Encode(topic, event)
+	if err == nil {
+		err = n.spool.Write(data)
+	}
+	if err != nil {
+		if pending {
+			return false, ErrorQueueOverflow
+		}
+		return false, nil
+	}
+	n.spills.Write(topic)
+	n.stpc[topic]++
+	return true, nil
+}
+
+// replay 在缓冲区存在可用空间时按写入顺序将 Spool 中的消息重放至缓冲区
+func (n *NonBlockingRW[I, T]) replay() {
+	n.cond.L.Lock()
+	count := n.spills.Len()
+	if n.limit > 0 {
+		if available := n.limit - n.buf.Len() - n.pbuf.Len(); available < count {
+			count = available
+		}
+	}
+	if count <= 0 {
+		n.cond.L.Unlock()
+		return
+	}
+	codec, over := n.codec, n.over
+	topics, records, errs := make([]T, 0, count), make([][]byte, 0, count), make([]error, 0, count)
+	for i := 0; i < count; i++ {
+		topic, _ := n.spills.Read()
+		record, err := n.spool.Read()
+		topics, records, errs = append(topics, topic), append(records, record), append(errs, err)
+		if err != nil {
+			// Spool 读取失败时剩余的数据已被丢弃，需要同时清空记录的主题，避免后续写入的数据与过期的主题错位
+			for !n.spills.IsEmpty() {
+				topic, _ = n.spills.Read()
+				topics, records, errs = append(topics, topic), append(records, nil), append(errs, err)
+			}
+			break
+		}
+	}
+	count = len(topics)
+	n.cond.L.Unlock()
+
+	// 解码及 OnPublished 在释放锁后进行，期间 topic 后续的普通消息依旧会写入 Spool，因此不会破坏消息顺序
+	events := make([]nexus.Event[I, T], count)
+	for i := 0; i < count; i++ {
+		if errs[i] == nil {
+			events[i], errs[i] = codec.Decode(topics[i], records[i])
+		}
+		if errs[i] == nil {
+			events[i].OnPublished(topics[i], n)
+		}
+	}
+
+	var lost []T
+	n.cond.L.Lock()
+	for i, topic := range topics {
+		if curr := n.stpc[topic] - 1; curr > 0 {
+			n.stpc[topic] = curr
+		} else {
+			delete(n.stpc, topic)
+		}
+		if errs[i] != nil {
+			lost = append(lost, topic)
+			n.total--
+			if curr := n.topics[topic] - 1; curr != 0 {
+				n.topics[topic] = curr
+			} else {
+				delete(n.topics, topic)
+			}
+			continue
+		}
+		n.buf.Write(n.newEventInfo(topic, events[i]))
+	}
+	n.cond.Signal()
+	n.cond.L.Unlock()
+
+	if over != nil {
+		for _, topic := range lost {
+			over(n.id, topic, OverflowPolicySpill, nil)
+		}
+	}
+}
+
+// newEventInfo 创建消息的执行信息
+func (n *NonBlockingRW[I, T]) newEventInfo(topic T, event nexus.Event[I, T]) nonBlockingRWEventInfo[I, T] {
+	return nonBlockingRWEventInfo[I, T]{
 		topic: topic,
 		event: event,
 		exec: func(handler nexus.EventHandler[T], finisher nexus.EventFinisher[I, T]) {
@@ -161,16 +403,59 @@ func (n *NonBlockingRW[I, T]) Publish(topic T, event nexus.Event[I, T]) error {
 			return
 		},
 	}
+}
+
+func (n *NonBlockingRW[I, T]) publish(topic T, event nexus.Event[I, T], priority bool) error {
+	if atomic.LoadInt32(&n.status) > NonBlockingRWStatusClosing {
+		return ErrorQueueClosed
+	}
+
+	ei := n.newEventInfo(topic, event)
+
+	// 在消息写入前触发 OnPublished，避免外部计数在消息处理完毕后才增加而导致计数异常
+	event.OnPublished(topic, n)
 
 	n.cond.L.Lock()
-	n.topics[topic]++
-	n.total++
-	n.buf.Write(ei)
-	//log.Info("消息总计数", log.Int64("计数", q.state.total))
-	n.cond.Signal()
+	var dropped *nonBlockingRWEventInfo[I, T]
+	spilled, err := n.spill(topic, event, priority)
+	if !spilled && err == nil {
+		dropped, err = n.overflow()
+	}
+	over, policy := n.over, n.policy
+	if err == nil {
+		n.topics[topic]++
+		n.total++
+		switch {
+		case spilled: // 消息已写入 Spool，将在重放时重新创建
+		case priority:
+			n.pbuf.Write(ei)
+			n.pnum.Add(1)
+		default:
+			n.buf.Write(ei)
+		}
+		//log.Info("消息总计数", log.Int64("计数", q.state.total))
+		n.cond.Signal()
+	}
 	n.cond.L.Unlock()
 
-	return nil
+	if spilled {
+		// 写入 Spool 的消息将被重新解码，原消息的计数等需要随之释放
+		n.dropped(&ei)
+	}
+	if dropped != nil {
+		n.dropped(dropped)
+		if over != nil {
+			over(n.id, dropped.topic, policy, dropped.event)
+		}
+	}
+	if err != nil {
+		// 未能写入的消息同样需要释放 OnPublished 中产生的计数
+		event.OnProcessed(topic, n, time.Now())
+		if over != nil {
+			over(n.id, topic, policy, event)
+		}
+	}
+	return err
 }
 
 func (n *NonBlockingRW[I, T]) IncrementCustomMessageCount(topic T, delta int64) {
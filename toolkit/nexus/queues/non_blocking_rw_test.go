@@ -0,0 +1,138 @@
+package queues_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/toolkit/nexus"
+	"github.com/kercylan98/minotaur/toolkit/nexus/queues"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder 按处理顺序记录消息名称
+type recorder struct {
+	lock      sync.Mutex
+	names     []string
+	processed []string // 调用了 OnProcessed 的消息，包含被丢弃的消息
+}
+
+func (r *recorder) event(name string) nexus.Event[int, string] {
+	return &testEvent{name: name, recorder: r}
+}
+
+type testEvent struct {
+	name     string
+	recorder *recorder
+}
+
+func (e *testEvent) OnInitialize(ctx context.Context, broker nexus.Broker[int, string]) {}
+
+func (e *testEvent) OnPublished(topic string, queue nexus.Queue[int, string]) {}
+
+func (e *testEvent) OnProcess(topic string, queue nexus.Queue[int, string], startAt time.Time) {
+	e.recorder.lock.Lock()
+	e.recorder.names = append(e.recorder.names, e.name)
+	e.recorder.lock.Unlock()
+}
+
+func (e *testEvent) OnProcessed(topic string, queue nexus.Queue[int, string], endAt time.Time) {
+	e.recorder.lock.Lock()
+	e.recorder.processed = append(e.recorder.processed, e.name)
+	e.recorder.lock.Unlock()
+}
+
+// newQueue 创建读取通道大小为 0 的队列，队列在消息被消费前将阻塞在向读取通道写入的位置，以便控制消息进入读取通道的时机
+func newQueue(t *testing.T) *queues.NonBlockingRW[int, string] {
+	t.Helper()
+	queue := queues.NewNonBlockingRW[int, string](1, 0, 8).(*queues.NonBlockingRW[int, string])
+	t.Cleanup(func() {
+		if queue.IsRunning() {
+			go func() {
+				for ei := range queue.Consume() {
+					ei.Exec(func(topic string, event nexus.EventExecutor) { event.Exec() }, nil)
+				}
+			}()
+			queue.Close()
+		}
+	})
+	return queue
+}
+
+// consume 消费 n 条消息
+func consume(t *testing.T, queue *queues.NonBlockingRW[int, string], n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case ei := <-queue.Consume():
+			ei.Exec(func(topic string, event nexus.EventExecutor) { event.Exec() }, nil)
+		case <-time.After(time.Second):
+			t.Fatalf("consume timeout, consumed: %d", i)
+		}
+	}
+}
+
+// publish 依次推送名称为 prefix + 序号的消息
+func publish(t *testing.T, queue *queues.NonBlockingRW[int, string], rec *recorder, priority bool, prefix string, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		var err error
+		if priority {
+			err = queue.PublishPriority("topic", rec.event(fmt.Sprint(prefix, i)))
+		} else {
+			err = queue.Publish("topic", rec.event(fmt.Sprint(prefix, i)))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func assertOrder(t *testing.T, rec *recorder, expected string) {
+	t.Helper()
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+	if fmt.Sprint(rec.names) != expected {
+		t.Fatalf("unexpected order: %v, expected: %s", rec.names, expected)
+	}
+}
+
+func TestNonBlockingRW_PublishPriority(t *testing.T) {
+	rec := new(recorder)
+	queue := newQueue(t)
+	publish(t, queue, rec, false, "n", 1, 4)
+	publish(t, queue, rec, true, "p", 1, 3)
+	go queue.Run()
+
+	consume(t, queue, 5)
+	assertOrder(t, rec, "[p1 p2 n1 n2 n3]")
+}
+
+func TestNonBlockingRW_SetPriorityWeight(t *testing.T) {
+	for _, c := range []struct {
+		weight   int
+		expected string
+	}{
+		{weight: 0, expected: "[n1 p1 p2 p3 n2 n3]"},
+		{weight: 1, expected: "[n1 p1 p2 n2 p3 n3]"},
+	} {
+		t.Run(fmt.Sprint(c.weight), func(t *testing.T) {
+			rec := new(recorder)
+			queue := newQueue(t)
+			queue.SetPriorityWeight(c.weight)
+			publish(t, queue, rec, false, "n", 1, 4)
+			go queue.Run()
+
+			// 队列阻塞在写入 n1 时推送高优先级消息，n1 被消费后高优先级消息将先于 n2 处理
+			time.Sleep(time.Millisecond * 50)
+			publish(t, queue, rec, true, "p", 1, 3)
+			consume(t, queue, 1)
+
+			// 队列阻塞在写入 p1 时推送 p3，此时 p1、p2 已被连续处理，设置了权重时将先处理 n2
+			time.Sleep(time.Millisecond * 50)
+			publish(t, queue, rec, true, "p", 3, 4)
+			consume(t, queue, 5)
+			assertOrder(t, rec, c.expected)
+		})
+	}
+}
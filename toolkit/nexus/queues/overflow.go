@@ -0,0 +1,43 @@
+package queues
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/toolkit/nexus"
+)
+
+const (
+	// OverflowPolicyExpand 扩容缓冲区，消息将继续写入，这是队列的默认策略
+	OverflowPolicyExpand OverflowPolicy = iota
+	// OverflowPolicyBlock 阻塞推送消息的协程，直到缓冲区存在可用空间或超时，超时将返回 ErrorQueueOverflow
+	OverflowPolicyBlock
+	// OverflowPolicyError 立即返回 ErrorQueueOverflow
+	OverflowPolicyError
+	// OverflowPolicyDropOldest 丢弃缓冲区中最早的消息，优先丢弃普通消息
+	OverflowPolicyDropOldest
+	// OverflowPolicySpill 将普通消息写入通过 SetSpill 设置的 Spool 中，待缓冲区存在可用空间后按写入顺序重放，未设置 Spool 时与 OverflowPolicyExpand 相同
+	OverflowPolicySpill
+)
+
+var ErrorQueueOverflow = errors.New("queue overflow") // 队列缓冲区溢出
+
+var overflowPolicyNames = map[OverflowPolicy]string{
+	OverflowPolicyExpand:     "OverflowPolicyExpand",
+	OverflowPolicyBlock:      "OverflowPolicyBlock",
+	OverflowPolicyError:      "OverflowPolicyError",
+	OverflowPolicyDropOldest: "OverflowPolicyDropOldest",
+	OverflowPolicySpill:      "OverflowPolicySpill",
+}
+
+// OverflowPolicy 队列缓冲区溢出策略
+type OverflowPolicy byte
+
+// String 返回溢出策略的字符串表示
+func (p OverflowPolicy) String() string {
+	return overflowPolicyNames[p]
+}
+
+// OverflowHandler 队列缓冲区溢出时的处理函数
+//   - 当策略为 OverflowPolicyBlock 或 OverflowPolicyError 时，event 为未能写入的消息
+//   - 当策略为 OverflowPolicyDropOldest 时，event 为被丢弃的消息
+//   - 当策略为 OverflowPolicySpill 时，event 为未能写入的消息，当消息在重放时丢失，event 为 nil
+type OverflowHandler[I, T comparable] func(queue I, topic T, policy OverflowPolicy, event nexus.Event[I, T])
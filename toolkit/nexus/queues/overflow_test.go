@@ -0,0 +1,105 @@
+package queues_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/toolkit/nexus"
+	"github.com/kercylan98/minotaur/toolkit/nexus/queues"
+	"testing"
+	"time"
+)
+
+func TestNonBlockingRW_SetOverflowPolicy(t *testing.T) {
+	for _, c := range []struct {
+		policy    queues.OverflowPolicy
+		errors    []error  // n1 ~ n4 推送的结果
+		overflows []string // 溢出处理函数收到的消息
+		expected  string   // 消息处理顺序
+	}{
+		{policy: queues.OverflowPolicyExpand, errors: []error{nil, nil, nil, nil}, expected: "[n1 n2 n3 n4]"},
+		{policy: queues.OverflowPolicyError, errors: []error{nil, nil, queues.ErrorQueueOverflow, queues.ErrorQueueOverflow}, overflows: []string{"n3", "n4"}, expected: "[n1 n2]"},
+		{policy: queues.OverflowPolicyBlock, errors: []error{nil, nil, queues.ErrorQueueOverflow, queues.ErrorQueueOverflow}, overflows: []string{"n3", "n4"}, expected: "[n1 n2]"},
+		{policy: queues.OverflowPolicyDropOldest, errors: []error{nil, nil, nil, nil}, overflows: []string{"n1", "n2"}, expected: "[n3 n4]"},
+	} {
+		t.Run(c.policy.String(), func(t *testing.T) {
+			rec := new(recorder)
+			queue := newQueue(t)
+			queue.SetOverflowPolicy(2, c.policy, time.Millisecond*20)
+			var overflows []string
+			queue.SetOverflowHandler(func(id int, topic string, policy queues.OverflowPolicy, event nexus.Event[int, string]) {
+				if policy != c.policy {
+					t.Errorf("unexpected policy: %s", policy)
+				}
+				overflows = append(overflows, event.(*testEvent).name)
+			})
+
+			for i, expected := range c.errors {
+				if err := queue.Publish("topic", rec.event(fmt.Sprint("n", i+1))); !errors.Is(err, expected) {
+					t.Fatalf("n%d: unexpected error: %v, expected: %v", i+1, err, expected)
+				}
+			}
+			if fmt.Sprint(overflows) != fmt.Sprint(c.overflows) {
+				t.Fatalf("unexpected overflows: %v, expected: %v", overflows, c.overflows)
+			}
+			// 未能写入及被丢弃的消息同样需要调用 OnProcessed 以释放计数
+			if fmt.Sprint(rec.processed) != fmt.Sprint(c.overflows) {
+				t.Fatalf("unexpected processed: %v, expected: %v", rec.processed, c.overflows)
+			}
+			if count := queue.GetMessageCount(); count != int64(len(c.errors)-len(c.overflows)) {
+				t.Fatalf("unexpected message count: %d", count)
+			}
+
+			go queue.Run()
+			consume(t, queue, len(c.errors)-len(c.overflows))
+			assertOrder(t, rec, c.expected)
+		})
+	}
+}
+
+func TestNonBlockingRW_SetOverflowPolicy_Block(t *testing.T) {
+	rec := new(recorder)
+	queue := newQueue(t)
+	queue.SetOverflowPolicy(2, queues.OverflowPolicyBlock, 0)
+	publish(t, queue, rec, false, "n", 1, 3)
+
+	var published = make(chan error, 1)
+	go func() {
+		published <- queue.Publish("topic", rec.event("n3"))
+	}()
+	select {
+	case err := <-published:
+		t.Fatalf("publish should be blocked when buffer is full, err: %v", err)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	// 队列运行后缓冲区中的消息被读出，阻塞的推送将继续写入
+	go queue.Run()
+	select {
+	case err := <-published:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("publish should be unblocked after buffer has space")
+	}
+	consume(t, queue, 3)
+	assertOrder(t, rec, "[n1 n2 n3]")
+}
+
+func TestNonBlockingRW_SetOverflowPolicy_DropOldestPriority(t *testing.T) {
+	rec := new(recorder)
+	queue := newQueue(t)
+	queue.SetOverflowPolicy(2, queues.OverflowPolicyDropOldest, 0)
+
+	// 优先丢弃普通消息，缓冲区中仅存在高优先级消息时才会丢弃高优先级消息
+	publish(t, queue, rec, true, "p", 1, 2)
+	publish(t, queue, rec, false, "n", 1, 3)
+	publish(t, queue, rec, true, "p", 2, 4)
+	if fmt.Sprint(rec.processed) != "[n1 n2 p1]" {
+		t.Fatalf("unexpected dropped: %v", rec.processed)
+	}
+
+	go queue.Run()
+	consume(t, queue, 2)
+	assertOrder(t, rec, "[p2 p3]")
+}
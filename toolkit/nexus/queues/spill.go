@@ -0,0 +1,114 @@
+package queues
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/kercylan98/minotaur/toolkit/nexus"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var ErrorSpoolCorrupted = errors.New("spool corrupted") // Spool 中的数据已损坏
+
+// Spool 缓冲区溢出时用于暂存消息的存储，数据需要按照写入顺序读取
+//   - 队列将在持有锁时调用 Spool 的函数，因此实现无需保证并发安全
+//   - 队列按写入顺序记录每条数据所属的 topic，因此 Read 返回 io.EOF 以外的错误时，Spool 需要丢弃所有剩余的数据，队列同样会将剩余的消息视为丢失
+type Spool interface {
+	// Write 追加写入一条数据
+	Write(data []byte) error
+
+	// Read 读取并移除最早写入的一条数据，当不存在数据时返回 io.EOF，返回其他错误时剩余的数据需要全部丢弃
+	Read() ([]byte, error)
+
+	// Close 关闭 Spool 并释放资源
+	Close() error
+}
+
+// SpillCodec 消息写入 Spool 时使用的编解码器
+type SpillCodec[I, T comparable] interface {
+	// Encode 将消息编码为可写入 Spool 的数据，当消息无法编码时应返回错误，此时消息将保留在内存中
+	Encode(topic T, event nexus.Event[I, T]) ([]byte, error)
+
+	// Decode 将 Spool 中的数据解码为消息，由于队列无法获取消息所属的 Broker，返回的消息需要已完成初始化
+	Decode(topic T, data []byte) (nexus.Event[I, T], error)
+}
+
+// NewFileSpool 创建基于本地文件的 FileSpool，当文件已存在时将被清空
+//   - FileSpool 仅用于在短时间的下游阻塞期间将溢出的消息从内存转移至磁盘，并非持久化存储，进程退出后其中的消息无法恢复
+func NewFileSpool(path string) (*FileSpool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSpool{file: file}, nil
+}
+
+// FileSpool 基于本地文件的非持久化 Spool，每条记录由 4 字节的长度、4 字节的 CRC32 校验码及数据组成
+//   - 当所有记录均被读取后，文件将被截断以回收磁盘空间
+//   - 记录损坏时将返回 ErrorSpoolCorrupted 并清空文件，剩余的记录将全部丢弃
+type FileSpool struct {
+	file   *os.File // 日志文件
+	reader int64    // 读取偏移量
+	writer int64    // 写入偏移量
+}
+
+func (f *FileSpool) Write(data []byte) error {
+	record := make([]byte, 8+len(data))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(data))
+	copy(record[8:], data)
+	if _, err := f.file.WriteAt(record, f.writer); err != nil {
+		return err
+	}
+	f.writer += int64(len(record))
+	return nil
+}
+
+func (f *FileSpool) Read() ([]byte, error) {
+	if f.reader >= f.writer {
+		return nil, io.EOF
+	}
+	var header [8]byte
+	if _, err := f.file.ReadAt(header[:], f.reader); err != nil {
+		f.reset()
+		return nil, err
+	}
+	size := int64(binary.LittleEndian.Uint32(header[0:4]))
+	if f.reader+8+size > f.writer {
+		f.reset()
+		return nil, ErrorSpoolCorrupted
+	}
+	data := make([]byte, size)
+	if _, err := f.file.ReadAt(data, f.reader+8); err != nil {
+		f.reset()
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(header[4:8]) {
+		// 无法确定损坏的范围，后续的记录同样不再可信
+		f.reset()
+		return nil, ErrorSpoolCorrupted
+	}
+	if f.reader += 8 + size; f.reader >= f.writer {
+		f.reset()
+	}
+	return data, nil
+}
+
+// Close 关闭并删除日志文件
+func (f *FileSpool) Close() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(f.file.Name())
+}
+
+// reset 清空日志文件
+func (f *FileSpool) reset() {
+	f.reader, f.writer = 0, 0
+	_ = f.file.Truncate(0)
+}
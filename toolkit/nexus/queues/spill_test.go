@@ -0,0 +1,204 @@
+package queues_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/toolkit/nexus"
+	"github.com/kercylan98/minotaur/toolkit/nexus/queues"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// spillCodec 以消息名称作为编码结果的 SpillCodec
+type spillCodec struct {
+	recorder *recorder
+}
+
+func (c *spillCodec) Encode(topic string, event nexus.Event[int, string]) ([]byte, error) {
+	return []byte(event.(*testEvent).name), nil
+}
+
+func (c *spillCodec) Decode(topic string, data []byte) (nexus.Event[int, string], error) {
+	// 消息名称以所属的 topic 开头，用于检查重放时消息与 topic 是否错位
+	if !strings.HasPrefix(string(data), topic) {
+		return nil, fmt.Errorf("message %s replayed with topic %s", data, topic)
+	}
+	return c.recorder.event(string(data)), nil
+}
+
+// memorySpool 基于内存的 Spool，设置 corrupt 后下一次读取将返回 ErrorSpoolCorrupted 并丢弃所有数据
+type memorySpool struct {
+	records [][]byte
+	corrupt bool
+}
+
+func (s *memorySpool) Write(data []byte) error {
+	s.records = append(s.records, data)
+	return nil
+}
+
+func (s *memorySpool) Read() ([]byte, error) {
+	if s.corrupt {
+		s.corrupt, s.records = false, nil
+		return nil, queues.ErrorSpoolCorrupted
+	}
+	if len(s.records) == 0 {
+		return nil, io.EOF
+	}
+	data := s.records[0]
+	s.records = s.records[1:]
+	return data, nil
+}
+
+func (s *memorySpool) Close() error {
+	return nil
+}
+
+func TestFileSpool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool", "queue.spill")
+	spool, err := queues.NewFileSpool(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err = spool.Write([]byte(fmt.Sprint("record", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		data, err := spool.Read()
+		if err != nil || string(data) != fmt.Sprint("record", i) {
+			t.Fatalf("unexpected record: %s, err: %v", data, err)
+		}
+	}
+	if _, err = spool.Read(); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 所有记录均被读取后文件将被截断
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Fatalf("spool should be truncated, err: %v", err)
+	}
+
+	// 校验码不匹配的记录将返回 ErrorSpoolCorrupted，剩余的记录将全部丢弃，此后 Spool 仍可继续使用
+	if err = spool.Write([]byte("broken")); err != nil {
+		t.Fatal(err)
+	}
+	if err = spool.Write([]byte("intact")); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = file.WriteAt([]byte("B"), 8); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+	if _, err = spool.Read(); !errors.Is(err, queues.ErrorSpoolCorrupted) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = spool.Read(); err != io.EOF {
+		t.Fatalf("remaining records should be dropped, err: %v", err)
+	}
+	if err = spool.Write([]byte("next")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := spool.Read(); err != nil || string(data) != "next" {
+		t.Fatalf("unexpected record: %s, err: %v", data, err)
+	}
+
+	if err = spool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("spool file should be removed, err: %v", err)
+	}
+}
+
+func TestNonBlockingRW_SetSpill(t *testing.T) {
+	rec := new(recorder)
+	queue := newQueue(t)
+	spool, err := queues.NewFileSpool(filepath.Join(t.TempDir(), "queue.spill"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue.SetOverflowPolicy(2, queues.OverflowPolicySpill, 0)
+	queue.SetSpill(spool, &spillCodec{recorder: rec})
+
+	// 超出缓冲区上限的消息将写入 Spool，高优先级消息不会写入 Spool
+	publish(t, queue, rec, false, "topic", 1, 7)
+	publish(t, queue, rec, true, "p", 1, 2)
+	if count := queue.GetSpillCount(); count != 4 {
+		t.Fatalf("unexpected spill count: %d", count)
+	}
+	if count := queue.GetMessageCount(); count != 7 {
+		t.Fatalf("unexpected message count: %d", count)
+	}
+
+	// 消费过程中继续推送消息，topic 存在尚未重放的消息时后续的消息同样会写入 Spool，重放后消息顺序保持不变
+	go queue.Run()
+	consume(t, queue, 3)
+	publish(t, queue, rec, false, "topic", 7, 9)
+	consume(t, queue, 6)
+	assertOrder(t, rec, "[p1 topic1 topic2 topic3 topic4 topic5 topic6 topic7 topic8]")
+	if count := queue.GetSpillCount(); count != 0 {
+		t.Fatalf("unexpected spill count: %d", count)
+	}
+}
+
+func TestNonBlockingRW_SetSpill_Corrupted(t *testing.T) {
+	rec := new(recorder)
+	queue := newQueue(t)
+	spool := new(memorySpool)
+	queue.SetOverflowPolicy(1, queues.OverflowPolicySpill, 0)
+	queue.SetSpill(spool, &spillCodec{recorder: rec})
+	var lost = make(chan string, 8)
+	queue.SetOverflowHandler(func(id int, topic string, policy queues.OverflowPolicy, event nexus.Event[int, string]) {
+		if event != nil {
+			t.Errorf("lost message should be nil")
+		}
+		lost <- topic
+	})
+
+	for _, name := range []string{"a1", "a2", "b1"} {
+		if err := queue.Publish(name[:1], rec.event(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 重放时 Spool 损坏，尚未重放的消息将全部丢失，此后写入 Spool 的消息不应与过期的 topic 错位
+	spool.corrupt = true
+	go queue.Run()
+	consume(t, queue, 1)
+	var topics []string
+	for len(topics) < 2 {
+		select {
+		case topic := <-lost:
+			topics = append(topics, topic)
+		case <-time.After(time.Second):
+			t.Fatalf("lost messages not reported, reported: %v", topics)
+		}
+	}
+	if fmt.Sprint(topics) != "[a b]" {
+		t.Fatalf("unexpected lost topics: %v", topics)
+	}
+
+	for _, name := range []string{"b2", "a3", "b3"} {
+		if err := queue.Publish(name[:1], rec.event(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	consume(t, queue, 3)
+	assertOrder(t, rec, "[a1 b2 a3 b3]")
+	if count := queue.GetMessageCount(); count != 0 {
+		t.Fatalf("unexpected message count: %d", count)
+	}
+	if count := queue.GetTopicMessageCount("a") + queue.GetTopicMessageCount("b"); count != 0 {
+		t.Fatalf("unexpected topic message count: %d", count)
+	}
+}
@@ -4,4 +4,5 @@ import "errors"
 
 var (
 	ErrBufferIsEmpty = errors.New("buffer is empty")
+	ErrBufferIsFull  = errors.New("buffer is full")
 )
@@ -0,0 +1,144 @@
+package buffer
+
+// NewFixedRing 创建一个并发不安全的固定容量环形缓冲区
+//   - capacity: 容量，当容量小于 1 时，将会使用默认容量 1
+//   - overwrite: 缓冲区已满时是否覆盖最旧的数据，当为 false 时，写入将会失败
+//
+// 适用于最近聊天记录、帧同步中的帧缓冲以及连接最近数据包窗口等仅需保留最近若干条数据的场景
+func NewFixedRing[T any](capacity int, overwrite bool) *FixedRing[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &FixedRing[T]{
+		buf:       make([]T, capacity),
+		overwrite: overwrite,
+	}
+}
+
+// FixedRing 固定容量的环形缓冲区，写入和读取均不会产生内存分配
+type FixedRing[T any] struct {
+	buf       []T
+	head      int // 最旧数据的位置
+	len       int
+	overwrite bool
+}
+
+// Write 写入数据，当缓冲区已满时将根据模式覆盖最旧的数据或返回 ErrBufferIsFull
+//   - 当发生覆盖时，evicted 为被覆盖的数据，overwritten 为 true，可用于回收被覆盖数据的资源
+func (b *FixedRing[T]) Write(v T) (evicted T, overwritten bool, err error) {
+	if b.len < len(b.buf) {
+		b.buf[b.index(b.len)] = v
+		b.len++
+		return evicted, false, nil
+	}
+	if !b.overwrite {
+		return evicted, false, ErrBufferIsFull
+	}
+	evicted = b.buf[b.head]
+	b.buf[b.head] = v
+	b.head = b.index(1)
+	return evicted, true, nil
+}
+
+// Read 读取并移除最旧的数据
+func (b *FixedRing[T]) Read() (T, error) {
+	var t T
+	if b.len == 0 {
+		return t, ErrBufferIsEmpty
+	}
+	v := b.buf[b.head]
+	b.buf[b.head] = t
+	b.head = b.index(1)
+	b.len--
+	return v, nil
+}
+
+// Peek 查看最旧的数据
+func (b *FixedRing[T]) Peek() (t T, err error) {
+	if b.len == 0 {
+		return t, ErrBufferIsEmpty
+	}
+	return b.buf[b.head], nil
+}
+
+// PeekNewest 查看最新的数据
+func (b *FixedRing[T]) PeekNewest() (t T, err error) {
+	if b.len == 0 {
+		return t, ErrBufferIsEmpty
+	}
+	return b.buf[b.index(b.len-1)], nil
+}
+
+// Get 获取从最旧的数据开始的第 i 个数据，当 i 超出范围时返回 false
+func (b *FixedRing[T]) Get(i int) (t T, ok bool) {
+	if i < 0 || i >= b.len {
+		return t, false
+	}
+	return b.buf[b.index(i)], true
+}
+
+// Range 从最旧到最新依次遍历数据，当 handler 返回 false 时将停止遍历
+//   - 遍历过程中不应对缓冲区进行写入或读取
+func (b *FixedRing[T]) Range(handler func(i int, v T) bool) {
+	for i := 0; i < b.len; i++ {
+		if !handler(i, b.buf[b.index(i)]) {
+			return
+		}
+	}
+}
+
+// RangeReverse 从最新到最旧依次遍历数据，当 handler 返回 false 时将停止遍历
+//   - 遍历过程中不应对缓冲区进行写入或读取
+func (b *FixedRing[T]) RangeReverse(handler func(i int, v T) bool) {
+	for i := b.len - 1; i >= 0; i-- {
+		if !handler(i, b.buf[b.index(i)]) {
+			return
+		}
+	}
+}
+
+// Snapshot 将数据从最旧到最新依次追加到 dst 中并返回，当 dst 容量足够时不会产生内存分配
+//   - 返回的切片与缓冲区互不影响，可在遍历期间继续写入缓冲区
+func (b *FixedRing[T]) Snapshot(dst []T) []T {
+	if b.head+b.len <= len(b.buf) {
+		return append(dst, b.buf[b.head:b.head+b.len]...)
+	}
+	dst = append(dst, b.buf[b.head:]...)
+	return append(dst, b.buf[:b.head+b.len-len(b.buf)]...)
+}
+
+// IsEmpty 是否为空
+func (b *FixedRing[T]) IsEmpty() bool {
+	return b.len == 0
+}
+
+// IsFull 是否已满
+func (b *FixedRing[T]) IsFull() bool {
+	return b.len == len(b.buf)
+}
+
+// Cap 返回缓冲区容量
+func (b *FixedRing[T]) Cap() int {
+	return len(b.buf)
+}
+
+// Len 返回缓冲区长度
+func (b *FixedRing[T]) Len() int {
+	return b.len
+}
+
+// Reset 重置缓冲区，缓冲区中的数据将被置为零值以便于垃圾回收
+func (b *FixedRing[T]) Reset() {
+	clear(b.buf)
+	b.head = 0
+	b.len = 0
+}
+
+// index 返回从最旧的数据开始的第 i 个数据在底层数组中的位置
+func (b *FixedRing[T]) index(i int) int {
+	i += b.head
+	if i >= len(b.buf) {
+		i -= len(b.buf)
+	}
+	return i
+}
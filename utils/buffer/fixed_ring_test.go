@@ -0,0 +1,71 @@
+package buffer_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/buffer"
+	"testing"
+)
+
+func TestFixedRing_Overwrite(t *testing.T) {
+	ring := buffer.NewFixedRing[int](3, true)
+	for i := 0; i < 5; i++ {
+		evicted, overwritten, err := ring.Write(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if overwritten != (i >= 3) || (overwritten && evicted != i-3) {
+			t.Fatalf("unexpected evicted %d at %d", evicted, i)
+		}
+	}
+
+	snapshot := ring.Snapshot(nil)
+	if len(snapshot) != 3 || snapshot[0] != 2 || snapshot[1] != 3 || snapshot[2] != 4 {
+		t.Fatalf("unexpected snapshot %v", snapshot)
+	}
+	var reversed []int
+	ring.RangeReverse(func(i int, v int) bool {
+		reversed = append(reversed, v)
+		return true
+	})
+	if len(reversed) != 3 || reversed[0] != 4 || reversed[2] != 2 {
+		t.Fatalf("unexpected reverse range %v", reversed)
+	}
+	if v, _ := ring.PeekNewest(); v != 4 {
+		t.Fatalf("unexpected newest %d", v)
+	}
+	if v, _ := ring.Read(); v != 2 {
+		t.Fatalf("unexpected oldest %d", v)
+	}
+	if v, ok := ring.Get(1); !ok || v != 4 {
+		t.Fatalf("unexpected get %d", v)
+	}
+}
+
+func TestFixedRing_Full(t *testing.T) {
+	ring := buffer.NewFixedRing[int](2, false)
+	_, _, _ = ring.Write(1)
+	_, _, _ = ring.Write(2)
+	if _, _, err := ring.Write(3); err != buffer.ErrBufferIsFull {
+		t.Fatalf("expect ErrBufferIsFull, got %v", err)
+	}
+	ring.Reset()
+	if !ring.IsEmpty() {
+		t.Fatal("ring should be empty after reset")
+	}
+}
+
+func TestFixedRing_ZeroAllocation(t *testing.T) {
+	ring := buffer.NewFixedRing[int](64, true)
+	dst := make([]int, 0, 64)
+	var sum int
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _, _ = ring.Write(1)
+		ring.Range(func(i int, v int) bool {
+			sum += v
+			return true
+		})
+		dst = ring.Snapshot(dst[:0])
+	})
+	if allocs != 0 {
+		t.Fatalf("expect zero allocation, got %v", allocs)
+	}
+}
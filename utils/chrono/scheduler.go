@@ -4,6 +4,7 @@ import (
 	"github.com/RussellLuo/timingwheel"
 	"github.com/gorhill/cronexpr"
 	"github.com/kercylan98/minotaur/utils/collection"
+	"github.com/kercylan98/minotaur/utils/times"
 	"reflect"
 	"sync"
 	"time"
@@ -112,8 +113,9 @@ func (s *Scheduler) GetRegisteredTasks() []string {
 
 // RegisterCronTask 通过 cron 表达式注册一个任务。
 //   - 当 cron 表达式错误时，将会返回错误信息
+//   - cron 表达式的格式可参考 times.ParseCron，6 段表达式将按照 "秒 分 时 日 月 周" 解析
 func (s *Scheduler) RegisterCronTask(name, expression string, function interface{}, args ...interface{}) error {
-	expr, err := cronexpr.Parse(expression)
+	expr, err := times.ParseCron(expression)
 	if err != nil {
 		return err
 	}
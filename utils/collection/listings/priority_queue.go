@@ -0,0 +1,160 @@
+package listings
+
+// NewPriorityQueue 创建一个基于二叉堆的优先级队列，less 返回 true 时 a 将先于 b 出队
+//   - 与 PrioritySlice 在每次添加时重新排序不同，PriorityQueue 的入队及出队复杂度均为 O(log n)，适用于调度器、寻路算法的开放列表等高频场景
+func NewPriorityQueue[V any](less func(a, b V) bool, capacity ...int) *PriorityQueue[V] {
+	q := &PriorityQueue[V]{less: less}
+	if len(capacity) > 0 {
+		q.items = make([]*PriorityQueueElement[V], 0, capacity[0])
+	}
+	return q
+}
+
+// PriorityQueue 基于二叉堆实现的优先级队列
+type PriorityQueue[V any] struct {
+	items []*PriorityQueueElement[V]
+	less  func(a, b V) bool
+}
+
+// PriorityQueueElement 优先级队列中的元素，可通过 PriorityQueue.Update 及 PriorityQueue.Remove 对已入队的元素进行操作
+type PriorityQueueElement[V any] struct {
+	value V
+	index int // 在堆中的位置，已出队的元素为 -1
+}
+
+// Value 返回元素值
+func (e *PriorityQueueElement[V]) Value() V {
+	return e.value
+}
+
+// InQueue 检查元素是否仍在队列中
+func (e *PriorityQueueElement[V]) InQueue() bool {
+	return e.index >= 0
+}
+
+// Len 返回队列长度
+func (q *PriorityQueue[V]) Len() int {
+	return len(q.items)
+}
+
+// Clear 清空队列
+func (q *PriorityQueue[V]) Clear() {
+	for _, item := range q.items {
+		item.index = -1
+	}
+	q.items = q.items[:0]
+}
+
+// Push 将元素入队，返回的元素可用于后续的更新及移除
+func (q *PriorityQueue[V]) Push(v V) *PriorityQueueElement[V] {
+	e := &PriorityQueueElement[V]{value: v, index: len(q.items)}
+	q.items = append(q.items, e)
+	q.up(e.index)
+	return e
+}
+
+// Pop 将优先级最高的元素出队，当队列为空时将返回 false
+func (q *PriorityQueue[V]) Pop() (v V, ok bool) {
+	if len(q.items) == 0 {
+		return v, false
+	}
+	return q.remove(0).value, true
+}
+
+// Peek 返回优先级最高的元素但不出队，当队列为空时将返回 false
+func (q *PriorityQueue[V]) Peek() (v V, ok bool) {
+	if len(q.items) == 0 {
+		return v, false
+	}
+	return q.items[0].value, true
+}
+
+// Update 更新已入队元素的值并调整其位置，例如寻路算法中发现了更短的路径，当元素已不在队列中时将返回 false
+func (q *PriorityQueue[V]) Update(e *PriorityQueueElement[V], v V) bool {
+	if !q.contains(e) {
+		return false
+	}
+	e.value = v
+	if !q.down(e.index) {
+		q.up(e.index)
+	}
+	return true
+}
+
+// Remove 将已入队的元素移出队列，当元素已不在队列中时将返回 false
+func (q *PriorityQueue[V]) Remove(e *PriorityQueueElement[V]) bool {
+	if !q.contains(e) {
+		return false
+	}
+	q.remove(e.index)
+	return true
+}
+
+// Values 返回队列中所有元素的值，返回的顺序为堆的存储顺序而非优先级顺序
+func (q *PriorityQueue[V]) Values() []V {
+	values := make([]V, len(q.items))
+	for i, item := range q.items {
+		values[i] = item.value
+	}
+	return values
+}
+
+// contains 检查元素是否属于当前队列
+func (q *PriorityQueue[V]) contains(e *PriorityQueueElement[V]) bool {
+	return e != nil && e.index >= 0 && e.index < len(q.items) && q.items[e.index] == e
+}
+
+// remove 移除特定位置的元素
+func (q *PriorityQueue[V]) remove(i int) *PriorityQueueElement[V] {
+	last := len(q.items) - 1
+	e := q.items[i]
+	if i != last {
+		q.swap(i, last)
+	}
+	q.items[last] = nil
+	q.items = q.items[:last]
+	if i != last {
+		if !q.down(i) {
+			q.up(i)
+		}
+	}
+	e.index = -1
+	return e
+}
+
+func (q *PriorityQueue[V]) swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.items[i].index = i
+	q.items[j].index = j
+}
+
+func (q *PriorityQueue[V]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.less(q.items[i].value, q.items[parent].value) {
+			break
+		}
+		q.swap(i, parent)
+		i = parent
+	}
+}
+
+// down 下沉特定位置的元素，返回元素是否发生了移动
+func (q *PriorityQueue[V]) down(i int) bool {
+	start, n := i, len(q.items)
+	for {
+		child := 2*i + 1
+		if child >= n {
+			break
+		}
+		if right := child + 1; right < n && q.less(q.items[right].value, q.items[child].value) {
+			child = right
+		}
+		if !q.less(q.items[child].value, q.items[i].value) {
+			break
+		}
+		q.swap(i, child)
+		i = child
+	}
+	return i > start
+}
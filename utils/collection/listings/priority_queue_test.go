@@ -0,0 +1,46 @@
+package listings_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/collection/listings"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestPriorityQueue_Pop(t *testing.T) {
+	q := listings.NewPriorityQueue[int](func(a, b int) bool { return a < b })
+	var values = rand.Perm(100)
+	for _, v := range values {
+		q.Push(v)
+	}
+	sort.Ints(values)
+	for _, expected := range values {
+		if v, ok := q.Pop(); !ok || v != expected {
+			t.Fatalf("pop %v != %v", v, expected)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Fatal("queue should be empty")
+	}
+}
+
+func TestPriorityQueue_UpdateAndRemove(t *testing.T) {
+	q := listings.NewPriorityQueue[int](func(a, b int) bool { return a < b })
+	a, b, c := q.Push(10), q.Push(20), q.Push(30)
+	q.Update(c, 5)
+	if v, _ := q.Peek(); v != 5 {
+		t.Fatalf("peek %v != 5", v)
+	}
+	q.Remove(a)
+	if a.InQueue() || q.Remove(a) {
+		t.Fatal("removed element should not be in queue")
+	}
+	for _, expected := range []int{5, 20} {
+		if v, _ := q.Pop(); v != expected {
+			t.Fatalf("pop %v != %v", v, expected)
+		}
+	}
+	if b.InQueue() || q.Update(b, 1) {
+		t.Fatal("popped element should not be in queue")
+	}
+}
@@ -0,0 +1,199 @@
+package listings
+
+import "math/rand"
+
+const (
+	sortedListMaxLevel    = 32   // 跳表的最大层数
+	sortedListProbability = 0.25 // 跳表节点晋升的概率
+)
+
+// NewSortedList 创建一个支持按排名访问的有序列表，compare 用于比较两个元素的大小
+//   - compare 返回负数表示 a 排在 b 之前，返回 0 表示 a 与 b 为同一元素，返回正数表示 a 排在 b 之后
+//   - 对于排行榜等存在相同分数的场景，compare 应通过 ID 等唯一标识打破平局，以便 Remove 及 Rank 能够定位到特定元素
+func NewSortedList[V any](compare func(a, b V) int) *SortedList[V] {
+	return &SortedList[V]{
+		compare: compare,
+		head:    &sortedListNode[V]{levels: make([]sortedListLevel[V], sortedListMaxLevel)},
+		level:   1,
+	}
+}
+
+// SortedList 基于可索引跳表实现的有序列表，插入、删除、按排名访问及查询排名的复杂度均为 O(log n)
+//   - 排名从 0 开始
+type SortedList[V any] struct {
+	compare func(a, b V) int
+	head    *sortedListNode[V]
+	level   int
+	length  int
+}
+
+type sortedListNode[V any] struct {
+	value  V
+	levels []sortedListLevel[V]
+}
+
+type sortedListLevel[V any] struct {
+	forward *sortedListNode[V]
+	span    int // 到达 forward 所跨越的节点数量
+}
+
+// Len 返回列表长度
+func (l *SortedList[V]) Len() int {
+	return l.length
+}
+
+// Clear 清空列表
+func (l *SortedList[V]) Clear() {
+	l.head = &sortedListNode[V]{levels: make([]sortedListLevel[V], sortedListMaxLevel)}
+	l.level = 1
+	l.length = 0
+}
+
+// Insert 插入元素并返回其排名，与已存在元素相等的元素将排在其之后
+func (l *SortedList[V]) Insert(v V) int {
+	var update [sortedListMaxLevel]*sortedListNode[V]
+	var rank [sortedListMaxLevel]int
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i != l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].forward != nil && l.compare(x.levels[i].forward.value, v) <= 0 {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+
+	level := l.randomLevel()
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			update[i] = l.head
+			update[i].levels[i].span = l.length
+		}
+		l.level = level
+	}
+
+	node := &sortedListNode[V]{value: v, levels: make([]sortedListLevel[V], level)}
+	for i := 0; i < level; i++ {
+		node.levels[i].forward = update[i].levels[i].forward
+		update[i].levels[i].forward = node
+		node.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = rank[0] - rank[i] + 1
+	}
+	for i := level; i < l.level; i++ {
+		update[i].levels[i].span++
+	}
+	l.length++
+	return rank[0]
+}
+
+// Remove 移除与 v 相等的元素，当元素不存在时将返回 false
+func (l *SortedList[V]) Remove(v V) bool {
+	var update [sortedListMaxLevel]*sortedListNode[V]
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && l.compare(x.levels[i].forward.value, v) < 0 {
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+	x = x.levels[0].forward
+	if x == nil || l.compare(x.value, v) != 0 {
+		return false
+	}
+	for i := 0; i < l.level; i++ {
+		if update[i].levels[i].forward == x {
+			update[i].levels[i].span += x.levels[i].span - 1
+			update[i].levels[i].forward = x.levels[i].forward
+		} else {
+			update[i].levels[i].span--
+		}
+	}
+	for l.level > 1 && l.head.levels[l.level-1].forward == nil {
+		l.level--
+	}
+	l.length--
+	return true
+}
+
+// Rank 返回与 v 相等的元素的排名，当元素不存在时将返回 false
+func (l *SortedList[V]) Rank(v V) (int, bool) {
+	var rank int
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && l.compare(x.levels[i].forward.value, v) < 0 {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+	}
+	x = x.levels[0].forward
+	if x == nil || l.compare(x.value, v) != 0 {
+		return 0, false
+	}
+	return rank, true
+}
+
+// Get 返回特定排名的元素，当排名超出范围时将返回 false
+func (l *SortedList[V]) Get(rank int) (v V, ok bool) {
+	x := l.nodeByRank(rank)
+	if x == nil {
+		return v, false
+	}
+	return x.value, true
+}
+
+// Range 从排名 start 开始按顺序遍历元素，当 action 返回 false 时将停止遍历
+func (l *SortedList[V]) Range(start int, action func(rank int, value V) bool) {
+	for x := l.nodeByRank(start); x != nil; x = x.levels[0].forward {
+		if !action(start, x.value) {
+			return
+		}
+		start++
+	}
+}
+
+// Slice 返回排名在 [start, end) 范围内的元素
+func (l *SortedList[V]) Slice(start, end int) []V {
+	end = min(end, l.length)
+	if start < 0 || start >= end {
+		return nil
+	}
+	values := make([]V, 0, end-start)
+	l.Range(start, func(rank int, value V) bool {
+		if rank >= end {
+			return false
+		}
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// nodeByRank 返回特定排名的节点
+func (l *SortedList[V]) nodeByRank(rank int) *sortedListNode[V] {
+	if rank < 0 || rank >= l.length {
+		return nil
+	}
+	target, traversed := rank+1, 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && traversed+x.levels[i].span <= target {
+			traversed += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if traversed == target {
+			return x
+		}
+	}
+	return nil
+}
+
+// randomLevel 随机生成新节点的层数
+func (l *SortedList[V]) randomLevel() int {
+	level := 1
+	for level < sortedListMaxLevel && rand.Float64() < sortedListProbability {
+		level++
+	}
+	return level
+}
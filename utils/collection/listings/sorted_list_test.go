@@ -0,0 +1,51 @@
+package listings_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/collection/listings"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSortedList_Insert(t *testing.T) {
+	l := listings.NewSortedList[int](func(a, b int) int { return a - b })
+	var values = rand.Perm(1000)
+	for _, v := range values {
+		l.Insert(v)
+	}
+	sort.Ints(values)
+	if l.Len() != len(values) {
+		t.Fatalf("len %v != %v", l.Len(), len(values))
+	}
+	for rank, expected := range values {
+		if v, ok := l.Get(rank); !ok || v != expected {
+			t.Fatalf("get %v: %v != %v", rank, v, expected)
+		}
+		if r, ok := l.Rank(expected); !ok || r != rank {
+			t.Fatalf("rank of %v: %v != %v", expected, r, rank)
+		}
+	}
+}
+
+func TestSortedList_Remove(t *testing.T) {
+	l := listings.NewSortedList[int](func(a, b int) int { return a - b })
+	for _, v := range rand.Perm(1000) {
+		l.Insert(v)
+	}
+	for v := 0; v < 1000; v += 2 {
+		if !l.Remove(v) {
+			t.Fatalf("remove %v failed", v)
+		}
+	}
+	if l.Remove(0) {
+		t.Fatal("remove of a missing element should fail")
+	}
+	for rank, v := range l.Slice(0, l.Len()) {
+		if expected := rank*2 + 1; v != expected {
+			t.Fatalf("rank %v: %v != %v", rank, v, expected)
+		}
+	}
+	if r, ok := l.Rank(999); !ok || r != 499 {
+		t.Fatalf("rank of 999: %v", r)
+	}
+}
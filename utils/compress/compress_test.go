@@ -0,0 +1,47 @@
+package compress_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/kercylan98/minotaur/utils/compress"
+	"testing"
+)
+
+func TestUnCompressLimit(t *testing.T) {
+	var cases = []struct {
+		name       string
+		compress   func(data []byte) ([]byte, error)
+		uncompress func(data []byte, limit int) ([]byte, error)
+	}{
+		{name: "GZip", compress: func(data []byte) ([]byte, error) {
+			buf, err := compress.GZipCompress(data)
+			return buf.Bytes(), err
+		}, uncompress: compress.GZipUnCompressLimit},
+		{name: "Snappy", compress: func(data []byte) ([]byte, error) {
+			return compress.SnappyCompress(data), nil
+		}, uncompress: compress.SnappyUnCompressLimit},
+		{name: "Zstd", compress: compress.ZstdCompress, uncompress: compress.ZstdUnCompressLimit},
+	}
+
+	data := bytes.Repeat([]byte("minotaur"), 64)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			compressed, err := c.compress(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, limit := range []int{0, len(data)} {
+				result, err := c.uncompress(compressed, limit)
+				if err != nil {
+					t.Fatalf("limit %d: %v", limit, err)
+				}
+				if !bytes.Equal(result, data) {
+					t.Fatalf("limit %d: uncompressed data mismatch", limit)
+				}
+			}
+			if _, err = c.uncompress(compressed, len(data)-1); !errors.Is(err, compress.ErrUnCompressOversize) {
+				t.Fatalf("expected ErrUnCompressOversize, got %v", err)
+			}
+		})
+	}
+}
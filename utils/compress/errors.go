@@ -0,0 +1,7 @@
+package compress
+
+import "errors"
+
+var (
+	ErrUnCompressOversize = errors.New("uncompressed data size exceeds the limit") // 解压缩后的数据大小超出限制
+)
@@ -38,3 +38,28 @@ func GZipUnCompress(dataByte []byte) ([]byte, error) {
 
 	return result, nil
 }
+
+// GZipUnCompressLimit 对已进行GZip压缩的数据进行解压缩，当解压缩后的大小超过 limit 时返回 ErrUnCompressOversize
+//   - 当 limit <= 0 时与 GZipUnCompress 相同
+func GZipUnCompressLimit(dataByte []byte, limit int) ([]byte, error) {
+	if limit <= 0 {
+		return GZipUnCompress(dataByte)
+	}
+	gzipReader, err := gzip.NewReader(bytes.NewReader(dataByte))
+	if err != nil {
+		return nil, err
+	}
+	result, err := io.ReadAll(io.LimitReader(gzipReader, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > limit {
+		return nil, ErrUnCompressOversize
+	}
+
+	if err := gzipReader.Close(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package compress
+
+import (
+	"github.com/klauspost/compress/s2"
+)
+
+// SnappyCompress 对数据进行Snappy压缩，返回压缩后的字节数组
+//   - 压缩结果兼容标准的 Snappy 块格式
+func SnappyCompress(data []byte) []byte {
+	return s2.EncodeSnappy(nil, data)
+}
+
+// SnappyUnCompress 对已进行Snappy压缩的数据进行解压缩，返回字节数组及错误信息
+func SnappyUnCompress(dataByte []byte) ([]byte, error) {
+	return s2.Decode(nil, dataByte)
+}
+
+// SnappyUnCompressLimit 对已进行Snappy压缩的数据进行解压缩，当解压缩后的大小超过 limit 时返回 ErrUnCompressOversize
+//   - 解压缩前将根据数据头部记录的长度进行检查，超出限制的数据不会分配内存
+//   - 当 limit <= 0 时与 SnappyUnCompress 相同
+func SnappyUnCompressLimit(dataByte []byte, limit int) ([]byte, error) {
+	if limit > 0 {
+		size, err := s2.DecodedLen(dataByte)
+		if err != nil {
+			return nil, err
+		}
+		if size > limit {
+			return nil, ErrUnCompressOversize
+		}
+	}
+	return s2.Decode(nil, dataByte)
+}
@@ -0,0 +1,70 @@
+package compress
+
+import (
+	"errors"
+	"github.com/klauspost/compress/zstd"
+	"sync"
+)
+
+var (
+	zstdOnce     sync.Once
+	zstdEncoder  *zstd.Encoder
+	zstdDecoder  *zstd.Decoder
+	zstdErr      error
+	zstdDecoders sync.Map // 按解压缩大小限制缓存的 Zstd 解码器
+)
+
+// zstdInit 延迟初始化共享的 Zstd 编解码器，EncodeAll 和 DecodeAll 均为并发安全的
+func zstdInit() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+// ZstdCompress 对数据进行Zstd压缩，返回压缩后的字节数组及错误信息
+func ZstdCompress(data []byte) ([]byte, error) {
+	if err := zstdInit(); err != nil {
+		return nil, err
+	}
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+// ZstdUnCompress 对已进行Zstd压缩的数据进行解压缩，返回字节数组及错误信息
+func ZstdUnCompress(dataByte []byte) ([]byte, error) {
+	if err := zstdInit(); err != nil {
+		return nil, err
+	}
+	return zstdDecoder.DecodeAll(dataByte, nil)
+}
+
+// ZstdUnCompressLimit 对已进行Zstd压缩的数据进行解压缩，当解压缩后的大小超过 limit 时返回 ErrUnCompressOversize
+//   - 当 limit <= 0 时与 ZstdUnCompress 相同
+//   - 每个不同的 limit 都将创建并缓存一个解码器，因此 limit 应当为固定的配置值
+func ZstdUnCompressLimit(dataByte []byte, limit int) ([]byte, error) {
+	if limit <= 0 {
+		return ZstdUnCompress(dataByte)
+	}
+	decoder, exist := zstdDecoders.Load(limit)
+	if !exist {
+		// 解码器无法解码窗口小于 MinWindowSize 的数据，因此较小的限制将在解码后再次检查
+		d, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(max(limit, zstd.MinWindowSize))))
+		if err != nil {
+			return nil, err
+		}
+		if decoder, exist = zstdDecoders.LoadOrStore(limit, d); exist {
+			d.Close()
+		}
+	}
+	data, err := decoder.(*zstd.Decoder).DecodeAll(dataByte, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+		return nil, ErrUnCompressOversize
+	}
+	if err == nil && len(data) > limit {
+		return nil, ErrUnCompressOversize
+	}
+	return data, err
+}
@@ -0,0 +1,113 @@
+package concurrent
+
+import (
+	"fmt"
+	"sync"
+)
+
+// NewSingleFlight 创建一个 SingleFlight，零值的 SingleFlight 同样可以直接使用
+func NewSingleFlight[K comparable, V any]() *SingleFlight[K, V] {
+	return &SingleFlight[K, V]{}
+}
+
+// SingleFlight 对相同 key 的并发调用进行去重，同一时刻相同 key 仅会执行一次函数，其余调用者将等待并共享该次执行的结果
+//   - 适用于大量异步消息同时请求相同的后端查询的场景，例如公会中 50 名成员同时登录时加载同一个公会数据
+type SingleFlight[K comparable, V any] struct {
+	lock  sync.Mutex
+	calls map[K]*singleFlightCall[V]
+}
+
+// SingleFlightResult SingleFlight 的执行结果
+type SingleFlightResult[V any] struct {
+	Val    V     // 函数返回值
+	Err    error // 函数返回的错误
+	Shared bool  // 结果是否被多个调用者共享
+}
+
+type singleFlightCall[V any] struct {
+	wg    sync.WaitGroup
+	val   V
+	err   error
+	panic any
+	dups  int
+	chans []chan<- SingleFlightResult[V]
+}
+
+// Do 执行并返回 fn 的结果，当相同 key 的调用正在执行时，将等待该调用完成并共享其结果
+//   - shared 表示结果是否被多个调用者共享
+//   - 当 fn 发生 panic 时，执行 fn 的调用者将重新抛出该 panic，其余调用者将获得描述该 panic 的错误
+func (slf *SingleFlight[K, V]) Do(key K, fn func() (V, error)) (v V, err error, shared bool) {
+	slf.lock.Lock()
+	if slf.calls == nil {
+		slf.calls = make(map[K]*singleFlightCall[V])
+	}
+	if c, exist := slf.calls[key]; exist {
+		c.dups++
+		slf.lock.Unlock()
+		c.wg.Wait()
+		if c.panic != nil {
+			return v, fmt.Errorf("concurrent: single flight panic: %v", c.panic), true
+		}
+		return c.val, c.err, true
+	}
+	c := new(singleFlightCall[V])
+	c.wg.Add(1)
+	slf.calls[key] = c
+	slf.lock.Unlock()
+
+	slf.call(c, key, fn)
+	if c.panic != nil {
+		panic(c.panic)
+	}
+	return c.val, c.err, c.dups > 0
+}
+
+// DoChan 与 Do 相同，但是将通过管道异步返回结果，fn 将在新的协程中执行
+//   - 当 fn 发生 panic 时，通过管道返回的错误将描述该 panic
+func (slf *SingleFlight[K, V]) DoChan(key K, fn func() (V, error)) <-chan SingleFlightResult[V] {
+	ch := make(chan SingleFlightResult[V], 1)
+	slf.lock.Lock()
+	if slf.calls == nil {
+		slf.calls = make(map[K]*singleFlightCall[V])
+	}
+	if c, exist := slf.calls[key]; exist {
+		c.dups++
+		c.chans = append(c.chans, ch)
+		slf.lock.Unlock()
+		return ch
+	}
+	c := &singleFlightCall[V]{chans: []chan<- SingleFlightResult[V]{ch}}
+	c.wg.Add(1)
+	slf.calls[key] = c
+	slf.lock.Unlock()
+
+	go slf.call(c, key, fn)
+	return ch
+}
+
+// Forget 使 SingleFlight 忘记正在执行的 key，后续相同 key 的调用将不再等待正在执行的调用
+func (slf *SingleFlight[K, V]) Forget(key K) {
+	slf.lock.Lock()
+	delete(slf.calls, key)
+	slf.lock.Unlock()
+}
+
+// call 执行 fn 并通知所有等待者
+func (slf *SingleFlight[K, V]) call(c *singleFlightCall[V], key K, fn func() (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.panic = r
+			c.err = fmt.Errorf("concurrent: single flight panic: %v", r)
+		}
+		slf.lock.Lock()
+		if slf.calls[key] == c {
+			delete(slf.calls, key)
+		}
+		c.wg.Done()
+		for _, ch := range c.chans {
+			ch <- SingleFlightResult[V]{Val: c.val, Err: c.err, Shared: c.dups > 0}
+		}
+		slf.lock.Unlock()
+	}()
+	c.val, c.err = fn()
+}
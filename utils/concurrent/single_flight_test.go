@@ -0,0 +1,49 @@
+package concurrent_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/concurrent"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlight_Do(t *testing.T) {
+	var sf concurrent.SingleFlight[string, int]
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, _ := sf.Do("guild", func() (int, error) {
+				calls.Add(1)
+				time.Sleep(time.Millisecond * 100)
+				return 1, nil
+			})
+			if v != 1 || err != nil {
+				t.Errorf("v: %d, err: %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Fatalf("calls: %d", calls.Load())
+	}
+}
+
+func TestSingleFlight_DoChan(t *testing.T) {
+	sf := concurrent.NewSingleFlight[string, int]()
+	first := sf.DoChan("guild", func() (int, error) {
+		time.Sleep(time.Millisecond * 50)
+		return 1, nil
+	})
+	second := sf.DoChan("guild", func() (int, error) {
+		return 2, nil
+	})
+	for _, ch := range []<-chan concurrent.SingleFlightResult[int]{first, second} {
+		if result := <-ch; result.Val != 1 || !result.Shared {
+			t.Fatalf("result: %+v", result)
+		}
+	}
+}
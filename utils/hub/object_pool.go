@@ -3,6 +3,7 @@ package hub
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // NewObjectPool 创建一个 ObjectPool
@@ -10,30 +11,140 @@ func NewObjectPool[T any](generator func() *T, releaser func(data *T)) *ObjectPo
 	if generator == nil || releaser == nil {
 		panic(fmt.Errorf("generator and releaser can not be nil, generator check: %v, releaser check: %v", generator != nil, releaser != nil))
 	}
-	return &ObjectPool[*T]{
-		releaser: releaser,
-		p: sync.Pool{
-			New: func() interface{} {
-				return generator()
-			},
-		},
+	op := &ObjectPool[*T]{
+		generator: generator,
+		releaser:  releaser,
 	}
+	op.p.New = func() interface{} {
+		return op.generate()
+	}
+	return op
+}
+
+// ObjectPoolStats 对象池的统计信息
+type ObjectPoolStats struct {
+	Gets     int64 // 获取对象的次数
+	Releases int64 // 放回对象的次数
+	Misses   int64 // 缓冲区中无可用对象而新生成对象的次数
+	Discards int64 // 由于超出最大空闲数量而被丢弃的对象数量
+	Idle     int   // 当前空闲的对象数量，仅在设置了最大空闲数量时有效
 }
 
 // ObjectPool 基于 sync.Pool 实现的线程安全的对象池
 //   - 一些高频临时生成使用的对象可以通过 ObjectPool 进行管理，例如属性计算等
+//   - 当通过 SetMaxIdle 设置了最大空闲数量时，空闲对象将由对象池自行维护，而不再受 GC 影响
 type ObjectPool[T any] struct {
-	p        sync.Pool
-	releaser func(data T)
+	p         sync.Pool
+	generator func() T
+	releaser  func(data T)
+
+	lock    sync.Mutex
+	maxIdle atomic.Int64 // 最大空闲数量，<= 0 时由 sync.Pool 管理空闲对象
+	idle    []T          // 设置了最大空闲数量时的空闲对象
+
+	gets     atomic.Int64
+	releases atomic.Int64
+	misses   atomic.Int64
+	discards atomic.Int64
 }
 
 // Get 获取一个对象
 func (op *ObjectPool[T]) Get() T {
-	return op.p.Get().(T)
+	op.gets.Add(1)
+	if op.maxIdle.Load() <= 0 {
+		return op.p.Get().(T)
+	}
+	op.lock.Lock()
+	if n := len(op.idle); n > 0 {
+		data := op.idle[n-1]
+		var zero T
+		op.idle[n-1] = zero
+		op.idle = op.idle[:n-1]
+		op.lock.Unlock()
+		return data
+	}
+	op.lock.Unlock()
+	return op.generate()
 }
 
 // Release 将使用完成的对象放回缓冲区
 func (op *ObjectPool[T]) Release(data T) {
+	op.releases.Add(1)
 	op.releaser(data)
+	op.put(data)
+}
+
+// Warmup 预先生成 n 个对象放入缓冲区，以避免在高峰期集中生成对象
+//   - 当设置了最大空闲数量时，超出的部分将不会被生成
+//   - 未设置最大空闲数量时，预先生成的对象依旧可能被 GC 回收
+func (op *ObjectPool[T]) Warmup(n int) *ObjectPool[T] {
+	if maxIdle := int(op.maxIdle.Load()); maxIdle > 0 {
+		op.lock.Lock()
+		n = min(n, maxIdle-len(op.idle))
+		op.lock.Unlock()
+	}
+	for i := 0; i < n; i++ {
+		op.put(op.generator())
+	}
+	return op
+}
+
+// SetMaxIdle 设置缓冲区中的最大空闲数量，超出该数量的对象在放回时将被丢弃
+//   - 当 n <= 0 时将恢复由 sync.Pool 管理空闲对象，现有的空闲对象将被转移至 sync.Pool 中
+func (op *ObjectPool[T]) SetMaxIdle(n int) *ObjectPool[T] {
+	op.lock.Lock()
+	defer op.lock.Unlock()
+	op.maxIdle.Store(int64(n))
+	if n <= 0 {
+		for _, data := range op.idle {
+			op.p.Put(data)
+		}
+		op.idle = nil
+		return op
+	}
+	if excess := len(op.idle) - n; excess > 0 {
+		var zero T
+		for i := n; i < len(op.idle); i++ {
+			op.idle[i] = zero
+		}
+		op.idle = op.idle[:n]
+		op.discards.Add(int64(excess))
+	}
+	return op
+}
+
+// GetStats 获取对象池的统计信息
+func (op *ObjectPool[T]) GetStats() ObjectPoolStats {
+	op.lock.Lock()
+	idle := len(op.idle)
+	op.lock.Unlock()
+	return ObjectPoolStats{
+		Gets:     op.gets.Load(),
+		Releases: op.releases.Load(),
+		Misses:   op.misses.Load(),
+		Discards: op.discards.Load(),
+		Idle:     idle,
+	}
+}
+
+// generate 生成一个新的对象并记录未命中次数
+func (op *ObjectPool[T]) generate() T {
+	op.misses.Add(1)
+	return op.generator()
+}
+
+// put 将对象放入缓冲区
+func (op *ObjectPool[T]) put(data T) {
+	if maxIdle := int(op.maxIdle.Load()); maxIdle > 0 {
+		op.lock.Lock()
+		if len(op.idle) < maxIdle {
+			op.idle = append(op.idle, data)
+			op.lock.Unlock()
+			return
+		}
+		op.lock.Unlock()
+		op.discards.Add(1)
+		return
+	}
 	op.p.Put(data)
 }
@@ -91,3 +91,29 @@ func TestObjectPool_Release(t *testing.T) {
 		})
 	}
 }
+
+func TestObjectPool_SetMaxIdle(t *testing.T) {
+	pool := hub.NewObjectPool[map[string]int](func() *map[string]int {
+		return &map[string]int{}
+	}, func(data *map[string]int) {}).SetMaxIdle(2).Warmup(3)
+
+	if stats := pool.GetStats(); stats.Idle != 2 || stats.Misses != 0 {
+		t.Fatalf("TestObjectPool_SetMaxIdle warmup failed, got: %+v", stats)
+	}
+
+	var objects = []*map[string]int{pool.Get(), pool.Get(), pool.Get()}
+	if stats := pool.GetStats(); stats.Gets != 3 || stats.Misses != 1 || stats.Idle != 0 {
+		t.Fatalf("TestObjectPool_SetMaxIdle get failed, got: %+v", stats)
+	}
+
+	for _, object := range objects {
+		pool.Release(object)
+	}
+	if stats := pool.GetStats(); stats.Releases != 3 || stats.Discards != 1 || stats.Idle != 2 {
+		t.Fatalf("TestObjectPool_SetMaxIdle release failed, got: %+v", stats)
+	}
+
+	if stats := pool.SetMaxIdle(1).GetStats(); stats.Discards != 2 || stats.Idle != 1 {
+		t.Fatalf("TestObjectPool_SetMaxIdle shrink failed, got: %+v", stats)
+	}
+}
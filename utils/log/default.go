@@ -3,6 +3,7 @@ package log
 import (
 	"context"
 	"errors"
+	"github.com/kercylan98/minotaur/utils/super"
 	"log/slog"
 	"os"
 	"sync/atomic"
@@ -71,6 +72,11 @@ func Fatal(msg string, args ...any) {
 	os.Exit(1)
 }
 
+// PanicHook 在 ErrorLevel 记录 panic 及其堆栈信息，可通过 super.RegPanicHook(log.PanicHook) 将 super.GoSafe 等函数捕获的 panic 输出到日志
+func PanicHook(err *super.PanicError) {
+	handle(ErrorLevel, "Panic", Err(err.Err), String("stack", string(err.Stack)))
+}
+
 // handle 在指定的级别记录一条消息。该消息包括在日志站点传递的任何字段以及记录器上累积的任何字段
 func handle(level slog.Level, msg string, args ...any) {
 	d := Default()
@@ -0,0 +1,159 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// GNetLogger gnet 日志适配器，可通过 gnet.WithLogger(new(log.GNetLogger)) 将 gnet 的日志输出至全局日志记录器
+type GNetLogger struct{}
+
+func (g *GNetLogger) Debugf(format string, args ...any) {
+	logger.Load().Debug(fmt.Sprintf(format, args...))
+}
+
+func (g *GNetLogger) Infof(format string, args ...any) {
+	logger.Load().Info(fmt.Sprintf(format, args...))
+}
+
+func (g *GNetLogger) Warnf(format string, args ...any) {
+	logger.Load().Warn(fmt.Sprintf(format, args...))
+}
+
+func (g *GNetLogger) Errorf(format string, args ...any) {
+	logger.Load().Error(fmt.Sprintf(format, args...))
+}
+
+func (g *GNetLogger) Fatalf(format string, args ...any) {
+	logger.Load().Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// AntsLogger ants 日志适配器，可通过 ants.WithLogger(new(log.AntsLogger)) 将 ants 的日志输出至全局日志记录器
+//   - ants 仅通过该接口输出协程池中发生的 panic 等异常信息，因此将以 LevelError 级别记录
+type AntsLogger struct{}
+
+func (a *AntsLogger) Printf(format string, args ...any) {
+	logger.Load().Error(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+// NewGRPCLogger 创建一个 gRPC 日志适配器，可通过 grpclog.SetLoggerV2(log.NewGRPCLogger(0)) 将 gRPC 的日志输出至全局日志记录器
+//   - verbosity 为 gRPC 的详细日志级别，仅当 gRPC 请求的级别小于等于 verbosity 时才会输出详细日志
+func NewGRPCLogger(verbosity int) *GRPCLogger {
+	return &GRPCLogger{verbosity: verbosity}
+}
+
+// GRPCLogger gRPC 日志适配器，实现了 grpclog.LoggerV2 接口
+type GRPCLogger struct {
+	verbosity int
+}
+
+func (g *GRPCLogger) Info(args ...any) {
+	logger.Load().Info(fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Infoln(args ...any) {
+	logger.Load().Info(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (g *GRPCLogger) Infof(format string, args ...any) {
+	logger.Load().Info(fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) Warning(args ...any) {
+	logger.Load().Warn(fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Warningln(args ...any) {
+	logger.Load().Warn(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (g *GRPCLogger) Warningf(format string, args ...any) {
+	logger.Load().Warn(fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) Error(args ...any) {
+	logger.Load().Error(fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Errorln(args ...any) {
+	logger.Load().Error(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (g *GRPCLogger) Errorf(format string, args ...any) {
+	logger.Load().Error(fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) Fatal(args ...any) {
+	logger.Load().Error(fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+func (g *GRPCLogger) Fatalln(args ...any) {
+	logger.Load().Error(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	os.Exit(1)
+}
+
+func (g *GRPCLogger) Fatalf(format string, args ...any) {
+	logger.Load().Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func (g *GRPCLogger) V(l int) bool {
+	return l <= g.verbosity
+}
+
+// NewWriter 创建一个将每行写入内容以特定级别输出至全局日志记录器的 io.Writer
+//   - 适用于仅支持 io.Writer 的场景，例如 gin.DefaultWriter、gin.DefaultErrorWriter 等
+func NewWriter(level Level) *Writer {
+	return &Writer{level: level}
+}
+
+// Writer 将写入内容输出至全局日志记录器的 io.Writer
+type Writer struct {
+	level Level
+}
+
+func (w *Writer) Write(p []byte) (n int, err error) {
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			logger.Load().Log(context.Background(), w.level, line)
+		}
+	}
+	return len(p), nil
+}
+
+// NewStdLogger 创建一个以特定级别输出至全局日志记录器的标准库 *log.Logger，例如用于 http.Server.ErrorLog
+func NewStdLogger(level Level) *stdlog.Logger {
+	return stdlog.New(NewWriter(level), "", 0)
+}
+
+// RedirectStd 将标准库 log 及 log/slog 的默认输出重定向至全局日志记录器
+//   - 重定向后通过 SetLogger 设置的全局日志记录器同样生效
+//   - 标准库 log 的输出将以 LevelInfo 级别记录
+func RedirectStd() {
+	slog.SetDefault(slog.New(new(bridgeHandler)))
+}
+
+// bridgeHandler 将日志转发至当前全局日志记录器的处理器
+type bridgeHandler struct{}
+
+func (h *bridgeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return logger.Load().Handler().Enabled(ctx, level)
+}
+
+func (h *bridgeHandler) Handle(ctx context.Context, record slog.Record) error {
+	return logger.Load().Handler().Handle(ctx, record)
+}
+
+func (h *bridgeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logger.Load().Handler().WithAttrs(attrs)
+}
+
+func (h *bridgeHandler) WithGroup(name string) slog.Handler {
+	return logger.Load().Handler().WithGroup(name)
+}
@@ -0,0 +1,36 @@
+package log_test
+
+import (
+	"bytes"
+	"github.com/kercylan98/minotaur/utils/log/v2"
+	"google.golang.org/grpc/grpclog"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+var _ grpclog.LoggerV2 = log.NewGRPCLogger(0)
+
+func TestAdapters(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetLogger(log.NewLogger(log.NewHandler(&buf, log.DefaultOptions().WithDisableColor(true))))
+	defer log.ResetLogger()
+
+	new(log.GNetLogger).Warnf("gnet %d", 1)
+	log.NewGRPCLogger(0).Errorln("grpc", 2)
+	_, _ = log.NewWriter(log.LevelInfo).Write([]byte("[GIN] 200 GET /healthz\n"))
+	defer slog.SetDefault(slog.Default())
+	log.RedirectStd()
+	slog.Info("slog", "Name", "Jerry")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, expected := range []string{"gnet 1", "grpc 2", "[GIN] 200 GET /healthz", "slog"} {
+		if i >= len(lines) || !strings.Contains(lines[i], expected) {
+			t.Fatalf("line %d should contain %q, logs:\n%s", i, expected, buf.String())
+		}
+		t.Log(lines[i])
+	}
+	if !strings.Contains(lines[0], "adapter_test.go") || !strings.Contains(lines[3], "Jerry") {
+		t.Fatalf("unexpected logs:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+const (
+	TraceIDKey = "trace_id" // 追踪 ID 的字段名称
+	SpanIDKey  = "span_id"  // 跨度 ID 的字段名称
+	ConnIDKey  = "conn_id"  // 连接 ID 的字段名称
+)
+
+type contextFieldsKey struct{}
+
+// ContextWithFields 返回一个注入了日志字段的上下文，字段将被追加在上下文中已存在的字段之后
+//   - 通过 WithContext 获取的日志记录器及通过 Logger.InfoContext 等函数记录的日志都将附带这些字段
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	exists := FieldsFromContext(ctx)
+	merged := make([]Field, 0, len(exists)+len(fields))
+	merged = append(append(merged, exists...), fields...)
+	return context.WithValue(ctx, contextFieldsKey{}, merged)
+}
+
+// ContextWithTraceID 返回一个注入了追踪 ID 的上下文
+func ContextWithTraceID(ctx context.Context, traceId string) context.Context {
+	return ContextWithFields(ctx, String(TraceIDKey, traceId))
+}
+
+// ContextWithSpanID 返回一个注入了跨度 ID 的上下文
+func ContextWithSpanID(ctx context.Context, spanId string) context.Context {
+	return ContextWithFields(ctx, String(SpanIDKey, spanId))
+}
+
+// ContextWithConnID 返回一个注入了连接 ID 的上下文
+func ContextWithConnID(ctx context.Context, connId string) context.Context {
+	return ContextWithFields(ctx, String(ConnIDKey, connId))
+}
+
+// FieldsFromContext 获取上下文中注入的日志字段
+func FieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextFieldsKey{}).([]Field)
+	return fields
+}
+
+// WithContext 基于全局日志记录器返回一个附带上下文中日志字段的日志记录器，以便通过同一 ID 检索一次请求产生的所有日志
+//   - 上下文中的字段通常是由服务器注入的追踪 ID、跨度 ID 及连接 ID 等
+func WithContext(ctx context.Context) *Logger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return GetLogger()
+	}
+	// contextHandler 将额外增加一层调用栈
+	h := logger.Load().Handler()
+	if cloned := cloneHandlerWithCallerSkip(h, 0); cloned != nil {
+		h = cloned
+	}
+	return NewLogger(&contextHandler{Handler: h, fields: fields})
+}
+
+// contextHandler 为每条日志追加固定字段的处理器
+type contextHandler struct {
+	Handler
+	fields []Field
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	record = record.Clone()
+	record.AddAttrs(h.fields...)
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), fields: h.fields}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), fields: h.fields}
+}
@@ -55,6 +55,10 @@ func (h *MinotaurHandler) Handle(ctx context.Context, record slog.Record) (err e
 		processLevel(buffer, record, opt)
 		processCaller(buffer, record, opt)
 		processMessage(buffer, record, opt)
+		if fields := FieldsFromContext(ctx); len(fields) > 0 {
+			record = record.Clone()
+			record.AddAttrs(fields...)
+		}
 		processAttrs(buffer, h, record, opt, record.Level, h.groupPrefix, h.groups)
 
 		if buffer.Len() == 0 {
@@ -77,15 +77,19 @@ func Log(level Level, msg string, args ...any) {
 }
 
 func cloneHandler(h Handler) Handler {
+	return cloneHandlerWithCallerSkip(h, -1)
+}
+
+func cloneHandlerWithCallerSkip(h Handler, skip int) Handler {
 	switch h := h.(type) {
 	case *MinotaurHandler:
 		cloneHandler := h.clone()
-		cloneHandler.GetOptions().WithCallerSkip(-1)
+		cloneHandler.GetOptions().WithCallerSkip(skip)
 		return cloneHandler
 	case *MultiHandler:
 		var handlers = make([]Handler, 0, len(h.handlers))
 		for _, handler := range h.handlers {
-			handlers = append(handlers, cloneHandler(handler))
+			handlers = append(handlers, cloneHandlerWithCallerSkip(handler, skip))
 		}
 		return NewMultiHandler(handlers...)
 	}
@@ -1,8 +1,11 @@
 package log_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"github.com/kercylan98/minotaur/utils/log/v2"
+	"strings"
 	"testing"
 )
 
@@ -17,3 +20,20 @@ func TestLogger(t *testing.T) {
 	}
 
 }
+
+func TestWithContext(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetLogger(log.NewLogger(log.NewHandler(&buf, log.DefaultOptions().WithDisableColor(true))))
+	defer log.ResetLogger()
+
+	ctx := log.ContextWithConnID(log.ContextWithTraceID(context.Background(), "trace-1"), "conn-1")
+	log.WithContext(ctx).Info("TestWithContext", log.String("Name", "Jerry"))
+	log.GetLogger().InfoContext(ctx, "TestInfoContext")
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		t.Log(line)
+		if !strings.Contains(line, "trace-1") || !strings.Contains(line, "conn-1") || !strings.Contains(line, "logger_test.go") {
+			t.Fatalf("unexpected log: %s", line)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package maths
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/utils/generic"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var ErrDecimalInvalid = errors.New("maths: invalid decimal string")
+
+// AbbreviationUnits 通过 Decimal.Abbreviate 格式化时每 3 个数量级使用的单位，超出后将依次使用 aa、ab ... zz、aaa 等单位
+//   - 可根据需要修改为其他单位，例如 []string{"", "K", "M", "B", "T", "Qa", "Qi"}
+var AbbreviationUnits = []string{"", "K", "M", "B", "T"}
+
+var bigTen = big.NewInt(10)
+
+// Decimal 基于 big.Int 的定点十进制数，适用于数值超出 int64 范围的放置类游戏经济数值
+//   - 值为 value * 10^-scale，零值即为 0，可直接使用
+//   - Decimal 是不可变的，所有运算均返回新的 Decimal
+type Decimal struct {
+	value *big.Int
+	scale int
+}
+
+// NewDecimal 通过整数创建 Decimal
+func NewDecimal[T generic.Integer](x T) Decimal {
+	if x < 0 {
+		return Decimal{value: new(big.Int).SetInt64(int64(x))}
+	}
+	return Decimal{value: new(big.Int).SetUint64(uint64(x))}
+}
+
+// NewDecimalFromBigInt 通过 big.Int 创建 Decimal，值为 value * 10^-scale，当 scale < 0 时将视为 0
+func NewDecimalFromBigInt(value *big.Int, scale int) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	return Decimal{value: new(big.Int).Set(value), scale: scale}
+}
+
+// NewDecimalFromFloat 通过浮点数创建 Decimal，保留 scale 位小数
+func NewDecimalFromFloat(f float64, scale int) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'f', scale, 64))
+	if err != nil {
+		return Decimal{}
+	}
+	return d
+}
+
+// ParseDecimal 解析十进制字符串，支持 "-12.34" 及 "1.5e30" 形式
+func ParseDecimal(s string) (Decimal, error) {
+	var mantissa, exponent = s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, ErrDecimalInvalid
+		}
+		mantissa, exponent = s[:i], exp
+	}
+	var scale int
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		scale = len(mantissa) - i - 1
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+	if mantissa == "" || mantissa == "-" || mantissa == "+" || strings.ContainsAny(mantissa[1:], "+-") {
+		return Decimal{}, ErrDecimalInvalid
+	}
+	value, ok := new(big.Int).SetString(mantissa, 10)
+	if !ok {
+		return Decimal{}, ErrDecimalInvalid
+	}
+	scale -= exponent
+	if scale < 0 {
+		value.Mul(value, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{value: value, scale: scale}, nil
+}
+
+// MustParseDecimal 解析十进制字符串，解析失败时将引发 panic
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// pow10 返回 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// bigInt 返回 Decimal 的 value，零值时返回 0
+func (d Decimal) bigInt() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return d.value
+}
+
+// align 将两个 Decimal 对齐至相同的小数位数
+func (d Decimal) align(o Decimal) (a, b *big.Int, scale int) {
+	switch {
+	case d.scale > o.scale:
+		return d.bigInt(), new(big.Int).Mul(o.bigInt(), pow10(d.scale-o.scale)), d.scale
+	case d.scale < o.scale:
+		return new(big.Int).Mul(d.bigInt(), pow10(o.scale-d.scale)), o.bigInt(), o.scale
+	default:
+		return d.bigInt(), o.bigInt(), d.scale
+	}
+}
+
+// Scale 获取小数位数
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Sign 当 d < 0 时返回 -1，当 d == 0 时返回 0，当 d > 0 时返回 1
+func (d Decimal) Sign() int {
+	return d.bigInt().Sign()
+}
+
+// IsZero 检查是否为 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp 比较，当 d > o 时返回 1，当 d < o 时返回 -1，当 d == o 时返回 0
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := d.align(o)
+	return a.Cmp(b)
+}
+
+// Add 返回 d + o
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := d.align(o)
+	return Decimal{value: new(big.Int).Add(a, b), scale: scale}
+}
+
+// Sub 返回 d - o
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := d.align(o)
+	return Decimal{value: new(big.Int).Sub(a, b), scale: scale}
+}
+
+// Mul 返回 d * o，结果的小数位数为两者之和，可通过 Round 或 Truncate 调整
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.bigInt(), o.bigInt()), scale: d.scale + o.scale}
+}
+
+// Div 返回 d / o，结果保留 scale 位小数，多余部分向零截断
+//   - 当 o 为 0 时将引发 panic
+func (d Decimal) Div(o Decimal, scale int) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	var num = new(big.Int).Mul(d.bigInt(), pow10(scale+o.scale))
+	var den = new(big.Int).Mul(o.bigInt(), pow10(d.scale))
+	return Decimal{value: num.Quo(num, den), scale: scale}
+}
+
+// Neg 返回 -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.bigInt()), scale: d.scale}
+}
+
+// Abs 返回 d 的绝对值
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.bigInt()), scale: d.scale}
+}
+
+// Truncate 将小数位数调整为 scale，多余部分向零截断
+func (d Decimal) Truncate(scale int) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if scale >= d.scale {
+		return Decimal{value: new(big.Int).Mul(d.bigInt(), pow10(scale-d.scale)), scale: scale}
+	}
+	return Decimal{value: new(big.Int).Quo(d.bigInt(), pow10(d.scale-scale)), scale: scale}
+}
+
+// Round 将小数位数调整为 scale，多余部分四舍五入（远离零）
+func (d Decimal) Round(scale int) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if scale >= d.scale {
+		return d.Truncate(scale)
+	}
+	var q, r = new(big.Int).QuoRem(d.bigInt(), pow10(d.scale-scale), new(big.Int))
+	if r.Abs(r).Mul(r, big.NewInt(2)).Cmp(pow10(d.scale-scale)) >= 0 {
+		if d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Decimal{value: q, scale: scale}
+}
+
+// BigInt 返回向零截断后的整数部分
+func (d Decimal) BigInt() *big.Int {
+	return d.Truncate(0).value
+}
+
+// Int64 返回向零截断后的整数部分，当超出 int64 范围时 ok 为 false
+func (d Decimal) Int64() (v int64, ok bool) {
+	i := d.BigInt()
+	if !i.IsInt64() {
+		return 0, false
+	}
+	return i.Int64(), true
+}
+
+// Float64 返回最接近的 float64 值，超出范围时将返回 ±Inf
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String 返回完整的十进制字符串，例如 "-12.340"
+func (d Decimal) String() string {
+	var digits = new(big.Int).Abs(d.bigInt()).String()
+	var builder strings.Builder
+	if d.Sign() < 0 {
+		builder.WriteByte('-')
+	}
+	if d.scale == 0 {
+		builder.WriteString(digits)
+		return builder.String()
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	builder.WriteString(digits[:len(digits)-d.scale])
+	builder.WriteByte('.')
+	builder.WriteString(digits[len(digits)-d.scale:])
+	return builder.String()
+}
+
+// Abbreviate 返回以 AbbreviationUnits 缩写的字符串，保留 precision 位小数并去除末尾的 0，例如 "1.2K"、"3.4M"、"5aa"
+//   - 为避免出现 "1000K" 这类结果，多余的小数将向零截断而非四舍五入
+func (d Decimal) Abbreviate(precision int) string {
+	if precision < 0 {
+		precision = 0
+	}
+	var digits = len(new(big.Int).Abs(d.BigInt()).String())
+	var group = (digits - 1) / 3
+	var scaled = d
+	if group > 0 {
+		scaled = Decimal{value: d.bigInt(), scale: d.scale + group*3}
+	}
+	var s = scaled.Truncate(precision).String()
+	if strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s + abbreviationUnit(group)
+}
+
+// abbreviationUnit 获取第 group 组数量级的单位
+func abbreviationUnit(group int) string {
+	if group < len(AbbreviationUnits) {
+		return AbbreviationUnits[group]
+	}
+	var n, width, count = group - len(AbbreviationUnits), 2, 26 * 26
+	for n >= count {
+		n -= count
+		width++
+		count *= 26
+	}
+	var letters = make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		letters[i] = 'a' + byte(n%26)
+		n /= 26
+	}
+	return string(letters)
+}
+
+// MarshalText 实现 encoding.TextMarshaler 接口，以完整的十进制字符串进行序列化
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler 接口
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,86 @@
+package maths_test
+
+import (
+	"encoding/json"
+	"github.com/kercylan98/minotaur/utils/maths"
+	"testing"
+)
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	var a = maths.MustParseDecimal("12345678901234567890123.45")
+	var b = maths.MustParseDecimal("0.055")
+
+	var cases = []struct {
+		name   string
+		result maths.Decimal
+		expect string
+	}{
+		{name: "add", result: a.Add(b), expect: "12345678901234567890123.505"},
+		{name: "sub", result: b.Sub(a), expect: "-12345678901234567890123.395"},
+		{name: "mul", result: maths.NewDecimal(3).Mul(b), expect: "0.165"},
+		{name: "div", result: maths.NewDecimal(10).Div(maths.NewDecimal(3), 4), expect: "3.3333"},
+		{name: "round", result: b.Round(2), expect: "0.06"},
+		{name: "round-negative", result: b.Neg().Round(2), expect: "-0.06"},
+		{name: "truncate", result: b.Truncate(2), expect: "0.05"},
+		{name: "exponent", result: maths.MustParseDecimal("1.5e30"), expect: "1500000000000000000000000000000"},
+		{name: "zero-value", result: maths.Decimal{}.Add(maths.NewDecimal(1)), expect: "1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if s := c.result.String(); s != c.expect {
+				t.Fatalf("%s != %s", s, c.expect)
+			}
+		})
+	}
+
+	if a.Cmp(b) != 1 || b.Cmp(maths.MustParseDecimal("0.0550")) != 0 {
+		t.Fatal("unexpected compare result")
+	}
+	if _, err := maths.ParseDecimal("1.2.3"); err == nil {
+		t.Fatal("parse invalid decimal should return error")
+	}
+}
+
+func TestDecimal_Abbreviate(t *testing.T) {
+	var cases = []struct {
+		value     string
+		precision int
+		expect    string
+	}{
+		{value: "999", precision: 1, expect: "999"},
+		{value: "1200", precision: 1, expect: "1.2K"},
+		{value: "1000", precision: 2, expect: "1K"},
+		{value: "3456789", precision: 1, expect: "3.4M"},
+		{value: "999999", precision: 1, expect: "999.9K"},
+		{value: "-2500000000", precision: 1, expect: "-2.5B"},
+		{value: "1e15", precision: 1, expect: "1aa"},
+		{value: "2.5e18", precision: 1, expect: "2.5ab"},
+		{value: "0.456", precision: 2, expect: "0.45"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			if s := maths.MustParseDecimal(c.value).Abbreviate(c.precision); s != c.expect {
+				t.Fatalf("%s != %s", s, c.expect)
+			}
+		})
+	}
+}
+
+func TestDecimal_MarshalText(t *testing.T) {
+	var v = struct {
+		Gold maths.Decimal
+	}{Gold: maths.MustParseDecimal("123456789012345678901234567890.5")}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Gold = maths.Decimal{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Gold.String() != "123456789012345678901234567890.5" {
+		t.Fatalf("unexpected value: %s", v.Gold)
+	}
+}
@@ -0,0 +1,132 @@
+package maths
+
+import (
+	"github.com/kercylan98/minotaur/utils/generic"
+	"math"
+	"math/bits"
+)
+
+// GCD 返回 a 和 b 的最大公约数，结果始终为非负数，当 a 和 b 均为 0 时返回 0
+func GCD[V generic.Integer](a, b V) V {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// LCM 返回 a 和 b 的最小公倍数，结果始终为非负数，当 a 或 b 为 0 时返回 0
+//   - 例如计算多个不同周期的奖励何时同时刷新
+func LCM[V generic.Integer](a, b V) V {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	lcm := a / GCD(a, b) * b
+	if lcm < 0 {
+		lcm = -lcm
+	}
+	return lcm
+}
+
+// IsPrime 返回 n 是否为质数，对于 64 位范围内的整数均能给出确定的结果
+func IsPrime[V generic.Integer](n V) bool {
+	if n < 2 {
+		return false
+	}
+	u := uint64(n)
+	for _, p := range []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37} {
+		if u%p == 0 {
+			return u == p
+		}
+	}
+	// 对于 64 位整数，使用前 12 个质数作为底数的 Miller-Rabin 测试是确定性的
+	d, s := u-1, 0
+	for d&1 == 0 {
+		d >>= 1
+		s++
+	}
+	for _, a := range []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37} {
+		x := powMod(a, d, u)
+		if x == 1 || x == u-1 {
+			continue
+		}
+		composite := true
+		for i := 1; i < s; i++ {
+			if x = mulMod(x, x, u); x == u-1 {
+				composite = false
+				break
+			}
+		}
+		if composite {
+			return false
+		}
+	}
+	return true
+}
+
+// CombinationCount 返回从 n 个元素中选取 k 个元素的组合数 C(n, k)，当结果溢出 int64 时 ok 将为 false
+//   - 当 k < 0 或 k > n 时返回 0
+func CombinationCount[V generic.Integer](n, k V) (count int64, ok bool) {
+	if k < 0 || n < 0 || k > n {
+		return 0, true
+	}
+	nn, kk := int64(n), int64(k)
+	if kk > nn-kk {
+		kk = nn - kk
+	}
+	count = 1
+	for i := int64(1); i <= kk; i++ {
+		// 先约去公因数，保证 count * (nn - kk + i) / i 的每一步均为整数且尽量不溢出
+		g := GCD(count, i)
+		count /= g
+		factor := (nn - kk + i) / (i / g)
+		if count > math.MaxInt64/factor {
+			return 0, false
+		}
+		count *= factor
+	}
+	return count, true
+}
+
+// PermutationCount 返回从 n 个元素中选取 k 个元素的排列数 A(n, k)，当结果溢出 int64 时 ok 将为 false
+//   - 当 k < 0 或 k > n 时返回 0
+func PermutationCount[V generic.Integer](n, k V) (count int64, ok bool) {
+	if k < 0 || n < 0 || k > n {
+		return 0, true
+	}
+	nn, kk := int64(n), int64(k)
+	count = 1
+	for i := nn; i > nn-kk; i-- {
+		if count > math.MaxInt64/i {
+			return 0, false
+		}
+		count *= i
+	}
+	return count, true
+}
+
+// mulMod 返回 a * b % m，避免乘法溢出
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, rem := bits.Div64(hi%m, lo, m)
+	return rem
+}
+
+// powMod 返回 a ^ e % m
+func powMod(a, e, m uint64) uint64 {
+	result := uint64(1)
+	a %= m
+	for e > 0 {
+		if e&1 == 1 {
+			result = mulMod(result, a, m)
+		}
+		a = mulMod(a, a, m)
+		e >>= 1
+	}
+	return result
+}
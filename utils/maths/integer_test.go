@@ -0,0 +1,81 @@
+package maths_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/maths"
+	"math"
+	"testing"
+)
+
+func TestGCDAndLCM(t *testing.T) {
+	var cases = []struct {
+		name     string
+		a, b     int
+		gcd, lcm int
+	}{
+		{name: "normal", a: 12, b: 18, gcd: 6, lcm: 36},
+		{name: "coprime", a: 7, b: 9, gcd: 1, lcm: 63},
+		{name: "negative", a: -4, b: 6, gcd: 2, lcm: 12},
+		{name: "zero", a: 0, b: 5, gcd: 5, lcm: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if gcd := maths.GCD(c.a, c.b); gcd != c.gcd {
+				t.Fatalf("gcd %v != %v", gcd, c.gcd)
+			}
+			if lcm := maths.LCM(c.a, c.b); lcm != c.lcm {
+				t.Fatalf("lcm %v != %v", lcm, c.lcm)
+			}
+		})
+	}
+}
+
+func TestIsPrime(t *testing.T) {
+	var cases = []struct {
+		name  string
+		n     int64
+		prime bool
+	}{
+		{name: "one", n: 1, prime: false},
+		{name: "two", n: 2, prime: true},
+		{name: "composite", n: 91, prime: false},
+		{name: "carmichael", n: 561, prime: false},
+		{name: "prime", n: 7919, prime: true},
+		{name: "large_prime", n: 9223372036854775783, prime: true},
+		{name: "large_composite", n: math.MaxInt64, prime: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if prime := maths.IsPrime(c.n); prime != c.prime {
+				t.Fatalf("IsPrime(%v) %v != %v", c.n, prime, c.prime)
+			}
+		})
+	}
+}
+
+func TestCombinationCountAndPermutationCount(t *testing.T) {
+	var cases = []struct {
+		name     string
+		n, k     int
+		c, a     int64
+		cOk, aOk bool
+	}{
+		{name: "normal", n: 5, k: 2, c: 10, a: 20, cOk: true, aOk: true},
+		{name: "zero", n: 5, k: 0, c: 1, a: 1, cOk: true, aOk: true},
+		{name: "out_of_range", n: 2, k: 5, c: 0, a: 0, cOk: true, aOk: true},
+		{name: "large", n: 66, k: 33, c: 7219428434016265740, cOk: true, aOk: false},
+		{name: "overflow", n: 68, k: 34, cOk: false, aOk: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if v, ok := maths.CombinationCount(c.n, c.k); ok != c.cOk || (ok && v != c.c) {
+				t.Fatalf("C(%v, %v) %v, %v != %v, %v", c.n, c.k, v, ok, c.c, c.cOk)
+			}
+			if v, ok := maths.PermutationCount(c.n, c.k); ok != c.aOk || (ok && v != c.a) {
+				t.Fatalf("A(%v, %v) %v, %v != %v, %v", c.n, c.k, v, ok, c.a, c.aOk)
+			}
+		})
+	}
+}
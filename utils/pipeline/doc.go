@@ -0,0 +1,3 @@
+// Package pipeline 提供了基于有向无环图的任务流水线，支持阶段依赖、并发限制、上下文取消及阶段重试
+//   - 适用于服务器启动流程、批量结算任务、配置导出等由多个存在依赖关系的步骤组成的场景
+package pipeline
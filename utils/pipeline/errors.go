@@ -0,0 +1,29 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrStageExist 阶段已存在
+	ErrStageExist = errors.New("pipeline stage already exists")
+	// ErrStageNotExist 依赖的阶段不存在
+	ErrStageNotExist = errors.New("pipeline stage not exist")
+	// ErrCycle 阶段之间存在循环依赖
+	ErrCycle = errors.New("pipeline stages have cyclic dependencies")
+)
+
+// StageError 阶段执行失败的错误
+type StageError struct {
+	Stage string // 失败的阶段名称
+	Err   error  // 阶段最后一次执行返回的错误
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("pipeline stage %s failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
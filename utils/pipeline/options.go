@@ -0,0 +1,31 @@
+package pipeline
+
+import "time"
+
+// Option 流水线选项
+type Option func(p *Pipeline)
+
+// WithConcurrency 设置同时执行的阶段数量上限，当 limit <= 0 时不进行限制
+func WithConcurrency(limit int) Option {
+	return func(p *Pipeline) {
+		p.concurrency = limit
+	}
+}
+
+// StageOption 阶段选项
+type StageOption func(s *stage)
+
+// WithDependOn 设置阶段依赖的其他阶段，仅当依赖的阶段全部执行成功后才会执行该阶段
+func WithDependOn(stages ...string) StageOption {
+	return func(s *stage) {
+		s.depends = append(s.depends, stages...)
+	}
+}
+
+// WithRetry 设置阶段执行失败后的重试次数及重试间隔，上下文被取消时将不再重试
+func WithRetry(count int, interval time.Duration) StageOption {
+	return func(s *stage) {
+		s.retry = count
+		s.interval = interval
+	}
+}
@@ -0,0 +1,203 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/super"
+	"time"
+)
+
+const (
+	StatePending   State = iota // 等待执行
+	StateRunning                // 正在执行
+	StateSucceeded              // 执行成功
+	StateFailed                 // 执行失败
+	StateSkipped                // 由于其他阶段失败或上下文被取消而未执行
+)
+
+// State 阶段状态
+type State byte
+
+// New 创建一个流水线
+func New(options ...Option) *Pipeline {
+	p := &Pipeline{
+		stages: make(map[string]*stage),
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// stage 流水线中的阶段
+type stage struct {
+	name     string
+	run      func(ctx context.Context) error
+	depends  []string
+	retry    int
+	interval time.Duration
+	state    State
+}
+
+// Pipeline 由多个阶段组成的流水线，阶段之间通过依赖关系构成有向无环图
+//   - 没有依赖关系的阶段将并发执行，任一阶段失败后将取消传递给其他阶段的上下文，尚未开始的阶段将被跳过
+//   - 该实例不是线程安全的，不应在执行期间添加阶段或同时多次执行
+type Pipeline struct {
+	concurrency int
+	stages      map[string]*stage
+	order       []string
+}
+
+// Stage 添加一个阶段，阶段名称不可重复，重复添加时将发生 panic
+func (p *Pipeline) Stage(name string, run func(ctx context.Context) error, options ...StageOption) *Pipeline {
+	if _, exist := p.stages[name]; exist {
+		panic(fmt.Errorf("%w: %s", ErrStageExist, name))
+	}
+	s := &stage{name: name, run: run}
+	for _, option := range options {
+		option(s)
+	}
+	p.stages[name] = s
+	p.order = append(p.order, name)
+	return p
+}
+
+// GetState 获取阶段在最近一次执行中的状态
+func (p *Pipeline) GetState(name string) State {
+	if s, exist := p.stages[name]; exist {
+		return s.state
+	}
+	return StatePending
+}
+
+// Run 执行流水线，直到所有阶段执行完毕、任一阶段失败或 ctx 被取消
+//   - 当存在不存在的依赖或循环依赖时，将在执行任何阶段之前返回错误
+//   - 阶段失败时将返回 *StageError，ctx 被取消时将返回 ctx.Err()
+func (p *Pipeline) Run(ctx context.Context) error {
+	dependents, indegree, err := p.build()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		stage *stage
+		err   error
+	}
+	var ready []*stage
+	for _, name := range p.order {
+		s := p.stages[name]
+		s.state = StatePending
+		if indegree[name] == 0 {
+			ready = append(ready, s)
+		}
+	}
+
+	var running int
+	var failed error
+	var results = make(chan result)
+	for len(ready) > 0 || running > 0 {
+		for failed == nil && ctx.Err() == nil && len(ready) > 0 && (p.concurrency <= 0 || running < p.concurrency) {
+			s := ready[0]
+			ready = ready[1:]
+			s.state = StateRunning
+			running++
+			go func(s *stage) {
+				results <- result{stage: s, err: s.execute(ctx)}
+			}(s)
+		}
+		if running == 0 {
+			break
+		}
+		r := <-results
+		running--
+		if r.err != nil {
+			r.stage.state = StateFailed
+			if failed == nil {
+				failed = &StageError{Stage: r.stage.name, Err: r.err}
+				cancel()
+			}
+			continue
+		}
+		r.stage.state = StateSucceeded
+		for _, name := range dependents[r.stage.name] {
+			if indegree[name]--; indegree[name] == 0 {
+				ready = append(ready, p.stages[name])
+			}
+		}
+	}
+
+	for _, s := range p.stages {
+		if s.state == StatePending {
+			s.state = StateSkipped
+		}
+	}
+	if failed != nil {
+		return failed
+	}
+	return ctx.Err()
+}
+
+// build 检查依赖关系并计算各阶段的后继阶段及入度
+func (p *Pipeline) build() (dependents map[string][]string, indegree map[string]int, err error) {
+	dependents = make(map[string][]string)
+	indegree = make(map[string]int)
+	for _, name := range p.order {
+		for _, depend := range p.stages[name].depends {
+			if _, exist := p.stages[depend]; !exist {
+				return nil, nil, fmt.Errorf("%w: %s depends on %s", ErrStageNotExist, name, depend)
+			}
+			dependents[depend] = append(dependents[depend], name)
+			indegree[name]++
+		}
+	}
+
+	var visited int
+	var queue []string
+	var remaining = make(map[string]int, len(indegree))
+	for _, name := range p.order {
+		remaining[name] = indegree[name]
+		if indegree[name] == 0 {
+			queue = append(queue, name)
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, dependent := range dependents[name] {
+			if remaining[dependent]--; remaining[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	if visited != len(p.order) {
+		return nil, nil, ErrCycle
+	}
+	return dependents, indegree, nil
+}
+
+// execute 执行阶段，失败时根据重试设置进行重试
+func (s *stage) execute(ctx context.Context) (err error) {
+	for i := 0; ; i++ {
+		if err = s.call(ctx); err == nil || i >= s.retry || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(s.interval):
+		}
+	}
+}
+
+// call 执行阶段函数并将 panic 转换为错误
+func (s *stage) call(ctx context.Context) (err error) {
+	defer func() {
+		if e := super.RecoverTransform(recover()); e != nil {
+			err = e
+		}
+	}()
+	return s.run(ctx)
+}
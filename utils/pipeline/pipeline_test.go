@@ -0,0 +1,68 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"github.com/kercylan98/minotaur/utils/pipeline"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPipeline_Run(t *testing.T) {
+	var lock sync.Mutex
+	var order []string
+	var running, peak, attempts atomic.Int32
+	record := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if n := running.Add(1); n > peak.Load() {
+				peak.Store(n)
+			}
+			defer running.Add(-1)
+			time.Sleep(time.Millisecond * 10)
+			lock.Lock()
+			order = append(order, name)
+			lock.Unlock()
+			return nil
+		}
+	}
+
+	p := pipeline.New(pipeline.WithConcurrency(2)).
+		Stage("config", record("config")).
+		Stage("db", record("db"), pipeline.WithDependOn("config")).
+		Stage("cache", record("cache"), pipeline.WithDependOn("config")).
+		Stage("rank", record("rank"), pipeline.WithDependOn("config")).
+		Stage("listen", func(ctx context.Context) error {
+			if attempts.Add(1) < 3 {
+				return errors.New("port in use")
+			}
+			return record("listen")(ctx)
+		}, pipeline.WithDependOn("db", "cache", "rank"), pipeline.WithRetry(2, time.Millisecond))
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 5 || order[0] != "config" || order[4] != "listen" || peak.Load() > 2 || attempts.Load() != 3 {
+		t.Fatalf("unexpected order: %v, peak: %d, attempts: %d", order, peak.Load(), attempts.Load())
+	}
+
+	p = pipeline.New().
+		Stage("settle", func(ctx context.Context) error {
+			panic("boom")
+		}).
+		Stage("mail", record("mail"), pipeline.WithDependOn("settle"))
+	var stageErr *pipeline.StageError
+	if err := p.Run(context.Background()); !errors.As(err, &stageErr) || stageErr.Stage != "settle" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.GetState("settle") != pipeline.StateFailed || p.GetState("mail") != pipeline.StateSkipped {
+		t.Fatalf("unexpected state: %v, %v", p.GetState("settle"), p.GetState("mail"))
+	}
+
+	p = pipeline.New().
+		Stage("a", record("a"), pipeline.WithDependOn("b")).
+		Stage("b", record("b"), pipeline.WithDependOn("a"))
+	if err := p.Run(context.Background()); !errors.Is(err, pipeline.ErrCycle) {
+		t.Fatalf("cyclic dependencies should return ErrCycle, got: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package random
+
+import (
+	"math"
+	"math/rand"
+)
+
+// NewNoise 创建一个以 seed 作为种子的柏林噪声生成器，相同种子在任意机器上都将生成相同的噪声，适用于程序化地图生成及资源分布
+func NewNoise(seed int64) *Noise {
+	noise := &Noise{seed: seed}
+	perm := rand.New(rand.NewSource(seed)).Perm(256)
+	for i := 0; i < 256; i++ {
+		noise.perm[i] = uint8(perm[i])
+		noise.perm[i+256] = uint8(perm[i])
+	}
+	return noise
+}
+
+// Noise 基于改进柏林噪声（Improved Perlin Noise）算法的噪声生成器，生成器创建后是只读的，可并发使用
+//   - 噪声在整数坐标处的值为 0，通常应通过缩放坐标（例如 x * 0.05）进行采样
+type Noise struct {
+	seed int64
+	perm [512]uint8
+}
+
+// GetSeed 获取噪声生成器的种子
+func (slf *Noise) GetSeed() int64 {
+	return slf.seed
+}
+
+// Perlin1D 返回坐标 x 处的一维噪声，取值范围为 [-1, 1]
+func (slf *Noise) Perlin1D(x float64) float64 {
+	xf := math.Floor(x)
+	xi := int(xf) & 255
+	x -= xf
+	u := noiseFade(x)
+	return noiseLerp(u, noiseGrad1D(slf.perm[xi], x), noiseGrad1D(slf.perm[xi+1], x-1)) * 2
+}
+
+// Perlin2D 返回坐标 (x, y) 处的二维噪声，取值范围约为 [-1, 1]
+func (slf *Noise) Perlin2D(x, y float64) float64 {
+	xf, yf := math.Floor(x), math.Floor(y)
+	xi, yi := int(xf)&255, int(yf)&255
+	x, y = x-xf, y-yf
+	u, v := noiseFade(x), noiseFade(y)
+
+	aa := slf.perm[int(slf.perm[xi])+yi]
+	ab := slf.perm[int(slf.perm[xi])+yi+1]
+	ba := slf.perm[int(slf.perm[xi+1])+yi]
+	bb := slf.perm[int(slf.perm[xi+1])+yi+1]
+
+	return noiseLerp(v,
+		noiseLerp(u, noiseGrad2D(aa, x, y), noiseGrad2D(ba, x-1, y)),
+		noiseLerp(u, noiseGrad2D(ab, x, y-1), noiseGrad2D(bb, x-1, y-1)),
+	)
+}
+
+// Fractal1D 返回坐标 x 处叠加 octaves 层的一维分形噪声，取值范围为 [-1, 1]
+//   - 每一层的频率为上一层的 lacunarity 倍（通常为 2），振幅为上一层的 persistence 倍（通常为 0.5）
+func (slf *Noise) Fractal1D(x float64, octaves int, persistence, lacunarity float64) float64 {
+	return noiseFractal(octaves, persistence, lacunarity, func(frequency float64) float64 {
+		return slf.Perlin1D(x * frequency)
+	})
+}
+
+// Fractal2D 返回坐标 (x, y) 处叠加 octaves 层的二维分形噪声，取值范围约为 [-1, 1]
+//   - 每一层的频率为上一层的 lacunarity 倍（通常为 2），振幅为上一层的 persistence 倍（通常为 0.5）
+//   - 相较于单层噪声，分形噪声具有更丰富的细节，适用于生成地形高度图
+func (slf *Noise) Fractal2D(x, y float64, octaves int, persistence, lacunarity float64) float64 {
+	return noiseFractal(octaves, persistence, lacunarity, func(frequency float64) float64 {
+		return slf.Perlin2D(x*frequency, y*frequency)
+	})
+}
+
+// noiseFractal 叠加多层噪声并归一化
+func noiseFractal(octaves int, persistence, lacunarity float64, sample func(frequency float64) float64) float64 {
+	if octaves < 1 {
+		octaves = 1
+	}
+	var total, amplitude, frequency, max = 0.0, 1.0, 1.0, 0.0
+	for i := 0; i < octaves; i++ {
+		total += sample(frequency) * amplitude
+		max += amplitude
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+	return total / max
+}
+
+// noiseFade 缓和曲线 6t^5 - 15t^4 + 10t^3
+func noiseFade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func noiseLerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+func noiseGrad1D(hash uint8, x float64) float64 {
+	if hash&1 == 0 {
+		return x
+	}
+	return -x
+}
+
+func noiseGrad2D(hash uint8, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
@@ -0,0 +1,46 @@
+package random_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/random"
+	"testing"
+)
+
+func TestNoise_Perlin2D(t *testing.T) {
+	a, b, c := random.NewNoise(1), random.NewNoise(1), random.NewNoise(2)
+	var different bool
+	for x := 0.0; x < 32; x += 0.37 {
+		for y := 0.0; y < 32; y += 0.41 {
+			va := a.Perlin2D(x, y)
+			if va != b.Perlin2D(x, y) {
+				t.Fatalf("noise with same seed should be equal at (%v, %v)", x, y)
+			}
+			if va < -1 || va > 1 {
+				t.Fatalf("noise %v out of range at (%v, %v)", va, x, y)
+			}
+			if va != c.Perlin2D(x, y) {
+				different = true
+			}
+			if f := a.Fractal2D(x, y, 4, 0.5, 2); f < -1 || f > 1 {
+				t.Fatalf("fractal noise %v out of range at (%v, %v)", f, x, y)
+			}
+		}
+	}
+	if !different {
+		t.Fatal("noise with different seed should be different")
+	}
+	if v := a.Perlin2D(3, 7); v != 0 {
+		t.Fatalf("noise at integer coordinate should be 0, got %v", v)
+	}
+}
+
+func TestNoise_Perlin1D(t *testing.T) {
+	noise := random.NewNoise(42)
+	for x := -16.0; x < 16; x += 0.13 {
+		if v := noise.Perlin1D(x); v < -1 || v > 1 {
+			t.Fatalf("noise %v out of range at %v", v, x)
+		}
+		if v := noise.Fractal1D(x, 3, 0.5, 2); v < -1 || v > 1 {
+			t.Fatalf("fractal noise %v out of range at %v", v, x)
+		}
+	}
+}
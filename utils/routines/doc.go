@@ -0,0 +1,3 @@
+// Package routines 提供了具有生命周期的协程组，协程组中的协程将捕获 panic，并可在停止时等待所有协程结束
+//   - 协程组通过名称进行区分，可通过 Groups 获取所有协程组中正在运行的协程数量，以便观察或检测协程泄漏
+package routines
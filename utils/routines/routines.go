@@ -0,0 +1,169 @@
+package routines
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/collection"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/super"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultGroupName 默认协程组的名称
+const DefaultGroupName = "default"
+
+var (
+	groups    = make(map[string]*Group)
+	groupLock sync.Mutex
+)
+
+// PanicHandler 协程发生 panic 时的处理函数
+type PanicHandler func(group string, err error, stack []byte)
+
+// Named 获取特定名称的协程组，当协程组不存在时将创建
+func Named(name string) *Group {
+	groupLock.Lock()
+	defer groupLock.Unlock()
+	g, exist := groups[name]
+	if !exist {
+		g = &Group{name: name}
+		g.ctx, g.cancel = context.WithCancel(context.Background())
+		groups[name] = g
+	}
+	return g
+}
+
+// Go 在默认协程组中运行 fn
+func Go(fn func()) {
+	Named(DefaultGroupName).Go(fn)
+}
+
+// Groups 获取所有协程组中正在运行的协程数量
+func Groups() map[string]int64 {
+	groupLock.Lock()
+	defer groupLock.Unlock()
+	var counts = make(map[string]int64, len(groups))
+	for name, g := range groups {
+		counts[name] = g.Count()
+	}
+	return counts
+}
+
+// Group 协程组
+type Group struct {
+	name    string
+	wait    sync.WaitGroup
+	count   atomic.Int64
+	lock    sync.RWMutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	handler PanicHandler
+}
+
+// Name 获取协程组的名称
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Count 获取协程组中正在运行的协程数量
+func (g *Group) Count() int64 {
+	return g.count.Load()
+}
+
+// Context 获取协程组的上下文，上下文将在 Stop 时被取消，长期运行的协程应当监听该上下文以便及时退出
+func (g *Group) Context() context.Context {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return g.ctx
+}
+
+// SetPanicHandler 设置协程发生 panic 时的处理函数，默认将输出错误日志
+func (g *Group) SetPanicHandler(handler PanicHandler) *Group {
+	g.lock.Lock()
+	g.handler = handler
+	g.lock.Unlock()
+	return g
+}
+
+// Go 在协程组中运行 fn，fn 发生的 panic 将被捕获并交由 PanicHandler 处理，不会导致进程退出
+func (g *Group) Go(fn func()) {
+	g.wait.Add(1)
+	g.count.Add(1)
+	go func() {
+		defer func() {
+			if err := super.RecoverPanic(recover()); err != nil {
+				g.onPanic(err.Err, err.Stack)
+			}
+			g.count.Add(-1)
+			g.wait.Done()
+		}()
+		fn()
+	}()
+}
+
+// Stop 取消协程组的上下文并等待所有协程结束，当 ctx 结束时协程仍未全部结束将返回 ctx 的错误
+//   - 停止后协程组将使用新的上下文，依旧可以运行新的协程
+func (g *Group) Stop(ctx context.Context) error {
+	g.lock.Lock()
+	g.cancel()
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+	g.lock.Unlock()
+
+	var done = make(chan struct{})
+	go func() {
+		g.wait.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("routines group %s stop with %d goroutines running: %w", g.name, g.Count(), ctx.Err())
+	}
+}
+
+func (g *Group) onPanic(err error, stack []byte) {
+	g.lock.RLock()
+	handler := g.handler
+	g.lock.RUnlock()
+	if handler != nil {
+		handler(g.name, err, stack)
+		return
+	}
+	log.Error("Routines", log.String("Group", g.name), log.Err(err), log.String("Stack", string(stack)))
+}
+
+// TB testing.TB 中用于报告协程泄漏的子集
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// VerifyNone 检查特定名称的协程组在 timeout 时间内是否已没有正在运行的协程，否则将通过 tb 报告泄漏，通常用于测试结束时检测协程泄漏
+//   - 当未指定 names 时将检查所有协程组
+func VerifyNone(tb TB, timeout time.Duration, names ...string) {
+	tb.Helper()
+	var deadline = time.Now().Add(timeout)
+	for {
+		var leaks []string
+		for name, count := range Groups() {
+			if count == 0 || (len(names) > 0 && !collection.InComparableSlice(names, name)) {
+				continue
+			}
+			leaks = append(leaks, fmt.Sprintf("%s(%d)", name, count))
+		}
+		if len(leaks) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			sort.Strings(leaks)
+			tb.Errorf("routines leaked: %s", strings.Join(leaks, ", "))
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
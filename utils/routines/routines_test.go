@@ -0,0 +1,59 @@
+package routines_test
+
+import (
+	"context"
+	"errors"
+	"github.com/kercylan98/minotaur/utils/routines"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
+
+func TestGroup_Stop(t *testing.T) {
+	g := routines.Named("TestGroup_Stop")
+	var recovered error
+	g.SetPanicHandler(func(group string, err error, stack []byte) {
+		recovered = err
+	})
+	g.Go(func() {
+		panic("boom")
+	})
+	ctx := g.Context()
+	g.Go(func() {
+		<-ctx.Done()
+	})
+
+	r := new(recorder)
+	routines.VerifyNone(r, time.Millisecond*50, g.Name())
+	if len(r.errors) != 1 {
+		t.Fatal("leak should be reported before stop")
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := g.Stop(stopCtx); err != nil {
+		t.Fatal(err)
+	}
+	if recovered == nil || g.Count() != 0 {
+		t.Fatalf("recovered: %v, count: %d", recovered, g.Count())
+	}
+	routines.VerifyNone(t, time.Second, g.Name())
+
+	g.Go(func() {
+		time.Sleep(time.Second)
+	})
+	stopCtx, cancel = context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := g.Stop(stopCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected stop error: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package super
 
 import (
+	"encoding/binary"
 	"fmt"
 	"github.com/kercylan98/minotaur/utils/generic"
 	"math/bits"
@@ -321,6 +322,45 @@ func (slf *BitSet[Bit]) Min() Bit {
 	return 0
 }
 
+// Rank 返回当前 BitSet 中小于等于 bit 的被设置的比特位数量
+//   - 例如在每日签到的位图中，可用于获取截止到某一天的累计签到天数
+func (slf *BitSet[Bit]) Rank(bit Bit) int {
+	if bit < 0 {
+		return 0
+	}
+	word := int(bit >> 6)
+	if word >= len(slf.set) {
+		return slf.Len()
+	}
+	var count int
+	for i := 0; i < word; i++ {
+		count += bits.OnesCount64(slf.set[i])
+	}
+	offset := uint(bit & 0x3f)
+	mask := uint64(1)<<offset | (uint64(1)<<offset - 1)
+	return count + bits.OnesCount64(slf.set[word]&mask)
+}
+
+// Select 返回当前 BitSet 中第 n 个被设置的比特位，n 从 0 开始，当不存在时将返回 false
+//   - Select 为 Rank 的逆运算，对于被设置的比特位 bit，满足 Select(Rank(bit)-1) == bit
+func (slf *BitSet[Bit]) Select(n int) (bit Bit, ok bool) {
+	if n < 0 {
+		return 0, false
+	}
+	for i, word := range slf.set {
+		count := bits.OnesCount64(word)
+		if n >= count {
+			n -= count
+			continue
+		}
+		for ; n > 0; n-- {
+			word &= word - 1 // 清除最低位的 1
+		}
+		return Bit(i*64 + bits.TrailingZeros64(word)), true
+	}
+	return 0, false
+}
+
 // String 返回当前 BitSet 的字符串表示
 func (slf *BitSet[Bit]) String() string {
 	return fmt.Sprintf("[%v] %v", slf.Len(), slf.Bits())
@@ -335,3 +375,28 @@ func (slf *BitSet[Bit]) MarshalJSON() ([]byte, error) {
 func (slf *BitSet[Bit]) UnmarshalJSON(data []byte) error {
 	return UnmarshalJSON(data, &slf.set)
 }
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口，将比特位集合以小端序编码，末尾为 0 的字节将被省略
+//   - 相较于 JSON 格式更加紧凑，适用于持久化玩家的解锁状态等场景
+func (slf *BitSet[Bit]) MarshalBinary() ([]byte, error) {
+	data := make([]byte, len(slf.set)*8)
+	for i, word := range slf.set {
+		binary.LittleEndian.PutUint64(data[i*8:], word)
+	}
+	end := len(data)
+	for end > 0 && data[end-1] == 0 {
+		end--
+	}
+	return data[:end], nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口
+func (slf *BitSet[Bit]) UnmarshalBinary(data []byte) error {
+	slf.set = make([]uint64, (len(data)+7)/8)
+	for i := range slf.set {
+		var buf [8]byte
+		copy(buf[:], data[i*8:])
+		slf.set[i] = binary.LittleEndian.Uint64(buf[:])
+	}
+	return nil
+}
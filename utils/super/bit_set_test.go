@@ -120,3 +120,63 @@ func TestBitSet_Shrink(t *testing.T) {
 		})
 	}
 }
+
+func TestBitSet_RankAndSelect(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   []int
+	}{
+		{name: "normal", in: []int{1, 3, 5, 7, 9}},
+		{name: "grow", in: []int{0, 63, 64, 127, 128, 300}},
+		{name: "empty", in: make([]int, 0)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bs := super.NewBitSet(c.in...)
+			for i, bit := range c.in {
+				if rank := bs.Rank(bit); rank != i+1 {
+					t.Fatalf("rank of %v: %v != %v", bit, rank, i+1)
+				}
+				if v, ok := bs.Select(i); !ok || v != bit {
+					t.Fatalf("select %v: %v != %v", i, v, bit)
+				}
+			}
+			if _, ok := bs.Select(len(c.in)); ok {
+				t.Fatalf("select %v should not exist", len(c.in))
+			}
+		})
+	}
+}
+
+func TestBitSet_MarshalBinary(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   []int
+	}{
+		{name: "normal", in: []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{name: "grow", in: []int{0, 63, 64, 127, 128, 300}},
+		{name: "empty", in: make([]int, 0)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := super.NewBitSet(c.in...).MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bs := super.NewBitSet[int]()
+			if err = bs.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if bs.Len() != len(c.in) {
+				t.Fatalf("len %v != %v", bs.Len(), len(c.in))
+			}
+			for _, bit := range c.in {
+				if !bs.Has(bit) {
+					t.Fatalf("bit %v not set", bit)
+				}
+			}
+		})
+	}
+}
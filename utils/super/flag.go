@@ -0,0 +1,43 @@
+package super
+
+import "github.com/kercylan98/minotaur/utils/generic"
+
+// FlagHas 检查 flags 是否包含 flag 中的所有比特位
+//   - 当需要表示的标记超过 64 个或数量不确定时，应使用 BitSet
+func FlagHas[F generic.Integer](flags, flag F) bool {
+	return flags&flag == flag
+}
+
+// FlagHasAny 检查 flags 是否包含 flag 中的任意比特位
+func FlagHasAny[F generic.Integer](flags, flag F) bool {
+	return flags&flag != 0
+}
+
+// FlagSet 返回将 flags 设置了 flag 中所有比特位后的结果
+func FlagSet[F generic.Integer](flags F, flag ...F) F {
+	for _, f := range flag {
+		flags |= f
+	}
+	return flags
+}
+
+// FlagDel 返回将 flags 清除了 flag 中所有比特位后的结果
+func FlagDel[F generic.Integer](flags F, flag ...F) F {
+	for _, f := range flag {
+		flags &^= f
+	}
+	return flags
+}
+
+// FlagToggle 返回将 flags 翻转了 flag 中所有比特位后的结果
+func FlagToggle[F generic.Integer](flags F, flag ...F) F {
+	for _, f := range flag {
+		flags ^= f
+	}
+	return flags
+}
+
+// FlagOf 返回第 bit 位为 1 的标记，例如 FlagOf[uint32](3) 将返回 0b1000
+func FlagOf[F generic.Integer](bit int) F {
+	return F(1) << bit
+}
@@ -0,0 +1,102 @@
+package super
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+var panicHooks []PanicHook
+var panicHookLock sync.RWMutex
+
+// PanicHook panic 钩子函数，将在 GoSafe 及 GoWithResult 执行的函数发生 panic 时被调用
+type PanicHook func(err *PanicError)
+
+// PanicError 由 panic 转换而来的错误，包含发生 panic 时的堆栈信息
+type PanicError struct {
+	Err   error  // 通过 RecoverTransform 转换后的错误
+	Stack []byte // 发生 panic 时的堆栈信息
+}
+
+func (e *PanicError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PanicError) Unwrap() error {
+	return e.Err
+}
+
+// RecoverPanic 将 recover 的结果转换为包含堆栈信息的 *PanicError，当 a 为 nil 时返回 nil
+//   - 应当在 defer 的函数中以 RecoverPanic(recover()) 的形式调用，以便获取发生 panic 时的堆栈信息
+func RecoverPanic(a any) *PanicError {
+	err := RecoverTransform(a)
+	if err == nil {
+		return nil
+	}
+	return &PanicError{Err: err, Stack: debug.Stack()}
+}
+
+// RegPanicHook 注册 panic 钩子函数，例如通过 RegPanicHook(log.PanicHook) 将 panic 及其堆栈信息输出到日志
+func RegPanicHook(hook PanicHook) {
+	panicHookLock.Lock()
+	panicHooks = append(panicHooks, hook)
+	panicHookLock.Unlock()
+}
+
+// ReportPanic 将 panic 报告给所有通过 RegPanicHook 注册的钩子函数
+func ReportPanic(err *PanicError) {
+	panicHookLock.RLock()
+	hooks := panicHooks
+	panicHookLock.RUnlock()
+	for _, hook := range hooks {
+		hook(err)
+	}
+}
+
+// GoSafe 在新的协程中执行 fn，fn 发生的 panic 将被转换为 *PanicError 并报告给通过 RegPanicHook 注册的钩子函数
+//   - 返回的通道将在 fn 结束后接收 fn 发生 panic 时的错误或 nil 并关闭，通道存在缓冲区，不读取时也不会阻塞协程
+func GoSafe(fn func()) <-chan error {
+	var errChan = make(chan error, 1)
+	go func() {
+		defer close(errChan)
+		if err := safeCall(fn); err != nil {
+			errChan <- err
+			return
+		}
+		errChan <- nil
+	}()
+	return errChan
+}
+
+// GoWithResult 在新的协程中执行 fn，fn 发生的 panic 将被转换为 *PanicError 并报告给通过 RegPanicHook 注册的钩子函数
+//   - 返回的通道将在 fn 结束后分别接收 fn 的返回值及错误并关闭，发生 panic 时将接收零值及 *PanicError，通道存在缓冲区，不读取时也不会阻塞协程
+func GoWithResult[T any](fn func() (T, error)) (<-chan T, <-chan error) {
+	var resultChan, errChan = make(chan T, 1), make(chan error, 1)
+	go func() {
+		defer func() {
+			close(resultChan)
+			close(errChan)
+		}()
+		var result T
+		var err error
+		if panicErr := safeCall(func() {
+			result, err = fn()
+		}); panicErr != nil {
+			var zero T
+			result, err = zero, panicErr
+		}
+		resultChan <- result
+		errChan <- err
+	}()
+	return resultChan, errChan
+}
+
+// safeCall 执行 fn 并将发生的 panic 转换为 *PanicError 进行报告
+func safeCall(fn func()) (err *PanicError) {
+	defer func() {
+		if err = RecoverPanic(recover()); err != nil {
+			ReportPanic(err)
+		}
+	}()
+	fn()
+	return nil
+}
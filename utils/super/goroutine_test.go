@@ -0,0 +1,45 @@
+package super_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/utils/super"
+	"testing"
+)
+
+func TestGoSafe(t *testing.T) {
+	var reported *super.PanicError
+	super.RegPanicHook(func(err *super.PanicError) {
+		reported = err
+	})
+
+	if err := <-super.GoSafe(func() {}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	target := errors.New("boom")
+	err := <-super.GoSafe(func() {
+		panic(target)
+	})
+	var panicErr *super.PanicError
+	if !errors.As(err, &panicErr) || !errors.Is(err, target) || len(panicErr.Stack) == 0 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reported != panicErr {
+		t.Fatalf("panic hook not reported")
+	}
+}
+
+func TestGoWithResult(t *testing.T) {
+	result, errChan := super.GoWithResult(func() (int, error) {
+		return 1, nil
+	})
+	if v, err := <-result, <-errChan; v != 1 || err != nil {
+		t.Fatalf("unexpected result: %d, %v", v, err)
+	}
+
+	result, errChan = super.GoWithResult(func() (int, error) {
+		panic("boom")
+	})
+	if v, err := <-result, <-errChan; v != 0 || err == nil || err.Error() != "boom" {
+		t.Fatalf("unexpected result: %d, %v", v, err)
+	}
+}
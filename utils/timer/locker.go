@@ -0,0 +1,71 @@
+package timer
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync"
+	"time"
+)
+
+// Locker 分布式锁，用于在集群部署时保证特定名称的调度器在每次触发时至多被一个节点执行
+//   - 可基于 redis（SET key value NX PX ttl）、etcd（租约）等实现
+//   - 实现应当是并发安全的
+type Locker interface {
+	// TryLock 尝试获取 key 的锁，锁在 ttl 后自动释放，当锁已被持有时应当返回 false 而不是阻塞
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// NewMemoryLocker 创建基于内存的锁，仅适用于单进程内的多个定时器或测试等情况
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: make(map[string]time.Time)}
+}
+
+// MemoryLocker 基于内存的锁
+type MemoryLocker struct {
+	lock  sync.Mutex
+	locks map[string]time.Time
+}
+
+func (slf *MemoryLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	now := time.Now()
+	if expire, exist := slf.locks[key]; exist && now.Before(expire) {
+		return false, nil
+	}
+	slf.locks[key] = now.Add(ttl)
+	return true, nil
+}
+
+// tickerLocker 定时器的分布式锁配置
+type tickerLocker struct {
+	locker Locker
+	ttl    time.Duration
+	names  map[string]struct{} // 需要获取锁的调度器名称，为空时表示所有调度器
+}
+
+// acquire 在执行特定名称的调度器前获取分布式锁，返回是否应当执行
+//   - 获取锁失败时将跳过本次执行，以保证至多执行一次
+func (slf *Ticker) acquire(name string) bool {
+	slf.lock.RLock()
+	l, mark := slf.locker, slf.mark
+	slf.lock.RUnlock()
+	if l == nil {
+		return true
+	}
+	if _, exist := l.names[name]; len(l.names) > 0 && !exist {
+		return true
+	}
+	key := name
+	if len(mark) > 0 {
+		key = mark + ":" + name
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), l.ttl)
+	defer cancel()
+	locked, err := l.locker.TryLock(ctx, key, l.ttl)
+	if err != nil {
+		log.Error("Timer", log.String("Lock", key), log.Err(err))
+		return false
+	}
+	return locked
+}
@@ -0,0 +1,28 @@
+package timer_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/timer"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithLocker(t *testing.T) {
+	var locker = timer.NewMemoryLocker()
+	var reset, other atomic.Int32
+	for i := 0; i < 3; i++ {
+		// 模拟集群中的多个节点
+		ticker := timer.NewPool(1).GetTicker(10, timer.WithMark("game"), timer.WithLocker(locker, time.Millisecond*100, "daily_reset"))
+		defer ticker.Release()
+		ticker.After("daily_reset", time.Millisecond*20, func() {
+			reset.Add(1)
+		})
+		ticker.After("other", time.Millisecond*20, func() {
+			other.Add(1)
+		})
+	}
+	time.Sleep(time.Millisecond * 300)
+	if reset.Load() != 1 || other.Load() != 3 {
+		t.Fatalf("reset: %d, other: %d", reset.Load(), other.Load())
+	}
+}
@@ -1,5 +1,7 @@
 package timer
 
+import "time"
+
 type Option func(ticker *Ticker)
 
 // WithCaller 通过其他的 handler 执行 Caller
@@ -17,3 +19,23 @@ func WithMark(mark string) Option {
 		ticker.mark = mark
 	}
 }
+
+// WithLocker 通过在调度器执行前获取分布式锁的方式创建定时器，适用于集群部署时每日重置等仅应在一个节点上执行的任务
+//   - 锁的键为调度器名称，当定时器存在标记时将以 "标记:名称" 作为键，各节点应当使用相同的名称及标记
+//   - 获取锁失败或锁已被其他节点持有时将跳过本次执行，锁不会在执行结束后主动释放，而是在 ttl 后自动过期
+//   - ttl 应当大于节点间的时钟误差且小于调度器的执行间隔
+//   - 当指定了 names 时仅对特定名称的调度器生效，否则对所有调度器生效
+func WithLocker(locker Locker, ttl time.Duration, names ...string) Option {
+	return func(ticker *Ticker) {
+		l := &tickerLocker{locker: locker, ttl: ttl}
+		if len(names) > 0 {
+			l.names = make(map[string]struct{}, len(names))
+			for _, name := range names {
+				l.names[name] = struct{}{}
+			}
+		}
+		ticker.lock.Lock()
+		ticker.locker = l
+		ticker.lock.Unlock()
+	}
+}
@@ -71,6 +71,9 @@ func (slf *Scheduler) Caller() {
 	} else {
 		slf.lock.Unlock()
 	}
+	if !slf.ticker.acquire(slf.name) {
+		return
+	}
 	slf.cbFunc.Call(slf.cbArgs)
 }
 
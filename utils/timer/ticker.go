@@ -2,6 +2,7 @@ package timer
 
 import (
 	"github.com/gorhill/cronexpr"
+	"github.com/kercylan98/minotaur/utils/times"
 	"reflect"
 	"sync"
 	"time"
@@ -18,6 +19,7 @@ type Ticker struct {
 
 	handler func(name string, caller func())
 	mark    string
+	locker  *tickerLocker
 }
 
 // Mark 获取定时器的标记
@@ -34,6 +36,7 @@ func (slf *Ticker) Release() {
 	slf.lock.Lock()
 	slf.mark = ""
 	slf.handler = nil
+	slf.locker = nil
 	for name, scheduler := range slf.timers {
 		scheduler.close()
 		delete(slf.timers, name)
@@ -80,8 +83,12 @@ func (slf *Ticker) GetSchedulers() []string {
 }
 
 // Cron 通过 cron 表达式设置一个调度器，当 cron 表达式错误时，将会引发 panic
+//   - cron 表达式的格式可参考 times.ParseCron，6 段表达式将按照 "秒 分 时 日 月 周" 解析
 func (slf *Ticker) Cron(name, expression string, handleFunc interface{}, args ...interface{}) {
-	expr := cronexpr.MustParse(expression)
+	expr, err := times.ParseCron(expression)
+	if err != nil {
+		panic(err)
+	}
 	slf.loop(name, 0, 0, expr, 0, handleFunc, args...)
 }
 
@@ -93,6 +100,9 @@ func (slf *Ticker) CronByInstantly(name, expression string, handleFunc interface
 			values[i] = reflect.ValueOf(v)
 		}
 		f := reflect.ValueOf(handleFunc)
+		if !slf.acquire(name) {
+			return
+		}
 		slf.lock.RLock()
 		defer slf.lock.RUnlock()
 		if slf.handler != nil {
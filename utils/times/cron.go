@@ -0,0 +1,18 @@
+package times
+
+import (
+	"github.com/gorhill/cronexpr"
+	"strings"
+)
+
+// ParseCron 解析 cron 表达式，基于 cron 表达式的定时器均通过该函数解析，以确保相同的表达式在各处具有相同的含义
+//   - 6 段表达式按照 "秒 分 时 日 月 周" 解析，例如 "0 0 4 * * *" 表示每天凌晨 4 点
+//   - 7 段表达式按照 "秒 分 时 日 月 周 年" 解析，5 段表达式按照 "分 时 日 月 周" 解析
+//   - 支持 "@daily" 等预定义表达式
+func ParseCron(expression string) (*cronexpr.Expression, error) {
+	// cronexpr 会将 6 段表达式视为 "分 时 日 月 周 年"，此处补充年份字段，使其按照 "秒 分 时 日 月 周" 解析
+	if len(strings.Fields(expression)) == 6 {
+		expression += " *"
+	}
+	return cronexpr.Parse(expression)
+}
@@ -0,0 +1,33 @@
+package times_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/times"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	now := time.Date(2024, 3, 1, 3, 30, 0, 0, time.UTC)
+	for _, c := range []struct {
+		expression string
+		expected   time.Time
+	}{
+		{expression: "0 0 4 * * *", expected: time.Date(2024, 3, 1, 4, 0, 0, 0, time.UTC)},
+		{expression: "30 0 4 * * *", expected: time.Date(2024, 3, 1, 4, 0, 30, 0, time.UTC)},
+		{expression: "0 4 * * *", expected: time.Date(2024, 3, 1, 4, 0, 0, 0, time.UTC)},
+		{expression: "0 0 4 * * * 2030", expected: time.Date(2030, 1, 1, 4, 0, 0, 0, time.UTC)},
+		{expression: "@daily", expected: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+	} {
+		expr, err := times.ParseCron(c.expression)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expression, err)
+		}
+		if next := expr.Next(now); !next.Equal(c.expected) {
+			t.Fatalf("%s: unexpected next: %v, expected: %v", c.expression, next, c.expected)
+		}
+	}
+
+	if _, err := times.ParseCron("invalid"); err == nil {
+		t.Fatal("invalid cron expression should return error")
+	}
+}